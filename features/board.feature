@@ -0,0 +1,73 @@
+Feature: Board view
+  As a developer planning what to pick up next
+  I want to see my assigned tickets in columns by status
+  So that I can choose a ticket and start on it from the same screen
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title                   | parent_id | status      |
+      | SPR-1      | Add user authentication |           | Todo        |
+      | SPR-2      | Fix login redirect      |           | In Review   |
+      | SPR-3      | Implement dashboard     |           | In Progress |
+      | SPR-4      | Write onboarding docs   |           | Todo        |
+      | SPR-5      | Remove legacy importer  |           | Done        |
+    When I start the Sprout TUI
+    And I press "v"
+
+  Scenario: Open tickets are grouped into status columns
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Todo (2)                   In Progress (1)            In Review (1)
+
+      SPR-1                      SPR-3                      SPR-2
+      Add user authentication    Implement dashboard        Fix login redirect
+
+      SPR-4
+      Write onboarding docs
+      [worktree <tab>] [enter create] [v list] [? help]
+      """
+
+  Scenario: Enter creates a worktree for the focused card
+    When I press "down"
+    And I press "enter"
+    Then a worktree should be created for branch "spr-4-write-onboarding-docs"
+
+  Scenario: Arrow keys move between columns
+    When I press "right"
+    And I press "right"
+    And I press "enter"
+    Then a worktree should be created for branch "spr-2-fix-login-redirect"
+
+  Scenario: Moving to a shorter column keeps the card in range
+    When I press "down"
+    And I press "right"
+    And I press "enter"
+    Then a worktree should be created for branch "spr-3-implement-dashboard"
+
+  Scenario: The board respects branch-only mode
+    When I press "tab"
+    Then the UI should display "[branch <tab>] [enter create] [v list] [? help]"
+
+  Scenario: Toggling back returns to the list with the card still selected
+    When I press "right"
+    And I press "v"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/spr-3-implement-dashboard
+      ├──SPR-1  Todo         Add user authentication
+      ├──SPR-2  In Review    Fix login redirect
+      ├──SPR-3  In Progress  Implement dashboard
+      ├──SPR-4  Todo         Write onboarding docs
+      └──SPR-5  Done         Remove legacy importer
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: The help overlay explains the board
+    When I press "?"
+    Then the UI should display "←/h →/l    move between columns"
+    And the UI should display "enter      create a worktree for SPR-1"
+    And the UI should display "v          back to the list"
@@ -0,0 +1,74 @@
+Feature: Recent branch suggestions
+  As a developer who keeps coming back to the same branches
+  I want branches I've used before suggested as I type
+  So that I can pick one instead of typing its full name again
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title                   | parent_id | status |
+      | SPR-2      | Add user authentication |           | Todo   |
+    And the branch history is:
+      | branch             |
+      | fix-logout-button  |
+      | fix-login-redirect |
+      | docs-typos         |
+
+  Scenario: Matching recent branches are listed under the input
+    When I start the Sprout TUI
+    And I type "fix-lo"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/fix-lo
+        fix-logout-button
+        fix-login-redirect
+      └──SPR-2  Todo  Add user authentication
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Suggestions narrow as more is typed
+    When I start the Sprout TUI
+    And I type "fix-login"
+    Then the UI should display "fix-login-redirect"
+    And the UI should not display "fix-logout-button"
+    And the UI should not display "docs-typos"
+
+  Scenario: Nothing is suggested before typing
+    When I start the Sprout TUI
+    Then the UI should not display "fix-logout-button"
+
+  Scenario: Choosing a suggestion with the arrow keys creates that branch
+    When I start the Sprout TUI
+    And I type "fix-lo"
+    And I press "down"
+    And I press "down"
+    And I press "enter"
+    Then a worktree should be created for branch "fix-login-redirect"
+    And the branch history should record "fix-login-redirect"
+
+  Scenario: Moving back up returns to the typed name
+    When I start the Sprout TUI
+    And I type "fix-lo"
+    And I press "down"
+    And I press "up"
+    And I press "enter"
+    Then a worktree should be created for branch "fix-lo"
+    And the branch history should record "fix-lo"
+
+  Scenario: Moving past the suggestions continues into the work queue
+    When I start the Sprout TUI
+    And I type "fix-lo"
+    And I press "down" 3 times
+    And I press "enter"
+    Then a worktree should be created for branch "spr-2-add-user-authentication"
+
+  Scenario: Resuming a worktree records it in the history
+    Given the following worktrees exist:
+      | branch         | path                           | updated_at           | merged |
+      | feature-search | /mock/worktrees/feature-search | 2026-05-01T16:00:00Z | false  |
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "enter"
+    Then the TUI should resume worktree "/mock/worktrees/feature-search"
+    And the branch history should record "feature-search"
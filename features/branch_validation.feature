@@ -0,0 +1,44 @@
+Feature: Check the branch name while it's typed
+  As a developer using Sprout
+  I want to hear what's wrong with a branch name before I submit it
+  So that I don't land on an error screen and have to start again
+
+  Background:
+    Given the following worktrees exist:
+      | branch      | path                        | updated_at           | merged |
+      | feature-one | /mock/worktrees/feature-one | 2026-05-02T07:00:00Z | false  |
+
+  Scenario: A reserved name is refused before submission
+    Given I start the Sprout TUI
+    When I type "main"
+    Then the UI should display "⚠ branch name is reserved: main is the repository's main line; pick another name"
+    When I press "enter"
+    Then no new worktree should be created
+    And the UI should display "> sprout/main"
+
+  Scenario: A name with nothing git can use is refused
+    Given I start the Sprout TUI
+    When I type "!!!"
+    Then the UI should display "⚠ branch name is empty once the characters git can't use are removed from "
+    When I press "enter"
+    Then no new worktree should be created
+
+  Scenario: A name git would reject is refused
+    Given I start the Sprout TUI
+    When I type "fix..typo"
+    Then the UI should display "⚠ not a valid branch name: fix..typo contains .."
+    When I press "enter"
+    Then no new worktree should be created
+
+  Scenario: The warning clears once the name is fixed
+    Given I start the Sprout TUI
+    When I type "main"
+    And I type "-docs"
+    Then the UI should not display "⚠"
+
+  Scenario: A branch with a worktree is noted but can still be submitted
+    Given I start the Sprout TUI
+    When I type "feature-one"
+    Then the UI should display "⚠ feature-one has a worktree already; Enter offers to open it"
+    When I press "enter"
+    Then the UI should display "A worktree for feature-one already exists:"
@@ -12,20 +12,117 @@ Feature: Sprout CLI Commands
       Usage:
         sprout                              Start in interactive mode
         sprout list                         List all worktrees
+        sprout issues                       Browse and triage issues without creating worktrees
+        sprout pick                         Print assigned issues a line each for fzf, to pipe to sprout create -
+        sprout clone <url> [dir]            Clone as a bare repo with a worktree per branch
+        sprout migrate [--dry-run]          Convert this checkout to a bare repo with worktrees
         sprout create <branch>              Create worktree and output path
         sprout create <branch> <command>    Create worktree and run command in it
+        sprout create -                     Create worktree for the branch piped in, outputting only its path
+        sprout create --issue-from-stdin    The same for the issue piped in, named as the TUI names it
+        sprout create --json <branch>       Create worktree, printing each step as a line of JSON
+        sprout create <issue-url>           Create worktree named after a Linear, GitHub, GitLab or Jira issue
+        sprout subtask <parent> <title>     Create a Linear subtask under a parent issue
+        sprout switch <branch>              Output an existing worktree's path, or attach to its tmux session
+        sprout resume [branch]              Run the last command run in a worktree again, by default the latest
+        sprout where [--exists] [branch]    Print where a branch's worktree is or would go, creating nothing
+        sprout pr checkout [pr]             Pull a PR's latest head into its worktree, by number, branch or issue
         sprout prune [branch]               Remove worktree(s) - all merged if no branch specified
-        sprout doctor                       Show configuration values
+        sprout rm <branch>                  Remove a specific worktree (alias for prune <branch>)
+        sprout archive <branch>             Save a worktree's unmerged work, then remove it
+        sprout restore <branch>             Recreate an archived worktree and output its path
+        sprout export <branch>              Pack a worktree's branch, changes and issue into one file for a teammate
+        sprout import <file>                Recreate a worktree from an exported file and output its path
+        sprout pin <branch>                 Lock a worktree so prune leaves it alone
+        sprout unpin <branch>               Lift the pin so the worktree can be pruned again
+        sprout note <branch> [text]         Keep a short note on a worktree, or show it
+        sprout undo                         Bring back the worktrees the last prune removed
+        sprout gc [--dry-run]               Prune merged, stale and oversize worktrees and empty old trash
+        sprout stack [branch]               Show the branches a worktree's branch is built on and those built on it
+        sprout stack restack [branch]       Rebase each branch in the stack onto the one it's built on
+        sprout rename <old> <new>           Rename a worktree's branch and move its directory
+        sprout repair                       Clean up worktrees deleted outside sprout
+        sprout exec -- <command>            Run a command in every worktree
+        sprout serve --stdio                Serve JSON-RPC on stdin and stdout for editor plugins
+        sprout sparse set <dirs...>         Save sparse-checkout directories as a named profile
+        sprout sparse show                  List sparse-checkout profiles for this repo
+        sprout sparse apply <branch>        Apply a sparse-checkout profile to a worktree
+        sprout history [--since when]       Show what sprout has done in this repo
+        sprout today [--json]               Sum up issues in progress, open PRs and what needs tidying
+        sprout stats                        Show local worktree usage statistics
+        sprout auth linear [--logout]       Sign in to Linear in the browser instead of using an API key
+        sprout auth github [--logout]       Store a GitHub token (read from stdin) for PR status without gh
+        sprout doctor [--json]              Check configuration and connectivity
+        sprout config resolved [--json]     Show each setting in effect and the file or variable it came from
+        sprout init --git-alias             Install git-sprout so git sprout <command> works too
+        sprout upgrade [--check]            Install the latest release in place of this binary
+        sprout version [--json]             Show build details and the git and gh versions found
         sprout help                         Show this help
 
+      Global options:
+        --config <path>                     Read settings from path instead of ~/.sprout.json5
+        --repo <name>                       Work in the registered repository called name
+        --profile-startup                   Print how long each phase of starting up took
+
+      Settings come from SPROUT_* environment variables first, then the config file
+      given with --config or SPROUT_CONFIG, or ~/.sprout.json5, then the defaults.
+
       Examples:
         sprout list                          # Show all worktrees
+        sprout list --all-repos              # Show worktrees from every repo sprout has run in
+        sprout list --porcelain              # Tab-separated branch, path, PR status, commit
+        sprout list --sort=age               # Most recently committed first (or status, branch)
+        sprout list --status=dirty           # Only worktrees with uncommitted changes
+        cd "$(sprout clone <url>)"           # Clone and change to the default branch's worktree
         cd "$(sprout create mybranch)"       # Change to worktree directory
         sprout create mybranch bash          # Create worktree and start bash
         sprout create mybranch code .        # Create worktree and open in VS Code
         sprout create mybranch git status    # Create worktree and run git status
+        sprout create --paths api mybranch   # Create worktree with only api/ checked out
+        sprout create --open=code mybranch   # Create worktree and open it in VS Code
+        sprout create --existing=fail fix    # Fail if fix already has a branch or worktree
+        sprout create --template fix/ login  # Create fix/login with the fix/ template applied
+        sprout create --no-lfs mybranch      # Create worktree without fetching LFS files
+        sprout create --no-fetch mybranch    # Create worktree from the refs already fetched
+        sprout create --detach web npm start # Start npm start in the background and return
+        sprout create --dry-run fix/login    # Show what create would do without doing it
+        echo fix/login | sprout create -     # Create fix/login, outputting only its path
+        sprout pick | fzf | sprout create -  # Choose an issue with fzf and create its worktree
+        sprout subtask ENG-12 Fix tests -w   # Create a subtask and a worktree for it
+        sprout subtask ENG-12 --from-file -  # Create a subtask per line piped in
         sprout prune                         # Remove all merged worktrees
         sprout prune mybranch                # Remove specific worktree and directory
+        sprout prune --dry-run               # Show what would be removed
+        sprout prune --yes                   # Remove merged worktrees without asking first
+        sprout prune --quiet                 # Print only a result line per removed worktree
+        sprout prune --larger-than 5GB       # Remove worktrees bigger than 5GB
+        sprout prune --all-repos             # Remove merged worktrees in every repo
+        sprout gc --stale-days 30 --dry-run  # See what gc would collect, counting a month idle
+        sprout gc --schedule daily           # Collect in every repo each day in the background
+        sprout rm mybranch --keep-branch     # Remove worktree but keep the branch
+        sprout rm mybranch --delete-remote   # Also delete origin/mybranch
+        sprout rm mybranch --unlock          # Remove a worktree locked with git worktree lock
+        sprout archive mybranch              # Keep mybranch's work but free its directory
+        cd "$(sprout restore mybranch)"      # Bring mybranch back and change to it
+        sprout export mybranch              # Write mybranch.sprout.tgz for a teammate
+        cd "$(sprout import fix.sprout.tgz)" # Carry on where a teammate left off
+        sprout pin release-2.x               # Keep a long-lived worktree out of every prune
+        sprout note fix/login waiting on QA  # Remember what a worktree is waiting on
+        sprout stack restack                 # Bring the parent's new commits into each branch above it
+        cd "$(sprout pr checkout ENG-123)"   # Pick up ENG-123's PR again after review comments
+        cd "$(sprout rename fxi fix)"        # Fix a typo and follow the worktree
+        sprout exec --parallel 4 git fetch   # Fetch in four worktrees at a time
+        sprout exec --status open -- npm ci  # Reinstall in worktrees with an open PR
+        sprout sparse set services/api libs  # Check out only these directories
+        sprout history --since 7d --json     # Last week's worktrees and commands as JSON
+        sprout today --all-repos             # Morning summary across every repo
+        sprout doctor --json                 # Health checks for scripts; exits 1 if one fails
+        git sprout create mybranch           # The same, once sprout init --git-alias has run
+        sprout upgrade --check               # See whether a newer release is out
+        gh auth token | sprout auth github   # Keep using gh's token once gh is gone
+        sprout --config ~/oss.json5 list     # Use a separate profile for open source work
+        sprout --repo api list               # List worktrees of the api repository from anywhere
+        SPROUT_OPEN_IN=tmux sprout create x  # Override one setting for a single run
       """
 
   Scenario: Show help with --help flag
@@ -37,20 +134,117 @@ Feature: Sprout CLI Commands
       Usage:
         sprout                              Start in interactive mode
         sprout list                         List all worktrees
+        sprout issues                       Browse and triage issues without creating worktrees
+        sprout pick                         Print assigned issues a line each for fzf, to pipe to sprout create -
+        sprout clone <url> [dir]            Clone as a bare repo with a worktree per branch
+        sprout migrate [--dry-run]          Convert this checkout to a bare repo with worktrees
         sprout create <branch>              Create worktree and output path
         sprout create <branch> <command>    Create worktree and run command in it
+        sprout create -                     Create worktree for the branch piped in, outputting only its path
+        sprout create --issue-from-stdin    The same for the issue piped in, named as the TUI names it
+        sprout create --json <branch>       Create worktree, printing each step as a line of JSON
+        sprout create <issue-url>           Create worktree named after a Linear, GitHub, GitLab or Jira issue
+        sprout subtask <parent> <title>     Create a Linear subtask under a parent issue
+        sprout switch <branch>              Output an existing worktree's path, or attach to its tmux session
+        sprout resume [branch]              Run the last command run in a worktree again, by default the latest
+        sprout where [--exists] [branch]    Print where a branch's worktree is or would go, creating nothing
+        sprout pr checkout [pr]             Pull a PR's latest head into its worktree, by number, branch or issue
         sprout prune [branch]               Remove worktree(s) - all merged if no branch specified
-        sprout doctor                       Show configuration values
+        sprout rm <branch>                  Remove a specific worktree (alias for prune <branch>)
+        sprout archive <branch>             Save a worktree's unmerged work, then remove it
+        sprout restore <branch>             Recreate an archived worktree and output its path
+        sprout export <branch>              Pack a worktree's branch, changes and issue into one file for a teammate
+        sprout import <file>                Recreate a worktree from an exported file and output its path
+        sprout pin <branch>                 Lock a worktree so prune leaves it alone
+        sprout unpin <branch>               Lift the pin so the worktree can be pruned again
+        sprout note <branch> [text]         Keep a short note on a worktree, or show it
+        sprout undo                         Bring back the worktrees the last prune removed
+        sprout gc [--dry-run]               Prune merged, stale and oversize worktrees and empty old trash
+        sprout stack [branch]               Show the branches a worktree's branch is built on and those built on it
+        sprout stack restack [branch]       Rebase each branch in the stack onto the one it's built on
+        sprout rename <old> <new>           Rename a worktree's branch and move its directory
+        sprout repair                       Clean up worktrees deleted outside sprout
+        sprout exec -- <command>            Run a command in every worktree
+        sprout serve --stdio                Serve JSON-RPC on stdin and stdout for editor plugins
+        sprout sparse set <dirs...>         Save sparse-checkout directories as a named profile
+        sprout sparse show                  List sparse-checkout profiles for this repo
+        sprout sparse apply <branch>        Apply a sparse-checkout profile to a worktree
+        sprout history [--since when]       Show what sprout has done in this repo
+        sprout today [--json]               Sum up issues in progress, open PRs and what needs tidying
+        sprout stats                        Show local worktree usage statistics
+        sprout auth linear [--logout]       Sign in to Linear in the browser instead of using an API key
+        sprout auth github [--logout]       Store a GitHub token (read from stdin) for PR status without gh
+        sprout doctor [--json]              Check configuration and connectivity
+        sprout config resolved [--json]     Show each setting in effect and the file or variable it came from
+        sprout init --git-alias             Install git-sprout so git sprout <command> works too
+        sprout upgrade [--check]            Install the latest release in place of this binary
+        sprout version [--json]             Show build details and the git and gh versions found
         sprout help                         Show this help
 
+      Global options:
+        --config <path>                     Read settings from path instead of ~/.sprout.json5
+        --repo <name>                       Work in the registered repository called name
+        --profile-startup                   Print how long each phase of starting up took
+
+      Settings come from SPROUT_* environment variables first, then the config file
+      given with --config or SPROUT_CONFIG, or ~/.sprout.json5, then the defaults.
+
       Examples:
         sprout list                          # Show all worktrees
+        sprout list --all-repos              # Show worktrees from every repo sprout has run in
+        sprout list --porcelain              # Tab-separated branch, path, PR status, commit
+        sprout list --sort=age               # Most recently committed first (or status, branch)
+        sprout list --status=dirty           # Only worktrees with uncommitted changes
+        cd "$(sprout clone <url>)"           # Clone and change to the default branch's worktree
         cd "$(sprout create mybranch)"       # Change to worktree directory
         sprout create mybranch bash          # Create worktree and start bash
         sprout create mybranch code .        # Create worktree and open in VS Code
         sprout create mybranch git status    # Create worktree and run git status
+        sprout create --paths api mybranch   # Create worktree with only api/ checked out
+        sprout create --open=code mybranch   # Create worktree and open it in VS Code
+        sprout create --existing=fail fix    # Fail if fix already has a branch or worktree
+        sprout create --template fix/ login  # Create fix/login with the fix/ template applied
+        sprout create --no-lfs mybranch      # Create worktree without fetching LFS files
+        sprout create --no-fetch mybranch    # Create worktree from the refs already fetched
+        sprout create --detach web npm start # Start npm start in the background and return
+        sprout create --dry-run fix/login    # Show what create would do without doing it
+        echo fix/login | sprout create -     # Create fix/login, outputting only its path
+        sprout pick | fzf | sprout create -  # Choose an issue with fzf and create its worktree
+        sprout subtask ENG-12 Fix tests -w   # Create a subtask and a worktree for it
+        sprout subtask ENG-12 --from-file -  # Create a subtask per line piped in
         sprout prune                         # Remove all merged worktrees
         sprout prune mybranch                # Remove specific worktree and directory
+        sprout prune --dry-run               # Show what would be removed
+        sprout prune --yes                   # Remove merged worktrees without asking first
+        sprout prune --quiet                 # Print only a result line per removed worktree
+        sprout prune --larger-than 5GB       # Remove worktrees bigger than 5GB
+        sprout prune --all-repos             # Remove merged worktrees in every repo
+        sprout gc --stale-days 30 --dry-run  # See what gc would collect, counting a month idle
+        sprout gc --schedule daily           # Collect in every repo each day in the background
+        sprout rm mybranch --keep-branch     # Remove worktree but keep the branch
+        sprout rm mybranch --delete-remote   # Also delete origin/mybranch
+        sprout rm mybranch --unlock          # Remove a worktree locked with git worktree lock
+        sprout archive mybranch              # Keep mybranch's work but free its directory
+        cd "$(sprout restore mybranch)"      # Bring mybranch back and change to it
+        sprout export mybranch              # Write mybranch.sprout.tgz for a teammate
+        cd "$(sprout import fix.sprout.tgz)" # Carry on where a teammate left off
+        sprout pin release-2.x               # Keep a long-lived worktree out of every prune
+        sprout note fix/login waiting on QA  # Remember what a worktree is waiting on
+        sprout stack restack                 # Bring the parent's new commits into each branch above it
+        cd "$(sprout pr checkout ENG-123)"   # Pick up ENG-123's PR again after review comments
+        cd "$(sprout rename fxi fix)"        # Fix a typo and follow the worktree
+        sprout exec --parallel 4 git fetch   # Fetch in four worktrees at a time
+        sprout exec --status open -- npm ci  # Reinstall in worktrees with an open PR
+        sprout sparse set services/api libs  # Check out only these directories
+        sprout history --since 7d --json     # Last week's worktrees and commands as JSON
+        sprout today --all-repos             # Morning summary across every repo
+        sprout doctor --json                 # Health checks for scripts; exits 1 if one fails
+        git sprout create mybranch           # The same, once sprout init --git-alias has run
+        sprout upgrade --check               # See whether a newer release is out
+        gh auth token | sprout auth github   # Keep using gh's token once gh is gone
+        sprout --config ~/oss.json5 list     # Use a separate profile for open source work
+        sprout --repo api list               # List worktrees of the api repository from anywhere
+        SPROUT_OPEN_IN=tmux sprout create x  # Override one setting for a single run
       """
 
   Scenario: List worktrees when none exist
@@ -71,83 +265,2722 @@ Feature: Sprout CLI Commands
       """
       🌱 Active Worktrees
 
-      ┌───────────┬─────────┬────────┐
-      │BRANCH     │PR STATUS│COMMIT  │
-      ├───────────┼─────────┼────────┤
-      │feature-123│Open     │abc12345│
-      │bugfix-456 │Merged   │def67890│
-      └───────────┴─────────┴────────┘
+      ┌─┬───────────┬─────────┬────────┬────┐
+      │ │BRANCH     │PR STATUS│COMMIT  │SIZE│
+      ├─┼───────────┼─────────┼────────┼────┤
+      │●│feature-123│Open     │abc12345│-   │
+      │✓│bugfix-456 │Merged   │def67890│-   │
+      └─┴───────────┴─────────┴────────┴────┘
       """
 
-  Scenario: Doctor command shows configuration
+  Scenario: List marks worktrees with live tmux sessions
     Given a config with:
-      | key             | value        |
-      | default_command | code .       |
-      | linear_api_key  | <not_set>    |
-    When I run "sprout doctor"
+      | key     | value |
+      | open_in | tmux  |
+    And the following worktrees exist:
+      | branch      | commit   | pr_status |
+      | feature-123 | abc12345 | Open      |
+      | bugfix-4.5  | def67890 | Merged    |
+    And tmux session "bugfix-4-5" is running
+    When I run "sprout list"
     Then the output should be:
       """
-      🌱 Sprout Configuration
+      🌱 Active Worktrees
 
-        Default Command: code .
-        Resume Command: not configured
-        Linear API Key: not configured
-        Config Path: /Users/laurenkt/.sprout.json5
-        Config File: exists
+      ┌─┬───────────┬─────────┬────────┬────┬────┐
+      │ │BRANCH     │PR STATUS│COMMIT  │SIZE│TMUX│
+      ├─┼───────────┼─────────┼────────┼────┼────┤
+      │●│feature-123│Open     │abc12345│-   │-   │
+      │✓│bugfix-4.5 │Merged   │def67890│-   │live│
+      └─┴───────────┴─────────┴────────┴────┴────┘
+      """
 
-      Linear Integration
+  Scenario: List shows no live tmux sessions when tmux isn't installed
+    Given a config with:
+      | key     | value |
+      | open_in | tmux  |
+    And the following worktrees exist:
+      | branch      | commit   | pr_status |
+      | feature-123 | abc12345 | Open      |
+    And tmux isn't installed
+    When I run "sprout list"
+    Then the output should be:
+      """
+      🌱 Active Worktrees
 
-        API Key: not configured
-        Status: disabled
+      ┌─┬───────────┬─────────┬────────┬────┬────┐
+      │ │BRANCH     │PR STATUS│COMMIT  │SIZE│TMUX│
+      ├─┼───────────┼─────────┼────────┼────┼────┤
+      │●│feature-123│Open     │abc12345│-   │-   │
+      └─┴───────────┴─────────┴────────┴────┴────┘
       """
 
-  Scenario: Doctor command with Linear API key configured
-    Given a config with:
-      | key             | value                      |
-      | default_command | code .                     |
-      | linear_api_key  | lin_api_test123456789abc   |
-    When I run "sprout doctor"
+  Scenario: List worktrees from every registered repository
+    Given repo "api" has worktrees:
+      | branch      | commit   | pr_status |
+      | main        | 11111111 | -         |
+      | feature-123 | abc12345 | Open      |
+    And repo "web" has worktrees:
+      | branch     | commit   | pr_status |
+      | bugfix-456 | def67890 | Merged    |
+    When I run "sprout list --all-repos"
     Then the output should be:
       """
-      🌱 Sprout Configuration
+      🌱 Active Worktrees
 
-        Default Command: code .
-        Resume Command: not configured
-        Linear API Key: configured
-        Config Path: /Users/laurenkt/.sprout.json5
-        Config File: exists
+      ┌─┬────┬───────────┬─────────┬────────┬────┐
+      │ │REPO│BRANCH     │PR STATUS│COMMIT  │SIZE│
+      ├─┼────┼───────────┼─────────┼────────┼────┤
+      │●│api │feature-123│Open     │abc12345│-   │
+      │✓│web │bugfix-456 │Merged   │def67890│-   │
+      └─┴────┴───────────┴─────────┴────────┴────┘
+      """
 
-      Linear Integration
+  Scenario: List shows detached and locked worktrees
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | state    |
+      | feature-123 | abc12345 | Open      | locked   |
+      |             | 99887766 | -         | detached |
+      |             | 55555555 | -         | bare     |
+    When I run "sprout list"
+    Then the output should be:
+      """
+      🌱 Active Worktrees
 
-        API Key: lin_api_...9abc
-        Status: ✓ Connected
-        User: Test User (test@example.com)
-        Assigned Issues: 0 active tickets
+      ┌──┬──────────────────────┬─────────┬────────┬────┐
+      │  │BRANCH                │PR STATUS│COMMIT  │SIZE│
+      ├──┼──────────────────────┼─────────┼────────┼────┤
+      │●⚑│feature-123           │Open     │abc12345│-   │
+      │● │(detached at 99887766)│-        │99887766│-   │
+      └──┴──────────────────────┴─────────┴────────┴────┘
       """
 
-  Scenario: Unknown command shows error and help
-    When I run "sprout unknown"
+  Scenario: List shows CI status once an open PR has checks
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | ci      |
+      | feature-a | abc12345 | Open      | passing |
+      | feature-b | def67890 | Open      | failing |
+      | feature-c | 11223344 | Open      | pending |
+      | feature-d | 55667788 | No PR     |         |
+    When I run "sprout list"
+    Then the output should be:
+      """
+      🌱 Active Worktrees
+
+      ┌─┬─────────┬─────────┬──┬────────┬────┐
+      │ │BRANCH   │PR STATUS│CI│COMMIT  │SIZE│
+      ├─┼─────────┼─────────┼──┼────────┼────┤
+      │●│feature-a│Open     │✓ │abc12345│-   │
+      │●│feature-b│Open     │✗ │def67890│-   │
+      │●│feature-c│Open     │● │11223344│-   │
+      │●│feature-d│No PR    │- │55667788│-   │
+      └─┴─────────┴─────────┴──┴────────┴────┘
+      """
+
+  Scenario: List marks pinned worktrees with a pin
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | state  |
+      | feature-123 | abc12345 | Open      |        |
+      | release-2   | def67890 | Merged    | pinned |
+    When I run "sprout list"
+    Then the output should be:
+      """
+      🌱 Active Worktrees
+
+      ┌───┬───────────┬─────────┬────────┬────┐
+      │   │BRANCH     │PR STATUS│COMMIT  │SIZE│
+      ├───┼───────────┼─────────┼────────┼────┤
+      │●  │feature-123│Open     │abc12345│-   │
+      │✓📌│release-2  │Merged   │def67890│-   │
+      └───┴───────────┴─────────┴────────┴────┘
+      """
+
+  Scenario: List marks worktrees with uncommitted changes
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+      | bugfix-456  | def67890 | Merged    | /mock/worktrees/bug-456  |
+    And worktree "bugfix-456" has uncommitted changes
+    When I run "sprout list"
+    Then the output should be:
+      """
+      🌱 Active Worktrees
+
+      ┌──┬───────────┬─────────┬────────┬────┐
+      │  │BRANCH     │PR STATUS│COMMIT  │SIZE│
+      ├──┼───────────┼─────────┼────────┼────┤
+      │● │feature-123│Open     │abc12345│0 B │
+      │✓✗│bugfix-456 │Merged   │def67890│0 B │
+      └──┴───────────┴─────────┴────────┴────┘
+      """
+
+  Scenario: List sorts worktrees by their most recent commit
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     | updated    |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 | 2026-03-01 |
+      | bugfix-456  | def67890 | Merged    | /mock/worktrees/bug-456  | 2026-05-12 |
+      | spike-cache | bbb22222 | No PR     | /mock/worktrees/spike    |            |
+      | chore-789   | aaa11111 | Open      | /mock/worktrees/chore    | 2026-04-20 |
+    When I run "sprout list --porcelain --sort=age"
+    Then the output should be:
+      """
+      bugfix-456	/mock/worktrees/bug-456	Merged	def67890
+      chore-789	/mock/worktrees/chore	Open	aaa11111
+      feature-123	/mock/worktrees/feat-123	Open	abc12345
+      spike-cache	/mock/worktrees/spike	No PR	bbb22222
+      """
+
+  Scenario: List sorts worktrees by branch
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status |
+      | feature-123 | abc12345 | Open      |
+      | bugfix-456  | def67890 | Merged    |
+      | chore-789   | aaa11111 | Open      |
+    When I run "sprout list --sort branch"
+    Then the output should be:
+      """
+      🌱 Active Worktrees
+
+      ┌─┬───────────┬─────────┬────────┬────┐
+      │ │BRANCH     │PR STATUS│COMMIT  │SIZE│
+      ├─┼───────────┼─────────┼────────┼────┤
+      │✓│bugfix-456 │Merged   │def67890│-   │
+      │●│chore-789  │Open     │aaa11111│-   │
+      │●│feature-123│Open     │abc12345│-   │
+      └─┴───────────┴─────────┴────────┴────┘
+      """
+
+  Scenario: List sorts worktrees needing attention first
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | bugfix-456  | def67890 | Merged    | /mock/worktrees/bug-456  |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+      | chore-789   | aaa11111 | Open      | /mock/worktrees/chore    |
+    And worktree "chore-789" has uncommitted changes
+    When I run "sprout list --porcelain --sort=status"
+    Then the output should be:
+      """
+      chore-789	/mock/worktrees/chore	Open	aaa11111
+      feature-123	/mock/worktrees/feat-123	Open	abc12345
+      bugfix-456	/mock/worktrees/bug-456	Merged	def67890
+      """
+
+  Scenario: List keeps only worktrees with a given status
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+      | bugfix-456  | def67890 | Merged    | /mock/worktrees/bug-456  |
+      | chore-789   | aaa11111 | Open      | /mock/worktrees/chore    |
+    And worktree "feature-123" has uncommitted changes
+    When I run "sprout list --porcelain --status=active"
+    Then the output should be:
+      """
+      feature-123	/mock/worktrees/feat-123	Open	abc12345
+      chore-789	/mock/worktrees/chore	Open	aaa11111
+      """
+    When I run "sprout list --porcelain --status=merged"
+    Then the output should be:
+      """
+      bugfix-456	/mock/worktrees/bug-456	Merged	def67890
+      """
+    When I run "sprout list --porcelain --status=dirty"
+    Then the output should be:
+      """
+      feature-123	/mock/worktrees/feat-123	Open	abc12345
+      """
+
+  Scenario: List rejects an unknown sort order
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status |
+      | feature-123 | abc12345 | Open      |
+    When I run "sprout list --sort=size"
     Then the command should fail
     And the output should be:
       """
-      Sprout - Git Worktree Terminal UI
+      Error: --sort must be age, status or branch, got "size"
+      """
 
-      Usage:
-        sprout                              Start in interactive mode
-        sprout list                         List all worktrees
-        sprout create <branch>              Create worktree and output path
-        sprout create <branch> <command>    Create worktree and run command in it
-        sprout prune [branch]               Remove worktree(s) - all merged if no branch specified
-        sprout doctor                       Show configuration values
-        sprout help                         Show this help
+  Scenario: List porcelain leaves out detached worktrees
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     | state    |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |          |
+      |             | 99887766 | -         | /mock/worktrees/bisect   | detached |
+    When I run "sprout list --porcelain"
+    Then the output should be:
+      """
+      feature-123	/mock/worktrees/feat-123	Open	abc12345
+      """
+
+  Scenario: List porcelain prints a tab-separated line per worktree
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+      | bugfix-456  | def67890 | Merged    | /mock/worktrees/bug-456  |
+    When I run "sprout list --porcelain"
+    Then the output should be:
+      """
+      feature-123	/mock/worktrees/feat-123	Open	abc12345
+      bugfix-456	/mock/worktrees/bug-456	Merged	def67890
+      """
+
+  Scenario: List prints porcelain lines when stdout is piped
+    Given stdout is piped
+    And the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    When I run "sprout list"
+    Then the output should be:
+      """
+      feature-123	/mock/worktrees/feat-123	Open	abc12345
+      """
+
+  Scenario: The issue browser doesn't start when stdout is piped
+    Given stdout is piped
+    When I run "sprout issues"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: stdout is not a terminal, so the issue browser can't start. Run sprout issues in a terminal
+      """
+
+  Scenario: The issue browser takes no arguments
+    When I run "sprout issues ENG-12"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: issues takes no arguments. Usage: sprout issues
+      """
+
+  Scenario: Quiet list prints nothing when there are no worktrees
+    Given no worktrees exist
+    When I run "sprout list --quiet"
+    Then the output should be:
+      """
+      """
+
+  Scenario: The interactive UI doesn't start when stdout is piped
+    Given stdout is piped
+    When I run "sprout"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: stdout is not a terminal, so the interactive UI can't start. Run a command instead, e.g. sprout list --porcelain
+      """
+
+  Scenario: Quiet create prints only the worktree path
+    When I run "sprout create --quiet mybranch"
+    Then the output should be:
+      """
+      /mock/path/mybranch
+      """
+
+  Scenario: Prune merged worktrees in every registered repository
+    Given repo "api" has worktrees:
+      | branch      | commit   | pr_status |
+      | feature-123 | abc12345 | Merged    |
+    And repo "web" has worktrees:
+      | branch     | commit   | pr_status |
+      | bugfix-456 | def67890 | Merged    |
+    When I run "sprout prune --all-repos --yes"
+    Then merged worktrees should be pruned in repo "api"
+    And merged worktrees should be pruned in repo "web"
+
+  Scenario: Prune across repositories refuses a branch name
+    When I run "sprout prune feature-123 --all-repos"
+    Then the command should fail
+
+  Scenario: Clone sets up the bare repository layout
+    When I run "sprout clone git@github.com:acme/widgets.git"
+    Then "git@github.com:acme/widgets.git" should be cloned into "widgets"
+    And the output should be:
+      """
+      /mock/src/widgets/mainCloned git@github.com:acme/widgets.git into /mock/src/widgets
+        .bare/       bare repository
+        main/        worktree for main
+      New worktrees will be created beside main.
+
+      Already have a checkout of this repo? Run sprout migrate inside it to convert it in place.
+      """
+
+  Scenario: Clone into a named directory
+    When I run "sprout clone https://github.com/acme/widgets.git gadgets"
+    Then "https://github.com/acme/widgets.git" should be cloned into "gadgets"
+
+  Scenario: Clone requires a URL
+    When I run "sprout clone"
+    Then the command should fail
+
+  Scenario: Migrate dry run lists the steps without changing anything
+    When I run "sprout migrate --dry-run"
+    Then the checkout should not be migrated
+    And the output should be:
+      """
+      Converting /mock/repo to a bare repository with worktrees:
+        1. Move the working files aside
+        2. Move .git to .bare
+        3. Add a worktree for main
+      Dry run: nothing was changed.
+      """
+
+  Scenario: Migrate converts the checkout and outputs the new worktree
+    When I run "sprout migrate"
+    Then the checkout should be migrated
+    And the output should be:
+      """
+      /mock/repo/mainConverting /mock/repo to a bare repository with worktrees:
+        ✓ Move the working files aside
+        ✓ Move .git to .bare
+        ✓ Add a worktree for main
+      Done. main is now checked out at /mock/repo/main; cd there to keep working.
+      """
+
+  Scenario: A failed migration reports the rollback
+    Given migration fails at step 2
+    When I run "sprout migrate"
+    Then the command should fail
+    And the checkout should not be migrated
+    And the output should be:
+      """
+      Converting /mock/repo to a bare repository with worktrees:
+        ✓ Move the working files aside
+      Error: migration failed: Move .git to .bare: disk full; all changes were rolled back
+      """
+
+  Scenario: Exec runs a command in every worktree and summarises the results
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | main      | abc12345 | -         | /mock/repo                |
+      | feature-a | def67890 | Open      | /mock/worktrees/feature-a |
+      | feature-b | fed09876 | No PR     | /mock/worktrees/feature-b |
+    And the command exits with code 1 in "/mock/worktrees/feature-b"
+    When I run "sprout exec -- git fetch --prune"
+    Then the command should fail
+    And the output should be:
+      """
+      main      | git fetch --prune in /mock/repo
+      feature-a | git fetch --prune in /mock/worktrees/feature-a
+      feature-b | git fetch --prune in /mock/worktrees/feature-b
+      feature-b | failed with 1
+
+      main       ok
+      feature-a  ok
+      feature-b  exit 1
+      Error: git failed in 1 of 3 worktrees
+      """
+
+  Scenario: Exec only runs in worktrees matching the filters
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | main      | abc12345 | -         | /mock/repo                |
+      | eng-1-api | def67890 | Open      | /mock/worktrees/eng-1-api |
+      | eng-2-ui  | fed09876 | No PR     | /mock/worktrees/eng-2-ui  |
+      | spike     | 0a1b2c3d | Open      | /mock/worktrees/spike     |
+    When I run "sprout exec --parallel 2 --status open --match eng-* npm ci"
+    Then the output should be:
+      """
+      eng-1-api | npm ci in /mock/worktrees/eng-1-api
+
+      eng-1-api  ok
+      """
+
+  Scenario: Exec needs a command
+    When I run "sprout exec --parallel 2"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: command is required. Usage: sprout exec [--parallel N] [--status S] [--match GLOB] -- <command>
+      """
+
+  Scenario: Serve needs --stdio
+    When I run "sprout serve"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: sprout serve only speaks JSON-RPC over stdio. Usage: sprout serve --stdio
+      """
+
+  Scenario: Archive saves unmerged work before removing the worktree
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | def67890 | No PR     | /mock/worktrees/feature-a |
+    And the worktree has 2 unmerged commits, uncommitted changes and 1 untracked file
+    When I run "sprout archive feature-a"
+    Then the output should be:
+      """
+      /mock/worktrees/.archive/feature-a
+      Archived feature-a (2 unmerged commits, uncommitted changes, 1 untracked file). Restore it with: sprout restore feature-a
+      """
+
+  Scenario: Archive a worktree with nothing unmerged
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | def67890 | Merged    | /mock/worktrees/feature-a |
+    When I run "sprout archive feature-a"
+    Then the output should contain "Archived feature-a (nothing unmerged, so only its commit)"
+
+  Scenario: Archive needs a branch
+    When I run "sprout archive"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: branch name is required. Usage: sprout archive <branch-name>
+      """
+
+  Scenario: Restore outputs the recreated worktree's path
+    Given "feature-a" is archived
+    When I run "sprout restore feature-a"
+    Then the output should be:
+      """
+      /mock/path/feature-a
+      """
+
+  Scenario: Restore fails when there is no archive
+    When I run "sprout restore feature-a"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: no archive found for feature-a in /mock/path/.archive
+      """
+
+  Scenario: Export packs a worktree into one file for a teammate
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | def67890 | No PR     | /mock/worktrees/feature-a |
+    And the worktree has 2 unmerged commits, uncommitted changes and 1 untracked file
+    When I run "sprout export feature-a -o /handoff/feature-a.sprout.tgz"
+    Then the output should be:
+      """
+      /handoff/feature-a.sprout.tgz
+      Exported feature-a (2 unmerged commits, uncommitted changes, 1 untracked file). Pick it up with: sprout import feature-a.sprout.tgz
+      """
+
+  Scenario: Export needs a worktree
+    When I run "sprout export feature-a -o /handoff/feature-a.sprout.tgz"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: worktree does not exist: feature-a
+      """
+
+  Scenario: Import outputs the recreated worktree's path
+    When I run "sprout import /handoff/feature-a.sprout.tgz"
+    Then the output should contain "/mock/path/feature-a"
+    And the output should contain "Imported feature-a"
+
+  Scenario: Import needs a file
+    When I run "sprout import"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: file is required. Usage: sprout import <file>
+      """
+
+  Scenario: Undo brings back everything the last prune removed
+    Given the last prune removed "feature-a, feature-b"
+    When I run "sprout undo"
+    Then the output should be:
+      """
+      /mock/worktrees/feature-a
+      /mock/worktrees/feature-b
+      Restored 2 worktree(s)
+      """
+
+  Scenario: Undo with an empty trash
+    When I run "sprout undo"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: nothing to undo: no pruned worktrees are in the trash
+      Hint: sprout history shows what sprout removed and when
+      """
+
+  Scenario: Rename moves a worktree to its new branch name
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | fix-lgoin | def67890 | No PR     | /mock/worktrees/fix-lgoin |
+    When I run "sprout rename fix-lgoin fix-login"
+    Then the output should be:
+      """
+      /mock/path/fix-login
+      Renamed fix-lgoin to fix-login
+      """
+
+  Scenario: Rename refuses a branch name already in use
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | fix-lgoin | def67890 | No PR     | /mock/worktrees/fix-lgoin |
+    And branch "fix-login" already exists
+    When I run "sprout rename fix-lgoin fix-login"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: branch fix-login already exists
+      """
+
+  Scenario: Rename needs both names
+    When I run "sprout rename fix-lgoin"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: old and new branch names are required. Usage: sprout rename <old-branch> <new-branch>
+      """
+
+  Scenario: Repair reports every fix
+    Given repair finds:
+      | kind           | value                             |
+      | pruned         | /mock/worktrees/old-spike         |
+      | forgotten      | old-spike                         |
+      | missing branch | /mock/worktrees/gone feature-gone |
+    When I run "sprout repair"
+    Then the output should be:
+      """
+      Removed git's records of deleted worktrees:
+        /mock/worktrees/old-spike
+      Marked worktrees that no longer exist as pruned:
+        old-spike
+      Worktrees whose branch no longer exists (remove with git worktree remove <path>):
+        /mock/worktrees/gone (feature-gone)
+      """
+
+  Scenario: Repair with nothing to fix
+    When I run "sprout repair"
+    Then the output should be:
+      """
+      Nothing to repair.
+      """
+
+  Scenario: List shows the status of each worktree's linked ticket
+    Given a config with:
+      | key            | value    |
+      | linear_api_key | test-key |
+    And the following worktrees exist:
+      | branch          | commit   | pr_status |
+      | eng-12-login    | abc12345 | Open      |
+      | eng-34-checkout | def67890 | Merged    |
+      | eng-56-cleanup  | aaa11111 | Merged    |
+      | spike-cache     | bbb22222 | No PR     |
+    And the linked tickets have statuses:
+      | identifier | status      | type      |
+      | ENG-12     | In Progress | started   |
+      | ENG-34     | In Progress | started   |
+      | ENG-56     | Done        | completed |
+    When I run "sprout list"
+    Then the output should be:
+      """
+      🌱 Active Worktrees
+
+      ┌─┬───────────────┬─────────┬────────────────────┬────────┬────┐
+      │ │BRANCH         │PR STATUS│TICKET              │COMMIT  │SIZE│
+      ├─┼───────────────┼─────────┼────────────────────┼────────┼────┤
+      │●│eng-12-login   │Open     │ENG-12 In Progress  │abc12345│-   │
+      │✓│eng-34-checkout│Merged   │ENG-34 In Progress ⚠│def67890│-   │
+      │✓│eng-56-cleanup │Merged   │ENG-56 Done         │aaa11111│-   │
+      │●│spike-cache    │No PR    │-                   │bbb22222│-   │
+      └─┴───────────────┴─────────┴────────────────────┴────────┴────┘
+      """
+
+  Scenario: Create opens the new worktree in a tmux session
+    Given a config with:
+      | key             | value  |
+      | open_in         | tmux   |
+      | default_command | code . |
+    When I run "sprout create mybranch"
+    Then tmux should open "mybranch /mock/path/mybranch code ."
+
+  Scenario: Create runs an explicit command inside the tmux session
+    Given a config with:
+      | key     | value |
+      | open_in | tmux  |
+    When I run "sprout create mybranch git status"
+    Then tmux should open "mybranch /mock/path/mybranch git status"
+
+  Scenario: Switch attaches to the worktree's tmux session
+    Given a config with:
+      | key     | value |
+      | open_in | tmux  |
+    And the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    When I run "sprout switch feature-123"
+    Then tmux should open "feature-123 /mock/worktrees/feat-123"
+
+  Scenario: Create remembers the command it runs for resume
+    Given a config with:
+      | key     | value |
+      | open_in | tmux  |
+    When I run "sprout create mybranch claude --model opus"
+    Then the last command in "mybranch" should be "claude --model opus"
+
+  Scenario: Resume runs the command run most recently again
+    Given a config with:
+      | key     | value |
+      | open_in | tmux  |
+    And the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+      | feature-456 | def67890 | Open      | /mock/worktrees/feat-456 |
+    And these commands were last run:
+      | branch      | command     | ran_at           |
+      | feature-123 | claude      | 2024-01-02 09:00 |
+      | feature-456 | npm run dev | 2024-01-02 11:00 |
+      | removed     | make test   | 2024-01-02 12:00 |
+    When I run "sprout resume"
+    Then tmux should open "feature-456 /mock/worktrees/feat-456 npm run dev"
+
+  Scenario: Resume runs the last command in a given worktree again
+    Given a config with:
+      | key     | value |
+      | open_in | tmux  |
+    And the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+      | feature-456 | def67890 | Open      | /mock/worktrees/feat-456 |
+    And these commands were last run:
+      | branch      | command     | ran_at           |
+      | feature-123 | claude      | 2024-01-02 09:00 |
+      | feature-456 | npm run dev | 2024-01-02 11:00 |
+    When I run "sprout resume feature-123"
+    Then tmux should open "feature-123 /mock/worktrees/feat-123 claude"
+
+  Scenario: Resume falls back to the resume command in a worktree nothing was run in
+    Given a config with:
+      | key            | value                        |
+      | open_in        | tmux                         |
+      | resume_command | claude --resume $BRANCH_NAME |
+    And the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    When I run "sprout resume feature-123"
+    Then tmux should open "feature-123 /mock/worktrees/feat-123 claude --resume feature-123"
+    And the last command in "feature-123" should be "claude --resume feature-123"
+
+  Scenario: Resume explains when nothing has been run yet
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    When I run "sprout resume"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: nothing to resume: sprout hasn't run a command in any of this repository's worktrees
+      Hint: sprout create <branch> <command> runs one and remembers it, as does the default command
+      """
+
+  Scenario: Where prints the worktree, repository and worktrees root
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    When I run "sprout where feature-123"
+    Then the output should be:
+      """
+      Worktree:    /mock/worktrees/feat-123
+      Repository:  /mock/repo
+      Worktrees:   /mock/path
+      """
+
+  Scenario: Where says where a worktree would go without creating it
+    When I run "sprout where --porcelain fix/login"
+    Then the output should be:
+      """
+      /mock/path/fix/login	/mock/repo	/mock/path	missing
+      """
+    And no worktree should be created
+
+  Scenario: Where uses the branch checked out here when none is named
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    And I am in the worktree for "feature-123"
+    When I run "sprout where --exists --porcelain"
+    Then the output should be:
+      """
+      /mock/worktrees/feat-123	/mock/repo	/mock/path	created
+      """
+
+  Scenario: Where fails with --exists when there's no worktree
+    When I run "sprout where --exists fix/login"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: no worktree for branch fix/login
+      Hint: sprout create fix/login makes one at /mock/path/fix/login
+      """
+
+  Scenario: Resume a branch without a worktree
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    When I run "sprout resume feature-999"
+    Then the command should fail
+    And the output should contain "no worktree found for branch feature-999"
+
+  Scenario: Switch without tmux outputs the worktree path
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    When I run "sprout switch feature-123"
+    Then the output should be:
+      """
+      /mock/worktrees/feat-123
+      """
+
+  Scenario: Switching to a worktree starts its timer
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    When I run "sprout switch feature-123"
+    Then the timer should have started for "feature-123"
+
+  Scenario: Switch fails when the branch has no worktree
+    Given no worktrees exist
+    When I run "sprout switch feature-123"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: no worktree found for branch feature-123
+      Hint: sprout create feature-123 makes one
+      """
+
+  Scenario: PR checkout makes a worktree for the PR an issue was worked on in
+    Given no worktrees exist
+    And these pull requests exist:
+      | number | title                   | branch            | state |
+      | 42     | ENG-123 Fix login retry | eng-123-fix-login | Open  |
+    When I run "sprout pr checkout ENG-123"
+    Then the output should contain "Checked out PR #42 (ENG-123 Fix login retry) at: /mock/path/eng-123-fix-login"
+
+  Scenario: PR checkout reuses the PR's worktree and opens it in the editor
+    Given the following worktrees exist:
+      | branch            | commit   | pr_status | path                      |
+      | eng-123-fix-login | abc12345 | Open      | /mock/worktrees/fix-login |
+    And these pull requests exist:
+      | number | title                   | branch            | state |
+      | 42     | ENG-123 Fix login retry | eng-123-fix-login | Open  |
+    And the installed editors are "code"
+    When I run "sprout pr checkout --open code #42"
+    Then the editor should open "code /mock/worktrees/fix-login"
+    And the output should contain "Updated PR #42 (ENG-123 Fix login retry) at: /mock/worktrees/fix-login"
+
+  Scenario: PR checkout refuses a merged PR
+    Given no worktrees exist
+    And these pull requests exist:
+      | number | title          | branch     | state  |
+      | 7      | Tidy the cache | tidy-cache | Merged |
+    When I run "sprout pr checkout tidy-cache"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: PR #7 was merged already; sprout create starts something new
+      """
+
+  Scenario: PR checkout fails when nothing matches
+    Given no worktrees exist
+    When I run "sprout pr checkout ENG-999"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: no pull request found for ENG-999
+      """
+
+  Scenario: Create reuses an existing worktree by default
+    Given a config with:
+      | key     | value |
+      | open_in | tmux  |
+    And the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    When I run "sprout create feature-123"
+    Then tmux should open "feature-123 /mock/worktrees/feat-123"
+    And the output should be:
+      """
+      Reusing existing worktree at: /mock/worktrees/feat-123
+      """
+
+  Scenario: Create fails on an existing worktree when asked to
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    When I run "sprout create --existing=fail feature-123"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: a worktree for feature-123 already exists at /mock/worktrees/feat-123
+      Hint: sprout switch feature-123 goes to it, as does sprout create --existing=open feature-123
+      Hint: sprout create --suffix-on-conflict feature-123 starts afresh beside it, as feature-123-2
+      """
+
+  Scenario: Create fails on an existing branch when asked to
+    Given branch "feature-123" already exists
+    When I run "sprout create --existing=fail feature-123"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: branch feature-123 already exists
+      Hint: sprout create --existing=reuse feature-123 checks it out in a new worktree
+      Hint: sprout create --suffix-on-conflict feature-123 starts afresh beside it, as feature-123-2
+      """
+
+  Scenario: Create starts afresh beside an existing branch when asked to
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    And branch "feature-123-2" already exists
+    When I run "sprout create --suffix-on-conflict feature-123"
+    Then the output should contain "feature-123 already exists, creating feature-123-3 instead"
+    And the output should contain "/mock/path/feature-123-3"
+
+  Scenario: Create opens an existing worktree instead of creating one
+    Given a config with:
+      | key     | value |
+      | open_in | tmux  |
+    And the following worktrees exist:
+      | branch      | commit   | pr_status | path                     |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feat-123 |
+    When I run "sprout create --existing=open feature-123"
+    Then tmux should open "feature-123 /mock/worktrees/feat-123"
+    And the output should be:
+      """
+      A worktree for feature-123 already exists, opening it
+      """
+
+  Scenario: Create with an unknown existing mode fails
+    When I run "sprout create --existing=skip feature-123"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: --existing must be fail, open or reuse, got "skip"
+      """
+
+  Scenario: Create refuses a reserved branch name
+    When I run "sprout create master"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: branch name is reserved: master is the repository's main line; pick another name
+      """
+
+  Scenario: Create refuses a branch name with nothing git can use
+    When I run "sprout create ???"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: branch name is empty once the characters git can't use are removed from "???"
+      Hint: branch names are made of letters, numbers, -, . and /, such as fix/login-redirect
+      """
+
+  Scenario: Doctor command shows configuration
+    Given a config with:
+      | key             | value        |
+      | default_command | code .       |
+      | linear_api_key  | <not_set>    |
+    When I run "sprout doctor"
+    Then the output should be:
+      """
+      🌱 Sprout Configuration
+
+        Default Command: code .
+        Resume Command: not configured
+        Issue Provider: linear (not connected)
+        PR Status: gh
+        Git: 2.43.0
+        Linear API Key: not configured
+        Config Path: /Users/laurenkt/.sprout.json5
+        Config File: exists
+        Editors: none detected
+
+      Linear Integration
+
+        API Key: not configured
+        Status: disabled
+      """
+
+  Scenario: Doctor explains how the default command is picked
+    Given a config with:
+      | key             | value     |
+      | default_command | code .    |
+      | linear_api_key  | <not_set> |
+    And the default command for this repository is "nvim"
+    And the default command for branches "spike/*" is "bash"
+    When I run "sprout doctor"
+    Then the output should contain "Default Command: nvim (this repository's)"
+    And the output should contain "first set of: the template's defaultCommand, defaultCommands.branches (longest matching pattern), defaultCommands.repos, defaultCommand"
+    And the output should contain "spike/*: bash"
+
+  Scenario: Doctor lists the settings overridden from the environment
+    Given a config with:
+      | key             | value        |
+      | default_command | code .       |
+      | linear_api_key  | <not_set>    |
+    And the environment variable "SPROUT_OPEN_IN" is "tmux"
+    And the environment variable "SPROUT_TRASH_DAYS" is "3"
+    When I run "sprout doctor"
+    Then the output should contain "Env Overrides: SPROUT_OPEN_IN, SPROUT_TRASH_DAYS"
+
+  Scenario: Doctor command with Linear API key configured
+    Given a config with:
+      | key             | value                      |
+      | default_command | code .                     |
+      | linear_api_key  | lin_api_test123456789abc   |
+    When I run "sprout doctor"
+    Then the output should be:
+      """
+      🌱 Sprout Configuration
+
+        Default Command: code .
+        Resume Command: not configured
+        Issue Provider: linear
+        PR Status: gh
+        Git: 2.43.0
+        Linear API Key: configured
+        Config Path: /Users/laurenkt/.sprout.json5
+        Config File: exists
+        Editors: none detected
+
+      Linear Integration
+
+        API Key: lin_api_...9abc
+        Status: ✓ Connected
+        User: Test User (test@example.com)
+        Assigned Issues: 0 active tickets
+      """
+
+  Scenario: Doctor lists each Linear workspace
+    Given a config with:
+      | key              | value                           |
+      | default_command  | <not_set>                       |
+      | linear_api_key   | lin_api_test123456789abc        |
+      | linear_workspace | client=lin_api_client987654wxyz |
+    When I run "sprout doctor"
+    Then the output should be:
+      """
+      🌱 Sprout Configuration
+
+        Default Command: not configured
+        Resume Command: not configured
+        Issue Provider: linear
+        PR Status: gh
+        Git: 2.43.0
+        Linear API Key: configured
+        Config Path: /Users/laurenkt/.sprout.json5
+        Config File: exists
+        Editors: none detected
+
+      Linear Integration
+
+        Workspace client: lin_api_...wxyz
+        Workspace default: lin_api_...9abc
+        Status: ✓ Connected
+        User: Test User (test@example.com)
+        Assigned Issues: 0 active tickets
+      """
+
+  Scenario: Auth signs in to Linear with OAuth
+    Given a config with:
+      | key                    | value     |
+      | linear_oauth_client_id | client-id |
+    When I run "sprout auth linear"
+    Then the output should be:
+      """
+      Signed in to Linear
+      """
+    And the Linear sign-in should be stored
+
+  Scenario: Auth needs a Linear OAuth application
+    When I run "sprout auth linear"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: linearOAuthClientId is not configured. Create an OAuth application in Linear (Settings > API) with the callback URL http://localhost:47823/callback and add its client ID to /Users/laurenkt/.sprout.json5
+      """
+
+  Scenario: Auth logout forgets the Linear sign-in
+    Given a config with:
+      | key                    | value     |
+      | linear_oauth_client_id | client-id |
+    And I am signed in to Linear
+    When I run "sprout auth linear --logout"
+    Then the output should be:
+      """
+      Signed out of Linear
+      """
+    And the Linear sign-in should be removed
+
+  Scenario: Doctor shows the Linear OAuth sign-in
+    Given a config with:
+      | key                    | value     |
+      | default_command        | <not_set> |
+      | linear_oauth_client_id | client-id |
+    And I am signed in to Linear
+    When I run "sprout doctor"
+    Then the output should be:
+      """
+      🌱 Sprout Configuration
+
+        Default Command: not configured
+        Resume Command: not configured
+        Issue Provider: linear
+        PR Status: gh
+        Git: 2.43.0
+        Linear API Key: not needed, signing in with OAuth
+        Config Path: /Users/laurenkt/.sprout.json5
+        Config File: exists
+        Editors: none detected
+
+      Linear Integration
+
+        Auth: OAuth (signed in, refreshed automatically)
+        Status: ✓ Connected
+        User: Test User (test@example.com)
+        Assigned Issues: 0 active tickets
+      """
+
+  Scenario: Doctor says when Linear OAuth hasn't signed in yet
+    Given a config with:
+      | key                    | value     |
+      | linear_oauth_client_id | client-id |
+    When I run "sprout doctor"
+    Then the output should contain "Auth: OAuth (not signed in)"
+    And the output should contain "Status: disabled - run 'sprout auth linear' to sign in"
+
+  Scenario: Auth stores a GitHub token from stdin
+    Given I will answer "ghp_test123"
+    When I run "sprout auth github"
+    Then the output should be:
+      """
+      Stored the GitHub token
+      """
+    And the stored GitHub token should be "ghp_test123"
+
+  Scenario: Auth needs a GitHub token on stdin
+    When I run "sprout auth github"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: no token given. Pass one on stdin, e.g. gh auth token | sprout auth github
+      """
+
+  Scenario: Auth logout forgets the GitHub token
+    Given a GitHub token is stored
+    When I run "sprout auth github --logout"
+    Then the output should be:
+      """
+      Removed the stored GitHub token
+      """
+    And the stored GitHub token should be ""
+
+  Scenario: Doctor shows PR status coming from the GitHub API without gh
+    Given the GitHub CLI is not installed
+    And a GitHub token is stored
+    When I run "sprout doctor"
+    Then the output should contain "PR Status: GitHub API (token from the keychain)"
+
+  Scenario: Doctor says when the GitHub API has no token
+    Given a config with:
+      | key             | value |
+      | github_provider | api   |
+    When I run "sprout doctor"
+    Then the output should contain "PR Status: GitHub API (no token) - set GH_TOKEN or run 'sprout auth github'"
+
+  Scenario: Doctor warns of the disk used by worktrees beside the main checkout
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                       | size |
+      | main        | abc12345 | -         | /mock/repo                 | 80GB |
+      | feature-123 | def67890 | Open      | /mock/worktrees/feat-123   | 30GB |
+      | bugfix-456  | 0a1b2c3d | Merged    | /mock/worktrees/bugfix-456 | 30GB |
+    And the worktrees' sizes were measured
+    When I run "sprout doctor"
+    Then the output should contain "Worktree Disk Usage: 60.0 GB across 2 worktree(s)"
+
+  Scenario: Doctor leaves the main checkout out of the disk used by worktrees
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                     | size |
+      | main        | abc12345 | -         | /mock/repo               | 80GB |
+      | feature-123 | def67890 | Open      | /mock/worktrees/feat-123 | 10GB |
+    And the worktrees' sizes were measured
+    When I run "sprout doctor"
+    Then the output should not contain "Worktree Disk Usage"
+
+  Scenario: Doctor lists installed editors and the repo's editor
+    Given a config with:
+      | key             | value     |
+      | default_command | <not_set> |
+      | linear_api_key  | <not_set> |
+    And the installed editors are "code, idea"
+    And the repo config opens worktrees in "idea"
+    When I run "sprout doctor"
+    Then the output should be:
+      """
+      🌱 Sprout Configuration
+
+        Default Command: not configured
+        Resume Command: not configured
+        Issue Provider: linear (not connected)
+        PR Status: gh
+        Git: 2.43.0
+        Linear API Key: not configured
+        Config Path: /Users/laurenkt/.sprout.json5
+        Config File: exists
+        Editors: code, idea
+        Open In: idea
+
+      Linear Integration
+
+        API Key: not configured
+        Status: disabled
+      """
+
+  Scenario: Doctor warns about worktrees whose git hooks won't run
+    Given a config with:
+      | key             | value     |
+      | default_command | <not_set> |
+      | linear_api_key  | <not_set> |
+    And the git hooks check reports "feature-a: hooks directory .husky/_ is missing"
+    When I run "sprout doctor"
+    Then the output should contain "Git Hooks: hooks won't run in some worktrees - set gitHooks to link or copy in .sprout.json5 for new ones"
+    And the output should contain "    feature-a: hooks directory .husky/_ is missing"
+
+  Scenario: Doctor prints its checks as JSON
+    Given a config with:
+      | key             | value                    |
+      | default_command | code .                   |
+      | linear_api_key  | lin_api_test123456789abc |
+    When I run "sprout doctor --json"
+    Then the doctor check "Git" should be "ok"
+    And the doctor check "Default Command" should be "ok"
+    And the doctor check "Status" should be "ok"
+    And the doctor check "Status" should be in section "linear"
+    And the doctor report should be ok
+
+  Scenario: Doctor fails when git is missing
+    Given the installed git version is ""
+    When I run "sprout doctor --json"
+    Then the command should fail
+    And the doctor check "Git" should be "fail" with fix "install git and make sure it's on your PATH"
+    And the doctor report should not be ok
+    And the output should contain "Error: 1 critical check(s) failed"
+
+  Scenario: Doctor warns about an old git
+    Given the installed git version is "2.20.1"
+    When I run "sprout doctor"
+    Then the output should contain "Git: 2.20.1 - sparse checkouts need git 2.25.0 or later"
+
+  Scenario: Doctor fails when Linear can't be reached
+    Given a config with:
+      | key            | value                    |
+      | linear_api_key | lin_api_test123456789abc |
+    And Linear can't be reached
+    When I run "sprout doctor"
+    Then the command should fail
+    And the output should contain "Status: can't connect: connection refused - check the API key, or run 'sprout auth linear' to sign in again"
+
+  Scenario: Doctor rejects arguments
+    When I run "sprout doctor now"
+    Then the command should fail
+    And the output should contain "unexpected arguments: now. Usage: sprout doctor [--json]"
+
+  Scenario: Config resolved shows where each setting came from
+    Given the config file ".sprout.json5" contains:
+      """
+      {
+        defaultCommand: "code .",
+        openIn: "tmux",
+        linearApiKey: "lin_api_1234567890abcdef",
+        templates: { "fix/": { base: "main", hooks: ["make setup"] } },
+        include: ["work.json5", "home.json5"],
+        overrides: [
+          { when: { env: "SPROUT_SCENARIO_CI" }, issueProvider: "none" },
+        ],
+      }
+      """
+    And the config file "work.json5" contains:
+      """
+      {
+        defaultCommand: "idea .",
+        openIn: null,
+        templates: { "fix/": { hooks: ["npm ci"] } },
+      }
+      """
+    And the environment variable "SPROUT_SCENARIO_CI" is "1"
+    And the environment variable "SPROUT_TRASH_DAYS" is "3"
+    When I run "sprout config resolved"
+    Then the output should be:
+      """
+      🌱 Config files, each winning over those above it
+
+        ~/.sprout.json5
+        ~/work.json5
+        ~/home.json5 (included, not found)
+
+      🌱 Settings
+
+        defaultCommand        "idea ."           # ~/work.json5
+        issueProvider         "none"             # ~/.sprout.json5 overrides[0]
+        linearApiKey          "lin_api_...cdef"  # ~/.sprout.json5
+        templates.fix/.base   "main"             # ~/.sprout.json5
+        templates.fix/.hooks  ["npm ci"]         # ~/work.json5
+        trashDays             3                  # SPROUT_TRASH_DAYS
+      """
+
+  Scenario: Config resolved reports an include cycle
+    Given the config file ".sprout.json5" contains:
+      """
+      { include: "work.json5" }
+      """
+    And the config file "work.json5" contains:
+      """
+      { include: ".sprout.json5" }
+      """
+    When I run "sprout config resolved"
+    Then the command should fail
+    And the output should contain "config files include each other: ~/.sprout.json5 -> ~/work.json5 -> ~/.sprout.json5"
+
+  Scenario: Config needs a subcommand
+    When I run "sprout config"
+    Then the command should fail
+    And the output should contain "subcommand is required. Usage: sprout config resolved [--json]"
+
+  Scenario: Doctor reports when issue integration is off
+    Given a config with:
+      | key             | value     |
+      | default_command | <not_set> |
+      | linear_api_key  | <not_set> |
+      | issue_provider  | none      |
+    When I run "sprout doctor"
+    Then the output should contain "Issue Provider: none (issue integration off)"
+
+  Scenario: Doctor hints that a newer release is available
+    Given a config with:
+      | key             | value     |
+      | default_command | <not_set> |
+      | linear_api_key  | <not_set> |
+    And the installed version is "v1.2.0"
+    And the latest release is "v1.4.0"
+    When I run "sprout doctor"
+    Then the output should be:
+      """
+      🌱 Sprout Configuration
+
+        Default Command: not configured
+        Resume Command: not configured
+        Issue Provider: linear (not connected)
+        PR Status: gh
+        Git: 2.43.0
+        Linear API Key: not configured
+        Config Path: /Users/laurenkt/.sprout.json5
+        Config File: exists
+        Editors: none detected
+        Update: v1.4.0 available (installed v1.2.0) - run 'sprout upgrade'
+
+      Linear Integration
+
+        API Key: not configured
+        Status: disabled
+      """
+
+  Scenario: Install git-sprout so git runs sprout as a subcommand
+    Given sprout is installed in a directory on the PATH
+    When I run "sprout init --git-alias"
+    Then git-sprout should link to sprout
+    And the output should contain "so git sprout create <branch> works too"
+    And the output should not contain "Warning"
+
+  Scenario: Installing git-sprout again leaves it in place
+    Given sprout is installed in a directory on the PATH
+    And git-sprout is installed
+    When I run "sprout init --git-alias"
+    Then git-sprout should link to sprout
+    And the output should contain "git-sprout is already installed"
+
+  Scenario: Installing git-sprout warns when git won't find it
+    Given sprout is installed in a directory not on the PATH
+    When I run "sprout init --git-alias"
+    Then git-sprout should link to sprout
+    And the output should contain "isn't on your PATH, so git won't find git-sprout there"
+
+  Scenario: Installing git-sprout leaves another program of that name alone
+    Given sprout is installed in a directory on the PATH
+    And another program is installed as git-sprout
+    When I run "sprout init --git-alias"
+    Then the command should fail
+    And the output should contain "git-sprout already exists and isn't a link to sprout; remove it first"
+
+  Scenario: Init says what it can set up
+    When I run "sprout init"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: nothing to set up. Usage: sprout init --git-alias [--dir <dir>]
+      """
+
+  Scenario: Upgrade installs the latest release
+    Given the installed version is "v1.2.0"
+    And the latest release is "v1.4.0"
+    When I run "sprout upgrade"
+    Then sprout should be upgraded to "v1.4.0"
+    And the output should be:
+      """
+      Upgraded sprout from v1.2.0 to v1.4.0
+      Downloading sprout v1.4.0...
+      """
+
+  Scenario: Upgrade check only reports the newer release
+    Given the installed version is "v1.2.0"
+    And the latest release is "v1.4.0"
+    When I run "sprout upgrade --check"
+    Then sprout should be upgraded to ""
+    And the output should be:
+      """
+      sprout v1.4.0 is available (installed v1.2.0). Run sprout upgrade to install it
+      """
+
+  Scenario: Upgrade leaves an up to date binary alone
+    Given the installed version is "v1.4.0"
+    And the latest release is "v1.4.0"
+    When I run "sprout upgrade"
+    Then sprout should be upgraded to ""
+    And the output should be:
+      """
+      sprout v1.4.0 is up to date
+      """
+
+  Scenario: Upgrade refuses to replace a build from source
+    Given the latest release is "v1.4.0"
+    When I run "sprout upgrade"
+    Then the command should fail
+    And sprout should be upgraded to ""
+    And the output should be:
+      """
+      Error: this sprout was built from source, so it can't replace itself with a release; the latest is v1.4.0 at https://github.com/laurenkt/sprout/releases/tag/v1.4.0
+      """
+
+  Scenario: Version reports build and tool versions as JSON
+    Given the installed version is "v1.2.0"
+    And the GitHub CLI is not installed
+    When I run "sprout version --json"
+    Then the JSON field "version" should be "v1.2.0"
+    And the JSON field "git" should be "2.43.0"
+    And the JSON field "gh" should be ""
+    And the JSON field "commit" should be ""
+
+  Scenario: Version describes the build
+    Given the installed version is "v1.2.0"
+    When I run "sprout version"
+    Then the output should contain "sprout v1.2.0"
+    And the output should contain "commit: unknown"
+    And the output should contain "git:    2.43.0"
+    And the output should contain "gh:     2.40.1"
+
+  Scenario: Commands warn when git is too old for worktrees
+    Given the installed git version is "2.5.0"
+    When I run "sprout switch feature-123"
+    Then the output should contain "Warning: sprout switch relies on git worktree list --porcelain, which needs git 2.7.0 or later (found 2.5.0)"
+
+  Scenario: Sparse paths warn when git is too old for cone mode
+    Given the installed git version is "2.20.1"
+    When I run "sprout create --paths api mybranch"
+    Then the output should contain "Warning: sprout create --paths relies on git sparse-checkout --cone, which needs git 2.25.0 or later (found 2.20.1)"
+
+  Scenario: A recent git gets no warning
+    When I run "sprout create --paths api mybranch"
+    Then the output should not contain "Warning"
+
+  Scenario: Create applies the template the branch name matches
+    Given the config has a template "fix/" with:
+      | key           | value                 |
+      | base          | develop               |
+      | sparseProfile | api                   |
+      | hooks         | make setup; npm ci    |
+    And I run "sprout sparse set --profile api services/api"
+    When I run "sprout create fix/login"
+    Then the worktree should be created from "develop" with hooks "make setup; npm ci"
+    And the worktree should be created with sparse directories "services/api"
+
+  Scenario: Create with --template adds the prefix and uses the template's default command
+    Given a config with:
+      | key             | value  |
+      | open_in         | tmux   |
+      | default_command | code . |
+    And the config has a template "spike/" with:
+      | key            | value  |
+      | defaultCommand | claude |
+    When I run "sprout create --template spike/ caching"
+    Then tmux should open "spike/caching /mock/path/spike/caching claude"
+
+  Scenario: Create uses the default command for the branch's pattern over the repository's
+    Given a config with:
+      | key             | value  |
+      | open_in         | tmux   |
+      | default_command | code . |
+    And the default command for this repository is "nvim"
+    And the default command for branches "spike/*" is "bash"
+    When I run "sprout create spike/caching"
+    Then tmux should open "spike/caching /mock/path/spike/caching bash"
+
+  Scenario: Create uses the repository's default command over defaultCommand
+    Given a config with:
+      | key             | value  |
+      | open_in         | tmux   |
+      | default_command | code . |
+    And the default command for this repository is "nvim"
+    And the default command for branches "spike/*" is "bash"
+    When I run "sprout create fix/login"
+    Then tmux should open "fix/login /mock/path/fix/login nvim"
+
+  Scenario: Create --detach starts the command in the background and returns
+    Given no worktrees exist
+    When I run "sprout create --detach feature-123 npm run dev"
+    Then "npm run dev in /mock/path/feature-123" should be started in the background
+    And the output should contain "Started npm run dev in the background (PID 4242), logging to"
+    And the output should contain "sprout prune feature-123 stops it along with the worktree"
+    And the output should contain "/mock/path/feature-123"
+
+  Scenario: The detach config runs the default command in the background
+    Given a config with:
+      | key             | value |
+      | default_command | make  |
+      | detach          | true  |
+    When I run "sprout create feature-123"
+    Then "make in /mock/path/feature-123" should be started in the background
+
+  Scenario: Create --detach with tmux starts the session without attaching
+    Given a config with:
+      | key             | value  |
+      | open_in         | tmux   |
+      | default_command | claude |
+    When I run "sprout create --detach feature-123"
+    Then tmux should start "feature-123 /mock/path/feature-123 claude" without attaching
+    And the output should contain "Started claude in tmux session feature-123; tmux attach -t feature-123 to watch it"
+
+  Scenario: Create --detach without a command outputs the worktree path
+    Given no worktrees exist
+    When I run "sprout create --detach feature-123"
+    Then nothing should be started in the background
+    And the output should contain "Worktree ready at: /mock/path/feature-123"
+
+  Scenario: Branches matching no template are created as usual
+    Given the config has a template "fix/" with:
+      | key  | value   |
+      | base | develop |
+    When I run "sprout create feature/login"
+    Then the worktree should be created from "" with hooks ""
+
+  Scenario: Create takes the branch piped in and outputs only the path
+    Given the following is piped in:
+      """
+
+      fix/login-bug
+      """
+    When I run "sprout create -"
+    Then the output should be:
+      """
+      /mock/path/fix/login-bug
+      """
+
+  Scenario: Create takes an issue piped in and names the branch after it
+    Given I am assigned these Linear issues:
+      | identifier | title         | parent |
+      | ENG-12     | Add audit log |        |
+    And the following is piped in:
+      """
+      ENG-12
+      """
+    When I run "sprout create --issue-from-stdin"
+    Then the output should be:
+      """
+      /mock/path/eng-12-add-audit-log
+      """
+
+  Scenario: Create gives an issue piped in the prefix of the template its labels match
+    Given the config has a template "fix/" with:
+      | key    | value   |
+      | base   | develop |
+      | labels | bug     |
+    And I am assigned these Linear issues:
+      | identifier | title         | parent | labels |
+      | ENG-12     | Add audit log |        | bug    |
+    And the following is piped in:
+      """
+      ENG-12
+      """
+    When I run "sprout create --issue-from-stdin"
+    Then the output should be:
+      """
+      /mock/path/fix/eng-12-add-audit-log
+      """
+    And the worktree should be created from "develop" with hooks ""
+
+  Scenario: Create from stdin leaves the default command and tmux for later
+    Given a config with:
+      | key             | value  |
+      | open_in         | tmux   |
+      | default_command | code . |
+    And the following is piped in:
+      """
+      mybranch
+      """
+    When I run "sprout create -"
+    Then the output should be:
+      """
+      /mock/path/mybranch
+      """
+
+  Scenario: Create from stdin needs something piped in
+    Given the following is piped in:
+      """
+      """
+    When I run "sprout create -"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: nothing was piped in. Pipe a branch name, e.g. echo fix/login-bug | sprout create -
+      """
+
+  Scenario: Create fails for an issue piped in that can't be found
+    Given I am assigned these Linear issues:
+      | identifier | title         | parent |
+      | ENG-12     | Add audit log |        |
+    And the following is piped in:
+      """
+      ENG-99
+      """
+    When I run "sprout create --issue-from-stdin"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: issue ENG-99 not found
+      """
+
+  Scenario: Create from a GitHub issue link names the branch after the issue
+    Given the issue at "https://github.com/acme/web/issues/42" is titled "Fix the login form"
+    When I run "sprout create --quiet https://github.com/acme/web/issues/42"
+    Then the output should be:
+      """
+      /mock/path/42-fix-the-login-form
+      """
+
+  Scenario: Create from a Linear issue link looks the issue up
+    Given I am assigned these Linear issues:
+      | identifier | title         | parent |
+      | ENG-12     | Add audit log |        |
+    When I run "sprout create --quiet https://linear.app/acme/issue/ENG-12/add-audit-log-draft"
+    Then the output should be:
+      """
+      /mock/path/eng-12-add-audit-log
+      """
+
+  Scenario: Create from an issue link gives it the prefix of the template its labels match
+    Given the config has a template "fix/" with:
+      | key    | value |
+      | labels | bug   |
+    And I am assigned these Linear issues:
+      | identifier | title         | parent | labels |
+      | ENG-12     | Add audit log |        | bug    |
+    When I run "sprout create --quiet https://linear.app/acme/issue/ENG-12/add-audit-log-draft"
+    Then the output should be:
+      """
+      /mock/path/fix/eng-12-add-audit-log
+      """
+
+  Scenario: Create from a piped Jira issue link
+    Given the issue at "https://acme.atlassian.net/browse/PROJ-7" is titled "Speed up search"
+    And the following is piped in:
+      """
+      https://acme.atlassian.net/browse/PROJ-7
+      """
+    When I run "sprout create -"
+    Then the output should be:
+      """
+      /mock/path/proj-7-speed-up-search
+      """
+
+  Scenario: Create from an issue link fails when its title can't be fetched
+    When I run "sprout create https://gitlab.com/acme/web/-/issues/9"
+    Then the command should fail
+    And no worktree should be created
+    And the output should be:
+      """
+      Error: failed to fetch the title of https://gitlab.com/acme/web/-/issues/9: gitlab answered with status 404
+      Hint: sprout create 9-<short-title> names the branch yourself
+      """
+
+  Scenario: Create --json prints a JSON event per line, ending with the path
+    Given a config with:
+      | key             | value  |
+      | open_in         | tmux   |
+      | default_command | code . |
+    When I run "sprout create --json mybranch"
+    Then the output should be:
+      """
+      {"type":"result","message":"Worktree ready at: /mock/path/mybranch"}
+      {"type":"done","status":"created","branch":"mybranch","path":"/mock/path/mybranch"}
+      """
+
+  Scenario: Create --json ends with a failed event when it can't create the worktree
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                        |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feature-123 |
+    When I run "sprout create --json --existing=fail feature-123"
+    Then the command should fail
+    And the output should be:
+      """
+      {"type":"done","status":"failed","error":"a worktree for feature-123 already exists at /mock/worktrees/feature-123"}
+      Error: a worktree for feature-123 already exists at /mock/worktrees/feature-123
+      Hint: sprout switch feature-123 goes to it, as does sprout create --existing=open feature-123
+      Hint: sprout create --suffix-on-conflict feature-123 starts afresh beside it, as feature-123-2
+      """
+
+  Scenario: Create --json gives the path of a worktree that already exists
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                        |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feature-123 |
+    When I run "sprout create --json --existing=open feature-123"
+    Then no worktree should be created
+    And the output should be:
+      """
+      {"type":"done","status":"exists","branch":"feature-123","path":"/mock/worktrees/feature-123"}
+      """
+
+  Scenario: Create --json can't run a command
+    When I run "sprout create --json mybranch make dev"
+    Then the command should fail
+    And no worktree should be created
+    And the output should contain "--json prints events on stdout, so it can't run make dev"
+
+  Scenario: Create with --base-from-issue stacks a subtask on its parent's worktree
+    Given I am assigned these Linear issues:
+      | identifier | title         | parent |
+      | ENG-12     | Add audit log |        |
+      | ENG-13     | Test it       | ENG-12 |
+    And the following worktrees exist:
+      | branch               | commit   | pr_status |
+      | eng-12-add-audit-log | abc12345 | Open      |
+    When I run "sprout create --base-from-issue eng-13-test-it"
+    Then the worktree should be created from "eng-12-add-audit-log" with hooks ""
+    And the output should contain "Starting from eng-12-add-audit-log, the branch for parent issue ENG-12"
+
+  Scenario: Create with --base-from-issue starts from the usual base when the parent has no worktree
+    Given I am assigned these Linear issues:
+      | identifier | title   | parent |
+      | ENG-13     | Test it | ENG-12 |
+    When I run "sprout create --base-from-issue eng-13-test-it"
+    Then the worktree should be created from "" with hooks ""
+    And the output should contain "Warning: parent issue ENG-12 has no worktree, starting from the usual base"
+
+  Scenario: Create with --base-from-issue needs an issue in the branch name
+    Given I am signed in to Linear
+    When I run "sprout create --base-from-issue feature/login"
+    Then the command should fail
+    And the output should contain "--base-from-issue needs the branch name to start with its issue"
+
+  Scenario: Create detects submodules and LFS by default
+    When I run "sprout create mybranch"
+    Then the worktree should be created with submodules "detect" and LFS "detect"
+
+  Scenario: Create follows the repo config for submodules and LFS
+    Given the repo config sets "submodules" to true
+    And the repo config sets "lfs" to false
+    When I run "sprout create mybranch"
+    Then the worktree should be created with submodules "always" and LFS "skip"
+
+  Scenario: Create shares git hooks as the repo config says
+    Given the repo config sets "gitHooks" to "link"
+    When I run "sprout create mybranch"
+    Then the worktree should be created with git hooks "link"
+
+  Scenario: Create can skip submodules and LFS
+    Given the repo config sets "submodules" to true
+    When I run "sprout create --no-submodules --no-lfs mybranch"
+    Then the worktree should be created with submodules "skip" and LFS "skip"
+
+  Scenario: Create --dry-run prints the plan without creating anything
+    Given the config has a template "fix/" with:
+      | key           | value                 |
+      | base          | develop               |
+      | sparseProfile | api                   |
+      | hooks         | make setup; npm ci    |
+    And I run "sprout sparse set --profile api services/api"
+    And the repo config sets "lfs" to false
+    And the repo config opens worktrees in "code"
+    When I run "sprout create --dry-run fix/login make dev"
+    Then no worktree should be created
+    And no editor should be opened
+    And the output should be:
+      """
+      Dry run: sprout create would
+        apply the fix/ template, checking out sparse profile api
+        create branch fix/login from develop
+        check it out at /mock/path/fix/login with only services/api
+        update submodules if the checkout has any
+        run hook: make setup
+        run hook: npm ci
+        open it in code
+        run make dev in it
+      """
+
+  Scenario: Create --dry-run with tmux plans the session
+    Given a config with:
+      | key             | value  |
+      | open_in         | tmux   |
+      | default_command | claude |
+    When I run "sprout create --dry-run feature-123"
+    Then no worktree should be created
+    And the output should contain "open tmux session feature-123 running claude"
+
+  Scenario: Create --dry-run on an existing worktree says it would open it
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                         |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feature-123  |
+    When I run "sprout create --dry-run --existing=open feature-123"
+    Then no worktree should be created
+    And the output should be:
+      """
+      Dry run: a worktree for feature-123 already exists at /mock/worktrees/feature-123, so sprout create would open it
+      """
+
+  Scenario: Create --dry-run still refuses a reserved branch name
+    When I run "sprout create --dry-run master"
+    Then the command should fail
+    And the output should contain "branch name is reserved"
+
+  Scenario: Create starts from the refs already fetched when origin needs a passphrase
+    Given origin can't be reached without a prompt
+    When I run "sprout create feature-123"
+    Then the output should contain "/mock/path/feature-123"
+    And the output should contain "Warning: can't reach origin without a prompt"
+    And the output should contain "ssh-add"
+    And the output should contain "starting from the refs already fetched"
+
+  Scenario: Create --dry-run plans from the refs already fetched without reaching origin
+    Given origin can't be reached without a prompt
+    When I run "sprout create --dry-run feature-123"
+    Then the output should contain "create branch feature-123 from origin/main"
+    And the output should not contain "Warning"
+
+  Scenario: Create --no-fetch starts from the refs already fetched
+    Given origin can't be reached without a prompt
+    When I run "sprout create --no-fetch feature-123"
+    Then the output should contain "/mock/path/feature-123"
+
+  Scenario: A template's base isn't fetched, so origin isn't checked
+    Given origin can't be reached without a prompt
+    And the config has a template "fix/" with:
+      | key  | value   |
+      | base | develop |
+    When I run "sprout create fix/login"
+    Then the output should contain "/mock/path/fix/login"
+
+  Scenario: Create with an unknown template fails
+    Given the config has a template "fix/" with:
+      | key  | value   |
+      | base | develop |
+    When I run "sprout create --template chore/ tidy"
+    Then the command should fail
+    And the output should contain "template 'chore/' is not defined; templates are: fix/"
+
+  Scenario: Create opens the worktree in the requested editor
+    When I run "sprout create --open=code mybranch"
+    Then the editor should open "code /mock/path/mybranch"
+
+  Scenario: Create uses the repo's configured editor
+    Given the repo config opens worktrees in "code"
+    And the repo config reuses editor windows
+    When I run "sprout create mybranch"
+    Then the editor should open "code /mock/path/mybranch (reuse window)"
+
+  Scenario: The --open flag overrides the repo's editor
+    Given the repo config opens worktrees in "code"
+    When I run "sprout create --open=none mybranch"
+    Then no editor should be opened
+
+  Scenario: Unknown editors are rejected
+    When I run "sprout create --open=emacs mybranch"
+    Then the command should fail
+    And no editor should be opened
+
+  Scenario: Create a Linear subtask from the command line
+    Given a config with:
+      | key            | value                    |
+      | linear_api_key | lin_api_test123456789abc |
+    When I run "sprout subtask eng-12 Write migration tests"
+    Then the subtask "ENG-12: Write migration tests" should be created
+    And the output should be:
+      """
+      Created TEST-101: Write migration tests
+      https://linear.app/test/issue/TEST-101
+      """
+
+  Scenario: Create a subtask and a worktree for it
+    Given a config with:
+      | key            | value                    |
+      | linear_api_key | lin_api_test123456789abc |
+    When I run "sprout subtask ENG-12 Write migration tests --worktree"
+    Then the output should be:
+      """
+      Created TEST-101: Write migration tests
+      https://linear.app/test/issue/TEST-101
+      Worktree ready at: /mock/path/test-101-write-migration-tests
+      """
+
+  Scenario: Create a subtask for each line of a piped checklist
+    Given a config with:
+      | key            | value                    |
+      | linear_api_key | lin_api_test123456789abc |
+    And the following is piped in:
+      """
+      - [ ] Write migration tests
+
+      - Backfill old rows
+      """
+    When I run "sprout subtask ENG-12 --from-file -"
+    Then the output should be:
+      """
+      [1/2] Created TEST-101: Write migration tests
+      [2/2] Created TEST-102: Backfill old rows
+      Created 2 subtasks under ENG-12
+      """
+
+  Scenario: Creating subtasks from a checklist carries on past a failure
+    Given a config with:
+      | key            | value                    |
+      | linear_api_key | lin_api_test123456789abc |
+    And Linear fails to create the subtask "Backfill old rows"
+    And the following is piped in:
+      """
+      1. Backfill old rows
+      2. Drop the column
+      """
+    When I run "sprout subtask ENG-12 --from-file -"
+    Then the command should fail
+    And the output should be:
+      """
+      [1/2] Failed "Backfill old rows": title too long
+      [2/2] Created TEST-101: Drop the column
+      Error: failed to create 1 of 2 subtasks
+      """
+
+  Scenario: A checklist of subtasks can't also create a worktree
+    When I run "sprout subtask ENG-12 --from-file plan.md -w"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: --worktree can't be used with --from-file
+      """
+
+  Scenario: Subtask creation requires a Linear API key
+    When I run "sprout subtask ENG-12 Write migration tests"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: Linear API key is not configured. Add linearApiKey to /Users/laurenkt/.sprout.json5
+      """
+
+  Scenario: Pick prints assigned issues a tab-separated line each
+    Given the config has a template "fix/" with:
+      | key    | value |
+      | labels | Bug   |
+    And I am assigned these Linear issues:
+      | identifier | title         | state       | labels |
+      | ENG-12     | Add audit log | In Progress |        |
+      | ENG-13     | Login fails   | Todo        | Bug    |
+    When I run "sprout pick"
+    Then the output should be:
+      """
+      ENG-12	In Progress	Add audit log	eng-12-add-audit-log
+      ENG-13	Todo	Login fails	fix/eng-13-login-fails
+      """
+
+  Scenario: Create takes the branch from a line sprout pick printed
+    Given the following is piped in:
+      """
+      ENG-12	In Progress	Add audit log	eng-12-add-audit-log
+      """
+    When I run "sprout create -"
+    Then the output should be:
+      """
+      /mock/path/eng-12-add-audit-log
+      """
+
+  Scenario: Pick requires a Linear API key
+    When I run "sprout pick"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: Linear API key is not configured. Add linearApiKey to /Users/laurenkt/.sprout.json5
+      """
+
+  Scenario: Today sums up issues in progress and worktrees that need attention
+    Given the following worktrees exist:
+      | branch             | commit   | pr_status | path                              |
+      | eng-12-fix-login   | abc12345 | Open      | /mock/worktrees/eng-12-fix-login  |
+      | eng-15-audit-log   | def67890 | No PR     | /mock/worktrees/eng-15-audit-log  |
+      | eng-9-old-feature  | 99887766 | Merged    | /mock/worktrees/eng-9-old-feature |
+    And worktree "eng-15-audit-log" has uncommitted changes
+    And I am assigned these Linear issues:
+      | identifier | title              | state       | state_type |
+      | ENG-12     | Fix login redirect | In Review   | started    |
+      | ENG-15     | Add audit log      | In Progress | started    |
+      | ENG-20     | Plan Q3 roadmap    | Todo        | unstarted  |
+    When I run "sprout today"
+    Then the output should be:
+      """
+      🌱 Today
+
+      In Progress
+        ENG-12 Fix login redirect [In Review] in eng-12-fix-login
+        ENG-15 Add audit log [In Progress] in eng-15-audit-log
+
+      Open PRs
+        eng-12-fix-login (ENG-12)
+
+      Uncommitted Changes
+        eng-15-audit-log (ENG-15) /mock/worktrees/eng-15-audit-log
+
+      Ready to Prune
+        eng-9-old-feature (ENG-9)
+        sprout prune removes them
+      """
+
+  Scenario: Today shows how each open PR's checks went, and their notes
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      | ci      | note                     |
+      | feature-a | abc12345 | Open      | /mock/worktrees/feature-a | failing |                          |
+      | feature-b | def67890 | Open      | /mock/worktrees/feature-b |         | waiting on design review |
+    When I run "sprout today"
+    Then the output should be:
+      """
+      🌱 Today
+
+      In Progress
+        Linear isn't configured
+
+      Open PRs
+        feature-a ✗ CI failing
+        feature-b — waiting on design review
+
+      Uncommitted Changes
+        Every worktree is clean
+
+      Ready to Prune
+        Nothing merged to clean up
+      """
+
+  Scenario: Today without Linear still covers the worktrees
+    Given the following worktrees exist:
+      | branch  | commit   | pr_status | path                    |
+      | feature | abc12345 | No PR     | /mock/worktrees/feature |
+    When I run "sprout today"
+    Then the output should be:
+      """
+      🌱 Today
+
+      In Progress
+        Linear isn't configured
+
+      Open PRs
+        No open PRs
+
+      Uncommitted Changes
+        Every worktree is clean
+
+      Ready to Prune
+        Nothing merged to clean up
+      """
+
+  Scenario: Today as JSON for scripts
+    Given the following worktrees exist:
+      | branch           | commit   | pr_status | path                             |
+      | eng-12-fix-login | abc12345 | Open      | /mock/worktrees/eng-12-fix-login |
+    And I am assigned these Linear issues:
+      | identifier | title              | state     | state_type |
+      | ENG-12     | Fix login redirect | In Review | started    |
+    And Linear can't be reached
+    When I run "sprout today --json"
+    Then the output should be:
+      """
+      {
+        "inProgress": [],
+        "openPRs": [
+          {
+            "branch": "eng-12-fix-login",
+            "path": "/mock/worktrees/eng-12-fix-login",
+            "prStatus": "Open",
+            "issue": "ENG-12"
+          }
+        ],
+        "dirty": [],
+        "readyToPrune": [],
+        "warnings": [
+          "failed to load issues from Linear: connection refused"
+        ]
+      }
+      """
+
+  Scenario: Stats with no recorded history
+    Given no worktrees exist
+    When I run "sprout stats"
+    Then the output should be:
+      """
+      🌱 Worktree Statistics
+
+        No worktree history recorded yet
+      """
+
+  Scenario: Stats summarise recorded worktree history
+    Given no worktrees exist
+    And the following worktree history exists:
+      | branch            | created_at       | pruned_at        |
+      | eng-101-login     | 2025-01-06 09:00 | 2025-01-08 13:00 |
+      | eng-101-follow-up | 2025-01-20 09:00 | 2025-02-03 09:00 |
+      | eng-202-search    | 2025-02-10 09:00 |                  |
+      | spike             | 2025-02-11 09:00 | 2025-02-11 15:00 |
+    When I run "sprout stats"
+    Then the output should be:
+      """
+      🌱 Worktree Statistics
+
+        Created: 4
+        Pruned: 3
+        Active: 1
+        Average Lifetime: 5d 11h
+
+      Activity by Month
+
+      ┌───────┬───────┬──────┐
+      │MONTH  │CREATED│PRUNED│
+      ├───────┼───────┼──────┤
+      │2025-01│2      │1     │
+      │2025-02│2      │2     │
+      └───────┴───────┴──────┘
+
+      Top Issues
+
+      ┌───────┬─────────┐
+      │ISSUE  │WORKTREES│
+      ├───────┼─────────┤
+      │ENG-101│2        │
+      │ENG-202│1        │
+      └───────┴─────────┘
+      """
+
+  Scenario: History lists what sprout did in the repo, oldest first
+    Given the following sprout history exists:
+      | time             | kind            | branch        | command | detail             |
+      | 2025-01-14 09:00 | worktreeCreated | eng-101-login |         |                    |
+      | 2025-01-14 09:00 | branchCreated   | eng-101-login |         | from main          |
+      | 2025-01-15 10:30 | commandRun      | eng-101-login | npm ci  | ok                 |
+      | 2025-01-16 17:45 | worktreeRenamed | login         |         | from eng-101-login |
+      | 2025-01-17 08:00 | worktreePruned  | login         |         |                    |
+    When I run "sprout history"
+    Then the output should be:
+      """
+      2025-01-14 09:00  created worktree eng-101-login at /mock/worktrees/eng-101-login [ENG-101]
+      2025-01-14 09:00  created branch eng-101-login from main [ENG-101]
+      2025-01-15 10:30  ran npm ci in eng-101-login (ok) [ENG-101]
+      2025-01-16 17:45  renamed worktree login from eng-101-login
+      2025-01-17 08:00  pruned worktree login
+      """
+
+  Scenario: History since a date, as JSON
+    Given the following sprout history exists:
+      | time             | kind            | branch | command | detail |
+      | 2025-01-14 09:00 | worktreeCreated | spike  |         |        |
+      | 2025-01-16 17:45 | worktreePruned  | spike  |         |        |
+    When I run "sprout history --since 2025-01-15 --json"
+    Then the output should contain "worktreePruned"
+    And the output should not contain "worktreeCreated"
+
+  Scenario: History with nothing recorded
+    When I run "sprout history"
+    Then the output should be:
+      """
+      No history recorded yet
+      """
+
+  Scenario: History rejects a --since it can't read
+    When I run "sprout history --since yesterday"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: --since must be a date like 2006-01-02 or a time ago like 24h, 7d or 2w, got "yesterday"
+      """
+
+  Scenario: Commands run by exec are recorded in the history
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | main      | abc12345 | -         | /mock/repo                |
+      | feature-a | def67890 | Open      | /mock/worktrees/feature-a |
+    And the command exits with code 1 in "/mock/worktrees/feature-a"
+    When I run "sprout exec -- git fetch"
+    And I run "sprout history"
+    Then the output should contain "ran git fetch in main (ok)"
+    And the output should contain "ran git fetch in feature-a (exit 1)"
+
+  Scenario: Save and show sparse checkout profiles
+    When I run "sprout sparse set --profile api services/api/ libs"
+    And I run "sprout sparse set web"
+    And I run "sprout sparse show"
+    Then the output should be:
+      """
+      🌱 Sparse Checkout Profiles
+
+      ┌───────┬──────────────────┐
+      │PROFILE│DIRECTORIES       │
+      ├───────┼──────────────────┤
+      │api    │services/api, libs│
+      │default│web               │
+      └───────┴──────────────────┘
+      """
+
+  Scenario: Apply a sparse checkout profile to an existing worktree
+    Given I run "sprout sparse set --profile api services/api"
+    When I run "sprout sparse apply feature-123 --profile api"
+    Then the output should be:
+      """
+      Applied sparse profile 'api' to feature-123
+      """
+
+  Scenario: Applying an unknown sparse profile fails
+    When I run "sprout sparse apply feature-123 --profile missing"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: sparse profile 'missing' is not defined
+      """
+
+  Scenario: Create a worktree with explicit sparse paths
+    When I run "sprout create --paths services/payments/,libs mybranch"
+    Then the worktree should be created with sparse directories "services/payments, libs"
+
+  Scenario: Create a worktree without sparse paths checks out everything
+    When I run "sprout create mybranch"
+    Then the worktree should be created with sparse directories ""
+
+  Scenario: Remove a worktree but keep its branch
+    When I run "sprout rm feature-123 --keep-branch"
+    Then worktree "feature-123" should be pruned
+    And the prune options should be "keep-branch"
+
+  Scenario: Prune flags may follow the branch name
+    When I run "sprout prune feature-123 --delete-remote --dry-run"
+    Then worktree "feature-123" should be pruned
+    And the prune options should be "delete-remote, dry-run"
+
+  Scenario: Remove can unlock a locked worktree
+    When I run "sprout rm feature-123 --unlock"
+    Then worktree "feature-123" should be pruned
+    And the prune options should be "unlock"
+
+  Scenario: Pruning merged worktrees leaves locked ones alone
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      | state  |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |        |
+      | feature-b | def67890 | Merged    | /mock/worktrees/feature-b | locked |
+    When I run "sprout prune --yes"
+    Then worktree "feature-a" should be pruned
+    And worktree "feature-b" should not be pruned
+
+  Scenario: Pin protects a worktree from pruning merged worktrees
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |
+      | release-2 | def67890 | Merged    | /mock/worktrees/release-2 |
+    When I run "sprout pin release-2"
+    And I run "sprout prune --yes"
+    Then worktree "feature-a" should be pruned
+    And worktree "release-2" should not be pruned
+
+  Scenario: Pin says how to lift it
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | release-2 | def67890 | Open      | /mock/worktrees/release-2 |
+    When I run "sprout pin release-2"
+    Then the output should be:
+      """
+      Pinned release-2; prune leaves it alone until sprout unpin release-2
+      """
+
+  Scenario: Unpinned worktrees are pruned again
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      | state  |
+      | release-2 | def67890 | Merged    | /mock/worktrees/release-2 | pinned |
+    When I run "sprout unpin release-2"
+    And I run "sprout prune --yes"
+    Then worktree "release-2" should be pruned
+
+  Scenario: Unpin refuses a worktree that isn't pinned
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Open      | /mock/worktrees/feature-a |
+    When I run "sprout unpin feature-a"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: feature-a isn't pinned
+      """
+
+  Scenario: Note keeps a note on a worktree that list shows
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status |
+      | feature-123 | abc12345 | Open      |
+      | release-2   | def67890 | Open      |
+    When I run "sprout note feature-123 waiting on design review"
+    Then the output should be:
+      """
+      Noted on feature-123: waiting on design review
+      """
+    When I run "sprout list"
+    Then the output should be:
+      """
+      🌱 Active Worktrees
+
+      ┌─┬───────────┬─────────┬────────┬────┬────────────────────────┐
+      │ │BRANCH     │PR STATUS│COMMIT  │SIZE│NOTE                    │
+      ├─┼───────────┼─────────┼────────┼────┼────────────────────────┤
+      │●│feature-123│Open     │abc12345│-   │waiting on design review│
+      │●│release-2  │Open     │def67890│-   │-                       │
+      └─┴───────────┴─────────┴────────┴────┴────────────────────────┘
+      """
+
+  Scenario: Note without text shows the note, and --clear removes it
+    Given the following worktrees exist:
+      | branch      | commit   | pr_status | path                        | note             |
+      | feature-123 | abc12345 | Open      | /mock/worktrees/feature-123 | blocked by API-9 |
+    When I run "sprout note feature-123"
+    Then the output should be:
+      """
+      blocked by API-9
+      """
+    When I run "sprout note --clear feature-123"
+    And I run "sprout note feature-123"
+    Then the output should be:
+      """
+      feature-123 has no note; sprout note feature-123 <text> adds one
+      """
+
+  Scenario: Note refuses a worktree that doesn't exist
+    When I run "sprout note missing-branch some text"
+    Then the command should fail
+    And the output should contain "worktree does not exist: missing-branch"
+
+  Scenario: Stack shows the branches a branch is built on and those built on it
+    Given these branches are stacked on "origin/main":
+      | branch               | parent               | depth | ahead | behind | path                                 |
+      | eng-12-add-audit-log | origin/main          | 0     | 2     | 0      | /mock/worktrees/eng-12-add-audit-log |
+      | eng-13-test-it       | eng-12-add-audit-log | 1     | 1     | 3      | /mock/worktrees/eng-13-test-it       |
+      | eng-14-document-it   | eng-13-test-it       | 2     | 0     | 0      |                                      |
+    When I run "sprout stack eng-13-test-it"
+    Then the output should be:
+      """
+      🌱 Stack
+
+      origin/main
+        eng-12-add-audit-log [2 ahead]
+          eng-13-test-it ← [1 ahead, 3 behind]
+            eng-14-document-it [up to date] (no worktree)
+
+      sprout stack restack rebases each branch onto the one it's built on
+      """
+
+  Scenario: Stack prints porcelain lines for scripts
+    Given these branches are stacked on "origin/main":
+      | branch               | parent               | depth | ahead | behind | path                                 |
+      | eng-12-add-audit-log | origin/main          | 0     | 2     | 0      | /mock/worktrees/eng-12-add-audit-log |
+      | eng-13-test-it       | eng-12-add-audit-log | 1     | 1     | 3      | /mock/worktrees/eng-13-test-it       |
+    When I run "sprout stack --porcelain"
+    Then the output should be:
+      """
+      eng-12-add-audit-log	origin/main	2	0	/mock/worktrees/eng-12-add-audit-log
+      eng-13-test-it	eng-12-add-audit-log	1	3	/mock/worktrees/eng-13-test-it
+      """
+
+  Scenario: Restack rebases each branch onto the one it's built on, bottom up
+    Given these branches are stacked on "origin/main":
+      | branch               | parent               | depth | ahead | behind | path                                 |
+      | eng-12-add-audit-log | origin/main          | 0     | 2     | 4      | /mock/worktrees/eng-12-add-audit-log |
+      | eng-13-test-it       | eng-12-add-audit-log | 1     | 1     | 3      | /mock/worktrees/eng-13-test-it       |
+      | eng-14-document-it   | eng-13-test-it       | 2     | 1     | 0      | /mock/worktrees/eng-14-document-it   |
+    When I run "sprout stack restack eng-12-add-audit-log"
+    Then the restacked branches should be "eng-13-test-it, eng-14-document-it"
+    And the output should be:
+      """
+      🌱 Stack
+
+      origin/main
+        eng-12-add-audit-log ← [2 ahead, 4 behind]
+          eng-13-test-it [1 ahead]
+            eng-14-document-it [1 ahead]
+      Rebasing eng-13-test-it onto eng-12-add-audit-log
+      Rebasing eng-14-document-it onto eng-13-test-it
+      """
+
+  Scenario: Restack refuses a stack with uncommitted changes
+    Given these branches are stacked on "origin/main":
+      | branch               | parent               | depth | ahead | behind | path                                 |
+      | eng-12-add-audit-log | origin/main          | 0     | 2     | 0      | /mock/worktrees/eng-12-add-audit-log |
+      | eng-13-test-it       | eng-12-add-audit-log | 1     | 1     | 3      | /mock/worktrees/eng-13-test-it       |
+    And worktree "eng-13-test-it" has uncommitted changes
+    When I run "sprout stack restack"
+    Then the command should fail
+    And the restacked branches should be ""
+    And the output should contain "eng-13-test-it has uncommitted changes; commit or stash them before restacking"
+
+  Scenario: Restack stops at a rebase that conflicts
+    Given these branches are stacked on "origin/main":
+      | branch               | parent               | depth | ahead | behind | path                                 |
+      | eng-12-add-audit-log | origin/main          | 0     | 2     | 0      | /mock/worktrees/eng-12-add-audit-log |
+      | eng-13-test-it       | eng-12-add-audit-log | 1     | 1     | 3      | /mock/worktrees/eng-13-test-it       |
+      | eng-14-document-it   | eng-13-test-it       | 2     | 1     | 0      | /mock/worktrees/eng-14-document-it   |
+    And rebasing "eng-14-document-it" conflicts
+    When I run "sprout stack restack"
+    Then the command should fail
+    And the restacked branches should be "eng-13-test-it"
+    And the output should contain "Error: rebasing eng-14-document-it onto eng-13-test-it conflicts"
+
+  Scenario: Quiet prune prints only porcelain result lines
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |
+    When I run "sprout prune --quiet --dry-run"
+    Then the prune options should be "dry-run, porcelain"
+    And the output should be:
+      """
+      would-prune	feature-a	/mock/worktrees/feature-a
+      """
+
+  Scenario: Pruning merged worktrees reports each one as it goes
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |
+      | feature-b | def67890 | Merged    | /mock/worktrees/feature-b |
+    When I run "sprout prune --yes"
+    Then the output should be:
+      """
+      Found 2 merged worktree(s) to prune:
+        - feature-a
+        - feature-b
+
+      [1/2] Pruned feature-a
+      [2/2] Pruned feature-b
+
+      Pruned 2 merged worktree(s)
+      Changed your mind? sprout undo brings them back
+      """
+
+  Scenario: A worktree that fails to prune doesn't stop the others
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |
+      | feature-b | def67890 | Merged    | /mock/worktrees/feature-b |
+    And pruning "feature-a" fails with "permission denied"
+    When I run "sprout prune --yes"
+    Then the command should fail
+    And worktree "feature-b" should be pruned
+    And the output should contain "[1/2] Failed to prune feature-a: permission denied"
+    And the output should contain "[2/2] Pruned feature-b"
+    And the output should contain "Pruned 1 of 2 merged worktree(s): 1 failed, 0 skipped"
+    And the output should contain "Error: failed to prune 1 of 2 worktree(s)"
+
+  Scenario: Pruning every merged worktree asks first
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |
+      | feature-b | def67890 | Open      | /mock/worktrees/feature-b |
+    And I will answer "y"
+    When I run "sprout prune"
+    Then the output should contain "These 1 merged worktree(s) will be pruned:"
+    And the output should contain "  - feature-a"
+    And the output should contain "Prune them? [y/N]"
+    And worktree "feature-a" should be pruned
+    And worktree "feature-b" should not be pruned
+
+  Scenario: Declining the prune leaves every worktree in place
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |
+    And I will answer "n"
+    When I run "sprout prune"
+    Then the output should contain "Nothing was pruned"
+    And worktree "feature-a" should not be pruned
+
+  Scenario: Each merged worktree with uncommitted changes is asked about
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |
+      | feature-b | def67890 | Merged    | /mock/worktrees/feature-b |
+    And worktree "feature-b" has uncommitted changes
+    And I will answer "y, n"
+    When I run "sprout prune"
+    Then the output should contain "feature-b has uncommitted changes. Prune it anyway? [y/N]"
+    And worktree "feature-a" should be pruned
+    And worktree "feature-b" should not be pruned
+    And the output should contain "Skipped feature-b: kept for its uncommitted changes"
+    And the output should contain "Pruned 1 of 2 merged worktree(s): 0 failed, 1 skipped"
+
+  Scenario: Removing a worktree with uncommitted changes asks first
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | No PR     | /mock/worktrees/feature-a |
+    And worktree "feature-a" has uncommitted changes
+    And I will answer "n"
+    When I run "sprout rm feature-a"
+    Then the output should contain "Nothing was pruned"
+    And worktree "feature-a" should not be pruned
+
+  Scenario: Prune refuses to go ahead unasked without a terminal
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |
+    When I run "sprout prune"
+    Then the command should fail
+    And the output should contain "Error: can't ask"
+    And the output should contain "Prune them?"
+    And the output should contain "without a terminal; pass --yes to go ahead without asking"
+    And worktree "feature-a" should not be pruned
+
+  Scenario: --yes prunes without asking
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |
+    And worktree "feature-a" has uncommitted changes
+    When I run "sprout prune -f"
+    Then worktree "feature-a" should be pruned
+
+  Scenario: Prune worktrees above a size threshold
+    When I run "sprout prune --larger-than 5GB --dry-run"
+    Then the prune threshold should be 5368709120 bytes
+    And the prune options should be "dry-run"
+
+  Scenario: Size threshold cannot be combined with a branch
+    When I run "sprout prune feature-123 --larger-than 5GB"
+    Then the command should fail
+
+  Scenario: gc collects merged and oversize worktrees, then expired trash
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      | size  |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a | 10MB  |
+      | feature-b | def67890 | Open      | /mock/worktrees/feature-b | 6GB   |
+      | feature-c | fed09876 | Open      | /mock/worktrees/feature-c | 200MB |
+    And a config with:
+      | key            | value |
+      | gc_larger_than | 5GB   |
+    And "old-spike" has been in the trash past trashDays
+    When I run "sprout gc --yes"
+    Then the output should be:
+      """
+      Found 2 worktree(s) to collect:
+        - feature-a (merged, PR merged)
+        - feature-b (oversize, 6.0 GB)
+
+      [1/2] Pruned feature-a
+      [2/2] Pruned feature-b
+
+      Collected 2 worktree(s): 1 merged, 1 oversize
+      Emptied 1 worktree(s) past trashDays from the trash
+      Deleted 1 oversize worktree(s) outright to free their space; sprout undo can't bring them back
+      Changed your mind? sprout undo brings them back
+      """
+    And worktree "feature-c" should not be pruned
+    And worktree "feature-b" should be deleted outright
+    And worktree "feature-a" should not be deleted outright
+    And the prune options should be "keep-unmerged"
+
+  Scenario: gc --dry-run reports stale worktrees without collecting them
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      | updated    |
+      | feature-a | abc12345 | Open      | /mock/worktrees/feature-a | 2020-01-01 |
+      | feature-b | def67890 | Open      | /mock/worktrees/feature-b | 2999-01-01 |
+    And "old-spike" has been in the trash past trashDays
+    When I run "sprout gc --stale-days 30 --dry-run"
+    Then the gc stale cutoff should be 30 days
+    And the output should contain "Found 1 worktree(s) to collect:"
+    And the output should contain "  - feature-a (stale, no commits for"
+    And the output should contain "Dry run: 1 worktree(s) would be collected"
+    And the output should contain "Dry run: 1 worktree(s) past trashDays would be emptied from the trash"
+    And the prune options should be "dry-run, keep-unmerged"
+
+  Scenario: gc takes its stale cutoff from the config
+    Given a config with:
+      | key           | value |
+      | gc_stale_days | 45    |
+    When I run "sprout gc --dry-run"
+    Then the gc stale cutoff should be 45 days
+    And the output should be:
+      """
+      Nothing to collect
+      """
+
+  Scenario: gc leaves worktrees with uncommitted changes
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |
+      | feature-b | def67890 | Merged    | /mock/worktrees/feature-b |
+    And worktree "feature-b" has uncommitted changes
+    When I run "sprout gc --yes"
+    Then worktree "feature-a" should be pruned
+    And worktree "feature-b" should not be pruned
+    And the output should contain "Skipped feature-b: kept for its uncommitted changes"
+    And the output should contain "Collected 1 worktree(s): 1 merged; 0 failed, 1 skipped"
+
+  Scenario: Declining gc collects nothing
+    Given the following worktrees exist:
+      | branch    | commit   | pr_status | path                      |
+      | feature-a | abc12345 | Merged    | /mock/worktrees/feature-a |
+    And I will answer "n"
+    When I run "sprout gc"
+    Then the output should contain "Collect them? [y/N]"
+    And the output should contain "Nothing was collected"
+    And worktree "feature-a" should not be pruned
+
+  Scenario: gc --schedule installs a job collecting in every repository
+    Given sprout is installed at "/usr/local/bin/sprout"
+    When I run "sprout gc --schedule daily"
+    Then the scheduled jobs should be "gc daily in /mock/repo: /usr/local/bin/sprout gc --all-repos --yes"
+    And the output should be:
+      """
+      Scheduled sprout gc daily for every registered repository, with the systemd user timer sprout-gc.timer
+      sprout gc --schedule off stops it
+      """
+
+  Scenario: gc --schedule rejects an unknown interval
+    Given sprout is installed at "/usr/local/bin/sprout"
+    When I run "sprout gc --schedule fortnightly"
+    Then the command should fail
+    And the output should contain "(supported: hourly, daily, weekly)"
+    And no jobs should be scheduled
+
+  Scenario: gc --schedule can't be combined with other flags
+    When I run "sprout gc --schedule daily --dry-run"
+    Then the command should fail
+    And the output should contain "Error: --schedule can't be combined with other flags"
+
+  Scenario: rm requires a branch name
+    When I run "sprout rm --dry-run"
+    Then the command should fail
+    And the output should be:
+      """
+      Error: branch name is required. Usage: sprout rm <branch-name> [--keep-branch] [--delete-remote] [--dry-run] [--quiet] [--yes]
+      """
+
+  Scenario: Unknown command shows error and help
+    When I run "sprout unknown"
+    Then the command should fail
+    And the output should be:
+      """
+      Sprout - Git Worktree Terminal UI
+
+      Usage:
+        sprout                              Start in interactive mode
+        sprout list                         List all worktrees
+        sprout issues                       Browse and triage issues without creating worktrees
+        sprout pick                         Print assigned issues a line each for fzf, to pipe to sprout create -
+        sprout clone <url> [dir]            Clone as a bare repo with a worktree per branch
+        sprout migrate [--dry-run]          Convert this checkout to a bare repo with worktrees
+        sprout create <branch>              Create worktree and output path
+        sprout create <branch> <command>    Create worktree and run command in it
+        sprout create -                     Create worktree for the branch piped in, outputting only its path
+        sprout create --issue-from-stdin    The same for the issue piped in, named as the TUI names it
+        sprout create --json <branch>       Create worktree, printing each step as a line of JSON
+        sprout create <issue-url>           Create worktree named after a Linear, GitHub, GitLab or Jira issue
+        sprout subtask <parent> <title>     Create a Linear subtask under a parent issue
+        sprout switch <branch>              Output an existing worktree's path, or attach to its tmux session
+        sprout resume [branch]              Run the last command run in a worktree again, by default the latest
+        sprout where [--exists] [branch]    Print where a branch's worktree is or would go, creating nothing
+        sprout pr checkout [pr]             Pull a PR's latest head into its worktree, by number, branch or issue
+        sprout prune [branch]               Remove worktree(s) - all merged if no branch specified
+        sprout rm <branch>                  Remove a specific worktree (alias for prune <branch>)
+        sprout archive <branch>             Save a worktree's unmerged work, then remove it
+        sprout restore <branch>             Recreate an archived worktree and output its path
+        sprout export <branch>              Pack a worktree's branch, changes and issue into one file for a teammate
+        sprout import <file>                Recreate a worktree from an exported file and output its path
+        sprout pin <branch>                 Lock a worktree so prune leaves it alone
+        sprout unpin <branch>               Lift the pin so the worktree can be pruned again
+        sprout note <branch> [text]         Keep a short note on a worktree, or show it
+        sprout undo                         Bring back the worktrees the last prune removed
+        sprout gc [--dry-run]               Prune merged, stale and oversize worktrees and empty old trash
+        sprout stack [branch]               Show the branches a worktree's branch is built on and those built on it
+        sprout stack restack [branch]       Rebase each branch in the stack onto the one it's built on
+        sprout rename <old> <new>           Rename a worktree's branch and move its directory
+        sprout repair                       Clean up worktrees deleted outside sprout
+        sprout exec -- <command>            Run a command in every worktree
+        sprout serve --stdio                Serve JSON-RPC on stdin and stdout for editor plugins
+        sprout sparse set <dirs...>         Save sparse-checkout directories as a named profile
+        sprout sparse show                  List sparse-checkout profiles for this repo
+        sprout sparse apply <branch>        Apply a sparse-checkout profile to a worktree
+        sprout history [--since when]       Show what sprout has done in this repo
+        sprout today [--json]               Sum up issues in progress, open PRs and what needs tidying
+        sprout stats                        Show local worktree usage statistics
+        sprout auth linear [--logout]       Sign in to Linear in the browser instead of using an API key
+        sprout auth github [--logout]       Store a GitHub token (read from stdin) for PR status without gh
+        sprout doctor [--json]              Check configuration and connectivity
+        sprout config resolved [--json]     Show each setting in effect and the file or variable it came from
+        sprout init --git-alias             Install git-sprout so git sprout <command> works too
+        sprout upgrade [--check]            Install the latest release in place of this binary
+        sprout version [--json]             Show build details and the git and gh versions found
+        sprout help                         Show this help
+
+      Global options:
+        --config <path>                     Read settings from path instead of ~/.sprout.json5
+        --repo <name>                       Work in the registered repository called name
+        --profile-startup                   Print how long each phase of starting up took
+
+      Settings come from SPROUT_* environment variables first, then the config file
+      given with --config or SPROUT_CONFIG, or ~/.sprout.json5, then the defaults.
 
       Examples:
         sprout list                          # Show all worktrees
+        sprout list --all-repos              # Show worktrees from every repo sprout has run in
+        sprout list --porcelain              # Tab-separated branch, path, PR status, commit
+        sprout list --sort=age               # Most recently committed first (or status, branch)
+        sprout list --status=dirty           # Only worktrees with uncommitted changes
+        cd "$(sprout clone <url>)"           # Clone and change to the default branch's worktree
         cd "$(sprout create mybranch)"       # Change to worktree directory
         sprout create mybranch bash          # Create worktree and start bash
         sprout create mybranch code .        # Create worktree and open in VS Code
         sprout create mybranch git status    # Create worktree and run git status
+        sprout create --paths api mybranch   # Create worktree with only api/ checked out
+        sprout create --open=code mybranch   # Create worktree and open it in VS Code
+        sprout create --existing=fail fix    # Fail if fix already has a branch or worktree
+        sprout create --template fix/ login  # Create fix/login with the fix/ template applied
+        sprout create --no-lfs mybranch      # Create worktree without fetching LFS files
+        sprout create --no-fetch mybranch    # Create worktree from the refs already fetched
+        sprout create --detach web npm start # Start npm start in the background and return
+        sprout create --dry-run fix/login    # Show what create would do without doing it
+        echo fix/login | sprout create -     # Create fix/login, outputting only its path
+        sprout pick | fzf | sprout create -  # Choose an issue with fzf and create its worktree
+        sprout subtask ENG-12 Fix tests -w   # Create a subtask and a worktree for it
+        sprout subtask ENG-12 --from-file -  # Create a subtask per line piped in
         sprout prune                         # Remove all merged worktrees
         sprout prune mybranch                # Remove specific worktree and directory
+        sprout prune --dry-run               # Show what would be removed
+        sprout prune --yes                   # Remove merged worktrees without asking first
+        sprout prune --quiet                 # Print only a result line per removed worktree
+        sprout prune --larger-than 5GB       # Remove worktrees bigger than 5GB
+        sprout prune --all-repos             # Remove merged worktrees in every repo
+        sprout gc --stale-days 30 --dry-run  # See what gc would collect, counting a month idle
+        sprout gc --schedule daily           # Collect in every repo each day in the background
+        sprout rm mybranch --keep-branch     # Remove worktree but keep the branch
+        sprout rm mybranch --delete-remote   # Also delete origin/mybranch
+        sprout rm mybranch --unlock          # Remove a worktree locked with git worktree lock
+        sprout archive mybranch              # Keep mybranch's work but free its directory
+        cd "$(sprout restore mybranch)"      # Bring mybranch back and change to it
+        sprout export mybranch              # Write mybranch.sprout.tgz for a teammate
+        cd "$(sprout import fix.sprout.tgz)" # Carry on where a teammate left off
+        sprout pin release-2.x               # Keep a long-lived worktree out of every prune
+        sprout note fix/login waiting on QA  # Remember what a worktree is waiting on
+        sprout stack restack                 # Bring the parent's new commits into each branch above it
+        cd "$(sprout pr checkout ENG-123)"   # Pick up ENG-123's PR again after review comments
+        cd "$(sprout rename fxi fix)"        # Fix a typo and follow the worktree
+        sprout exec --parallel 4 git fetch   # Fetch in four worktrees at a time
+        sprout exec --status open -- npm ci  # Reinstall in worktrees with an open PR
+        sprout sparse set services/api libs  # Check out only these directories
+        sprout history --since 7d --json     # Last week's worktrees and commands as JSON
+        sprout today --all-repos             # Morning summary across every repo
+        sprout doctor --json                 # Health checks for scripts; exits 1 if one fails
+        git sprout create mybranch           # The same, once sprout init --git-alias has run
+        sprout upgrade --check               # See whether a newer release is out
+        gh auth token | sprout auth github   # Keep using gh's token once gh is gone
+        sprout --config ~/oss.json5 list     # Use a separate profile for open source work
+        sprout --repo api list               # List worktrees of the api repository from anywhere
+        SPROUT_OPEN_IN=tmux sprout create x  # Override one setting for a single run
       Unknown command: unknown
       """
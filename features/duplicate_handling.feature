@@ -15,7 +15,7 @@ Feature: Sprout duplicate issue handling
 
       > sprout/enter branch name or select suggestion below
       └──TICK-1  In Progress  Parent Task
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "down"
     And I press "right"
@@ -27,7 +27,7 @@ Feature: Sprout duplicate issue handling
       └──TICK-1  In Progress  Parent Task
          ├──TICK-2  Todo         Child Task
          └──+ Add subtask
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Multiple nested levels only show top-level parents
@@ -45,7 +45,7 @@ Feature: Sprout duplicate issue handling
       > sprout/enter branch name or select suggestion below
       ├──TICK-1  In Progress  Parent Task
       └──TICK-4  In Review    Solo Task
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Recently updated assigned subtasks keep their parent discoverable
@@ -62,7 +62,7 @@ Feature: Sprout duplicate issue handling
       > sprout/enter branch name or select suggestion below
       ├──TICK-1  In Progress  Parent Task
       └──TICK-3  Todo         Other Recent Task
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "down"
     And I press "right"
@@ -75,7 +75,7 @@ Feature: Sprout duplicate issue handling
       │  ├──TICK-2  Todo         New Child Task
       │  └──+ Add subtask
       └──TICK-3  Todo         Other Recent Task
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Parent can still disclose add-subtask row when child loading fails
@@ -95,5 +95,5 @@ Feature: Sprout duplicate issue handling
       > sprout/tick-1-parent-task
       └──TICK-1  In Progress  Parent Task
          └──+ Add subtask
-      [worktree <tab>] [u unassign] [d done] [z undo]                                      failed to fetch children for TICK-1
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]                  failed to fetch children for TICK-1
       """
@@ -0,0 +1,69 @@
+Feature: Ask before reusing an existing worktree or branch
+  As a developer using Sprout
+  I want to know when the branch I'm creating already exists
+  So that I open the existing work instead of silently reusing it
+
+  Background:
+    Given the following worktrees exist:
+      | branch            | path                              | updated_at           | merged |
+      | old-merged-branch | /mock/worktrees/old-merged-branch | 2026-05-02T07:00:00Z | true   |
+
+  Scenario: Creating a branch that has a worktree offers to open it
+    Given I start the Sprout TUI
+    When I type "old-merged-branch"
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      A worktree for old-merged-branch already exists:
+      /mock/worktrees/old-merged-branch
+
+      Open it instead?
+      [enter/y open] [s create old-merged-branch-2] [esc/n back]
+      """
+    When I press "enter"
+    Then the TUI should resume worktree "/mock/worktrees/old-merged-branch"
+    And no new worktree should be created
+
+  Scenario: Going back from the prompt returns to the list
+    Given I start the Sprout TUI
+    When I type "old-merged-branch"
+    And I press "enter"
+    And I press "esc"
+    Then the UI should display "> sprout/old-merged-branch"
+    And the UI should not display "already exists"
+    And no new worktree should be created
+
+  Scenario: Creating a branch that exists without a worktree offers to check it out
+    Given branch "spike" already exists
+    And I start the Sprout TUI
+    When I type "spike"
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Branch spike already exists without a worktree.
+
+      Check it out in a new worktree?
+      [enter/y check out] [s create spike-2] [esc/n back]
+      """
+    When I press "y"
+    Then a worktree should be created for branch "spike"
+
+  Scenario: A branch that's taken can be started afresh under a new name
+    Given branch "spike" already exists
+    And branch "spike-2" already exists
+    And I start the Sprout TUI
+    When I type "spike"
+    And I press "enter"
+    Then the UI should display "[s create spike-3]"
+    When I press "s"
+    Then a worktree should be created for branch "spike-3"
+
+  Scenario: A new branch is created without asking
+    Given I start the Sprout TUI
+    When I type "brand-new"
+    And I press "enter"
+    Then a worktree should be created for branch "brand-new"
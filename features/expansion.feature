@@ -23,7 +23,7 @@ Feature: Sprout TUI Tree Expansion
       ├──SPR-100  In Progress  Feature A: User management system
       ├──SPR-200  Todo         Feature B: Dashboard and analytics
       └──SPR-300  In Review    Bug fix: Payment processing errors
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "down"
     Then the UI should display:
@@ -34,7 +34,7 @@ Feature: Sprout TUI Tree Expansion
       ├──SPR-100  In Progress  Feature A: User management system
       ├──SPR-200  Todo         Feature B: Dashboard and analytics
       └──SPR-300  In Review    Bug fix: Payment processing errors
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "right"
     Then the UI should display:
@@ -48,7 +48,7 @@ Feature: Sprout TUI Tree Expansion
       │  └──+ Add subtask
       ├──SPR-200  Todo         Feature B: Dashboard and analytics
       └──SPR-300  In Review    Bug fix: Payment processing errors
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "down"
     And I press "down"
@@ -65,7 +65,7 @@ Feature: Sprout TUI Tree Expansion
       │  └──+ Add subtask
       ├──SPR-200  Todo         Feature B: Dashboard and analytics
       └──SPR-300  In Review    Bug fix: Payment processing errors
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "right"
     Then the UI should display:
@@ -83,5 +83,25 @@ Feature: Sprout TUI Tree Expansion
       │  ├──SPR-203  Backlog      Implement data visualization
       │  └──+ Add subtask
       └──SPR-300  In Review    Bug fix: Payment processing errors
-      [worktree <tab>] [u unassign] [d done] [z undo]
-      """
\ No newline at end of file
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+  Scenario: Subtasks fetched in the background show as soon as an issue is expanded
+    Given the following Linear issues exist:
+      | identifier | title       | parent_id | status      |
+      | TICK-1     | Parent Task |           | In Progress |
+      | TICK-2     | Child Task  | TICK-1    | Todo        |
+    And my terminal width is 120 characters
+    When I start the Sprout TUI
+    And fetching children for "TICK-1" fails
+    And I press "down"
+    And I press "right"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/tick-1-parent-task
+      └──TICK-1  In Progress  Parent Task
+         ├──TICK-2  Todo         Child Task
+         └──+ Add subtask
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
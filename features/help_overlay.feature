@@ -0,0 +1,73 @@
+Feature: Help overlay
+  As a developer new to Sprout
+  I want a cheatsheet I can pull up over the work queue
+  So that I can discover what each key does without reading the source
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title                   | parent_id | status      |
+      | SPR-2      | Add user authentication |           | Todo        |
+      | SPR-124    | Implement dashboard     |           | In Progress |
+
+  Scenario: The footer points at the help overlay
+    When I start the Sprout TUI
+    Then the UI should display "[? help]"
+
+  Scenario: The overlay explains the branch name input
+    When I start the Sprout TUI
+    And I press "?"
+    Then the UI should display "type       name a new branch"
+    And the UI should display "enter      create the worktree"
+    And the UI should display "tab        switch to branch only"
+    And the UI should display "/          fuzzy search issues"
+
+  Scenario: The overlay follows the creation mode
+    When I start the Sprout TUI
+    And I press "tab"
+    And I press "?"
+    Then the UI should display "enter      create the branch"
+    And the UI should display "tab        switch to worktree"
+
+  Scenario: The overlay explains the actions for a selected issue
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "?"
+    Then the UI should display "enter      create a worktree for SPR-2"
+    And the UI should display "→/l        show subtasks or add one"
+    And the UI should display "s u d      change status, unassign, mark done"
+
+  Scenario: The overlay explains how to add a subtask
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "right"
+    And I press "down"
+    And I press "?"
+    Then the UI should display "→/l        start a subtask of SPR-2"
+    And the UI should display "tab        add description, estimate and priority"
+
+  Scenario: The overlay is drawn over the work queue
+    When I start the Sprout TUI
+    And I press "?"
+    Then the UI should display "╭"
+    And the UI should display "🌱 sprout"
+    And the UI should display "├──SPR-2    Todo"
+
+  Scenario: Escape closes the overlay
+    When I start the Sprout TUI
+    And I press "?"
+    And I press "esc"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-2    Todo         Add user authentication
+      └──SPR-124  In Progress  Implement dashboard
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: A question mark in a branch name is typed rather than opening help
+    When I start the Sprout TUI
+    And I type "why?"
+    Then the UI should display "> sprout/why?"
+    And the UI should not display "Keybindings"
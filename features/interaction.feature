@@ -23,7 +23,7 @@ Feature: Sprout TUI Interaction
       ├──SPR-123  Todo         Add user authentication
       ├──SPR-124  In Progress  Implement dashboard with analytics and re...
       └──SPR-127  Done         Fix critical bug in payment processing
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "up"
     Then the UI should display:
@@ -34,7 +34,7 @@ Feature: Sprout TUI Interaction
       ├──SPR-123  Todo         Add user authentication
       ├──SPR-124  In Progress  Implement dashboard with analytics and re...
       └──SPR-127  Done         Fix critical bug in payment processing
-      [branch <tab>] [u unassign] [d done] [z undo]
+      [branch <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Toggle between worktree and branch mode
@@ -48,7 +48,7 @@ Feature: Sprout TUI Interaction
       ├──SPR-123  Todo         Add user authentication
       ├──SPR-124  In Progress  Implement dashboard with analytics and re...
       └──SPR-127  Done         Fix critical bug in payment processing
-      [branch <tab>] [u unassign] [d done] [z undo]
+      [branch <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Create a branch after toggling mode
@@ -80,6 +80,19 @@ Feature: Sprout TUI Interaction
       | command                                                                                                       |
       | git worktree add /mock/worktrees/spr-123-add-user-authentication -b spr-123-add-user-authentication main |
 
+  Scenario: What creating a worktree warns about shows with the result
+    Given creating a worktree warns "timerStart failed: exit status 1"
+    And I start the Sprout TUI
+    When I press "down"
+    And I press "enter"
+    Then the UI should display:
+      """
+      ✓ Worktree created at: /mock/worktrees/spr-123-add-user-authentication
+      Warning: timerStart failed: exit status 1
+
+      Press any key to exit.
+      """
+
   Scenario: Run configured post-create command after creating worktree
     Given the default worktree command is "code ."
     And I start the Sprout TUI
@@ -90,6 +103,48 @@ Feature: Sprout TUI Interaction
       | git worktree add /mock/worktrees/spr-123-add-user-authentication -b spr-123-add-user-authentication main |
       | cd /mock/worktrees/spr-123-add-user-authentication && code .                                           |
 
+  Scenario: A default command for the branch's pattern replaces the default worktree command
+    Given the default worktree command is "code ."
+    And the default command for branches "spr-123-*" is "nvim"
+    And I start the Sprout TUI
+    When I press "down"
+    And I press "enter"
+    Then the following commands should be run:
+      | command                                                                                                       |
+      | git worktree add /mock/worktrees/spr-123-add-user-authentication -b spr-123-add-user-authentication main |
+      | cd /mock/worktrees/spr-123-add-user-authentication && nvim                                             |
+
+  Scenario: A failed creation shows git's output beneath the error
+    Given creating a worktree fails with:
+      """
+      failed to create worktree: exit status 128
+      Output: fatal: a branch named 'spr-123-add-user-authentication' already exists
+      """
+    And I start the Sprout TUI
+    When I press "down"
+    And I press "enter"
+    Then the UI should display:
+      """
+      ✗ Error: failed to create worktree: exit status 128
+        fatal: a branch named 'spr-123-add-user-authentication' already exists
+
+      Press any key to exit.
+      """
+
+  Scenario: A failed creation suggests what to try next
+    Given the directory for new worktrees is taken by something else
+    And I start the Sprout TUI
+    When I press "down"
+    And I press "enter"
+    Then the UI should display:
+      """
+      ✗ Error: directory exists but is not a valid worktree: /mock/worktrees/spr-123-add-user-authentication
+
+      → move or delete that directory, then try again
+
+      Press any key to exit.
+      """
+
   Scenario: Unassign selected ticket and remove it from the list
     Given I start the Sprout TUI
     And I press "down"
@@ -101,7 +156,7 @@ Feature: Sprout TUI Interaction
       > sprout/spr-124-implement-dashboard-with-analytics-and-reporting
       ├──SPR-124  In Progress  Implement dashboard with analytics and re...
       └──SPR-127  Done         Fix critical bug in payment processing
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Mark selected ticket as done and remove it from the list
@@ -115,7 +170,7 @@ Feature: Sprout TUI Interaction
       > sprout/spr-124-implement-dashboard-with-analytics-and-reporting
       ├──SPR-124  In Progress  Implement dashboard with analytics and re...
       └──SPR-127  Done         Fix critical bug in payment processing
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Undo unassign restores the ticket to the list
@@ -131,5 +186,5 @@ Feature: Sprout TUI Interaction
       ├──SPR-123  Todo         Add user authentication
       ├──SPR-124  In Progress  Implement dashboard with analytics and re...
       └──SPR-127  Done         Fix critical bug in payment processing
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
@@ -0,0 +1,101 @@
+Feature: Sprout Issue Browser
+  As a developer triaging tickets
+  I want to browse my issue tree without the worktree controls
+  So that I can update tickets without creating anything by accident
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title                      | parent_id | status      |
+      | SPR-100    | Feature A: User management |           | In Progress |
+      | SPR-101    | Add user registration      | SPR-100   | Todo        |
+      | SPR-200    | Feature B: Dashboard       |           | Todo        |
+    And the following worktrees exist:
+      | branch      | path                        | updated_at           | merged |
+      | feature-xyz | /mock/worktrees/feature-xyz | 2026-05-01T16:00:00Z | false  |
+
+  Scenario: The browser lists issues with the first one selected and no branch input
+    When I start the issue browser
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Browsing issues; nothing here creates a branch or worktree
+      ├──SPR-100  In Progress  Feature A: User management
+      └──SPR-200  Todo         Feature B: Dashboard
+      [s status] [u unassign] [d done] [z undo] [b open] [c copy] [? help]
+      """
+
+  Scenario: Enter shows and hides subtasks instead of creating a worktree
+    When I start the issue browser
+    And I press "enter"
+    Then the UI should contain "SPR-101"
+    And the UI should contain "Add subtask"
+    When I press "enter"
+    Then the UI should not display "SPR-101"
+    And no new worktree should be created
+
+  Scenario: Enter on Add subtask starts a subtask
+    When I start the issue browser
+    And I press "enter"
+    And I press "down"
+    And I press "down"
+    And I press "enter"
+    And I type "Write migration tests"
+    And I press "enter"
+    Then the subtask should be created with:
+      | field       | value                 |
+      | title       | Write migration tests |
+      | description |                       |
+      | estimate    |                       |
+      | priority    |                       |
+
+  Scenario: The selection stays in the tree at either end
+    When I start the issue browser
+    And I press "up"
+    And I press "down"
+    And I press "down"
+    Then the UI should contain "└──SPR-200  Todo         Feature B: Dashboard"
+    And the UI should contain "Browsing issues; nothing here creates a branch or worktree"
+
+  Scenario: Open the selected issue in the browser
+    When I start the issue browser
+    And I press "down"
+    And I press "b"
+    Then the browser should open "https://linear.local/SPR-200"
+    And the UI should contain "Opened SPR-200"
+
+  Scenario: Copy the selected issue's identifier
+    When I start the issue browser
+    And I press "c"
+    Then the clipboard should contain "SPR-100"
+    And the UI should contain "Copied SPR-100"
+
+  Scenario: The status picker works from the browser
+    When I start the issue browser
+    And I press "s"
+    Then the UI should contain "Status for SPR-100 Feature A: User management:"
+
+  Scenario: Leaving a search keeps the issue found selected
+    When I start the issue browser
+    And I press "/"
+    And I type "dash"
+    And I press "down"
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Browsing issues; nothing here creates a branch or worktree
+      ├──SPR-100  In Progress  Feature A: User management
+      └──SPR-200  Todo         Feature B: Dashboard
+      [s status] [u unassign] [d done] [z undo] [b open] [c copy] [? help]
+      """
+    When I press "c"
+    Then the clipboard should contain "SPR-200"
+
+  Scenario: The board offers links rather than worktrees
+    When I start the issue browser
+    And I press "v"
+    And I press "enter"
+    Then the UI should contain "[b open] [c copy] [v list] [? help]"
+    And no new worktree should be created
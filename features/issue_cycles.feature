@@ -0,0 +1,79 @@
+Feature: Filtering issues by cycle
+  As a developer planning my work a sprint at a time
+  I want to narrow my tickets to the current cycle, or any other
+  So that next sprint's tickets don't crowd out this one's
+
+  Background:
+    Given the current Linear cycle is 12
+    And the following Linear issues exist:
+      | identifier | title              | parent_id | status | updated_at           | cycle |
+      | SPR-1      | Tidy the changelog |           | Todo   | 2026-05-04T12:00:00Z |       |
+      | SPR-2      | Fix data loss      |           | Todo   | 2026-05-03T12:00:00Z | 12    |
+      | SPR-3      | Add dark mode      |           | Todo   | 2026-05-02T12:00:00Z | 13    |
+      | SPR-4      | Speed up search    |           | Todo   | 2026-05-01T12:00:00Z | 12    |
+
+  Scenario: Only the current cycle's issues are listed to begin with
+    When I start the Sprout TUI
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-2  Todo  Fix data loss  C12
+      └──SPR-4  Todo  Speed up search  C12
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [t cycle: current]
+      [? help]
+      """
+
+  Scenario: Toggling shows every cycle
+    When I start the Sprout TUI
+    And I press "t"
+    Then the UI should display "├──SPR-1  Todo  Tidy the changelog"
+    And the UI should display "├──SPR-3  Todo  Add dark mode  C13"
+    And the UI should not display "cycle:"
+
+  Scenario: Toggling again shows only the current cycle's issues
+    When I start the Sprout TUI
+    And I press "t"
+    And I press "t"
+    Then the UI should display "[t cycle: current]"
+    And the UI should not display "SPR-3"
+
+  Scenario: The cycle picker lists every cycle my issues are in
+    When I start the Sprout TUI
+    And I press "i"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Show issues in:
+        any cycle
+      > the current cycle
+        Cycle 12
+        Cycle 13
+      [enter filter] [esc back]
+      """
+
+  Scenario: Picking a cycle shows only its issues
+    When I start the Sprout TUI
+    And I press "i"
+    And I press "down" 2 times
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      └──SPR-3  Todo  Add dark mode  C13
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [t cycle: Cycle 13]
+      [? help]
+      """
+
+  Scenario: The config can list every cycle
+    Given a config with:
+      | key        | value |
+      | issueCycle | all   |
+    When I start the Sprout TUI
+    Then the UI should display "├──SPR-1  Todo  Tidy the changelog"
+    And the UI should display "├──SPR-3  Todo  Add dark mode  C13"
+    And the UI should not display "cycle:"
@@ -0,0 +1,78 @@
+Feature: Issue labels and projects
+  As a developer juggling tickets across teams and projects
+  I want to see each ticket's labels and project and filter by label
+  So that I can focus on one area of work at a time
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title               | parent_id | status | updated_at           | labels                          | project                 |
+      | SPR-1      | Fix refund rounding |           | Todo   | 2026-05-04T12:00:00Z | bug, payments                   | Billing                 |
+      | SPR-2      | Update landing page |           | Todo   | 2026-05-03T12:00:00Z |                                 | Website                 |
+      | SPR-3      | Tidy up docs        |           | Todo   | 2026-05-02T12:00:00Z | docs                            |                         |
+      | SPR-4      | Retry failed syncs  |           | Todo   | 2026-05-01T12:00:00Z | bug, backend, sync, reliability | Platform Infrastructure |
+
+  Scenario: Labels and projects are shown as chips after the title
+    When I start the Sprout TUI
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-1  Todo  Fix refund rounding  ● bug ● payments ◆ Billing
+      ├──SPR-2  Todo  Update landing page  ◆ Website
+      ├──SPR-3  Todo  Tidy up docs  ● docs
+      └──SPR-4  Todo  Retry failed syncs  ● bug ● backend ● sync +2
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: The label picker lists every label on my issues
+    When I start the Sprout TUI
+    And I press "L"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Show issues labelled:
+      > any label
+        backend
+        bug
+        docs
+        payments
+        reliability
+        sync
+      [enter filter] [esc back]
+      """
+
+  Scenario: Filtering by a label shows only issues bearing it
+    When I start the Sprout TUI
+    And I press "L"
+    And I press "down" 2 times
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-1  Todo  Fix refund rounding  ● bug ● payments ◆ Billing
+      └──SPR-4  Todo  Retry failed syncs  ● bug ● backend ● sync +2
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [L label: bug]
+      [? help]
+      """
+
+  Scenario: Choosing any label clears the filter
+    When I start the Sprout TUI
+    And I press "L"
+    And I press "down" 2 times
+    And I press "enter"
+    And I press "L"
+    And I press "up" 2 times
+    And I press "enter"
+    Then the UI should display "├──SPR-2  Todo  Update landing page  ◆ Website"
+    And the UI should not display "[L label"
+
+  Scenario: Escape leaves the filter unchanged
+    When I start the Sprout TUI
+    And I press "L"
+    And I press "down"
+    And I press "esc"
+    Then the UI should display "├──SPR-3  Todo  Tidy up docs  ● docs"
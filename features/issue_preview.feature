@@ -0,0 +1,40 @@
+Feature: Preview an issue's description
+  As a developer using Sprout
+  I want to read an issue's description without leaving the tree
+  So that I can tell what an issue is about before starting work on it
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title              | parent_id | status      | updated_at           | description                                   |
+      | SPR-1      | Tidy the changelog |           | Todo        | 2026-05-03T07:00:00Z | Group entries by **release**\n\n- Drop typos |
+      | SPR-2      | Fix data loss      |           | In Progress | 2026-05-02T07:00:00Z |                                               |
+
+  Scenario: Space shows the selected issue's description under its row
+    Given I start the Sprout TUI
+    Then the UI should not display "Group entries by release"
+    When I press "down"
+    And I press "space"
+    Then the UI should display "Group entries by release"
+    And the UI should display "Drop typos"
+    And the UI should not display "**release**"
+
+  Scenario: Space again hides the description
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "space"
+    And I press "space"
+    Then the UI should not display "Group entries by release"
+
+  Scenario: An issue without a description says so
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "down"
+    And I press "space"
+    Then the UI should display "No description"
+
+  Scenario: A space typed into a branch name is part of the name
+    Given I start the Sprout TUI
+    When I type "fix"
+    And I press "space"
+    Then the UI should display "fix "
+    And the UI should not display "No description"
@@ -0,0 +1,82 @@
+Feature: Refreshing issues
+  As a developer using Sprout
+  I want to reload my issues without restarting the TUI
+  So that I see tickets assigned to me since it opened
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title                      | parent_id | status      |
+      | SPR-100    | Feature A: User management |           | In Progress |
+      | SPR-101    | Add user registration      | SPR-100   | Done        |
+
+  Scenario: Refreshing asks Linear again
+    When I start the Sprout TUI
+    And I press "right"
+    And I press "left"
+    And Linear now also has:
+      | identifier | title                 | parent_id | status |
+      | SPR-200    | Feature B: Dashboard  |           | Todo   |
+    And I press "r"
+    Then the UI should contain "SPR-200  Todo         Feature B: Dashboard"
+    And Linear should have been asked for my issues 2 times
+
+  Scenario: Refreshing is left to the keys it's mapped to
+    Given the keybindings are:
+      | action  | keys |
+      | refresh | f    |
+    When I start the Sprout TUI
+    And Linear now also has:
+      | identifier | title                 | parent_id | status |
+      | SPR-200    | Feature B: Dashboard  |           | Todo   |
+    And I press "ctrl+r"
+    Then the UI should not display "Feature B: Dashboard"
+    When I press "f"
+    Then the UI should contain "Feature B: Dashboard"
+
+  Scenario: The footer says how long ago issues were loaded
+    When I start the Sprout TUI
+    Then the UI should not display "last updated"
+    When 3 minutes pass
+    Then the UI should display "last updated 3m ago"
+    When I press "ctrl+r"
+    Then the UI should not display "last updated 3m ago"
+
+  Scenario: Issues reload by themselves when configured to
+    Given a config with:
+      | key                 | value |
+      | issueRefreshSeconds | 300   |
+    When I start the Sprout TUI
+    And Linear now also has:
+      | identifier | title                 | parent_id | status |
+      | SPR-200    | Feature B: Dashboard  |           | Todo   |
+    And 4 minutes pass
+    Then the UI should not display "Feature B: Dashboard"
+    When 1 minute passes
+    Then the UI should contain "SPR-200  Todo         Feature B: Dashboard"
+    And Linear should have been asked for my issues 2 times
+
+  Scenario: Issues don't reload from under a subtask being typed
+    Given a config with:
+      | key                 | value |
+      | issueRefreshSeconds | 300   |
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "right"
+    And I press "down"
+    And I press "down"
+    And I press "right"
+    And I type "Draft"
+    And 10 minutes pass
+    Then Linear should have been asked for my issues 1 time
+
+  Scenario: Refreshing keeps the same issues open and selected
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "right"
+    And I press "down"
+    And Linear now also has:
+      | identifier | title                 | parent_id | status |
+      | SPR-102    | Add password reset    | SPR-100   | Todo   |
+    And I press "ctrl+r"
+    Then the UI should contain "SPR-102  Todo         Add password reset"
+    And the UI should display "> sprout/spr-101-add-user-registration"
@@ -0,0 +1,73 @@
+Feature: Issue priority, estimate and sorting
+  As a developer with urgent tickets among many recently touched ones
+  I want to see each ticket's priority, estimate and cycle and sort by them
+  So that urgent work isn't buried under whatever was updated last
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title              | parent_id | status | updated_at           | priority | estimate | cycle |
+      | SPR-1      | Tidy the changelog |           | Todo   | 2026-05-04T12:00:00Z | 4        | 1        |       |
+      | SPR-2      | Fix data loss      |           | Todo   | 2026-05-01T12:00:00Z | 1        | 5        | 12    |
+      | SPR-3      | Add dark mode      |           | Todo   | 2026-05-03T12:00:00Z | 0        |          |       |
+      | SPR-4      | Speed up search    |           | Todo   | 2026-05-02T12:00:00Z | 2        | 3        | 12    |
+
+  Scenario: Issues show compact priority, estimate and cycle badges
+    When I start the Sprout TUI
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-1  Todo  Tidy the changelog  P4 1pt
+      ├──SPR-3  Todo  Add dark mode
+      ├──SPR-4  Todo  Speed up search  P2 3pt C12
+      └──SPR-2  Todo  Fix data loss  P1 5pt C12
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Sorting by priority puts urgent issues first
+    When I start the Sprout TUI
+    And I press "o"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-2  Todo  Fix data loss  P1 5pt C12
+      ├──SPR-4  Todo  Speed up search  P2 3pt C12
+      ├──SPR-1  Todo  Tidy the changelog  P4 1pt
+      └──SPR-3  Todo  Add dark mode
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [o by priority]
+      [? help]
+      """
+    And the issue sort should be saved as "priority"
+
+  Scenario: Sorting by estimate puts the smallest first and unestimated last
+    When I start the Sprout TUI
+    And I press "o" 2 times
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-1  Todo  Tidy the changelog  P4 1pt
+      ├──SPR-4  Todo  Speed up search  P2 3pt C12
+      ├──SPR-2  Todo  Fix data loss  P1 5pt C12
+      └──SPR-3  Todo  Add dark mode
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [o by estimate]
+      [? help]
+      """
+    And the issue sort should be saved as "estimate"
+
+  Scenario: Sorting cycles back to recently updated
+    When I start the Sprout TUI
+    And I press "o" 3 times
+    Then the UI should display "├──SPR-1  Todo  Tidy the changelog  P4 1pt"
+    And the UI should not display "[o by"
+    And the issue sort should be saved as "updated"
+
+  Scenario: The saved order is used on start
+    Given issues are sorted by "priority"
+    When I start the Sprout TUI
+    Then the UI should display "├──SPR-2  Todo  Fix data loss  P1 5pt C12"
+    And the UI should display "[o by priority]"
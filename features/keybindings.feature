@@ -0,0 +1,124 @@
+Feature: Configurable keybindings
+  As a developer with my own muscle memory
+  I want to remap the TUI's keys in my config
+  So that sprout fits the way I already move around a terminal
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title                   | parent_id | status      |
+      | SPR-2      | Add user authentication |           | Todo        |
+      | SPR-124    | Implement dashboard     |           | In Progress |
+      | SPR-125    | Create analytics card   | SPR-124   | Todo        |
+
+  Scenario: Remapped navigation keys move the selection
+    Given the keybindings are:
+      | action | keys    |
+      | down   | j, down |
+      | up     | k, up   |
+    When I start the Sprout TUI
+    And I press "j"
+    And I press "j"
+    And I press "k"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/spr-2-add-user-authentication
+      ├──SPR-2    Todo         Add user authentication
+      └──SPR-124  In Progress  Implement dashboard
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Remapped keys still type into the branch name once typing has started
+    Given the keybindings are:
+      | action | keys    |
+      | down   | j, down |
+    When I start the Sprout TUI
+    And I type "fix-j"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/fix-j
+      ├──SPR-2    Todo         Add user authentication
+      └──SPR-124  In Progress  Implement dashboard
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: The footer shows remapped shortcuts
+    Given the keybindings are:
+      | action     | keys |
+      | toggleMode | m    |
+      | status     | S    |
+    When I start the Sprout TUI
+    And I press "m"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-2    Todo         Add user authentication
+      └──SPR-124  In Progress  Implement dashboard
+      [branch <m>] [S status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: The help overlay lists the active keymap
+    Given the keybindings are:
+      | action | keys    |
+      | down   | j, down |
+      | quit   | q, esc  |
+    When I start the Sprout TUI
+    And I press "?"
+    Then the UI should display:
+      """
+      🌱 sprout         ╭──────────────────────────────────────────╮
+      │ Keybindings                              │
+      > sprout/enter bra│ ↑/k        move up                       │
+      ├──SPR-2    Todo  │ j/↓        move down                     │
+      └──SPR-124  In Pro│ →/l        expand issue / add subtask    │
+      [worktree <tab>] [│ ←/h        collapse issue                │help]
+      │ home/gg    jump to the first row         │
+      │ end/G      jump to the last row          │
+      │ ctrl+d     move down half a page         │
+      │ ctrl+u     move up half a page           │
+      │ 1-9        select the nth issue shown    │
+      │ enter      create or resume worktree     │
+      │ /          fuzzy search issues           │
+      │ tab        toggle worktree / branch only │
+      │ a          show all or active work items │
+      │ s          change issue status           │
+      │ u          unassign issue                │
+      │ d          mark issue done               │
+      │ z          undo unassign                 │
+      │ n          rename worktree and branch    │
+      │ e          note on worktree              │
+      │ f          pull PR head and resume       │
+      │ p          prune merged worktrees        │
+      │ R/ctrl+p   switch repository             │
+      │ r/ctrl+r   refresh issues                │
+      │ v          toggle board view             │
+      │ o          cycle issue sort order        │
+      │ L          filter issues by label        │
+      │ t          show the current cycle or all │
+      │ i          filter issues by cycle        │
+      │ b          open issue in browser         │
+      │ c          copy issue identifier         │
+      │ space      preview issue description     │
+      │ x          select the suggested issue    │
+      │ ?          toggle this help              │
+      │ q/esc      quit, or leave search         │
+      │ Right now                                │
+      │ type       name a new branch             │
+      │ enter      create the worktree           │
+      │ tab        switch to branch only         │
+      │ /          fuzzy search issues           │
+      │ [?/esc close]                            │
+      ╰──────────────────────────────────────────╯
+      """
+
+  Scenario: Unknown actions are rejected
+    Given the keybindings are:
+      | action | keys |
+      | jump   | g    |
+    When I start the Sprout TUI
+    Then the TUI should fail to start with "unknown keybindings actions [jump]"
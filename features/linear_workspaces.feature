@@ -0,0 +1,57 @@
+Feature: Multiple Linear workspaces
+  As a developer with tickets in more than one Linear workspace
+  I want their assigned issues merged into one work queue
+  So that I can see all my work without switching accounts
+
+  Background:
+    Given the following Linear issues exist in workspace "work":
+      | identifier | title               | parent_id | status      | updated_at           |
+      | SPR-1      | Fix refund rounding |           | Todo        | 2026-05-04T12:00:00Z |
+      | SPR-2      | Tidy up docs        |           | In Progress | 2026-05-02T12:00:00Z |
+    And the following Linear issues exist in workspace "client":
+      | identifier | title               | parent_id | status      | updated_at           |
+      | ACME-7     | Add export button   |           | Todo        | 2026-05-03T12:00:00Z |
+
+  Scenario: Issues from every workspace are listed with a workspace column
+    When I start the Sprout TUI
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-1   work    Todo         Fix refund rounding
+      ├──ACME-7  client  Todo         Add export button
+      └──SPR-2   work    In Progress  Tidy up docs
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Changes go to the workspace the issue came from
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "down"
+    And I press "d"
+    Then workspace "client" should have updated "ACME-7"
+    And the UI should not display "Add export button"
+
+  Scenario: Refreshing asks every workspace again
+    When I start the Sprout TUI
+    And workspace "work" now also has:
+      | identifier | title            | parent_id | status | updated_at           |
+      | SPR-3      | Rotate API keys  |           | Todo   | 2026-05-01T12:00:00Z |
+    And workspace "client" now also has:
+      | identifier | title            | parent_id | status | updated_at           |
+      | ACME-8     | Import CSV files |           | Todo   | 2026-05-01T12:00:00Z |
+    And I press "r"
+    Then the UI should contain "Rotate API keys"
+    And the UI should contain "Import CSV files"
+
+  Scenario: Issues from every workspace reload by themselves when configured to
+    Given a config with:
+      | key                 | value |
+      | issueRefreshSeconds | 300   |
+    When I start the Sprout TUI
+    And workspace "client" now also has:
+      | identifier | title            | parent_id | status | updated_at           |
+      | ACME-8     | Import CSV files |           | Todo   | 2026-05-01T12:00:00Z |
+    And 5 minutes pass
+    Then the UI should contain "Import CSV files"
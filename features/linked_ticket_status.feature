@@ -0,0 +1,25 @@
+Feature: Linked ticket status for worktrees
+  As a developer using Sprout
+  I want worktrees to show the status of the ticket they were created for
+  So that I can spot tickets left open after their work has landed
+
+  Scenario: A worktree whose ticket isn't in the work queue shows the ticket's status
+    Given the following Linear issues exist:
+      | identifier | title                 | parent_id | status      | updated_at           |
+      | SPR-124    | Dashboard analytics   |           | In Progress | 2026-05-01T10:00:00Z |
+      | SPR-125    | Create analytics card | SPR-124   | In Review   | 2026-05-01T09:00:00Z |
+    And the following worktrees exist:
+      | branch                        | path                                           | updated_at           | merged |
+      | spr-125-create-analytics-card | /mock/worktrees/spr-125-create-analytics-card | 2026-05-02T08:00:00Z | false  |
+      | feature-search                | /mock/worktrees/feature-search                | 2026-05-01T16:00:00Z | false  |
+    When I start the Sprout TUI
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/█enter branch name or select suggestion below
+      ├──spr-125-create-analytics-card  SPR-125 In Review
+      ├──feature-search
+      └──SPR-124   In Progress  Dashboard analytics
+      [worktree <tab>] [a all] [s status] [u unassign] [d done] [z undo] [? help]
+      """
@@ -22,7 +22,7 @@ Feature: Sprout TUI Navigation
       ├──SPR-2     Todo         Add user authentication
       ├──SPR-124   In Progress  Implement dashboard with analytics and r...
       └──SPR-1234  In Review    Fix critical bug in payment processing
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Navigate down from input field
@@ -36,7 +36,7 @@ Feature: Sprout TUI Navigation
       ├──SPR-2     Todo         Add user authentication
       ├──SPR-124   In Progress  Implement dashboard with analytics and r...
       └──SPR-1234  In Review    Fix critical bug in payment processing
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Navigate back up to input field
@@ -51,5 +51,5 @@ Feature: Sprout TUI Navigation
       ├──SPR-2     Todo         Add user authentication
       ├──SPR-124   In Progress  Implement dashboard with analytics and r...
       └──SPR-1234  In Review    Fix critical bug in payment processing
-      [branch <tab>] [u unassign] [d done] [z undo]
+      [branch <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
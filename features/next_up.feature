@@ -0,0 +1,36 @@
+Feature: Next up suggestion
+  As a developer deciding what to work on
+  I want Sprout to suggest the ticket I'm most likely to pick up next
+  So that it's always a keypress and Enter away
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title              | parent_id | status      | updated_at           | priority | state_type |
+      | SPR-1      | Tidy the changelog |           | Todo        | 2026-05-04T12:00:00Z | 1        | unstarted  |
+      | SPR-2      | Fix data loss      |           | In Progress | 2026-05-01T12:00:00Z | 2        | started    |
+      | SPR-3      | Add dark mode      |           | Backlog     | 2026-05-03T12:00:00Z | 1        | backlog    |
+
+  Scenario: The suggestion prefers work already started
+    When I start the Sprout TUI
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      Suggested: SPR-2 — In Progress, high priority  [x select]
+      ├──SPR-1  Todo         Tidy the changelog  P1
+      ├──SPR-3  Backlog      Add dark mode  P1
+      └──SPR-2  In Progress  Fix data loss  P2
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: One key selects the suggestion and Enter starts work on it
+    When I start the Sprout TUI
+    And I press "x"
+    And I press "enter"
+    Then a worktree should be created for branch "spr-2-fix-data-loss"
+
+  Scenario: Nothing is suggested while searching
+    When I start the Sprout TUI
+    And I press "/"
+    Then the UI should not display "Suggested:"
@@ -0,0 +1,139 @@
+Feature: Prune merged worktrees from the TUI
+  As a developer using Sprout
+  I want to clear out worktrees whose PRs have merged without leaving the work queue
+  So that finished work doesn't pile up on disk
+
+  Background:
+    Given the following worktrees exist:
+      | branch       | path                         | updated_at           | merged |
+      | feature-done | /mock/worktrees/feature-done | 2026-05-01T16:00:00Z | true   |
+      | feature-live | /mock/worktrees/feature-live | 2026-04-30T12:00:00Z | false  |
+      | fix-shipped  | /mock/worktrees/fix-shipped  | 2026-04-29T10:00:00Z | true   |
+
+  Scenario: Pruning asks first and lists the merged worktrees
+    Given I start the Sprout TUI
+    When I press "p"
+    Then the UI should display "Prune 2 merged worktree(s)?"
+    And the UI should display "feature-done"
+    And the UI should display "fix-shipped"
+    And the UI should not display "feature-live"
+    And the UI should display "[k keep branches: off] [r delete on origin: off] [d dry run: off]"
+    And the UI should display "[y prune] [n back]"
+
+  Scenario: Confirming prunes each merged worktree and reports how it went
+    Given I start the Sprout TUI
+    When I press "p"
+    And I press "y"
+    Then the UI should display "Pruned merged worktrees"
+    And the UI should display "✓ feature-done"
+    And the UI should display "✓ fix-shipped"
+    When I press "enter"
+    Then the UI should display "Pruned 2 merged worktree(s); sprout undo brings them back"
+    And the UI should display "feature-live"
+    When I press "p"
+    Then the UI should display "No merged worktrees to prune"
+
+  Scenario: Branches are deleted with their worktrees by default
+    Given I start the Sprout TUI
+    When I press "p"
+    And I press "y"
+    Then the UI should display "✓ feature-done"
+    And "feature-done" should no longer be a local branch
+    And "feature-done" should not be deleted on origin
+
+  Scenario: Keeping the branches leaves them behind
+    Given I start the Sprout TUI
+    When I press "p"
+    And I press "k"
+    Then the UI should display "[k keep branches: on]"
+    When I press "y"
+    Then the UI should display "✓ feature-done"
+    And "feature-done" should still be a local branch
+    And "fix-shipped" should still be a local branch
+
+  Scenario: Branches can be deleted on origin too
+    Given I start the Sprout TUI
+    When I press "p"
+    And I press "r"
+    Then the UI should display "[r delete on origin: on]"
+    When I press "y"
+    Then the UI should display "✓ fix-shipped"
+    And "feature-done" should also be deleted on origin
+    And "fix-shipped" should also be deleted on origin
+
+  Scenario: A dry run only says what would be pruned
+    Given I start the Sprout TUI
+    When I press "p"
+    And I press "d"
+    Then the UI should display "[d dry run: on]"
+    When I press "y"
+    Then the UI should display "Merged worktrees that would be pruned"
+    And the UI should display "~ feature-done: would prune"
+    And the UI should display "~ fix-shipped: would prune"
+    When I press "enter"
+    Then the UI should display "Would prune 2 merged worktree(s); nothing was changed"
+    When I press "p"
+    Then the UI should display "Prune 2 merged worktree(s)?"
+
+  Scenario: Toggling an option again turns it off
+    Given I start the Sprout TUI
+    When I press "p"
+    And I press "k"
+    And I press "k"
+    Then the UI should display "[k keep branches: off]"
+
+  Scenario: A worktree that fails to prune doesn't stop the others
+    Given pruning "feature-done" fails with "permission denied"
+    And I start the Sprout TUI
+    When I press "p"
+    And I press "y"
+    Then the UI should display "✗ feature-done: permission denied"
+    And the UI should display "✓ fix-shipped"
+    When I press "enter"
+    Then the UI should display "Pruned 1 of 2 merged worktree(s); 1 failed"
+    When I press "p"
+    Then the UI should display "Prune 1 merged worktree(s)?"
+    And the UI should display "feature-done"
+    And the UI should not display "fix-shipped"
+
+  Scenario: Declining leaves the worktrees alone
+    Given I start the Sprout TUI
+    When I press "p"
+    And I press "n"
+    Then the UI should not display "Prune 2 merged worktree(s)?"
+    When I press "p"
+    Then the UI should display "Prune 2 merged worktree(s)?"
+
+  Scenario: Pinned worktrees are left out
+    Given the following worktrees exist:
+      | branch       | path                         | updated_at           | merged | state  |
+      | feature-done | /mock/worktrees/feature-done | 2026-05-01T16:00:00Z | true   |        |
+      | release-2    | /mock/worktrees/release-2    | 2026-04-29T10:00:00Z | true   | pinned |
+    And I start the Sprout TUI
+    When I press "p"
+    Then the UI should display "Prune 1 merged worktree(s)?"
+    And the UI should display "feature-done"
+    And the UI should not display "release-2"
+
+  Scenario: Worktrees merged since sprout last ran are pointed out until they're pruned
+    Given "feature-done" has merged since sprout last ran
+    And "fix-shipped" has merged since sprout last ran
+    And I start the Sprout TUI
+    Then the UI should display "2 worktrees are merged — press p to prune"
+    When I press "P"
+    And I press "y"
+    And I press "enter"
+    Then the UI should display "Pruned 2 merged worktree(s); sprout undo brings them back"
+    And the UI should not display "worktrees are merged"
+
+  Scenario: One new merge points out every merged worktree
+    Given "feature-done" has merged since sprout last ran
+    And I start the Sprout TUI
+    Then the UI should display "2 worktrees are merged — press p to prune"
+
+  Scenario: Merges seen when sprout last ran aren't pointed out again
+    Given I start the Sprout TUI
+    Then the UI should display "feature-live"
+    And the UI should not display "are merged"
+    When I press "p"
+    Then the UI should display "Prune 2 merged worktree(s)?"
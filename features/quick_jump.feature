@@ -0,0 +1,40 @@
+Feature: Jump to an issue by typing its identifier
+  As a developer using Sprout
+  I want to type an issue's identifier, such as SPR-123, to start work on it
+  So that I don't have to find it in the tree, or know it's there at all
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title              | parent_id | status      | updated_at           | assignee |
+      | SPR-1      | Tidy the changelog |           | Todo        | 2026-05-03T07:00:00Z |          |
+      | SPR-2      | Fix data loss      |           | In Progress | 2026-05-02T07:00:00Z |          |
+      | SPR-9      | Add dark mode      |           | Backlog     | 2026-05-01T07:00:00Z | alex     |
+
+  Scenario: An identifier in the tree selects that issue
+    Given I start the Sprout TUI
+    When I type "spr-2"
+    Then the UI should display "→ SPR-2 Fix data loss"
+    When I press "enter"
+    Then a worktree should be created for branch "spr-2-fix-data-loss"
+
+  Scenario: An identifier that isn't in the tree is looked up in Linear
+    Given I start the Sprout TUI
+    Then the UI should not display "Add dark mode"
+    When I type "SPR-9"
+    Then the UI should display "→ SPR-9 Add dark mode (not in your issues)"
+    When I press "enter"
+    Then a worktree should be created for branch "spr-9-add-dark-mode"
+
+  Scenario: An identifier Linear doesn't know is a branch name after all
+    Given I start the Sprout TUI
+    When I type "SPR-404"
+    Then the UI should display "No issue SPR-404; Enter creates a branch by that name"
+    When I press "enter"
+    Then a worktree should be created for branch "SPR-404"
+
+  Scenario: Typing on past an identifier goes back to naming a branch
+    Given I start the Sprout TUI
+    When I type "SPR-2-notes"
+    Then the UI should not display "→ SPR-2"
+    When I press "enter"
+    Then a worktree should be created for branch "SPR-2-notes"
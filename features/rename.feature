@@ -0,0 +1,61 @@
+Feature: Rename a worktree from the TUI
+  As a developer using Sprout
+  I want to rename a worktree and its branch without leaving the work queue
+  So that fixing a typo in a branch name doesn't take manual surgery
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title               | parent_id | status | updated_at           |
+      | SPR-140    | Fix onboarding copy |           | Todo   | 2026-04-30T12:00:00Z |
+    And the following worktrees exist:
+      | branch         | path                           | updated_at           | merged |
+      | feature-serach | /mock/worktrees/feature-serach | 2026-05-01T16:00:00Z | false  |
+      | misc-cleanup   | /mock/worktrees/misc-cleanup   | 2026-04-29T10:00:00Z | false  |
+
+  Scenario: Renaming the selected worktree keeps it selected under its new name
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "n"
+    Then the UI should display "Rename feature-serach to:"
+    When I press "backspace"
+    And I press "backspace"
+    And I press "backspace"
+    And I press "backspace"
+    And I type "arch"
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/feature-search
+      ├──feature-search
+      ├──SPR-140   Todo  Fix onboarding copy
+      └──misc-cleanup
+      [worktree <tab>] [a all] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Escape leaves the worktree's name alone
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "n"
+    And I type "-typo"
+    And I press "esc"
+    Then the UI should display "├──feature-serach"
+    And the UI should not display "Rename feature-serach to:"
+
+  Scenario: A name already in use is reported in the footer
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "n"
+    And I press "ctrl+u"
+    And I type "misc-cleanup"
+    And I press "enter"
+    Then the UI should display "branch misc-cleanup already exists"
+    And the UI should display "├──feature-serach"
+
+  Scenario: Issues without a worktree have nothing to rename
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "down"
+    And I press "n"
+    Then the UI should not display "Rename"
@@ -0,0 +1,41 @@
+Feature: Repo picker on launch
+  As a developer with several repositories under one directory
+  I want sprout to ask which one to open when I start it outside them
+  So that I don't have to cd into a repository first
+
+  Background:
+    Given repo "api" is registered with worktrees:
+      | branch         | path                               | updated_at           | merged |
+      | fix-rate-limit | /mock/api-worktrees/fix-rate-limit | 2026-05-02T09:00:00Z | false  |
+    And repo "web" is registered with worktrees:
+      | branch         | path                               | updated_at           | merged |
+      | new-onboarding | /mock/web-worktrees/new-onboarding | 2026-05-03T10:00:00Z | false  |
+    When I start the Sprout TUI outside any repository
+
+  Scenario: Started outside any repository, the TUI asks which to open
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Choose a repository:
+      > api
+        web
+      [enter open] [esc quit]
+      """
+
+  Scenario: Choosing a repository opens it
+    When I press "down"
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > web/enter branch name or select suggestion below
+      └──new-onboarding
+      [worktree <tab>] [a all] [s status] [u unassign] [d done] [z undo]
+      [R/ctrl+p repo] [? help]
+      """
+
+  Scenario: Escape quits when no repository is open yet
+    When I press "esc"
+    Then the TUI should have quit
@@ -0,0 +1,77 @@
+Feature: Repo switcher
+  As a developer with several repositories
+  I want to switch the dashboard between repositories sprout knows about
+  So that I can see every repo's worktrees from one place
+
+  Background:
+    Given the following worktrees exist:
+      | branch         | path                           | updated_at           | merged |
+      | feature-search | /mock/worktrees/feature-search | 2026-05-01T16:00:00Z | false  |
+    And repo "api" is registered with worktrees:
+      | branch         | path                               | updated_at           | merged |
+      | fix-rate-limit | /mock/api-worktrees/fix-rate-limit | 2026-05-02T09:00:00Z | false  |
+    When I start the Sprout TUI
+
+  Scenario: The footer offers the repo switcher when several repos are registered
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      └──feature-search
+      [worktree <tab>] [a all] [s status] [u unassign] [d done] [z undo]
+      [R/ctrl+p repo] [? help]
+      """
+
+  Scenario: The picker lists registered repos with the current one selected
+    When I press "R"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Switch repository:
+        api
+      > sprout (current)
+      [enter switch] [esc back]
+      """
+
+  Scenario: Choosing another repo shows its worktrees
+    When I press "R"
+    And I press "up"
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > api/enter branch name or select suggestion below
+      └──fix-rate-limit
+      [worktree <tab>] [a all] [s status] [u unassign] [d done] [z undo]
+      [R/ctrl+p repo] [? help]
+      """
+
+  Scenario: Escape leaves the current repo in place
+    When I press "R"
+    And I press "up"
+    And I press "esc"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      └──feature-search
+      [worktree <tab>] [a all] [s status] [u unassign] [d done] [z undo]
+      [R/ctrl+p repo] [? help]
+      """
+
+  Scenario: Ctrl+P opens the switcher while typing a branch name
+    When I type "fix-"
+    And I press "ctrl+p"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Switch repository:
+        api
+      > sprout (current)
+      [enter switch] [esc back]
+      """
@@ -40,3 +40,25 @@ Feature: Resume command execution
     And I press "enter"
     Then no post-resume command should run
     And the TUI should resume worktree "/mock/worktrees/feature-search"
+
+  Scenario: Pulling a worktree's PR resumes it at the latest head
+    Given a config with:
+      | key           | value           |
+      | resumeCommand | claude --resume |
+    And the following worktrees exist:
+      | branch         | path                           | updated_at           | merged |
+      | feature-search | /mock/worktrees/feature-search | 2026-05-01T16:00:00Z | false  |
+    And "feature-search" has PR #42 on branch "feature-search"
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "f"
+    Then the post-resume command should be "cd /mock/worktrees/feature-search && claude --resume"
+
+  Scenario: Pulling a PR that can't be found says so
+    Given the following worktrees exist:
+      | branch         | path                           | updated_at           | merged |
+      | feature-search | /mock/worktrees/feature-search | 2026-05-01T16:00:00Z | false  |
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "f"
+    Then the UI should display "no pull request found for feature-search"
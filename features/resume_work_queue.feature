@@ -28,7 +28,7 @@ Feature: Resume work from an interleaved work queue
       ├──SPR-124   In Progress  Dashboard analytics
       ├──SPR-140   Todo         Fix onboarding copy
       └──misc-cleanup
-      [worktree <tab>] [a all] [u unassign] [d done] [z undo]
+      [worktree <tab>] [a all] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Matching worktree and Linear ticket render as a single Linear row
@@ -86,7 +86,7 @@ Feature: Resume work from an interleaved work queue
       │  └──+ Add subtask
       ├──SPR-140   Todo         Fix onboarding copy
       └──misc-cleanup
-      [worktree <tab>] [a all] [u unassign] [d done] [z undo]
+      [worktree <tab>] [a all] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Worktree-only rows are leaves
@@ -102,7 +102,7 @@ Feature: Resume work from an interleaved work queue
       ├──SPR-124   In Progress  Dashboard analytics
       ├──SPR-140   Todo         Fix onboarding copy
       └──misc-cleanup
-      [worktree <tab>] [a all] [u unassign] [d done] [z undo]
+      [worktree <tab>] [a all] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Closed and merged rows are hidden by default
@@ -124,7 +124,7 @@ Feature: Resume work from an interleaved work queue
       ├──misc-cleanup
       ├──SPR-141   Done         Old auth cleanup
       └──old-merged-branch
-      [worktree <tab>] [a active] [u unassign] [d done] [z undo]
+      [worktree <tab>] [a active] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Default list is limited to twenty active rows
@@ -137,3 +137,10 @@ Feature: Resume work from an interleaved work queue
     When I type "/search"
     Then the UI should display "feature-search"
     And the UI should not display "SPR-124"
+
+  Scenario: Worktree rows show their cached disk usage
+    Given the following worktrees exist:
+      | branch    | path                      | updated_at           | merged | size |
+      | big-build | /mock/worktrees/big-build | 2026-05-03T08:00:00Z | false  | 12GB |
+    When I start the Sprout TUI
+    Then the UI should display "big-build  12.0 GB"
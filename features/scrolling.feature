@@ -0,0 +1,105 @@
+Feature: Scrolling work queue
+  As a developer with a long list of assigned tickets
+  I want the work queue to scroll within my terminal
+  So that the top of the list is never cut off and my selection stays in view
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title          | parent_id | status |
+      | SPR-1      | First ticket   |           | Todo   |
+      | SPR-2      | Second ticket  |           | Todo   |
+      | SPR-3      | Third ticket   |           | Todo   |
+      | SPR-4      | Fourth ticket  |           | Todo   |
+      | SPR-5      | Fifth ticket   |           | Todo   |
+      | SPR-6      | Sixth ticket   |           | Todo   |
+      | SPR-7      | Seventh ticket |           | Todo   |
+      | SPR-8      | Eighth ticket  |           | Todo   |
+      | SPR-9      | Ninth ticket   |           | Todo   |
+      | SPR-10     | Tenth ticket   |           | Todo   |
+
+  Scenario: A tall terminal shows the whole tree
+    When I start the Sprout TUI
+    Then the UI should display "🌱 sprout"
+    And the UI should display "SPR-10"
+    And the UI should not display "more"
+
+  Scenario: A short terminal drops the header and shows how much is below
+    Given my terminal height is 10 lines
+    When I start the Sprout TUI
+    Then the UI should display:
+      """
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-1   Todo  First ticket
+      ├──SPR-2   Todo  Second ticket
+      ├──SPR-3   Todo  Third ticket
+      ├──SPR-4   Todo  Fourth ticket
+      ├──SPR-5   Todo  Fifth ticket
+      ├──SPR-6   Todo  Sixth ticket
+      ├──SPR-7   Todo  Seventh ticket
+      ↓ 3 more
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Moving past the bottom scrolls the selection into view
+    Given my terminal height is 10 lines
+    When I start the Sprout TUI
+    And I press "down" 9 times
+    Then the UI should display:
+      """
+      > sprout/spr-9-ninth-ticket
+      ↑ 3 more
+      ├──SPR-4   Todo  Fourth ticket
+      ├──SPR-5   Todo  Fifth ticket
+      ├──SPR-6   Todo  Sixth ticket
+      ├──SPR-7   Todo  Seventh ticket
+      ├──SPR-8   Todo  Eighth ticket
+      ├──SPR-9   Todo  Ninth ticket
+      └──SPR-10  Todo  Tenth ticket
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Moving back up keeps the list where it is until the selection reaches the top
+    Given my terminal height is 10 lines
+    When I start the Sprout TUI
+    And I press "down" 9 times
+    And I press "up" 3 times
+    Then the UI should display "> sprout/spr-6-sixth-ticket"
+    And the UI should display "↑ 3 more"
+    And the UI should display "SPR-10"
+
+  Scenario: Moving above the window scrolls it back up
+    Given my terminal height is 10 lines
+    When I start the Sprout TUI
+    And I press "down" 9 times
+    And I press "up" 7 times
+    Then the UI should display:
+      """
+      > sprout/spr-2-second-ticket
+      ↑ 1 more
+      ├──SPR-2   Todo  Second ticket
+      ├──SPR-3   Todo  Third ticket
+      ├──SPR-4   Todo  Fourth ticket
+      ├──SPR-5   Todo  Fifth ticket
+      ├──SPR-6   Todo  Sixth ticket
+      ├──SPR-7   Todo  Seventh ticket
+      ↓ 3 more
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: The last row shows only the indicator above it
+    Given my terminal height is 10 lines
+    When I start the Sprout TUI
+    And I press "up"
+    Then the UI should display:
+      """
+      > sprout/spr-10-tenth-ticket
+      ↑ 3 more
+      ├──SPR-4   Todo  Fourth ticket
+      ├──SPR-5   Todo  Fifth ticket
+      ├──SPR-6   Todo  Sixth ticket
+      ├──SPR-7   Todo  Seventh ticket
+      ├──SPR-8   Todo  Eighth ticket
+      ├──SPR-9   Todo  Ninth ticket
+      └──SPR-10  Todo  Tenth ticket
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
@@ -27,7 +27,7 @@ Feature: Sprout TUI Fuzzy Search
       ├──SPR-127  In Review    Fix critical bug in payment processing
       ├──SPR-128  Backlog      Update user profile settings
       └──SPR-129  Todo         Implement notification system
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Filter issues by typing partial text
@@ -40,7 +40,7 @@ Feature: Sprout TUI Fuzzy Search
 
       /auth
       └──SPR-123  Todo  Add user authentication
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Filter issues by identifier
@@ -53,7 +53,7 @@ Feature: Sprout TUI Fuzzy Search
 
       /127
       └──SPR-127  In Review  Fix critical bug in payment processing
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Filter shows multiple matches
@@ -67,9 +67,51 @@ Feature: Sprout TUI Fuzzy Search
       /user
       ├──SPR-123  Todo     Add user authentication
       └──SPR-128  Backlog  Update user profile settings
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
+  Scenario: Subtasks match with their parent shown above them
+    Given I start the Sprout TUI
+    And I press "/"
+    When I type "126"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      /126
+      └──SPR-124  In Progress  Implement dashboard with analytics and re...
+         └──SPR-126  Done         Add reporting metrics
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+    And the search match in "SPR-126" should be "126"
+    And the search match in "SPR-124" should be ""
+
+  Scenario: Closer matches rank first
+    Given I start the Sprout TUI
+    And I press "/"
+    When I type "set"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      /set
+      ├──SPR-128  Backlog      Update user profile settings
+      ├──SPR-124  In Progress  Implement dashboard with analytics and re...
+      │  ├──SPR-125  Todo         Create analytics component
+      │  └──SPR-126  Done         Add reporting metrics
+      ├──SPR-127  In Review    Fix critical bug in payment processing
+      ├──SPR-129  Todo         Implement notification system
+      └──SPR-123  Todo         Add user authentication
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+    And the search match in "SPR-128" should be "set"
+
+  Scenario: Matched characters are highlighted
+    Given I start the Sprout TUI
+    And I press "/"
+    When I type "spr127"
+    Then the search match in "SPR-127" should be "spr127"
+
   Scenario: No matches found
     Given I start the Sprout TUI
     And I press "/"
@@ -79,7 +121,7 @@ Feature: Sprout TUI Fuzzy Search
       🌱 sprout
 
       /xyz
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Clear search and return to normal mode
@@ -97,7 +139,7 @@ Feature: Sprout TUI Fuzzy Search
       ├──SPR-127  In Review    Fix critical bug in payment processing
       ├──SPR-128  Backlog      Update user profile settings
       └──SPR-129  Todo         Implement notification system
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Navigate search results with arrow keys
@@ -112,7 +154,7 @@ Feature: Sprout TUI Fuzzy Search
       /user sprout/spr-123-add-user-authentication
       ├──SPR-123  Todo     Add user authentication
       └──SPR-128  Backlog  Update user profile settings
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "down"
     Then the UI should display:
@@ -122,7 +164,7 @@ Feature: Sprout TUI Fuzzy Search
       /user sprout/spr-128-update-user-profile-settings
       ├──SPR-123  Todo     Add user authentication
       └──SPR-128  Backlog  Update user profile settings
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "up"
     Then the UI should display:
@@ -132,7 +174,7 @@ Feature: Sprout TUI Fuzzy Search
       /user sprout/spr-123-add-user-authentication
       ├──SPR-123  Todo     Add user authentication
       └──SPR-128  Backlog  Update user profile settings
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
 
   Scenario: Backspace works in search mode
@@ -146,9 +188,9 @@ Feature: Sprout TUI Fuzzy Search
 
       /aut
       ├──SPR-123  Todo       Add user authentication
-      ├──SPR-127  In Review  Fix critical bug in payment processing
-      └──SPR-128  Backlog    Update user profile settings
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      ├──SPR-128  Backlog    Update user profile settings
+      └──SPR-127  In Review  Fix critical bug in payment processing
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "backspace"
     Then the UI should display:
@@ -157,9 +199,9 @@ Feature: Sprout TUI Fuzzy Search
 
       /au
       ├──SPR-123  Todo       Add user authentication
-      ├──SPR-127  In Review  Fix critical bug in payment processing
-      └──SPR-128  Backlog    Update user profile settings
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      ├──SPR-128  Backlog    Update user profile settings
+      └──SPR-127  In Review  Fix critical bug in payment processing
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "backspace"
     Then the UI should display:
@@ -169,10 +211,12 @@ Feature: Sprout TUI Fuzzy Search
       /a
       ├──SPR-123  Todo         Add user authentication
       ├──SPR-124  In Progress  Implement dashboard with analytics and re...
-      ├──SPR-127  In Review    Fix critical bug in payment processing
+      │  ├──SPR-125  Todo         Create analytics component
+      │  └──SPR-126  Done         Add reporting metrics
       ├──SPR-128  Backlog      Update user profile settings
+      ├──SPR-127  In Review    Fix critical bug in payment processing
       └──SPR-129  Todo         Implement notification system
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
     When I press "backspace"
     Then the UI should display:
@@ -185,5 +229,5 @@ Feature: Sprout TUI Fuzzy Search
       ├──SPR-127  In Review    Fix critical bug in payment processing
       ├──SPR-128  Backlog      Update user profile settings
       └──SPR-129  Todo         Implement notification system
-      [worktree <tab>] [u unassign] [d done] [z undo]
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
       """
\ No newline at end of file
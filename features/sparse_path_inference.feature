@@ -0,0 +1,63 @@
+Feature: Infer sparse checkout directories from an issue
+  As a developer working in a monorepo
+  I want sprout to check out only the directories an issue's labels or project map to
+  So that I don't have to pick them by hand for every ticket
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title               | parent_id | status | labels        | project  |
+      | SPR-201    | Fix refund rounding |           | Todo   | team-payments |          |
+      | SPR-202    | Update landing page |           | Todo   |               | Website  |
+      | SPR-203    | Tidy up docs        |           | Todo   | docs          |          |
+    And the repo config maps sparse paths:
+      | kind    | name          | directories       |
+      | label   | team-payments | services/payments |
+      | project | Website       | web               |
+      | always  |               | libs              |
+
+  Scenario: A matching label creates a sparse worktree
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "enter"
+    Then the following commands should be run:
+      | command                                                                                          |
+      | git worktree add /mock/worktrees/spr-201-fix-refund-rounding -b spr-201-fix-refund-rounding main |
+      | git sparse-checkout set services/payments libs                                                   |
+
+  Scenario: A matching project creates a sparse worktree
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "down"
+    And I press "enter"
+    Then the following commands should be run:
+      | command                                                                                          |
+      | git worktree add /mock/worktrees/spr-202-update-landing-page -b spr-202-update-landing-page main |
+      | git sparse-checkout set web libs                                                                 |
+
+  Scenario: Issues without a mapping get a full checkout
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "down"
+    And I press "down"
+    And I press "enter"
+    Then the following commands should be run:
+      | command                                                                            |
+      | git worktree add /mock/worktrees/spr-203-tidy-up-docs -b spr-203-tidy-up-docs main |
+
+  Scenario: Inferred directories are offered first in the profile picker
+    Given the following sparse profiles exist:
+      | name | directories  |
+      | api  | services/api |
+    And I start the Sprout TUI
+    When I press "down"
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Sparse checkout for spr-201-fix-refund-rounding:
+      > inferred  services/payments, libs
+        full checkout
+        api  services/api
+      [enter create] [esc back]
+      """
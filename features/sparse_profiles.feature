@@ -0,0 +1,57 @@
+Feature: Choose a sparse checkout profile when creating a worktree
+  As a developer working in a monorepo
+  I want to pick one of my saved sparse profiles before a worktree is created
+  So that I only check out the directories I need
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title                   | parent_id | status |
+      | SPR-123    | Add user authentication |           | Todo   |
+    And the following sparse profiles exist:
+      | name | directories        |
+      | api  | services/api, libs |
+      | web  | web                |
+
+  Scenario: Profile picker is shown before creating a worktree
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Sparse checkout for spr-123-add-user-authentication:
+      > full checkout
+        api  services/api, libs
+        web  web
+      [enter create] [esc back]
+      """
+
+  Scenario: Selecting a profile creates a sparse worktree
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "enter"
+    And I press "down"
+    And I press "enter"
+    Then the following commands should be run:
+      | command                                                                                                  |
+      | git worktree add /mock/worktrees/spr-123-add-user-authentication -b spr-123-add-user-authentication main |
+      | git sparse-checkout set services/api libs                                                                |
+
+  Scenario: Full checkout keeps the normal create flow
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "enter"
+    And I press "enter"
+    Then a worktree should be created for branch "spr-123-add-user-authentication"
+    And the following commands should be run:
+      | command                                                                                                  |
+      | git worktree add /mock/worktrees/spr-123-add-user-authentication -b spr-123-add-user-authentication main |
+
+  Scenario: Escape returns to the work queue without creating anything
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "enter"
+    And I press "esc"
+    Then no new worktree should be created
+    And the UI should display "SPR-123"
@@ -0,0 +1,61 @@
+Feature: Sprout TUI Status Picker
+  As a developer using Sprout
+  I want to change a ticket's status from the work queue
+  So that I can move tickets without leaving the terminal
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title                      | parent_id | status      |
+      | SPR-100    | Feature A: User management |           | In Progress |
+      | SPR-200    | Feature B: Dashboard       |           | Todo        |
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "s"
+
+  Scenario: The picker lists the team's workflow states with the current one selected
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Status for SPR-100 Feature A: User management:
+        Backlog
+        Todo
+      > In Progress
+        In Review
+        Done
+        Canceled
+      [enter apply] [esc back]
+      """
+
+  Scenario: Moving an issue to another open state updates it in place
+    When I press "down"
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/spr-100-feature-a-user-management
+      Suggested: SPR-100 — In Review  [x select]
+      ├──SPR-100  In Review  Feature A: User management
+      └──SPR-200  Todo       Feature B: Dashboard
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Moving an issue to a closed state removes it from the queue
+    When I press "down"
+    And I press "down"
+    And I press "enter"
+    Then the UI should not display "SPR-100"
+    And the UI should contain "SPR-200"
+
+  Scenario: Escape closes the picker without changing the issue
+    When I press "esc"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/spr-100-feature-a-user-management
+      ├──SPR-100  In Progress  Feature A: User management
+      └──SPR-200  Todo         Feature B: Dashboard
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
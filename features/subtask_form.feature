@@ -0,0 +1,122 @@
+Feature: Sprout TUI Subtask Form
+  As a developer using Sprout
+  I want to describe, estimate and prioritise a subtask as I create it
+  So that new subtasks don't need a trip to Linear afterwards
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title                     | parent_id | status      |
+      | SPR-100    | Feature A: User management |           | In Progress |
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "right"
+    And I press "down"
+    And I press "right"
+    And I type "Write migration tests"
+
+  Scenario: Enter creates a subtask from the title alone
+    When I press "enter"
+    Then the subtask should be created with:
+      | field       | value                 |
+      | title       | Write migration tests |
+      | description |                       |
+      | estimate    |                       |
+      | priority    |                       |
+
+  Scenario: Tab expands the form with description, estimate and priority
+    When I press "tab"
+    Then the UI should contain "Description:"
+    And the UI should contain "Estimate: ‹ none ›"
+    And the UI should contain "Priority: ‹ No priority ›"
+    And the UI should contain "[tab next field] [←/→ change] [enter create] [esc cancel]"
+
+  Scenario: Fill in every field before creating the subtask
+    When I press "tab"
+    And I type "Cover the rollback path"
+    And I press "shift+enter"
+    And I type "and the happy path"
+    And I press "tab"
+    And I press "right"
+    And I press "right"
+    And I press "tab"
+    And I press "right"
+    And I press "right"
+    Then the UI should contain "Estimate: ‹ 2 ›"
+    And the UI should contain "Priority: ‹ High ›"
+    When I press "enter"
+    Then the subtask should be created with:
+      | field       | value                                       |
+      | title       | Write migration tests                       |
+      | description | Cover the rollback path\nand the happy path |
+      | estimate    | 2                                           |
+      | priority    | 2                                           |
+
+  Scenario: Tab cycles back to the title
+    When I press "tab"
+    And I press "tab"
+    And I press "tab"
+    And I press "tab"
+    And I type " now"
+    And I press "left"
+    And I press "enter"
+    Then the subtask should be created with:
+      | field | value                     |
+      | title | Write migration tests now |
+
+  Scenario: Shift+tab moves to the previous field
+    When I press "shift+tab"
+    And I press "left"
+    And I press "enter"
+    Then the subtask should be created with:
+      | field    | value |
+      | priority | 4     |
+
+  Scenario: Escape discards the form
+    When I press "tab"
+    And I press "esc"
+    Then the UI should not display "Description:"
+    And the UI should contain "+ Add subtask"
+
+  Scenario: Pasting a checklist creates a subtask for each line
+    When I paste:
+      """
+
+      - Backfill old rows
+      - [ ] Drop the column
+      """
+    Then the UI should contain "Checklist: 3 subtask(s), one per line"
+    And the UI should contain "[shift+enter new line] [enter create all] [esc cancel]"
+    When I press "enter"
+    Then subtasks should be created with the titles:
+      | title                 |
+      | Write migration tests |
+      | Backfill old rows     |
+      | Drop the column       |
+    And the UI should contain "✓ SPR-1001 Write migration tests"
+    And the UI should contain "✓ SPR-1003 Drop the column"
+    And the UI should contain "Created 3 subtasks"
+
+  Scenario: A checklist carries on past a subtask that fails
+    Given Linear fails to create the subtask "Backfill old rows"
+    When I press "ctrl+l"
+    And I press "shift+enter"
+    And I type "Backfill old rows"
+    And I press "shift+enter"
+    And I type "Drop the column"
+    And I press "enter"
+    Then subtasks should be created with the titles:
+      | title                 |
+      | Write migration tests |
+      | Backfill old rows     |
+      | Drop the column       |
+    And the UI should contain "✗ Backfill old rows"
+    And the UI should contain "✓ SPR-1002 Drop the column"
+    And the UI should contain "Created 2 of 3 subtasks; 1 failed"
+    When I press "down"
+    Then the UI should not display "✓ SPR-1002 Drop the column"
+
+  Scenario: Escape discards a checklist
+    When I press "ctrl+l"
+    And I press "esc"
+    Then the UI should not display "Checklist:"
+    And the UI should contain "+ Add subtask"
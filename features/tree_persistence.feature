@@ -0,0 +1,86 @@
+Feature: Issue tree persistence
+  As a developer using Sprout
+  I want the issue tree to open the way I left it
+  So that I can pick up where I was without expanding everything again
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title                         | parent_id | status      |
+      | SPR-100    | Feature A: User management    |           | In Progress |
+      | SPR-101    | Add user registration         | SPR-100   | Done        |
+      | SPR-102    | Implement authentication      | SPR-100   | Todo        |
+      | SPR-110    | Hash passwords                | SPR-102   | Todo        |
+      | SPR-200    | Feature B: Dashboard          |           | Todo        |
+      | SPR-300    | Bug fix: Payment errors       |           | In Review   |
+
+  Scenario: Expanded issues and the selection come back next session
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "right"
+    And I press "down"
+    And I press "down"
+    And I quit and start the Sprout TUI again
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/spr-102-implement-authentication
+      ├──SPR-100  In Progress  Feature A: User management
+      │  ├──SPR-101  Done         Add user registration
+      │  ├──SPR-102  Todo         Implement authentication
+      │  └──+ Add subtask
+      ├──SPR-200  Todo         Feature B: Dashboard
+      └──SPR-300  In Review    Bug fix: Payment errors
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Nested issues are prefetched level by level
+    Given the last session left "SPR-100, SPR-102" expanded and "SPR-110" selected
+    When I start the Sprout TUI
+    Then the UI should display "SPR-110  Todo         Hash passwords"
+    And the UI should display "> sprout/spr-110-hash-passwords"
+
+  Scenario: Collapsing before quitting is remembered too
+    Given the last session left "SPR-100" expanded and "SPR-100" selected
+    When I start the Sprout TUI
+    And I press "left"
+    And I quit and start the Sprout TUI again
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/spr-100-feature-a-user-management
+      ├──SPR-100  In Progress  Feature A: User management
+      ├──SPR-200  Todo         Feature B: Dashboard
+      └──SPR-300  In Review    Bug fix: Payment errors
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Issues no longer assigned are skipped
+    Given the last session left "SPR-999" expanded and "SPR-999" selected
+    When I start the Sprout TUI
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-100  In Progress  Feature A: User management
+      ├──SPR-200  Todo         Feature B: Dashboard
+      └──SPR-300  In Review    Bug fix: Payment errors
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Saved issues fetch their subtasks together
+    Given the following Linear issues exist:
+      | identifier | title                      | parent_id | status      |
+      | SPR-100    | Feature A: User management |           | In Progress |
+      | SPR-101    | Add user registration      | SPR-100   | Done        |
+      | SPR-200    | Feature B: Dashboard       |           | Todo        |
+      | SPR-201    | Draw the charts            | SPR-200   | Todo        |
+      | SPR-300    | Bug fix: Payment errors    |           | In Review   |
+      | SPR-301    | Retry failed charges       | SPR-300   | Todo        |
+    And the last session left "SPR-100, SPR-200" expanded and "SPR-201" selected
+    When I start the Sprout TUI
+    Then the UI should display "SPR-101  Done         Add user registration"
+    And the UI should display "> sprout/spr-201-draw-the-charts"
+    And the subtasks of "SPR-100, SPR-200" should have been fetched in one request
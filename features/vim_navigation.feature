@@ -0,0 +1,104 @@
+Feature: Vim-style navigation
+  As a heavy keyboard user with a long list of tickets
+  I want vim's movement and jump keys in the work queue
+  So that I can get around a long tree without reaching for the arrow keys
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title          | parent_id | status |
+      | SPR-1      | First ticket   |           | Todo   |
+      | SPR-2      | Second ticket  |           | Todo   |
+      | SPR-3      | Third ticket   |           | Todo   |
+      | SPR-4      | Fourth ticket  |           | Todo   |
+      | SPR-5      | Fifth ticket   |           | Todo   |
+      | SPR-6      | Sixth ticket   |           | Todo   |
+      | SPR-7      | Seventh ticket |           | Todo   |
+      | SPR-8      | Eighth ticket  |           | Todo   |
+      | SPR-9      | Ninth ticket   |           | Todo   |
+      | SPR-10     | Tenth ticket   |           | Todo   |
+      | SPR-11     | Subtask        | SPR-1      | Todo   |
+
+  Scenario: j and k move the selection and h collapses
+    When I start the Sprout TUI
+    And I press "j"
+    And I press "right"
+    Then the UI should display "├──SPR-11  Todo  Subtask"
+    When I press "j"
+    And I press "k"
+    And I press "h"
+    Then the UI should display "> sprout/spr-1-first-ticket"
+    And the UI should not display "SPR-11"
+
+  Scenario: h still types into an empty branch name
+    When I start the Sprout TUI
+    And I type "hotfix"
+    Then the UI should display "> sprout/hotfix"
+
+  Scenario: l expands as the right arrow does
+    When I start the Sprout TUI
+    And I press "j"
+    And I press "l"
+    Then the UI should display "├──SPR-11  Todo  Subtask"
+    When I press "h"
+    Then the UI should not display "SPR-11"
+
+  Scenario: Home and end jump to the first and last rows
+    When I start the Sprout TUI
+    And I press "end"
+    Then the UI should display "> sprout/spr-10-tenth-ticket"
+    When I press "home"
+    Then the UI should display "> sprout/spr-1-first-ticket"
+
+  Scenario: Half-page keys move half a screen and scroll with it
+    Given my terminal height is 10 lines
+    When I start the Sprout TUI
+    And I press "ctrl+d"
+    Then the UI should display "> sprout/spr-4-fourth-ticket"
+    And the UI should display "↓ 3 more"
+    When I press "ctrl+d"
+    Then the UI should display:
+      """
+      > sprout/spr-8-eighth-ticket
+      ↑ 3 more
+      ├──SPR-4   Todo  Fourth ticket
+      ├──SPR-5   Todo  Fifth ticket
+      ├──SPR-6   Todo  Sixth ticket
+      ├──SPR-7   Todo  Seventh ticket
+      ├──SPR-8   Todo  Eighth ticket
+      ├──SPR-9   Todo  Ninth ticket
+      └──SPR-10  Todo  Tenth ticket
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+    When I press "ctrl+u"
+    Then the UI should display "> sprout/spr-4-fourth-ticket"
+
+  Scenario: A number selects that issue among those shown
+    When I start the Sprout TUI
+    And I press "3"
+    Then the UI should display "> sprout/spr-3-third-ticket"
+    When I press "9"
+    Then the UI should display "> sprout/spr-9-ninth-ticket"
+
+  Scenario: Numbers count from the top of the scrolled tree
+    Given my terminal height is 10 lines
+    When I start the Sprout TUI
+    And I press "end"
+    And I press "1"
+    Then the UI should display "> sprout/spr-4-fourth-ticket"
+
+  Scenario: gg and G jump to the first and last rows as in vim
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "G"
+    Then the UI should display "> sprout/spr-10-tenth-ticket"
+    When I press "g"
+    Then the UI should display "> sprout/spr-10-tenth-ticket"
+    When I press "g"
+    Then the UI should display "> sprout/spr-1-first-ticket"
+
+  Scenario: A key that doesn't finish a sequence is used as itself
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "g"
+    And I press "j"
+    Then the UI should display "> sprout/spr-2-second-ticket"
@@ -58,3 +58,62 @@ Feature: Work queue loading status
     When I start the Sprout TUI
     Then the UI should not display "gh pr list --head feature-search --state all --json state --limit 1"
     And the UI should not display "feature-search"
+
+  Scenario: Worktrees still show when Linear doesn't respond in time
+    Given 1 active work queue rows exist
+    And Linear doesn't respond in time
+    When I start the Sprout TUI
+    Then the UI should display "Error: Linear didn't respond in time (waited 20ms); showing worktrees only"
+    And the UI should show 1 work queue rows
+
+  Scenario: Loading renders the same screen every time
+    Given Linear issue loading is paused
+    And worktree loading is paused at "git worktree list --porcelain"
+    When I start the Sprout TUI
+    And the spinner ticks
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ⣾  Loading Linear issues...
+      ⣾  git worktree list --porcelain
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Issues are listed as each page of them arrives
+    Given the following Linear issues exist:
+      | identifier | title                   | parent_id | status      |
+      | SPR-1      | Add user authentication |           | Todo        |
+      | SPR-2      | Fix login redirect      |           | In Progress |
+      | SPR-3      | Write onboarding docs   |           | Todo        |
+    And Linear sends my issues 2 at a time
+    When I start the Sprout TUI
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──SPR-1  Todo         Add user authentication
+      └──SPR-2  In Progress  Fix login redirect
+      [worktree <tab>] [s status] [u unassign] [d done] [z undo] [? help]
+      ⣾  loaded 2/3 issues
+      """
+    When the rest of my issues arrive
+    Then the UI should display "Write onboarding docs"
+    And the UI should not display "loaded"
+
+  Scenario: An issue selected while the rest load stays selected
+    Given the following Linear issues exist:
+      | identifier | title                   | parent_id | status      |
+      | SPR-1      | Add user authentication |           | Todo        |
+      | SPR-2      | Fix login redirect      |           | In Progress |
+      | SPR-3      | Write onboarding docs   |           | Todo        |
+    And Linear sends my issues 2 at a time
+    When I start the Sprout TUI
+    And I press "down"
+    And I press "down"
+    And the rest of my issues arrive
+    Then the UI should display "sprout/spr-2-fix-login-redirect"
+    When I press "down"
+    Then the UI should display "sprout/spr-3-write-onboarding-docs"
@@ -0,0 +1,51 @@
+Feature: Notes on worktrees
+  As a developer juggling many branches
+  I want to keep a short note on each worktree
+  So that I remember what each one is waiting on when I come back to it
+
+  Background:
+    Given the following worktrees exist:
+      | branch         | path                           | updated_at           | merged | note                     |
+      | feature-search | /mock/worktrees/feature-search | 2026-05-01T16:00:00Z | false  | waiting on design review |
+      | misc-cleanup   | /mock/worktrees/misc-cleanup   | 2026-04-29T10:00:00Z | false  |                          |
+
+  Scenario: Notes are shown beside their worktree
+    Given I start the Sprout TUI
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/enter branch name or select suggestion below
+      ├──feature-search  📝 waiting on design review
+      └──misc-cleanup
+      [worktree <tab>] [a all] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Adding a note to the selected worktree
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "down"
+    And I press "e"
+    Then the UI should display "Note on misc-cleanup:"
+    When I type "blocked by API-9"
+    And I press "enter"
+    Then the UI should display "└──misc-cleanup  📝 blocked by API-9"
+
+  Scenario: Editing starts from the note there is, and clearing it removes it
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "e"
+    Then the UI should display "> waiting on design review"
+    When I press "ctrl+u"
+    And I press "enter"
+    Then the UI should display "├──feature-search"
+    And the UI should not display "📝"
+
+  Scenario: Escape leaves the note alone
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "e"
+    And I type " and QA"
+    And I press "esc"
+    Then the UI should display "├──feature-search  📝 waiting on design review"
+    And the UI should not display "and QA"
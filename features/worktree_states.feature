@@ -0,0 +1,52 @@
+Feature: Detached, locked and bare worktrees in the work queue
+  As a developer using Sprout
+  I want worktrees git reports as detached or locked shown for what they are
+  So that they don't turn up nameless or get mistaken for ordinary branches
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title               | parent_id | status | updated_at           |
+      | SPR-140    | Fix onboarding copy |           | Todo   | 2026-04-30T12:00:00Z |
+    And the following worktrees exist:
+      | branch         | path                           | updated_at           | merged | commit                                   | state    |
+      | feature-search | /mock/worktrees/feature-search | 2026-05-01T16:00:00Z | false  | 1111111111111111111111111111111111111111 | locked   |
+      |                | /mock/worktrees/bisect         | 2026-04-29T10:00:00Z | false  | 2222222222222222222222222222222222222222 | detached |
+      |                | /mock/sprout.git               | 2026-04-28T10:00:00Z | false  |                                          | bare     |
+
+  Scenario: Detached worktrees are named by their commit and locked ones are marked
+    When I start the Sprout TUI
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      > sprout/█enter branch name or select suggestion below
+      ├──feature-search  locked
+      ├──SPR-140   Todo  Fix onboarding copy
+      └──(detached at 22222222)
+      [worktree <tab>] [a all] [s status] [u unassign] [d done] [z undo] [? help]
+      """
+
+  Scenario: Selecting a detached worktree resumes it
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "down"
+    And I press "down"
+    And I press "enter"
+    Then the TUI should resume worktree "/mock/worktrees/bisect"
+    And no new worktree should be created
+
+  Scenario: Pinned worktrees are marked with a pin
+    Given the following worktrees exist:
+      | branch    | path                      | updated_at           | merged | state  |
+      | release-2 | /mock/worktrees/release-2 | 2026-05-01T16:00:00Z | false  | pinned |
+    When I start the Sprout TUI
+    Then the UI should display "release-2  📌 pinned"
+
+  Scenario: Worktrees with an open PR show how its checks went
+    Given the following worktrees exist:
+      | branch    | path                      | updated_at           | merged | ci      |
+      | feature-a | /mock/worktrees/feature-a | 2026-05-01T16:00:00Z | false  | failing |
+      | feature-b | /mock/worktrees/feature-b | 2026-04-30T16:00:00Z | false  | passing |
+    When I start the Sprout TUI
+    Then the UI should display "feature-a  ✗ CI"
+    And the UI should display "feature-b  ✓ CI"
@@ -0,0 +1,76 @@
+Feature: Worktree templates per branch type
+  As a developer who starts fixes, features and spikes differently
+  I want the template for a branch's prefix or issue label applied as the worktree is created
+  So that each kind of work starts from the right base with the right setup
+
+  Background:
+    Given the following Linear issues exist:
+      | identifier | title               | parent_id | status | labels |
+      | SPR-1      | Fix refund rounding |           | Todo   | bug    |
+      | SPR-2      | Update landing page |           | Todo   |        |
+    And the following sparse profiles exist:
+      | name | directories  |
+      | api  | services/api |
+    And the following worktree templates exist:
+      | prefix | base    | sparse_profile | hooks      | default_command | labels |
+      | fix/   | develop | api            | make setup |                 | bug    |
+      | spike/ |         |                |            | claude          |        |
+
+  Scenario: An issue's label picks the template and its prefix
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "enter"
+    Then a worktree should be created for branch "fix/spr-1-fix-refund-rounding"
+    And the following commands should be run:
+      | command                                                                                                  |
+      | git worktree add /mock/worktrees/fix/spr-1-fix-refund-rounding -b fix/spr-1-fix-refund-rounding develop |
+      | git sparse-checkout set services/api                                                                     |
+      | sh -c make setup                                                                                         |
+
+  Scenario: Other branches offer a template picker
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "down"
+    And I press "enter"
+    Then the UI should display:
+      """
+      🌱 sprout
+
+      Template for spr-2-update-landing-page:
+      > no template
+        fix/  from develop, api profile
+        spike/  runs claude
+      [enter choose] [esc back]
+      """
+
+  Scenario: Choosing a template prefixes the branch and uses its default command
+    Given the default worktree command is "code ."
+    And I start the Sprout TUI
+    When I press "down"
+    And I press "down"
+    And I press "enter"
+    And I press "down"
+    And I press "down"
+    And I press "enter"
+    And I press "enter"
+    Then the following commands should be run:
+      | command                                                                                                    |
+      | git worktree add /mock/worktrees/spike/spr-2-update-landing-page -b spike/spr-2-update-landing-page main |
+      | cd /mock/worktrees/spike/spr-2-update-landing-page && claude                                             |
+
+  Scenario: No template keeps the normal create flow
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "down"
+    And I press "enter"
+    And I press "enter"
+    Then the UI should display "Sparse checkout for spr-2-update-landing-page:"
+
+  Scenario: Escape leaves the template picker without creating anything
+    Given I start the Sprout TUI
+    When I press "down"
+    And I press "down"
+    And I press "enter"
+    And I press "esc"
+    Then no new worktree should be created
+    And the UI should display "SPR-2"
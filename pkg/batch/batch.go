@@ -0,0 +1,134 @@
+package batch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// Task is a directory to run the command in, with the label that prefixes its output
+type Task struct {
+	Label string
+	Dir   string
+}
+
+// Result is how the command finished in one task
+type Result struct {
+	Task     Task
+	ExitCode int // -1 when the command couldn't be started
+	Err      error
+}
+
+// RunFunc runs command in dir, sending its output to stdout and stderr
+type RunFunc func(dir string, command []string, stdout, stderr io.Writer) error
+
+// Exec runs command in dir as a child process
+func Exec(dir string, command []string, stdout, stderr io.Writer) error {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// Executor runs one command across many tasks, at most Parallel at a time,
+// streaming each line of output prefixed with its task's label
+type Executor struct {
+	Run      RunFunc
+	Parallel int
+	Stdout   io.Writer
+	Stderr   io.Writer
+}
+
+// Execute runs command in every task and returns the results in task order
+func (e *Executor) Execute(tasks []Task, command []string) []Result {
+	results := make([]Result, len(tasks))
+	if len(tasks) == 0 {
+		return results
+	}
+
+	width := 0
+	for _, task := range tasks {
+		width = max(width, len(task.Label))
+	}
+
+	workerCount := min(max(e.Parallel, 1), len(tasks))
+	jobCh := make(chan int)
+	var mu sync.Mutex // keeps lines from different tasks whole
+	var wg sync.WaitGroup
+
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobCh {
+				task := tasks[index]
+				prefix := fmt.Sprintf("%-*s | ", width, task.Label)
+				stdout := &prefixWriter{prefix: prefix, w: e.Stdout, mu: &mu}
+				stderr := &prefixWriter{prefix: prefix, w: e.Stderr, mu: &mu}
+				err := e.Run(task.Dir, command, stdout, stderr)
+				stdout.flush()
+				stderr.flush()
+				results[index] = Result{Task: task, ExitCode: exitCode(err), Err: err}
+			}
+		}()
+	}
+
+	for index := range tasks {
+		jobCh <- index
+	}
+	close(jobCh)
+	wg.Wait()
+	return results
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// prefixWriter writes whole lines to w, each starting with prefix, holding
+// back a partial line until the rest of it arrives or flush is called
+type prefixWriter struct {
+	prefix  string
+	w       io.Writer
+	mu      *sync.Mutex
+	pending []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.pending = append(p.pending, data...)
+	for {
+		end := bytes.IndexByte(p.pending, '\n')
+		if end < 0 {
+			return len(data), nil
+		}
+		if err := p.writeLine(p.pending[:end+1]); err != nil {
+			return 0, err
+		}
+		p.pending = p.pending[end+1:]
+	}
+}
+
+func (p *prefixWriter) flush() {
+	if len(p.pending) > 0 {
+		_ = p.writeLine(append(p.pending, '\n'))
+		p.pending = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := io.WriteString(p.w, p.prefix+string(line))
+	return err
+}
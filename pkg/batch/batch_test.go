@@ -0,0 +1,85 @@
+package batch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type exitError int
+
+func (e exitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+func (e exitError) ExitCode() int { return int(e) }
+
+func TestExecutePrefixesOutputAndReportsExitCodes(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	executor := &Executor{
+		Run: func(dir string, command []string, out, errOut io.Writer) error {
+			fmt.Fprintf(out, "%s in %s\npartial", strings.Join(command, " "), dir)
+			switch dir {
+			case "/b":
+				fmt.Fprintln(errOut, "conflict")
+				return exitError(2)
+			case "/c":
+				return errors.New("executable file not found")
+			}
+			return nil
+		},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+
+	results := executor.Execute([]Task{{Label: "a", Dir: "/a"}, {Label: "bee", Dir: "/b"}, {Label: "c", Dir: "/c"}}, []string{"git", "fetch"})
+
+	expected := "a   | git fetch in /a\na   | partial\nbee | git fetch in /b\nbee | partial\nc   | git fetch in /c\nc   | partial\n"
+	if stdout.String() != expected {
+		t.Fatalf("Expected stdout:\n%s\ngot:\n%s", expected, stdout.String())
+	}
+	if stderr.String() != "bee | conflict\n" {
+		t.Fatalf("Expected prefixed stderr, got %q", stderr.String())
+	}
+
+	var codes []int
+	for _, result := range results {
+		codes = append(codes, result.ExitCode)
+	}
+	if fmt.Sprint(codes) != "[0 2 -1]" {
+		t.Fatalf("Expected exit codes [0 2 -1] in task order, got %v", codes)
+	}
+}
+
+func TestExecuteRunsAtMostParallelTasksAtOnce(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	executor := &Executor{
+		Run: func(dir string, command []string, out, errOut io.Writer) error {
+			mu.Lock()
+			running++
+			peak = max(peak, running)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		},
+		Parallel: 2,
+		Stdout:   io.Discard,
+		Stderr:   io.Discard,
+	}
+
+	var tasks []Task
+	for i := 0; i < 6; i++ {
+		tasks = append(tasks, Task{Label: fmt.Sprint(i), Dir: fmt.Sprint("/", i)})
+	}
+	executor.Execute(tasks, []string{"true"})
+
+	if peak != 2 {
+		t.Fatalf("Expected at most 2 tasks at once, saw %d", peak)
+	}
+}
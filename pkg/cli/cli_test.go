@@ -3,15 +3,26 @@ package cli
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cucumber/godog"
 	"sprout/pkg/config"
 	"sprout/pkg/git"
+	"sprout/pkg/github"
 	"sprout/pkg/linear"
+	"sprout/pkg/metadata"
+	"sprout/pkg/release"
+	"sprout/pkg/stats"
+	"sprout/pkg/version"
 )
 
 // CLITestContext holds the state for CLI Gherkin tests
@@ -24,30 +35,56 @@ type CLITestContext struct {
 	outputBuffer   *bytes.Buffer
 	errorBuffer    *bytes.Buffer
 	deps           *Dependencies
+	knownRepos     []RepoTarget
+	commandRunner  *MockCommandRunner
+	detacher       *MockDetacher
+	savedEnv       map[string]*string // what setEnv replaced, nil when unset
+	configDir      string             // where the config files a scenario writes go, shown as ~ in output
 	t              *testing.T
 }
 
+const historyTimeLayout = "2006-01-02 15:04"
+
 // NewCLITestContext creates a new CLI test context
 func NewCLITestContext(t *testing.T) *CLITestContext {
 	outputBuffer := &bytes.Buffer{}
 	errorBuffer := &bytes.Buffer{}
+	commandRunner := &MockCommandRunner{ExitCodes: map[string]int{}}
+	detacher := &MockDetacher{}
 	
 	return &CLITestContext{
 		t:              t,
 		outputBuffer:   outputBuffer,
 		errorBuffer:    errorBuffer,
+		commandRunner:  commandRunner,
+		detacher:       detacher,
 		originalStdout: os.Stdout,
 		originalStderr: os.Stderr,
 		deps: &Dependencies{
-			WorktreeManager:    &MockWorktreeManager{Worktrees: []git.Worktree{}},
+			WorktreeManager:    NewMockWorktreeManager([]git.Worktree{}),
 			ConfigLoader:       &MockConfigLoader{Config: &config.Config{}},
 			LinearClient:       nil,
+			LinearAuth:         &MockLinearAuth{},
+			GitHubTokens:       &MockGitHubTokens{},
+			IssueLinks:         &MockIssueLinks{Titles: map[string]string{}},
 			ConfigPathProvider: &MockConfigPathProvider{
 				ConfigPath: "/Users/laurenkt/.sprout.json5",
 				FileExists: true,
 			},
-			Output:      outputBuffer,
-			ErrorOutput: errorBuffer,
+			Metadata:      metadata.NewStoreWithPath("/mock/repo", t.TempDir()+"/metadata.json"),
+			Tmux:          &MockTmuxClient{},
+			Scheduler:     &MockScheduler{},
+			RepoConfig:    &config.RepoConfig{},
+			Editor:        &MockEditorLauncher{},
+			Cloner:        &MockCloner{},
+			Migrator:      &MockMigrator{},
+			Tools:         &MockTools{Git: "2.43.0", GH: "2.40.1"},
+			RunCommand:    commandRunner.Run,
+			StartDetached: detacher.Start,
+			RepoRoot:      "/mock/repo",
+			Interactive:   true,
+			Output:        outputBuffer,
+			ErrorOutput:   errorBuffer,
 		},
 	}
 }
@@ -72,6 +109,9 @@ func (tc *CLITestContext) iRun(command string) error {
 	
 	// Capture output from buffers
 	tc.lastOutput = tc.outputBuffer.String() + tc.errorBuffer.String()
+	if tc.configDir != "" {
+		tc.lastOutput = strings.ReplaceAll(tc.lastOutput, tc.configDir, "~")
+	}
 	
 	return nil
 }
@@ -82,26 +122,415 @@ func (tc *CLITestContext) noWorktreesExist() error {
 	return nil
 }
 
+func (tc *CLITestContext) theInstalledVersionIs(installed string) error {
+	version.Version = installed
+	return nil
+}
+
+func (tc *CLITestContext) theLatestReleaseIs(latest string) error {
+	tc.deps.Updater = &MockUpdater{Release: &release.Release{
+		Version: latest,
+		URL:     "https://github.com/" + release.Repo + "/releases/tag/" + latest,
+	}}
+	return nil
+}
+
+func (tc *CLITestContext) sproutShouldBeUpgradedTo(expected string) error {
+	installed := ""
+	if updater, ok := tc.deps.Updater.(*MockUpdater); ok {
+		installed = updater.Installed
+	}
+	if installed != expected {
+		return fmt.Errorf("expected sprout to be upgraded to %q, got %q", expected, installed)
+	}
+	return nil
+}
+
+// sproutIsInstalled puts a stand-in sprout binary in a directory of its own,
+// which is on the PATH or not
+func (tc *CLITestContext) sproutIsInstalled(onPath bool) error {
+	dir := tc.t.TempDir()
+	tc.deps.Executable = filepath.Join(dir, "sprout")
+	if err := os.WriteFile(tc.deps.Executable, []byte("#!/bin/sh\n"), 0755); err != nil {
+		return err
+	}
+	if onPath {
+		return tc.setEnv("PATH", dir+string(filepath.ListSeparator)+os.Getenv("PATH"))
+	}
+	return nil
+}
+
+func (tc *CLITestContext) gitSproutShouldLinkToSprout() error {
+	shim := filepath.Join(filepath.Dir(tc.deps.Executable), gitShimName)
+	target, err := os.Readlink(shim)
+	if err != nil {
+		return fmt.Errorf("expected %s to be a link: %v", shim, err)
+	}
+	if target != tc.deps.Executable {
+		return fmt.Errorf("expected %s to link to %s, got %s", shim, tc.deps.Executable, target)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theInstalledGitVersionIs(gitVersion string) error {
+	tc.deps.Tools.(*MockTools).Git = gitVersion
+	return nil
+}
+
+func (tc *CLITestContext) theGitHubCLIIsNotInstalled() error {
+	tc.deps.Tools.(*MockTools).GH = ""
+	return nil
+}
+
+func (tc *CLITestContext) theJSONFieldShouldBe(field, expected string) error {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(tc.outputBuffer.String()), &fields); err != nil {
+		return fmt.Errorf("output is not JSON: %w\n%s", err, tc.outputBuffer.String())
+	}
+	value, ok := fields[field]
+	if !ok {
+		return fmt.Errorf("expected field %q in %s", field, tc.outputBuffer.String())
+	}
+	if value != expected {
+		return fmt.Errorf("expected %s to be %q, got %q", field, expected, value)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theDoctorCheckShouldBe(name, status, fix string) error {
+	var report doctorReport
+	if err := json.Unmarshal([]byte(tc.outputBuffer.String()), &report); err != nil {
+		return fmt.Errorf("output is not a doctor report: %w\n%s", err, tc.outputBuffer.String())
+	}
+	for _, check := range report.Checks {
+		if check.Name != name {
+			continue
+		}
+		if check.Status != status || check.Fix != fix {
+			return fmt.Errorf("expected %s to be %q with fix %q, got %q with fix %q", name, status, fix, check.Status, check.Fix)
+		}
+		return nil
+	}
+	return fmt.Errorf("no %q check in %s", name, tc.outputBuffer.String())
+}
+
+func (tc *CLITestContext) theDoctorReportShouldBeOK(ok bool) error {
+	var report doctorReport
+	if err := json.Unmarshal([]byte(tc.outputBuffer.String()), &report); err != nil {
+		return fmt.Errorf("output is not a doctor report: %w\n%s", err, tc.outputBuffer.String())
+	}
+	if report.OK != ok {
+		return fmt.Errorf("expected the report's ok to be %v, got %v", ok, report.OK)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theDoctorCheckShouldBeInSection(name, section string) error {
+	var report doctorReport
+	if err := json.Unmarshal([]byte(tc.outputBuffer.String()), &report); err != nil {
+		return fmt.Errorf("output is not a doctor report: %w\n%s", err, tc.outputBuffer.String())
+	}
+	for _, check := range report.Checks {
+		if check.Name == name && check.Section == section {
+			return nil
+		}
+	}
+	return fmt.Errorf("no %q check in section %q in %s", name, section, tc.outputBuffer.String())
+}
+
+func (tc *CLITestContext) theOutputShouldContain(expected string) error {
+	if !strings.Contains(tc.lastOutput, expected) {
+		return fmt.Errorf("expected output to contain %q, got:\n%s", expected, tc.lastOutput)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theOutputShouldNotContain(unexpected string) error {
+	if strings.Contains(tc.lastOutput, unexpected) {
+		return fmt.Errorf("expected output not to contain %q, got:\n%s", unexpected, tc.lastOutput)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) stdoutIsPiped() error {
+	tc.deps.Interactive = false
+	return nil
+}
+
+func (tc *CLITestContext) theCommandExitsWithCodeIn(code int, dir string) error {
+	tc.commandRunner.ExitCodes[dir] = code
+	return nil
+}
+
+func (tc *CLITestContext) branchAlreadyExists(branch string) error {
+	mock := tc.deps.WorktreeManager.(*MockWorktreeManager)
+	mock.Branches = append(mock.Branches, branch)
+	return nil
+}
+
+func (tc *CLITestContext) worktreeHasUnmergedWork(commits, untracked int) error {
+	mock := tc.deps.WorktreeManager.(*MockWorktreeManager)
+	mock.Archive = git.Archive{Commits: commits, Changes: true, Untracked: untracked}
+	return nil
+}
+
+func (tc *CLITestContext) branchIsArchived(branch string) error {
+	mock := tc.deps.WorktreeManager.(*MockWorktreeManager)
+	mock.AddWorktree(branch)
+	_, err := mock.FakeWorktreeRepository.ArchiveWorktree(branch)
+	return err
+}
+
+func (tc *CLITestContext) branchesArePrunedToTheTrash(branches string) error {
+	mock := tc.deps.WorktreeManager.(*MockWorktreeManager)
+	for _, branch := range strings.Split(branches, ",") {
+		branch = strings.TrimSpace(branch)
+		mock.Trash = append(mock.Trash, git.TrashedWorktree{Branch: branch, Path: "/mock/worktrees/" + branch})
+	}
+	return nil
+}
+
 func (tc *CLITestContext) theFollowingWorktreesExist(worktreeTable *godog.Table) error {
+	tc.deps.WorktreeManager.(*MockWorktreeManager).Worktrees = parseWorktreeTable(worktreeTable)
+	return nil
+}
+
+func (tc *CLITestContext) repoHasWorktrees(name string, worktreeTable *godog.Table) error {
+	tc.knownRepos = append(tc.knownRepos, RepoTarget{
+		Name:            name,
+		WorktreeManager: NewMockWorktreeManager(parseWorktreeTable(worktreeTable)),
+		Metadata:        metadata.NewStoreWithPath("/mock/"+name, tc.t.TempDir()+"/metadata.json"),
+	})
+	tc.deps.KnownRepos = func() ([]RepoTarget, error) {
+		return tc.knownRepos, nil
+	}
+	return nil
+}
+
+func (tc *CLITestContext) mergedWorktreesShouldBePrunedInRepo(name string) error {
+	for _, repo := range tc.knownRepos {
+		if repo.Name == name {
+			if !repo.WorktreeManager.(*MockWorktreeManager).PrunedMerged {
+				return fmt.Errorf("expected merged worktrees to be pruned in %s", name)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("repo %s is not registered", name)
+}
+
+func (tc *CLITestContext) theRepoShouldBeClonedInto(url, dir string) error {
+	cloned := tc.deps.Cloner.(*MockCloner).Cloned
+	if len(cloned) != 1 || cloned[0] != url+" "+dir {
+		return fmt.Errorf("expected %s to be cloned into %s, got %v", url, dir, cloned)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) migrationFailsAtStep(step int) error {
+	tc.deps.Migrator.(*MockMigrator).FailAt = step
+	return nil
+}
+
+func (tc *CLITestContext) theCheckoutShouldBeMigrated(migrated bool) error {
+	if got := tc.deps.Migrator.(*MockMigrator).Migrated; got != migrated {
+		return fmt.Errorf("expected migrated to be %v, got %v", migrated, got)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) repairFinds(table *godog.Table) error {
+	report := &tc.deps.WorktreeManager.(*MockWorktreeManager).RepairReport
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue
+		}
+		value := row.Cells[1].Value
+		switch kind := row.Cells[0].Value; kind {
+		case "pruned":
+			report.PrunedWorktrees = append(report.PrunedWorktrees, value)
+		case "forgotten":
+			report.ForgottenBranches = append(report.ForgottenBranches, value)
+		case "missing branch":
+			path, branch, _ := strings.Cut(value, " ")
+			report.MissingBranches = append(report.MissingBranches, git.Worktree{Path: path, Branch: branch})
+		default:
+			return fmt.Errorf("unknown repair finding %q", kind)
+		}
+	}
+	return nil
+}
+
+func parseWorktreeTable(worktreeTable *godog.Table) []git.Worktree {
 	var worktrees []git.Worktree
-	
+	pathColumn := -1
+	stateColumn := -1
+	updatedColumn := -1
+	ciColumn := -1
+	sizeColumn := -1
+	noteColumn := -1
+
 	for i, row := range worktreeTable.Rows {
-		if i == 0 { // Skip header row
+		if i == 0 { // Header row; the optional path and state columns are located by name
+			for col, cell := range row.Cells {
+				switch cell.Value {
+				case "path":
+					pathColumn = col
+				case "state":
+					stateColumn = col
+				case "updated":
+					updatedColumn = col
+				case "ci":
+					ciColumn = col
+				case "size":
+					sizeColumn = col
+				case "note":
+					noteColumn = col
+				}
+			}
 			continue
 		}
-		
+
 		branch := row.Cells[0].Value
 		commit := row.Cells[1].Value
 		prStatus := row.Cells[2].Value
-		
-		worktrees = append(worktrees, git.Worktree{
+
+		worktree := git.Worktree{
 			Branch:   branch,
 			Commit:   commit,
 			PRStatus: prStatus,
+		}
+		if pathColumn >= 0 {
+			worktree.Path = row.Cells[pathColumn].Value
+		}
+		if updatedColumn >= 0 {
+			worktree.UpdatedAt, _ = time.Parse("2006-01-02", row.Cells[updatedColumn].Value)
+		}
+		if ciColumn >= 0 {
+			worktree.CIStatus = row.Cells[ciColumn].Value
+		}
+		if sizeColumn >= 0 {
+			worktree.DiskUsage, _ = stats.ParseSize(row.Cells[sizeColumn].Value)
+		}
+		if noteColumn >= 0 && row.Cells[noteColumn].Value != "" {
+			worktree.Note = row.Cells[noteColumn].Value
+		}
+		if stateColumn >= 0 {
+			for _, state := range strings.Split(row.Cells[stateColumn].Value, ",") {
+				switch strings.TrimSpace(state) {
+				case "bare":
+					worktree.Bare = true
+				case "detached":
+					worktree.Detached = true
+				case "locked":
+					worktree.Locked = true
+				case "pinned":
+					worktree.Locked = true
+					worktree.Pinned = true
+				case "prunable":
+					worktree.Prunable = true
+				}
+			}
+		}
+		worktrees = append(worktrees, worktree)
+	}
+	return worktrees
+}
+
+func (tc *CLITestContext) theFollowingWorktreeHistoryExists(historyTable *godog.Table) error {
+	store := tc.deps.Metadata
+
+	for i, row := range historyTable.Rows {
+		if i == 0 { // Skip header row
+			continue
+		}
+
+		branch := row.Cells[0].Value
+		createdAt, err := time.Parse(historyTimeLayout, row.Cells[1].Value)
+		if err != nil {
+			return err
+		}
+
+		store.SetClock(func() time.Time { return createdAt })
+		store.RecordCreated(branch, "/mock/path/"+branch)
+
+		if prunedValue := row.Cells[2].Value; prunedValue != "" {
+			prunedAt, err := time.Parse(historyTimeLayout, prunedValue)
+			if err != nil {
+				return err
+			}
+			store.SetClock(func() time.Time { return prunedAt })
+			store.RecordPruned(branch)
+		}
+	}
+
+	return nil
+}
+
+// theConfigFileContains writes a config file into a directory standing in
+// for the home directory; .sprout.json5 becomes the config file sprout reads
+func (tc *CLITestContext) theConfigFileContains(name string, content *godog.DocString) error {
+	if tc.configDir == "" {
+		tc.configDir = tc.t.TempDir()
+	}
+	path := filepath.Join(tc.configDir, name)
+	if name == ".sprout.json5" {
+		tc.deps.ConfigPathProvider.(*MockConfigPathProvider).ConfigPath = path
+	}
+	return os.WriteFile(path, []byte(content.Content), 0600)
+}
+
+func (tc *CLITestContext) theseCommandsWereLastRun(commandTable *godog.Table) error {
+	store := tc.deps.Metadata
+	for i, row := range commandTable.Rows {
+		if i == 0 { // Skip header row
+			continue
+		}
+
+		ranAt, err := time.Parse(historyTimeLayout, row.Cells[2].Value)
+		if err != nil {
+			return err
+		}
+		branch := row.Cells[0].Value
+		store.SetClock(func() time.Time { return ranAt })
+		store.RecordLastCommand(branch, "/mock/path/"+branch, strings.Fields(row.Cells[1].Value))
+	}
+	store.SetClock(time.Now)
+	return nil
+}
+
+func (tc *CLITestContext) theLastCommandInShouldBe(branch, expected string) error {
+	run, ok := tc.deps.Metadata.LastCommands()[branch]
+	if !ok {
+		return fmt.Errorf("expected a command to be remembered for %s", branch)
+	}
+	if got := strings.Join(run.Command, " "); got != expected {
+		return fmt.Errorf("expected the last command in %s to be %q, got %q", branch, expected, got)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theFollowingSproutHistoryExists(historyTable *godog.Table) error {
+	for i, row := range historyTable.Rows {
+		if i == 0 { // Skip header row
+			continue
+		}
+
+		at, err := time.Parse(historyTimeLayout, row.Cells[0].Value)
+		if err != nil {
+			return err
+		}
+		branch := row.Cells[2].Value
+		tc.deps.Metadata.LogEvent(metadata.HistoryEvent{
+			Time:    at,
+			Kind:    row.Cells[1].Value,
+			Branch:  branch,
+			Path:    "/mock/worktrees/" + branch,
+			Command: row.Cells[3].Value,
+			Detail:  row.Cells[4].Value,
 		})
 	}
-	
-	tc.deps.WorktreeManager.(*MockWorktreeManager).Worktrees = worktrees
+
 	return nil
 }
 
@@ -121,6 +550,24 @@ func (tc *CLITestContext) aConfigWith(configTable *godog.Table) error {
 			if value != "<not_set>" {
 				cfg.DefaultCommand = value
 			}
+		case "open_in":
+			if value != "<not_set>" {
+				cfg.OpenIn = value
+			}
+		case "resume_command":
+			cfg.ResumeCommand = value
+		case "detach":
+			cfg.Detach = value == "true"
+		case "issue_provider":
+			cfg.IssueProvider = value
+		case "linear_oauth_client_id":
+			cfg.LinearOAuthClientID = value
+		case "github_provider":
+			cfg.GitHubProvider = value
+		case "gc_stale_days":
+			cfg.GC.StaleDays, _ = strconv.Atoi(value)
+		case "gc_larger_than":
+			cfg.GC.LargerThan = value
 		case "linear_api_key":
 			if value != "<not_set>" {
 				cfg.LinearAPIKey = value
@@ -133,6 +580,20 @@ func (tc *CLITestContext) aConfigWith(configTable *godog.Table) error {
 					AssignedIssues: []linear.Issue{},
 				}
 			}
+		case "linear_workspace":
+			// name=key, once per workspace
+			name, apiKey, _ := strings.Cut(value, "=")
+			if cfg.LinearWorkspaces == nil {
+				cfg.LinearWorkspaces = make(map[string]string)
+			}
+			cfg.LinearWorkspaces[name] = apiKey
+			tc.deps.LinearClient = &MockLinearClient{
+				CurrentUser: &linear.User{
+					Name:  "Test User",
+					Email: "test@example.com",
+				},
+				AssignedIssues: []linear.Issue{},
+			}
 		}
 	}
 	
@@ -140,6 +601,95 @@ func (tc *CLITestContext) aConfigWith(configTable *godog.Table) error {
 	return nil
 }
 
+func (tc *CLITestContext) iAmSignedInToLinear() error {
+	tc.deps.LinearAuth.(*MockLinearAuth).Token = &linear.Token{AccessToken: "access", RefreshToken: "refresh"}
+	tc.deps.LinearClient = &MockLinearClient{
+		CurrentUser: &linear.User{
+			Name:  "Test User",
+			Email: "test@example.com",
+		},
+		AssignedIssues: []linear.Issue{},
+	}
+	return nil
+}
+
+func (tc *CLITestContext) iAmAssignedTheseLinearIssues(issueTable *godog.Table) error {
+	if err := tc.iAmSignedInToLinear(); err != nil {
+		return err
+	}
+	client := tc.deps.LinearClient.(*MockLinearClient)
+	header := issueTable.Rows[0].Cells
+	for _, row := range issueTable.Rows[1:] {
+		var issue linear.Issue
+		for i, cell := range row.Cells {
+			switch header[i].Value {
+			case "identifier":
+				issue.Identifier = cell.Value
+			case "title":
+				issue.Title = cell.Value
+			case "state":
+				issue.State.Name = cell.Value
+			case "state_type":
+				issue.State.Type = cell.Value
+			case "parent":
+				if cell.Value != "" {
+					issue.Parent = &linear.Issue{Identifier: cell.Value}
+				}
+			case "labels":
+				for _, name := range strings.Split(cell.Value, ", ") {
+					if name != "" {
+						issue.Labels = append(issue.Labels, linear.Label{Name: name})
+					}
+				}
+			}
+		}
+		client.AssignedIssues = append(client.AssignedIssues, issue)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) thesePullRequestsExist(prTable *godog.Table) error {
+	mock := tc.deps.WorktreeManager.(*MockWorktreeManager)
+	header := prTable.Rows[0].Cells
+	for _, row := range prTable.Rows[1:] {
+		var pr github.PullRequest
+		for i, cell := range row.Cells {
+			switch header[i].Value {
+			case "number":
+				pr.Number, _ = strconv.Atoi(cell.Value)
+			case "title":
+				pr.Title = cell.Value
+			case "branch":
+				pr.HeadBranch = cell.Value
+			case "state":
+				pr.State = cell.Value
+			}
+		}
+		mock.PullRequests = append(mock.PullRequests, pr)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) aGitHubTokenIsStored() error {
+	tc.deps.GitHubTokens.(*MockGitHubTokens).Stored = "ghp_stored"
+	return nil
+}
+
+func (tc *CLITestContext) theStoredGitHubTokenShouldBe(expected string) error {
+	if stored := tc.deps.GitHubTokens.(*MockGitHubTokens).Stored; stored != expected {
+		return fmt.Errorf("expected the stored GitHub token to be %q, got %q", expected, stored)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theLinearSignInShouldBe(state string) error {
+	signedIn := tc.deps.LinearAuth.(*MockLinearAuth).Token != nil
+	if signedIn != (state == "stored") {
+		return fmt.Errorf("expected the Linear sign-in to be %s, but signed in is %v", state, signedIn)
+	}
+	return nil
+}
+
 func (tc *CLITestContext) theOutputShouldBe(expected *godog.DocString) error {
 	expectedContent := strings.TrimSpace(expected.Content)
 	actualContent := strings.TrimSpace(tc.lastOutput)
@@ -158,6 +708,295 @@ func (tc *CLITestContext) theCommandShouldFail() error {
 	return nil
 }
 
+func (tc *CLITestContext) worktreeShouldBePruned(branch string) error {
+	existing, err := tc.deps.WorktreeManager.FindExisting(branch)
+	if err != nil {
+		return err
+	}
+	if existing.WorktreePath != "" {
+		return fmt.Errorf("expected worktree %q to be pruned, still at %s", branch, existing.WorktreePath)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) worktreeShouldNotBePruned(branch string) error {
+	existing, err := tc.deps.WorktreeManager.FindExisting(branch)
+	if err != nil {
+		return err
+	}
+	if existing.WorktreePath == "" {
+		return fmt.Errorf("expected worktree %q to be kept, but it was pruned", branch)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) worktreeHasUncommittedChanges(branch string) error {
+	mock := tc.deps.WorktreeManager.(*MockWorktreeManager)
+	for _, wt := range mock.Worktrees {
+		if wt.Branch == branch {
+			mock.Dirty = append(mock.Dirty, wt.Path)
+			return nil
+		}
+	}
+	return fmt.Errorf("no worktree for %s", branch)
+}
+
+// theseBranchesAreStackedOn sets up the stack the mock reports, found for
+// its first branch unless one is named; branches with a path get a worktree
+func (tc *CLITestContext) theseBranchesAreStackedOn(trunk string, table *godog.Table) error {
+	mock := tc.deps.WorktreeManager.(*MockWorktreeManager)
+	mock.Stacked = git.Stack{Trunk: trunk}
+	header := table.Rows[0].Cells
+	for _, row := range table.Rows[1:] {
+		var entry git.StackBranch
+		for i, cell := range row.Cells {
+			switch header[i].Value {
+			case "branch":
+				entry.Branch = cell.Value
+			case "parent":
+				entry.Parent = cell.Value
+			case "depth":
+				entry.Depth, _ = strconv.Atoi(cell.Value)
+			case "ahead":
+				entry.Ahead, _ = strconv.Atoi(cell.Value)
+			case "behind":
+				entry.Behind, _ = strconv.Atoi(cell.Value)
+			case "path":
+				entry.Path = cell.Value
+			}
+		}
+		if entry.Path != "" {
+			mock.Worktrees = append(mock.Worktrees, git.Worktree{Branch: entry.Branch, Path: entry.Path})
+		}
+		mock.Stacked.Branches = append(mock.Stacked.Branches, entry)
+	}
+	mock.Stacked.Branch = mock.Stacked.Branches[0].Branch
+	return nil
+}
+
+func (tc *CLITestContext) iWillAnswer(answers string) error {
+	var lines []string
+	for _, answer := range strings.Split(answers, ",") {
+		lines = append(lines, strings.TrimSpace(answer)+"\n")
+	}
+	tc.deps.Input = strings.NewReader(strings.Join(lines, ""))
+	return nil
+}
+
+func (tc *CLITestContext) thePruneOptionsShouldBe(expected string) error {
+	opts := tc.deps.WorktreeManager.(*MockWorktreeManager).PruneOptions
+	var actual []string
+	if opts.KeepBranch {
+		actual = append(actual, "keep-branch")
+	}
+	if opts.DeleteRemote {
+		actual = append(actual, "delete-remote")
+	}
+	if opts.DryRun {
+		actual = append(actual, "dry-run")
+	}
+	if opts.Porcelain {
+		actual = append(actual, "porcelain")
+	}
+	if opts.Unlock {
+		actual = append(actual, "unlock")
+	}
+	if opts.KeepUnmerged {
+		actual = append(actual, "keep-unmerged")
+	}
+	if strings.Join(actual, ", ") != expected {
+		return fmt.Errorf("expected prune options %q, got %q", expected, strings.Join(actual, ", "))
+	}
+	return nil
+}
+
+func (tc *CLITestContext) thePruneThresholdShouldBe(expected int64) error {
+	actual := tc.deps.WorktreeManager.(*MockWorktreeManager).PruneThreshold
+	if actual != expected {
+		return fmt.Errorf("expected prune threshold %d, got %d", expected, actual)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theWorktreeShouldBeCreatedWithSparseDirectories(expected string) error {
+	actual := strings.Join(tc.deps.WorktreeManager.(*MockWorktreeManager).CreateOptions.SparseDirectories, ", ")
+	if actual != expected {
+		return fmt.Errorf("expected sparse directories %q, got %q", expected, actual)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theConfigHasATemplateWith(prefix string, table *godog.Table) error {
+	cfg := tc.deps.ConfigLoader.(*MockConfigLoader).Config
+	var template config.Template
+	for _, row := range table.Rows[1:] {
+		value := row.Cells[1].Value
+		switch key := row.Cells[0].Value; key {
+		case "base":
+			template.Base = value
+		case "sparseProfile":
+			template.SparseProfile = value
+		case "hooks":
+			template.Hooks = strings.Split(value, "; ")
+		case "defaultCommand":
+			template.DefaultCommand = value
+		case "labels":
+			template.Labels = strings.Split(value, ", ")
+		default:
+			return fmt.Errorf("unknown template key %q", key)
+		}
+	}
+	if cfg.Templates == nil {
+		cfg.Templates = make(config.Templates)
+	}
+	cfg.Templates[prefix] = template
+	return nil
+}
+
+func (tc *CLITestContext) theWorktreeShouldBeCreatedFromWithHooks(base, hooks string) error {
+	opts := tc.deps.WorktreeManager.(*MockWorktreeManager).CreateOptions
+	if opts.BaseBranch != base || strings.Join(opts.Hooks, "; ") != hooks {
+		return fmt.Errorf("expected base %q and hooks %q, got %q and %q", base, hooks, opts.BaseBranch, strings.Join(opts.Hooks, "; "))
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theRepoConfigSets(key, value string) error {
+	enabled := value == "true"
+	switch key {
+	case "submodules":
+		tc.deps.RepoConfig.Submodules = &enabled
+	case "lfs":
+		tc.deps.RepoConfig.LFS = &enabled
+	default:
+		return fmt.Errorf("unknown repo config key %q", key)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theSetupStepsShouldBe(submodules, lfs string) error {
+	names := map[git.Setup]string{git.SetupDetect: "detect", git.SetupAlways: "always", git.SetupSkip: "skip"}
+	opts := tc.deps.WorktreeManager.(*MockWorktreeManager).CreateOptions
+	if names[opts.Submodules] != submodules || names[opts.LFS] != lfs {
+		return fmt.Errorf("expected submodules %q and LFS %q, got %q and %q", submodules, lfs, names[opts.Submodules], names[opts.LFS])
+	}
+	return nil
+}
+
+func (tc *CLITestContext) tmuxSessionIsRunning(session string) error {
+	mock := tc.deps.Tmux.(*MockTmuxClient)
+	if mock.Sessions == nil {
+		mock.Sessions = make(map[string]bool)
+	}
+	mock.Sessions[session] = true
+	return nil
+}
+
+func (tc *CLITestContext) theWorktreeSizesWereMeasured() error {
+	sizes := map[string]int64{}
+	for _, wt := range tc.deps.WorktreeManager.(*MockWorktreeManager).Worktrees {
+		sizes[wt.Path] = wt.DiskUsage
+	}
+	tc.deps.Metadata.RememberDiskUsage(sizes)
+	return nil
+}
+
+func (tc *CLITestContext) tmuxIsNotInstalled() error {
+	tc.deps.Tmux.(*MockTmuxClient).ListErr = fmt.Errorf("failed to list tmux sessions: %w", exec.ErrNotFound)
+	return nil
+}
+
+func (tc *CLITestContext) theLinkedTicketsHaveStatuses(table *godog.Table) error {
+	mock, ok := tc.deps.LinearClient.(*MockLinearClient)
+	if !ok {
+		return fmt.Errorf("linear client is not configured")
+	}
+	mock.IssueStates = make(map[string]linear.State)
+	for _, row := range table.Rows[1:] {
+		mock.IssueStates[row.Cells[0].Value] = linear.State{
+			Name: row.Cells[1].Value,
+			Type: row.Cells[2].Value,
+		}
+	}
+	return nil
+}
+
+func (tc *CLITestContext) tmuxShouldOpen(expected string) error {
+	opened := tc.deps.Tmux.(*MockTmuxClient).Opened
+	if len(opened) != 1 || opened[0] != expected {
+		return fmt.Errorf("expected tmux to open %q, got %q", expected, opened)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theRepoConfigOpensWorktreesIn(name string) error {
+	tc.deps.RepoConfig.Open = name
+	return nil
+}
+
+func (tc *CLITestContext) theRepoConfigReusesEditorWindows() error {
+	tc.deps.RepoConfig.ReuseWindow = true
+	return nil
+}
+
+func (tc *CLITestContext) theInstalledEditorsAre(names string) error {
+	tc.deps.Editor.(*MockEditorLauncher).InstalledEditors = strings.Split(names, ", ")
+	return nil
+}
+
+func (tc *CLITestContext) theEditorShouldOpen(expected string) error {
+	opened := tc.deps.Editor.(*MockEditorLauncher).Opened
+	if len(opened) != 1 || opened[0] != expected {
+		return fmt.Errorf("expected editor to open %q, got %q", expected, opened)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) noEditorShouldBeOpened() error {
+	if opened := tc.deps.Editor.(*MockEditorLauncher).Opened; len(opened) > 0 {
+		return fmt.Errorf("expected no editor to be opened, got %q", opened)
+	}
+	return nil
+}
+
+func (tc *CLITestContext) theSubtaskShouldBeCreated(expected string) error {
+	client, ok := tc.deps.LinearClient.(*MockLinearClient)
+	if !ok {
+		return fmt.Errorf("no Linear client configured")
+	}
+	if len(client.CreatedSubtasks) != 1 || client.CreatedSubtasks[0] != expected {
+		return fmt.Errorf("expected subtask %q to be created, got %q", expected, client.CreatedSubtasks)
+	}
+	return nil
+}
+
+// setEnv sets an environment variable for the rest of the scenario
+func (tc *CLITestContext) setEnv(name, value string) error {
+	if tc.savedEnv == nil {
+		tc.savedEnv = map[string]*string{}
+	}
+	if _, saved := tc.savedEnv[name]; !saved {
+		if previous, ok := os.LookupEnv(name); ok {
+			tc.savedEnv[name] = &previous
+		} else {
+			tc.savedEnv[name] = nil
+		}
+	}
+	return os.Setenv(name, value)
+}
+
+// restoreEnv puts back the environment variables setEnv changed
+func (tc *CLITestContext) restoreEnv() {
+	for name, previous := range tc.savedEnv {
+		if previous == nil {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, *previous)
+		}
+	}
+	tc.savedEnv = nil
+}
+
 // InitializeCLIScenario initializes godog with CLI step definitions
 func InitializeCLIScenario(ctx *godog.ScenarioContext, t *testing.T) {
 	var tc *CLITestContext
@@ -168,6 +1007,14 @@ func InitializeCLIScenario(ctx *godog.ScenarioContext, t *testing.T) {
 		tc = NewCLITestContext(t)
 		return ctx, nil
 	})
+	ctx.After(func(ctx context.Context, sc *godog.Scenario, err error) (context.Context, error) {
+		version.Version = version.Dev
+		tc.restoreEnv()
+		return ctx, nil
+	})
+	ctx.Step(`^the environment variable "([^"]*)" is "([^"]*)"$`, func(name, value string) error {
+		return tc.setEnv(name, value)
+	})
 	
 	// Step definitions
 	ctx.Step(`^I run "([^"]*)"$`, func(command string) error {
@@ -179,19 +1026,357 @@ func InitializeCLIScenario(ctx *godog.ScenarioContext, t *testing.T) {
 	ctx.Step(`^the following worktrees exist:$`, func(table *godog.Table) error {
 		return tc.theFollowingWorktreesExist(table)
 	})
+	ctx.Step(`^the installed version is "([^"]*)"$`, func(installed string) error {
+		return tc.theInstalledVersionIs(installed)
+	})
+	ctx.Step(`^the latest release is "([^"]*)"$`, func(latest string) error {
+		return tc.theLatestReleaseIs(latest)
+	})
+	ctx.Step(`^sprout should be upgraded to "([^"]*)"$`, func(expected string) error {
+		return tc.sproutShouldBeUpgradedTo(expected)
+	})
+	ctx.Step(`^sprout is installed in a directory (on|not on) the PATH$`, func(where string) error {
+		return tc.sproutIsInstalled(where == "on")
+	})
+	ctx.Step(`^git-sprout is installed$`, func() error {
+		return os.Symlink(tc.deps.Executable, filepath.Join(filepath.Dir(tc.deps.Executable), gitShimName))
+	})
+	ctx.Step(`^another program is installed as git-sprout$`, func() error {
+		return os.WriteFile(filepath.Join(filepath.Dir(tc.deps.Executable), gitShimName), []byte("#!/bin/sh\n"), 0755)
+	})
+	ctx.Step(`^git-sprout should link to sprout$`, func() error {
+		return tc.gitSproutShouldLinkToSprout()
+	})
+	ctx.Step(`^the installed git version is "([^"]*)"$`, func(gitVersion string) error {
+		return tc.theInstalledGitVersionIs(gitVersion)
+	})
+	ctx.Step(`^the GitHub CLI is not installed$`, func() error {
+		return tc.theGitHubCLIIsNotInstalled()
+	})
+	ctx.Step(`^the JSON field "([^"]*)" should be "([^"]*)"$`, func(field, expected string) error {
+		return tc.theJSONFieldShouldBe(field, expected)
+	})
+	ctx.Step(`^the output should contain "([^"]*)"$`, func(expected string) error {
+		return tc.theOutputShouldContain(expected)
+	})
+	ctx.Step(`^the output should not contain "([^"]*)"$`, func(unexpected string) error {
+		return tc.theOutputShouldNotContain(unexpected)
+	})
+	ctx.Step(`^stdout is piped$`, func() error {
+		return tc.stdoutIsPiped()
+	})
+	ctx.Step(`^the command exits with code (\d+) in "([^"]*)"$`, func(code int, dir string) error {
+		return tc.theCommandExitsWithCodeIn(code, dir)
+	})
+	ctx.Step(`^branch "([^"]*)" already exists$`, func(branch string) error {
+		return tc.branchAlreadyExists(branch)
+	})
+	ctx.Step(`^the worktree has (\d+) unmerged commits?, uncommitted changes and (\d+) untracked files?$`, func(commits, untracked int) error {
+		return tc.worktreeHasUnmergedWork(commits, untracked)
+	})
+	ctx.Step(`^"([^"]*)" is archived$`, func(branch string) error {
+		return tc.branchIsArchived(branch)
+	})
+	ctx.Step(`^the last prune removed "([^"]*)"$`, func(branches string) error {
+		return tc.branchesArePrunedToTheTrash(branches)
+	})
+	ctx.Step(`^the following worktree history exists:$`, func(table *godog.Table) error {
+		return tc.theFollowingWorktreeHistoryExists(table)
+	})
+	ctx.Step(`^the following sprout history exists:$`, func(table *godog.Table) error {
+		return tc.theFollowingSproutHistoryExists(table)
+	})
+	ctx.Step(`^I am in the worktree for "([^"]*)"$`, func(branch string) error {
+		tc.deps.WorktreeManager.(*MockWorktreeManager).BranchHere = branch
+		return nil
+	})
+	ctx.Step(`^the config file "([^"]*)" contains:$`, func(name string, content *godog.DocString) error {
+		return tc.theConfigFileContains(name, content)
+	})
+	ctx.Step(`^these commands were last run:$`, func(table *godog.Table) error {
+		return tc.theseCommandsWereLastRun(table)
+	})
+	ctx.Step(`^the last command in "([^"]*)" should be "([^"]*)"$`, func(branch, expected string) error {
+		return tc.theLastCommandInShouldBe(branch, expected)
+	})
 	ctx.Step(`^a config with:$`, func(table *godog.Table) error {
 		return tc.aConfigWith(table)
 	})
+	ctx.Step(`^I am signed in to Linear$`, func() error {
+		return tc.iAmSignedInToLinear()
+	})
+	ctx.Step(`^I am assigned these Linear issues:$`, func(table *godog.Table) error {
+		return tc.iAmAssignedTheseLinearIssues(table)
+	})
+	ctx.Step(`^these pull requests exist:$`, func(table *godog.Table) error {
+		return tc.thesePullRequestsExist(table)
+	})
+	ctx.Step(`^the Linear sign-in should be (stored|removed)$`, func(state string) error {
+		return tc.theLinearSignInShouldBe(state)
+	})
+	ctx.Step(`^a GitHub token is stored$`, func() error {
+		return tc.aGitHubTokenIsStored()
+	})
+	ctx.Step(`^the stored GitHub token should be "([^"]*)"$`, func(expected string) error {
+		return tc.theStoredGitHubTokenShouldBe(expected)
+	})
+	ctx.Step(`^the issue at "([^"]*)" is titled "([^"]*)"$`, func(url, title string) error {
+		tc.deps.IssueLinks.(*MockIssueLinks).Titles[url] = title
+		return nil
+	})
 	ctx.Step(`^the output should be:$`, func(expected *godog.DocString) error {
 		return tc.theOutputShouldBe(expected)
 	})
+	ctx.Step(`^the doctor check "([^"]*)" should be "([^"]*)"$`, func(name, status string) error {
+		return tc.theDoctorCheckShouldBe(name, status, "")
+	})
+	ctx.Step(`^the doctor check "([^"]*)" should be "([^"]*)" with fix "([^"]*)"$`, func(name, status, fix string) error {
+		return tc.theDoctorCheckShouldBe(name, status, fix)
+	})
+	ctx.Step(`^the doctor check "([^"]*)" should be in section "([^"]*)"$`, func(name, section string) error {
+		return tc.theDoctorCheckShouldBeInSection(name, section)
+	})
+	ctx.Step(`^the doctor report should( not)? be ok$`, func(not string) error {
+		return tc.theDoctorReportShouldBeOK(not == "")
+	})
+	ctx.Step(`^Linear can't be reached$`, func() error {
+		tc.deps.LinearClient.(*MockLinearClient).ConnectionError = fmt.Errorf("connection refused")
+		return nil
+	})
 	ctx.Step(`^the command should fail$`, func() error {
 		return tc.theCommandShouldFail()
 	})
+	ctx.Step(`^worktree "([^"]*)" should be pruned$`, func(branch string) error {
+		return tc.worktreeShouldBePruned(branch)
+	})
+	ctx.Step(`^worktree "([^"]*)" should not be pruned$`, func(branch string) error {
+		return tc.worktreeShouldNotBePruned(branch)
+	})
+	ctx.Step(`^pruning "([^"]*)" fails with "([^"]*)"$`, func(branch, reason string) error {
+		wm := tc.deps.WorktreeManager.(*MockWorktreeManager)
+		if wm.PruneFailures == nil {
+			wm.PruneFailures = map[string]string{}
+		}
+		wm.PruneFailures[branch] = reason
+		return nil
+	})
+	ctx.Step(`^worktree "([^"]*)" has uncommitted changes$`, func(branch string) error {
+		return tc.worktreeHasUncommittedChanges(branch)
+	})
+	ctx.Step(`^the git hooks check reports "([^"]*)"$`, func(problem string) error {
+		mock := tc.deps.WorktreeManager.(*MockWorktreeManager)
+		mock.HookProblems = append(mock.HookProblems, problem)
+		return nil
+	})
+	ctx.Step(`^I will answer "([^"]*)"$`, func(answers string) error {
+		return tc.iWillAnswer(answers)
+	})
+	ctx.Step(`^the prune threshold should be (\d+) bytes$`, func(expected int64) error {
+		return tc.thePruneThresholdShouldBe(expected)
+	})
+	ctx.Step(`^worktree "([^"]*)" should (not )?be deleted outright$`, func(branch, not string) error {
+		deleted := slices.Contains(tc.deps.WorktreeManager.(*MockWorktreeManager).Deleted, branch)
+		if deleted != (not == "") {
+			return fmt.Errorf("expected worktree %q %sdeleted outright, got %v", branch, not, tc.deps.WorktreeManager.(*MockWorktreeManager).Deleted)
+		}
+		return nil
+	})
+	ctx.Step(`^the prune options should be "([^"]*)"$`, func(expected string) error {
+		return tc.thePruneOptionsShouldBe(expected)
+	})
+	ctx.Step(`^"([^"]*)" has been in the trash past trashDays$`, func(branch string) error {
+		wm := tc.deps.WorktreeManager.(*MockWorktreeManager)
+		wm.ExpiredTrash = append(wm.ExpiredTrash, git.TrashedWorktree{Branch: branch, Path: "/mock/worktrees/" + branch})
+		return nil
+	})
+	ctx.Step(`^the gc stale cutoff should be (\d+) days$`, func(days int) error {
+		actual := tc.deps.WorktreeManager.(*MockWorktreeManager).GCPolicy.StaleAfter
+		if actual != time.Duration(days)*24*time.Hour {
+			return fmt.Errorf("expected gc to collect after %d days idle, got %s", days, actual)
+		}
+		return nil
+	})
+	ctx.Step(`^sprout is installed at "([^"]*)"$`, func(path string) error {
+		tc.deps.Executable = path
+		return nil
+	})
+	ctx.Step(`^no jobs should be scheduled$`, func() error {
+		if installed := tc.deps.Scheduler.(*MockScheduler).Installed; len(installed) > 0 {
+			return fmt.Errorf("expected nothing scheduled, got %+v", installed)
+		}
+		return nil
+	})
+	ctx.Step(`^the scheduled jobs should be "([^"]*)"$`, func(expected string) error {
+		var actual []string
+		for _, job := range tc.deps.Scheduler.(*MockScheduler).Installed {
+			actual = append(actual, fmt.Sprintf("%s %s in %s: %s", job.Name, job.Interval, job.Dir, strings.Join(job.Command, " ")))
+		}
+		if strings.Join(actual, "; ") != expected {
+			return fmt.Errorf("expected scheduled jobs %q, got %q", expected, strings.Join(actual, "; "))
+		}
+		return nil
+	})
+	ctx.Step(`^the repo config opens worktrees in "([^"]*)"$`, func(name string) error {
+		return tc.theRepoConfigOpensWorktreesIn(name)
+	})
+	ctx.Step(`^the repo config reuses editor windows$`, func() error {
+		return tc.theRepoConfigReusesEditorWindows()
+	})
+	ctx.Step(`^the installed editors are "([^"]*)"$`, func(names string) error {
+		return tc.theInstalledEditorsAre(names)
+	})
+	ctx.Step(`^the editor should open "([^"]*)"$`, func(expected string) error {
+		return tc.theEditorShouldOpen(expected)
+	})
+	ctx.Step(`^no editor should be opened$`, func() error {
+		return tc.noEditorShouldBeOpened()
+	})
+	ctx.Step(`^the following is piped in:$`, func(input *godog.DocString) error {
+		tc.deps.Stdin = strings.NewReader(input.Content)
+		return nil
+	})
+	ctx.Step(`^Linear fails to create the subtask "([^"]*)"$`, func(title string) error {
+		client := tc.deps.LinearClient.(*MockLinearClient)
+		if client.SubtaskErrors == nil {
+			client.SubtaskErrors = map[string]error{}
+		}
+		client.SubtaskErrors[title] = fmt.Errorf("title too long")
+		return nil
+	})
+	ctx.Step(`^the subtask "([^"]*)" should be created$`, func(expected string) error {
+		return tc.theSubtaskShouldBeCreated(expected)
+	})
+	ctx.Step(`^tmux session "([^"]*)" is running$`, func(session string) error {
+		return tc.tmuxSessionIsRunning(session)
+	})
+	ctx.Step(`^the worktrees' sizes were measured$`, func() error {
+		return tc.theWorktreeSizesWereMeasured()
+	})
+	ctx.Step(`^tmux isn't installed$`, func() error {
+		return tc.tmuxIsNotInstalled()
+	})
+	ctx.Step(`^the linked tickets have statuses:$`, func(table *godog.Table) error {
+		return tc.theLinkedTicketsHaveStatuses(table)
+	})
+	ctx.Step(`^repo "([^"]*)" has worktrees:$`, func(name string, table *godog.Table) error {
+		return tc.repoHasWorktrees(name, table)
+	})
+	ctx.Step(`^"([^"]*)" should be cloned into "([^"]*)"$`, func(url, dir string) error {
+		return tc.theRepoShouldBeClonedInto(url, dir)
+	})
+	ctx.Step(`^repair finds:$`, func(table *godog.Table) error {
+		return tc.repairFinds(table)
+	})
+	ctx.Step(`^migration fails at step (\d+)$`, func(step int) error {
+		return tc.migrationFailsAtStep(step)
+	})
+	ctx.Step(`^the checkout should be migrated$`, func() error {
+		return tc.theCheckoutShouldBeMigrated(true)
+	})
+	ctx.Step(`^the checkout should not be migrated$`, func() error {
+		return tc.theCheckoutShouldBeMigrated(false)
+	})
+	ctx.Step(`^merged worktrees should be pruned in repo "([^"]*)"$`, func(name string) error {
+		return tc.mergedWorktreesShouldBePrunedInRepo(name)
+	})
+	ctx.Step(`^tmux should open "([^"]*)"$`, func(expected string) error {
+		return tc.tmuxShouldOpen(expected)
+	})
+	ctx.Step(`^tmux should start "([^"]*)" without attaching$`, func(expected string) error {
+		mock := tc.deps.Tmux.(*MockTmuxClient)
+		if !slices.Contains(mock.Started, expected) || len(mock.Opened) > 0 {
+			return fmt.Errorf("expected tmux to start %q alone, started %v and opened %v", expected, mock.Started, mock.Opened)
+		}
+		return nil
+	})
+	ctx.Step(`^"([^"]*)" should be started in the background$`, func(expected string) error {
+		if !slices.Contains(tc.detacher.Started, expected) {
+			return fmt.Errorf("expected %q started in the background, got %v", expected, tc.detacher.Started)
+		}
+		return nil
+	})
+	ctx.Step(`^nothing should be started in the background$`, func() error {
+		if len(tc.detacher.Started) > 0 {
+			return fmt.Errorf("expected nothing started in the background, got %v", tc.detacher.Started)
+		}
+		return nil
+	})
+	ctx.Step(`^the default command for branches "([^"]*)" is "([^"]*)"$`, func(pattern, command string) error {
+		cfg := tc.deps.ConfigLoader.(*MockConfigLoader).Config
+		if cfg.DefaultCommands.Branches == nil {
+			cfg.DefaultCommands.Branches = make(map[string]string)
+		}
+		cfg.DefaultCommands.Branches[pattern] = command
+		return nil
+	})
+	ctx.Step(`^the default command for this repository is "([^"]*)"$`, func(command string) error {
+		cfg := tc.deps.ConfigLoader.(*MockConfigLoader).Config
+		cfg.DefaultCommands.Repos = map[string]string{tc.deps.RepoRoot: command}
+		return nil
+	})
+	ctx.Step(`^the config has a template "([^"]*)" with:$`, func(prefix string, table *godog.Table) error {
+		return tc.theConfigHasATemplateWith(prefix, table)
+	})
+	ctx.Step(`^the worktree should be created from "([^"]*)" with hooks "([^"]*)"$`, func(base, hooks string) error {
+		return tc.theWorktreeShouldBeCreatedFromWithHooks(base, hooks)
+	})
+	ctx.Step(`^the repo config sets "([^"]*)" to (true|false)$`, func(key, value string) error {
+		return tc.theRepoConfigSets(key, value)
+	})
+	ctx.Step(`^the repo config sets "gitHooks" to "([^"]*)"$`, func(mode string) error {
+		tc.deps.RepoConfig.GitHooks = mode
+		return nil
+	})
+	ctx.Step(`^the worktree should be created with git hooks "([^"]*)"$`, func(expected string) error {
+		if actual := tc.deps.WorktreeManager.(*MockWorktreeManager).CreateOptions.GitHooks; actual != expected {
+			return fmt.Errorf("expected git hooks %q, got %q", expected, actual)
+		}
+		return nil
+	})
+	ctx.Step(`^these branches are stacked on "([^"]*)":$`, func(trunk string, table *godog.Table) error {
+		return tc.theseBranchesAreStackedOn(trunk, table)
+	})
+	ctx.Step(`^the restacked branches should be "([^"]*)"$`, func(expected string) error {
+		if actual := strings.Join(tc.deps.WorktreeManager.(*MockWorktreeManager).Restacked, ", "); actual != expected {
+			return fmt.Errorf("expected %q restacked, got %q", expected, actual)
+		}
+		return nil
+	})
+	ctx.Step(`^rebasing "([^"]*)" conflicts$`, func(branch string) error {
+		tc.deps.WorktreeManager.(*MockWorktreeManager).RestackFailure = branch
+		return nil
+	})
+	ctx.Step(`^the timer should have started for "([^"]*)"$`, func(branch string) error {
+		started := tc.deps.WorktreeManager.(*MockWorktreeManager).TimersStarted
+		if !slices.Contains(started, branch) {
+			return fmt.Errorf("expected the timer started for %s, got %v", branch, started)
+		}
+		return nil
+	})
+	ctx.Step(`^the worktree should be created with submodules "([^"]*)" and LFS "([^"]*)"$`, func(submodules, lfs string) error {
+		return tc.theSetupStepsShouldBe(submodules, lfs)
+	})
+	ctx.Step(`^no worktree should be created$`, func() error {
+		if created := tc.deps.WorktreeManager.(*MockWorktreeManager).Created; len(created) > 0 {
+			return fmt.Errorf("expected no worktree created, got %v", created)
+		}
+		return nil
+	})
+	ctx.Step(`^the worktree should be created with sparse directories "([^"]*)"$`, func(expected string) error {
+		return tc.theWorktreeShouldBeCreatedWithSparseDirectories(expected)
+	})
+	ctx.Step(`^origin can't be reached without a prompt$`, func() error {
+		tc.deps.WorktreeManager.(*MockWorktreeManager).Unreachable = true
+		return nil
+	})
 }
 
 // TestCLIFeatures runs the CLI Gherkin tests
 func TestCLIFeatures(t *testing.T) {
+	// A token in the environment would win over the mock keychain
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+
 	suite := godog.TestSuite{
 		ScenarioInitializer: func(ctx *godog.ScenarioContext) {
 			InitializeCLIScenario(ctx, t)
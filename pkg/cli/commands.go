@@ -1,18 +1,45 @@
 package cli
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 	"syscall"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	"github.com/charmbracelet/x/term"
+	"sprout/pkg/batch"
 	"sprout/pkg/config"
+	"sprout/pkg/detach"
+	"sprout/pkg/editor"
 	"sprout/pkg/git"
+	"sprout/pkg/github"
+	"sprout/pkg/issues"
+	"sprout/pkg/keychain"
 	"sprout/pkg/linear"
+	"sprout/pkg/metadata"
+	"sprout/pkg/problem"
+	"sprout/pkg/progress"
+	"sprout/pkg/release"
+	"sprout/pkg/rpc"
+	"sprout/pkg/schedule"
+	"sprout/pkg/sprout"
+	"sprout/pkg/startup"
+	"sprout/pkg/stats"
+	"sprout/pkg/tmux"
 	"sprout/pkg/ui"
+	"sprout/pkg/version"
 )
 
 // ConfigPathProvider provides config path and file status information
@@ -25,11 +52,11 @@ type ConfigPathProvider interface {
 type DefaultConfigPathProvider struct{}
 
 func (p *DefaultConfigPathProvider) GetConfigPath() (string, error) {
-	return getConfigPath()
+	return config.Path()
 }
 
 func (p *DefaultConfigPathProvider) ConfigFileExists() bool {
-	path, err := getConfigPath()
+	path, err := config.Path()
 	if err != nil {
 		return false
 	}
@@ -42,54 +69,252 @@ type Dependencies struct {
 	WorktreeManager    git.WorktreeManagerInterface
 	ConfigLoader       config.LoaderInterface
 	LinearClient       linear.LinearClientInterface
+	LinearAuth         linear.AuthenticatorInterface // OAuth sign-in for sprout auth linear
+	GitHubTokens       github.TokenStoreInterface    // token sprout auth github keeps for the GitHub API
+	IssueLinks         issues.TitleFetcher           // titles of GitHub, GitLab and Jira issues pasted as links to sprout create
 	ConfigPathProvider ConfigPathProvider
+	Metadata           *metadata.Store
+	Tmux               tmux.ClientInterface
+	Scheduler          schedule.InstallerInterface // installs sprout gc --schedule's background job
+	RepoConfig         *config.RepoConfig
+	Editor             editor.LauncherInterface
+	Cloner             git.ClonerInterface
+	Migrator           git.MigratorInterface
+	Updater            release.UpdaterInterface
+	Tools              version.ToolsInterface
+	RunCommand         batch.RunFunc                // runs sprout exec's command in a worktree
+	StartDetached      detach.StartFunc             // runs sprout create --detach's command in the background
+	RepoRoot           string                       // top-level directory of the current checkout
+	KnownRepos         func() ([]RepoTarget, error) // registered repositories, for --all-repos
+	Interactive        bool                         // stdout is a terminal; when piped the TUI won't start and list prints porcelain lines
+	Input              io.Reader                    // answers to confirmation prompts; nil when stdin isn't a terminal
+	Stdin              io.Reader                    // piped input, such as the titles for sprout subtask --from-file -
+	Executable         string                       // the running sprout, which sprout init --git-alias links git-sprout to
+	Profile            *startup.Profile             // times starting up for --profile-startup; nil when not asked for
 	Output             io.Writer
 	ErrorOutput        io.Writer
 }
 
+// presenter is where handlers present progress, warnings and results: as
+// plain lines on stderr, leaving stdout for what scripts read
+func (deps *Dependencies) presenter() progress.Presenter {
+	return progress.NewText(deps.ErrorOutput)
+}
+
+// RepoTarget is a registered repository that --all-repos commands act on
+type RepoTarget struct {
+	Name            string
+	WorktreeManager git.WorktreeManagerInterface
+	Metadata        *metadata.Store
+}
+
+// issueTrackerCommands are the commands that use the issue tracker. Setting
+// it up can mean reading a sign-in from the keychain, so the rest start
+// without it, as they would with no tracker configured
+var issueTrackerCommands = map[string]bool{
+	"list":    true,
+	"create":  true,
+	"subtask": true,
+	"today":   true,
+	"pick":    true,
+	"doctor":  true,
+}
+
 // NewDependencies creates production dependencies
 func NewDependencies() (*Dependencies, error) {
+	return newDependencies("", nil)
+}
+
+// newDependencies creates the production dependencies command needs, or
+// everything when command is "", marking each phase on profile
+func newDependencies(command string, profile *startup.Profile) (*Dependencies, error) {
 	wm, err := git.NewWorktreeManager()
 	if err != nil {
 		return nil, err
 	}
+	profile.Mark("find repository")
 
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, err
 	}
 
+	// A broken repo config is the repository's to fix, so rather than
+	// stopping every command it's reported and left out
+	repoConfig, err := config.LoadRepoConfig(wm.RepoRoot())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring %s: %v\n", filepath.Join(wm.RepoRoot(), config.RepoConfigFileName), err)
+		repoConfig = &config.RepoConfig{}
+	}
+	profile.Mark("load config")
+
 	var linearClient linear.LinearClientInterface
-	if cfg.LinearAPIKey != "" {
-		linearClient = linear.NewClient(cfg.LinearAPIKey)
+	if command == "" || issueTrackerCommands[command] {
+		if linearClient, err = issues.NewForRepo(cfg, wm.RepoRoot()); err != nil {
+			return nil, err
+		}
+		profile.Mark("connect issue tracker")
 	}
 
+	store := metadata.NewStore(wm.RepoRoot())
+	store.RegisterRepo()
+	executable, _ := os.Executable()
+	profile.Mark("load metadata")
+
 	return &Dependencies{
 		WorktreeManager:    wm,
 		ConfigLoader:       &config.DefaultLoader{Config: cfg},
 		LinearClient:       linearClient,
+		LinearAuth:         linear.NewOAuth(cfg.LinearOAuthClientID, linear.NewTokenStore()),
+		GitHubTokens:       &github.KeychainTokenStore{},
+		IssueLinks:         issues.NewLinkTitles(&github.KeychainTokenStore{}, cfg.NetworkTimeout()),
 		ConfigPathProvider: &DefaultConfigPathProvider{},
+		Metadata:           store,
+		Tmux:               tmux.NewClient(),
+		Scheduler:          schedule.NewInstaller(),
+		RepoConfig:         repoConfig,
+		Editor:             &editor.Launcher{},
+		Cloner:             &git.Cloner{},
+		Migrator:           &git.Migrator{},
+		Updater:            release.NewUpdater(cfg.NetworkTimeout()),
+		Tools:              version.NewTools(),
+		RunCommand:         batch.Exec,
+		StartDetached:      detach.Start,
+		RepoRoot:           wm.RepoRoot(),
+		Executable:         executable,
+		KnownRepos:         func() ([]RepoTarget, error) { return loadKnownRepos(store) },
+		Interactive:        term.IsTerminal(os.Stdout.Fd()),
+		Input:              terminalInput(),
+		Stdin:              os.Stdin,
 		Output:             os.Stdout,
 		ErrorOutput:        os.Stderr,
+		Profile:            profile,
 	}, nil
 }
 
+// terminalInput is stdin when someone's there to answer prompts
+func terminalInput() io.Reader {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return nil
+	}
+	return os.Stdin
+}
+
+// loadKnownRepos opens a worktree manager for each registered repository,
+// skipping ones that are no longer git repositories
+func loadKnownRepos(store *metadata.Store) ([]RepoTarget, error) {
+	var repos []RepoTarget
+	for _, root := range store.KnownRepos() {
+		wm, err := git.NewWorktreeManagerForRepo(root)
+		if err != nil {
+			continue
+		}
+		repos = append(repos, RepoTarget{
+			Name:            wm.RepoName(),
+			WorktreeManager: wm,
+			Metadata:        metadata.NewStore(root),
+		})
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repositories registered yet; run sprout inside a repository to register it")
+	}
+	return repos, nil
+}
+
+// targetRepos returns the repositories a command should act on: every
+// registered one with --all-repos, otherwise just the current repository
+func targetRepos(deps *Dependencies, allRepos bool) ([]RepoTarget, error) {
+	if !allRepos {
+		return []RepoTarget{{WorktreeManager: deps.WorktreeManager, Metadata: deps.Metadata}}, nil
+	}
+	if deps.KnownRepos == nil {
+		return nil, fmt.Errorf("no repositories registered yet; run sprout inside a repository to register it")
+	}
+	return deps.KnownRepos()
+}
+
+// listedWorktree is a worktree row in sprout list along with the repository it belongs to
+type listedWorktree struct {
+	repo     RepoTarget
+	worktree git.Worktree
+	dirty    bool // has uncommitted changes
+}
+
 // HandleListCommand handles the list command
 func HandleListCommand(deps *Dependencies) error {
-	worktrees, err := deps.WorktreeManager.ListWorktrees()
+	return handleListCommandWithDeps(nil, deps)
+}
+
+func handleListCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("list", deps)
+	allRepos := fs.Bool("all-repos", false, "list worktrees from every registered repository")
+	sortBy := fs.String("sort", "", "order worktrees by age, status or branch")
+	status := fs.String("status", "", "only list merged, active or dirty worktrees")
+	quiet := quietFlags(fs)
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if err := validateListOptions(*sortBy, *status); err != nil {
+		return err
+	}
+	// Piped output is for scripts, so it gets the porcelain lines rather than the table
+	porcelain := *quiet || !deps.Interactive
+
+	repos, err := targetRepos(deps, *allRepos)
 	if err != nil {
 		return err
 	}
 
-	var filteredWorktrees []git.Worktree
-	for _, wt := range worktrees {
-		if wt.Branch != "master" && wt.Branch != "main" && wt.Branch != "" {
-			filteredWorktrees = append(filteredWorktrees, wt)
+	cfg, err := deps.ConfigLoader.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var filteredWorktrees []listedWorktree
+	for _, repo := range repos {
+		worktrees, err := repo.WorktreeManager.ListWorktrees()
+		if err != nil {
+			if *allRepos {
+				return fmt.Errorf("%s: %w", repo.Name, err)
+			}
+			return err
+		}
+		repo.WorktreeManager.LookUpCIStatuses(worktrees)
+		for _, wt := range worktrees {
+			// Detached checkouts are listed by their commit, but a bare
+			// clone's own directory isn't a worktree to work in
+			if wt.Branch != "master" && wt.Branch != "main" && !wt.Bare && (wt.Branch != "" || wt.Detached) {
+				filteredWorktrees = append(filteredWorktrees, listedWorktree{repo: repo, worktree: wt})
+			}
+		}
+	}
+
+	// Checking for uncommitted changes runs git status in every worktree, so
+	// porcelain output only pays for it when the listing depends on it
+	if !porcelain || *status == listStatusDirty || *sortBy == listSortStatus {
+		markDirty(filteredWorktrees)
+	}
+	filteredWorktrees = filterListed(filteredWorktrees, *status)
+	sortListed(filteredWorktrees, *sortBy)
+
+	if porcelain {
+		for _, listed := range filteredWorktrees {
+			wt := listed.worktree
+			// Porcelain lines are keyed by branch, so scripts never see a detached one
+			if wt.Branch == "" {
+				continue
+			}
+			fields := []string{wt.Branch, wt.Path, wt.PRStatus, wt.Commit}
+			if *allRepos {
+				fields = append([]string{listed.repo.Name}, fields...)
+			}
+			fmt.Fprintln(deps.Output, strings.Join(fields, "\t"))
 		}
+		return nil
 	}
 
 	if len(filteredWorktrees) == 0 {
-		fmt.Fprintln(deps.Output, "No worktrees found")
+		deps.presenter().Result("No worktrees found")
 		return nil
 	}
 
@@ -104,6 +329,12 @@ func HandleListCommand(deps *Dependencies) error {
 	normalStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("252"))
 
+	branchCol := 1
+	if *allRepos {
+		branchCol = 2
+	}
+	ciCol := -1
+
 	t := table.New().
 		Border(lipgloss.NormalBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("243"))).
@@ -111,19 +342,113 @@ func HandleListCommand(deps *Dependencies) error {
 			if row == 0 {
 				return headerStyle
 			}
-			if col == 0 {
+			// The status and CI icons bring their own colours
+			if col == 0 || col == ciCol {
+				return lipgloss.NewStyle()
+			}
+			if col == branchCol {
 				return branchStyle
 			}
 			return normalStyle
-		}).
-		Headers("BRANCH", "PR STATUS", "COMMIT")
+		})
+
+	headers := []string{"", "BRANCH", "PR STATUS"}
+	if *allRepos {
+		headers = []string{"", "REPO", "BRANCH", "PR STATUS"}
+	}
+
+	// Only show the CI column when some open PR has had checks run
+	showCI := false
+	for _, listed := range filteredWorktrees {
+		showCI = showCI || listed.worktree.CIStatus != ""
+	}
+	if showCI {
+		headers = append(headers, "CI")
+		ciCol = branchCol + 2
+	}
+
+	// Only show linked ticket statuses when Linear is configured
+	var ticketStates map[string]linear.State
+	if deps.LinearClient != nil {
+		var identifiers []string
+		for _, listed := range filteredWorktrees {
+			identifiers = append(identifiers, listed.repo.Metadata.IssueForBranch(listed.worktree.Branch))
+		}
+		ticketStates, err = deps.LinearClient.GetIssueStates(identifiers)
+		if err != nil {
+			deps.presenter().Warning(fmt.Sprintf("failed to load ticket statuses: %v", err))
+		}
+		headers = append(headers, "TICKET")
+	}
+	headers = append(headers, "COMMIT", "SIZE")
+
+	// Only show the tmux column when worktrees are opened in tmux sessions
+	var liveSessions map[string]bool
+	if cfg.OpensInTmux() && deps.Tmux != nil {
+		// Without tmux installed or running, no session is live
+		if liveSessions, err = deps.Tmux.LiveSessions(); err != nil {
+			liveSessions = map[string]bool{}
+		}
+		headers = append(headers, "TMUX")
+	}
+
+	// Only show the note column when some worktree has a note
+	showNotes := false
+	for _, listed := range filteredWorktrees {
+		showNotes = showNotes || listed.worktree.Note != ""
+	}
+	if showNotes {
+		headers = append(headers, "NOTE")
+	}
+	t.Headers(headers...)
+
+	sizes := make(map[string]int64)
+	for _, repo := range repos {
+		var worktrees []git.Worktree
+		for _, listed := range filteredWorktrees {
+			if listed.repo.WorktreeManager == repo.WorktreeManager {
+				worktrees = append(worktrees, listed.worktree)
+			}
+		}
+		for path, bytes := range stats.CachedDirSizes(repo.Metadata, worktreePaths(worktrees), stats.DiskUsageMaxAge) {
+			sizes[path] = bytes
+		}
+	}
+
+	for _, listed := range filteredWorktrees {
+		wt := listed.worktree
+		row := []string{listed.statusIcons(), listed.listedName(), wt.PRStatus}
+		if *allRepos {
+			row = []string{listed.statusIcons(), listed.repo.Name, listed.listedName(), wt.PRStatus}
+		}
+		if showCI {
+			row = append(row, ciIcon(wt.CIStatus))
+		}
+		if deps.LinearClient != nil {
+			row = append(row, ticketStatus(listed.repo.Metadata.IssueForBranch(wt.Branch), ticketStates, wt.PRStatus))
+		}
 
-	for _, wt := range filteredWorktrees {
 		commit := wt.Commit
 		if len(commit) > 8 {
 			commit = commit[:8]
 		}
-		t.Row(wt.Branch, wt.PRStatus, commit)
+		size := "-"
+		if bytes, ok := sizes[wt.Path]; ok {
+			size = stats.FormatBytes(bytes)
+		}
+		row = append(row, commit, size)
+
+		if liveSessions != nil {
+			session := "-"
+			if liveSessions[tmux.SessionName(wt.Branch)] {
+				session = "live"
+			}
+			row = append(row, session)
+		}
+		if showNotes {
+			row = append(row, listedNote(wt.Note))
+		}
+		t.Row(row...)
 	}
 
 	fmt.Fprintln(deps.Output, headerStyle.Render("🌱 Active Worktrees"))
@@ -133,13 +458,32 @@ func HandleListCommand(deps *Dependencies) error {
 	return nil
 }
 
-// HandleDoctorCommand handles the doctor command
-func HandleDoctorCommand(deps *Dependencies) error {
-	cfg, err := deps.ConfigLoader.GetConfig()
-	if err != nil {
-		return err
+// ticketStatus describes a worktree's linked ticket, flagging tickets still
+// open after their PR merged
+func ticketStatus(identifier string, states map[string]linear.State, prStatus string) string {
+	state, ok := states[identifier]
+	if identifier == "" || !ok {
+		return "-"
+	}
+	status := identifier + " " + state.Name
+	if prStatus == "Merged" && state.Type != "completed" && state.Type != "canceled" {
+		status += " ⚠"
+	}
+	return status
+}
+
+func worktreePaths(worktrees []git.Worktree) []string {
+	var paths []string
+	for _, wt := range worktrees {
+		if wt.Path != "" {
+			paths = append(paths, wt.Path)
+		}
 	}
+	return paths
+}
 
+// HandleStatsCommand reports local worktree usage statistics for the repository
+func HandleStatsCommand(deps *Dependencies) error {
 	headerStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("69")).
 		Bold(true)
@@ -150,63 +494,105 @@ func HandleDoctorCommand(deps *Dependencies) error {
 	normalStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("252"))
 
-	warningStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("221"))
-
-	fmt.Fprintln(deps.Output, headerStyle.Render("🌱 Sprout Configuration"))
+	fmt.Fprintln(deps.Output, headerStyle.Render("🌱 Worktree Statistics"))
 	fmt.Fprintln(deps.Output)
 
-	defaultCmd := cfg.DefaultCommand
-	if defaultCmd == "" {
-		defaultCmd = "not configured"
+	records := deps.Metadata.Worktrees()
+	if len(records) == 0 {
+		fmt.Fprintln(deps.Output, "  No worktree history recorded yet")
+	} else {
+		summary := stats.Summarize(records, 5)
+
+		fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Created"), normalStyle.Render(fmt.Sprint(summary.Created)))
+		fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Pruned"), normalStyle.Render(fmt.Sprint(summary.Pruned)))
+		fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Active"), normalStyle.Render(fmt.Sprint(summary.Active)))
+		fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Average Lifetime"), normalStyle.Render(stats.FormatDuration(summary.AverageLifetime)))
+
+		fmt.Fprintln(deps.Output)
+		fmt.Fprintln(deps.Output, headerStyle.Render("Activity by Month"))
+		fmt.Fprintln(deps.Output)
+		activity := newStatsTable("MONTH", "CREATED", "PRUNED")
+		for _, period := range summary.ByMonth {
+			activity.Row(period.Period, fmt.Sprint(period.Created), fmt.Sprint(period.Pruned))
+		}
+		fmt.Fprintln(deps.Output, activity)
+
+		if len(summary.TopIssues) > 0 {
+			fmt.Fprintln(deps.Output)
+			fmt.Fprintln(deps.Output, headerStyle.Render("Top Issues"))
+			fmt.Fprintln(deps.Output)
+			issues := newStatsTable("ISSUE", "WORKTREES")
+			for _, issue := range summary.TopIssues {
+				issues.Row(issue.Issue, fmt.Sprint(issue.Worktrees))
+			}
+			fmt.Fprintln(deps.Output, issues)
+		}
 	}
-	fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Default Command"), normalStyle.Render(defaultCmd))
 
-	resumeCmd := cfg.ResumeCommand
-	if resumeCmd == "" {
-		resumeCmd = "not configured"
+	worktrees, err := deps.WorktreeManager.ListWorktrees()
+	if err != nil {
+		return err
 	}
-	fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Resume Command"), normalStyle.Render(resumeCmd))
 
-	if cfg.GetLinearAPIKey() != "" {
-		fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Linear API Key"), normalStyle.Render("configured"))
-	} else {
-		fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Linear API Key"), warningStyle.Render("not configured"))
+	var paths []string
+	var measured []git.Worktree
+	for _, wt := range worktrees {
+		if wt.Branch == "master" || wt.Branch == "main" || wt.Branch == "" || wt.Path == "" {
+			continue
+		}
+		paths = append(paths, wt.Path)
+		measured = append(measured, wt)
+	}
+	if len(measured) == 0 {
+		return nil
 	}
 
-	configPath, err := deps.ConfigPathProvider.GetConfigPath()
-	if err != nil {
-		fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Config Path"), warningStyle.Render(fmt.Sprintf("<error: %v>", err)))
-	} else {
-		fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Config Path"), normalStyle.Render(configPath))
-
-		if deps.ConfigPathProvider.ConfigFileExists() {
-			fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Config File"), normalStyle.Render("exists"))
-		} else {
-			fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Config File"), warningStyle.Render("not found (using defaults)"))
+	sizes := stats.CachedDirSizes(deps.Metadata, paths, stats.DiskUsageMaxAge)
+	var total int64
+	usage := newStatsTable("BRANCH", "SIZE")
+	for _, wt := range measured {
+		size, ok := sizes[wt.Path]
+		if !ok {
+			usage.Row(wt.Branch, "unknown")
+			continue
 		}
+		total += size
+		usage.Row(wt.Branch, stats.FormatBytes(size))
 	}
 
 	fmt.Fprintln(deps.Output)
-	fmt.Fprintln(deps.Output, headerStyle.Render("Linear Integration"))
+	fmt.Fprintln(deps.Output, headerStyle.Render("Disk Usage"))
 	fmt.Fprintln(deps.Output)
+	fmt.Fprintln(deps.Output, usage)
+	fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Total"), normalStyle.Render(stats.FormatBytes(total)))
 
-	if cfg.LinearAPIKey == "" {
-		fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("API Key"), warningStyle.Render("not configured"))
-		fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("Status"), warningStyle.Render("disabled"))
-	} else {
-		// Mask the key for security
-		maskedKey := cfg.LinearAPIKey
-		if len(maskedKey) > 8 {
-			maskedKey = maskedKey[:8] + "..." + maskedKey[len(maskedKey)-4:]
-		}
-		fmt.Fprintf(deps.Output, "  %s: %s\n", accentStyle.Render("API Key"), normalStyle.Render(maskedKey))
+	return nil
+}
 
-		fmt.Fprintf(deps.Output, "  %s: ", accentStyle.Render("Status"))
-		testLinearConnection(deps.LinearClient, deps.Output)
-	}
+func newStatsTable(headers ...string) *table.Table {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("69")).
+		Bold(true)
 
-	return nil
+	firstColumnStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("108"))
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252"))
+
+	return table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("243"))).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == 0 {
+				return headerStyle
+			}
+			if col == 0 {
+				return firstColumnStyle
+			}
+			return normalStyle
+		}).
+		Headers(headers...)
 }
 
 // HandleHelpCommand handles the help command
@@ -216,83 +602,345 @@ func HandleHelpCommand(deps *Dependencies) {
 	fmt.Fprintln(deps.Output, "Usage:")
 	fmt.Fprintln(deps.Output, "  sprout                              Start in interactive mode")
 	fmt.Fprintln(deps.Output, "  sprout list                         List all worktrees")
+	fmt.Fprintln(deps.Output, "  sprout issues                       Browse and triage issues without creating worktrees")
+	fmt.Fprintln(deps.Output, "  sprout pick                         Print assigned issues a line each for fzf, to pipe to sprout create -")
+	fmt.Fprintln(deps.Output, "  sprout clone <url> [dir]            Clone as a bare repo with a worktree per branch")
+	fmt.Fprintln(deps.Output, "  sprout migrate [--dry-run]          Convert this checkout to a bare repo with worktrees")
 	fmt.Fprintln(deps.Output, "  sprout create <branch>              Create worktree and output path")
 	fmt.Fprintln(deps.Output, "  sprout create <branch> <command>    Create worktree and run command in it")
+	fmt.Fprintln(deps.Output, "  sprout create -                     Create worktree for the branch piped in, outputting only its path")
+	fmt.Fprintln(deps.Output, "  sprout create --issue-from-stdin    The same for the issue piped in, named as the TUI names it")
+	fmt.Fprintln(deps.Output, "  sprout create --json <branch>       Create worktree, printing each step as a line of JSON")
+	fmt.Fprintln(deps.Output, "  sprout create <issue-url>           Create worktree named after a Linear, GitHub, GitLab or Jira issue")
+	fmt.Fprintln(deps.Output, "  sprout subtask <parent> <title>     Create a Linear subtask under a parent issue")
+	fmt.Fprintln(deps.Output, "  sprout switch <branch>              Output an existing worktree's path, or attach to its tmux session")
+	fmt.Fprintln(deps.Output, "  sprout resume [branch]              Run the last command run in a worktree again, by default the latest")
+	fmt.Fprintln(deps.Output, "  sprout where [--exists] [branch]    Print where a branch's worktree is or would go, creating nothing")
+	fmt.Fprintln(deps.Output, "  sprout pr checkout [pr]             Pull a PR's latest head into its worktree, by number, branch or issue")
 	fmt.Fprintln(deps.Output, "  sprout prune [branch]               Remove worktree(s) - all merged if no branch specified")
-	fmt.Fprintln(deps.Output, "  sprout doctor                       Show configuration values")
+	fmt.Fprintln(deps.Output, "  sprout rm <branch>                  Remove a specific worktree (alias for prune <branch>)")
+	fmt.Fprintln(deps.Output, "  sprout archive <branch>             Save a worktree's unmerged work, then remove it")
+	fmt.Fprintln(deps.Output, "  sprout restore <branch>             Recreate an archived worktree and output its path")
+	fmt.Fprintln(deps.Output, "  sprout export <branch>              Pack a worktree's branch, changes and issue into one file for a teammate")
+	fmt.Fprintln(deps.Output, "  sprout import <file>                Recreate a worktree from an exported file and output its path")
+	fmt.Fprintln(deps.Output, "  sprout pin <branch>                 Lock a worktree so prune leaves it alone")
+	fmt.Fprintln(deps.Output, "  sprout unpin <branch>               Lift the pin so the worktree can be pruned again")
+	fmt.Fprintln(deps.Output, "  sprout note <branch> [text]         Keep a short note on a worktree, or show it")
+	fmt.Fprintln(deps.Output, "  sprout undo                         Bring back the worktrees the last prune removed")
+	fmt.Fprintln(deps.Output, "  sprout gc [--dry-run]               Prune merged, stale and oversize worktrees and empty old trash")
+	fmt.Fprintln(deps.Output, "  sprout stack [branch]               Show the branches a worktree's branch is built on and those built on it")
+	fmt.Fprintln(deps.Output, "  sprout stack restack [branch]       Rebase each branch in the stack onto the one it's built on")
+	fmt.Fprintln(deps.Output, "  sprout rename <old> <new>           Rename a worktree's branch and move its directory")
+	fmt.Fprintln(deps.Output, "  sprout repair                       Clean up worktrees deleted outside sprout")
+	fmt.Fprintln(deps.Output, "  sprout exec -- <command>            Run a command in every worktree")
+	fmt.Fprintln(deps.Output, "  sprout serve --stdio                Serve JSON-RPC on stdin and stdout for editor plugins")
+	fmt.Fprintln(deps.Output, "  sprout sparse set <dirs...>         Save sparse-checkout directories as a named profile")
+	fmt.Fprintln(deps.Output, "  sprout sparse show                  List sparse-checkout profiles for this repo")
+	fmt.Fprintln(deps.Output, "  sprout sparse apply <branch>        Apply a sparse-checkout profile to a worktree")
+	fmt.Fprintln(deps.Output, "  sprout history [--since when]       Show what sprout has done in this repo")
+	fmt.Fprintln(deps.Output, "  sprout today [--json]               Sum up issues in progress, open PRs and what needs tidying")
+	fmt.Fprintln(deps.Output, "  sprout stats                        Show local worktree usage statistics")
+	fmt.Fprintln(deps.Output, "  sprout auth linear [--logout]       Sign in to Linear in the browser instead of using an API key")
+	fmt.Fprintln(deps.Output, "  sprout auth github [--logout]       Store a GitHub token (read from stdin) for PR status without gh")
+	fmt.Fprintln(deps.Output, "  sprout doctor [--json]              Check configuration and connectivity")
+	fmt.Fprintln(deps.Output, "  sprout config resolved [--json]     Show each setting in effect and the file or variable it came from")
+	fmt.Fprintln(deps.Output, "  sprout init --git-alias             Install git-sprout so git sprout <command> works too")
+	fmt.Fprintln(deps.Output, "  sprout upgrade [--check]            Install the latest release in place of this binary")
+	fmt.Fprintln(deps.Output, "  sprout version [--json]             Show build details and the git and gh versions found")
 	fmt.Fprintln(deps.Output, "  sprout help                         Show this help")
 	fmt.Fprintln(deps.Output)
+	fmt.Fprintln(deps.Output, "Global options:")
+	fmt.Fprintln(deps.Output, "  --config <path>                     Read settings from path instead of ~/.sprout.json5")
+	fmt.Fprintln(deps.Output, "  --repo <name>                       Work in the registered repository called name")
+	fmt.Fprintln(deps.Output, "  --profile-startup                   Print how long each phase of starting up took")
+	fmt.Fprintln(deps.Output)
+	fmt.Fprintln(deps.Output, "Settings come from SPROUT_* environment variables first, then the config file")
+	fmt.Fprintln(deps.Output, "given with --config or SPROUT_CONFIG, or ~/.sprout.json5, then the defaults.")
+	fmt.Fprintln(deps.Output)
 	fmt.Fprintln(deps.Output, "Examples:")
 	fmt.Fprintln(deps.Output, "  sprout list                          # Show all worktrees")
+	fmt.Fprintln(deps.Output, "  sprout list --all-repos              # Show worktrees from every repo sprout has run in")
+	fmt.Fprintln(deps.Output, "  sprout list --porcelain              # Tab-separated branch, path, PR status, commit")
+	fmt.Fprintln(deps.Output, "  sprout list --sort=age               # Most recently committed first (or status, branch)")
+	fmt.Fprintln(deps.Output, "  sprout list --status=dirty           # Only worktrees with uncommitted changes")
+	fmt.Fprintln(deps.Output, "  cd \"$(sprout clone <url>)\"           # Clone and change to the default branch's worktree")
 	fmt.Fprintln(deps.Output, "  cd \"$(sprout create mybranch)\"       # Change to worktree directory")
 	fmt.Fprintln(deps.Output, "  sprout create mybranch bash          # Create worktree and start bash")
 	fmt.Fprintln(deps.Output, "  sprout create mybranch code .        # Create worktree and open in VS Code")
 	fmt.Fprintln(deps.Output, "  sprout create mybranch git status    # Create worktree and run git status")
+	fmt.Fprintln(deps.Output, "  sprout create --paths api mybranch   # Create worktree with only api/ checked out")
+	fmt.Fprintln(deps.Output, "  sprout create --open=code mybranch   # Create worktree and open it in VS Code")
+	fmt.Fprintln(deps.Output, "  sprout create --existing=fail fix    # Fail if fix already has a branch or worktree")
+	fmt.Fprintln(deps.Output, "  sprout create --template fix/ login  # Create fix/login with the fix/ template applied")
+	fmt.Fprintln(deps.Output, "  sprout create --no-lfs mybranch      # Create worktree without fetching LFS files")
+	fmt.Fprintln(deps.Output, "  sprout create --no-fetch mybranch    # Create worktree from the refs already fetched")
+	fmt.Fprintln(deps.Output, "  sprout create --detach web npm start # Start npm start in the background and return")
+	fmt.Fprintln(deps.Output, "  sprout create --dry-run fix/login    # Show what create would do without doing it")
+	fmt.Fprintln(deps.Output, "  echo fix/login | sprout create -     # Create fix/login, outputting only its path")
+	fmt.Fprintln(deps.Output, "  sprout pick | fzf | sprout create -  # Choose an issue with fzf and create its worktree")
+	fmt.Fprintln(deps.Output, "  sprout subtask ENG-12 Fix tests -w   # Create a subtask and a worktree for it")
+	fmt.Fprintln(deps.Output, "  sprout subtask ENG-12 --from-file -  # Create a subtask per line piped in")
 	fmt.Fprintln(deps.Output, "  sprout prune                         # Remove all merged worktrees")
 	fmt.Fprintln(deps.Output, "  sprout prune mybranch                # Remove specific worktree and directory")
+	fmt.Fprintln(deps.Output, "  sprout prune --dry-run               # Show what would be removed")
+	fmt.Fprintln(deps.Output, "  sprout prune --yes                   # Remove merged worktrees without asking first")
+	fmt.Fprintln(deps.Output, "  sprout prune --quiet                 # Print only a result line per removed worktree")
+	fmt.Fprintln(deps.Output, "  sprout prune --larger-than 5GB       # Remove worktrees bigger than 5GB")
+	fmt.Fprintln(deps.Output, "  sprout prune --all-repos             # Remove merged worktrees in every repo")
+	fmt.Fprintln(deps.Output, "  sprout gc --stale-days 30 --dry-run  # See what gc would collect, counting a month idle")
+	fmt.Fprintln(deps.Output, "  sprout gc --schedule daily           # Collect in every repo each day in the background")
+	fmt.Fprintln(deps.Output, "  sprout rm mybranch --keep-branch     # Remove worktree but keep the branch")
+	fmt.Fprintln(deps.Output, "  sprout rm mybranch --delete-remote   # Also delete origin/mybranch")
+	fmt.Fprintln(deps.Output, "  sprout rm mybranch --unlock          # Remove a worktree locked with git worktree lock")
+	fmt.Fprintln(deps.Output, "  sprout archive mybranch              # Keep mybranch's work but free its directory")
+	fmt.Fprintln(deps.Output, "  cd \"$(sprout restore mybranch)\"      # Bring mybranch back and change to it")
+	fmt.Fprintln(deps.Output, "  sprout export mybranch              # Write mybranch.sprout.tgz for a teammate")
+	fmt.Fprintln(deps.Output, "  cd \"$(sprout import fix.sprout.tgz)\" # Carry on where a teammate left off")
+	fmt.Fprintln(deps.Output, "  sprout pin release-2.x               # Keep a long-lived worktree out of every prune")
+	fmt.Fprintln(deps.Output, "  sprout note fix/login waiting on QA  # Remember what a worktree is waiting on")
+	fmt.Fprintln(deps.Output, "  sprout stack restack                 # Bring the parent's new commits into each branch above it")
+	fmt.Fprintln(deps.Output, "  cd \"$(sprout pr checkout ENG-123)\"   # Pick up ENG-123's PR again after review comments")
+	fmt.Fprintln(deps.Output, "  cd \"$(sprout rename fxi fix)\"        # Fix a typo and follow the worktree")
+	fmt.Fprintln(deps.Output, "  sprout exec --parallel 4 git fetch   # Fetch in four worktrees at a time")
+	fmt.Fprintln(deps.Output, "  sprout exec --status open -- npm ci  # Reinstall in worktrees with an open PR")
+	fmt.Fprintln(deps.Output, "  sprout sparse set services/api libs  # Check out only these directories")
+	fmt.Fprintln(deps.Output, "  sprout history --since 7d --json     # Last week's worktrees and commands as JSON")
+	fmt.Fprintln(deps.Output, "  sprout today --all-repos             # Morning summary across every repo")
+	fmt.Fprintln(deps.Output, "  sprout doctor --json                 # Health checks for scripts; exits 1 if one fails")
+	fmt.Fprintln(deps.Output, "  git sprout create mybranch           # The same, once sprout init --git-alias has run")
+	fmt.Fprintln(deps.Output, "  sprout upgrade --check               # See whether a newer release is out")
+	fmt.Fprintln(deps.Output, "  gh auth token | sprout auth github   # Keep using gh's token once gh is gone")
+	fmt.Fprintln(deps.Output, "  sprout --config ~/oss.json5 list     # Use a separate profile for open source work")
+	fmt.Fprintln(deps.Output, "  sprout --repo api list               # List worktrees of the api repository from anywhere")
+	fmt.Fprintln(deps.Output, "  SPROUT_OPEN_IN=tmux sprout create x  # Override one setting for a single run")
 }
 
-func getConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+// prStatusSource describes where PR status comes from with the configured
+// githubProvider, and how to fix it when that won't work
+func prStatusSource(cfg *config.Config, deps *Dependencies) (source, fix string, ok bool) {
+	provider := cfg.GetGitHubProvider()
+	if provider == config.GitHubProviderGH {
+		return "gh", "", true
+	}
+	if provider == config.GitHubProviderAuto && deps.Tools != nil {
+		if _, err := deps.Tools.GHVersion(); err == nil {
+			return "gh", "", true
+		}
 	}
-	return fmt.Sprintf("%s/.sprout.json5", homeDir), nil
+
+	if github.TokenFromEnv() != "" {
+		return "GitHub API (token from the environment)", "", true
+	}
+	token, err := github.FindToken(deps.GitHubTokens)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("GitHub API (<error: %v>)", err), "", false
+	case token == "":
+		return "GitHub API (no token)", "set GH_TOKEN or run 'sprout auth github'", false
+	}
+	return "GitHub API (token from the keychain)", "", true
 }
 
-func testLinearConnection(client linear.LinearClientInterface, output io.Writer) {
-	if client == nil {
-		return
+// linearSignIn describes the OAuth sign-in sprout auth linear stored
+func linearSignIn(deps *Dependencies) string {
+	if deps.LinearAuth == nil {
+		return "OAuth"
 	}
+	token, err := deps.LinearAuth.StoredToken()
+	switch {
+	case err != nil:
+		return fmt.Sprintf("OAuth (<error: %v>)", err)
+	case token == nil:
+		return "OAuth (not signed in)"
+	}
+	return "OAuth (signed in, refreshed automatically)"
+}
 
-	successStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("108")).
-		Bold(true)
+// maskAPIKey hides all but the ends of an API key, so doctor's output can be shared
+func maskAPIKey(key string) string {
+	if len(key) > 8 {
+		return key[:8] + "..." + key[len(key)-4:]
+	}
+	return key
+}
 
-	errorStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("204")).
-		Bold(true)
+// Run handles the main CLI logic and returns an exit code
+func Run(args []string) int {
+	if filepath.Base(args[0]) == gitShimName {
+		if err := enterGitInvocation(); err != nil {
+			printError(os.Stderr, err)
+			return 1
+		}
+	}
+	args, flags, err := extractGlobalFlags(args)
+	if err != nil {
+		printError(os.Stderr, err)
+		return 1
+	}
+	if flags.config != "" {
+		config.SetPath(flags.config)
+	}
+	if flags.repo != "" {
+		if err := enterRepo(flags.repo); err != nil {
+			printError(os.Stderr, err)
+			return 1
+		}
+	}
 
-	normalStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("252"))
+	var profile *startup.Profile
+	if flags.profileStartup {
+		profile = flags.started
+		defer profile.Report(os.Stderr)
+		profile.Mark("read arguments")
+	}
+	code := runCommand(args, profile)
+	if len(args) > 1 {
+		profile.Mark("run " + args[1])
+	}
+	// The webhook is posted to in the background, so let it finish
+	for _, err := range git.WaitForNotifications() {
+		progress.NewText(os.Stderr).Warning(err.Error())
+	}
+	return code
+}
 
-	user, err := client.GetCurrentUser()
-	if err != nil {
-		fmt.Fprintf(output, "%s\n", errorStyle.Render("✗ Failed"))
-		fmt.Fprintf(output, "  %s\n", errorStyle.Render(fmt.Sprintf("Error: %v", err)))
-		return
+// runCommand builds what the command in args needs and runs it
+func runCommand(args []string, profile *startup.Profile) int {
+	// These commands run without a repository to build the usual dependencies from
+	if len(args) > 1 && (args[1] == "clone" || args[1] == "init" || args[1] == "upgrade" || args[1] == "version" || args[1] == "--version" || args[1] == "help" || args[1] == "--help" || args[1] == "-h") {
+		executable, _ := os.Executable()
+		// Without a repository there's only the user's config, and none is fine
+		cfg, _ := config.Load()
+		return RunWithDependencies(args, &Dependencies{
+			Cloner:      &git.Cloner{},
+			Updater:     release.NewUpdater(cfg.NetworkTimeout()),
+			Tools:       version.NewTools(),
+			Executable:  executable,
+			Output:      os.Stdout,
+			ErrorOutput: os.Stderr,
+			Profile:     profile,
+		})
 	}
 
-	fmt.Fprintf(output, "%s\n", successStyle.Render("✓ Connected"))
-	fmt.Fprintf(output, "  %s: %s\n", normalStyle.Render("User"), normalStyle.Render(fmt.Sprintf("%s (%s)", user.Name, user.Email)))
+	// Triage only needs the issue tracker, so it works outside a repository too
+	if len(args) > 1 && args[1] == "issues" {
+		return RunWithDependencies(args, &Dependencies{
+			Interactive: term.IsTerminal(os.Stdout.Fd()),
+			Output:      os.Stdout,
+			ErrorOutput: os.Stderr,
+			Profile:     profile,
+		})
+	}
 
-	// Try to fetch assigned issues
-	issues, err := client.GetAssignedIssues()
-	if err != nil {
-		fmt.Fprintf(output, "  %s: %s\n", normalStyle.Render("Assigned Issues"), errorStyle.Render(fmt.Sprintf("<error fetching: %v>", err)))
-	} else {
-		fmt.Fprintf(output, "  %s: %s\n", normalStyle.Render("Assigned Issues"), normalStyle.Render(fmt.Sprintf("%d active tickets", len(issues))))
+	// Signing in is often the first thing done, before there's a repository to hand
+	if len(args) > 1 && args[1] == "auth" {
+		cfg, err := config.Load()
+		if err != nil {
+			printError(os.Stderr, err)
+			return 1
+		}
+		return RunWithDependencies(args, &Dependencies{
+			ConfigLoader:       &config.DefaultLoader{Config: cfg},
+			LinearAuth:         linear.NewOAuth(cfg.LinearOAuthClientID, linear.NewTokenStore()),
+			GitHubTokens:       &github.KeychainTokenStore{},
+			ConfigPathProvider: &DefaultConfigPathProvider{},
+			Input:              os.Stdin,
+			Output:             os.Stdout,
+			ErrorOutput:        os.Stderr,
+			Profile:            profile,
+		})
 	}
-}
 
-// Run handles the main CLI logic and returns an exit code
-func Run(args []string) int {
-	// Create dependencies for CLI commands
-	deps, err := NewDependencies()
+	// The config is the same wherever it's looked at from
+	if len(args) > 1 && args[1] == "config" {
+		return RunWithDependencies(args, &Dependencies{
+			ConfigPathProvider: &DefaultConfigPathProvider{},
+			Output:             os.Stdout,
+			ErrorOutput:        os.Stderr,
+			Profile:            profile,
+		})
+	}
+
+	// The interactive UI sets itself up, offering the registered repositories
+	// to open when there's none here
+	if len(args) < 2 {
+		return RunWithDependencies(args, &Dependencies{
+			Interactive: term.IsTerminal(os.Stdout.Fd()),
+			Tools:       version.NewTools(),
+			Output:      os.Stdout,
+			ErrorOutput: os.Stderr,
+			Profile:     profile,
+		})
+	}
+
+	// Create what the command needs
+	deps, err := newDependencies(args[1], profile)
 	if err != nil {
-		fmt.Printf("Error: Failed to initialize dependencies: %v\n", err)
+		printError(os.Stderr, fmt.Errorf("Failed to initialize dependencies: %w", err))
 		return 1
 	}
 
 	return RunWithDependencies(args, deps)
 }
 
+// globalFlags are the options taken from in front of the command
+type globalFlags struct {
+	config         string           // --config, the settings file to read
+	repo           string           // --repo, the registered repository to work in
+	profileStartup bool             // --profile-startup, to print how long starting up took
+	started        *startup.Profile // timing from when the flags were read, for --profile-startup
+}
+
+// extractGlobalFlags takes the global --config <path>, --repo <name> and
+// --profile-startup, or the --flag=value forms, from in front of the command,
+// returning the remaining arguments and the flags
+func extractGlobalFlags(args []string) ([]string, globalFlags, error) {
+	flags := globalFlags{started: startup.New()}
+	if len(args) == 0 {
+		return args, flags, nil
+	}
+	rest := args[1:]
+	for len(rest) > 0 {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(rest[0], "-"), "=")
+		if name == "profile-startup" && !hasValue && strings.HasPrefix(rest[0], "-") {
+			flags.profileStartup = true
+			rest = rest[1:]
+			continue
+		}
+		if !strings.HasPrefix(rest[0], "-") || (name != "config" && name != "repo") {
+			break
+		}
+		consumed := 1
+		if !hasValue && len(rest) > 1 {
+			value = rest[1]
+			consumed = 2
+		}
+		switch {
+		case name == "config" && value == "":
+			return nil, globalFlags{}, fmt.Errorf("--config needs a path, e.g. sprout --config ~/work.json5 list")
+		case name == "repo" && value == "":
+			return nil, globalFlags{}, fmt.Errorf("--repo needs a repository name, e.g. sprout --repo api list")
+		case name == "config":
+			flags.config = value
+		default:
+			flags.repo = value
+		}
+		rest = rest[consumed:]
+	}
+	return append([]string{args[0]}, rest...), flags, nil
+}
+
 // RunWithDependencies handles CLI logic with injected dependencies for testing
 func RunWithDependencies(args []string, deps *Dependencies) int {
 	if len(args) < 2 {
-		// Interactive mode
-		if err := ui.RunInteractive(); err != nil {
-			fmt.Printf("Error: %v\n", err)
+		// Interactive mode draws on stdout, so it can't run when that's a pipe
+		if !deps.Interactive {
+			fmt.Fprintln(deps.ErrorOutput, "Error: stdout is not a terminal, so the interactive UI can't start. Run a command instead, e.g. sprout list --porcelain")
+			return 1
+		}
+		warnIfGitLacks("sprout", version.GitWorktrees, deps)
+		if err := ui.RunInteractiveWithProfile(deps.Profile); err != nil {
+			printError(deps.ErrorOutput, err)
 			return 1
 		}
 		return 0
@@ -300,108 +948,609 @@ func RunWithDependencies(args []string, deps *Dependencies) int {
 
 	// One-shot mode
 	command := args[1]
+	// The manager's warnings go with the rest of what a command reports,
+	// rather than straight to stderr
+	if deps.WorktreeManager != nil {
+		deps.WorktreeManager.SetProgress(deps.presenter())
+	}
+	if feature, ok := gitFeatures[command]; ok {
+		warnIfGitLacks("sprout "+command, feature, deps)
+	}
 	switch command {
 	case "create":
 		if err := handleCreateCommandWithDeps(args[2:], deps); err != nil {
-			fmt.Printf("Error: %v\n", err)
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "clone":
+		if err := handleCloneCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "migrate":
+		if err := handleMigrateCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "auth":
+		if err := handleAuthCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "subtask":
+		if err := handleSubtaskCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "switch":
+		if err := handleSwitchCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
 			return 1
 		}
 	case "list":
-		if err := HandleListCommand(deps); err != nil {
-			fmt.Printf("Error: %v\n", err)
+		if err := handleListCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
 			return 1
 		}
 	case "prune":
 		if err := handlePruneCommandWithDeps(args[2:], deps); err != nil {
-			fmt.Printf("Error: %v\n", err)
+			printError(deps.ErrorOutput, err)
 			return 1
 		}
-	case "doctor":
-		if err := HandleDoctorCommand(deps); err != nil {
-			fmt.Printf("Error: %v\n", err)
+	case "rm":
+		if err := handleRmCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
 			return 1
 		}
-	case "help", "--help", "-h":
-		HandleHelpCommand(deps)
-		return 0
-	default:
-		fmt.Fprintf(deps.ErrorOutput, "Unknown command: %s\n", command)
-		HandleHelpCommand(deps)
-		return 1
-	}
-	return 0
-}
-
-// Legacy functions for backward compatibility
-func handleCreateCommand(args []string) error {
-	deps, err := NewDependencies()
-	if err != nil {
-		return err
-	}
-	return handleCreateCommandWithDeps(args, deps)
-}
-
-func handlePruneCommand(args []string) error {
-	deps, err := NewDependencies()
-	if err != nil {
-		return err
-	}
-	return handlePruneCommandWithDeps(args, deps)
-}
-
-func handleCreateCommandWithDeps(args []string, deps *Dependencies) error {
-	if len(args) == 0 {
-		return fmt.Errorf("branch name is required. Usage: sprout create <branch-name> [command...]")
-	}
-
-	branchName := args[0]
-
-	worktreePath, err := deps.WorktreeManager.CreateWorktree(branchName)
-	if err != nil {
-		return err
-	}
-
-	fmt.Fprintf(deps.ErrorOutput, "Worktree ready at: %s\n", worktreePath)
-
-	// If no command provided, check for default command
-	if len(args) == 1 {
-		cfg, err := deps.ConfigLoader.GetConfig()
-		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+	case "archive":
+		if err := handleArchiveCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "restore":
+		if err := handleRestoreCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "export":
+		if err := handleExportCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "import":
+		if err := handleImportCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "pin":
+		if err := handlePinCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "unpin":
+		if err := handleUnpinCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "note":
+		if err := handleNoteCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "pr":
+		if err := handlePRCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "stack":
+		if err := handleStackCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "gc":
+		if err := handleGCCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "issues":
+		if err := handleIssuesCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "undo":
+		if err := handleUndoCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "rename":
+		if err := handleRenameCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "repair":
+		if err := handleRepairCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "exec":
+		if err := handleExecCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "history":
+		if err := handleHistoryCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "today":
+		if err := handleTodayCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "resume":
+		if err := handleResumeCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "where":
+		if err := handleWhereCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "pick":
+		if err := handlePickCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "stats":
+		if err := HandleStatsCommand(deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "serve":
+		if err := handleServeCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "sparse":
+		if err := handleSparseCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "doctor":
+		if err := handleDoctorCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "config":
+		if err := handleConfigCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "init":
+		if err := handleInitCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "upgrade":
+		if err := handleUpgradeCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "version", "--version":
+		if err := handleVersionCommandWithDeps(args[2:], deps); err != nil {
+			printError(deps.ErrorOutput, err)
+			return 1
+		}
+	case "help", "--help", "-h":
+		HandleHelpCommand(deps)
+		return 0
+	default:
+		fmt.Fprintf(deps.ErrorOutput, "Unknown command: %s\n", command)
+		HandleHelpCommand(deps)
+		return 1
+	}
+	return 0
+}
+
+// parentIssueBranch is the branch stacked work on branchName's issue starts
+// from: that of the worktree for the issue's parent. It's "" when the issue
+// has no parent, or its parent no worktree, so the usual base applies
+func parentIssueBranch(branchName string, presenter progress.Presenter, deps *Dependencies) (string, error) {
+	issue := deps.Metadata.IssueForBranch(branchName)
+	if issue == "" {
+		return "", fmt.Errorf("--base-from-issue needs the branch name to start with its issue, such as eng-123-fix-login")
+	}
+	if deps.LinearClient == nil {
+		return "", fmt.Errorf("Linear API key is not configured. Add linearApiKey to %s", configPathForDisplay(deps))
+	}
+	found, err := deps.LinearClient.GetIssue(issue)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up %s: %w", issue, err)
+	}
+	if found == nil {
+		return "", fmt.Errorf("issue %s not found", issue)
+	}
+	if found.Parent == nil {
+		presenter.Result(fmt.Sprintf("%s has no parent issue, starting from the usual base", issue))
+		return "", nil
+	}
+
+	parent := found.Parent.Identifier
+	worktrees, err := deps.WorktreeManager.ListWorktrees()
+	if err != nil {
+		return "", err
+	}
+	linked := deps.Metadata.BranchForIssue(parent)
+	for _, wt := range worktrees {
+		if wt.Branch != "" && (wt.Branch == linked || strings.EqualFold(deps.Metadata.IssueForBranch(wt.Branch), parent)) {
+			presenter.Result(fmt.Sprintf("Starting from %s, the branch for parent issue %s", wt.Branch, parent))
+			return wt.Branch, nil
+		}
+	}
+	presenter.Warning(fmt.Sprintf("parent issue %s has no worktree, starting from the usual base", parent))
+	return "", nil
+}
+
+// Legacy functions for backward compatibility
+func handleCreateCommand(args []string) error {
+	deps, err := NewDependencies()
+	if err != nil {
+		return err
+	}
+	return handleCreateCommandWithDeps(args, deps)
+}
+
+func handlePruneCommand(args []string) error {
+	deps, err := NewDependencies()
+	if err != nil {
+		return err
+	}
+	return handlePruneCommandWithDeps(args, deps)
+}
+
+// What sprout create does when the branch it's asked for already exists
+const (
+	existingFail  = "fail"  // refuse to continue
+	existingOpen  = "open"  // switch to the existing worktree, as sprout switch does
+	existingReuse = "reuse" // carry on with the existing worktree or branch, as if newly created
+)
+
+func handleCreateCommandWithDeps(args []string, deps *Dependencies) (err error) {
+	// Flags must precede the branch so that everything after it is passed to the command untouched
+	fs := newFlagSet("create", deps)
+	paths := fs.String("paths", "", "comma-separated directories to sparse-checkout instead of the whole repo")
+	openIn := fs.String("open", "", "editor to open the worktree in: code, idea or none (defaults to the repo config)")
+	existingMode := fs.String("existing", existingReuse, "when the branch already exists: fail, open its worktree instead, or reuse it")
+	suffixOnConflict := fs.Bool("suffix-on-conflict", false, "when the branch already exists, create the first free name-2, name-3 and so on instead")
+	templateName := fs.String("template", "", "template prefix to apply, such as fix/ (defaults to the one the branch name matches)")
+	noSubmodules := fs.Bool("no-submodules", false, "don't run git submodule update in the new worktree")
+	noLFS := fs.Bool("no-lfs", false, "don't run git lfs pull in the new worktree")
+	baseFromIssue := fs.Bool("base-from-issue", false, "start from the branch of the parent issue's worktree, for stacked work on a subtask")
+	detached := fs.Bool("detach", false, "start the command in the background and return straight away (defaults to the detach config)")
+	dryRun := fs.Bool("dry-run", false, "print what create would do without creating, running or opening anything")
+	noFetch := fs.Bool("no-fetch", false, "start from the refs already fetched rather than fetching the default branch from origin")
+	issueFromStdin := fs.Bool("issue-from-stdin", false, "read an issue identifier from stdin and create the branch named after it")
+	quiet := quietFlags(fs)
+	asJSON := fs.Bool("json", false, "print progress as a JSON event per line on stdout, ending with the worktree's path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+
+	// Tools wrapping sprout follow each step as an event, and the last one
+	// says how it ended even when it failed
+	var events *progress.JSON
+	if *asJSON {
+		if len(args) > 1 {
+			return fmt.Errorf("--json prints events on stdout, so it can't run %s; run it in the path the done event gives", strings.Join(args[1:], " "))
+		}
+		events = progress.NewJSON(deps.Output)
+		defer func() {
+			if err != nil {
+				events.Emit(progress.Event{Type: "done", Status: "failed", Error: err.Error()})
+			}
+		}()
+	}
+
+	// A branch or issue piped in from another tool, such as a fuzzy finder,
+	// leaves only the worktree's path on stdout for the next one
+	var namedIssue *linear.Issue // the issue the branch is named after, for its labels
+	fromStdin := *issueFromStdin || (len(args) > 0 && args[0] == "-")
+	if fromStdin {
+		if len(args) > 0 && args[0] == "-" {
+			args = args[1:]
+		}
+		line, err := readPipedLine(deps)
+		if err != nil {
+			return err
+		}
+		_, isLink := issues.ParseLink(line)
+		switch {
+		case isLink:
+			// Looked up below, as a link given as an argument is
+		case *issueFromStdin:
+			if namedIssue, err = pipedIssueFor(line, deps); err != nil {
+				return err
+			}
+			line = namedIssue.GetBranchName()
+		default:
+			line = pickedBranch(line)
+		}
+		args = append([]string{line}, args...)
+		*quiet = true
+	}
+
+	// A link pasted from the browser names the branch after its issue
+	if len(args) > 0 {
+		if link, ok := issues.ParseLink(args[0]); ok {
+			issue, err := linkedIssue(link, deps)
+			if err != nil {
+				return err
+			}
+			namedIssue = issue
+			args[0] = issue.GetBranchName()
+		}
+	}
+
+	switch *existingMode {
+	case existingFail, existingOpen, existingReuse:
+	default:
+		return fmt.Errorf("--existing must be %s, %s or %s, got %q", existingFail, existingOpen, existingReuse, *existingMode)
+	}
+
+	editorName := *openIn
+	reuseWindow := false
+	if deps.RepoConfig != nil {
+		if editorName == "" {
+			editorName = deps.RepoConfig.Open
+		}
+		reuseWindow = deps.RepoConfig.ReuseWindow
+	}
+	if err := editor.Validate(editorName); err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("branch name is required. Usage: sprout create [--paths dirs] [--open editor] [--existing fail|open|reuse] [--suffix-on-conflict] [--template prefix] [--no-submodules] [--no-lfs] [--base-from-issue] [--detach] [--dry-run] [--no-fetch] [--issue-from-stdin] [--quiet|--json] <branch-name|issue-url|-> [command...]")
+	}
+
+	cfg, err := deps.ConfigLoader.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	branchName := args[0]
+	templatePrefix := *templateName
+	template, hasTemplate := config.Template{}, false
+	if templatePrefix != "" {
+		if template, hasTemplate = cfg.Templates[templatePrefix]; !hasTemplate {
+			return fmt.Errorf("template '%s' is not defined; templates are: %s", templatePrefix, strings.Join(cfg.Templates.Prefixes(), ", "))
+		}
+		branchName = config.ApplyTemplatePrefix(templatePrefix, branchName)
+	} else {
+		var labels []string
+		if namedIssue != nil {
+			labels = namedIssue.LabelNames()
+		}
+		// An issue's labels can pick a template, whose prefix it's then given
+		// as the TUI gives it
+		if templatePrefix, template, hasTemplate = cfg.Templates.Match(branchName, labels); hasTemplate {
+			branchName = config.ApplyTemplatePrefix(templatePrefix, branchName)
+		}
+	}
+	if _, err := git.ValidateBranchName(branchName); err != nil {
+		return err
+	}
+
+	var presenter progress.Presenter = progress.Discard
+	switch {
+	case events != nil:
+		presenter = events
+	case !*quiet:
+		presenter = deps.presenter()
+	}
+	existing, err := deps.WorktreeManager.FindExisting(branchName)
+	if err != nil {
+		return err
+	}
+	if *suffixOnConflict && existing.Found() {
+		free, err := git.FreeBranchName(deps.WorktreeManager, existing.Branch)
+		if err != nil {
+			return err
+		}
+		presenter.Result(fmt.Sprintf("%s already exists, creating %s instead", existing.Branch, free))
+		branchName = free
+		existing = git.ExistingBranch{Branch: free}
+	}
+	switch {
+	case *existingMode == existingFail && existing.WorktreePath != "":
+		return problem.WithHint(fmt.Errorf("a worktree for %s already exists at %s", existing.Branch, existing.WorktreePath),
+			fmt.Sprintf("sprout switch %s goes to it, as does sprout create --existing=open %s", existing.Branch, existing.Branch),
+			fmt.Sprintf("sprout create --suffix-on-conflict %s starts afresh beside it, as %s-2", existing.Branch, existing.Branch))
+	case *existingMode == existingFail && existing.BranchExists:
+		return problem.WithHint(fmt.Errorf("branch %s already exists", existing.Branch),
+			fmt.Sprintf("sprout create --existing=reuse %s checks it out in a new worktree", existing.Branch),
+			fmt.Sprintf("sprout create --suffix-on-conflict %s starts afresh beside it, as %s-2", existing.Branch, existing.Branch))
+	case *existingMode == existingOpen && existing.WorktreePath != "" && events != nil:
+		events.Emit(progress.Event{Type: "done", Status: "exists", Branch: existing.Branch, Path: existing.WorktreePath})
+		return nil
+	case *existingMode == existingOpen && existing.WorktreePath != "" && *dryRun:
+		presenter.Result(fmt.Sprintf("Dry run: a worktree for %s already exists at %s, so sprout create would open it", existing.Branch, existing.WorktreePath))
+		return nil
+	case *existingMode == existingOpen && existing.WorktreePath != "":
+		presenter.Result(fmt.Sprintf("A worktree for %s already exists, opening it", existing.Branch))
+		return handleSwitchCommandWithDeps([]string{existing.Branch}, deps)
+	}
+
+	opts := git.CreateOptions{Progress: presenter, NoFetch: *noFetch}
+	for _, dir := range strings.Split(*paths, ",") {
+		dir = strings.Trim(strings.TrimSpace(dir), "/")
+		if dir != "" {
+			opts.SparseDirectories = append(opts.SparseDirectories, dir)
+		}
+	}
+	if hasTemplate {
+		opts.BaseBranch = template.Base
+		opts.Hooks = template.Hooks
+		if template.SparseProfile != "" && len(opts.SparseDirectories) == 0 {
+			directories, ok := deps.Metadata.SparseProfiles()[template.SparseProfile]
+			if !ok {
+				return fmt.Errorf("sparse profile '%s' from the template is not defined; save it with: sprout sparse set --profile %s <dirs...>", template.SparseProfile, template.SparseProfile)
+			}
+			opts.SparseDirectories = directories
+		}
+	}
+	if *baseFromIssue && !existing.BranchExists {
+		base, err := parentIssueBranch(branchName, presenter, deps)
+		if err != nil {
+			return err
+		}
+		if base != "" {
+			opts.BaseBranch = base
+		}
+	}
+	if len(opts.SparseDirectories) > 0 {
+		warnIfGitLacks("sprout create --paths", version.GitSparseCone, deps)
+	}
+	if deps.RepoConfig != nil {
+		opts.Submodules = git.SetupFromConfig(deps.RepoConfig.Submodules)
+		opts.LFS = git.SetupFromConfig(deps.RepoConfig.LFS)
+		opts.GitHooks = deps.RepoConfig.GitHooks
+	}
+	if *noSubmodules {
+		opts.Submodules = git.SetupSkip
+	}
+	if *noLFS {
+		opts.LFS = git.SetupSkip
+	}
+
+	defaultCmd, _ := cfg.DefaultCommandFor(deps.RepoRoot, branchName, template)
+	opensInTmux := cfg.OpensInTmux()
+	if fromStdin || events != nil {
+		// stdin was the pipe, or stdout is the events, so there's no one to
+		// use the default command or a tmux session; the path is printed for
+		// whatever comes next
+		defaultCmd = nil
+		opensInTmux = false
+	}
+	if *dryRun {
+		// A dry run doesn't touch origin, so it plans from the refs already
+		// fetched
+		opts.NoFetch = true
+		plan, err := deps.WorktreeManager.PlanWorktree(branchName, opts)
+		if err != nil {
+			return err
+		}
+		var steps []string
+		if hasTemplate {
+			step := "apply the " + templatePrefix + " template"
+			if template.SparseProfile != "" && len(*paths) == 0 {
+				step += ", checking out sparse profile " + template.SparseProfile
+			}
+			steps = append(steps, step)
+		}
+		steps = append(steps, plan.Steps()...)
+		if editorName != "" && editorName != editor.None && deps.Editor != nil {
+			steps = append(steps, "open it in "+editorName)
+		}
+		command := args[1:]
+		if len(command) == 0 {
+			command = defaultCmd
+		}
+		switch {
+		case len(command) > 0 && (*detached || cfg.Detach):
+			steps = append(steps, "start "+strings.Join(command, " ")+" in the background")
+		case opensInTmux && len(command) > 0:
+			steps = append(steps, "open tmux session "+plan.Branch+" running "+strings.Join(command, " "))
+		case opensInTmux:
+			steps = append(steps, "open tmux session "+plan.Branch)
+		case len(command) > 0:
+			steps = append(steps, "run "+strings.Join(command, " ")+" in it")
+		default:
+			steps = append(steps, "print its path")
+		}
+		presenter.Result("Dry run: sprout create would\n  " + strings.Join(steps, "\n  "))
+		if events != nil {
+			events.Emit(progress.Event{Type: "done", Status: "planned", Branch: plan.Branch, Path: plan.Path})
+		}
+		return nil
+	}
+
+	worktreePath, err := deps.WorktreeManager.CreateWorktreeWithOptions(branchName, opts)
+	if err != nil {
+		return err
+	}
+
+	if existing.WorktreePath != "" {
+		presenter.Result(fmt.Sprintf("Reusing existing worktree at: %s", worktreePath))
+	} else {
+		presenter.Result(fmt.Sprintf("Worktree ready at: %s", worktreePath))
+	}
+
+	if editorName != "" && editorName != editor.None && deps.Editor != nil {
+		if err := deps.Editor.Open(editorName, worktreePath, reuseWindow); err != nil {
+			return err
+		}
+	}
+
+	if events != nil {
+		status := "created"
+		if existing.WorktreePath != "" {
+			status = "reused"
+		}
+		events.Emit(progress.Event{Type: "done", Status: status, Branch: existing.Branch, Path: worktreePath})
+		return nil
+	}
+
+	if *detached || cfg.Detach {
+		command := args[1:]
+		if len(command) == 0 {
+			command = defaultCmd
+		}
+		if len(command) > 0 {
+			sanitized, _ := git.ValidateBranchName(branchName)
+			return startDetached(sanitized, worktreePath, command, opensInTmux, *quiet, deps)
 		}
+	}
+	if opensInTmux {
+		// The session runs the given command, or the default command, instead of sprout
+		command := args[1:]
+		if len(command) == 0 {
+			command = defaultCmd
+		}
+		deps.Metadata.RecordLastCommand(existing.Branch, worktreePath, command)
+		return openInTmux(branchName, worktreePath, command, deps)
+	}
 
-		defaultCmd := cfg.GetDefaultCommand()
+	// If no command provided, check for default command
+	if len(args) == 1 {
 		if len(defaultCmd) > 0 {
 			// Execute the default command in the worktree directory
-			cmd := exec.Command(defaultCmd[0], defaultCmd[1:]...)
-			cmd.Dir = worktreePath
-			cmd.Stdin = os.Stdin
-			cmd.Stdout = deps.Output
-			cmd.Stderr = deps.ErrorOutput
-
-			if err := cmd.Run(); err != nil {
-				if exitError, ok := err.(*exec.ExitError); ok {
-					if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
-						fmt.Fprintf(deps.ErrorOutput, "\nWorktree directory: %s\n", worktreePath)
-						os.Exit(status.ExitStatus())
-					}
-				}
-				return fmt.Errorf("default command failed: %w", err)
-			}
-			fmt.Fprintf(deps.ErrorOutput, "\nWorktree directory: %s\n", worktreePath)
-			return nil
+			return runInWorktree(existing.Branch, worktreePath, defaultCmd, "default command", deps)
 		}
 
 		// No default command, output path for shell evaluation
-		fmt.Fprint(deps.Output, worktreePath)
+		if *quiet {
+			fmt.Fprintln(deps.Output, worktreePath)
+		} else {
+			fmt.Fprint(deps.Output, worktreePath)
+		}
 		return nil
 	}
 
 	// Execute the provided command in the worktree directory
-	command := args[1]
-	commandArgs := args[2:]
+	return runInWorktree(existing.Branch, worktreePath, args[1:], "command", deps)
+}
 
-	cmd := exec.Command(command, commandArgs...)
+// runInWorktree runs command in branch's worktree attached to the terminal,
+// remembering it for sprout resume, then points back at the worktree. sprout
+// exits with the command's status when it fails
+func runInWorktree(branch, worktreePath string, command []string, name string, deps *Dependencies) error {
+	logCommandRun(deps, branch, worktreePath, command, "")
+	deps.Metadata.RecordLastCommand(branch, worktreePath, command)
+
+	cmd := exec.Command(command[0], command[1:]...)
 	cmd.Dir = worktreePath
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = deps.Output
@@ -414,19 +1563,1328 @@ func handleCreateCommandWithDeps(args []string, deps *Dependencies) error {
 				os.Exit(status.ExitStatus())
 			}
 		}
-		return fmt.Errorf("command failed: %w", err)
+		return fmt.Errorf("%s failed: %w", name, err)
 	}
 
 	fmt.Fprintf(deps.ErrorOutput, "\nWorktree directory: %s\n", worktreePath)
 	return nil
 }
 
-func handlePruneCommandWithDeps(args []string, deps *Dependencies) error {
-	if len(args) == 0 {
-		// Prune all merged branches
-		return deps.WorktreeManager.PruneAllMerged()
+// startDetached runs command for branch's worktree without waiting for it: in
+// a tmux session of its own when openIn is tmux, otherwise in the background
+// with its output going to a log. Pruning the worktree stops it. The
+// worktree's path is output as when there's no command, for cd
+func startDetached(branch, worktreePath string, command []string, inTmux, quiet bool, deps *Dependencies) error {
+	run := metadata.DetachedRun{Command: strings.Join(command, " ")}
+	if inTmux {
+		if deps.Tmux == nil || !deps.Tmux.Available() {
+			return fmt.Errorf("openIn is set to tmux but tmux was not found on PATH")
+		}
+		run.Session = tmux.SessionName(branch)
+		if err := deps.Tmux.Start(run.Session, worktreePath, command); err != nil {
+			return err
+		}
+	} else {
+		run.Log = deps.Metadata.DetachedLogPath(branch)
+		process, err := deps.StartDetached(worktreePath, command, run.Log)
+		if err != nil {
+			return err
+		}
+		run.PID, run.ProcessStart = process.PID, process.Started
 	}
+	deps.Metadata.RecordDetached(branch, run)
+	logCommandRun(deps, branch, worktreePath, command, "detached")
 
-	branchName := args[0]
-	return deps.WorktreeManager.PruneWorktree(branchName)
+	if !quiet {
+		if run.Session != "" {
+			fmt.Fprintf(deps.ErrorOutput, "Started %s in tmux session %s; tmux attach -t %s to watch it\n", run.Command, run.Session, run.Session)
+		} else {
+			fmt.Fprintf(deps.ErrorOutput, "Started %s in the background (PID %d), logging to %s\n", run.Command, run.PID, run.Log)
+		}
+		fmt.Fprintf(deps.ErrorOutput, "sprout prune %s stops it along with the worktree\n", branch)
+		fmt.Fprint(deps.Output, worktreePath)
+	} else {
+		fmt.Fprintln(deps.Output, worktreePath)
+	}
+	return nil
+}
+
+// logCommandRun adds a command sprout ran in a worktree to the repository's
+// history
+func logCommandRun(deps *Dependencies, branch, worktreePath string, command []string, outcome string) {
+	deps.Metadata.LogEvent(metadata.HistoryEvent{
+		Kind:    metadata.HistoryCommandRun,
+		Branch:  branch,
+		Path:    worktreePath,
+		Command: strings.Join(command, " "),
+		Detail:  outcome,
+	})
+}
+
+// handleCloneCommandWithDeps clones a repository into the bare-repo-with-worktrees
+// layout and outputs the primary worktree's path
+func handleCloneCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("repository URL is required. Usage: sprout clone <url> [directory]")
+	}
+
+	url := args[0]
+	dir := git.CloneDirName(url)
+	if len(args) == 2 {
+		dir = args[1]
+	}
+	if dir == "" {
+		return fmt.Errorf("could not work out a directory name from %s; pass one as the second argument", url)
+	}
+
+	result, err := deps.Cloner.Clone(url, dir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(deps.ErrorOutput, "Cloned %s into %s\n", url, result.Root)
+	fmt.Fprintf(deps.ErrorOutput, "  %-12s bare repository\n", git.BareDirName+"/")
+	fmt.Fprintf(deps.ErrorOutput, "  %-12s worktree for %s\n", result.DefaultBranch+"/", result.DefaultBranch)
+	fmt.Fprintf(deps.ErrorOutput, "New worktrees will be created beside %s.\n", result.DefaultBranch)
+	fmt.Fprintln(deps.ErrorOutput)
+	fmt.Fprintln(deps.ErrorOutput, "Already have a checkout of this repo? Run sprout migrate inside it to convert it in place.")
+
+	fmt.Fprint(deps.Output, result.PrimaryWorktree)
+	return nil
+}
+
+// handleMigrateCommandWithDeps converts the current checkout into the
+// bare-repo-with-worktrees layout and outputs the new worktree's path
+func handleMigrateCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("migrate", deps)
+	dryRun := fs.Bool("dry-run", false, "show the steps without changing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s. Usage: sprout migrate [--dry-run]", strings.Join(fs.Args(), " "))
+	}
+
+	plan, err := deps.Migrator.PlanMigration(deps.RepoRoot)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(deps.ErrorOutput, "Converting %s to a bare repository with worktrees:\n", plan.Root)
+	if *dryRun {
+		for i, step := range plan.Steps {
+			fmt.Fprintf(deps.ErrorOutput, "  %d. %s\n", i+1, step)
+		}
+		fmt.Fprintln(deps.ErrorOutput, "Dry run: nothing was changed.")
+		return nil
+	}
+
+	err = deps.Migrator.Migrate(plan, func(step string) {
+		fmt.Fprintf(deps.ErrorOutput, "  ✓ %s\n", step)
+	})
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	fmt.Fprintf(deps.ErrorOutput, "Done. %s is now checked out at %s; cd there to keep working.\n", plan.Branch, plan.WorktreePath)
+	fmt.Fprint(deps.Output, plan.WorktreePath)
+	return nil
+}
+
+// handleAuthCommandWithDeps signs in to Linear with OAuth in the browser, or
+// stores a token for the GitHub API; --logout forgets either
+func handleAuthCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("auth", deps)
+	logout := fs.Bool("logout", false, "forget the stored sign-in or token")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: sprout auth linear|github [--logout]")
+	}
+	switch positional[0] {
+	case "linear":
+		return authLinear(*logout, deps)
+	case "github":
+		return authGitHub(*logout, deps)
+	default:
+		return fmt.Errorf("usage: sprout auth linear|github [--logout]")
+	}
+}
+
+func authLinear(logout bool, deps *Dependencies) error {
+	if deps.LinearAuth == nil {
+		return fmt.Errorf("signing in to Linear is not available in this build")
+	}
+
+	if logout {
+		if err := deps.LinearAuth.Logout(); err != nil {
+			return err
+		}
+		fmt.Fprintln(deps.Output, "Signed out of Linear")
+		return nil
+	}
+
+	cfg, err := deps.ConfigLoader.GetConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.LinearOAuthClientID == "" {
+		return fmt.Errorf("linearOAuthClientId is not configured. Create an OAuth application in Linear (Settings > API) with the callback URL %s and add its client ID to %s", linear.OAuthRedirectURI, configPathForDisplay(deps))
+	}
+	if _, err := deps.LinearAuth.Login(deps.ErrorOutput); err != nil {
+		return err
+	}
+	fmt.Fprintln(deps.Output, "Signed in to Linear")
+	if cfg.LinearAPIKey != "" {
+		fmt.Fprintln(deps.ErrorOutput, "Note: linearApiKey is still set and is used instead; remove it to use this sign-in")
+	}
+	return nil
+}
+
+// authGitHub stores a GitHub token read from stdin in the system keychain, for
+// looking up PR status without gh
+func authGitHub(logout bool, deps *Dependencies) error {
+	if deps.GitHubTokens == nil {
+		return fmt.Errorf("storing a GitHub token is not available in this build")
+	}
+
+	if logout {
+		if err := deps.GitHubTokens.DeleteToken(); err != nil {
+			return err
+		}
+		fmt.Fprintln(deps.Output, "Removed the stored GitHub token")
+		return nil
+	}
+
+	token, err := readToken(deps, "GitHub token (needs read access to pull requests): ")
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("no token given. Pass one on stdin, e.g. gh auth token | sprout auth github")
+	}
+	if err := deps.GitHubTokens.SaveToken(token); err != nil {
+		if errors.Is(err, keychain.ErrUnavailable) {
+			return fmt.Errorf("%w to store the token in; set GH_TOKEN instead", err)
+		}
+		return err
+	}
+	fmt.Fprintln(deps.Output, "Stored the GitHub token")
+	if github.TokenFromEnv() != "" {
+		fmt.Fprintln(deps.ErrorOutput, "Note: GH_TOKEN or GITHUB_TOKEN is set and is used instead")
+	}
+	return nil
+}
+
+// readToken reads a secret from the first line of input, without echoing it
+// when it's typed at a terminal
+func readToken(deps *Dependencies, prompt string) (string, error) {
+	if deps.Input == nil {
+		return "", nil
+	}
+	if file, ok := deps.Input.(*os.File); ok && term.IsTerminal(file.Fd()) {
+		fmt.Fprint(deps.ErrorOutput, prompt)
+		secret, err := term.ReadPassword(file.Fd())
+		fmt.Fprintln(deps.ErrorOutput)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the token: %w", err)
+		}
+		return strings.TrimSpace(string(secret)), nil
+	}
+	line, err := bufio.NewReader(deps.Input).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read the token: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// handleSubtaskCommandWithDeps creates a Linear subtask under a parent issue and,
+// with --worktree, a worktree for the new subtask. With --from-file it creates
+// one subtask per line instead
+func handleSubtaskCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("subtask", deps)
+	withWorktree := fs.Bool("worktree", false, "also create a worktree for the new subtask")
+	fs.BoolVar(withWorktree, "w", false, "shorthand for --worktree")
+	fromFile := fs.String("from-file", "", "create a subtask for each line of a file, or of stdin with -")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if *fromFile != "" {
+		if len(positional) != 1 {
+			return fmt.Errorf("parent issue is required, and titles come from the file. Usage: sprout subtask <parent-id> --from-file <file|->")
+		}
+		if *withWorktree {
+			return fmt.Errorf("--worktree can't be used with --from-file")
+		}
+	} else if len(positional) < 2 {
+		return fmt.Errorf("parent issue and title are required. Usage: sprout subtask <parent-id> <title> [--worktree]")
+	}
+	if deps.LinearClient == nil {
+		return fmt.Errorf("Linear API key is not configured. Add linearApiKey to %s", configPathForDisplay(deps))
+	}
+
+	parentID := strings.ToUpper(positional[0])
+	if *fromFile != "" {
+		return createSubtasksFromFile(parentID, *fromFile, deps)
+	}
+	title := strings.Join(positional[1:], " ")
+
+	subtask, err := deps.LinearClient.CreateSubtask(parentID, title)
+	if err != nil {
+		return fmt.Errorf("failed to create subtask: %w", err)
+	}
+
+	fmt.Fprintf(deps.Output, "Created %s: %s\n", subtask.Identifier, subtask.Title)
+	if subtask.URL != "" {
+		fmt.Fprintln(deps.Output, subtask.URL)
+	}
+
+	if !*withWorktree {
+		return nil
+	}
+
+	worktreePath, err := deps.WorktreeManager.CreateWorktree(subtask.GetBranchName())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(deps.Output, "Worktree ready at: %s\n", worktreePath)
+	return nil
+}
+
+// readPipedLine is the first line piped to sprout that isn't blank
+func readPipedLine(deps *Dependencies) (string, error) {
+	stdin := deps.Stdin
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			return line, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return "", fmt.Errorf("nothing was piped in. Pipe a branch name, e.g. echo fix/login-bug | sprout create -")
+}
+
+// pipedIssueFor looks up the issue a piped line starts with, such as ENG-123
+// or a line of issues listed with it first
+func pipedIssueFor(line string, deps *Dependencies) (*linear.Issue, error) {
+	if deps.LinearClient == nil {
+		return nil, fmt.Errorf("Linear API key is not configured. Add linearApiKey to %s", configPathForDisplay(deps))
+	}
+	identifier := strings.Fields(line)[0]
+	issue, err := deps.LinearClient.GetIssue(identifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %s: %w", identifier, err)
+	}
+	if issue == nil {
+		return nil, fmt.Errorf("issue %s not found", identifier)
+	}
+	return issue, nil
+}
+
+// linkedIssue is the issue link points at, titled as its tracker has it.
+// Linear's are looked up as identifiers are, falling back to the title in
+// the link when Linear isn't set up
+func linkedIssue(link issues.Link, deps *Dependencies) (*linear.Issue, error) {
+	if link.Tracker == issues.LinkLinear {
+		if deps.LinearClient == nil && link.Slug != "" {
+			return &linear.Issue{Identifier: link.Identifier, Title: link.SlugTitle()}, nil
+		}
+		return pipedIssueFor(link.Identifier, deps)
+	}
+	if deps.IssueLinks == nil {
+		return nil, fmt.Errorf("%s issue links can't be looked up here", link.Tracker)
+	}
+	title, err := deps.IssueLinks.Title(link)
+	if err != nil {
+		return nil, problem.WithHint(err, fmt.Sprintf("sprout create %s-<short-title> names the branch yourself", strings.ToLower(link.Identifier)))
+	}
+	return &linear.Issue{Identifier: link.Identifier, Title: title}, nil
+}
+
+// createSubtasksFromFile creates a subtask under parentID for each item of a
+// checklist, carrying on past failures and reporting each line as it goes
+func createSubtasksFromFile(parentID, path string, deps *Dependencies) error {
+	var data []byte
+	var err error
+	source := path
+	if path == "-" {
+		source = "stdin"
+		stdin := deps.Stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read subtasks: %w", err)
+	}
+
+	titles := linear.ChecklistTitles(string(data))
+	if len(titles) == 0 {
+		return fmt.Errorf("no subtasks to create: %s has no titles in it", source)
+	}
+
+	failed := 0
+	for i, title := range titles {
+		progress := fmt.Sprintf("[%d/%d]", i+1, len(titles))
+		subtask, err := deps.LinearClient.CreateSubtask(parentID, title)
+		if err != nil {
+			failed++
+			fmt.Fprintf(deps.Output, "%s Failed %q: %v\n", progress, title, err)
+			continue
+		}
+		fmt.Fprintf(deps.Output, "%s Created %s: %s\n", progress, subtask.Identifier, subtask.Title)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to create %d of %d subtasks", failed, len(titles))
+	}
+	fmt.Fprintf(deps.Output, "Created %d subtasks under %s\n", len(titles), parentID)
+	return nil
+}
+
+func configPathForDisplay(deps *Dependencies) string {
+	if deps.ConfigPathProvider != nil {
+		if path, err := deps.ConfigPathProvider.GetConfigPath(); err == nil {
+			return path
+		}
+	}
+	return "~/.sprout.json5"
+}
+
+// handleSwitchCommandWithDeps locates the worktree for a branch and either attaches
+// to its tmux session or outputs its path for shell evaluation
+func handleSwitchCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) != 1 {
+		return fmt.Errorf("branch name is required. Usage: sprout switch <branch-name>")
+	}
+	branchName := args[0]
+
+	worktrees, err := deps.WorktreeManager.ListWorktrees()
+	if err != nil {
+		return err
+	}
+
+	var worktreePath string
+	for _, wt := range worktrees {
+		if wt.Branch == branchName {
+			worktreePath = wt.Path
+			break
+		}
+	}
+	if worktreePath == "" {
+		return problem.WithHint(fmt.Errorf("no worktree found for branch %s", branchName), "sprout create "+branchName+" makes one")
+	}
+
+	cfg, err := deps.ConfigLoader.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := deps.WorktreeManager.StartTimer(branchName, worktreePath); err != nil {
+		fmt.Fprintf(deps.ErrorOutput, "Warning: %v\n", err)
+	}
+	if cfg.OpensInTmux() {
+		return openInTmux(branchName, worktreePath, nil, deps)
+	}
+
+	fmt.Fprint(deps.Output, worktreePath)
+	return nil
+}
+
+func openInTmux(branchName, worktreePath string, command []string, deps *Dependencies) error {
+	if deps.Tmux == nil || !deps.Tmux.Available() {
+		return fmt.Errorf("openIn is set to tmux but tmux was not found on PATH")
+	}
+	return deps.Tmux.Open(tmux.SessionName(branchName), worktreePath, command)
+}
+
+func handlePruneCommandWithDeps(args []string, deps *Dependencies) error {
+	return runPrune("prune", args, deps, false)
+}
+
+func handleRmCommandWithDeps(args []string, deps *Dependencies) error {
+	return runPrune("rm", args, deps, true)
+}
+
+func runPrune(name string, args []string, deps *Dependencies, requireBranch bool) error {
+	opts := git.PruneOptions{Progress: deps.presenter(), Results: progress.NewText(deps.Output)}
+	var yes bool
+	fs := newFlagSet(name, deps)
+	fs.BoolVar(&opts.KeepBranch, "keep-branch", false, "remove the worktree but keep the local branch")
+	fs.BoolVar(&opts.DeleteRemote, "delete-remote", false, "also delete the branch on origin")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "print what would be removed without removing anything")
+	fs.BoolVar(&opts.Unlock, "unlock", false, "remove a worktree even if it's locked with git worktree lock")
+	fs.BoolVar(&opts.Porcelain, "quiet", false, "print only a tab-separated line per worktree removed")
+	fs.BoolVar(&opts.Porcelain, "porcelain", false, "same as --quiet")
+	fs.BoolVar(&yes, "yes", false, "prune without asking first, for scripts")
+	fs.BoolVar(&yes, "f", false, "same as --yes")
+	var largerThan string
+	var allRepos bool
+	if !requireBranch {
+		fs.StringVar(&largerThan, "larger-than", "", "remove every worktree bigger than this size (e.g. 5GB)")
+		fs.BoolVar(&allRepos, "all-repos", false, "prune merged worktrees in every registered repository")
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	// Dry runs remove nothing, so there's nothing to confirm
+	ask := !yes && !opts.DryRun
+
+	if allRepos {
+		if len(positional) > 0 || largerThan != "" {
+			return fmt.Errorf("--all-repos only prunes merged worktrees and cannot be combined with a branch name or --larger-than")
+		}
+		repos, err := targetRepos(deps, true)
+		if err != nil {
+			return err
+		}
+		return pruneMerged(repos, opts, ask, deps)
+	}
+
+	if largerThan != "" {
+		if len(positional) > 0 {
+			return fmt.Errorf("--larger-than cannot be combined with a branch name")
+		}
+		threshold, err := stats.ParseSize(largerThan)
+		if err != nil {
+			return err
+		}
+		return deps.WorktreeManager.PruneLargerThan(threshold, opts)
+	}
+
+	if len(positional) == 0 {
+		if requireBranch {
+			return fmt.Errorf("branch name is required. Usage: sprout %s <branch-name> [--keep-branch] [--delete-remote] [--dry-run] [--quiet] [--yes]", name)
+		}
+		// Prune all merged branches
+		return pruneMerged([]RepoTarget{{WorktreeManager: deps.WorktreeManager}}, opts, ask, deps)
+	}
+
+	branchName := positional[0]
+	if ask {
+		existing, err := deps.WorktreeManager.FindExisting(branchName)
+		if err != nil {
+			return err
+		}
+		if existing.WorktreePath != "" && deps.WorktreeManager.HasUncommittedChanges(existing.WorktreePath) {
+			ok, err := confirm(deps, fmt.Sprintf("%s has uncommitted changes. Prune it anyway?", branchName))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				opts.Progress.Result("Nothing was pruned")
+				return nil
+			}
+		}
+	}
+	return deps.WorktreeManager.PruneWorktree(branchName, opts)
+}
+
+// pruneMerged prunes every merged worktree in repos. Unless ask is false it
+// lists them and asks first, then asks again about each with uncommitted
+// changes, leaving the ones declined in place.
+func pruneMerged(repos []RepoTarget, opts git.PruneOptions, ask bool, deps *Dependencies) error {
+	merged := make([][]git.Worktree, len(repos))
+	skipped := make([][]git.PruneOutcome, len(repos))
+	var listing []string
+	for i, repo := range repos {
+		repo.WorktreeManager.SetProgress(opts.Progress)
+		worktrees, err := repo.WorktreeManager.MergedWorktrees()
+		if err != nil {
+			return prefixRepoError(repo, err)
+		}
+		merged[i] = worktrees
+		for _, wt := range worktrees {
+			listing = append(listing, "  - "+repoLabel(repo)+wt.Branch)
+		}
+	}
+
+	if ask && len(listing) > 0 {
+		fmt.Fprintf(deps.ErrorOutput, "These %d merged worktree(s) will be pruned:\n%s\n", len(listing), strings.Join(listing, "\n"))
+		ok, err := confirm(deps, "Prune them?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			opts.Progress.Result("Nothing was pruned")
+			return nil
+		}
+		for i, repo := range repos {
+			var keep []git.Worktree
+			for _, wt := range merged[i] {
+				if repo.WorktreeManager.HasUncommittedChanges(wt.Path) {
+					ok, err := confirm(deps, fmt.Sprintf("%s%s has uncommitted changes. Prune it anyway?", repoLabel(repo), wt.Branch))
+					if err != nil {
+						return err
+					}
+					if !ok {
+						skipped[i] = append(skipped[i], git.PruneOutcome{Branch: wt.Branch, Path: wt.Path, Status: git.PruneSkipped, Reason: "kept for its uncommitted changes"})
+						continue
+					}
+				}
+				keep = append(keep, wt)
+			}
+			merged[i] = keep
+		}
+	}
+
+	for i, repo := range repos {
+		if repo.Name != "" && !opts.Porcelain {
+			opts.Progress.Result(repo.Name + ":")
+		}
+		if err := pruneMergedIn(repo, merged[i], skipped[i], ask, opts, deps); err != nil {
+			return prefixRepoError(repo, err)
+		}
+	}
+	return nil
+}
+
+// repoLabel prefixes a branch with its repository when pruning across several
+func repoLabel(repo RepoTarget) string {
+	if repo.Name == "" {
+		return ""
+	}
+	return repo.Name + ": "
+}
+
+func prefixRepoError(repo RepoTarget, err error) error {
+	if repo.Name == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", repo.Name, err)
+}
+
+// confirm asks a yes or no question on stderr and reads the answer; anything
+// but y or yes is a no. Without a terminal to ask on it refuses, so scripts
+// have to say --yes up front.
+func confirm(deps *Dependencies, question string) (bool, error) {
+	if deps.Input == nil {
+		return false, fmt.Errorf("can't ask %q without a terminal; pass --yes to go ahead without asking", question)
+	}
+	reader, ok := deps.Input.(*bufio.Reader)
+	if !ok {
+		// Keep one reader so answers buffered past this line aren't lost
+		reader = bufio.NewReader(deps.Input)
+		deps.Input = reader
+	}
+
+	fmt.Fprintf(deps.ErrorOutput, "%s [y/N] ", question)
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(deps.ErrorOutput)
+		return false, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// handleArchiveCommandWithDeps saves what a worktree has that isn't merged,
+// removes it, and prints where the archive went
+func handleArchiveCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) != 1 {
+		return fmt.Errorf("branch name is required. Usage: sprout archive <branch-name>")
+	}
+
+	archive, err := deps.WorktreeManager.ArchiveWorktree(args[0])
+	if err != nil {
+		return err
+	}
+
+	var saved []string
+	if archive.Commits > 0 {
+		saved = append(saved, pluralize(archive.Commits, "unmerged commit"))
+	}
+	if archive.Changes {
+		saved = append(saved, "uncommitted changes")
+	}
+	if archive.Untracked > 0 {
+		saved = append(saved, pluralize(archive.Untracked, "untracked file"))
+	}
+	if len(saved) == 0 {
+		saved = append(saved, "nothing unmerged, so only its commit")
+	}
+	fmt.Fprintf(deps.ErrorOutput, "Archived %s (%s). Restore it with: sprout restore %s\n", archive.Branch, strings.Join(saved, ", "), archive.Branch)
+	fmt.Fprintln(deps.Output, archive.Dir)
+	return nil
+}
+
+// pluralize is count followed by noun, with an s unless count is 1
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// handleRestoreCommandWithDeps brings back an archived worktree and prints its path
+func handleRestoreCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) != 1 {
+		return fmt.Errorf("branch name is required. Usage: sprout restore <branch-name>")
+	}
+
+	worktreePath, err := deps.WorktreeManager.RestoreWorktree(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(deps.Output, worktreePath)
+	return nil
+}
+
+// handleExportCommandWithDeps packs a worktree's branch, changes and issue
+// link into one file for a teammate, and prints the file's path
+func handleExportCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("export", deps)
+	var output string
+	fs.StringVar(&output, "output", "", "file to write (defaults to <branch>"+git.HandoffExtension+" here)")
+	fs.StringVar(&output, "o", "", "same as --output")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("branch name is required. Usage: sprout export [-o file] <branch-name>")
+	}
+	if output == "" {
+		output = git.HandoffFileName(positional[0])
+	}
+	if output, err = filepath.Abs(output); err != nil {
+		return err
+	}
+
+	archive, err := deps.WorktreeManager.ExportWorktree(positional[0], output)
+	if err != nil {
+		return err
+	}
+	var saved []string
+	if archive.Commits > 0 {
+		saved = append(saved, pluralize(archive.Commits, "unmerged commit"))
+	}
+	if archive.Changes {
+		saved = append(saved, "uncommitted changes")
+	}
+	if archive.Untracked > 0 {
+		saved = append(saved, pluralize(archive.Untracked, "untracked file"))
+	}
+	if archive.Issue != "" {
+		saved = append(saved, "linked to "+archive.Issue)
+	}
+	if len(saved) == 0 {
+		saved = append(saved, "nothing unmerged, so only its commit")
+	}
+	fmt.Fprintf(deps.ErrorOutput, "Exported %s (%s). Pick it up with: sprout import %s\n", archive.Branch, strings.Join(saved, ", "), filepath.Base(archive.Dir))
+	fmt.Fprintln(deps.Output, archive.Dir)
+	return nil
+}
+
+// handleImportCommandWithDeps recreates a worktree from a file sprout export
+// wrote, and prints its path
+func handleImportCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) != 1 {
+		return fmt.Errorf("file is required. Usage: sprout import <file>")
+	}
+
+	archive, worktreePath, err := deps.WorktreeManager.ImportWorktree(args[0])
+	if err != nil {
+		return err
+	}
+	if archive.Issue != "" {
+		fmt.Fprintf(deps.ErrorOutput, "Imported %s, linked to %s\n", archive.Branch, archive.Issue)
+	} else {
+		fmt.Fprintf(deps.ErrorOutput, "Imported %s\n", archive.Branch)
+	}
+	fmt.Fprintln(deps.Output, worktreePath)
+	return nil
+}
+
+// handlePinCommandWithDeps protects a worktree from being pruned until it's unpinned
+func handlePinCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) != 1 {
+		return fmt.Errorf("branch name is required. Usage: sprout pin <branch-name>")
+	}
+
+	if err := deps.WorktreeManager.PinWorktree(args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(deps.ErrorOutput, "Pinned %s; prune leaves it alone until sprout unpin %s\n", args[0], args[0])
+	return nil
+}
+
+// handleUnpinCommandWithDeps lifts the pin on a worktree so it can be pruned again
+func handleUnpinCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) != 1 {
+		return fmt.Errorf("branch name is required. Usage: sprout unpin <branch-name>")
+	}
+
+	if err := deps.WorktreeManager.UnpinWorktree(args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(deps.ErrorOutput, "Unpinned %s\n", args[0])
+	return nil
+}
+
+// handleNoteCommandWithDeps keeps a short note on a worktree, such as what
+// it's waiting on, or shows the one it has. --clear removes it
+func handleNoteCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("note", deps)
+	clearNote := fs.Bool("clear", false, "remove the worktree's note")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("branch name is required. Usage: sprout note [--clear] <branch-name> [text...]")
+	}
+	branch, text := positional[0], strings.Join(positional[1:], " ")
+
+	switch {
+	case *clearNote && text != "":
+		return fmt.Errorf("--clear takes no text. Usage: sprout note [--clear] <branch-name> [text...]")
+	case *clearNote:
+		if err := deps.WorktreeManager.SetNote(branch, ""); err != nil {
+			return err
+		}
+		fmt.Fprintf(deps.ErrorOutput, "Cleared the note on %s\n", branch)
+		return nil
+	case text != "":
+		if err := deps.WorktreeManager.SetNote(branch, text); err != nil {
+			return err
+		}
+		fmt.Fprintf(deps.ErrorOutput, "Noted on %s: %s\n", branch, text)
+		return nil
+	}
+
+	worktrees, err := deps.WorktreeManager.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch != branch {
+			continue
+		}
+		if wt.Note == "" {
+			fmt.Fprintf(deps.ErrorOutput, "%s has no note; sprout note %s <text> adds one\n", branch, branch)
+			return nil
+		}
+		fmt.Fprintln(deps.Output, wt.Note)
+		return nil
+	}
+	return fmt.Errorf("worktree does not exist: %s", branch)
+}
+
+// handlePRCommandWithDeps runs sprout pr's subcommands; checkout picks an
+// existing PR back up, making or reusing its worktree and pulling its latest
+// head, then opens it as sprout switch does
+func handlePRCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) == 0 || args[0] != "checkout" {
+		return fmt.Errorf("subcommand is required. Usage: sprout pr checkout [--open editor] [number|branch|issue]")
+	}
+	fs := newFlagSet("pr checkout", deps)
+	openIn := fs.String("open", "", "editor to open the worktree in: code, idea or none (defaults to the repo config)")
+	positional, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return fmt.Errorf("unexpected arguments: %s. Usage: sprout pr checkout [--open editor] [number|branch|issue]", strings.Join(positional[1:], " "))
+	}
+	var ref string
+	if len(positional) == 1 {
+		ref = positional[0]
+	}
+
+	editorName := *openIn
+	reuseWindow := false
+	opts := git.CreateOptions{Progress: deps.presenter()}
+	if deps.RepoConfig != nil {
+		if editorName == "" {
+			editorName = deps.RepoConfig.Open
+		}
+		reuseWindow = deps.RepoConfig.ReuseWindow
+		opts.Submodules = git.SetupFromConfig(deps.RepoConfig.Submodules)
+		opts.LFS = git.SetupFromConfig(deps.RepoConfig.LFS)
+		opts.GitHooks = deps.RepoConfig.GitHooks
+	}
+	if err := editor.Validate(editorName); err != nil {
+		return err
+	}
+	cfg, err := deps.ConfigLoader.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	checkout, err := deps.WorktreeManager.CheckoutPR(ref, opts)
+	if err != nil {
+		return err
+	}
+	pr := checkout.PullRequest
+	if checkout.Created {
+		fmt.Fprintf(deps.ErrorOutput, "Checked out PR #%d (%s) at: %s\n", pr.Number, pr.Title, checkout.WorktreePath)
+	} else {
+		fmt.Fprintf(deps.ErrorOutput, "Updated PR #%d (%s) at: %s\n", pr.Number, pr.Title, checkout.WorktreePath)
+	}
+
+	if editorName != "" && editorName != editor.None && deps.Editor != nil {
+		if err := deps.Editor.Open(editorName, checkout.WorktreePath, reuseWindow); err != nil {
+			return err
+		}
+	}
+	if cfg.OpensInTmux() {
+		return openInTmux(pr.HeadBranch, checkout.WorktreePath, nil, deps)
+	}
+	fmt.Fprint(deps.Output, checkout.WorktreePath)
+	return nil
+}
+
+// handleIssuesCommandWithDeps opens the issue tree for triage, without the
+// branch name input or anything that creates a worktree
+func handleIssuesCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) != 0 {
+		return fmt.Errorf("issues takes no arguments. Usage: sprout issues")
+	}
+	// Like the work queue, the browser draws on stdout
+	if !deps.Interactive {
+		return fmt.Errorf("stdout is not a terminal, so the issue browser can't start. Run sprout issues in a terminal")
+	}
+	return ui.RunIssueBrowser()
+}
+
+// handleUndoCommandWithDeps brings back the worktrees the last prune moved to
+// the trash, printing where each one is
+func handleUndoCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) != 0 {
+		return fmt.Errorf("undo takes no arguments. Usage: sprout undo")
+	}
+
+	restored, err := deps.WorktreeManager.UndoPrune()
+	for _, wt := range restored {
+		fmt.Fprintln(deps.Output, wt.Path)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(deps.ErrorOutput, "Restored %d worktree(s)\n", len(restored))
+	return nil
+}
+
+// handleRenameCommandWithDeps renames a worktree's branch, moves the worktree
+// to match and prints where it is now
+func handleRenameCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) != 2 {
+		return fmt.Errorf("old and new branch names are required. Usage: sprout rename <old-branch> <new-branch>")
+	}
+
+	renamed, err := deps.WorktreeManager.RenameWorktree(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(deps.ErrorOutput, "Renamed %s to %s\n", args[0], renamed.Branch)
+	fmt.Fprintln(deps.Output, renamed.Path)
+	return nil
+}
+
+// handleExecCommandWithDeps runs a command in every worktree, or the ones
+// --status and --match pick out, then summarises how it went in each
+func handleExecCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("exec", deps)
+	parallel := fs.Int("parallel", 1, "run the command in up to this many worktrees at once")
+	status := fs.String("status", "", "only worktrees with this PR status: open, merged, closed or no-pr")
+	match := fs.String("match", "", "only worktrees whose branch matches this glob, e.g. eng-*")
+	// Flags end where the command starts, so the command's own flags pass through
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	command := fs.Args()
+	if len(command) == 0 {
+		return fmt.Errorf("command is required. Usage: sprout exec [--parallel N] [--status S] [--match GLOB] -- <command>")
+	}
+	if *parallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1, got %d", *parallel)
+	}
+	if _, err := path.Match(*match, ""); err != nil {
+		return fmt.Errorf("invalid --match pattern %q: %w", *match, err)
+	}
+
+	worktrees, err := deps.WorktreeManager.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	var tasks []batch.Task
+	for _, wt := range worktrees {
+		if wt.Branch == "" || wt.Prunable {
+			continue
+		}
+		if *status != "" && !strings.EqualFold(wt.PRStatus, strings.ReplaceAll(*status, "-", " ")) {
+			continue
+		}
+		if matched, _ := path.Match(*match, wt.Branch); *match != "" && !matched {
+			continue
+		}
+		tasks = append(tasks, batch.Task{Label: wt.Branch, Dir: wt.Path})
+	}
+	if len(tasks) == 0 {
+		fmt.Fprintln(deps.ErrorOutput, "No worktrees found")
+		return nil
+	}
+
+	executor := &batch.Executor{
+		Run:      deps.RunCommand,
+		Parallel: *parallel,
+		Stdout:   deps.Output,
+		Stderr:   deps.ErrorOutput,
+	}
+	results := executor.Execute(tasks, command)
+
+	// The summary goes to stderr so stdout carries only the command's output
+	width := 0
+	for _, task := range tasks {
+		width = max(width, len(task.Label))
+	}
+	failed := 0
+	fmt.Fprintln(deps.ErrorOutput)
+	for _, result := range results {
+		outcome := "ok"
+		switch {
+		case result.ExitCode < 0:
+			outcome = fmt.Sprintf("failed to start: %v", result.Err)
+		case result.ExitCode > 0:
+			outcome = fmt.Sprintf("exit %d", result.ExitCode)
+		}
+		if result.Err != nil {
+			failed++
+		}
+		fmt.Fprintf(deps.ErrorOutput, "%-*s  %s\n", width, result.Task.Label, outcome)
+		logCommandRun(deps, result.Task.Label, result.Task.Dir, command, outcome)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s failed in %d of %d worktrees", command[0], failed, len(results))
+	}
+	return nil
+}
+
+const defaultSparseProfile = "default"
+
+// handleRepairCommandWithDeps cleans up after worktrees that were deleted or
+// broken outside sprout and reports every fix
+func handleRepairCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments: %s. Usage: sprout repair", strings.Join(args, " "))
+	}
+
+	report, err := deps.WorktreeManager.Repair()
+	if err != nil {
+		return err
+	}
+
+	if report.Empty() {
+		fmt.Fprintln(deps.Output, "Nothing to repair.")
+		return nil
+	}
+	if len(report.PrunedWorktrees) > 0 {
+		fmt.Fprintln(deps.Output, "Removed git's records of deleted worktrees:")
+		for _, path := range report.PrunedWorktrees {
+			fmt.Fprintf(deps.Output, "  %s\n", path)
+		}
+	}
+	if len(report.ForgottenBranches) > 0 {
+		fmt.Fprintln(deps.Output, "Marked worktrees that no longer exist as pruned:")
+		for _, branch := range report.ForgottenBranches {
+			fmt.Fprintf(deps.Output, "  %s\n", branch)
+		}
+	}
+	if len(report.MissingBranches) > 0 {
+		fmt.Fprintln(deps.Output, "Worktrees whose branch no longer exists (remove with git worktree remove <path>):")
+		for _, wt := range report.MissingBranches {
+			fmt.Fprintf(deps.Output, "  %s (%s)\n", wt.Path, wt.Branch)
+		}
+	}
+	return nil
+}
+
+// handleServeCommandWithDeps answers JSON-RPC requests from an editor plugin on
+// stdin until it closes, pushing a notification whenever worktrees change
+func handleServeCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("serve", deps)
+	stdio := fs.Bool("stdio", false, "serve JSON-RPC, one message per line, over stdin and stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*stdio || fs.NArg() > 0 {
+		return fmt.Errorf("sprout serve only speaks JSON-RPC over stdio. Usage: sprout serve --stdio")
+	}
+
+	// Stdout carries the protocol, so progress goes to stderr
+	client, err := sprout.New(sprout.Options{
+		RepoPath: deps.RepoRoot,
+		Progress: deps.ErrorOutput,
+		OnEventPanic: func(change sprout.Change, recovered any) {
+			fmt.Fprintf(deps.ErrorOutput, "Warning: handling %T panicked: %v\n", change, recovered)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return rpc.NewServer(client).Serve(os.Stdin, deps.Output)
+}
+
+func handleSparseCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) == 0 {
+		return fmt.Errorf("subcommand is required. Usage: sprout sparse <set|show|apply>")
+	}
+
+	subcommand := args[0]
+	fs := newFlagSet("sparse "+subcommand, deps)
+	profile := fs.String("profile", defaultSparseProfile, "name of the sparse-checkout profile")
+
+	positional, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	switch subcommand {
+	case "set":
+		var directories []string
+		for _, dir := range positional {
+			dir = strings.Trim(strings.TrimSpace(dir), "/")
+			if dir != "" {
+				directories = append(directories, dir)
+			}
+		}
+		if len(directories) == 0 {
+			return fmt.Errorf("at least one directory is required. Usage: sprout sparse set [--profile name] <dirs...>")
+		}
+		if err := deps.Metadata.SetSparseProfile(*profile, directories); err != nil {
+			return err
+		}
+		fmt.Fprintf(deps.Output, "Saved sparse profile '%s': %s\n", *profile, strings.Join(directories, ", "))
+		return nil
+
+	case "show":
+		profiles := deps.Metadata.SparseProfiles()
+		if len(profiles) == 0 {
+			fmt.Fprintln(deps.Output, "No sparse profiles defined. Create one with: sprout sparse set <dirs...>")
+			return nil
+		}
+
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		headerStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("69")).
+			Bold(true)
+		fmt.Fprintln(deps.Output, headerStyle.Render("🌱 Sparse Checkout Profiles"))
+		fmt.Fprintln(deps.Output)
+		t := newStatsTable("PROFILE", "DIRECTORIES")
+		for _, name := range names {
+			t.Row(name, strings.Join(profiles[name], ", "))
+		}
+		fmt.Fprintln(deps.Output, t)
+		return nil
+
+	case "apply":
+		if len(positional) == 0 {
+			return fmt.Errorf("branch name is required. Usage: sprout sparse apply <branch> [--profile name]")
+		}
+		directories, ok := deps.Metadata.SparseProfiles()[*profile]
+		if !ok {
+			return fmt.Errorf("sparse profile '%s' is not defined", *profile)
+		}
+		if err := deps.WorktreeManager.ApplySparseCheckout(positional[0], directories); err != nil {
+			return err
+		}
+		fmt.Fprintf(deps.Output, "Applied sparse profile '%s' to %s\n", *profile, positional[0])
+		return nil
+
+	default:
+		return fmt.Errorf("unknown sparse subcommand: %s. Usage: sprout sparse <set|show|apply>", subcommand)
+	}
+}
+
+// quietFlags registers --quiet and its --porcelain alias, which limit a
+// command to stable, parseable lines on stdout
+func quietFlags(fs *flag.FlagSet) *bool {
+	quiet := fs.Bool("quiet", false, "print only stable, tab-separated lines for scripts")
+	fs.BoolVar(quiet, "porcelain", false, "same as --quiet")
+	return quiet
+}
+
+func newFlagSet(name string, deps *Dependencies) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(deps.ErrorOutput)
+	return fs
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments, returning the positional arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// handleUpgradeCommandWithDeps replaces the running binary with the latest
+// release, or with --check only reports whether there is one
+func handleUpgradeCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("upgrade", deps)
+	check := fs.Bool("check", false, "only report whether a newer release is available")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s. Usage: sprout upgrade [--check]", strings.Join(fs.Args(), " "))
+	}
+	if deps.Updater == nil {
+		return fmt.Errorf("upgrades are not available in this build")
+	}
+
+	latest, err := deps.Updater.Latest()
+	if err != nil {
+		return err
+	}
+
+	if !version.IsRelease() {
+		return fmt.Errorf("this sprout was built from source, so it can't replace itself with a release; the latest is %s at %s", latest.Version, latest.URL)
+	}
+	if !version.Newer(latest.Version, version.Version) {
+		fmt.Fprintf(deps.Output, "sprout %s is up to date\n", version.Version)
+		return nil
+	}
+	if *check {
+		fmt.Fprintf(deps.Output, "sprout %s is available (installed %s). Run sprout upgrade to install it\n", latest.Version, version.Version)
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the running binary: %w", err)
+	}
+	fmt.Fprintf(deps.ErrorOutput, "Downloading sprout %s...\n", latest.Version)
+	if err := deps.Updater.Install(latest, executable); err != nil {
+		return err
+	}
+	fmt.Fprintf(deps.Output, "Upgraded sprout from %s to %s\n", version.Version, latest.Version)
+	return nil
+}
+
+// gitFeatures is the git feature each command relies on, so an old git gets a
+// warning up front rather than a confusing failure part way through
+var gitFeatures = map[string]version.GitFeature{
+	"create":  version.GitWorktrees,
+	"clone":   version.GitWorktrees,
+	"migrate": version.GitWorktrees,
+	"switch":  version.GitWorktrees,
+	"pr":      version.GitWorktrees,
+	"stack":   version.GitWorktrees,
+	"list":    version.GitWorktrees,
+	"today":   version.GitWorktrees,
+	"prune":   version.GitWorktrees,
+	"gc":      version.GitWorktrees,
+	"rm":      version.GitWorktrees,
+	"repair":  version.GitWorktrees,
+	"archive": version.GitWorktrees,
+	"restore": version.GitWorktrees,
+	"export":  version.GitWorktrees,
+	"import":  version.GitWorktrees,
+	"undo":    version.GitWorktrees,
+	"rename":  version.GitWorktreeMove,
+	"pin":     version.GitWorktreeLock,
+	"unpin":   version.GitWorktreeLock,
+	"exec":    version.GitWorktrees,
+	"serve":   version.GitWorktrees,
+	"sparse":  version.GitSparseCone,
+}
+
+// warnIfGitLacks warns on stderr when the local git is too old for feature;
+// when git's version can't be read, the command's own errors say more
+func warnIfGitLacks(command string, feature version.GitFeature, deps *Dependencies) {
+	if deps.Tools == nil {
+		return
+	}
+	gitVersion, err := deps.Tools.GitVersion()
+	if err != nil || feature.Supports(gitVersion) {
+		return
+	}
+	fmt.Fprintf(deps.ErrorOutput, "Warning: %s relies on %s, which needs git %s or later (found %s)\n",
+		command, feature.Name, feature.MinVersion, gitVersion)
+}
+
+// versionInfo is what sprout version reports; every field is always present in
+// the JSON so scripts can rely on the keys
+type versionInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+	Go      string `json:"go"`
+	Git     string `json:"git"`
+	GH      string `json:"gh"`
+}
+
+func handleVersionCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("version", deps)
+	asJSON := fs.Bool("json", false, "print build and tool versions as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s. Usage: sprout version [--json]", strings.Join(fs.Args(), " "))
+	}
+
+	info := versionInfo{
+		Version: version.Version,
+		Commit:  version.Commit,
+		Date:    version.Date,
+		Go:      runtime.Version(),
+	}
+	if deps.Tools != nil {
+		// A missing tool is reported as an empty version rather than an error
+		info.Git, _ = deps.Tools.GitVersion()
+		info.GH, _ = deps.Tools.GHVersion()
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(deps.Output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	}
+
+	orUnknown := func(value, missing string) string {
+		if value == "" {
+			return missing
+		}
+		return value
+	}
+	fmt.Fprintf(deps.Output, "sprout %s\n", info.Version)
+	fmt.Fprintf(deps.Output, "  commit: %s\n", orUnknown(info.Commit, "unknown"))
+	fmt.Fprintf(deps.Output, "  built:  %s\n", orUnknown(info.Date, "unknown"))
+	fmt.Fprintf(deps.Output, "  go:     %s\n", info.Go)
+	fmt.Fprintf(deps.Output, "  git:    %s\n", orUnknown(info.Git, "not found"))
+	fmt.Fprintf(deps.Output, "  gh:     %s\n", orUnknown(info.GH, "not found"))
+	return nil
 }
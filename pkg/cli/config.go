@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"sprout/pkg/config"
+)
+
+const configUsage = "Usage: sprout config resolved [--json]"
+
+// handleConfigCommandWithDeps shows the config sprout runs with once the
+// files it includes, their overrides and the environment have been layered
+func handleConfigCommandWithDeps(args []string, deps *Dependencies) error {
+	if len(args) == 0 || args[0] != "resolved" {
+		return fmt.Errorf("subcommand is required. %s", configUsage)
+	}
+	fs := newFlagSet("config resolved", deps)
+	asJSON := fs.Bool("json", false, "print the files read and each setting as JSON")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s. %s", strings.Join(fs.Args(), " "), configUsage)
+	}
+
+	configPath, err := deps.ConfigPathProvider.GetConfigPath()
+	if err != nil {
+		return fmt.Errorf("failed to get config path: %w", err)
+	}
+	resolved, err := config.Resolve(configPath)
+	if err != nil {
+		return err
+	}
+	for i, setting := range resolved.Settings {
+		if secretSetting(setting.Key) {
+			resolved.Settings[i].Value = maskAPIKey(fmt.Sprint(setting.Value))
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(deps.Output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Files    []string         `json:"files"`
+			Missing  []string         `json:"missing,omitempty"`
+			Settings []config.Setting `json:"settings"`
+		}{nonNil(resolved.Files), resolved.Missing, nonNil(resolved.Settings)})
+	}
+	printResolvedConfig(deps.Output, configPath, resolved)
+	return nil
+}
+
+// secretSetting is whether the setting at key is a credential, which is
+// masked so the output can be shared
+func secretSetting(key string) bool {
+	return key == "linearApiKey" || key == "webhook.url" || strings.HasPrefix(key, "linearWorkspaces.")
+}
+
+// printResolvedConfig lists the files layered, then each setting with its
+// value and where it was set
+func printResolvedConfig(w io.Writer, configPath string, resolved *config.Resolved) {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("69")).
+		Bold(true)
+
+	accentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("108"))
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("245"))
+
+	fmt.Fprintln(w, headerStyle.Render("🌱 Config files, each winning over those above it"))
+	fmt.Fprintln(w)
+	if len(resolved.Files) == 0 {
+		fmt.Fprintf(w, "  %s (not found, using defaults)\n", configPath)
+	}
+	for _, file := range resolved.Files {
+		fmt.Fprintf(w, "  %s\n", file)
+	}
+	for _, file := range resolved.Missing {
+		fmt.Fprintf(w, "  %s\n", mutedStyle.Render(file+" (included, not found)"))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, headerStyle.Render("🌱 Settings"))
+	fmt.Fprintln(w)
+	if len(resolved.Settings) == 0 {
+		fmt.Fprintln(w, "  none set, everything is at its default")
+		return
+	}
+	keys, values := make([]string, len(resolved.Settings)), make([]string, len(resolved.Settings))
+	keyWidth, valueWidth := 0, 0
+	for i, setting := range resolved.Settings {
+		value, _ := json.Marshal(setting.Value)
+		keys[i], values[i] = setting.Key, string(value)
+		keyWidth, valueWidth = max(keyWidth, len(keys[i])), max(valueWidth, len(values[i]))
+	}
+	for i, setting := range resolved.Settings {
+		fmt.Fprintf(w, "  %s%s  %-*s  %s\n", accentStyle.Render(keys[i]), strings.Repeat(" ", keyWidth-len(keys[i])), valueWidth, values[i], mutedStyle.Render("# "+setting.Source))
+	}
+}
+
+// nonNil is list, or an empty list in its place so JSON shows [] not null
+func nonNil[T any](list []T) []T {
+	if list == nil {
+		return []T{}
+	}
+	return list
+}
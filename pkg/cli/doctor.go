@@ -0,0 +1,333 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"sprout/pkg/config"
+	"sprout/pkg/git"
+	"sprout/pkg/issues"
+	"sprout/pkg/stats"
+	"sprout/pkg/version"
+)
+
+// diskUsageWarningThreshold is the combined worktree size above which doctor warns
+const diskUsageWarningThreshold = 50 * 1024 * 1024 * 1024
+
+// How a sprout doctor check came out
+const (
+	checkOK   = "ok"
+	checkWarn = "warn" // sprout works, but something is missing or could be better
+	checkFail = "fail" // critical: sprout doctor exits non-zero
+)
+
+// The sections of the sprout doctor report, in the order they're shown
+const (
+	doctorSectionConfig = "configuration"
+	doctorSectionLinear = "linear"
+)
+
+var doctorSectionTitles = map[string]string{
+	doctorSectionConfig: "🌱 Sprout Configuration",
+	doctorSectionLinear: "Linear Integration",
+}
+
+// doctorCheck is one line of the sprout doctor report
+type doctorCheck struct {
+	Section string   `json:"section"`
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Detail  string   `json:"detail"`
+	Fix     string   `json:"fix,omitempty"`   // what to do about a warning or failure
+	Notes   []string `json:"notes,omitempty"` // specifics, such as which worktrees are affected
+
+	text string // shown in place of Detail in the text report, when set
+}
+
+// doctorReport is what sprout doctor --json prints; every check is listed,
+// passing or not, so scripts can look for the ones they care about
+type doctorReport struct {
+	OK     bool          `json:"ok"` // no check failed
+	Checks []doctorCheck `json:"checks"`
+}
+
+func handleDoctorCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("doctor", deps)
+	asJSON := fs.Bool("json", false, "print the checks as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s. Usage: sprout doctor [--json]", strings.Join(fs.Args(), " "))
+	}
+
+	checks, err := doctorChecks(deps)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, check := range checks {
+		if check.Status == checkFail {
+			failed++
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(deps.Output)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(doctorReport{OK: failed == 0, Checks: checks}); err != nil {
+			return err
+		}
+	} else {
+		renderDoctorReport(deps.Output, checks)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d critical check(s) failed", failed)
+	}
+	return nil
+}
+
+// doctorChecks runs every check sprout doctor reports on
+func doctorChecks(deps *Dependencies) ([]doctorCheck, error) {
+	cfg, err := deps.ConfigLoader.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []doctorCheck
+	section := doctorSectionConfig
+	add := func(name, status, detail, fix string) *doctorCheck {
+		checks = append(checks, doctorCheck{Section: section, Name: name, Status: status, Detail: detail, Fix: fix})
+		return &checks[len(checks)-1]
+	}
+
+	add("Default Command", checkOK, defaultCommandDetail(cfg, deps.RepoRoot), "").Notes = defaultCommandNotes(cfg)
+	if cfg.ResumeCommand != "" {
+		add("Resume Command", checkOK, cfg.ResumeCommand, "")
+	} else {
+		add("Resume Command", checkOK, "not configured", "")
+	}
+
+	provider := cfg.GetIssueProvider()
+	switch {
+	case provider == issues.None:
+		add("Issue Provider", checkWarn, "none (issue integration off)", "")
+	case deps.LinearClient == nil:
+		add("Issue Provider", checkWarn, provider+" (not connected)", "")
+	default:
+		add("Issue Provider", checkOK, provider, "")
+	}
+
+	if source, fix, ok := prStatusSource(cfg, deps); ok {
+		add("PR Status", checkOK, source, "")
+	} else {
+		add("PR Status", checkWarn, source, fix)
+	}
+
+	// Without git there's nothing sprout can do
+	if deps.Tools != nil {
+		gitVersion, err := deps.Tools.GitVersion()
+		switch {
+		case err != nil:
+			add("Git", checkFail, "not found", "install git and make sure it's on your PATH")
+		case !version.GitSparseCone.Supports(gitVersion):
+			add("Git", checkWarn, gitVersion, fmt.Sprintf("sparse checkouts need git %s or later", version.GitSparseCone.MinVersion))
+		default:
+			add("Git", checkOK, gitVersion, "")
+		}
+	}
+
+	switch {
+	case cfg.LinearAPIKey != "" || len(cfg.LinearWorkspaces) > 0:
+		add("Linear API Key", checkOK, "configured", "")
+	case cfg.LinearOAuthClientID != "":
+		add("Linear API Key", checkOK, "not needed, signing in with OAuth", "")
+	default:
+		add("Linear API Key", checkWarn, "not configured", "")
+	}
+
+	configPath, err := deps.ConfigPathProvider.GetConfigPath()
+	if err != nil {
+		add("Config Path", checkWarn, fmt.Sprintf("<error: %v>", err), "")
+	} else {
+		add("Config Path", checkOK, configPath, "")
+		if deps.ConfigPathProvider.ConfigFileExists() {
+			add("Config File", checkOK, "exists", "")
+		} else {
+			add("Config File", checkWarn, "not found (using defaults)", "")
+		}
+	}
+	if overrides := config.ActiveEnvOverrides(); len(overrides) > 0 {
+		add("Env Overrides", checkOK, strings.Join(overrides, ", "), "")
+	}
+
+	if deps.Editor != nil {
+		if installed := deps.Editor.Installed(); len(installed) > 0 {
+			add("Editors", checkOK, strings.Join(installed, ", "), "")
+		} else {
+			add("Editors", checkWarn, "none detected", "")
+		}
+	}
+	if deps.RepoConfig != nil && deps.RepoConfig.Open != "" {
+		add("Open In", checkOK, deps.RepoConfig.Open, "")
+	}
+
+	// Only a hint: doctor works offline, so a failed check says nothing
+	if deps.Updater != nil && version.IsRelease() {
+		if latest, err := deps.Updater.Latest(); err == nil && version.Newer(latest.Version, version.Version) {
+			add("Update", checkWarn, fmt.Sprintf("%s available (installed %s)", latest.Version, version.Version), "run 'sprout upgrade'")
+		}
+	}
+
+	if listed, err := deps.WorktreeManager.ListWorktrees(); err == nil {
+		// The main checkout can't be pruned, so only the worktrees beside it count
+		var worktrees []git.Worktree
+		for _, wt := range listed {
+			if wt.Path != deps.RepoRoot && !wt.Bare {
+				worktrees = append(worktrees, wt)
+			}
+		}
+		var total int64
+		for _, size := range stats.CachedDirSizes(deps.Metadata, worktreePaths(worktrees), stats.DiskUsageMaxAge) {
+			total += size
+		}
+		if total > diskUsageWarningThreshold {
+			add("Worktree Disk Usage", checkWarn, fmt.Sprintf("%s across %d worktree(s)", stats.FormatBytes(total), len(worktrees)), "try 'sprout prune --larger-than 5GB'")
+		}
+	}
+
+	if problems, err := deps.WorktreeManager.CheckGitHooks(); err == nil && len(problems) > 0 {
+		fix := ""
+		if deps.RepoConfig == nil || deps.RepoConfig.GitHooks == "" {
+			fix = "set gitHooks to link or copy in .sprout.json5 for new ones"
+		}
+		add("Git Hooks", checkWarn, "hooks won't run in some worktrees", fix).Notes = problems
+	}
+
+	section = doctorSectionLinear
+	workspaces := cfg.ForRepo(deps.RepoRoot).GetLinearWorkspaces()
+	switch {
+	case len(workspaces) == 0:
+		add("API Key", checkWarn, "not configured", "")
+		add("Status", checkWarn, "disabled", "")
+	case len(cfg.LinearWorkspaces) == 0 && workspaces[0].OAuth:
+		add("Auth", checkOK, linearSignIn(deps), "")
+	case len(cfg.LinearWorkspaces) == 0:
+		add("API Key", checkOK, maskAPIKey(cfg.LinearAPIKey), "")
+	default:
+		// Only the workspaces this repo's issues come from are listed
+		for _, workspace := range workspaces {
+			credential := maskAPIKey(workspace.APIKey)
+			if workspace.OAuth {
+				credential = linearSignIn(deps)
+			}
+			add("Workspace "+workspace.Name, checkOK, credential, "")
+		}
+	}
+	signsIn := false
+	for _, workspace := range workspaces {
+		signsIn = signsIn || workspace.OAuth
+	}
+	switch {
+	case len(workspaces) == 0:
+	case deps.LinearClient == nil && signsIn:
+		// An OAuth workspace that hasn't signed in yet is left out of the client
+		add("Status", checkWarn, "disabled", "run 'sprout auth linear' to sign in")
+	case deps.LinearClient != nil:
+		checks = append(checks, linearConnectionChecks(deps, section)...)
+	}
+
+	return checks, nil
+}
+
+// defaultCommandDetail is the command new worktrees in repoRoot run unless a
+// template or branch pattern picks another
+func defaultCommandDetail(cfg *config.Config, repoRoot string) string {
+	if command := cfg.DefaultCommands.Repos[repoRoot]; command != "" {
+		return command + " (this repository's)"
+	}
+	if cfg.DefaultCommand != "" {
+		return cfg.DefaultCommand
+	}
+	return "not configured"
+}
+
+// defaultCommandNotes explains how the command for a new worktree is picked,
+// once there's more than defaultCommand to pick from
+func defaultCommandNotes(cfg *config.Config) []string {
+	if len(cfg.DefaultCommands.Repos) == 0 && len(cfg.DefaultCommands.Branches) == 0 {
+		return nil
+	}
+	notes := []string{"first set of: " + strings.Join(config.DefaultCommandOrder, ", ")}
+	for _, pattern := range cfg.DefaultCommands.BranchPatterns() {
+		notes = append(notes, fmt.Sprintf("%s: %s", pattern, cfg.DefaultCommands.Branches[pattern]))
+	}
+	return notes
+}
+
+// linearConnectionChecks signs in to Linear and fetches the assigned issues,
+// which is all sprout needs from it
+func linearConnectionChecks(deps *Dependencies, section string) []doctorCheck {
+	user, err := deps.LinearClient.GetCurrentUser()
+	if err != nil {
+		return []doctorCheck{{Section: section, Name: "Status", Status: checkFail, Detail: fmt.Sprintf("can't connect: %v", err), Fix: "check the API key, or run 'sprout auth linear' to sign in again"}}
+	}
+
+	checks := []doctorCheck{
+		{Section: section, Name: "Status", Status: checkOK, Detail: "connected", text: "✓ Connected"},
+		{Section: section, Name: "User", Status: checkOK, Detail: fmt.Sprintf("%s (%s)", user.Name, user.Email)},
+	}
+	if assigned, err := deps.LinearClient.GetAssignedIssues(); err != nil {
+		checks = append(checks, doctorCheck{Section: section, Name: "Assigned Issues", Status: checkWarn, Detail: fmt.Sprintf("<error fetching: %v>", err)})
+	} else {
+		checks = append(checks, doctorCheck{Section: section, Name: "Assigned Issues", Status: checkOK, Detail: fmt.Sprintf("%d active tickets", len(assigned))})
+	}
+	return checks
+}
+
+// renderDoctorReport prints the checks grouped into their sections, with
+// warnings and failures picked out and followed by how to fix them
+func renderDoctorReport(w io.Writer, checks []doctorCheck) {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("69")).
+		Bold(true)
+
+	accentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("108"))
+
+	statusStyles := map[string]lipgloss.Style{
+		checkOK:   lipgloss.NewStyle().Foreground(lipgloss.Color("252")),
+		checkWarn: lipgloss.NewStyle().Foreground(lipgloss.Color("221")),
+		checkFail: lipgloss.NewStyle().Foreground(lipgloss.Color("204")).Bold(true),
+	}
+
+	section := ""
+	for _, check := range checks {
+		if check.Section != section {
+			if section != "" {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, headerStyle.Render(doctorSectionTitles[check.Section]))
+			fmt.Fprintln(w)
+			section = check.Section
+		}
+
+		value := check.Detail
+		if check.text != "" {
+			value = check.text
+		}
+		if check.Fix != "" {
+			value += " - " + check.Fix
+		}
+		style := statusStyles[check.Status]
+		fmt.Fprintf(w, "  %s: %s\n", accentStyle.Render(check.Name), style.Render(value))
+		for _, note := range check.Notes {
+			fmt.Fprintf(w, "    %s\n", style.Render(note))
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sprout/pkg/git"
+	"sprout/pkg/progress"
+	"sprout/pkg/schedule"
+	"sprout/pkg/stats"
+)
+
+const gcUsage = "Usage: sprout gc [--dry-run] [--yes] [--quiet] [--all-repos] [--stale-days N] [--larger-than SIZE], or sprout gc --schedule hourly|daily|weekly|off"
+
+// handleGCCommandWithDeps collects what's no longer needed: merged
+// worktrees, and stale or oversize ones when gc is set up to, then trash past
+// trashDays. With --schedule it installs or removes a background job that
+// does so for every registered repository
+func handleGCCommandWithDeps(args []string, deps *Dependencies) error {
+	cfg, err := deps.ConfigLoader.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	opts := git.PruneOptions{Progress: deps.presenter(), Results: progress.NewText(deps.Output), KeepUnmerged: true}
+	var yes, allRepos bool
+	var every string
+	fs := newFlagSet("gc", deps)
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "report what would be collected without removing anything")
+	fs.BoolVar(&yes, "yes", false, "collect without asking first, for scripts and schedules")
+	fs.BoolVar(&yes, "f", false, "same as --yes")
+	fs.BoolVar(&opts.Porcelain, "quiet", false, "print only a tab-separated line per worktree or trash entry removed")
+	fs.BoolVar(&opts.Porcelain, "porcelain", false, "same as --quiet")
+	fs.BoolVar(&allRepos, "all-repos", false, "collect in every registered repository")
+	staleDays := fs.Int("stale-days", cfg.GC.StaleDays, "also collect worktrees with no commits for this many days, 0 for none")
+	largerThan := fs.String("larger-than", cfg.GC.LargerThan, "also collect worktrees bigger than this size (e.g. 5GB)")
+	fs.StringVar(&every, "schedule", "", "run sprout gc --all-repos --yes hourly, daily or weekly in the background, or off to stop")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s. %s", strings.Join(positional, " "), gcUsage)
+	}
+	if every != "" {
+		if fs.NFlag() > 1 {
+			return fmt.Errorf("--schedule can't be combined with other flags; the scheduled gc follows the gc settings in your config")
+		}
+		return scheduleGC(every, deps)
+	}
+
+	if *staleDays < 0 {
+		return fmt.Errorf("--stale-days can't be negative")
+	}
+	policy := git.GCPolicy{StaleAfter: time.Duration(*staleDays) * 24 * time.Hour, Now: time.Now()}
+	if *largerThan != "" {
+		if policy.LargerThan, err = stats.ParseSize(*largerThan); err != nil {
+			return err
+		}
+	}
+	repos, err := targetRepos(deps, allRepos)
+	if err != nil {
+		return err
+	}
+	return collectGarbage(repos, policy, opts, !yes && !opts.DryRun, deps)
+}
+
+// collectGarbage lists what gc would collect in each of repos, asks first
+// unless ask is false, then prunes it and empties expired trash. Worktrees
+// with uncommitted changes are always left, since no one may be there to ask
+func collectGarbage(repos []RepoTarget, policy git.GCPolicy, opts git.PruneOptions, ask bool, deps *Dependencies) error {
+	presenter := gcPresenter(opts)
+	candidates := make([][]git.GCCandidate, len(repos))
+	total := 0
+	for i, repo := range repos {
+		found, err := repo.WorktreeManager.GCCandidates(policy)
+		if err != nil {
+			return prefixRepoError(repo, err)
+		}
+		candidates[i] = found
+		total += len(found)
+	}
+
+	if total > 0 {
+		listing := []string{fmt.Sprintf("Found %d worktree(s) to collect:", total)}
+		for i, repo := range repos {
+			for _, candidate := range candidates[i] {
+				listing = append(listing, fmt.Sprintf("  - %s%s (%s, %s)", repoLabel(repo), candidate.Worktree.Branch, candidate.Policy, candidate.Detail))
+			}
+		}
+		presenter.Result(strings.Join(listing, "\n") + "\n")
+		if ask {
+			ok, err := confirm(deps, "Collect them?")
+			if err != nil {
+				return err
+			}
+			if !ok {
+				presenter.Result("Nothing was collected")
+				return nil
+			}
+		}
+	}
+
+	for i, repo := range repos {
+		if repo.Name != "" && !opts.Porcelain {
+			presenter.Result(repo.Name + ":")
+		}
+		if err := collectGarbageIn(repo, candidates[i], opts, deps); err != nil {
+			return prefixRepoError(repo, err)
+		}
+	}
+	return nil
+}
+
+// collectGarbageIn prunes one repository's candidates and empties its
+// expired trash, saying how it went
+func collectGarbageIn(repo RepoTarget, candidates []git.GCCandidate, opts git.PruneOptions, deps *Dependencies) error {
+	presenter := gcPresenter(opts)
+	// Oversize worktrees are deleted outright, as prune --larger-than does,
+	// since the trash would hold on to the space they're collected for
+	var worktrees, oversize []git.Worktree
+	var skipped []git.PruneOutcome
+	policies := make(map[string]string)
+	for _, candidate := range candidates {
+		wt := candidate.Worktree
+		if repo.WorktreeManager.HasUncommittedChanges(wt.Path) {
+			skipped = append(skipped, git.PruneOutcome{Branch: wt.Branch, Path: wt.Path, Status: git.PruneSkipped, Reason: "kept for its uncommitted changes"})
+			continue
+		}
+		if candidate.Policy == git.GCOversize {
+			oversize = append(oversize, wt)
+		} else {
+			worktrees = append(worktrees, wt)
+		}
+		policies[wt.Branch] = candidate.Policy
+	}
+
+	var result git.PruneResult
+	var pruneErr error
+	if len(candidates) > 0 {
+		bar := newPruneProgress(len(candidates), opts, deps)
+		for _, outcome := range skipped {
+			bar.report(outcome)
+		}
+		opts.OnProgress = bar.report
+		if len(worktrees) > 0 {
+			result, pruneErr = repo.WorktreeManager.PruneMergedWorktrees(worktrees, opts)
+		}
+		if len(oversize) > 0 {
+			deleteOpts := opts
+			deleteOpts.Permanent = true
+			deleted, err := repo.WorktreeManager.PruneMergedWorktrees(oversize, deleteOpts)
+			result.Outcomes = append(result.Outcomes, deleted.Outcomes...)
+			pruneErr = errors.Join(pruneErr, err)
+		}
+		bar.finish()
+	}
+
+	// Trash that won't empty is left for next time, and pruning's result stands
+	expired, err := repo.WorktreeManager.PurgeExpiredTrash(opts.DryRun)
+	if err != nil {
+		progress.Or(opts.Progress).Warning(err.Error())
+	}
+	if opts.Porcelain {
+		status := "emptied"
+		if opts.DryRun {
+			status = "would-empty"
+		}
+		for _, entry := range expired {
+			fmt.Fprintf(deps.Output, "%s\t%s\t%s\n", status, entry.Branch, entry.Path)
+		}
+	}
+
+	if len(candidates) == 0 && len(expired) == 0 {
+		presenter.Result("Nothing to collect")
+		return pruneErr
+	}
+	if len(candidates) > 0 {
+		presenter.Result("\n" + describeCollected(result, policies, len(skipped), opts.DryRun))
+	}
+	switch {
+	case len(expired) > 0 && opts.DryRun:
+		presenter.Result(fmt.Sprintf("Dry run: %d worktree(s) past trashDays would be emptied from the trash", len(expired)))
+	case len(expired) > 0:
+		presenter.Result(fmt.Sprintf("Emptied %d worktree(s) past trashDays from the trash", len(expired)))
+	}
+	trashed, deleted := 0, 0
+	for _, outcome := range result.Outcomes {
+		switch {
+		case outcome.Status != git.PrunePruned:
+		case policies[outcome.Branch] == git.GCOversize:
+			deleted++
+		default:
+			trashed++
+		}
+	}
+	if deleted > 0 {
+		presenter.Result(fmt.Sprintf("Deleted %d oversize worktree(s) outright to free their space; sprout undo can't bring them back", deleted))
+	}
+	if trashed > 0 {
+		presenter.Result("Changed your mind? sprout undo brings them back")
+	}
+	return pruneErr
+}
+
+// gcPresenter is where gc presents what it found and how it went: nowhere
+// when porcelain lines stand in for it
+func gcPresenter(opts git.PruneOptions) progress.Presenter {
+	if opts.Porcelain {
+		return progress.Discard
+	}
+	return progress.Or(opts.Progress)
+}
+
+// describeCollected sums up a gc's pruning, counting the worktrees pruned
+// under each policy
+func describeCollected(result git.PruneResult, policies map[string]string, skipped int, dryRun bool) string {
+	if dryRun {
+		return fmt.Sprintf("Dry run: %d worktree(s) would be collected", result.Count(git.PruneWouldPrune))
+	}
+	counts := make(map[string]int)
+	for _, outcome := range result.Outcomes {
+		if outcome.Status == git.PrunePruned {
+			counts[policies[outcome.Branch]]++
+		}
+	}
+	var parts []string
+	for _, policy := range []string{git.GCMerged, git.GCStale, git.GCOversize} {
+		if counts[policy] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[policy], policy))
+		}
+	}
+	pruned := result.Count(git.PrunePruned)
+	line := fmt.Sprintf("Collected %d worktree(s)", pruned)
+	if len(parts) > 0 {
+		line += ": " + strings.Join(parts, ", ")
+	}
+	if failed := result.Count(git.PruneFailed); failed > 0 || skipped > 0 {
+		line += fmt.Sprintf("; %d failed, %d skipped", failed, skipped)
+	}
+	return line
+}
+
+// scheduleGC installs a job running sprout gc for every registered
+// repository every hour, day or week, or removes it given off
+func scheduleGC(every string, deps *Dependencies) error {
+	if deps.Scheduler == nil {
+		return fmt.Errorf("scheduling isn't available here")
+	}
+	presenter := deps.presenter()
+	if every == "off" {
+		where, err := deps.Scheduler.Remove("gc")
+		if err != nil {
+			return err
+		}
+		presenter.Result("Stopped scheduled gc: removed " + where)
+		return nil
+	}
+	if deps.Executable == "" {
+		return fmt.Errorf("couldn't find the sprout executable to schedule")
+	}
+	where, err := deps.Scheduler.Install(schedule.Job{
+		Name:     "gc",
+		Interval: every,
+		Dir:      deps.RepoRoot,
+		Command:  []string{deps.Executable, "gc", "--all-repos", "--yes"},
+	})
+	if err != nil {
+		return err
+	}
+	presenter.Result(fmt.Sprintf("Scheduled sprout gc %s for every registered repository, with %s", every, where))
+	presenter.Result("sprout gc --schedule off stops it")
+	return nil
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sprout/pkg/git"
+)
+
+// gitShimName is what sprout is installed as for git to find it as an
+// external command, so git sprout create foo runs sprout create foo
+const gitShimName = "git-sprout"
+
+// gitEnvironment is what git sets for the commands it runs that would
+// otherwise point every git command sprout runs, including those inside
+// other worktrees, at the checkout git sprout was run from
+var gitEnvironment = []string{"GIT_DIR", "GIT_WORK_TREE", "GIT_COMMON_DIR", "GIT_PREFIX"}
+
+func handleInitCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("init", deps)
+	gitAlias := fs.Bool("git-alias", false, "install git-sprout so sprout also runs as git sprout")
+	dir := fs.String("dir", "", "directory to install git-sprout in (default: the one sprout is in)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*gitAlias || fs.NArg() > 0 {
+		return fmt.Errorf("nothing to set up. Usage: sprout init --git-alias [--dir <dir>]")
+	}
+	return installGitShim(*dir, deps)
+}
+
+// installGitShim links git-sprout in dir to the running sprout, which then
+// knows from its name that git ran it
+func installGitShim(dir string, deps *Dependencies) error {
+	if deps.Executable == "" {
+		return fmt.Errorf("failed to find the running binary, so there's nothing for git-sprout to run")
+	}
+	if dir == "" {
+		dir = filepath.Dir(deps.Executable)
+	}
+	shim := filepath.Join(dir, gitShimName)
+
+	if target, err := os.Readlink(shim); err == nil {
+		if target != deps.Executable {
+			return fmt.Errorf("%s already links to %s; remove it to link it to this sprout", shim, target)
+		}
+		fmt.Fprintf(deps.Output, "%s is already installed\n", shim)
+	} else if _, err := os.Lstat(shim); err == nil {
+		return fmt.Errorf("%s already exists and isn't a link to sprout; remove it first", shim)
+	} else if err := os.Symlink(deps.Executable, shim); err != nil {
+		return fmt.Errorf("failed to install %s: %w", gitShimName, err)
+	} else {
+		fmt.Fprintf(deps.Output, "Installed %s, so git sprout create <branch> works too\n", shim)
+	}
+
+	if !onPath(dir) {
+		fmt.Fprintf(deps.ErrorOutput, "Warning: %s isn't on your PATH, so git won't find %s there\n", dir, gitShimName)
+	}
+	return nil
+}
+
+// onPath is whether dir is one of the directories in PATH
+func onPath(dir string) bool {
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if entry != "" && filepath.Clean(entry) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// enterGitInvocation moves to the checkout git ran git sprout for and clears
+// the environment git set, so sprout finds the repository the way it does
+// when run directly. Git points at the checkout with GIT_DIR and
+// GIT_WORK_TREE for git --git-dir and git --work-tree, and with GIT_PREFIX,
+// relative to the checkout's top, when sprout runs from a shell alias
+func enterGitInvocation() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	dir := cwd
+	if prefix := os.Getenv("GIT_PREFIX"); prefix != "" {
+		dir = filepath.Join(cwd, prefix)
+	}
+	workTree, gitDir := os.Getenv("GIT_WORK_TREE"), os.Getenv("GIT_DIR")
+	for _, name := range gitEnvironment {
+		os.Unsetenv(name)
+	}
+
+	switch {
+	case workTree != "":
+		dir = absoluteFrom(cwd, workTree)
+	case gitDir != "":
+		if dir, err = git.CheckoutForGitDir(absoluteFrom(cwd, gitDir)); err != nil {
+			return err
+		}
+	}
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("can't work in %s: %w", dir, err)
+	}
+	return nil
+}
+
+// absoluteFrom is path made absolute relative to dir
+func absoluteFrom(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sprout/pkg/metadata"
+)
+
+// historyTimeFormat is how sprout history shows when each event happened,
+// in the time zone it happened in
+const historyTimeFormat = "2006-01-02 15:04"
+
+func handleHistoryCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("history", deps)
+	sinceValue := fs.String("since", "", "only events since a date (2006-01-02) or a time ago (90m, 24h, 7d, 2w)")
+	asJSON := fs.Bool("json", false, "print the events as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s. Usage: sprout history [--since when] [--json]", strings.Join(fs.Args(), " "))
+	}
+
+	var since time.Time
+	if *sinceValue != "" {
+		var err error
+		if since, err = parseSince(*sinceValue, time.Now()); err != nil {
+			return err
+		}
+	}
+	events := deps.Metadata.History(since)
+
+	if *asJSON {
+		if events == nil {
+			events = []metadata.HistoryEvent{}
+		}
+		encoder := json.NewEncoder(deps.Output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(events)
+	}
+
+	if len(events) == 0 {
+		fmt.Fprintln(deps.ErrorOutput, "No history recorded yet")
+		return nil
+	}
+	for _, event := range events {
+		fmt.Fprintf(deps.Output, "%s  %s\n", event.Time.Format(historyTimeFormat), describeHistoryEvent(event))
+	}
+	return nil
+}
+
+// parseSince reads sprout history --since: a date, a date and time, or how
+// long ago counted back from now in minutes, hours, days or weeks
+func parseSince(value string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, historyTimeFormat, "2006-01-02"} {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+
+	units := map[byte]time.Duration{'m': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if len(value) > 1 {
+		if unit, ok := units[value[len(value)-1]]; ok {
+			if count, err := strconv.Atoi(value[:len(value)-1]); err == nil && count >= 0 {
+				return now.Add(-time.Duration(count) * unit), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("--since must be a date like 2006-01-02 or a time ago like 24h, 7d or 2w, got %q", value)
+}
+
+// describeHistoryEvent is the line sprout history prints for event, after
+// the time
+func describeHistoryEvent(event metadata.HistoryEvent) string {
+	var line string
+	switch event.Kind {
+	case metadata.HistoryWorktreeCreated:
+		line = fmt.Sprintf("created worktree %s at %s", event.Branch, event.Path)
+	case metadata.HistoryWorktreePruned:
+		line = "pruned worktree " + event.Branch
+	case metadata.HistoryWorktreeRenamed:
+		line = "renamed worktree " + event.Branch
+	case metadata.HistoryWorktreeArchived:
+		line = "archived worktree " + event.Branch
+	case metadata.HistoryWorktreeRestored:
+		line = fmt.Sprintf("restored worktree %s at %s", event.Branch, event.Path)
+	case metadata.HistoryBranchCreated:
+		line = "created branch " + event.Branch
+	case metadata.HistoryCommandRun:
+		line = fmt.Sprintf("ran %s in %s", event.Command, event.Branch)
+	default:
+		line = strings.TrimSpace(event.Kind + " " + event.Branch)
+	}
+	switch {
+	case event.Detail == "":
+	case event.Kind == metadata.HistoryCommandRun:
+		line += " (" + event.Detail + ")"
+	default:
+		line += " " + event.Detail
+	}
+	if event.Issue != "" {
+		line += " [" + event.Issue + "]"
+	}
+	return line
+}
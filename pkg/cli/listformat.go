@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"sprout/pkg/github"
+)
+
+// How sprout list --sort orders worktrees; without it they're listed in the
+// order git reports them
+const (
+	listSortAge    = "age"    // most recent commit first
+	listSortStatus = "status" // dirty, then active, then merged
+	listSortBranch = "branch" // alphabetically by name
+)
+
+// Which worktrees sprout list --status keeps
+const (
+	listStatusMerged = "merged" // the PR has been merged
+	listStatusActive = "active" // not merged yet
+	listStatusDirty  = "dirty"  // has uncommitted changes
+)
+
+// Status icons shown ahead of each worktree in the sprout list table
+var (
+	mergedIcon = lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Render("✓")
+	activeIcon = lipgloss.NewStyle().Foreground(lipgloss.Color("69")).Render("●")
+	dirtyIcon  = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render("✗")
+	lockedIcon = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("⚑")
+	pinnedIcon = "📌"
+)
+
+// CI icons shown in the CI column of the sprout list table and beside open
+// PRs in sprout today
+var ciIcons = map[string]string{
+	github.CIPassing: lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Render("✓"),
+	github.CIFailing: lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render("✗"),
+	github.CIPending: lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("●"),
+}
+
+// ciIcon is the icon for a CI status, or - when no checks have run
+func ciIcon(status string) string {
+	if icon, ok := ciIcons[status]; ok {
+		return icon
+	}
+	return "-"
+}
+
+// validateListOptions checks the values given to sprout list --sort and --status
+func validateListOptions(sortBy, status string) error {
+	switch sortBy {
+	case "", listSortAge, listSortStatus, listSortBranch:
+	default:
+		return fmt.Errorf("--sort must be %s, %s or %s, got %q", listSortAge, listSortStatus, listSortBranch, sortBy)
+	}
+	switch status {
+	case "", listStatusMerged, listStatusActive, listStatusDirty:
+	default:
+		return fmt.Errorf("--status must be %s, %s or %s, got %q", listStatusMerged, listStatusActive, listStatusDirty, status)
+	}
+	return nil
+}
+
+// merged is whether the worktree's branch has been merged
+func (l listedWorktree) merged() bool {
+	return l.worktree.Merged || l.worktree.PRStatus == "Merged"
+}
+
+// markDirty records which worktrees have uncommitted changes, asking each
+// one's repository in turn
+func markDirty(listed []listedWorktree) {
+	for i := range listed {
+		if listed[i].worktree.Path != "" {
+			listed[i].dirty = listed[i].repo.WorktreeManager.HasUncommittedChanges(listed[i].worktree.Path)
+		}
+	}
+}
+
+// filterListed keeps the worktrees matching a sprout list --status value; ""
+// keeps them all
+func filterListed(listed []listedWorktree, status string) []listedWorktree {
+	if status == "" {
+		return listed
+	}
+	var kept []listedWorktree
+	for _, l := range listed {
+		switch {
+		case status == listStatusMerged && l.merged(),
+			status == listStatusActive && !l.merged(),
+			status == listStatusDirty && l.dirty:
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// sortListed orders worktrees for a sprout list --sort value, keeping git's
+// order among equals; "" leaves them as they are
+func sortListed(listed []listedWorktree, sortBy string) {
+	switch sortBy {
+	case listSortAge:
+		// Worktrees with no known commit time sink to the bottom
+		sort.SliceStable(listed, func(i, j int) bool {
+			a, b := listed[i].worktree.UpdatedAt, listed[j].worktree.UpdatedAt
+			if a.IsZero() != b.IsZero() {
+				return b.IsZero()
+			}
+			return a.After(b)
+		})
+	case listSortStatus:
+		sort.SliceStable(listed, func(i, j int) bool {
+			return listed[i].statusRank() < listed[j].statusRank()
+		})
+	case listSortBranch:
+		sort.SliceStable(listed, func(i, j int) bool {
+			return listed[i].worktree.Name() < listed[j].worktree.Name()
+		})
+	}
+}
+
+// statusRank puts the worktrees that need attention first: uncommitted work,
+// then unmerged branches, then merged ones ready to prune
+func (l listedWorktree) statusRank() int {
+	switch {
+	case l.dirty:
+		return 0
+	case !l.merged():
+		return 1
+	}
+	return 2
+}
+
+// statusIcons is the compact status column of the sprout list table: ✓ for
+// merged or ● for active, then ✗ when there are uncommitted changes, and 📌
+// when the worktree is pinned or ⚑ when it's otherwise locked
+func (l listedWorktree) statusIcons() string {
+	icons := activeIcon
+	if l.merged() {
+		icons = mergedIcon
+	}
+	if l.dirty {
+		icons += dirtyIcon
+	}
+	switch {
+	case l.worktree.Pinned:
+		icons += pinnedIcon
+	case l.worktree.Locked:
+		icons += lockedIcon
+	}
+	return icons
+}
+
+// maxListedNote is how much of a note the sprout list table shows, so a long
+// one doesn't push the table past the terminal
+const maxListedNote = 40
+
+// listedNote is the note column of the sprout list table, cut short with an
+// ellipsis when it's long, or - when there's none
+func listedNote(note string) string {
+	if note == "" {
+		return "-"
+	}
+	if runes := []rune(note); len(runes) > maxListedNote {
+		return string(runes[:maxListedNote-1]) + "…"
+	}
+	return note
+}
+
+// listedName is the branch column of the sprout list table. Locked and
+// pinned are left out of the states in brackets since the icons already say so
+func (l listedWorktree) listedName() string {
+	name := l.worktree.Name()
+	var states []string
+	for _, state := range l.worktree.States() {
+		if state != "locked" && state != "pinned" {
+			states = append(states, state)
+		}
+	}
+	if len(states) > 0 {
+		name += " (" + strings.Join(states, ", ") + ")"
+	}
+	return name
+}
@@ -0,0 +1,693 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sprout/pkg/config"
+	"sprout/pkg/detach"
+	"sprout/pkg/git"
+	"sprout/pkg/github"
+	"sprout/pkg/issues"
+	"sprout/pkg/linear"
+	"sprout/pkg/progress"
+	"sprout/pkg/release"
+	"sprout/pkg/schedule"
+	"sprout/pkg/sprouttest"
+)
+
+// MockWorktreeManager implements git.WorktreeManagerInterface for testing.
+// Worktrees live in the embedded fake, at /mock/path for new ones, and the
+// methods overridden here record what they were asked or play back what a
+// scenario set up
+type MockWorktreeManager struct {
+	*sprouttest.FakeWorktreeRepository
+	PruneOptions   git.PruneOptions
+	PruneThreshold int64
+	SparseApplied  map[string][]string
+	CreateOptions  git.CreateOptions
+	Created        []string // branches CreateWorktreeWithOptions was asked for
+	PrunedMerged   bool
+	Deleted        []string // branches pruned outright rather than to the trash
+	RepairReport   git.RepairReport
+	Repaired       bool
+	Archive        git.Archive           // what ArchiveWorktree and ExportWorktree report saving
+	Trash          []git.TrashedWorktree // what the last prune left for UndoPrune
+	Dirty          []string              // worktree paths with uncommitted changes
+	HookProblems   []string              // what CheckGitHooks reports
+	PruneFailures  map[string]string     // why pruning each of these branches with others fails
+	TimersStarted  []string              // branches StartTimer was called for
+	PullRequests   []github.PullRequest  // what CheckoutPR can find, by number, branch or an issue in the title
+	Stacked        git.Stack             // what Stack reports for any branch in it
+	Restacked      []string              // branches Restack rebased, in order
+	RestackFailure string                // branch whose rebase conflicts
+	GCPolicy       git.GCPolicy          // what GCCandidates was last asked to apply
+	ExpiredTrash   []git.TrashedWorktree // what PurgeExpiredTrash finds past trashDays
+	Unreachable    bool                  // origin can't be reached without a prompt, so fetching the default branch fails
+	BranchHere     string                // the branch checked out where sprout runs, for Where with no branch
+}
+
+// NewMockWorktreeManager mocks a repository at /mock/repo with worktrees
+func NewMockWorktreeManager(worktrees []git.Worktree) *MockWorktreeManager {
+	return &MockWorktreeManager{FakeWorktreeRepository: &sprouttest.FakeWorktreeRepository{
+		Root:         "/mock/repo",
+		WorktreesDir: "/mock/path",
+		Worktrees:    worktrees,
+		Statuses:     &sprouttest.Statuses{},
+	}}
+}
+
+func (m *MockWorktreeManager) CreateWorktree(branchName string) (string, error) {
+	return m.CreateWorktreeWithOptions(branchName, git.CreateOptions{})
+}
+
+func (m *MockWorktreeManager) CreateWorktreeWithOptions(branchName string, opts git.CreateOptions) (string, error) {
+	m.CreateOptions = opts
+	m.defaultBase(opts)
+	m.Created = append(m.Created, branchName)
+	return m.FakeWorktreeRepository.CreateWorktreeWithOptions(branchName, opts)
+}
+
+func (m *MockWorktreeManager) PlanWorktree(branchName string, opts git.CreateOptions) (git.CreatePlan, error) {
+	m.CreateOptions = opts
+	plan, err := m.FakeWorktreeRepository.PlanWorktree(branchName, opts)
+	if err == nil && opts.BaseBranch == "" {
+		plan.Base = m.defaultBase(opts)
+	}
+	return plan, err
+}
+
+// defaultBase is the base a worktree with no base branch starts from,
+// warning as creating does when the default branch would be fetched from an
+// origin that can't be reached without a prompt, so the local one is used
+func (m *MockWorktreeManager) defaultBase(opts git.CreateOptions) string {
+	if m.Unreachable && opts.BaseBranch == "" && !opts.NoFetch {
+		progress.Or(opts.Progress).Warning(fmt.Sprintf("%v: git@github.com:team/app.git (Permission denied (publickey).). Load the key for this host into your SSH agent with ssh-add (ssh-add -l lists those loaded); starting from the refs already fetched", git.ErrRemoteAccess))
+		return "main"
+	}
+	return "origin/main"
+}
+
+func (m *MockWorktreeManager) PruneWorktree(branchName string, opts git.PruneOptions) error {
+	m.PruneOptions = opts
+	return m.FakeWorktreeRepository.PruneWorktree(branchName, opts)
+}
+
+func (m *MockWorktreeManager) PruneAllMerged(opts git.PruneOptions) (git.PruneResult, error) {
+	merged, err := m.MergedWorktrees()
+	if err != nil {
+		return git.PruneResult{}, err
+	}
+	return m.PruneMergedWorktrees(merged, opts)
+}
+
+// MergedWorktrees takes a worktree as merged by its PR, as the table of
+// worktrees a scenario sets up gives it
+func (m *MockWorktreeManager) MergedWorktrees() ([]git.Worktree, error) {
+	listed, err := m.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	var merged []git.Worktree
+	for _, wt := range listed {
+		if wt.PRStatus == "Merged" && !wt.Locked && !wt.Pinned {
+			merged = append(merged, wt)
+		}
+	}
+	return merged, nil
+}
+
+// PruneMergedWorktrees fails to prune the branches in PruneFailures and
+// leaves the rest to the fake
+func (m *MockWorktreeManager) PruneMergedWorktrees(worktrees []git.Worktree, opts git.PruneOptions) (git.PruneResult, error) {
+	m.PrunedMerged = true
+	m.PruneOptions = opts
+	for _, wt := range worktrees {
+		if _, fails := m.PruneFailures[wt.Branch]; opts.Permanent && !opts.DryRun && !fails {
+			m.Deleted = append(m.Deleted, wt.Branch)
+		}
+	}
+	var prunable []git.Worktree
+	for _, wt := range worktrees {
+		if _, fails := m.PruneFailures[wt.Branch]; !fails {
+			prunable = append(prunable, wt)
+		}
+	}
+	fakeOpts := opts
+	fakeOpts.OnProgress = nil
+	pruned, err := m.FakeWorktreeRepository.PruneMergedWorktrees(prunable, fakeOpts)
+	if err != nil {
+		return pruned, err
+	}
+	var result git.PruneResult
+	for _, wt := range worktrees {
+		var outcome git.PruneOutcome
+		if reason, fails := m.PruneFailures[wt.Branch]; fails {
+			outcome = git.PruneOutcome{Branch: wt.Branch, Path: wt.Path, Status: git.PruneFailed, Reason: reason}
+		} else {
+			outcome, pruned.Outcomes = pruned.Outcomes[0], pruned.Outcomes[1:]
+		}
+		result.Outcomes = append(result.Outcomes, outcome)
+		if opts.OnProgress != nil {
+			opts.OnProgress(outcome)
+		}
+	}
+	if failed := result.Failed(); len(failed) > 0 {
+		return result, fmt.Errorf("failed to prune %d of %d worktree(s)", len(failed), len(worktrees))
+	}
+	return result, nil
+}
+
+func (m *MockWorktreeManager) HasUncommittedChanges(worktreePath string) bool {
+	return slices.Contains(m.Dirty, worktreePath)
+}
+
+func (m *MockWorktreeManager) ApplySparseCheckout(branchName string, directories []string) error {
+	if m.SparseApplied == nil {
+		m.SparseApplied = make(map[string][]string)
+	}
+	m.SparseApplied[branchName] = directories
+	return nil
+}
+
+func (m *MockWorktreeManager) PruneLargerThan(threshold int64, opts git.PruneOptions) error {
+	m.PruneThreshold = threshold
+	m.PruneOptions = opts
+	return nil
+}
+
+func (m *MockWorktreeManager) Repair() (*git.RepairReport, error) {
+	m.Repaired = true
+	report := m.RepairReport
+	return &report, nil
+}
+
+func (m *MockWorktreeManager) ArchiveWorktree(branchName string) (*git.Archive, error) {
+	return m.withArchive(m.FakeWorktreeRepository.ArchiveWorktree(branchName))
+}
+
+func (m *MockWorktreeManager) ExportWorktree(branchName, path string) (*git.Archive, error) {
+	return m.withArchive(m.FakeWorktreeRepository.ExportWorktree(branchName, path))
+}
+
+func (m *MockWorktreeManager) ImportWorktree(path string) (*git.Archive, string, error) {
+	if !strings.HasSuffix(path, git.HandoffExtension) {
+		return nil, "", fmt.Errorf("%s isn't a sprout export", path)
+	}
+	archive, worktreePath, err := m.FakeWorktreeRepository.ImportWorktree(path)
+	archive, err = m.withArchive(archive, err)
+	return archive, worktreePath, err
+}
+
+// withArchive reports what Archive says was saved, in the fake's archive
+func (m *MockWorktreeManager) withArchive(archive *git.Archive, err error) (*git.Archive, error) {
+	if err != nil {
+		return nil, err
+	}
+	saved := m.Archive
+	saved.Branch, saved.Head, saved.Dir = archive.Branch, archive.Head, archive.Dir
+	return &saved, nil
+}
+
+func (m *MockWorktreeManager) CheckGitHooks() ([]string, error) {
+	return m.HookProblems, nil
+}
+
+func (m *MockWorktreeManager) StartTimer(branchName, worktreePath string) error {
+	m.TimersStarted = append(m.TimersStarted, branchName)
+	return nil
+}
+
+// CheckoutPR finds ref among PullRequests and has the fake reuse the
+// worktree for its branch or add one
+func (m *MockWorktreeManager) CheckoutPR(ref string, opts git.CreateOptions) (git.PRCheckout, error) {
+	m.CreateOptions = opts
+	number, _ := github.ParsePullRequestNumber(ref)
+	for _, pr := range m.PullRequests {
+		if pr.Number != number && pr.HeadBranch != ref && !strings.Contains(strings.ToUpper(pr.Title), strings.ToUpper(ref)) {
+			continue
+		}
+		if pr.State == "Merged" {
+			return git.PRCheckout{PullRequest: pr}, fmt.Errorf("PR #%d was merged already; sprout create starts something new", pr.Number)
+		}
+		m.Statuses.SetPR(pr.HeadBranch, pr.State)
+		checkout, err := m.FakeWorktreeRepository.CheckoutPR(pr.HeadBranch, opts)
+		checkout.PullRequest = pr
+		return checkout, err
+	}
+	return git.PRCheckout{}, fmt.Errorf("%w for %s", github.ErrNoPullRequest, ref)
+}
+
+func (m *MockWorktreeManager) Stack(branchName string) (git.Stack, error) {
+	if branchName == "" {
+		branchName = m.Stacked.Branch
+	}
+	for _, entry := range m.Stacked.Branches {
+		if entry.Branch == branchName {
+			stack := m.Stacked
+			stack.Branch = branchName
+			stack.Branches = slices.Clone(stack.Branches)
+			return stack, nil
+		}
+	}
+	return git.Stack{}, fmt.Errorf("branch %s does not exist", branchName)
+}
+
+func (m *MockWorktreeManager) Restack(branchName string, presenter progress.Presenter) (git.Stack, error) {
+	stack, err := m.Stack(branchName)
+	if err != nil {
+		return stack, err
+	}
+	for i, entry := range stack.Branches {
+		if entry.Depth == 0 {
+			continue
+		}
+		if slices.Contains(m.Dirty, entry.Path) {
+			return stack, fmt.Errorf("%s has uncommitted changes; commit or stash them before restacking", entry.Branch)
+		}
+		presenter.StepStarted(fmt.Sprintf("Rebasing %s onto %s", entry.Branch, entry.Parent))
+		if entry.Branch == m.RestackFailure {
+			return stack, fmt.Errorf("rebasing %s onto %s conflicts, so it and the branches above it were left as they were", entry.Branch, entry.Parent)
+		}
+		m.Restacked = append(m.Restacked, entry.Branch)
+		stack.Branches[i].Behind = 0
+	}
+	return stack, nil
+}
+
+// UndoPrune brings back what Trash says the last prune left, or else what
+// the fake pruned
+func (m *MockWorktreeManager) UndoPrune() ([]git.TrashedWorktree, error) {
+	if len(m.Trash) == 0 {
+		return m.FakeWorktreeRepository.UndoPrune()
+	}
+	restored := m.Trash
+	m.Trash = nil
+	return restored, nil
+}
+
+func (m *MockWorktreeManager) GCCandidates(policy git.GCPolicy) ([]git.GCCandidate, error) {
+	m.GCPolicy = policy
+	return m.FakeWorktreeRepository.GCCandidates(policy)
+}
+
+func (m *MockWorktreeManager) PurgeExpiredTrash(dryRun bool) ([]git.TrashedWorktree, error) {
+	expired := m.ExpiredTrash
+	if !dryRun {
+		m.ExpiredTrash = nil
+	}
+	return expired, nil
+}
+
+// Where defaults to BranchHere, and takes a prunable worktree as missing
+func (m *MockWorktreeManager) Where(branchName string) (git.Location, error) {
+	if branchName == "" {
+		branchName = m.BranchHere
+	}
+	if branchName == "" {
+		return git.Location{}, fmt.Errorf("not on a branch; name the branch to use")
+	}
+	location, err := m.FakeWorktreeRepository.Where(branchName)
+	if err != nil {
+		return location, err
+	}
+	if i := slices.IndexFunc(m.Worktrees, func(wt git.Worktree) bool { return wt.Branch == location.Branch }); i >= 0 && m.Worktrees[i].Prunable {
+		location.Path, location.Exists = filepath.Join(location.WorktreesRoot, location.Branch), false
+	}
+	return location, nil
+}
+
+// MockScheduler implements schedule.InstallerInterface for testing
+type MockScheduler struct {
+	Installed []schedule.Job
+	Removed   []string
+}
+
+func (m *MockScheduler) Install(job schedule.Job) (string, error) {
+	if !slices.Contains(schedule.Intervals, job.Interval) {
+		return "", fmt.Errorf("invalid interval %q (supported: %s)", job.Interval, strings.Join(schedule.Intervals, ", "))
+	}
+	m.Installed = append(m.Installed, job)
+	return "the systemd user timer sprout-" + job.Name + ".timer", nil
+}
+
+func (m *MockScheduler) Remove(name string) (string, error) {
+	m.Removed = append(m.Removed, name)
+	return "the systemd user timer sprout-" + name + ".timer", nil
+}
+
+// MockTmuxClient implements tmux.ClientInterface for testing
+type MockTmuxClient struct {
+	Sessions map[string]bool
+	ListErr  error // returned by LiveSessions, as when tmux isn't installed
+	Opened   []string
+	Started  []string // sessions started without attaching
+}
+
+func (m *MockTmuxClient) Available() bool {
+	return true
+}
+
+func (m *MockTmuxClient) LiveSessions() (map[string]bool, error) {
+	if m.ListErr != nil {
+		return nil, m.ListErr
+	}
+	if m.Sessions == nil {
+		return map[string]bool{}, nil
+	}
+	return m.Sessions, nil
+}
+
+func (m *MockTmuxClient) Open(session, dir string, command []string) error {
+	m.Opened = append(m.Opened, strings.TrimSpace(session+" "+dir+" "+strings.Join(command, " ")))
+	return nil
+}
+
+func (m *MockTmuxClient) Start(session, dir string, command []string) error {
+	m.Started = append(m.Started, strings.TrimSpace(session+" "+dir+" "+strings.Join(command, " ")))
+	return nil
+}
+
+// MockEditorLauncher implements editor.LauncherInterface for testing
+type MockEditorLauncher struct {
+	InstalledEditors []string
+	Opened           []string
+}
+
+func (m *MockEditorLauncher) Open(name, path string, reuseWindow bool) error {
+	opened := name + " " + path
+	if reuseWindow {
+		opened += " (reuse window)"
+	}
+	m.Opened = append(m.Opened, opened)
+	return nil
+}
+
+func (m *MockEditorLauncher) Installed() []string {
+	return m.InstalledEditors
+}
+
+// MockCloner implements git.ClonerInterface for testing
+type MockCloner struct {
+	Cloned []string
+}
+
+func (m *MockCloner) Clone(url, dir string) (*git.CloneResult, error) {
+	m.Cloned = append(m.Cloned, url+" "+dir)
+	root := "/mock/src/" + dir
+	return &git.CloneResult{
+		Root:            root,
+		BareDir:         root + "/" + git.BareDirName,
+		PrimaryWorktree: root + "/main",
+		DefaultBranch:   "main",
+	}, nil
+}
+
+// MockMigrator implements git.MigratorInterface for testing
+type MockMigrator struct {
+	FailAt   int // 1-based step that fails, 0 for none
+	Migrated bool
+}
+
+func (m *MockMigrator) PlanMigration(repoRoot string) (*git.MigrationPlan, error) {
+	return &git.MigrationPlan{
+		Root:         repoRoot,
+		Branch:       "main",
+		WorktreePath: repoRoot + "/main",
+		Steps: []string{
+			"Move the working files aside",
+			"Move .git to .bare",
+			"Add a worktree for main",
+		},
+	}, nil
+}
+
+func (m *MockMigrator) Migrate(plan *git.MigrationPlan, progress func(step string)) error {
+	for i, step := range plan.Steps {
+		if i+1 == m.FailAt {
+			return fmt.Errorf("%s: disk full; all changes were rolled back", step)
+		}
+		progress(step)
+	}
+	m.Migrated = true
+	return nil
+}
+
+// MockUpdater implements release.UpdaterInterface for testing
+type MockUpdater struct {
+	Release   *release.Release
+	Installed string // version installed by Install
+}
+
+func (m *MockUpdater) Latest() (*release.Release, error) {
+	if m.Release == nil {
+		return nil, fmt.Errorf("failed to check for releases: no network")
+	}
+	return m.Release, nil
+}
+
+func (m *MockUpdater) Install(rel *release.Release, executable string) error {
+	m.Installed = rel.Version
+	return nil
+}
+
+// MockLinearAuth implements linear.AuthenticatorInterface for testing
+type MockLinearAuth struct {
+	Token    *linear.Token // the stored sign-in, nil when signed out
+	LoginErr error
+}
+
+func (m *MockLinearAuth) Login(output io.Writer) (*linear.Token, error) {
+	if m.LoginErr != nil {
+		return nil, m.LoginErr
+	}
+	m.Token = &linear.Token{AccessToken: "access", RefreshToken: "refresh"}
+	return m.Token, nil
+}
+
+func (m *MockLinearAuth) Logout() error {
+	m.Token = nil
+	return nil
+}
+
+func (m *MockLinearAuth) StoredToken() (*linear.Token, error) {
+	return m.Token, nil
+}
+
+// MockGitHubTokens implements github.TokenStoreInterface for testing
+type MockGitHubTokens struct {
+	Stored string // "" when no token is stored
+}
+
+func (m *MockGitHubTokens) Token() (string, error) {
+	return m.Stored, nil
+}
+
+func (m *MockGitHubTokens) SaveToken(token string) error {
+	m.Stored = token
+	return nil
+}
+
+func (m *MockGitHubTokens) DeleteToken() error {
+	m.Stored = ""
+	return nil
+}
+
+// MockIssueLinks implements issues.TitleFetcher for testing, with the title
+// of each issue by its URL
+type MockIssueLinks struct {
+	Titles map[string]string
+}
+
+func (m *MockIssueLinks) Title(link issues.Link) (string, error) {
+	title, ok := m.Titles[link.URL.String()]
+	if !ok {
+		return "", fmt.Errorf("failed to fetch the title of %s: %s answered with status 404", link.URL, link.Tracker)
+	}
+	return title, nil
+}
+
+// MockTools implements version.ToolsInterface for testing; an empty version means the tool is missing
+type MockTools struct {
+	Git string
+	GH  string
+}
+
+func (m *MockTools) GitVersion() (string, error) {
+	if m.Git == "" {
+		return "", fmt.Errorf("git not found")
+	}
+	return m.Git, nil
+}
+
+func (m *MockTools) GHVersion() (string, error) {
+	if m.GH == "" {
+		return "", fmt.Errorf("gh not found")
+	}
+	return m.GH, nil
+}
+
+// MockDetacher stands in for starting sprout create --detach's command in
+// the background, noting each command and the directory it would run in
+type MockDetacher struct {
+	Started []string
+}
+
+func (m *MockDetacher) Start(dir string, command []string, logPath string) (detach.Process, error) {
+	m.Started = append(m.Started, strings.Join(command, " ")+" in "+dir)
+	return detach.Process{PID: 4242}, nil
+}
+
+// MockCommandRunner stands in for sprout exec's command runner, echoing the
+// command it was given and failing with the configured exit code per directory
+type MockCommandRunner struct {
+	ExitCodes map[string]int
+}
+
+// mockExitError carries an exit code the way *exec.ExitError does
+type mockExitError int
+
+func (e mockExitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+func (e mockExitError) ExitCode() int { return int(e) }
+
+func (m *MockCommandRunner) Run(dir string, command []string, stdout, stderr io.Writer) error {
+	fmt.Fprintf(stdout, "%s in %s\n", strings.Join(command, " "), dir)
+	if code := m.ExitCodes[dir]; code != 0 {
+		fmt.Fprintf(stderr, "failed with %d\n", code)
+		return mockExitError(code)
+	}
+	return nil
+}
+
+// MockConfigLoader implements config.LoaderInterface for testing
+type MockConfigLoader struct {
+	Config *config.Config
+}
+
+func (m *MockConfigLoader) GetConfig() (*config.Config, error) {
+	return m.Config, nil
+}
+
+// MockLinearClient implements linear.LinearClientInterface for testing
+type MockLinearClient struct {
+	CurrentUser     *linear.User
+	AssignedIssues  []linear.Issue
+	ConnectionError error
+	CreatedSubtasks []string
+	SubtaskErrors   map[string]error // by title
+	IssueStates     map[string]linear.State
+}
+
+func (m *MockLinearClient) GetCurrentUser() (*linear.User, error) {
+	if m.ConnectionError != nil {
+		return nil, m.ConnectionError
+	}
+	return m.CurrentUser, nil
+}
+
+func (m *MockLinearClient) GetAssignedIssues() ([]linear.Issue, error) {
+	if m.ConnectionError != nil {
+		return nil, m.ConnectionError
+	}
+	return m.AssignedIssues, nil
+}
+
+func (m *MockLinearClient) GetIssueChildren(issueID string) ([]linear.Issue, error) {
+	return []linear.Issue{}, nil
+}
+
+func (m *MockLinearClient) GetChildrenOfIssues(issueIDs []string) (map[string][]linear.Issue, error) {
+	children := make(map[string][]linear.Issue, len(issueIDs))
+	for _, issueID := range issueIDs {
+		children[issueID] = []linear.Issue{}
+	}
+	return children, nil
+}
+
+func (m *MockLinearClient) GetIssue(identifier string) (*linear.Issue, error) {
+	if m.ConnectionError != nil {
+		return nil, m.ConnectionError
+	}
+	for i := range m.AssignedIssues {
+		if strings.EqualFold(m.AssignedIssues[i].Identifier, identifier) {
+			return &m.AssignedIssues[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockLinearClient) CreateSubtask(parentID, title string) (*linear.Issue, error) {
+	return m.CreateSubtaskWithOptions(parentID, title, linear.SubtaskOptions{})
+}
+
+func (m *MockLinearClient) CreateSubtaskWithOptions(parentID, title string, opts linear.SubtaskOptions) (*linear.Issue, error) {
+	if m.ConnectionError != nil {
+		return nil, m.ConnectionError
+	}
+	if err := m.SubtaskErrors[title]; err != nil {
+		return nil, err
+	}
+	m.CreatedSubtasks = append(m.CreatedSubtasks, parentID+": "+title)
+	identifier := fmt.Sprintf("TEST-%d", 100+len(m.CreatedSubtasks))
+	return &linear.Issue{
+		ID:         identifier,
+		Identifier: identifier,
+		Title:      title,
+		URL:        "https://linear.app/test/issue/" + identifier,
+	}, nil
+}
+
+func (m *MockLinearClient) UnassignIssue(issueID string) error {
+	return nil
+}
+
+func (m *MockLinearClient) AssignIssueToMe(issueID string) error {
+	return nil
+}
+
+func (m *MockLinearClient) MarkIssueDone(issueID string) error {
+	return nil
+}
+
+func (m *MockLinearClient) GetWorkflowStates(issueID string) ([]linear.State, error) {
+	return nil, nil
+}
+
+func (m *MockLinearClient) UpdateIssueState(issueID, stateID string) error {
+	return nil
+}
+
+func (m *MockLinearClient) GetIssueStates(identifiers []string) (map[string]linear.State, error) {
+	states := make(map[string]linear.State)
+	for _, identifier := range identifiers {
+		if state, ok := m.IssueStates[identifier]; ok {
+			states[identifier] = state
+		}
+	}
+	return states, nil
+}
+
+func (m *MockLinearClient) TestConnection() error {
+	return m.ConnectionError
+}
+
+// MockConfigPathProvider provides configurable config path and file status for testing
+type MockConfigPathProvider struct {
+	ConfigPath string
+	FileExists bool
+	PathError  error
+}
+
+func (p *MockConfigPathProvider) GetConfigPath() (string, error) {
+	if p.PathError != nil {
+		return "", p.PathError
+	}
+	return p.ConfigPath, nil
+}
+
+func (p *MockConfigPathProvider) ConfigFileExists() bool {
+	return p.FileExists
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"sprout/pkg/config"
+	"sprout/pkg/linear"
+)
+
+// handlePickCommandWithDeps prints the assigned issues a line each, for a
+// fuzzy finder such as fzf to choose from and sprout create - to take back.
+// The fields are the identifier, status, title and branch, separated by tabs;
+// the branch comes last so it can be found however many fzf shows:
+//
+//	sprout pick | fzf --delimiter '\t' --with-nth 1..3 | sprout create -
+func handlePickCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("pick", deps)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s. Usage: sprout pick", strings.Join(fs.Args(), " "))
+	}
+	if deps.LinearClient == nil {
+		return fmt.Errorf("Linear API key is not configured. Add linearApiKey to %s", configPathForDisplay(deps))
+	}
+
+	cfg, err := deps.ConfigLoader.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	assigned, err := deps.LinearClient.GetAssignedIssues()
+	if err != nil {
+		return fmt.Errorf("failed to load issues from Linear: %w", err)
+	}
+	for _, issue := range assigned {
+		fmt.Fprintln(deps.Output, strings.Join([]string{
+			issue.Identifier,
+			pickField(issue.State.Name),
+			pickField(issue.Title),
+			pickBranch(issue, cfg.Templates),
+		}, "\t"))
+	}
+	return nil
+}
+
+// pickBranch is the branch the TUI would create for issue, with the prefix
+// of any template its labels match
+func pickBranch(issue linear.Issue, templates config.Templates) string {
+	branch := issue.GetBranchName()
+	if prefix, _, ok := templates.Match(branch, issue.LabelNames()); ok {
+		branch = config.ApplyTemplatePrefix(prefix, branch)
+	}
+	return branch
+}
+
+// pickField keeps a field on its line and out of the others
+func pickField(value string) string {
+	return strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return r == '\t' || r == '\n' || r == '\r'
+	}), " ")
+}
+
+// pickedBranch is the branch in a line sprout pick printed, or the line
+// itself when it's just a branch name
+func pickedBranch(line string) string {
+	fields := strings.Split(line, "\t")
+	return strings.TrimSpace(fields[len(fields)-1])
+}
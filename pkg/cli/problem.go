@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"sprout/pkg/problem"
+)
+
+// printError writes err to w as the TUI's result screen shows it: the title
+// after Error:, the details indented beneath it and then what to try next
+func printError(w io.Writer, err error) {
+	p := problem.Describe(err)
+	fmt.Fprintf(w, "Error: %s\n", p.Title)
+	for _, detail := range p.Details {
+		fmt.Fprintf(w, "  %s\n", detail)
+	}
+	for _, hint := range p.Hints {
+		fmt.Fprintf(w, "Hint: %s\n", hint)
+	}
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"sprout/pkg/git"
+	"sprout/pkg/progress"
+)
+
+// pruneBarWidth is how many cells the prune progress bar has
+const pruneBarWidth = 20
+
+// pruneMergedIn prunes one repository's merged worktrees and says how each
+// went. skipped are ones already left out, such as those with uncommitted
+// changes the user chose to keep; listed is whether the worktrees were
+// already shown while asking to go ahead
+func pruneMergedIn(repo RepoTarget, worktrees []git.Worktree, skipped []git.PruneOutcome, listed bool, opts git.PruneOptions, deps *Dependencies) error {
+	presenter := progress.Or(opts.Progress)
+	if opts.Porcelain {
+		presenter = progress.Discard
+	}
+	if len(worktrees) == 0 && len(skipped) == 0 {
+		presenter.Result("No merged worktrees found to prune")
+		return nil
+	}
+	if !listed {
+		listing := []string{fmt.Sprintf("Found %d merged worktree(s) to prune:", len(worktrees))}
+		for _, wt := range worktrees {
+			listing = append(listing, "  - "+wt.Branch)
+		}
+		presenter.Result(strings.Join(listing, "\n") + "\n")
+	}
+
+	bar := newPruneProgress(len(worktrees)+len(skipped), opts, deps)
+	for _, outcome := range skipped {
+		bar.report(outcome)
+	}
+	opts.OnProgress = bar.report
+	result, err := repo.WorktreeManager.PruneMergedWorktrees(worktrees, opts)
+	bar.finish()
+
+	result.Outcomes = append(skipped, result.Outcomes...)
+	pruned, total := result.Count(git.PrunePruned), len(result.Outcomes)
+	switch {
+	case opts.DryRun:
+		presenter.Result(fmt.Sprintf("\nDry run: %d merged worktree(s) would be pruned", result.Count(git.PruneWouldPrune)))
+	case pruned == total:
+		presenter.Result(fmt.Sprintf("\nPruned %d merged worktree(s)", pruned))
+	default:
+		presenter.Result(fmt.Sprintf("\nPruned %d of %d merged worktree(s): %d failed, %d skipped",
+			pruned, total, result.Count(git.PruneFailed), result.Count(git.PruneSkipped)))
+	}
+	if pruned > 0 {
+		presenter.Result("Changed your mind? sprout undo brings them back")
+	}
+	return err
+}
+
+// pruneProgress shows how far through pruning several worktrees sprout is:
+// on a terminal a bar redrawn in place under a line per worktree that didn't
+// simply go, otherwise a numbered line per worktree. Porcelain prunes get
+// their result lines instead. Warnings go to the prune's presenter, once the
+// bar is out of their way
+type pruneProgress struct {
+	total     int
+	done      int
+	opts      git.PruneOptions
+	deps      *Dependencies
+	presenter progress.Presenter
+	terminal  bool
+}
+
+func newPruneProgress(total int, opts git.PruneOptions, deps *Dependencies) *pruneProgress {
+	p := &pruneProgress{total: total, opts: opts, deps: deps, presenter: progress.Or(opts.Progress), terminal: isTerminalWriter(deps.ErrorOutput)}
+	if p.terminal && !opts.Porcelain {
+		fmt.Fprint(deps.ErrorOutput, p.bar())
+	}
+	return p
+}
+
+// report takes in how one worktree went
+func (p *pruneProgress) report(outcome git.PruneOutcome) {
+	p.done++
+	out := p.deps.ErrorOutput
+	if p.terminal && !p.opts.Porcelain {
+		fmt.Fprint(out, "\r\033[K")
+	}
+	for _, warning := range outcome.Warnings {
+		p.presenter.Warning(outcome.Branch + ": " + warning)
+	}
+
+	switch {
+	case p.opts.Porcelain:
+		if outcome.Status == git.PruneFailed {
+			fmt.Fprintf(out, "Failed to prune %s: %s\n", outcome.Branch, outcome.Reason)
+		} else {
+			fmt.Fprintf(p.deps.Output, "%s\t%s\t%s\n", outcome.Status, outcome.Branch, outcome.Path)
+		}
+	case p.terminal:
+		if outcome.Status != git.PrunePruned {
+			fmt.Fprintln(out, describePruneOutcome(outcome))
+		}
+		fmt.Fprint(out, p.bar())
+	default:
+		fmt.Fprintf(out, "[%d/%d] %s\n", p.done, p.total, describePruneOutcome(outcome))
+	}
+}
+
+// finish clears the bar once every worktree is done
+func (p *pruneProgress) finish() {
+	if p.terminal && !p.opts.Porcelain {
+		fmt.Fprint(p.deps.ErrorOutput, "\r\033[K")
+	}
+}
+
+func (p *pruneProgress) bar() string {
+	filled := 0
+	if p.total > 0 {
+		filled = p.done * pruneBarWidth / p.total
+	}
+	return fmt.Sprintf("Pruning [%s%s] %d/%d", strings.Repeat("█", filled), strings.Repeat("░", pruneBarWidth-filled), p.done, p.total)
+}
+
+// describePruneOutcome is a line saying how pruning one worktree went
+func describePruneOutcome(outcome git.PruneOutcome) string {
+	switch outcome.Status {
+	case git.PrunePruned:
+		return "Pruned " + outcome.Branch
+	case git.PruneWouldPrune:
+		return fmt.Sprintf("Would prune %s at %s", outcome.Branch, outcome.Path)
+	case git.PruneSkipped:
+		return fmt.Sprintf("Skipped %s: %s", outcome.Branch, outcome.Reason)
+	}
+	return fmt.Sprintf("Failed to prune %s: %s", outcome.Branch, outcome.Reason)
+}
+
+// isTerminalWriter is whether w is a terminal, where output can be redrawn
+func isTerminalWriter(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	return ok && term.IsTerminal(file.Fd())
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sprout/pkg/metadata"
+)
+
+// enterRepo moves to the registered repository called name, so sprout --repo
+// works there the way it does when run from inside it
+func enterRepo(name string) error {
+	root, err := resolveRepo(name, metadata.NewStore("").KnownRepos())
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(root); err != nil {
+		return fmt.Errorf("can't work in %s: %w", root, err)
+	}
+	return nil
+}
+
+// resolveRepo is the root of the repository in known called name, as the
+// repo switcher names them. A path to a directory is taken as it is, so a
+// repository can be given before it's registered
+func resolveRepo(name string, known []string) (string, error) {
+	var matches []string
+	for _, root := range known {
+		if filepath.Base(root) == name {
+			matches = append(matches, root)
+		}
+	}
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1:
+		return "", fmt.Errorf("more than one registered repository is called %s: %s; pass its path instead", name, strings.Join(matches, ", "))
+	}
+	if info, err := os.Stat(name); err == nil && info.IsDir() {
+		return filepath.Abs(name)
+	}
+	if len(known) == 0 {
+		return "", fmt.Errorf("no repository called %s is registered; run sprout inside a repository to register it", name)
+	}
+	names := make([]string, len(known))
+	for i, root := range known {
+		names[i] = filepath.Base(root)
+	}
+	return "", fmt.Errorf("no repository called %s is registered; registered ones are %s", name, strings.Join(names, ", "))
+}
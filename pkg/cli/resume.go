@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sprout/pkg/config"
+	"sprout/pkg/problem"
+)
+
+// handleResumeCommandWithDeps runs the last command sprout ran in a worktree
+// again: in branch's worktree, or with no branch in the worktree a command was
+// last run in, to get back to what you were doing
+func handleResumeCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("resume", deps)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("unexpected arguments: %s. Usage: sprout resume [branch]", strings.Join(fs.Args()[1:], " "))
+	}
+
+	worktrees, err := deps.WorktreeManager.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	paths := make(map[string]string, len(worktrees))
+	for _, wt := range worktrees {
+		if wt.Branch != "" && !wt.Prunable {
+			paths[wt.Branch] = wt.Path
+		}
+	}
+
+	last := deps.Metadata.LastCommands()
+	branch := fs.Arg(0)
+	if branch == "" {
+		// The worktree still there that a command was run in most recently
+		for candidate, run := range last {
+			if _, ok := paths[candidate]; !ok {
+				continue
+			}
+			latest := last[branch].RanAt
+			if branch == "" || run.RanAt.After(latest) || (run.RanAt.Equal(latest) && candidate < branch) {
+				branch = candidate
+			}
+		}
+		if branch == "" {
+			return problem.WithHint(fmt.Errorf("nothing to resume: sprout hasn't run a command in any of this repository's worktrees"),
+				"sprout create <branch> <command> runs one and remembers it, as does the default command")
+		}
+	}
+	worktreePath, ok := paths[branch]
+	if !ok {
+		return problem.WithHint(fmt.Errorf("no worktree found for branch %s", branch), "sprout create "+branch+" makes one")
+	}
+
+	cfg, err := deps.ConfigLoader.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	command := last[branch].Command
+	if len(command) == 0 {
+		// Nothing run there yet, so resume as the TUI does
+		defaultCmd, _ := cfg.DefaultCommandFor(deps.RepoRoot, branch, config.Template{})
+		command = config.ResolveResumeCommand(cfg.GetResumeCommand(), defaultCmd, config.ResumeContext{
+			WorktreePath: worktreePath,
+			BranchName:   branch,
+			RepoName:     filepath.Base(deps.RepoRoot),
+		})
+	}
+
+	if err := deps.WorktreeManager.StartTimer(branch, worktreePath); err != nil {
+		fmt.Fprintf(deps.ErrorOutput, "Warning: %v\n", err)
+	}
+	deps.Metadata.RecordBranchUse(branch)
+	if cfg.OpensInTmux() {
+		deps.Metadata.RecordLastCommand(branch, worktreePath, command)
+		return openInTmux(branch, worktreePath, command, deps)
+	}
+	if len(command) == 0 {
+		fmt.Fprint(deps.Output, worktreePath)
+		return nil
+	}
+	fmt.Fprintf(deps.ErrorOutput, "Resuming %s: %s\n", branch, strings.Join(command, " "))
+	return runInWorktree(branch, worktreePath, command, "command", deps)
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"sprout/pkg/git"
+)
+
+const stackUsage = "Usage: sprout stack [--porcelain] [branch], or sprout stack restack [branch]"
+
+// handleStackCommandWithDeps shows the stack of branches built on one another
+// that a branch is part of, or with restack rebases each onto the one below
+func handleStackCommandWithDeps(args []string, deps *Dependencies) error {
+	restack := len(args) > 0 && args[0] == "restack"
+	if restack {
+		args = args[1:]
+	}
+	fs := newFlagSet("stack", deps)
+	porcelain := fs.Bool("porcelain", false, "print a tab-separated line per branch: branch, parent, ahead, behind, path")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return fmt.Errorf("unexpected arguments: %s. %s", strings.Join(positional[1:], " "), stackUsage)
+	}
+	var branch string
+	if len(positional) == 1 {
+		branch = positional[0]
+	}
+
+	var stack git.Stack
+	if restack {
+		stack, err = deps.WorktreeManager.Restack(branch, deps.presenter())
+	} else {
+		stack, err = deps.WorktreeManager.Stack(branch)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *porcelain {
+		for _, entry := range stack.Branches {
+			fmt.Fprintf(deps.Output, "%s\t%s\t%d\t%d\t%s\n", entry.Branch, entry.Parent, entry.Ahead, entry.Behind, entry.Path)
+		}
+		return nil
+	}
+	printStack(deps.Output, stack, !restack)
+	return nil
+}
+
+// printStack draws the stack as a tree on the default branch, each branch
+// indented under the one it's built on with how far apart they are
+func printStack(w io.Writer, stack git.Stack, hintRestack bool) {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("69")).
+		Bold(true)
+
+	accentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("108"))
+
+	fmt.Fprintln(w, headerStyle.Render("🌱 Stack"))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, stack.Trunk)
+
+	behind := false
+	for _, entry := range stack.Branches {
+		name := entry.Branch
+		if entry.Branch == stack.Branch {
+			name = accentStyle.Render(name) + " ←"
+		}
+		line := strings.Repeat("  ", entry.Depth+1) + name + " " + stackDistance(entry)
+		if entry.Path == "" {
+			line += " (no worktree)"
+		}
+		fmt.Fprintln(w, line)
+		behind = behind || (entry.Depth > 0 && entry.Behind > 0)
+	}
+
+	if hintRestack && behind {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "sprout stack restack rebases each branch onto the one it's built on")
+	}
+}
+
+// stackDistance says how far a branch in a stack is from its parent
+func stackDistance(entry git.StackBranch) string {
+	var parts []string
+	if entry.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("%d ahead", entry.Ahead))
+	}
+	if entry.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("%d behind", entry.Behind))
+	}
+	if len(parts) == 0 {
+		return "[up to date]"
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"sprout/pkg/git"
+)
+
+// todayReport is what sprout today sums up: the issues being worked on and
+// the worktrees that want something doing. Every list is present in the
+// JSON, empty or not, so scripts can rely on the keys
+type todayReport struct {
+	InProgress   []todayIssue    `json:"inProgress"`   // assigned Linear issues in a started state
+	OpenPRs      []todayWorktree `json:"openPRs"`      // worktrees whose PR is still open
+	Dirty        []todayWorktree `json:"dirty"`        // worktrees with uncommitted changes
+	ReadyToPrune []todayWorktree `json:"readyToPrune"` // merged worktrees sprout prune would remove
+	Warnings     []string        `json:"warnings,omitempty"`
+}
+
+// todayIssue is an issue in progress, with the worktree for it when there is one
+type todayIssue struct {
+	Identifier string `json:"identifier"`
+	Title      string `json:"title"`
+	State      string `json:"state"`
+	URL        string `json:"url,omitempty"`
+	Worktree   string `json:"worktree,omitempty"` // branch of its worktree
+}
+
+// todayWorktree is a worktree listed in one of sprout today's sections
+type todayWorktree struct {
+	Repo     string `json:"repo,omitempty"` // set with --all-repos
+	Branch   string `json:"branch"`
+	Path     string `json:"path"`
+	PRStatus string `json:"prStatus"`
+	CI       string `json:"ci,omitempty"` // passing, failing or pending, for open PRs that have had checks run
+	Issue    string `json:"issue,omitempty"`
+	Note     string `json:"note,omitempty"` // what sprout note says about it
+}
+
+// label is how the worktree is named in the text report
+func (w todayWorktree) label() string {
+	name := w.Branch
+	if w.Repo != "" {
+		name = w.Repo + ": " + name
+	}
+	if w.Issue != "" {
+		name += " (" + w.Issue + ")"
+	}
+	return name
+}
+
+// handleTodayCommandWithDeps prints a morning summary: issues in progress,
+// open PRs, uncommitted work and merged worktrees to prune
+func handleTodayCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("today", deps)
+	allRepos := fs.Bool("all-repos", false, "cover worktrees from every registered repository")
+	asJSON := fs.Bool("json", false, "print the summary as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s. Usage: sprout today [--all-repos] [--json]", strings.Join(fs.Args(), " "))
+	}
+
+	repos, err := targetRepos(deps, *allRepos)
+	if err != nil {
+		return err
+	}
+	var listed []listedWorktree
+	for _, repo := range repos {
+		worktrees, err := repo.WorktreeManager.ListWorktrees()
+		if err != nil {
+			return prefixRepoError(repo, err)
+		}
+		repo.WorktreeManager.LookUpCIStatuses(worktrees)
+		for _, wt := range worktrees {
+			if wt.Branch != "" && wt.Branch != "master" && wt.Branch != "main" && !wt.Bare && !wt.Prunable {
+				listed = append(listed, listedWorktree{repo: repo, worktree: wt})
+			}
+		}
+	}
+	markDirty(listed)
+
+	report := buildTodayReport(listed)
+	report.InProgress, report.Warnings = issuesInProgress(listed, deps)
+
+	if *asJSON {
+		encoder := json.NewEncoder(deps.Output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(deps.ErrorOutput, "Warning: %s\n", warning)
+	}
+	printTodayReport(deps.Output, report, deps.LinearClient != nil)
+	return nil
+}
+
+// buildTodayReport sorts worktrees into the sections they belong in; one can
+// be in several, such as an open PR with uncommitted changes
+func buildTodayReport(listed []listedWorktree) todayReport {
+	report := todayReport{
+		InProgress:   []todayIssue{},
+		OpenPRs:      []todayWorktree{},
+		Dirty:        []todayWorktree{},
+		ReadyToPrune: []todayWorktree{},
+	}
+	for _, l := range listed {
+		wt := l.worktree
+		entry := todayWorktree{
+			Repo:     l.repo.Name,
+			Branch:   wt.Branch,
+			Path:     wt.Path,
+			PRStatus: wt.PRStatus,
+			CI:       wt.CIStatus,
+			Issue:    l.repo.Metadata.IssueForBranch(wt.Branch),
+			Note:     wt.Note,
+		}
+		if wt.PRStatus == "Open" {
+			report.OpenPRs = append(report.OpenPRs, entry)
+		}
+		if l.dirty {
+			report.Dirty = append(report.Dirty, entry)
+		}
+		if git.ReadyToPrune(wt) {
+			report.ReadyToPrune = append(report.ReadyToPrune, entry)
+		}
+	}
+	return report
+}
+
+// issuesInProgress lists the assigned issues Linear has in a started state,
+// each with the worktree made for it. Linear being unreachable is a warning
+// rather than an error, as the rest of the report still stands
+func issuesInProgress(listed []listedWorktree, deps *Dependencies) ([]todayIssue, []string) {
+	issues := []todayIssue{}
+	if deps.LinearClient == nil {
+		return issues, nil
+	}
+	assigned, err := deps.LinearClient.GetAssignedIssues()
+	if err != nil {
+		return issues, []string{fmt.Sprintf("failed to load issues from Linear: %v", err)}
+	}
+
+	worktrees := make(map[string]string)
+	for _, l := range listed {
+		if issue := l.repo.Metadata.IssueForBranch(l.worktree.Branch); issue != "" {
+			if _, ok := worktrees[issue]; !ok {
+				worktrees[issue] = l.worktree.Branch
+			}
+		}
+	}
+	for _, issue := range assigned {
+		if issue.State.Type != "started" {
+			continue
+		}
+		issues = append(issues, todayIssue{
+			Identifier: issue.Identifier,
+			Title:      issue.Title,
+			State:      issue.State.Name,
+			URL:        issue.URL,
+			Worktree:   worktrees[strings.ToUpper(issue.Identifier)],
+		})
+	}
+	return issues, nil
+}
+
+// printTodayReport writes the report as a section per list, saying so when
+// one is empty
+func printTodayReport(w io.Writer, report todayReport, linearConfigured bool) {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("69")).
+		Bold(true)
+
+	accentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("108"))
+
+	section := func(title string, lines []string, empty string) {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, headerStyle.Render(title))
+		if len(lines) == 0 {
+			fmt.Fprintln(w, "  "+empty)
+		}
+		for _, line := range lines {
+			fmt.Fprintln(w, "  "+line)
+		}
+	}
+
+	fmt.Fprintln(w, headerStyle.Render("🌱 Today"))
+
+	var issues []string
+	for _, issue := range report.InProgress {
+		line := accentStyle.Render(issue.Identifier) + " " + issue.Title + " [" + issue.State + "]"
+		if issue.Worktree != "" {
+			line += " in " + issue.Worktree
+		}
+		issues = append(issues, line)
+	}
+	empty := "Nothing in progress"
+	switch {
+	case len(report.Warnings) > 0:
+		empty = "Couldn't load issues from Linear"
+	case !linearConfigured:
+		empty = "Linear isn't configured"
+	}
+	section("In Progress", issues, empty)
+
+	worktreeLines := func(worktrees []todayWorktree, detail func(todayWorktree) string) []string {
+		var lines []string
+		for _, wt := range worktrees {
+			line := accentStyle.Render(wt.label())
+			if extra := detail(wt); extra != "" {
+				line += " " + extra
+			}
+			if wt.Note != "" {
+				line += " — " + wt.Note
+			}
+			lines = append(lines, line)
+		}
+		return lines
+	}
+	section("Open PRs", worktreeLines(report.OpenPRs, func(wt todayWorktree) string {
+		if wt.CI == "" {
+			return ""
+		}
+		return ciIcon(wt.CI) + " CI " + wt.CI
+	}), "No open PRs")
+	section("Uncommitted Changes", worktreeLines(report.Dirty, func(wt todayWorktree) string { return wt.Path }), "Every worktree is clean")
+	section("Ready to Prune", worktreeLines(report.ReadyToPrune, func(todayWorktree) string { return "" }), "Nothing merged to clean up")
+	if len(report.ReadyToPrune) > 0 {
+		fmt.Fprintln(w, "  sprout prune removes them")
+	}
+}
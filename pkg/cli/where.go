@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"sprout/pkg/problem"
+)
+
+const whereUsage = "Usage: sprout where [--exists] [--porcelain] [branch]"
+
+// handleWhereCommandWithDeps prints where a branch's worktree is, or would go,
+// beside the main checkout and the directory worktrees go in, creating
+// nothing, so scripts don't have to work out sprout's layout for themselves
+func handleWhereCommandWithDeps(args []string, deps *Dependencies) error {
+	fs := newFlagSet("where", deps)
+	exists := fs.Bool("exists", false, "fail unless the branch already has a worktree")
+	porcelain := fs.Bool("porcelain", false, "print a tab-separated line: worktree path, repository, worktrees root, created or missing")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return fmt.Errorf("unexpected arguments: %s. %s", strings.Join(positional[1:], " "), whereUsage)
+	}
+	var branch string
+	if len(positional) == 1 {
+		branch = positional[0]
+	}
+
+	location, err := deps.WorktreeManager.Where(branch)
+	if err != nil {
+		return err
+	}
+	if *exists && !location.Exists {
+		return problem.WithHint(fmt.Errorf("no worktree for branch %s", location.Branch),
+			fmt.Sprintf("sprout create %s makes one at %s", location.Branch, location.Path))
+	}
+
+	state := "missing"
+	if location.Exists {
+		state = "created"
+	}
+	if *porcelain {
+		fmt.Fprintf(deps.Output, "%s\t%s\t%s\t%s\n", location.Path, location.RepoRoot, location.WorktreesRoot, state)
+		return nil
+	}
+	worktree := location.Path
+	if !location.Exists {
+		worktree += " (not created yet)"
+	}
+	fmt.Fprintf(deps.Output, "Worktree:    %s\n", worktree)
+	fmt.Fprintf(deps.Output, "Repository:  %s\n", location.RepoRoot)
+	fmt.Fprintf(deps.Output, "Worktrees:   %s\n", location.WorktreesRoot)
+	return nil
+}
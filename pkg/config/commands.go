@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultCommands replaces defaultCommand for the worktrees of some
+// repositories and branches
+type DefaultCommands struct {
+	Repos    map[string]string `json:"repos,omitempty"`    // by repository path
+	Branches map[string]string `json:"branches,omitempty"` // by branch pattern: fix/* for branches starting fix/, or a whole branch name
+}
+
+// Where the command run in a new worktree can come from, most specific first.
+// The first that sets one is used
+const (
+	CommandFromTemplate = "template"
+	CommandFromBranch   = "branch"
+	CommandFromRepo     = "repository"
+	CommandFromDefault  = "defaultCommand"
+)
+
+// DefaultCommandOrder is where the command for a new worktree is looked for,
+// in order, as sprout doctor explains it
+var DefaultCommandOrder = []string{
+	"the template's defaultCommand",
+	"defaultCommands.branches (longest matching pattern)",
+	"defaultCommands.repos",
+	"defaultCommand",
+}
+
+// DefaultCommandFor is the command to run in a new worktree of branchName in
+// repoPath, split into arguments, and which of the CommandFrom settings it
+// came from. Both are empty when nothing sets one
+func (c *Config) DefaultCommandFor(repoPath, branchName string, template Template) ([]string, string) {
+	if command := template.GetDefaultCommand(); len(command) > 0 {
+		return command, CommandFromTemplate
+	}
+	if c == nil {
+		return nil, ""
+	}
+	if pattern, ok := c.DefaultCommands.matchBranch(branchName); ok {
+		if command := parseConfiguredCommand(c.DefaultCommands.Branches[pattern]); len(command) > 0 {
+			return command, CommandFromBranch
+		}
+	}
+	if command := parseConfiguredCommand(c.DefaultCommands.Repos[repoPath]); len(command) > 0 {
+		return command, CommandFromRepo
+	}
+	if command := c.GetDefaultCommand(); len(command) > 0 {
+		return command, CommandFromDefault
+	}
+	return nil, ""
+}
+
+// BranchPatterns lists the configured branch patterns in name order
+func (d DefaultCommands) BranchPatterns() []string {
+	patterns := make([]string, 0, len(d.Branches))
+	for pattern := range d.Branches {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+// matchBranch finds the longest branch pattern branchName matches
+func (d DefaultCommands) matchBranch(branchName string) (string, bool) {
+	match := ""
+	for _, pattern := range d.BranchPatterns() {
+		if matchesBranchPattern(pattern, branchName) && len(pattern) > len(match) {
+			match = pattern
+		}
+	}
+	return match, match != ""
+}
+
+func matchesBranchPattern(pattern, branchName string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(branchName, prefix)
+	}
+	return pattern == branchName
+}
+
+func validateDefaultCommands(commands DefaultCommands) error {
+	for pattern := range commands.Branches {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("defaultCommands.branches can't have an empty branch pattern")
+		}
+		if strings.Contains(strings.TrimSuffix(pattern, "*"), "*") {
+			return fmt.Errorf("invalid defaultCommands.branches pattern %q (only a trailing * is supported, as in fix/*)", pattern)
+		}
+	}
+	return nil
+}
+
+// TimerContext is what timerStart and timerStop are told about the worktree
+// through the $BRANCH_NAME, $ISSUE_ID, $WORKTREE_PATH and $REPO_NAME
+// placeholders
+type TimerContext struct {
+	BranchName   string
+	IssueID      string // empty when the branch isn't named after an issue
+	WorktreePath string
+	RepoName     string
+}
+
+// TimerStartCommand is timerStart split into arguments for ctx, or nil when
+// it isn't set
+func (c *Config) TimerStartCommand(ctx TimerContext) []string {
+	return resolveTimerCommand(c.TimerStart, ctx)
+}
+
+// TimerStopCommand is timerStop split into arguments for ctx, or nil when it
+// isn't set
+func (c *Config) TimerStopCommand(ctx TimerContext) []string {
+	return resolveTimerCommand(c.TimerStop, ctx)
+}
+
+func resolveTimerCommand(command string, ctx TimerContext) []string {
+	args := parseConfiguredCommand(command)
+	for i, arg := range args {
+		arg = strings.ReplaceAll(arg, "$WORKTREE_PATH", ctx.WorktreePath)
+		arg = strings.ReplaceAll(arg, "$BRANCH_NAME", ctx.BranchName)
+		arg = strings.ReplaceAll(arg, "$ISSUE_ID", ctx.IssueID)
+		arg = strings.ReplaceAll(arg, "$REPO_NAME", ctx.RepoName)
+		args[i] = arg
+	}
+	return args
+}
@@ -1,25 +1,91 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
-
-	"github.com/yosuke-furukawa/json5/encoding/json5"
+	"time"
 )
 
 const PromptPlaceholder = "$PROMPT"
 
+// OpenInTmux makes create and switch open each worktree in its own tmux session
+const OpenInTmux = "tmux"
+
+// DefaultIssueProvider is the issue tracker used when issueProvider isn't set
+const DefaultIssueProvider = "linear"
+
+// Ways to look up PR status, chosen with githubProvider
+const (
+	GitHubProviderAuto = "auto" // gh when it's installed, otherwise the GitHub API
+	GitHubProviderGH   = "gh"
+	GitHubProviderAPI  = "api"
+)
+
+// DefaultNetworkTimeout bounds Linear and GitHub calls when networkTimeoutSeconds isn't set
+const DefaultNetworkTimeout = 30 * time.Second
+
+// DefaultIssueCacheTTL is how long fetched tickets are reused when issueCacheSeconds isn't set
+const DefaultIssueCacheTTL = 5 * time.Minute
+
+// DefaultTrashRetention is how long pruned worktrees stay undoable when trashDays isn't set
+const DefaultTrashRetention = 7 * 24 * time.Hour
+
+// Orders the work queue can list issues in, chosen per repo with issueSort
+const (
+	IssueSortUpdated  = "updated"
+	IssueSortPriority = "priority"
+	IssueSortEstimate = "estimate"
+)
+
+// IssueSortOrders lists the issue orders in the order the TUI cycles through them
+var IssueSortOrders = []string{IssueSortUpdated, IssueSortPriority, IssueSortEstimate}
+
+// What issueCycle can limit the issue list to, besides a cycle's number
+const (
+	IssueCycleAll     = "all"     // every cycle, and issues in none
+	IssueCycleCurrent = "current" // the cycle under way, as when unset and any issue is in it
+)
+
+// DefaultLinearWorkspace is the name linearApiKey goes by next to the
+// workspaces named in linearWorkspaces
+const DefaultLinearWorkspace = "default"
+
 type Config struct {
-	DefaultCommand    string              `json:"defaultCommand,omitempty"`
-	ResumeCommand     string              `json:"resumeCommand,omitempty"`
-	LinearAPIKey      string              `json:"linearApiKey,omitempty"`
-	SparseCheckout    map[string][]string `json:"sparseCheckout,omitempty"`
-	WorktreeBasePath  string              `json:"worktreeBasePath,omitempty"`
-	WorktreeBasePaths map[string]string   `json:"worktreeBasePaths,omitempty"`
+	DefaultCommand        string              `json:"defaultCommand,omitempty"`
+	DefaultCommands       DefaultCommands     `json:"defaultCommands,omitzero"`
+	ResumeCommand         string              `json:"resumeCommand,omitempty"`
+	TimerStart            string              `json:"timerStart,omitempty"`
+	TimerStop             string              `json:"timerStop,omitempty"`
+	LinearAPIKey          string              `json:"linearApiKey,omitempty"`
+	LinearWorkspaces      map[string]string   `json:"linearWorkspaces,omitempty"`
+	LinearWorkspace       map[string]string   `json:"linearWorkspace,omitempty"`
+	LinearOAuthClientID   string              `json:"linearOAuthClientId,omitempty"`
+	IssueProvider         string              `json:"issueProvider,omitempty"`
+	GitHubProvider        string              `json:"githubProvider,omitempty"`
+	SparseCheckout        map[string][]string `json:"sparseCheckout,omitempty"`
+	WorktreeBasePath      string              `json:"worktreeBasePath,omitempty"`
+	WorktreeBasePaths     map[string]string   `json:"worktreeBasePaths,omitempty"`
+	OpenIn                string              `json:"openIn,omitempty"`
+	Detach                bool                `json:"detach,omitempty"`
+	EnvTemplate           string              `json:"envTemplate,omitempty"`
+	Keybindings           map[string][]string `json:"keybindings,omitempty"`
+	NetworkTimeoutSeconds int                 `json:"networkTimeoutSeconds,omitempty"`
+	GitTimeoutSeconds     int                 `json:"gitTimeoutSeconds,omitempty"`
+	TrashDays             int                 `json:"trashDays,omitempty"`
+	IssueCacheSeconds     *int                `json:"issueCacheSeconds,omitempty"`
+	IssueCacheOnDisk      bool                `json:"issueCacheOnDisk,omitempty"`
+	IssueRefreshSeconds   int                 `json:"issueRefreshSeconds,omitempty"`
+	IssueSort             map[string]string   `json:"issueSort,omitempty"`
+	IssueCycle            string              `json:"issueCycle,omitempty"`
+	Templates             Templates           `json:"templates,omitempty"`
+	Webhook               Webhook             `json:"webhook,omitzero"`
+	GC                    GC                  `json:"gc,omitzero"`
+	Include               Includes            `json:"include,omitempty"`
+	Overrides             []Override          `json:"overrides,omitempty"`
 }
 
 // LoaderInterface defines the interface for config loading
@@ -54,42 +120,54 @@ func DefaultConfig() *Config {
 	}
 }
 
+// Load reads the config file from Path and the files it includes, then
+// applies any SPROUT_* environment overrides on top, so the environment wins
+// over the files and the files over the defaults
 func Load() (*Config, error) {
-	configPath, err := getConfigPath()
+	configPath, err := Path()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config path: %w", err)
 	}
-
-	config := DefaultConfig()
-
-	file, err := os.Open(configPath)
+	resolved, err := Resolve(configPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return config, nil
-		}
-		return nil, fmt.Errorf("failed to open config file: %w", err)
-	}
-	defer file.Close()
-
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	// First parse into a generic map to detect unknown keys
-	var rawConfig map[string]interface{}
-	if err := json5.Unmarshal(data, &rawConfig); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+		return nil, err
 	}
+	return resolved.Config, nil
+}
 
+// checkKeys rejects settings sprout doesn't know, listing those it does
+func checkKeys(rawConfig map[string]interface{}) error {
 	// Check for unknown keys
 	validKeys := map[string]bool{
-		"defaultCommand":    true,
-		"resumeCommand":     true,
-		"linearApiKey":      true,
-		"sparseCheckout":    true,
-		"worktreeBasePath":  true,
-		"worktreeBasePaths": true,
+		"defaultCommand":        true,
+		"defaultCommands":       true,
+		"resumeCommand":         true,
+		"timerStart":            true,
+		"timerStop":             true,
+		"linearApiKey":          true,
+		"linearWorkspaces":      true,
+		"linearWorkspace":       true,
+		"linearOAuthClientId":   true,
+		"issueProvider":         true,
+		"githubProvider":        true,
+		"sparseCheckout":        true,
+		"worktreeBasePath":      true,
+		"worktreeBasePaths":     true,
+		"openIn":                true,
+		"detach":                true,
+		"envTemplate":           true,
+		"keybindings":           true,
+		"networkTimeoutSeconds": true,
+		"gitTimeoutSeconds":     true,
+		"trashDays":             true,
+		"issueCacheSeconds":     true,
+		"issueCacheOnDisk":      true,
+		"issueRefreshSeconds":   true,
+		"issueSort":             true,
+		"issueCycle":            true,
+		"templates":             true,
+		"webhook":               true,
+		"gc":                    true,
 	}
 
 	var unknownKeys []string
@@ -100,46 +178,51 @@ func Load() (*Config, error) {
 	}
 
 	if len(unknownKeys) > 0 {
-		return nil, fmt.Errorf("unknown config keys found: %v\n\nValid config keys are:\n  - defaultCommand: string (command to run by default in new worktrees)\n  - resumeCommand: string (command to run when resuming existing worktrees)\n  - linearApiKey: string (API key for Linear integration)\n  - sparseCheckout: object (map of repository paths to directory arrays)\n  - worktreeBasePath: string (base worktree directory with optional variables)\n  - worktreeBasePaths: object (deprecated: map of repository names or paths to base worktree directories)", unknownKeys)
-	}
-
-	// Now parse into the actual config struct
-	if err := json5.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+		return fmt.Errorf("unknown config keys found: %v\n\nValid config keys are:\n  - defaultCommand: string (command to run by default in new worktrees)\n  - defaultCommands: object (repos and branches maps of repository paths and branch patterns, such as fix/*, to commands that replace defaultCommand)\n  - resumeCommand: string (command to run when resuming existing worktrees)\n  - timerStart: string (command that starts a time tracker when a worktree is created or opened)\n  - timerStop: string (command that stops it when the worktree is pruned)\n  - linearApiKey: string (API key for Linear integration)\n  - linearWorkspaces: object (map of workspace names to Linear API keys, merged in the work queue)\n  - linearWorkspace: object (map of repository paths to the one Linear workspace they use)\n  - linearOAuthClientId: string (Linear OAuth application to sign in with via sprout auth linear, instead of an API key)\n  - issueProvider: string (issue tracker to load tickets from: \"linear\", \"github\", \"jira\" or \"none\")\n  - githubProvider: string (how to look up PR status: \"auto\", \"gh\" or \"api\", default auto)\n  - sparseCheckout: object (map of repository paths to directory arrays)\n  - worktreeBasePath: string (base worktree directory with optional variables)\n  - worktreeBasePaths: object (deprecated: map of repository names or paths to base worktree directories)\n  - openIn: string (\"tmux\" to open worktrees in their own tmux session)\n  - detach: boolean (run sprout create's command in the background rather than waiting for it)\n  - envTemplate: string (template rendered to .env.local in new worktrees)\n  - keybindings: object (map of TUI actions to key lists, e.g. {\"up\": [\"k\", \"up\"]})\n  - networkTimeoutSeconds: number (how long to wait for Linear and GitHub, default 30)\n  - gitTimeoutSeconds: number (how long a git command may run, default no limit)\n  - trashDays: number (how long sprout undo can bring back pruned worktrees, default 7)\n  - issueCacheSeconds: number (how long tickets fetched from Linear are reused before asking again, default 300, 0 to always ask)\n  - issueCacheOnDisk: boolean (keep fetched tickets between runs in the user cache directory)\n  - issueRefreshSeconds: number (how often the TUI reloads tickets by itself, default never)\n  - issueSort: object (map of repository paths to issue orders: updated, priority or estimate)\n  - issueCycle: string (cycle the work queue opens on: \"current\", \"all\" or a cycle number)\n  - templates: object (map of branch prefixes to base, sparseProfile, hooks, defaultCommand and labels)\n  - webhook: object (url to post worktreeCreated, worktreeDeleted and prMerged events to, and the events to send)\n  - gc: object (staleDays and largerThan, what sprout gc collects besides merged worktrees)\n  - include: string or array (config files layered over this one, each a path or {path, when})\n  - overrides: array (sections of settings applied when their when matches the hostname, os or env)", unknownKeys)
 	}
-
-	return config, nil
+	return nil
 }
 
-func Save(config *Config) error {
-	configPath, err := getConfigPath()
-	if err != nil {
-		return fmt.Errorf("failed to get config path: %w", err)
+// validate checks the settings that have a fixed set of values or limits
+func validate(config *Config) error {
+	if config.OpenIn != "" && config.OpenIn != OpenInTmux {
+		return fmt.Errorf("invalid openIn value %q (supported: %q)", config.OpenIn, OpenInTmux)
 	}
-
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+	switch config.GitHubProvider {
+	case "", GitHubProviderAuto, GitHubProviderGH, GitHubProviderAPI:
+	default:
+		return fmt.Errorf("invalid githubProvider value %q (supported: %q, %q, %q)", config.GitHubProvider, GitHubProviderAuto, GitHubProviderGH, GitHubProviderAPI)
 	}
-
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	if config.NetworkTimeoutSeconds < 0 || config.GitTimeoutSeconds < 0 {
+		return fmt.Errorf("networkTimeoutSeconds and gitTimeoutSeconds can't be negative")
 	}
-
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	if config.TrashDays < 0 {
+		return fmt.Errorf("trashDays can't be negative")
 	}
-
-	return nil
-}
-
-func getConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+	if (config.IssueCacheSeconds != nil && *config.IssueCacheSeconds < 0) || config.IssueRefreshSeconds < 0 {
+		return fmt.Errorf("issueCacheSeconds and issueRefreshSeconds can't be negative")
+	}
+	if err := validateTemplates(config.Templates); err != nil {
+		return err
+	}
+	if err := validateDefaultCommands(config.DefaultCommands); err != nil {
+		return err
+	}
+	if err := validateWebhook(config.Webhook); err != nil {
+		return err
+	}
+	if err := validateGC(config.GC); err != nil {
+		return err
+	}
+	if !isIssueCycle(config.IssueCycle) {
+		return fmt.Errorf("invalid issueCycle value %q (supported: %q, %q or a cycle number)", config.IssueCycle, IssueCycleAll, IssueCycleCurrent)
+	}
+	for repoPath, order := range config.IssueSort {
+		if !isIssueSortOrder(order) {
+			return fmt.Errorf("invalid issueSort value %q for %s (supported: %s)", order, repoPath, strings.Join(IssueSortOrders, ", "))
+		}
 	}
-	return filepath.Join(homeDir, ".sprout.json5"), nil
+	return validateLinearWorkspaces(config)
 }
 
 func (c *Config) GetDefaultCommand() []string {
@@ -185,6 +268,18 @@ func ResolveDefaultCommand(defaultCmdArgs []string, prompt string) []string {
 	return resolved
 }
 
+// ResolveCreatedCommand is the command to run in a new worktree, from
+// defaultCmdArgs given prompt, and the one to remember for sprout resume to
+// run again. The prompt was for starting out, so when the default command
+// takes one, resuming runs the resume command instead, or nothing
+func ResolveCreatedCommand(defaultCmdArgs, resumeCmdArgs []string, prompt string, ctx ResumeContext) (run, remember []string) {
+	run = ResolveDefaultCommand(defaultCmdArgs, prompt)
+	if NeedsPromptCapture(defaultCmdArgs) {
+		return run, ResolveResumeCommand(resumeCmdArgs, nil, ctx)
+	}
+	return run, run
+}
+
 type ResumeContext struct {
 	WorktreePath string
 	BranchName   string
@@ -269,10 +364,193 @@ func parseCommandLine(command string) []string {
 	return args
 }
 
+// GetEnvTemplatePath returns the env template path, resolving relative paths
+// against the repository root
+func (c *Config) GetEnvTemplatePath(repoRoot string) (string, bool) {
+	if c == nil || strings.TrimSpace(c.EnvTemplate) == "" {
+		return "", false
+	}
+	path := os.ExpandEnv(c.EnvTemplate)
+	if strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repoRoot, path)
+	}
+	return path, true
+}
+
+// OpensInTmux reports whether worktrees should be opened in tmux sessions
+func (c *Config) OpensInTmux() bool {
+	return c != nil && c.OpenIn == OpenInTmux
+}
+
 func (c *Config) GetLinearAPIKey() string {
 	return c.LinearAPIKey
 }
 
+// LinearWorkspace is one set of Linear credentials, named so its issues can
+// be told apart from another workspace's
+type LinearWorkspace struct {
+	Name   string
+	APIKey string
+	OAuth  bool // signed in with sprout auth linear rather than an API key
+}
+
+// GetLinearWorkspaces lists the Linear workspaces to load issues from in name
+// order, with linearApiKey included as the default workspace. Without a key,
+// the default workspace is the OAuth sign-in when linearOAuthClientId is set.
+func (c *Config) GetLinearWorkspaces() []LinearWorkspace {
+	if c == nil {
+		return nil
+	}
+	var workspaces []LinearWorkspace
+	if _, named := c.LinearWorkspaces[DefaultLinearWorkspace]; !named {
+		switch {
+		case c.LinearAPIKey != "":
+			workspaces = append(workspaces, LinearWorkspace{Name: DefaultLinearWorkspace, APIKey: c.LinearAPIKey})
+		case c.LinearOAuthClientID != "":
+			workspaces = append(workspaces, LinearWorkspace{Name: DefaultLinearWorkspace, OAuth: true})
+		}
+	}
+	for name, apiKey := range c.LinearWorkspaces {
+		if apiKey != "" {
+			workspaces = append(workspaces, LinearWorkspace{Name: name, APIKey: apiKey})
+		}
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].Name < workspaces[j].Name })
+	return workspaces
+}
+
+// ForRepo is the config as repoPath sees it: when linearWorkspace picks a
+// workspace for the repo, that is the only one its issues come from
+func (c *Config) ForRepo(repoPath string) *Config {
+	if c == nil {
+		return nil
+	}
+	name, ok := c.LinearWorkspace[repoPath]
+	if !ok {
+		return c
+	}
+	scoped := *c
+	scoped.LinearWorkspaces = nil
+	if apiKey, named := c.LinearWorkspaces[name]; named {
+		scoped.LinearWorkspaces = map[string]string{name: apiKey}
+	}
+	// Whatever the default workspace signs in with belongs to it alone
+	if _, named := c.LinearWorkspaces[name]; named || name != DefaultLinearWorkspace {
+		scoped.LinearAPIKey = ""
+		scoped.LinearOAuthClientID = ""
+	}
+	return &scoped
+}
+
+func validateLinearWorkspaces(c *Config) error {
+	var names []string
+	for _, workspace := range c.GetLinearWorkspaces() {
+		names = append(names, workspace.Name)
+	}
+	for repoPath, name := range c.LinearWorkspace {
+		found := false
+		for _, known := range names {
+			found = found || known == name
+		}
+		if !found {
+			return fmt.Errorf("linearWorkspace %q for %s isn't a configured workspace (configured: %s)", name, repoPath, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// GetIssueProvider names the configured issue tracker, defaulting to Linear
+func (c *Config) GetIssueProvider() string {
+	if c == nil || strings.TrimSpace(c.IssueProvider) == "" {
+		return DefaultIssueProvider
+	}
+	return strings.TrimSpace(c.IssueProvider)
+}
+
+// GetGitHubProvider is how PR status is looked up, defaulting to auto
+func (c *Config) GetGitHubProvider() string {
+	if c == nil || c.GitHubProvider == "" {
+		return GitHubProviderAuto
+	}
+	return c.GitHubProvider
+}
+
+// NetworkTimeout is how long to wait for Linear or GitHub before giving up
+func (c *Config) NetworkTimeout() time.Duration {
+	if c == nil || c.NetworkTimeoutSeconds <= 0 {
+		return DefaultNetworkTimeout
+	}
+	return time.Duration(c.NetworkTimeoutSeconds) * time.Second
+}
+
+// GitTimeout is how long a single git command may run; zero means no limit
+func (c *Config) GitTimeout() time.Duration {
+	if c == nil || c.GitTimeoutSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(c.GitTimeoutSeconds) * time.Second
+}
+
+// IssueCacheTTL is how long tickets fetched from the issue tracker are
+// reused before it's asked again; zero, when issueCacheSeconds is 0, means
+// they're never reused
+func (c *Config) IssueCacheTTL() time.Duration {
+	if c == nil || c.IssueCacheSeconds == nil {
+		return DefaultIssueCacheTTL
+	}
+	return time.Duration(*c.IssueCacheSeconds) * time.Second
+}
+
+// IssueRefreshInterval is how often the TUI reloads tickets by itself; zero
+// means only when asked to
+func (c *Config) IssueRefreshInterval() time.Duration {
+	if c == nil || c.IssueRefreshSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(c.IssueRefreshSeconds) * time.Second
+}
+
+// TrashRetention is how long a pruned worktree waits in the trash for sprout
+// undo before it's deleted for good
+func (c *Config) TrashRetention() time.Duration {
+	if c == nil || c.TrashDays <= 0 {
+		return DefaultTrashRetention
+	}
+	return time.Duration(c.TrashDays) * 24 * time.Hour
+}
+
+// GetIssueSort is the order the work queue lists repoPath's issues in,
+// defaulting to most recently updated first
+func (c *Config) GetIssueSort(repoPath string) string {
+	if c == nil || !isIssueSortOrder(c.IssueSort[repoPath]) {
+		return IssueSortUpdated
+	}
+	return c.IssueSort[repoPath]
+}
+
+func isIssueCycle(cycle string) bool {
+	switch cycle {
+	case "", IssueCycleAll, IssueCycleCurrent:
+		return true
+	}
+	number, err := strconv.Atoi(cycle)
+	return err == nil && number > 0
+}
+
+func isIssueSortOrder(order string) bool {
+	for _, known := range IssueSortOrders {
+		if order == known {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Config) GetSparseCheckoutDirectories(repoPath string) ([]string, bool) {
 	if c.SparseCheckout == nil {
 		return nil, false
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetDefaultCommand(t *testing.T) {
@@ -183,6 +187,25 @@ func TestResolveDefaultCommand(t *testing.T) {
 	}
 }
 
+func TestResolveCreatedCommandLeavesThePromptOutOfWhatsRemembered(t *testing.T) {
+	ctx := ResumeContext{WorktreePath: "/tmp/worktrees/feature", BranchName: "feature", RepoName: "sprout"}
+
+	run, remember := ResolveCreatedCommand([]string{"claude", PromptPlaceholder}, []string{"claude", "--resume"}, "fix the login form", ctx)
+	if !reflect.DeepEqual(run, []string{"claude", "fix the login form"}) {
+		t.Errorf("expected the prompt run, got %v", run)
+	}
+	if !reflect.DeepEqual(remember, []string{"claude", "--resume"}) {
+		t.Errorf("expected the resume command remembered, got %v", remember)
+	}
+
+	if _, remember := ResolveCreatedCommand([]string{"claude", PromptPlaceholder}, nil, "fix the login form", ctx); remember != nil {
+		t.Errorf("expected nothing remembered without a resume command, got %v", remember)
+	}
+	if run, remember := ResolveCreatedCommand([]string{"code", "."}, []string{"claude", "--resume"}, "", ctx); !reflect.DeepEqual(run, remember) {
+		t.Errorf("expected a command without a prompt remembered as run, got %v and %v", run, remember)
+	}
+}
+
 func TestResolveResumeCommand(t *testing.T) {
 	ctx := ResumeContext{
 		WorktreePath: "/tmp/worktrees/feature",
@@ -299,3 +322,321 @@ func TestGetWorktreeBasePathFallsBackToLegacyMap(t *testing.T) {
 		t.Fatalf("expected path %s, got %s", expectedPath, path)
 	}
 }
+
+func TestTimeouts(t *testing.T) {
+	var unset *Config
+	if unset.NetworkTimeout() != DefaultNetworkTimeout || unset.GitTimeout() != 0 {
+		t.Fatalf("expected a 30s network timeout and no git timeout by default, got %s and %s", unset.NetworkTimeout(), unset.GitTimeout())
+	}
+
+	cfg := &Config{NetworkTimeoutSeconds: 5, GitTimeoutSeconds: 120}
+	if cfg.NetworkTimeout() != 5*time.Second {
+		t.Fatalf("expected a 5s network timeout, got %s", cfg.NetworkTimeout())
+	}
+	if cfg.GitTimeout() != 2*time.Minute {
+		t.Fatalf("expected a 2m git timeout, got %s", cfg.GitTimeout())
+	}
+}
+
+func TestIssueCacheSecondsZeroTurnsTheCacheOff(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".sprout.json5"), []byte(`{"issueCacheSeconds": 0}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.IssueCacheTTL() != 0 {
+		t.Fatalf("expected no caching, got %s", cfg.IssueCacheTTL())
+	}
+	if unset := (&Config{}).IssueCacheTTL(); unset != DefaultIssueCacheTTL {
+		t.Fatalf("expected %s when unset, got %s", DefaultIssueCacheTTL, unset)
+	}
+
+	t.Setenv("SPROUT_ISSUE_CACHE_SECONDS", "60")
+	if cfg, err = Load(); err != nil || cfg.IssueCacheTTL() != time.Minute {
+		t.Fatalf("expected the variable to win, got %v, %v", cfg, err)
+	}
+}
+
+func TestIssueSortIsSetPerRepo(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	var unset *Config
+	if unset.GetIssueSort("/repos/sprout") != IssueSortUpdated {
+		t.Fatalf("expected issues sorted by update time by default, got %s", unset.GetIssueSort("/repos/sprout"))
+	}
+
+	configPath := filepath.Join(home, ".sprout.json5")
+	if err := os.WriteFile(configPath, []byte(`{issueSort: {"/repos/sprout": "priority"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.GetIssueSort("/repos/sprout") != IssueSortPriority || cfg.GetIssueSort("/repos/other") != IssueSortUpdated {
+		t.Fatalf("expected only /repos/sprout sorted by priority, got %v", cfg.IssueSort)
+	}
+
+	if err := os.WriteFile(configPath, []byte(`{issueSort: {"/repos/sprout": "alphabetical"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); err == nil {
+		t.Fatal("expected an unknown order to be rejected")
+	}
+}
+
+func TestIssueCycleIsAllCurrentOrANumber(t *testing.T) {
+	for _, cycle := range []string{"", IssueCycleAll, IssueCycleCurrent, "12"} {
+		if err := validate(&Config{IssueCycle: cycle}); err != nil {
+			t.Fatalf("expected issueCycle %q accepted, got %v", cycle, err)
+		}
+	}
+	for _, cycle := range []string{"next", "0", "-3"} {
+		if err := validate(&Config{IssueCycle: cycle}); err == nil || !strings.Contains(err.Error(), "invalid issueCycle value") {
+			t.Fatalf("expected issueCycle %q rejected, got %v", cycle, err)
+		}
+	}
+}
+
+func TestLoadReadsIssueCycleFromTheConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	config := `{
+  // Optional: list only tickets in this Linear cycle, "current", a number or "all"
+  "issueCycle": "current",
+}`
+	if err := os.WriteFile(filepath.Join(home, ".sprout.json5"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.IssueCycle != IssueCycleCurrent {
+		t.Fatalf("expected issueCycle %q, got %q", IssueCycleCurrent, cfg.IssueCycle)
+	}
+}
+
+func TestLinearWorkspacesAreMergedOrPickedPerRepo(t *testing.T) {
+	cfg := &Config{
+		LinearAPIKey:     "lin_api_personal",
+		LinearWorkspaces: map[string]string{"work": "lin_api_work", "client": "lin_api_client"},
+		LinearWorkspace:  map[string]string{"/repos/client-app": "client"},
+	}
+
+	want := []LinearWorkspace{
+		{Name: "client", APIKey: "lin_api_client"},
+		{Name: DefaultLinearWorkspace, APIKey: "lin_api_personal"},
+		{Name: "work", APIKey: "lin_api_work"},
+	}
+	if got := cfg.GetLinearWorkspaces(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected every workspace in name order, got %v", got)
+	}
+	if got := cfg.ForRepo("/repos/sprout").GetLinearWorkspaces(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected a repo without a workspace to see them all, got %v", got)
+	}
+
+	scoped := cfg.ForRepo("/repos/client-app")
+	if got := scoped.GetLinearWorkspaces(); !reflect.DeepEqual(got, []LinearWorkspace{{Name: "client", APIKey: "lin_api_client"}}) {
+		t.Fatalf("expected only the client workspace, got %v", got)
+	}
+	if cfg.LinearAPIKey != "lin_api_personal" || len(cfg.LinearWorkspaces) != 2 {
+		t.Fatal("expected ForRepo to leave the loaded config alone")
+	}
+}
+
+func TestLinearOAuthSignsInTheDefaultWorkspace(t *testing.T) {
+	cfg := &Config{
+		LinearOAuthClientID: "client-id",
+		LinearWorkspaces:    map[string]string{"client": "lin_api_client"},
+		LinearWorkspace:     map[string]string{"/repos/client-app": "client", "/repos/sprout": DefaultLinearWorkspace},
+	}
+
+	want := []LinearWorkspace{{Name: "client", APIKey: "lin_api_client"}, {Name: DefaultLinearWorkspace, OAuth: true}}
+	if got := cfg.GetLinearWorkspaces(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the OAuth sign-in as the default workspace, got %v", got)
+	}
+	if got := cfg.ForRepo("/repos/sprout").GetLinearWorkspaces(); !reflect.DeepEqual(got, want[1:]) {
+		t.Fatalf("expected only the OAuth workspace, got %v", got)
+	}
+	if got := cfg.ForRepo("/repos/client-app").GetLinearWorkspaces(); !reflect.DeepEqual(got, want[:1]) {
+		t.Fatalf("expected only the client workspace, got %v", got)
+	}
+
+	cfg.LinearAPIKey = "lin_api_personal"
+	if got := cfg.GetLinearWorkspaces(); got[1].APIKey != "lin_api_personal" || got[1].OAuth {
+		t.Fatalf("expected linearApiKey to take precedence over OAuth, got %v", got)
+	}
+}
+
+func TestLoadRejectsAnUnknownLinearWorkspace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	config := `{linearWorkspaces: {work: "lin_api_work"}, linearWorkspace: {"/repos/sprout": "wrok"}}`
+	if err := os.WriteFile(filepath.Join(home, ".sprout.json5"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), `linearWorkspace "wrok" for /repos/sprout isn't a configured workspace (configured: work)`) {
+		t.Fatalf("expected an unknown workspace error, got %v", err)
+	}
+}
+
+func TestGitHubProvider(t *testing.T) {
+	var unset *Config
+	if unset.GetGitHubProvider() != GitHubProviderAuto {
+		t.Fatalf("expected the auto provider by default, got %s", unset.GetGitHubProvider())
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, ".sprout.json5")
+	if err := os.WriteFile(configPath, []byte(`{githubProvider: "api"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.GetGitHubProvider() != GitHubProviderAPI {
+		t.Fatalf("expected the api provider, got %s", cfg.GetGitHubProvider())
+	}
+
+	if err := os.WriteFile(configPath, []byte(`{githubProvider: "octokit"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), `invalid githubProvider value "octokit"`) {
+		t.Fatalf("expected an unknown provider to be rejected, got %v", err)
+	}
+}
+
+func TestTemplatesMatchLongestPrefixThenLabels(t *testing.T) {
+	templates := Templates{
+		"fix/":        {Base: "develop", Labels: []string{"Bug"}},
+		"fix/urgent/": {Base: "main"},
+		"spike/":      {DefaultCommand: "claude"},
+	}
+
+	if prefix, template, ok := templates.Match("fix/urgent/login", nil); !ok || prefix != "fix/urgent/" || template.Base != "main" {
+		t.Fatalf("expected the longest prefix to win, got %q %+v", prefix, template)
+	}
+	if prefix, _, ok := templates.Match("eng-42-refunds", []string{"payments", "bug"}); !ok || prefix != "fix/" {
+		t.Fatalf("expected the bug label to pick fix/, got %q", prefix)
+	}
+	if _, _, ok := templates.Match("eng-43-docs", []string{"docs"}); ok {
+		t.Fatal("expected no template for an unmatched branch")
+	}
+	if branch := ApplyTemplatePrefix("fix/", "fix/login"); branch != "fix/login" {
+		t.Fatalf("expected an existing prefix to be kept once, got %s", branch)
+	}
+}
+
+func TestDefaultCommandForPrefersTemplateThenBranchThenRepo(t *testing.T) {
+	cfg := &Config{
+		DefaultCommand: "code .",
+		DefaultCommands: DefaultCommands{
+			Repos:    map[string]string{"/code/api": "idea ."},
+			Branches: map[string]string{"spike/*": "bash", "spike/ml/*": "jupyter lab", "release": "make release"},
+		},
+	}
+
+	tests := []struct {
+		repo     string
+		branch   string
+		template Template
+		command  string
+		source   string
+	}{
+		{repo: "/code/api", branch: "spike/cache", template: Template{DefaultCommand: "claude"}, command: "claude", source: CommandFromTemplate},
+		{repo: "/code/api", branch: "spike/cache", command: "bash", source: CommandFromBranch},
+		{repo: "/code/web", branch: "spike/ml/embeddings", command: "jupyter lab", source: CommandFromBranch},
+		{repo: "/code/web", branch: "release", command: "make release", source: CommandFromBranch},
+		{repo: "/code/api", branch: "release-notes", command: "idea .", source: CommandFromRepo},
+		{repo: "/code/web", branch: "fix/login", command: "code .", source: CommandFromDefault},
+	}
+	for _, tt := range tests {
+		command, source := cfg.DefaultCommandFor(tt.repo, tt.branch, tt.template)
+		if strings.Join(command, " ") != tt.command || source != tt.source {
+			t.Errorf("%s in %s: expected %q from %s, got %q from %s", tt.branch, tt.repo, tt.command, tt.source, strings.Join(command, " "), source)
+		}
+	}
+
+	if command, source := (&Config{}).DefaultCommandFor("/code/web", "fix/login", Template{}); command != nil || source != "" {
+		t.Fatalf("expected no command when nothing sets one, got %q from %q", command, source)
+	}
+	if err := validate(&Config{DefaultCommands: DefaultCommands{Branches: map[string]string{"fix/*/wip": "bash"}}}); err == nil {
+		t.Fatal("expected a * before the end of a branch pattern to be rejected")
+	}
+}
+
+func TestWebhookSendsTheEventsItNames(t *testing.T) {
+	all := Webhook{URL: "https://hooks.example.com/sprout"}
+	if !all.Sends(WebhookPRMerged) {
+		t.Fatal("expected a webhook without events to send them all")
+	}
+	some := Webhook{URL: "https://hooks.example.com/sprout", Events: []string{WebhookWorktreeCreated}}
+	if !some.Sends(WebhookWorktreeCreated) || some.Sends(WebhookWorktreeDeleted) {
+		t.Fatal("expected only the events named to be sent")
+	}
+	if (Webhook{}).Sends(WebhookWorktreeCreated) {
+		t.Fatal("expected nothing sent without a URL")
+	}
+
+	if err := validate(&Config{Webhook: Webhook{URL: "https://hooks.example.com/sprout", Events: []string{"prOpened"}}}); err == nil || !strings.Contains(err.Error(), `invalid webhook event "prOpened"`) {
+		t.Fatalf("expected an unknown event to be rejected, got %v", err)
+	}
+	if err := validate(&Config{Webhook: Webhook{URL: "hooks.example.com"}}); err == nil {
+		t.Fatal("expected a URL without a scheme to be rejected")
+	}
+}
+
+func TestGCPoliciesAreOffUntilSet(t *testing.T) {
+	if (GC{}).StaleAfter() != 0 || (GC{}).SizeLimit() != 0 {
+		t.Fatal("expected no stale or size policy by default")
+	}
+	gc := GC{StaleDays: 30, LargerThan: "5GB"}
+	if gc.StaleAfter() != 30*24*time.Hour {
+		t.Fatalf("expected 30 days, got %v", gc.StaleAfter())
+	}
+	if gc.SizeLimit() != 5<<30 {
+		t.Fatalf("expected 5GB, got %d", gc.SizeLimit())
+	}
+
+	if err := validate(&Config{GC: GC{LargerThan: "huge"}}); err == nil || !strings.Contains(err.Error(), "invalid gc.largerThan") {
+		t.Fatalf("expected an unreadable size to be rejected, got %v", err)
+	}
+	if err := validate(&Config{GC: GC{StaleDays: -1}}); err == nil {
+		t.Fatal("expected negative staleDays to be rejected")
+	}
+}
+
+func TestLoadReadsGCPoliciesFromTheConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	config := `{
+  // Optional: what sprout gc collects besides merged worktrees
+  "gc": {
+    "staleDays": 30,
+    "largerThan": "5GB"
+  },
+}`
+	if err := os.WriteFile(filepath.Join(home, ".sprout.json5"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.GC.StaleDays != 30 || cfg.GC.LargerThan != "5GB" {
+		t.Fatalf("expected the gc policies from the file, got %+v", cfg.GC)
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PathEnvVar names a config file to use instead of ~/.sprout.json5
+const PathEnvVar = "SPROUT_CONFIG"
+
+// pathOverride is the config file given with --config, which wins over
+// PathEnvVar
+var pathOverride string
+
+// SetPath makes Load use the config file at path, as sprout
+// --config does; "" goes back to SPROUT_CONFIG or ~/.sprout.json5
+func SetPath(path string) {
+	pathOverride = path
+}
+
+// Path is the config file in use: the one given to SetPath, then the one
+// SPROUT_CONFIG names, then ~/.sprout.json5
+func Path() (string, error) {
+	path := pathOverride
+	if path == "" {
+		path = os.Getenv(PathEnvVar)
+	}
+	if path == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(homeDir, ".sprout.json5"), nil
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(homeDir, rest)
+	}
+	return filepath.Abs(path)
+}
+
+// envOverride is a setting a SPROUT_* environment variable replaces for one
+// run without touching the config file
+type envOverride struct {
+	Env string
+	Key string // the setting in the config file it replaces
+	set func(c *Config, value string) error
+}
+
+// envOverrides are the settings that can be given in the environment. Only
+// plain string and number settings are covered; maps stay in the file
+var envOverrides = []envOverride{
+	{"SPROUT_DEFAULT_COMMAND", "defaultCommand", setString(func(c *Config) *string { return &c.DefaultCommand })},
+	{"SPROUT_RESUME_COMMAND", "resumeCommand", setString(func(c *Config) *string { return &c.ResumeCommand })},
+	{"SPROUT_TIMER_START", "timerStart", setString(func(c *Config) *string { return &c.TimerStart })},
+	{"SPROUT_TIMER_STOP", "timerStop", setString(func(c *Config) *string { return &c.TimerStop })},
+	{"SPROUT_LINEAR_API_KEY", "linearApiKey", setString(func(c *Config) *string { return &c.LinearAPIKey })},
+	{"SPROUT_LINEAR_OAUTH_CLIENT_ID", "linearOAuthClientId", setString(func(c *Config) *string { return &c.LinearOAuthClientID })},
+	{"SPROUT_ISSUE_PROVIDER", "issueProvider", setString(func(c *Config) *string { return &c.IssueProvider })},
+	{"SPROUT_GITHUB_PROVIDER", "githubProvider", setString(func(c *Config) *string { return &c.GitHubProvider })},
+	{"SPROUT_WORKTREE_BASE_PATH", "worktreeBasePath", setString(func(c *Config) *string { return &c.WorktreeBasePath })},
+	{"SPROUT_OPEN_IN", "openIn", setString(func(c *Config) *string { return &c.OpenIn })},
+	{"SPROUT_ENV_TEMPLATE", "envTemplate", setString(func(c *Config) *string { return &c.EnvTemplate })},
+	{"SPROUT_NETWORK_TIMEOUT_SECONDS", "networkTimeoutSeconds", setInt(func(c *Config) *int { return &c.NetworkTimeoutSeconds })},
+	{"SPROUT_GIT_TIMEOUT_SECONDS", "gitTimeoutSeconds", setInt(func(c *Config) *int { return &c.GitTimeoutSeconds })},
+	{"SPROUT_TRASH_DAYS", "trashDays", setInt(func(c *Config) *int { return &c.TrashDays })},
+	{"SPROUT_ISSUE_CACHE_SECONDS", "issueCacheSeconds", setOptionalInt(func(c *Config) **int { return &c.IssueCacheSeconds })},
+	{"SPROUT_ISSUE_REFRESH_SECONDS", "issueRefreshSeconds", setInt(func(c *Config) *int { return &c.IssueRefreshSeconds })},
+}
+
+func setString(field func(c *Config) *string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setInt(field func(c *Config) *int) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("must be a whole number, got %q", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+// setOptionalInt is setInt for a setting whose zero means something other
+// than unset
+func setOptionalInt(field func(c *Config) **int) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		var n int
+		if err := setInt(func(*Config) *int { return &n })(c, value); err != nil {
+			return err
+		}
+		*field(c) = &n
+		return nil
+	}
+}
+
+// applyEnvOverrides replaces the settings in c that a SPROUT_* variable is
+// set for. An empty variable counts as unset
+func applyEnvOverrides(c *Config) error {
+	for _, override := range envOverrides {
+		value := os.Getenv(override.Env)
+		if value == "" {
+			continue
+		}
+		if err := override.set(c, value); err != nil {
+			return fmt.Errorf("%s %w", override.Env, err)
+		}
+	}
+	return nil
+}
+
+// ActiveEnvOverrides names the SPROUT_* variables currently overriding the
+// config file, in the order they're documented
+func ActiveEnvOverrides() []string {
+	var active []string
+	for _, override := range envOverrides {
+		if os.Getenv(override.Env) != "" {
+			active = append(active, override.Env)
+		}
+	}
+	return active
+}
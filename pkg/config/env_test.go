@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPathPrefersTheFlagThenTheEnvironment(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(PathEnvVar, "")
+	t.Cleanup(func() { SetPath("") })
+
+	for _, tc := range []struct {
+		flag, env, expected string
+	}{
+		{"", "", filepath.Join(home, ".sprout.json5")},
+		{"", "~/profiles/work.json5", filepath.Join(home, "profiles", "work.json5")},
+		{"/etc/sprout.json5", "~/profiles/work.json5", "/etc/sprout.json5"},
+	} {
+		SetPath(tc.flag)
+		t.Setenv(PathEnvVar, tc.env)
+		path, err := Path()
+		if err != nil {
+			t.Fatalf("Path failed: %v", err)
+		}
+		if path != tc.expected {
+			t.Errorf("with --config %q and SPROUT_CONFIG %q, expected %s, got %s", tc.flag, tc.env, tc.expected, path)
+		}
+	}
+}
+
+func TestLoadUsesTheChosenFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	profile := filepath.Join(t.TempDir(), "oss.json5")
+	if err := os.WriteFile(profile, []byte(`{defaultCommand: "nvim"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(PathEnvVar, profile)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.DefaultCommand != "nvim" {
+		t.Fatalf("expected the profile's defaultCommand, got %q", cfg.DefaultCommand)
+	}
+}
+
+func TestEnvironmentOverridesTheConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(PathEnvVar, "")
+	if err := os.WriteFile(filepath.Join(home, ".sprout.json5"), []byte(`{defaultCommand: "code .", trashDays: 14}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SPROUT_DEFAULT_COMMAND", "nvim")
+	t.Setenv("SPROUT_OPEN_IN", "tmux")
+	t.Setenv("SPROUT_LINEAR_API_KEY", "lin_api_from_env")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.DefaultCommand != "nvim" || cfg.OpenIn != OpenInTmux || cfg.TrashDays != 14 {
+		t.Fatalf("expected the environment to win where it's set and the file elsewhere, got %+v", cfg)
+	}
+	if active := strings.Join(ActiveEnvOverrides(), ","); active != "SPROUT_DEFAULT_COMMAND,SPROUT_LINEAR_API_KEY,SPROUT_OPEN_IN" {
+		t.Fatalf("unexpected active overrides: %s", active)
+	}
+}
+
+func TestEnvironmentOverridesAreValidated(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(PathEnvVar, "")
+
+	t.Setenv("SPROUT_TRASH_DAYS", "a week")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), `SPROUT_TRASH_DAYS must be a whole number, got "a week"`) {
+		t.Fatalf("expected a bad number to be rejected, got %v", err)
+	}
+
+	t.Setenv("SPROUT_TRASH_DAYS", "")
+	t.Setenv("SPROUT_GITHUB_PROVIDER", "octokit")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), `invalid githubProvider value "octokit"`) {
+		t.Fatalf("expected an unknown provider to be rejected, got %v", err)
+	}
+}
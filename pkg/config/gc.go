@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"sprout/pkg/stats"
+)
+
+// GC is what sprout gc collects besides merged worktrees and trash past
+// trashDays. Either policy is off until it's set
+type GC struct {
+	StaleDays  int    `json:"staleDays,omitempty"`  // worktrees with no commits or changes for this many days
+	LargerThan string `json:"largerThan,omitempty"` // worktrees bigger than this, such as "5GB"
+}
+
+// StaleAfter is how long a worktree goes untouched before gc collects it, 0
+// when it never does
+func (g GC) StaleAfter() time.Duration {
+	return time.Duration(g.StaleDays) * 24 * time.Hour
+}
+
+// SizeLimit is the size above which gc collects a worktree, 0 when it never does
+func (g GC) SizeLimit() int64 {
+	limit, err := stats.ParseSize(g.LargerThan)
+	if g.LargerThan == "" || err != nil {
+		return 0
+	}
+	return limit
+}
+
+func validateGC(gc GC) error {
+	if gc.StaleDays < 0 {
+		return fmt.Errorf("gc.staleDays can't be negative")
+	}
+	if gc.LargerThan != "" {
+		if _, err := stats.ParseSize(gc.LargerThan); err != nil {
+			return fmt.Errorf("invalid gc.largerThan: %w", err)
+		}
+	}
+	return nil
+}
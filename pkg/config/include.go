@@ -0,0 +1,336 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/yosuke-furukawa/json5/encoding/json5"
+)
+
+// Include is another config file layered over the one that names it, such as
+// the settings for one machine. It's given as its path, or as an object with
+// the path and when it applies
+type Include struct {
+	Path string `json:"path"`
+	When When   `json:"when,omitzero"`
+}
+
+func (i *Include) UnmarshalJSON(data []byte) error {
+	if err := json5.Unmarshal(data, &i.Path); err == nil {
+		return nil
+	}
+	type plain Include
+	return json5.Unmarshal(data, (*plain)(i))
+}
+
+func (i Include) MarshalJSON() ([]byte, error) {
+	if i.When == (When{}) {
+		return json.Marshal(i.Path)
+	}
+	type plain Include
+	return json.Marshal(plain(i))
+}
+
+// Includes are the files a config file includes, one path or a list of them
+type Includes []Include
+
+func (i *Includes) UnmarshalJSON(data []byte) error {
+	var one Include
+	if err := json5.Unmarshal(data, &one); err == nil && one.Path != "" {
+		*i = Includes{one}
+		return nil
+	}
+	return json5.Unmarshal(data, (*[]Include)(i))
+}
+
+// Override is a section of settings that only applies when its "when" does,
+// written in the config file as the settings themselves alongside "when"
+type Override map[string]any
+
+// When limits an include or override to some machines. Each condition given
+// has to hold: hostname is a glob such as "work-*", os is one of Go's names
+// for it such as "darwin" or "linux", and env names a variable that has to be
+// set, or is NAME=value for one that has to have that value
+type When struct {
+	Hostname string `json:"hostname,omitempty"`
+	OS       string `json:"os,omitempty"`
+	Env      string `json:"env,omitempty"`
+}
+
+// hostname is the machine's name, swapped out in tests
+var hostname = os.Hostname
+
+// Applies is whether every condition in w holds on this machine
+func (w When) Applies() bool {
+	if w.Hostname != "" {
+		name, err := hostname()
+		if err != nil {
+			return false
+		}
+		short, _, _ := strings.Cut(name, ".")
+		matched, _ := path.Match(strings.ToLower(w.Hostname), strings.ToLower(name))
+		matchedShort, _ := path.Match(strings.ToLower(w.Hostname), strings.ToLower(short))
+		if !matched && !matchedShort {
+			return false
+		}
+	}
+	if w.OS != "" && w.OS != runtime.GOOS {
+		return false
+	}
+	if w.Env != "" {
+		name, value, hasValue := strings.Cut(w.Env, "=")
+		actual := os.Getenv(name)
+		if actual == "" || (hasValue && actual != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Setting is one setting in effect, named by its path through the config
+// such as templates.fix/.base, with where it was set: a config file, an
+// override in one, or the SPROUT_* variable that replaced it
+type Setting struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// Resolved is the config in effect and how it came about
+type Resolved struct {
+	Config   *Config
+	Files    []string  // the config files read, in the order they were layered
+	Missing  []string  // included files that don't exist, which are skipped
+	Settings []Setting // every setting made, by key
+}
+
+// Resolve reads the config file at configPath with everything it includes,
+// then the SPROUT_* environment overrides, noting where each setting came
+// from.
+//
+// Layers are applied in a fixed order, each winning over those before it: a
+// file's own settings, then the files it includes in the order listed (each
+// layered the same way), then its overrides that apply, in order. Objects
+// such as templates are merged key by key; anything else, lists included,
+// replaces what was there, and null removes it
+func Resolve(configPath string) (*Resolved, error) {
+	r := &resolver{values: map[string]any{}, sources: map[string]any{}}
+	if err := r.layer(configPath, nil); err != nil {
+		return nil, err
+	}
+
+	config := DefaultConfig()
+	data, err := json.Marshal(r.values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge config files: %w", err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if err := applyEnvOverrides(config); err != nil {
+		return nil, err
+	}
+	if err := validate(config); err != nil {
+		return nil, err
+	}
+
+	settings := settingsOf(r.values, r.sources, "")
+	if active := ActiveEnvOverrides(); len(active) > 0 {
+		effective := map[string]any{}
+		data, _ := json.Marshal(config)
+		_ = json.Unmarshal(data, &effective)
+		for _, override := range envOverrides {
+			if os.Getenv(override.Env) == "" {
+				continue
+			}
+			settings = withSetting(settings, Setting{Key: override.Key, Value: effective[override.Key], Source: override.Env})
+		}
+	}
+	return &Resolved{Config: config, Files: r.files, Missing: r.missing, Settings: settings}, nil
+}
+
+// resolver layers config files one over another
+type resolver struct {
+	values  map[string]any // the settings so far
+	sources map[string]any // the same shape as values, with where each was set
+	files   []string
+	missing []string
+}
+
+// layer merges the config file at configPath into what's been read so far,
+// then what it includes and its overrides. including is the chain of files
+// that led to it, to catch one including itself
+func (r *resolver) layer(configPath string, including []string) error {
+	for _, seen := range including {
+		if seen == configPath {
+			return fmt.Errorf("config files include each other: %s", strings.Join(append(including, configPath), " -> "))
+		}
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		if len(including) > 0 {
+			r.missing = append(r.missing, configPath)
+		}
+		return nil
+	}
+	r.files = append(r.files, configPath)
+
+	var raw map[string]any
+	if err := json5.Unmarshal(data, &raw); err != nil {
+		return inFile(configPath, including, fmt.Errorf("failed to parse config file: %w", err))
+	}
+	var includes Includes
+	var overrides []Override
+	if err := takeKey(raw, "include", &includes); err != nil {
+		return fmt.Errorf("invalid include in %s: %w", configPath, err)
+	}
+	if err := takeKey(raw, "overrides", &overrides); err != nil {
+		return fmt.Errorf("invalid overrides in %s: %w", configPath, err)
+	}
+	if err := checkKeys(raw); err != nil {
+		return inFile(configPath, including, err)
+	}
+	mergeSettings(r.values, r.sources, raw, configPath)
+
+	chain := append(append([]string(nil), including...), configPath)
+	for _, include := range includes {
+		if !include.When.Applies() {
+			continue
+		}
+		if err := r.layer(includePath(configPath, include.Path), chain); err != nil {
+			return err
+		}
+	}
+	for i, override := range overrides {
+		var when When
+		settings := map[string]any(override)
+		if err := takeKey(settings, "when", &when); err != nil {
+			return fmt.Errorf("invalid when in %s overrides[%d]: %w", configPath, i, err)
+		}
+		if !when.Applies() {
+			continue
+		}
+		if _, ok := settings["include"]; ok {
+			return fmt.Errorf("in %s overrides[%d]: an override can't include files; list them in include with the same when", configPath, i)
+		}
+		if _, ok := settings["overrides"]; ok {
+			return fmt.Errorf("in %s overrides[%d]: overrides can't be nested", configPath, i)
+		}
+		if err := checkKeys(settings); err != nil {
+			return fmt.Errorf("in %s overrides[%d]: %w", configPath, i, err)
+		}
+		mergeSettings(r.values, r.sources, settings, fmt.Sprintf("%s overrides[%d]", configPath, i))
+	}
+	return nil
+}
+
+// inFile says which file err is about, unless it's the config file itself
+func inFile(configPath string, including []string, err error) error {
+	if len(including) == 0 {
+		return err
+	}
+	return fmt.Errorf("in %s: %w", configPath, err)
+}
+
+// takeKey removes key from raw, decoding what it held into target
+func takeKey(raw map[string]any, key string, target any) error {
+	value, ok := raw[key]
+	if !ok {
+		return nil
+	}
+	delete(raw, key)
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// includePath is where an include names, from the directory of the file
+// naming it unless it's absolute or starts with ~/, with $VARIABLES expanded
+// so one line can pick a file per machine
+func includePath(from, name string) string {
+	name = os.ExpandEnv(name)
+	if rest, ok := strings.CutPrefix(name, "~/"); ok {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, rest)
+		}
+	}
+	return resolveFrom(filepath.Dir(from), name)
+}
+
+// resolveFrom is name taken relative to dir unless it's absolute
+func resolveFrom(dir, name string) string {
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
+	}
+	return filepath.Join(dir, name)
+}
+
+// mergeSettings layers from over into, going key by key through objects and
+// noting in sources, which has the same shape as into, where each setting
+// came from. Anything else replaces what was there, and null removes it
+func mergeSettings(into, sources, from map[string]any, source string) {
+	for key, value := range from {
+		switch value := value.(type) {
+		case nil:
+			delete(into, key)
+			delete(sources, key)
+		case map[string]any:
+			existing, isObject := into[key].(map[string]any)
+			existingSources, _ := sources[key].(map[string]any)
+			if !isObject || existingSources == nil {
+				existing, existingSources = map[string]any{}, map[string]any{}
+				into[key], sources[key] = existing, existingSources
+			}
+			mergeSettings(existing, existingSources, value, source)
+		default:
+			into[key] = value
+			sources[key] = source
+		}
+	}
+}
+
+// settingsOf lists the settings in values by key, with where each was set
+func settingsOf(values, sources map[string]any, prefix string) []Setting {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var settings []Setting
+	for _, key := range keys {
+		if object, ok := values[key].(map[string]any); ok {
+			objectSources, _ := sources[key].(map[string]any)
+			settings = append(settings, settingsOf(object, objectSources, prefix+key+".")...)
+			continue
+		}
+		source, _ := sources[key].(string)
+		settings = append(settings, Setting{Key: prefix + key, Value: values[key], Source: source})
+	}
+	return settings
+}
+
+// withSetting is settings with setting in place of the one with its key,
+// kept in key order
+func withSetting(settings []Setting, setting Setting) []Setting {
+	for i := range settings {
+		if settings[i].Key == setting.Key {
+			settings[i] = setting
+			return settings
+		}
+	}
+	settings = append(settings, setting)
+	sort.SliceStable(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
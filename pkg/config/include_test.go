@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file named name into dir, returning its path
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestIncludesAreLayeredInOrderWithProvenance(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SPROUT_TRASH_DAYS", "3")
+	base := writeConfig(t, dir, "base.json5", `{
+		defaultCommand: "code .",
+		openIn: "tmux",
+		trashDays: 14,
+		templates: {"fix/": {base: "main", hooks: ["make setup"]}},
+		include: ["work.json5", "missing.json5"],
+		overrides: [
+			{when: {os: "`+runtime.GOOS+`"}, defaultCommand: "nvim"},
+			{when: {os: "plan9-and-then-some"}, defaultCommand: "ed"},
+		],
+	}`)
+	writeConfig(t, dir, "work.json5", `{
+		defaultCommand: "idea .",
+		openIn: null,
+		templates: {"fix/": {hooks: ["npm ci"]}},
+	}`)
+
+	resolved, err := Resolve(base)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	cfg := resolved.Config
+	if cfg.DefaultCommand != "nvim" || cfg.OpenIn != "" || cfg.TrashDays != 3 {
+		t.Fatalf("expected the override, the removal and the environment to win, got %+v", cfg)
+	}
+	if fix := cfg.Templates["fix/"]; fix.Base != "main" || !reflect.DeepEqual(fix.Hooks, []string{"npm ci"}) {
+		t.Fatalf("expected the template merged key by key and its hooks replaced, got %+v", fix)
+	}
+	work := filepath.Join(dir, "work.json5")
+	if !reflect.DeepEqual(resolved.Files, []string{base, work}) || !reflect.DeepEqual(resolved.Missing, []string{filepath.Join(dir, "missing.json5")}) {
+		t.Fatalf("expected the files read and the one missing, got %v and %v", resolved.Files, resolved.Missing)
+	}
+
+	want := []Setting{
+		{Key: "defaultCommand", Value: "nvim", Source: base + " overrides[0]"},
+		{Key: "templates.fix/.base", Value: "main", Source: base},
+		{Key: "templates.fix/.hooks", Value: []any{"npm ci"}, Source: work},
+		{Key: "trashDays", Value: float64(3), Source: "SPROUT_TRASH_DAYS"},
+	}
+	if !reflect.DeepEqual(resolved.Settings, want) {
+		t.Fatalf("expected settings\n%+v\ngot\n%+v", want, resolved.Settings)
+	}
+}
+
+func TestIncludesCanBeConditional(t *testing.T) {
+	dir := t.TempDir()
+	hostname = func() (string, error) { return "work-laptop.corp.example.com", nil }
+	t.Cleanup(func() { hostname = os.Hostname })
+	t.Setenv("SPROUT_PROFILE", "oss")
+	base := writeConfig(t, dir, "base.json5", `{
+		include: [
+			{path: "laptop.json5", when: {hostname: "work-*"}},
+			{path: "desktop.json5", when: {hostname: "home-*"}},
+			{path: "$SPROUT_PROFILE.json5", when: {env: "SPROUT_PROFILE=oss"}},
+		],
+	}`)
+	writeConfig(t, dir, "laptop.json5", `{openIn: "tmux"}`)
+	writeConfig(t, dir, "desktop.json5", `{defaultCommand: "bash"}`)
+	writeConfig(t, dir, "oss.json5", `{resumeCommand: "claude --resume"}`)
+
+	resolved, err := Resolve(base)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if cfg := resolved.Config; cfg.OpenIn != "tmux" || cfg.DefaultCommand != "" || cfg.ResumeCommand != "claude --resume" {
+		t.Fatalf("expected only the includes for this machine, got %+v", cfg)
+	}
+}
+
+func TestIncludesReportCyclesAndUnknownKeysByFile(t *testing.T) {
+	dir := t.TempDir()
+	a := writeConfig(t, dir, "a.json5", `{include: "b.json5"}`)
+	b := writeConfig(t, dir, "b.json5", `{include: "a.json5"}`)
+	if _, err := Resolve(a); err == nil || !strings.Contains(err.Error(), a+" -> "+b+" -> "+a) {
+		t.Fatalf("expected the include cycle reported, got %v", err)
+	}
+
+	writeConfig(t, dir, "b.json5", `{defaultComand: "nvim"}`)
+	if _, err := Resolve(a); err == nil || !strings.HasPrefix(err.Error(), "in "+b+": unknown config keys found: [defaultComand]") {
+		t.Fatalf("expected the unknown key reported against the included file, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yosuke-furukawa/json5/encoding/json5"
+	"sprout/pkg/editor"
+)
+
+// RepoConfigFileName is the repo-local config file read from the repository root
+const RepoConfigFileName = ".sprout.json5"
+
+// RepoConfig holds settings checked into a repository rather than the user's home directory
+type RepoConfig struct {
+	SparsePaths SparsePathRules `json:"sparsePaths,omitempty"`
+	Open        string          `json:"open,omitempty"`        // editor to open new worktrees in: code, idea or none
+	ReuseWindow bool            `json:"reuseWindow,omitempty"` // open in the editor's current window instead of a new one
+	Submodules  *bool           `json:"submodules,omitempty"`  // update submodules in new worktrees; unset detects .gitmodules
+	LFS         *bool           `json:"lfs,omitempty"`         // run git lfs pull in new worktrees; unset detects LFS attributes
+	GitHooks    string          `json:"gitHooks,omitempty"`    // link or copy the main checkout's hooks into new worktrees
+}
+
+// How new worktrees get the main checkout's hooks, chosen with gitHooks
+const (
+	GitHooksLink = "link"
+	GitHooksCopy = "copy"
+)
+
+var validRepoConfigKeys = map[string]bool{
+	"sparsePaths": true,
+	"open":        true,
+	"reuseWindow": true,
+	"submodules":  true,
+	"lfs":         true,
+	"gitHooks":    true,
+}
+
+// SparsePathRules maps Linear issue labels and projects to the directories a
+// worktree for that issue should check out
+type SparsePathRules struct {
+	Labels   map[string][]string `json:"labels,omitempty"`
+	Projects map[string][]string `json:"projects,omitempty"`
+	Always   []string            `json:"always,omitempty"`
+}
+
+// LoadRepoConfig reads <repoRoot>/.sprout.json5. A missing file yields an empty config.
+func LoadRepoConfig(repoRoot string) (*RepoConfig, error) {
+	repoConfig := &RepoConfig{}
+	if repoRoot == "" {
+		return repoConfig, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(repoRoot, RepoConfigFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return repoConfig, nil
+		}
+		return nil, fmt.Errorf("failed to read repo config file: %w", err)
+	}
+
+	var rawConfig map[string]interface{}
+	if err := json5.Unmarshal(data, &rawConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse repo config file: %w", err)
+	}
+
+	var unknownKeys []string
+	for key := range rawConfig {
+		if !validRepoConfigKeys[key] {
+			unknownKeys = append(unknownKeys, key)
+		}
+	}
+	if len(unknownKeys) > 0 {
+		sort.Strings(unknownKeys)
+		return nil, fmt.Errorf("unknown repo config keys found: %v\n\nValid repo config keys are:\n  - sparsePaths: object (labels, projects and always directory lists for sparse checkouts)\n  - open: string (editor for new worktrees: code, idea or none)\n  - reuseWindow: boolean (reuse the editor's current window)\n  - submodules: boolean (update submodules in new worktrees; detected when unset)\n  - lfs: boolean (run git lfs pull in new worktrees; detected when unset)\n  - gitHooks: string (link or copy the main checkout's hooks into new worktrees)", unknownKeys)
+	}
+
+	if err := json5.Unmarshal(data, repoConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse repo config file: %w", err)
+	}
+	if err := editor.Validate(repoConfig.Open); err != nil {
+		return nil, fmt.Errorf("invalid repo config: %w", err)
+	}
+	switch repoConfig.GitHooks {
+	case "", GitHooksLink, GitHooksCopy:
+	default:
+		return nil, fmt.Errorf("invalid repo config: gitHooks must be %s or %s, got %q", GitHooksLink, GitHooksCopy, repoConfig.GitHooks)
+	}
+
+	return repoConfig, nil
+}
+
+// InferSparseDirectories returns the directories mapped from an issue's labels
+// and project, plus the always-included directories. Label and project names
+// match case-insensitively. Nil is returned when nothing matched, meaning a full checkout.
+func (rc *RepoConfig) InferSparseDirectories(labels []string, project string) []string {
+	if rc == nil {
+		return nil
+	}
+	rules := rc.SparsePaths
+
+	var matched []string
+	for _, label := range labels {
+		matched = append(matched, lookupFold(rules.Labels, label)...)
+	}
+	if project != "" {
+		matched = append(matched, lookupFold(rules.Projects, project)...)
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	return uniqueDirectories(append(matched, rules.Always...))
+}
+
+func lookupFold(mapping map[string][]string, name string) []string {
+	if directories, ok := mapping[name]; ok {
+		return directories
+	}
+	for key, directories := range mapping {
+		if strings.EqualFold(key, name) {
+			return directories
+		}
+	}
+	return nil
+}
+
+func uniqueDirectories(directories []string) []string {
+	seen := make(map[string]bool, len(directories))
+	var result []string
+	for _, directory := range directories {
+		directory = strings.Trim(strings.TrimSpace(directory), "/")
+		if directory == "" || seen[directory] {
+			continue
+		}
+		seen[directory] = true
+		result = append(result, directory)
+	}
+	return result
+}
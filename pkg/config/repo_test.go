@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInferSparseDirectories(t *testing.T) {
+	repoConfig := &RepoConfig{
+		SparsePaths: SparsePathRules{
+			Labels: map[string][]string{
+				"team-payments": {"services/payments/"},
+				"team-web":      {"web", "libs/ui"},
+			},
+			Projects: map[string][]string{
+				"Checkout": {"services/payments", "services/cart"},
+			},
+			Always: []string{"libs/ui", "tools"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		labels   []string
+		project  string
+		expected []string
+	}{
+		{"label", []string{"team-payments"}, "", []string{"services/payments", "libs/ui", "tools"}},
+		{"label case-insensitive", []string{"Team-Web"}, "", []string{"web", "libs/ui", "tools"}},
+		{"label and project deduplicated", []string{"team-payments"}, "Checkout", []string{"services/payments", "services/cart", "libs/ui", "tools"}},
+		{"no match", []string{"bug"}, "Other", nil},
+		{"no labels or project", nil, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := repoConfig.InferSparseDirectories(tt.labels, tt.project)
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+
+	var missing *RepoConfig
+	if dirs := missing.InferSparseDirectories([]string{"team-payments"}, ""); dirs != nil {
+		t.Fatalf("expected nil repo config to infer nothing, got %v", dirs)
+	}
+}
+
+func TestLoadRepoConfig(t *testing.T) {
+	repoRoot := t.TempDir()
+
+	repoConfig, err := LoadRepoConfig(repoRoot)
+	if err != nil {
+		t.Fatalf("expected missing repo config to load, got %v", err)
+	}
+	if len(repoConfig.SparsePaths.Labels) != 0 {
+		t.Fatalf("expected empty repo config, got %+v", repoConfig)
+	}
+
+	content := `{
+  // monorepo layout
+  sparsePaths: {
+    labels: { "team-payments": ["services/payments"] },
+    always: ["libs"],
+  },
+}`
+	if err := os.WriteFile(filepath.Join(repoRoot, RepoConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repoConfig, err = LoadRepoConfig(repoRoot)
+	if err != nil {
+		t.Fatalf("failed to load repo config: %v", err)
+	}
+	expected := []string{"services/payments", "libs"}
+	if dirs := repoConfig.InferSparseDirectories([]string{"team-payments"}, ""); !reflect.DeepEqual(dirs, expected) {
+		t.Fatalf("expected %v, got %v", expected, dirs)
+	}
+
+	if repoConfig.Submodules != nil || repoConfig.LFS != nil {
+		t.Fatalf("expected submodules and lfs to be left for detection, got %+v", repoConfig)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoRoot, RepoConfigFileName), []byte(`{submodules: true, lfs: false}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repoConfig, err = LoadRepoConfig(repoRoot)
+	if err != nil {
+		t.Fatalf("failed to load repo config: %v", err)
+	}
+	if repoConfig.Submodules == nil || !*repoConfig.Submodules || repoConfig.LFS == nil || *repoConfig.LFS {
+		t.Fatalf("expected submodules on and lfs off, got %+v", repoConfig)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoRoot, RepoConfigFileName), []byte(`{gitHooks: "move"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRepoConfig(repoRoot); err == nil || !strings.Contains(err.Error(), "gitHooks must be link or copy") {
+		t.Fatalf("expected invalid gitHooks error, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoRoot, RepoConfigFileName), []byte(`{defaultCommand: "code ."}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRepoConfig(repoRoot); err == nil || !strings.Contains(err.Error(), "defaultCommand") {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+}
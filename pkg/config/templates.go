@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Template customises worktrees whose branch starts with its prefix, the key
+// it's configured under in the templates section
+type Template struct {
+	Base           string   `json:"base,omitempty"`           // branch to start from instead of the default branch
+	SparseProfile  string   `json:"sparseProfile,omitempty"`  // sparse profile, saved with sprout sparse set, to check out
+	Hooks          []string `json:"hooks,omitempty"`          // shell commands run in each new worktree
+	DefaultCommand string   `json:"defaultCommand,omitempty"` // replaces defaultCommand for these worktrees
+	Labels         []string `json:"labels,omitempty"`         // issue labels, such as Bug, that pick this template
+}
+
+// GetDefaultCommand is the template's default command split into arguments,
+// or nil when it doesn't override defaultCommand
+func (t Template) GetDefaultCommand() []string {
+	return parseConfiguredCommand(t.DefaultCommand)
+}
+
+// Templates are worktree templates keyed by the branch prefix they apply to
+type Templates map[string]Template
+
+// Prefixes lists the configured template prefixes in name order
+func (t Templates) Prefixes() []string {
+	prefixes := make([]string, 0, len(t))
+	for prefix := range t {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// Match finds the template for a new branch: the longest prefix the branch
+// starts with, or else the first template sharing one of labels
+func (t Templates) Match(branchName string, labels []string) (string, Template, bool) {
+	match := ""
+	for _, prefix := range t.Prefixes() {
+		if strings.HasPrefix(branchName, prefix) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+	if match != "" {
+		return match, t[match], true
+	}
+
+	for _, prefix := range t.Prefixes() {
+		for _, want := range t[prefix].Labels {
+			for _, label := range labels {
+				if strings.EqualFold(want, label) {
+					return prefix, t[prefix], true
+				}
+			}
+		}
+	}
+	return "", Template{}, false
+}
+
+// ApplyTemplatePrefix puts prefix in front of branchName unless it's already there
+func ApplyTemplatePrefix(prefix, branchName string) string {
+	if strings.HasPrefix(branchName, prefix) {
+		return branchName
+	}
+	return prefix + branchName
+}
+
+func validateTemplates(templates Templates) error {
+	for prefix := range templates {
+		if strings.TrimSpace(prefix) == "" {
+			return fmt.Errorf("templates can't have an empty branch prefix")
+		}
+	}
+	return nil
+}
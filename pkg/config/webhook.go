@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Events a webhook can be sent, named in webhook.events
+const (
+	WebhookWorktreeCreated = "worktreeCreated"
+	WebhookWorktreeDeleted = "worktreeDeleted"
+	WebhookPRMerged        = "prMerged"
+)
+
+// WebhookEvents lists every event a webhook can be sent
+var WebhookEvents = []string{WebhookWorktreeCreated, WebhookWorktreeDeleted, WebhookPRMerged}
+
+// Webhook is where sprout posts a JSON payload as worktrees come and go and
+// their PRs merge, such as a Slack incoming webhook
+type Webhook struct {
+	URL    string   `json:"url,omitempty"`
+	Events []string `json:"events,omitempty"` // which of WebhookEvents to send; all of them when empty
+}
+
+// Sends reports whether event should be posted to the webhook
+func (w Webhook) Sends(event string) bool {
+	return w.URL != "" && (len(w.Events) == 0 || slices.Contains(w.Events, event))
+}
+
+func validateWebhook(webhook Webhook) error {
+	if webhook.URL != "" && !strings.HasPrefix(webhook.URL, "https://") && !strings.HasPrefix(webhook.URL, "http://") {
+		return fmt.Errorf("invalid webhook.url %q (it must start with https:// or http://)", webhook.URL)
+	}
+	for _, event := range webhook.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return fmt.Errorf("invalid webhook event %q (supported: %s)", event, strings.Join(WebhookEvents, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Package detach runs a worktree's command in the background, in a process
+// group of its own so it outlives sprout and can be stopped as a whole
+package detach
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Process is a command Start left running: the process group it leads, and
+// when it started, so Stop can tell it from a later process given the same ID
+type Process struct {
+	PID     int
+	Started string // as the system reports it, only for comparing; empty when it couldn't be read
+}
+
+// StartFunc starts command in dir without waiting for it, sending its output
+// to logPath, and returns the process it started
+type StartFunc func(dir string, command []string, logPath string) (Process, error)
+
+// Start runs command in dir in a new process group, with its output appended
+// to logPath. sprout can exit straight away and leave it running
+func Start(dir string, command []string, logPath string) (Process, error) {
+	if len(command) == 0 {
+		return Process{}, fmt.Errorf("no command to run")
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return Process{}, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	log, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return Process{}, fmt.Errorf("failed to open log: %w", err)
+	}
+	defer log.Close()
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = log
+	cmd.Stderr = log
+	cmd.SysProcAttr = newGroup()
+	if err := cmd.Start(); err != nil {
+		return Process{}, fmt.Errorf("failed to start %s: %w", command[0], err)
+	}
+	process := Process{PID: cmd.Process.Pid, Started: processStart(cmd.Process.Pid)}
+	// Nothing waits on it; when sprout exits it's left to init
+	_ = cmd.Process.Release()
+	return process, nil
+}
+
+// Stop ends the process group Start began. One that has already exited
+// isn't an error, and nor is one whose ID now belongs to a process that
+// started at another time, which is left alone. So is one recorded without
+// a start time, since there's no telling it from such a process
+func Stop(process Process) error {
+	if process.PID <= 0 || process.Started == "" || processStart(process.PID) != process.Started {
+		return nil
+	}
+	if err := stopGroup(process.PID); err != nil {
+		return fmt.Errorf("failed to stop process %d: %w", process.PID, err)
+	}
+	return nil
+}
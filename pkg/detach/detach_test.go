@@ -0,0 +1,83 @@
+//go:build !windows
+
+package detach
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartLeavesTheCommandRunningUntilStopped(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "logs", "run.log")
+
+	script := `trap 'echo stopped; exit 0' TERM; echo started in "$PWD"; while :; do sleep 0.05; done`
+	process, err := Start(dir, []string{"sh", "-c", script}, logPath)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !logSays(logPath, "started in") {
+		t.Fatal("expected the command's output in the log")
+	}
+	if log, _ := os.ReadFile(logPath); !strings.Contains(string(log), filepath.Base(dir)) {
+		t.Fatalf("expected the command run in %s, got %q", dir, log)
+	}
+
+	if err := Stop(process); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if !logSays(logPath, "stopped") {
+		t.Fatalf("expected process %d told to stop", process.PID)
+	}
+}
+
+func TestStopLeavesAProcessThatStartedAtAnotherTime(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "run.log")
+	script := `trap 'echo stopped; exit 0' TERM; echo started; while :; do sleep 0.05; done`
+	process, err := Start(t.TempDir(), []string{"sh", "-c", script}, logPath)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !logSays(logPath, "started") {
+		t.Fatal("expected the command's output in the log")
+	}
+	if process.Started == "" {
+		t.Fatal("expected the start time read")
+	}
+	t.Cleanup(func() { Stop(process) })
+
+	// As if the recorded process had exited and its ID been reused
+	if err := Stop(Process{PID: process.PID, Started: process.Started + "0"}); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := Stop(Process{PID: process.PID}); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if log, _ := os.ReadFile(logPath); strings.Contains(string(log), "stopped") {
+		t.Fatal("expected a process started at another time left running")
+	}
+}
+
+func TestStopIgnoresAProcessThatHasGone(t *testing.T) {
+	if err := Stop(Process{}); err != nil {
+		t.Fatalf("expected no process to be fine, got %v", err)
+	}
+	// Far beyond any real process ID
+	if err := Stop(Process{PID: 1 << 30, Started: "1"}); err != nil {
+		t.Fatalf("expected a process that's gone to be fine, got %v", err)
+	}
+}
+
+func logSays(logPath, text string) bool {
+	for range 100 {
+		if log, _ := os.ReadFile(logPath); strings.Contains(string(log), text) {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return false
+}
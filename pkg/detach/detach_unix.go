@@ -0,0 +1,21 @@
+//go:build !windows
+
+package detach
+
+import (
+	"errors"
+	"syscall"
+)
+
+func newGroup() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+// stopGroup asks every process in the group to terminate
+func stopGroup(pid int) error {
+	err := syscall.Kill(-pid, syscall.SIGTERM)
+	if errors.Is(err, syscall.ESRCH) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package detach
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func newGroup() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// stopGroup kills the process; Windows has no signal for the whole group
+func stopGroup(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return nil
+	}
+	if err := process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
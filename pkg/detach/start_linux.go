@@ -0,0 +1,23 @@
+package detach
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processStart is when pid started, in clock ticks since boot, or empty when
+// there's no such process
+func processStart(pid int) string {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return ""
+	}
+	// The command name in brackets can hold spaces, so count fields after it:
+	// the start time is the 22nd field, the 20th after the name
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	if len(fields) < 20 {
+		return ""
+	}
+	return fields[19]
+}
@@ -0,0 +1,19 @@
+//go:build !linux && !windows
+
+package detach
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// processStart is when pid started, as ps reports it, or empty when there's
+// no such process
+func processStart(pid int) string {
+	output, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
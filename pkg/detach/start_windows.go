@@ -0,0 +1,23 @@
+//go:build windows
+
+package detach
+
+import (
+	"strconv"
+
+	"golang.org/x/sys/windows"
+)
+
+// processStart is when pid was created, or empty when there's no such process
+func processStart(pid int) string {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(handle)
+	var created, exited, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(handle, &created, &exited, &kernel, &user); err != nil {
+		return ""
+	}
+	return strconv.FormatInt(created.Nanoseconds(), 10)
+}
@@ -0,0 +1,102 @@
+package editor
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// None disables opening an editor
+const None = "none"
+
+// Editor describes how to open a directory with an editor's command line launcher
+type Editor struct {
+	Name       string
+	Binary     string
+	ReuseFlag  string // flag that opens the path in the last active window
+	NewWinFlag string // flag that forces a new window
+}
+
+var editors = map[string]Editor{
+	"code": {Name: "code", Binary: "code", ReuseFlag: "--reuse-window", NewWinFlag: "--new-window"},
+	"idea": {Name: "idea", Binary: "idea"},
+}
+
+// Names returns the supported editor names, including none
+func Names() []string {
+	names := []string{None}
+	for name := range editors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks that name is a supported editor or none
+func Validate(name string) error {
+	if name == "" || name == None {
+		return nil
+	}
+	if _, ok := editors[name]; !ok {
+		return fmt.Errorf("unknown editor %q (supported: %s)", name, strings.Join(Names(), ", "))
+	}
+	return nil
+}
+
+// Command returns the command line that opens path in the named editor, or nil for none
+func Command(name, path string, reuseWindow bool) ([]string, error) {
+	if err := Validate(name); err != nil {
+		return nil, err
+	}
+	e, ok := editors[name]
+	if !ok {
+		return nil, nil
+	}
+
+	args := []string{e.Binary}
+	if reuseWindow && e.ReuseFlag != "" {
+		args = append(args, e.ReuseFlag)
+	} else if !reuseWindow && e.NewWinFlag != "" {
+		args = append(args, e.NewWinFlag)
+	}
+	return append(args, path), nil
+}
+
+// LauncherInterface defines the editor operations used by the CLI
+type LauncherInterface interface {
+	Open(name, path string, reuseWindow bool) error
+	Installed() []string
+}
+
+// Launcher opens editors using their launchers on PATH
+type Launcher struct{}
+
+func (l *Launcher) Open(name, path string, reuseWindow bool) error {
+	args, err := Command(name, path, reuseWindow)
+	if err != nil || len(args) == 0 {
+		return err
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return fmt.Errorf("%s launcher not found on PATH", args[0])
+	}
+	if output, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to open %s: %s", name, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Installed returns the supported editors whose launchers are on PATH
+func (l *Launcher) Installed() []string {
+	var installed []string
+	for _, name := range Names() {
+		e, ok := editors[name]
+		if !ok {
+			continue
+		}
+		if _, err := exec.LookPath(e.Binary); err == nil {
+			installed = append(installed, name)
+		}
+	}
+	return installed
+}
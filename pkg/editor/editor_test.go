@@ -0,0 +1,37 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		editor      string
+		reuseWindow bool
+		expected    []string
+	}{
+		{"code new window", "code", false, []string{"code", "--new-window", "/wt/feature"}},
+		{"code reuse window", "code", true, []string{"code", "--reuse-window", "/wt/feature"}},
+		{"idea", "idea", true, []string{"idea", "/wt/feature"}},
+		{"none", "none", false, nil},
+		{"unset", "", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := Command(tt.editor, "/wt/feature", tt.reuseWindow)
+			if err != nil {
+				t.Fatalf("Command returned error: %v", err)
+			}
+			if !reflect.DeepEqual(args, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, args)
+			}
+		})
+	}
+
+	if _, err := Command("emacs", "/wt/feature", false); err == nil {
+		t.Fatalf("expected unknown editor to be rejected")
+	}
+}
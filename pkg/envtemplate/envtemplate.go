@@ -0,0 +1,125 @@
+package envtemplate
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// OutputFileName is the file rendered into each new worktree
+const OutputFileName = ".env.local"
+
+const (
+	basePort       = 3000
+	portsPerBlock  = 10
+	portBlockCount = 500
+)
+
+// Vars are the values available to an env template, e.g. {{.Branch}} or {{port 1}}
+type Vars struct {
+	Branch         string
+	Issue          string
+	WorktreePath   string
+	RepoName       string
+	RepoRoot       string
+	Port           int    // first port of a block of ten reserved for this worktree
+	ComposeProject string // docker compose project name, unique to this worktree
+}
+
+// PortFor derives the first port of a stable block of ten for a worktree, so
+// that each worktree's dev servers listen on different ports
+func PortFor(worktreePath string) int {
+	h := fnv.New32a()
+	h.Write([]byte(worktreePath))
+	return basePort + int(h.Sum32()%portBlockCount)*portsPerBlock
+}
+
+// FreePort is the first port of the block PortFor gives worktreePath, or of
+// the next block along that isn't in used. It's an error when every block
+// is used, rather than handing out ports another worktree holds
+func FreePort(worktreePath string, used map[int]bool) (int, error) {
+	port := PortFor(worktreePath)
+	for i := 0; i < portBlockCount; i++ {
+		if !used[port] {
+			return port, nil
+		}
+		port += portsPerBlock
+		if port >= basePort+portBlockCount*portsPerBlock {
+			port = basePort
+		}
+	}
+	return 0, fmt.Errorf("all %d blocks of ports from %d are held by other worktrees; prune some to free theirs", portBlockCount, basePort)
+}
+
+var composeProjectUnsafe = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// ComposeProject names the docker compose project for branch's worktree in
+// repoName, in the letters, digits, dashes and underscores compose allows
+func ComposeProject(repoName, branch string) string {
+	name := composeProjectUnsafe.ReplaceAllString(strings.ToLower(repoName+"-"+branch), "-")
+	return strings.Trim(name, "-_")
+}
+
+// Environ is vars as environment variables for hooks: SPROUT_PORT,
+// SPROUT_BRANCH, SPROUT_WORKTREE and COMPOSE_PROJECT_NAME, which docker
+// compose reads
+func Environ(vars Vars) []string {
+	env := []string{
+		fmt.Sprintf("SPROUT_PORT=%d", vars.Port),
+		"SPROUT_BRANCH=" + vars.Branch,
+		"SPROUT_WORKTREE=" + vars.WorktreePath,
+	}
+	if vars.ComposeProject != "" {
+		env = append(env, "COMPOSE_PROJECT_NAME="+vars.ComposeProject)
+	}
+	return env
+}
+
+// Render executes the template at templatePath with vars
+func Render(templatePath string, vars Vars) ([]byte, error) {
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(templatePath)).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"port": func(offset int) (int, error) {
+				if offset < 0 || offset >= portsPerBlock {
+					return 0, fmt.Errorf("port offset %d is outside the worktree's block of %d", offset, portsPerBlock)
+				}
+				return vars.Port + offset, nil
+			},
+		}).
+		Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env template: %w", err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, vars); err != nil {
+		return nil, fmt.Errorf("failed to render env template: %w", err)
+	}
+	return rendered.Bytes(), nil
+}
+
+// WriteIfMissing renders the template into the worktree's .env.local, leaving
+// an existing file untouched so local edits survive re-running create
+func WriteIfMissing(templatePath string, vars Vars) error {
+	outputPath := filepath.Join(vars.WorktreePath, OutputFileName)
+	if _, err := os.Stat(outputPath); err == nil {
+		return nil
+	}
+
+	rendered, err := Render(templatePath, vars)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, rendered, 0644)
+}
@@ -0,0 +1,116 @@
+package envtemplate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPortForIsStableAndStartsABlock(t *testing.T) {
+	port := PortFor("/worktrees/eng-1")
+
+	if port != PortFor("/worktrees/eng-1") {
+		t.Fatal("expected the same worktree to get the same port")
+	}
+	if port < basePort || port >= basePort+portBlockCount*portsPerBlock || (port-basePort)%portsPerBlock != 0 {
+		t.Fatalf("expected the first port of a block, got %d", port)
+	}
+}
+
+func TestFreePortSkipsUsedBlocks(t *testing.T) {
+	port := PortFor("/worktrees/eng-1")
+
+	free, err := FreePort("/worktrees/eng-1", map[int]bool{port: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := port + portsPerBlock
+	if next >= basePort+portBlockCount*portsPerBlock {
+		next = basePort
+	}
+	if free != next {
+		t.Fatalf("expected the next block along, %d, got %d", next, free)
+	}
+}
+
+func TestFreePortWrapsAroundToTheFirstBlock(t *testing.T) {
+	used := make(map[int]bool)
+	for i := 1; i < portBlockCount; i++ {
+		used[basePort+i*portsPerBlock] = true
+	}
+
+	free, err := FreePort("/worktrees/eng-1", used)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free != basePort {
+		t.Fatalf("expected the only free block, %d, got %d", basePort, free)
+	}
+}
+
+func TestFreePortIsAnErrorWhenEveryBlockIsUsed(t *testing.T) {
+	used := make(map[int]bool)
+	for i := 0; i < portBlockCount; i++ {
+		used[basePort+i*portsPerBlock] = true
+	}
+
+	if port, err := FreePort("/worktrees/eng-1", used); err == nil {
+		t.Fatalf("expected an error rather than a taken port, got %d", port)
+	}
+}
+
+func TestComposeProjectKeepsOnlyWhatComposeAllows(t *testing.T) {
+	if name := ComposeProject("Web App", "feature/ENG-1 Login"); name != "web-app-feature-eng-1-login" {
+		t.Fatalf("unexpected project name %q", name)
+	}
+}
+
+func TestRenderFillsInPortsFromTheBlock(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), ".env.template")
+	if err := os.WriteFile(templatePath, []byte("API_PORT={{port 0}}\nWEB_PORT={{port 1}}\nBRANCH={{.Branch}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := Render(templatePath, Vars{Branch: "eng-1", Port: 3040})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rendered) != "API_PORT=3040\nWEB_PORT=3041\nBRANCH=eng-1\n" {
+		t.Fatalf("unexpected render %q", rendered)
+	}
+}
+
+func TestRenderRejectsPortsOutsideTheBlock(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), ".env.template")
+	if err := os.WriteFile(templatePath, []byte("PORT={{port 10}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Render(templatePath, Vars{Port: 3040}); err == nil || !strings.Contains(err.Error(), "outside the worktree's block") {
+		t.Fatalf("expected the offset rejected, got %v", err)
+	}
+}
+
+func TestWriteIfMissingLeavesAnEditedFile(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, ".env.template")
+	if err := os.WriteFile(templatePath, []byte("PORT={{port 0}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree := filepath.Join(dir, "eng-1")
+	if err := os.Mkdir(worktree, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, OutputFileName), []byte("PORT=9999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteIfMissing(templatePath, Vars{WorktreePath: worktree, Port: 3040}); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(worktree, OutputFileName)); string(content) != "PORT=9999\n" {
+		t.Fatalf("expected the edited file kept, got %q", content)
+	}
+}
@@ -0,0 +1,178 @@
+// Package events fans what sprout does out to whatever wants to hear of it,
+// such as the history log, the webhook and embedding tools. A Bus hands each
+// subscriber its events in order, either on a goroutine of its own, so a
+// slow subscriber holds up neither the publisher nor the others, or inline
+// before Publish returns, for subscribers whose order against the
+// publisher's own output matters
+package events
+
+import "sync"
+
+// Bus fans each published event out to its subscribers. The zero Bus is
+// ready to use
+type Bus struct {
+	// OnPanic is told of a handler that panicked, with the event it was
+	// handling and what it panicked with; ignored when nil
+	OnPanic func(event, recovered any)
+
+	mu          sync.Mutex
+	next        int
+	subscribers map[int]*subscriber
+}
+
+// Subscribe calls handle with every event published until the returned
+// function is called, in order on a goroutine of its own. A handler that
+// panics is recovered, reported to OnPanic, and still hears of later events.
+// Unsubscribing waits for the events already published to be handled
+func (b *Bus) Subscribe(handle func(event any)) (unsubscribe func()) {
+	return b.subscribe(handle, false)
+}
+
+// SubscribeInline calls handle with every event published until the
+// returned function is called, before Publish returns. A handler that
+// panics is recovered as with Subscribe
+func (b *Bus) SubscribeInline(handle func(event any)) (unsubscribe func()) {
+	return b.subscribe(handle, true)
+}
+
+func (b *Bus) subscribe(handle func(any), inline bool) func() {
+	s := &subscriber{handle: handle, onPanic: b.onPanic, inline: inline, done: make(chan struct{})}
+	s.ready = sync.NewCond(&s.mu)
+	if inline {
+		close(s.done)
+	} else {
+		go s.run()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subscribers == nil {
+		b.subscribers = make(map[int]*subscriber)
+	}
+	id := b.next
+	b.next++
+	b.subscribers[id] = s
+	return func() {
+		b.mu.Lock()
+		delete(b.subscribers, id)
+		b.mu.Unlock()
+		s.close()
+	}
+}
+
+// Publish hands event to every subscriber, returning once the inline ones
+// have handled it
+func (b *Bus) Publish(event any) {
+	var inline []*subscriber
+	b.mu.Lock()
+	for _, s := range b.subscribers {
+		if s.inline {
+			inline = append(inline, s)
+		} else {
+			s.queue(event)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, s := range inline {
+		s.deliver(event)
+	}
+}
+
+// Flush waits for every subscriber to handle the events published so far
+func (b *Bus) Flush() {
+	b.mu.Lock()
+	subscribers := make([]*subscriber, 0, len(b.subscribers))
+	for _, s := range b.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	b.mu.Unlock()
+
+	for _, s := range subscribers {
+		s.flush()
+	}
+}
+
+func (b *Bus) onPanic(event, recovered any) {
+	if b.OnPanic != nil {
+		b.OnPanic(event, recovered)
+	}
+}
+
+// subscriber hands the events queued for it to its handler one at a time
+type subscriber struct {
+	handle  func(any)
+	onPanic func(any, any)
+	inline  bool // handed events by Publish itself, with nothing queued
+
+	mu       sync.Mutex
+	ready    *sync.Cond // broadcast when an event is queued or handled, or the subscriber closes
+	pending  []any
+	handling bool
+	closed   bool
+	done     chan struct{} // closed once everything queued has been handled
+}
+
+func (s *subscriber) queue(event any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.pending = append(s.pending, event)
+		s.ready.Broadcast()
+	}
+}
+
+// flush waits for the events queued so far to be handled
+func (s *subscriber) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.pending) > 0 || s.handling {
+		s.ready.Wait()
+	}
+}
+
+// close stops the subscriber taking events, returning once those already
+// queued have been handled
+func (s *subscriber) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.ready.Broadcast()
+	s.mu.Unlock()
+	<-s.done
+}
+
+func (s *subscriber) run() {
+	defer close(s.done)
+	for {
+		s.mu.Lock()
+		for len(s.pending) == 0 && !s.closed {
+			s.ready.Wait()
+		}
+		if len(s.pending) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		event := s.pending[0]
+		s.pending = s.pending[1:]
+		s.handling = true
+		s.mu.Unlock()
+
+		s.deliver(event)
+
+		s.mu.Lock()
+		s.handling = false
+		s.ready.Broadcast()
+		s.mu.Unlock()
+	}
+}
+
+// deliver hands event to the handler, recovering if it panics so the
+// subscriber carries on with the next
+func (s *subscriber) deliver(event any) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.onPanic(event, recovered)
+		}
+	}()
+	s.handle(event)
+}
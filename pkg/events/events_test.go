@@ -0,0 +1,59 @@
+package events
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFlushWaitsForBackgroundSubscribers(t *testing.T) {
+	var bus Bus
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var handled []string
+	stop := bus.Subscribe(func(event any) {
+		<-release
+		mu.Lock()
+		handled = append(handled, event.(string))
+		mu.Unlock()
+	})
+	defer stop()
+
+	bus.Publish("one")
+	bus.Publish("two")
+	close(release)
+	bus.Flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(handled, ", ") != "one, two" {
+		t.Fatalf("Expected both events handled once flushed, got %v", handled)
+	}
+}
+
+func TestSubscribeInlineHandlesEventsBeforePublishReturns(t *testing.T) {
+	var bus Bus
+	var panicked []any
+	bus.OnPanic = func(event, recovered any) { panicked = append(panicked, event) }
+
+	var handled []string
+	stop := bus.SubscribeInline(func(event any) {
+		if event == "broken" {
+			panic("can't handle it")
+		}
+		handled = append(handled, event.(string))
+	})
+
+	bus.Publish("first")
+	if strings.Join(handled, ", ") != "first" {
+		t.Fatalf("Expected the event handled before Publish returned, got %v", handled)
+	}
+	bus.Publish("broken")
+	bus.Publish("last")
+	stop()
+	bus.Publish("unheard")
+
+	if strings.Join(handled, ", ") != "first, last" || len(panicked) != 1 || panicked[0] != "broken" {
+		t.Fatalf("Expected the panic reported and later events handled, got %v and %v", handled, panicked)
+	}
+}
@@ -0,0 +1,382 @@
+package git
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sprout/pkg/config"
+	"sprout/pkg/metadata"
+)
+
+// ArchiveDirName is the directory among the worktrees that archives are kept in
+const ArchiveDirName = ".archive"
+
+const (
+	archiveManifest  = "archive.json"
+	archiveBundle    = "commits.bundle"
+	archivePatch     = "changes.patch"
+	archiveUntracked = "untracked.tar.gz"
+)
+
+// Archive records what ArchiveWorktree saved of a worktree before removing it
+type Archive struct {
+	Branch    string    `json:"branch"`
+	Head      string    `json:"head"`            // the commit the worktree had checked out
+	Base      string    `json:"base"`            // the branch unmerged commits were counted against
+	Commits   int       `json:"commits"`         // unmerged commits, kept in commits.bundle
+	Changes   bool      `json:"changes"`         // uncommitted changes to tracked files, kept in changes.patch
+	Untracked int       `json:"untracked"`       // untracked files, kept in untracked.tar.gz
+	Issue     string    `json:"issue,omitempty"` // the issue the branch was linked to
+	CreatedAt time.Time `json:"createdAt"`
+	Dir       string    `json:"-"`
+}
+
+// ArchiveWorktree saves a worktree's unmerged commits as a bundle, its
+// uncommitted changes as a patch and its untracked files as a tarball, then
+// removes the worktree and its branch. RestoreWorktree brings it back.
+func (wm *WorktreeManager) ArchiveWorktree(branchName string) (*Archive, error) {
+	if branchName == "" {
+		return nil, fmt.Errorf("branch name cannot be empty")
+	}
+
+	cfg, err := wm.loadConfig()
+	if err != nil {
+		wm.presenter().Warning(fmt.Sprintf("failed to load config, using default worktree path: %v", err))
+	}
+	worktreePath := wm.resolveWorktreePath(cfg, branchName)
+	if !isValidWorktree(worktreePath) {
+		return nil, fmt.Errorf("worktree does not exist: %s", branchName)
+	}
+	wt, err := wm.findWorktree(branchName)
+	if err != nil {
+		return nil, err
+	}
+	// Checked before anything is written, so a refusal leaves no archive behind
+	if reason := wt.keptBecause(); reason == "pinned" {
+		return nil, fmt.Errorf("%s is pinned; sprout unpin %s first to archive it", branchName, branchName)
+	} else if reason != "" {
+		return nil, fmt.Errorf("%s is %s, so it isn't archived", branchName, reason)
+	}
+
+	archive := &Archive{Branch: branchName, Issue: wm.metadata.IssueForBranch(branchName), Dir: wm.archiveDir(cfg, branchName), CreatedAt: time.Now().UTC()}
+	if _, err := os.Stat(archive.Dir); err == nil {
+		return nil, fmt.Errorf("%s is already archived at %s; restore it with sprout restore %s first", branchName, archive.Dir, branchName)
+	}
+
+	if err := wm.saveArchive(archive, worktreePath); err != nil {
+		return nil, err
+	}
+
+	// The archive already holds everything worth keeping
+	if err := wm.PruneWorktree(branchName, PruneOptions{Permanent: true}); err != nil {
+		return archive, fmt.Errorf("archived to %s, but %w", archive.Dir, err)
+	}
+	wm.publish(metadata.HistoryEvent{Kind: metadata.HistoryWorktreeArchived, Branch: branchName, Path: worktreePath, Detail: "to " + archive.Dir})
+	return archive, nil
+}
+
+// saveArchive writes what's worth keeping of the worktree at worktreePath
+// into archive.Dir, leaving nothing half-written behind if it fails
+func (wm *WorktreeManager) saveArchive(archive *Archive, worktreePath string) error {
+	head, err := wm.gitCommand(worktreePath, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("failed to read the worktree's commit: %w", err)
+	}
+	archive.Head = strings.TrimSpace(string(head))
+	if archive.Base, err = wm.getBaseBranch(); err != nil {
+		return fmt.Errorf("failed to determine base branch: %w", err)
+	}
+
+	if err := os.MkdirAll(archive.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	if err := wm.writeArchive(archive, worktreePath); err != nil {
+		// The worktree hasn't been touched, so nothing is lost
+		os.RemoveAll(archive.Dir)
+		return err
+	}
+	return nil
+}
+
+func (wm *WorktreeManager) writeArchive(archive *Archive, worktreePath string) error {
+	count, err := wm.gitCommand(worktreePath, "rev-list", "--count", archive.Base+"..HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("failed to count unmerged commits: %w", err)
+	}
+	archive.Commits, _ = strconv.Atoi(strings.TrimSpace(string(count)))
+	if archive.Commits > 0 {
+		bundle := filepath.Join(archive.Dir, archiveBundle)
+		if output, err := wm.gitCommand(wm.repoRoot, "bundle", "create", bundle, "refs/heads/"+archive.Branch, "^"+archive.Base).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to bundle unmerged commits: %w\nOutput: %s", err, string(output))
+		}
+	}
+
+	patch, err := wm.gitCommand(worktreePath, "diff", "--binary", "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("failed to save uncommitted changes: %w", err)
+	}
+	if len(patch) > 0 {
+		archive.Changes = true
+		if err := os.WriteFile(filepath.Join(archive.Dir, archivePatch), patch, 0644); err != nil {
+			return fmt.Errorf("failed to save uncommitted changes: %w", err)
+		}
+	}
+
+	untracked, err := wm.gitCommand(worktreePath, "ls-files", "--others", "--exclude-standard", "-z").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	var files []string
+	for _, file := range strings.Split(string(untracked), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	if len(files) > 0 {
+		archive.Untracked = len(files)
+		if err := writeTarball(filepath.Join(archive.Dir, archiveUntracked), worktreePath, files); err != nil {
+			return fmt.Errorf("failed to save untracked files: %w", err)
+		}
+	}
+
+	manifest, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(archive.Dir, archiveManifest), manifest, 0644)
+}
+
+// RestoreWorktree recreates an archived worktree with its branch, uncommitted
+// changes and untracked files, then deletes the archive
+func (wm *WorktreeManager) RestoreWorktree(branchName string) (string, error) {
+	if branchName == "" {
+		return "", fmt.Errorf("branch name cannot be empty")
+	}
+
+	cfg, err := wm.loadConfig()
+	if err != nil {
+		wm.presenter().Warning(fmt.Sprintf("failed to load config, using default worktree path: %v", err))
+	}
+	dir := wm.archiveDir(cfg, branchName)
+	data, err := os.ReadFile(filepath.Join(dir, archiveManifest))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no archive found for %s in %s", branchName, filepath.Dir(dir))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read archive: %w", err)
+	}
+	var archive Archive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return "", fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	worktreePath, err := wm.restoreArchive(cfg, &archive, dir)
+	if err != nil {
+		return worktreePath, err
+	}
+	wm.publish(metadata.HistoryEvent{Kind: metadata.HistoryWorktreeRestored, Branch: archive.Branch, Path: worktreePath, Detail: "from the archive"})
+	if err := os.RemoveAll(dir); err != nil {
+		wm.presenter().Warning(fmt.Sprintf("restored %s but couldn't remove the archive: %v", archive.Branch, err))
+	}
+	return worktreePath, nil
+}
+
+// restoreArchive recreates the worktree archive describes from the files
+// saveArchive wrote to dir, linking it to the archived issue
+func (wm *WorktreeManager) restoreArchive(cfg *config.Config, archive *Archive, dir string) (string, error) {
+	worktreePath := wm.resolveWorktreePath(cfg, archive.Branch)
+	if _, err := os.Stat(worktreePath); err == nil {
+		return "", fmt.Errorf("%s already exists; remove it before restoring", worktreePath)
+	}
+
+	if err := wm.restoreBranch(archive, dir); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create worktree base directory: %w", err)
+	}
+	if output, err := wm.gitCommand(wm.repoRoot, "worktree", "add", worktreePath, archive.Branch).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to create worktree: %w\nOutput: %s", err, string(output))
+	}
+
+	if archive.Changes {
+		if output, err := wm.gitCommand(worktreePath, "apply", filepath.Join(dir, archivePatch)).CombinedOutput(); err != nil {
+			return worktreePath, fmt.Errorf("restored %s, but its uncommitted changes didn't apply (the patch is still in %s): %w\nOutput: %s", worktreePath, dir, err, string(output))
+		}
+	}
+	if archive.Untracked > 0 {
+		if err := extractTarball(filepath.Join(dir, archiveUntracked), worktreePath); err != nil {
+			return worktreePath, fmt.Errorf("restored %s, but its untracked files didn't unpack (they're still in %s): %w", worktreePath, dir, err)
+		}
+	}
+
+	wm.metadata.RecordCreated(archive.Branch, worktreePath)
+	wm.metadata.LinkIssue(archive.Branch, archive.Issue)
+	return worktreePath, nil
+}
+
+// restoreBranch recreates the archived branch from its bundle, or at the
+// archived commit when it had nothing unmerged
+func (wm *WorktreeManager) restoreBranch(archive *Archive, dir string) error {
+	ref := "refs/heads/" + archive.Branch
+	if wm.branchExists(ref) {
+		tip, err := wm.gitCommand(wm.repoRoot, "rev-parse", ref).Output()
+		if err != nil || strings.TrimSpace(string(tip)) != archive.Head {
+			return fmt.Errorf("branch %s already exists at a different commit; rename or delete it before restoring", archive.Branch)
+		}
+		return nil
+	}
+
+	cmd := wm.gitCommand(wm.repoRoot, "branch", "--", archive.Branch, archive.Head)
+	if archive.Commits > 0 {
+		cmd = wm.gitCommand(wm.repoRoot, "fetch", "--", filepath.Join(dir, archiveBundle), ref+":"+ref)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore branch %s: %w\nOutput: %s", archive.Branch, err, string(output))
+	}
+	return nil
+}
+
+// archiveDir is where branchName's archive lives: an .archive directory
+// alongside the worktrees, or beside the default .worktrees when the
+// configured path puts each branch somewhere of its own
+func (wm *WorktreeManager) archiveDir(cfg *config.Config, branchName string) string {
+	basePath, includesBranch := wm.getWorktreeBasePath(cfg, branchName)
+	if includesBranch {
+		basePath = filepath.Join(filepath.Dir(wm.repoRoot), ".worktrees")
+	}
+	return filepath.Join(basePath, ArchiveDirName, branchName)
+}
+
+// writeTarball packs files, relative to root, into a gzipped tarball at path
+func writeTarball(path, root string, files []string) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		fullPath := filepath.Join(root, file)
+		info, err := os.Lstat(fullPath)
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(fullPath); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(file)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			data, err := os.ReadFile(fullPath)
+			if err != nil {
+				return err
+			}
+			if _, err := tw.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// throughSymlink reports whether name, relative to root, is or is under a
+// symlink already there, so that writing it would land wherever the link
+// points. An earlier entry in the same tarball can be that link
+func throughSymlink(root, name string) bool {
+	path := root
+	for _, part := range strings.Split(filepath.FromSlash(name), string(filepath.Separator)) {
+		path = filepath.Join(path, part)
+		info, err := os.Lstat(path)
+		if err != nil {
+			// Nothing is there yet, so nothing under it either
+			return false
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// localLink is whether a symlink at name pointing to linkname stays inside
+// the directory name is relative to
+func localLink(name, linkname string) bool {
+	if filepath.IsAbs(linkname) || strings.HasPrefix(linkname, "/") {
+		return false
+	}
+	return filepath.IsLocal(filepath.Join(filepath.Dir(filepath.FromSlash(name)), filepath.FromSlash(linkname)))
+}
+
+// extractTarball unpacks a tarball written by writeTarball into root
+func extractTarball(path, root string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !filepath.IsLocal(header.Name) || throughSymlink(root, header.Name) {
+			return fmt.Errorf("refusing to unpack %s outside the worktree", header.Name)
+		}
+		// The worktree's .git file says where its repository is, so one from
+		// the tarball could hand git a crafted config
+		if first, _, _ := strings.Cut(filepath.ToSlash(filepath.Clean(filepath.FromSlash(header.Name))), "/"); strings.EqualFold(first, ".git") {
+			return fmt.Errorf("refusing to unpack %s over the worktree's git files", header.Name)
+		}
+		if header.Typeflag == tar.TypeSymlink && !localLink(header.Name, header.Linkname) {
+			return fmt.Errorf("refusing to unpack %s, a link to %s outside the worktree", header.Name, header.Linkname)
+		}
+		target := filepath.Join(root, filepath.FromSlash(header.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeSymlink:
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(target, data, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
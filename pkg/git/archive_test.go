@@ -0,0 +1,206 @@
+package git
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/metadata"
+)
+
+func TestArchiveAndRestoreRoundTrip(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	basePath := t.TempDir()
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: &config.Config{WorktreeBasePath: basePath}},
+	}
+
+	worktreePath, err := wm.CreateWorktree("feature-archive")
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, "committed.txt"), []byte("committed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, worktreePath, "add", "committed.txt")
+	runGit(t, worktreePath, "commit", "-m", "Unmerged work")
+	head := currentCommit(t, worktreePath, "HEAD")
+	if err := os.WriteFile(filepath.Join(worktreePath, "committed.txt"), []byte("committed\nand changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(worktreePath, "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, "notes", "todo.md"), []byte("- finish\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := wm.ArchiveWorktree("feature-archive")
+	if err != nil {
+		t.Fatalf("ArchiveWorktree failed: %v", err)
+	}
+	if archive.Commits != 1 || !archive.Changes || archive.Untracked != 1 || archive.Head != head {
+		t.Fatalf("Expected 1 commit, changes and 1 untracked file at %s, got %+v", head, archive)
+	}
+	if archive.Dir != filepath.Join(basePath, ArchiveDirName, "feature-archive") {
+		t.Fatalf("Unexpected archive directory %s", archive.Dir)
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Fatalf("Expected the worktree to be removed, stat returned %v", err)
+	}
+	if wm.branchExists("refs/heads/feature-archive") {
+		t.Fatal("Expected the branch to be deleted")
+	}
+	if _, err := wm.ArchiveWorktree("feature-archive"); err == nil {
+		t.Fatal("Expected archiving a removed worktree to fail")
+	}
+
+	restoredPath, err := wm.RestoreWorktree("feature-archive")
+	if err != nil {
+		t.Fatalf("RestoreWorktree failed: %v", err)
+	}
+	if restoredPath != worktreePath {
+		t.Fatalf("Expected the worktree back at %s, got %s", worktreePath, restoredPath)
+	}
+	if restored := currentCommit(t, restoredPath, "HEAD"); restored != head {
+		t.Fatalf("Expected HEAD %s, got %s", head, restored)
+	}
+	changed, err := os.ReadFile(filepath.Join(restoredPath, "committed.txt"))
+	if err != nil || string(changed) != "committed\nand changed\n" {
+		t.Fatalf("Expected the uncommitted change back, got %q (%v)", changed, err)
+	}
+	info, err := os.Stat(filepath.Join(restoredPath, "notes", "todo.md"))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("Expected the untracked file back with its mode, got %v (%v)", info, err)
+	}
+	if _, err := os.Stat(archive.Dir); !os.IsNotExist(err) {
+		t.Fatalf("Expected the archive to be removed after restoring, stat returned %v", err)
+	}
+
+	if _, err := wm.RestoreWorktree("feature-archive"); err == nil || !strings.Contains(err.Error(), "no archive found") {
+		t.Fatalf("Expected restoring twice to find no archive, got %v", err)
+	}
+}
+
+func TestArchivingAPinnedWorktreeLeavesNoArchive(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	basePath := t.TempDir()
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: &config.Config{WorktreeBasePath: basePath}},
+		metadata:     metadata.NewStoreWithPath(repoRoot, filepath.Join(t.TempDir(), "metadata.json")),
+	}
+	worktreePath, err := wm.CreateWorktree("feature-pinned")
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	if err := wm.PinWorktree("feature-pinned"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := wm.ArchiveWorktree("feature-pinned"); err == nil || !strings.Contains(err.Error(), "sprout unpin feature-pinned") {
+		t.Fatalf("Expected archiving to be refused until unpinned, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(basePath, ArchiveDirName, "feature-pinned")); !os.IsNotExist(err) {
+		t.Fatalf("Expected no archive written, stat returned %v", err)
+	}
+	if _, err := os.Stat(worktreePath); err != nil {
+		t.Fatalf("Expected the worktree kept, stat returned %v", err)
+	}
+}
+
+func TestExtractTarballRefusesToWriteThroughASymlink(t *testing.T) {
+	outside := t.TempDir()
+	file := filepath.Join(t.TempDir(), "untracked.tar.gz")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "ssh", Typeflag: tar.TypeSymlink, Linkname: outside}); err != nil {
+		t.Fatal(err)
+	}
+	payload := "ssh-ed25519 AAAA attacker\n"
+	if err := tw.WriteHeader(&tar.Header{Name: "ssh/authorized_keys", Typeflag: tar.TypeReg, Mode: 0600, Size: int64(len(payload))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(payload)); err != nil {
+		t.Fatal(err)
+	}
+	for _, closer := range []interface{ Close() error }{tw, gz, f} {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := extractTarball(file, t.TempDir()); err == nil || !strings.Contains(err.Error(), "outside the worktree") {
+		t.Fatalf("Expected unpacking through the symlink to be refused, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "authorized_keys")); err == nil {
+		t.Fatal("Expected nothing written outside the worktree")
+	}
+}
+
+func TestExtractTarballRefusesGitFilesAndLinksOutOfTheWorktree(t *testing.T) {
+	for name, header := range map[string]tar.Header{
+		"the gitdir pointer":   {Name: ".git", Typeflag: tar.TypeReg, Mode: 0644},
+		"a file under .git":    {Name: ".GIT/config", Typeflag: tar.TypeReg, Mode: 0644},
+		"an absolute link":     {Name: "keys", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+		"a link escaping root": {Name: "docs/keys", Typeflag: tar.TypeSymlink, Linkname: "../../etc"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "untracked.tar.gz")
+			writeTestTarball(t, file, header)
+			root := t.TempDir()
+
+			if err := extractTarball(file, root); err == nil {
+				t.Fatalf("Expected %s to be refused", header.Name)
+			}
+			if entries, _ := os.ReadDir(root); len(entries) != 0 {
+				t.Fatalf("Expected nothing unpacked, got %v", entries)
+			}
+		})
+	}
+}
+
+func TestExtractTarballKeepsLinksWithinTheWorktree(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "untracked.tar.gz")
+	writeTestTarball(t, file, tar.Header{Name: "docs/latest", Typeflag: tar.TypeSymlink, Linkname: "../notes/v2"})
+	root := t.TempDir()
+
+	if err := extractTarball(file, root); err != nil {
+		t.Fatalf("extractTarball failed: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(root, "docs", "latest")); err != nil || target != "../notes/v2" {
+		t.Fatalf("Expected the link unpacked, got %q, %v", target, err)
+	}
+}
+
+// writeTestTarball writes a gzipped tarball of empty entries to path
+func writeTestTarball(t *testing.T, path string, headers ...tar.Header) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, header := range headers {
+		if err := tw.WriteHeader(&header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, closer := range []interface{ Close() error }{tw, gz, f} {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
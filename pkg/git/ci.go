@@ -0,0 +1,57 @@
+package git
+
+import (
+	"sync"
+
+	"sprout/pkg/progress"
+)
+
+// LookUpCIStatuses fills in the CI status of each worktree with an open PR,
+// for listings that show it. ListWorktrees leaves it out, as looking it up
+// asks GitHub about every open PR
+func (wm *WorktreeManager) LookUpCIStatuses(worktrees []Worktree) {
+	wm.applyCIStatuses(worktrees, nil)
+}
+
+// applyCIStatuses looks up the CI status of each worktree with an open PR,
+// several at a time. CI status is only shown alongside the PR, so one that
+// can't be looked up is left blank rather than failing the listing
+func (wm *WorktreeManager) applyCIStatuses(worktrees []Worktree, presenter progress.Presenter) {
+	if wm.githubClient == nil {
+		return
+	}
+
+	var jobs []int
+	for i := range worktrees {
+		if worktrees[i].PRStatus == "Open" && worktrees[i].Commit != "" {
+			jobs = append(jobs, i)
+		}
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	jobCh := make(chan int)
+	var wg sync.WaitGroup
+	for range min(maxConcurrentTUIStatusChecks, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobCh {
+				// Each worker writes only its own worktrees
+				progress.Step(presenter, wm.githubClient.CIStatusCommand(worktrees[i].Commit), func() error {
+					status, err := wm.githubClient.GetCIStatus(worktrees[i].Commit)
+					if err == nil {
+						worktrees[i].CIStatus = status
+					}
+					return err
+				})
+			}
+		}()
+	}
+	for _, i := range jobs {
+		jobCh <- i
+	}
+	close(jobCh)
+	wg.Wait()
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"sprout/pkg/github"
+)
+
+func TestCIStatusIsLookedUpForOpenPRsOnly(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		prs      string
+		wantCI   string
+		wantAsks int32
+	}{
+		{name: "open", prs: `[{"state":"OPEN"}]`, wantCI: github.CIFailing, wantAsks: 2},
+		{name: "no-pr", prs: `[]`, wantCI: "", wantAsks: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := setupRepoWithFeatureWorktree(t, "feature-search")
+
+			var asks atomic.Int32
+			runner := func(dir string, name string, args ...string) ([]byte, error) {
+				if name != "gh" || args[0] != "api" {
+					return []byte(tc.prs), nil
+				}
+				asks.Add(1)
+				if filepath.Base(args[1]) == "status" {
+					return []byte(`{"state":"pending","total_count":0}`), nil
+				}
+				return []byte(`{"check_runs":[{"status":"completed","conclusion":"failure"}]}`), nil
+			}
+			wm := &WorktreeManager{
+				repoRoot:     tempDir,
+				githubClient: github.NewClientWithRunnerAndCachePath(tempDir, runner, filepath.Join(t.TempDir(), "pr-status-cache.json")),
+			}
+
+			worktrees, err := wm.ListWorktreesForTUIWithProgress(nil)
+			if err != nil {
+				t.Fatalf("ListWorktreesForTUIWithProgress returned error: %v", err)
+			}
+			for _, wt := range worktrees {
+				if wt.Branch == "feature-search" && wt.CIStatus != tc.wantCI {
+					t.Fatalf("expected CI status %q, got %q", tc.wantCI, wt.CIStatus)
+				}
+			}
+			if got := asks.Load(); got != tc.wantAsks {
+				t.Fatalf("expected %d CI lookups, got %d", tc.wantAsks, got)
+			}
+		})
+	}
+}
+
+func TestLookUpCIStatusesFillsInListedWorktrees(t *testing.T) {
+	tempDir := setupRepoWithFeatureWorktree(t, "feature-search")
+
+	var asks atomic.Int32
+	runner := func(dir string, name string, args ...string) ([]byte, error) {
+		asks.Add(1)
+		return []byte(`{"check_runs":[{"status":"completed","conclusion":"success"}],"state":"success","total_count":1}`), nil
+	}
+	wm := &WorktreeManager{
+		repoRoot:     tempDir,
+		githubClient: github.NewClientWithRunnerAndCachePath(tempDir, runner, filepath.Join(t.TempDir(), "pr-status-cache.json")),
+	}
+
+	worktrees, err := wm.ListWorktrees()
+	if err != nil {
+		t.Fatalf("ListWorktrees returned error: %v", err)
+	}
+	for i := range worktrees {
+		if worktrees[i].CIStatus != "" {
+			t.Fatalf("expected ListWorktrees to leave CI statuses out, got %+v", worktrees[i])
+		}
+		if worktrees[i].Branch == "feature-search" {
+			worktrees[i].PRStatus = "Open"
+		}
+	}
+	asks.Store(0)
+
+	wm.LookUpCIStatuses(worktrees)
+	for _, wt := range worktrees {
+		if wt.Branch == "feature-search" && wt.CIStatus != github.CIPassing {
+			t.Fatalf("expected the open PR's checks passing, got %q", wt.CIStatus)
+		}
+	}
+	if got := asks.Load(); got == 0 {
+		t.Fatal("expected GitHub asked about the open PR's checks")
+	}
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sprout/pkg/metadata"
+)
+
+// BareDirName is where the sprout layout keeps the bare repository, next to
+// one directory per worktree
+const BareDirName = ".bare"
+
+// ClonerInterface clones repositories into the sprout layout
+type ClonerInterface interface {
+	Clone(url, dir string) (*CloneResult, error)
+}
+
+// CloneResult describes a repository cloned into the sprout layout
+type CloneResult struct {
+	Root            string // directory holding the bare repository and worktrees
+	BareDir         string
+	PrimaryWorktree string // worktree checked out on the default branch
+	DefaultBranch   string
+}
+
+// Cloner clones repositories with the git CLI
+type Cloner struct{}
+
+// CloneDirName returns the directory name a clone of url defaults to
+func CloneDirName(url string) string {
+	if name := extractRepoNameFromURL(url); name != "" {
+		return name
+	}
+	return strings.TrimSuffix(filepath.Base(strings.TrimRight(url, "/")), ".git")
+}
+
+// Clone clones url as a bare repository in dir/.bare and checks the default
+// branch out as a worktree beside it, e.g. dir/main
+func (c *Cloner) Clone(url, dir string) (*CloneResult, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if entries, err := os.ReadDir(root); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("%s already exists and is not empty", root)
+	}
+
+	result := &CloneResult{Root: root, BareDir: filepath.Join(root, BareDirName)}
+	if output, err := exec.Command("git", "clone", "--bare", url, result.BareDir).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w\nOutput: %s", url, err, string(output))
+	}
+
+	// A .git file pointing at the bare repository lets git commands run from the root
+	if err := os.WriteFile(filepath.Join(root, ".git"), []byte("gitdir: ./"+BareDirName+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write .git file: %w", err)
+	}
+
+	// Bare clones don't track remote branches, which worktrees rely on for upstreams and merge checks
+	steps := [][]string{
+		{"config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*"},
+		{"fetch", "origin"},
+		{"remote", "set-head", "origin", "--auto"},
+	}
+	for _, args := range steps {
+		if err := runGitIn(result.BareDir, args...); err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := gitOutputIn(result.BareDir, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine the default branch: %w", err)
+	}
+	result.DefaultBranch = output
+	result.PrimaryWorktree = filepath.Join(root, sanitizeBranchName(result.DefaultBranch))
+
+	if err := runGitIn(result.BareDir, "worktree", "add", result.PrimaryWorktree, result.DefaultBranch); err != nil {
+		return nil, err
+	}
+	if err := runGitIn(result.PrimaryWorktree, "branch", "--set-upstream-to", "origin/"+result.DefaultBranch); err != nil {
+		return nil, err
+	}
+
+	// Register the new repository so it shows up in --all-repos and the repo switcher
+	metadata.NewStore(result.PrimaryWorktree).RegisterRepo()
+	return result, nil
+}
+
+// usesBareLayout reports whether repoRoot is a worktree in the sprout layout,
+// with the bare repository in a .bare directory beside it
+func usesBareLayout(repoRoot string) bool {
+	info, err := os.Stat(filepath.Join(filepath.Dir(repoRoot), BareDirName))
+	return err == nil && info.IsDir()
+}
+
+func runGitIn(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %w\nOutput: %s", strings.Join(args, " "), err, string(output))
+	}
+	return nil
+}
+
+func gitOutputIn(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
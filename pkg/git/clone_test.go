@@ -0,0 +1,52 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCloneCreatesBareLayout(t *testing.T) {
+	origin := initTestRepo(t)
+	dir := filepath.Join(t.TempDir(), "project")
+
+	result, err := (&Cloner{}).Clone(origin, dir)
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, BareDirName)); err != nil || !info.IsDir() {
+		t.Fatalf("Expected bare repository at %s", result.BareDir)
+	}
+	if result.PrimaryWorktree != filepath.Join(dir, result.DefaultBranch) {
+		t.Fatalf("Expected primary worktree beside the bare repo, got %s", result.PrimaryWorktree)
+	}
+	if _, err := os.Stat(filepath.Join(result.PrimaryWorktree, "README.md")); err != nil {
+		t.Fatalf("Expected default branch checked out in the primary worktree: %v", err)
+	}
+
+	wm, err := NewWorktreeManagerForRepo(result.PrimaryWorktree)
+	if err != nil {
+		t.Fatalf("Failed to open primary worktree: %v", err)
+	}
+	if got := wm.resolveWorktreePath(nil, "feature"); got != filepath.Join(dir, "feature") {
+		t.Fatalf("Expected new worktrees beside the primary one, got %s", got)
+	}
+
+	if _, err := (&Cloner{}).Clone(origin, dir); err == nil {
+		t.Fatalf("Expected cloning into a non-empty directory to fail")
+	}
+}
+
+func TestCloneDirName(t *testing.T) {
+	cases := map[string]string{
+		"https://github.com/laurenkt/sprout.git": "sprout",
+		"git@github.com:laurenkt/sprout.git":     "sprout",
+		"/srv/git/tools.git/":                    "tools",
+	}
+	for url, want := range cases {
+		if got := CloneDirName(url); got != want {
+			t.Errorf("CloneDirName(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// gitCmd is a git command that's killed once the configured gitTimeoutSeconds
+// pass. Its Run, Output and CombinedOutput release the timer when they return
+// and say when the timeout was what stopped git.
+type gitCmd struct {
+	*exec.Cmd
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+}
+
+// gitCommand prepares git args to run in dir
+func (wm *WorktreeManager) gitCommand(dir string, args ...string) *gitCmd {
+	return wm.gitCommandWithin(wm.gitTimeout, dir, args...)
+}
+
+// gitCommandWithin prepares git args to run in dir, killed once timeout
+// passes unless it's 0
+func (wm *WorktreeManager) gitCommandWithin(timeout time.Duration, dir string, args ...string) *gitCmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	return &gitCmd{Cmd: cmd, ctx: ctx, cancel: cancel, timeout: timeout}
+}
+
+func (c *gitCmd) Run() error {
+	defer c.cancel()
+	return c.explain(c.Cmd.Run())
+}
+
+func (c *gitCmd) Output() ([]byte, error) {
+	defer c.cancel()
+	output, err := c.Cmd.Output()
+	return output, c.explain(err)
+}
+
+func (c *gitCmd) CombinedOutput() ([]byte, error) {
+	defer c.cancel()
+	output, err := c.Cmd.CombinedOutput()
+	return output, c.explain(err)
+}
+
+func (c *gitCmd) explain(err error) error {
+	if err != nil && errors.Is(c.ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s timed out after %s (raise gitTimeoutSeconds to allow longer)", strings.Join(c.Args, " "), c.timeout)
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+package git
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitCommandsStopAtTheTimeout(t *testing.T) {
+	wm := &WorktreeManager{repoRoot: t.TempDir(), gitTimeout: time.Nanosecond}
+
+	_, err := wm.gitCommand(wm.repoRoot, "status", "--porcelain").Output()
+	if err == nil || !strings.Contains(err.Error(), "git status --porcelain timed out after 1ns") {
+		t.Fatalf("Expected a timeout error naming the command, got %v", err)
+	}
+
+	wm.gitTimeout = 0
+	if output, err := wm.gitCommand(wm.repoRoot, "--version").Output(); err != nil || !strings.HasPrefix(string(output), "git version") {
+		t.Fatalf("Expected git to run without a timeout, got %q, %v", output, err)
+	}
+}
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrRemoteAccess is wrapped by the error for an origin that can't be reached
+// without someone typing a password or passphrase
+var ErrRemoteAccess = errors.New("can't reach origin without a prompt")
+
+// remoteCheckTimeout is how long CheckRemoteAccess waits on origin, so a
+// remote that never answers fails the check rather than hanging it
+const remoteCheckTimeout = 15 * time.Second
+
+// remoteCommand prepares git args that talk to origin. Prompts for a
+// password, passphrase or host key fail rather than wait, since inside the
+// TUI no one sees them to answer
+func (wm *WorktreeManager) remoteCommand(args ...string) *gitCmd {
+	cmd := wm.gitCommand(wm.repoRoot, args...)
+	cmd.Env = wm.nonInteractiveEnv()
+	return cmd
+}
+
+// nonInteractiveEnv is the environment with git's prompts turned off. SSH
+// runs in batch mode unless the user has set their own ssh command, which is
+// left to them
+func (wm *WorktreeManager) nonInteractiveEnv() []string {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never")
+	if os.Getenv("GIT_SSH_COMMAND") != "" || os.Getenv("GIT_SSH") != "" {
+		return env
+	}
+	if sshCommand, _ := gitOutputIn(wm.repoRoot, "config", "core.sshCommand"); sshCommand != "" {
+		return env
+	}
+	return append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+}
+
+// CheckRemoteAccess makes sure origin answers without prompting for
+// credentials, so operations that fetch fail fast with a way forward instead
+// of hanging on a prompt. A repository with no origin passes, as nothing is
+// fetched from it
+func (wm *WorktreeManager) CheckRemoteAccess() error {
+	remote, err := wm.gitCommand(wm.repoRoot, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return nil
+	}
+	url := strings.TrimSpace(string(remote))
+
+	timeout := remoteCheckTimeout
+	if wm.gitTimeout > 0 && wm.gitTimeout < timeout {
+		timeout = wm.gitTimeout
+	}
+	cmd := wm.gitCommandWithin(timeout, wm.repoRoot, "ls-remote", "--exit-code", "origin", "HEAD")
+	cmd.Env = wm.nonInteractiveEnv()
+	output, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 2:
+		// An empty repository has no HEAD to list, but was reached
+		return nil
+	case errors.Is(cmd.ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w: %s didn't answer within %s. %s", ErrRemoteAccess, url, timeout, credentialHint(url))
+	}
+	reason := lastLine(string(output))
+	if reason == "" {
+		reason = err.Error()
+	}
+	return fmt.Errorf("%w: %s (%s). %s", ErrRemoteAccess, url, reason, credentialHint(url))
+}
+
+// credentialHint says how to let git reach url without a prompt
+func credentialHint(url string) string {
+	if !isSSHRemote(url) {
+		return "Set up a credential helper (git config --global credential.helper) so git can sign in by itself"
+	}
+	if os.Getenv("SSH_AUTH_SOCK") == "" {
+		return "No SSH agent is running; start one and load your key with ssh-add"
+	}
+	return "Load the key for this host into your SSH agent with ssh-add (ssh-add -l lists those loaded)"
+}
+
+// isSSHRemote is whether git reaches url over SSH, either as an ssh:// URL or
+// the scp-like user@host:path form
+func isSSHRemote(url string) bool {
+	if strings.HasPrefix(url, "ssh://") || strings.HasPrefix(url, "git+ssh://") {
+		return true
+	}
+	if strings.Contains(url, "://") {
+		return false
+	}
+	colon := strings.Index(url, ":")
+	return colon > 1 && !strings.Contains(url[:colon], "/")
+}
+
+// lastLine is the last non-empty line of output, where git puts its reason
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
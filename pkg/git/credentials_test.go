@@ -0,0 +1,73 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/progress"
+)
+
+func TestCheckRemoteAccessPassesForAReachableOrigin(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	wm := &WorktreeManager{repoRoot: repoRoot}
+	if err := wm.CheckRemoteAccess(); err != nil {
+		t.Fatalf("expected a repository with no origin to pass, got %v", err)
+	}
+
+	runGitCommand(t, repoRoot, "remote", "add", "origin", initTestRepo(t))
+	if err := wm.CheckRemoteAccess(); err != nil {
+		t.Fatalf("expected a local origin to pass, got %v", err)
+	}
+}
+
+func TestCheckRemoteAccessFailsFastWithoutCredentials(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	// Nothing listens on port 1, so SSH fails straight away rather than prompting
+	runGitCommand(t, repoRoot, "remote", "add", "origin", "ssh://git@127.0.0.1:1/team/app.git")
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: &config.Config{WorktreeBasePath: t.TempDir()}},
+	}
+
+	err := wm.CheckRemoteAccess()
+	if !errors.Is(err, ErrRemoteAccess) || !strings.Contains(err.Error(), "ssh://git@127.0.0.1:1/team/app.git") {
+		t.Fatalf("expected origin named as unreachable, got %v", err)
+	}
+
+	// Offline, planning carries on from the local refs rather than fetching
+	var warnings bytes.Buffer
+	plan, err := wm.PlanWorktree("feature", CreateOptions{Progress: progress.NewText(&warnings)})
+	if err != nil {
+		t.Fatalf("expected an unreachable origin to fall back to local refs, got %v", err)
+	}
+	if plan.Base != currentBranch(t, repoRoot) || !strings.Contains(warnings.String(), "Warning: can't reach origin") || !strings.Contains(warnings.String(), "starting from the refs already fetched") {
+		t.Errorf("expected the local default branch with a warning, got %s and %q", plan.Base, warnings.String())
+	}
+	plan, err = wm.PlanWorktree("feature", CreateOptions{NoFetch: true})
+	if err != nil {
+		t.Fatalf("expected --no-fetch to plan from local refs, got %v", err)
+	}
+	if plan.Base != currentBranch(t, repoRoot) {
+		t.Errorf("expected the local default branch as base, got %s", plan.Base)
+	}
+}
+
+func TestIsSSHRemote(t *testing.T) {
+	for url, want := range map[string]bool{
+		"git@github.com:laurenkt/sprout.git":     true,
+		"ssh://git@github.com/laurenkt/sprout":   true,
+		"https://github.com/laurenkt/sprout.git": false,
+		"/srv/git/sprout.git":                    false,
+		"../sprout":                              false,
+		`C:\src\sprout`:                          false,
+	} {
+		if got := isSSHRemote(url); got != want {
+			t.Errorf("isSSHRemote(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package git
+
+import (
+	"sprout/pkg/detach"
+	"sprout/pkg/tmux"
+)
+
+// stopDetached stops the command sprout create --detach left running in
+// branch's worktree, before the worktree is removed from under it
+func (wm *WorktreeManager) stopDetached(branchName string) error {
+	run, ok := wm.metadata.Detached(branchName)
+	if !ok {
+		return nil
+	}
+	wm.metadata.ForgetDetached(branchName)
+	if run.Session != "" {
+		return tmux.NewClient().Kill(run.Session)
+	}
+	return detach.Stop(detach.Process{PID: run.PID, Started: run.ProcessStart})
+}
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sprout/pkg/config"
+	"sprout/pkg/detach"
+	"sprout/pkg/metadata"
+	"sprout/pkg/progress"
+)
+
+func TestPruneStopsTheCommandLeftRunningInTheWorktree(t *testing.T) {
+	wm := newConfiguredTestManager(t, &config.Config{})
+	path, err := wm.CreateWorktree("dev-server")
+	if err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	logPath := filepath.Join(t.TempDir(), "dev-server.log")
+	process, err := detach.Start(path, []string{"sh", "-c", `trap 'echo stopped; exit 0' TERM; echo started; while :; do sleep 0.05; done`}, logPath)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	// Pruning before the trap is set would kill it without a word
+	waitForLog(t, logPath, "started")
+	wm.metadata.RecordDetached("dev-server", metadata.DetachedRun{PID: process.PID, ProcessStart: process.Started, Command: "dev", Log: logPath})
+
+	if err := wm.PruneWorktree("dev-server", PruneOptions{Progress: progress.Discard}); err != nil {
+		t.Fatalf("PruneWorktree failed: %v", err)
+	}
+
+	waitForLog(t, logPath, "stopped")
+	if _, ok := wm.metadata.Detached("dev-server"); ok {
+		t.Fatal("expected the detached command forgotten once stopped")
+	}
+}
+
+// waitForLog waits for a detached command to write want to its log
+func waitForLog(t *testing.T, logPath, want string) {
+	t.Helper()
+	for range 250 {
+		if log, _ := os.ReadFile(logPath); strings.Contains(string(log), want) {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("expected the detached command to log %q", want)
+}
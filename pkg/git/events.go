@@ -0,0 +1,57 @@
+package git
+
+import (
+	"sync"
+
+	"sprout/pkg/metadata"
+)
+
+// prMergedEvent is published the first time a listing finds wt's PR merged
+type prMergedEvent struct {
+	wt Worktree
+}
+
+// publishers are the managers that have published events, for
+// WaitForNotifications to wait on
+var publishers struct {
+	mu       sync.Mutex
+	managers []*WorktreeManager
+}
+
+// publish hands event, a metadata.HistoryEvent or a prMergedEvent, to the
+// history log, which writes it before publish returns, and to the webhook,
+// which is posted to in the background so a slow endpoint holds nothing up
+func (wm *WorktreeManager) publish(event any) {
+	wm.eventsOnce.Do(func() {
+		wm.events.SubscribeInline(func(event any) {
+			if logged, ok := event.(metadata.HistoryEvent); ok {
+				wm.metadata.LogEvent(logged)
+			}
+		})
+		wm.events.Subscribe(wm.postToWebhook)
+		publishers.mu.Lock()
+		publishers.managers = append(publishers.managers, wm)
+		publishers.mu.Unlock()
+	})
+	wm.events.Publish(event)
+}
+
+// WaitForNotifications waits for the webhook to be posted everything
+// published so far, by every manager, returning the posts that failed since
+// it was last called. Commands call it before exiting so the posts aren't
+// cut short
+func WaitForNotifications() []error {
+	publishers.mu.Lock()
+	managers := append([]*WorktreeManager(nil), publishers.managers...)
+	publishers.mu.Unlock()
+
+	var failed []error
+	for _, wm := range managers {
+		wm.events.Flush()
+		wm.notifyMu.Lock()
+		failed = append(failed, wm.notifyErrs...)
+		wm.notifyErrs = nil
+		wm.notifyMu.Unlock()
+	}
+	return failed
+}
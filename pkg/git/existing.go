@@ -0,0 +1,65 @@
+package git
+
+import "fmt"
+
+// ExistingBranch describes what is already there for a branch sprout is asked to create
+type ExistingBranch struct {
+	Branch       string // the sanitized branch name
+	WorktreePath string // the worktree with the branch checked out, empty when there is none
+	BranchExists bool   // whether the local branch exists
+}
+
+// Found reports whether the branch or a worktree for it already exists
+func (e ExistingBranch) Found() bool {
+	return e.WorktreePath != "" || e.BranchExists
+}
+
+// FindExisting looks for a worktree or local branch matching branchName, so
+// callers can decide what to do before creating anything
+func (wm *WorktreeManager) FindExisting(branchName string) (ExistingBranch, error) {
+	existing := ExistingBranch{Branch: sanitizeBranchName(branchName)}
+	if existing.Branch == "" {
+		return existing, fmt.Errorf("branch name results in empty string after sanitization")
+	}
+
+	worktrees, err := wm.gitWorktrees()
+	if err != nil {
+		return existing, err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == existing.Branch {
+			existing.WorktreePath = wt.Path
+			break
+		}
+	}
+	existing.BranchExists = wm.branchExists("refs/heads/" + existing.Branch)
+	return existing, nil
+}
+
+// maxBranchSuffix is how far FreeBranchName counts before giving up
+const maxBranchSuffix = 99
+
+// existingFinder says what's already there for a branch name
+type existingFinder interface {
+	FindExisting(branchName string) (ExistingBranch, error)
+}
+
+// FreeBranchName is branchName itself when nothing is there for it yet, and
+// otherwise the first of branchName-2, branchName-3 and so on that has
+// neither a branch nor a worktree, for starting afresh beside what exists
+func FreeBranchName(finder existingFinder, branchName string) (string, error) {
+	existing, err := finder.FindExisting(branchName)
+	if err != nil || !existing.Found() {
+		return existing.Branch, err
+	}
+	for suffix := 2; suffix <= maxBranchSuffix; suffix++ {
+		candidate, err := finder.FindExisting(fmt.Sprintf("%s-%d", existing.Branch, suffix))
+		if err != nil {
+			return "", err
+		}
+		if !candidate.Found() {
+			return candidate.Branch, nil
+		}
+	}
+	return "", fmt.Errorf("branches %s-2 to %s-%d all exist already; pick another name", existing.Branch, existing.Branch, maxBranchSuffix)
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFindExistingReportsWorktreesAndBranches(t *testing.T) {
+	repoRoot := setupRepoWithFeatureWorktrees(t, "existing-worktree")
+	runGit(t, repoRoot, "branch", "existing-branch")
+	wm := &WorktreeManager{repoRoot: repoRoot}
+
+	existing, err := wm.FindExisting("existing-worktree")
+	if err != nil {
+		t.Fatalf("FindExisting failed: %v", err)
+	}
+	if filepath.Base(existing.WorktreePath) != "existing-worktree" || !existing.BranchExists {
+		t.Fatalf("Expected the worktree and its branch to be found, got %+v", existing)
+	}
+
+	existing, err = wm.FindExisting("existing-branch")
+	if err != nil {
+		t.Fatalf("FindExisting failed: %v", err)
+	}
+	if existing.WorktreePath != "" || !existing.BranchExists {
+		t.Fatalf("Expected only the branch to be found, got %+v", existing)
+	}
+
+	existing, err = wm.FindExisting("Brand New Branch")
+	if err != nil {
+		t.Fatalf("FindExisting failed: %v", err)
+	}
+	if existing.Found() || existing.Branch != sanitizeBranchName("Brand New Branch") {
+		t.Fatalf("Expected nothing to be found for a new branch, got %+v", existing)
+	}
+}
+
+func TestFreeBranchNameCountsPastTakenSuffixes(t *testing.T) {
+	repoRoot := setupRepoWithFeatureWorktrees(t, "login-fix")
+	runGit(t, repoRoot, "branch", "login-fix-2")
+	wm := &WorktreeManager{repoRoot: repoRoot}
+
+	if name, err := FreeBranchName(wm, "brand-new"); err != nil || name != "brand-new" {
+		t.Fatalf("Expected a free name to be kept, got %q, %v", name, err)
+	}
+	if name, err := FreeBranchName(wm, "login-fix"); err != nil || name != "login-fix-3" {
+		t.Fatalf("Expected login-fix-3 past the worktree and the -2 branch, got %q, %v", name, err)
+	}
+}
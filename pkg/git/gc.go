@@ -0,0 +1,124 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"sprout/pkg/stats"
+)
+
+// Policies sprout gc collects a worktree under, in the order they're checked
+const (
+	GCMerged   = "merged"   // its PR has been merged
+	GCOversize = "oversize" // it's grown past gc.largerThan
+	GCStale    = "stale"    // nothing's been committed to it for gc.staleDays
+)
+
+// GCPolicy is what sprout gc collects besides merged worktrees
+type GCPolicy struct {
+	StaleAfter time.Duration // worktrees without a commit for this long, none when 0
+	LargerThan int64         // worktrees bigger than this many bytes, none when 0
+	Now        time.Time     // when staleness is measured from
+}
+
+// GCCandidate is a worktree sprout gc would prune, and the policy it falls under
+type GCCandidate struct {
+	Worktree Worktree
+	Policy   string // GCMerged, GCOversize or GCStale
+	Detail   string // why, such as "no commits for 45 days" or "6.2 GB"
+}
+
+// GCCandidates lists the worktrees policy collects, each under the first
+// policy it falls foul of. Like prune it leaves the default branch, detached
+// and bare worktrees, and locked or pinned ones alone. Sizes are measured
+// only when LargerThan is set, and reuse cached sizes up to an hour old
+func (wm *WorktreeManager) GCCandidates(policy GCPolicy) ([]GCCandidate, error) {
+	worktrees, err := wm.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	var present []Worktree
+	var paths []string
+	for _, wt := range worktrees {
+		if wt.Path == wm.repoRoot {
+			continue
+		}
+		if _, err := os.Stat(wt.Path); err != nil {
+			continue
+		}
+		present = append(present, wt)
+		paths = append(paths, wt.Path)
+	}
+
+	var sizes map[string]int64
+	if policy.LargerThan > 0 {
+		sizes = stats.CachedDirSizes(wm.metadata, paths, stats.DiskUsageMaxAge)
+	}
+	var candidates []GCCandidate
+	for _, wt := range present {
+		if size, ok := sizes[wt.Path]; ok {
+			wt.DiskUsage = size
+		}
+		if candidate, ok := policy.Collects(wt); ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
+// Collects reports whether sprout gc prunes wt, going by its PR status,
+// DiskUsage and UpdatedAt
+func (policy GCPolicy) Collects(wt Worktree) (GCCandidate, bool) {
+	if wt.keptBecause() != "" {
+		return GCCandidate{}, false
+	}
+	now := policy.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	switch {
+	case wt.PRStatus == "Merged":
+		return GCCandidate{Worktree: wt, Policy: GCMerged, Detail: "PR merged"}, true
+	case policy.LargerThan > 0 && wt.DiskUsage > policy.LargerThan:
+		return GCCandidate{Worktree: wt, Policy: GCOversize, Detail: stats.FormatBytes(wt.DiskUsage)}, true
+	case policy.StaleAfter > 0 && !wt.UpdatedAt.IsZero() && now.Sub(wt.UpdatedAt) > policy.StaleAfter:
+		days := int(now.Sub(wt.UpdatedAt).Hours() / 24)
+		return GCCandidate{Worktree: wt, Policy: GCStale, Detail: fmt.Sprintf("no commits for %d days", days)}, true
+	}
+	return GCCandidate{}, false
+}
+
+// PurgeExpiredTrash empties whatever has been in the trash longer than
+// trashDays, as pruning does on its way, and lists what went, along with
+// why any of it wouldn't. A dry run lists what would go and leaves it
+func (wm *WorktreeManager) PurgeExpiredTrash(dryRun bool) ([]TrashedWorktree, error) {
+	cfg, err := wm.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().Add(-cfg.TrashRetention())
+	var expired []TrashedWorktree
+	for _, entry := range wm.trashedWorktrees(cfg) {
+		if entry.TrashedAt.Before(cutoff) {
+			expired = append(expired, entry)
+		}
+	}
+	if dryRun {
+		return expired, nil
+	}
+	wm.pruneMu.Lock()
+	defer wm.pruneMu.Unlock()
+	var emptied []TrashedWorktree
+	var errs []error
+	for i := range expired {
+		if err := wm.deleteTrashEntry(&expired[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		emptied = append(emptied, expired[i])
+	}
+	return emptied, errors.Join(errs...)
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sprout/pkg/config"
+	"sprout/pkg/progress"
+)
+
+func TestGCPolicyCollects(t *testing.T) {
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	policy := GCPolicy{StaleAfter: 30 * 24 * time.Hour, LargerThan: 5 << 30, Now: now}
+
+	tests := []struct {
+		name   string
+		wt     Worktree
+		policy string
+		detail string
+	}{
+		{"merged", Worktree{Branch: "done", PRStatus: "Merged", UpdatedAt: now}, GCMerged, "PR merged"},
+		{"oversize", Worktree{Branch: "big", DiskUsage: 6 << 30, UpdatedAt: now}, GCOversize, "6.0 GB"},
+		{"stale", Worktree{Branch: "spike", UpdatedAt: now.AddDate(0, 0, -45)}, GCStale, "no commits for 45 days"},
+		{"recent", Worktree{Branch: "wip", UpdatedAt: now.AddDate(0, 0, -3)}, "", ""},
+		{"pinned", Worktree{Branch: "release", PRStatus: "Merged", Pinned: true}, "", ""},
+		{"default branch", Worktree{Branch: "main", UpdatedAt: now.AddDate(-1, 0, 0)}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidate, ok := policy.Collects(tt.wt)
+			if ok != (tt.policy != "") {
+				t.Fatalf("expected collected %v, got %+v", tt.policy != "", candidate)
+			}
+			if candidate.Policy != tt.policy || candidate.Detail != tt.detail {
+				t.Errorf("expected %s (%s), got %s (%s)", tt.policy, tt.detail, candidate.Policy, candidate.Detail)
+			}
+		})
+	}
+
+	if _, ok := (GCPolicy{Now: now}).Collects(Worktree{Branch: "spike", UpdatedAt: now.AddDate(-1, 0, 0)}); ok {
+		t.Error("expected only merged worktrees collected without a stale or size policy")
+	}
+}
+
+func TestPurgeExpiredTrashLeavesRecentTrash(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	cfg := &config.Config{WorktreeBasePath: t.TempDir(), TrashDays: 1}
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: cfg},
+	}
+
+	for _, branch := range []string{"old", "recent"} {
+		if _, err := wm.CreateWorktree(branch); err != nil {
+			t.Fatal(err)
+		}
+		if err := wm.PruneWorktree(branch, PruneOptions{Progress: progress.Discard}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := wm.trashedWorktrees(cfg)[0]
+	old.TrashedAt = time.Now().Add(-48 * time.Hour)
+	if err := writeTrashManifest(&old); err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := wm.PurgeExpiredTrash(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0].Branch != "old" {
+		t.Fatalf("Expected only old to have expired, got %+v", expired)
+	}
+	if _, err := os.Stat(old.Dir); err != nil {
+		t.Fatalf("Expected a dry run to leave the trash, stat returned %v", err)
+	}
+
+	if _, err := wm.PurgeExpiredTrash(false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(old.Dir); !os.IsNotExist(err) {
+		t.Fatalf("Expected the expired trash to be deleted, stat returned %v", err)
+	}
+	if trash := wm.trashedWorktrees(cfg); len(trash) != 1 || trash[0].Branch != "recent" {
+		t.Fatalf("Expected recent left in the trash, got %+v", trash)
+	}
+}
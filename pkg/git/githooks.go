@@ -0,0 +1,196 @@
+package git
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sprout/pkg/config"
+	"sprout/pkg/progress"
+)
+
+// hooksPath is core.hooksPath as the checkout at dir sees it, or "" when
+// hooks live in the git directory every worktree shares
+func hooksPath(dir string) string {
+	path, _ := gitOutputIn(dir, "config", "--get", "core.hooksPath")
+	return path
+}
+
+// isRelativeHooksPath is whether git looks for hooks inside each checkout,
+// rather than somewhere every worktree shares
+func isRelativeHooksPath(path string) bool {
+	return path != "" && !filepath.IsAbs(path) && !strings.HasPrefix(path, "~")
+}
+
+// shareGitHooks gives a new worktree the hooks the main checkout runs.
+// Husky-style setups point core.hooksPath at a directory generated on
+// install, which a fresh checkout doesn't have, so it's linked or copied
+// across; a core.hooksPath the main checkout sets just for itself is set
+// for the worktree too
+func (wm *WorktreeManager) shareGitHooks(worktreePath, mode string, presenter progress.Presenter) error {
+	path, source := wm.planGitHooks(mode)
+	if path == "" {
+		return nil
+	}
+
+	if hooksPath(worktreePath) != path {
+		err := progress.Step(presenter, "Setting core.hooksPath to "+path, func() error {
+			return runGitIn(worktreePath, "config", "--worktree", "core.hooksPath", path)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set core.hooksPath: %w", err)
+		}
+	}
+
+	if source == "" {
+		return nil
+	}
+	target := filepath.Join(worktreePath, path)
+	if _, err := os.Lstat(target); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+	}
+
+	switch mode {
+	case config.GitHooksLink:
+		if err := progress.Step(presenter, "Linking git hooks from "+source, func() error { return os.Symlink(source, target) }); err != nil {
+			return fmt.Errorf("failed to link git hooks: %w", err)
+		}
+		// git status would otherwise list the link as untracked
+		return excludeFromStatus(worktreePath, path)
+	case config.GitHooksCopy:
+		if err := progress.Step(presenter, "Copying git hooks from "+source, func() error { return copyDir(source, target) }); err != nil {
+			return fmt.Errorf("failed to copy git hooks: %w", err)
+		}
+	}
+	return nil
+}
+
+// planGitHooks is the core.hooksPath shareGitHooks gives a new worktree for
+// mode, and the hooks directory it links or copies across when that path is
+// inside the checkout and the main checkout has it. Both are empty when
+// there's nothing to share
+func (wm *WorktreeManager) planGitHooks(mode string) (path, source string) {
+	path = hooksPath(wm.repoRoot)
+	if mode == "" || path == "" {
+		return "", ""
+	}
+	if !isRelativeHooksPath(path) {
+		return path, ""
+	}
+	source = filepath.Join(wm.repoRoot, path)
+	if info, err := os.Stat(source); err != nil || !info.IsDir() {
+		return path, ""
+	}
+	return path, source
+}
+
+// excludeFromStatus adds path to the repository's info/exclude, which every
+// worktree shares, unless it's listed already
+func excludeFromStatus(worktreePath, path string) error {
+	commonDir, err := gitOutputIn(worktreePath, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return fmt.Errorf("failed to find info/exclude: %w", err)
+	}
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(worktreePath, commonDir)
+	}
+	excludeFile := filepath.Join(commonDir, "info", "exclude")
+	pattern := "/" + filepath.ToSlash(filepath.Clean(path))
+
+	existing, err := os.ReadFile(excludeFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(excludeFile), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(excludeFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		pattern = "\n" + pattern
+	}
+	_, err = fmt.Fprintln(f, pattern)
+	return err
+}
+
+// copyDir copies the files under from to to, keeping their modes so hooks
+// stay executable
+func copyDir(from, to string) error {
+	return filepath.WalkDir(from, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(to, rel)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(dest, info.Mode().Perm()|0700)
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, dest)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dest, data, info.Mode().Perm())
+	})
+}
+
+// CheckGitHooks lists the worktrees where git won't run the hooks the main
+// checkout does: core.hooksPath differs, or points at a directory the
+// worktree doesn't have
+func (wm *WorktreeManager) CheckGitHooks() ([]string, error) {
+	path := hooksPath(wm.repoRoot)
+	if path == "" {
+		return nil, nil
+	}
+	worktrees, err := wm.gitWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for _, wt := range worktrees {
+		if wt.Bare || wt.Prunable || wt.Path == wm.repoRoot {
+			continue
+		}
+		if own := hooksPath(wt.Path); own != path {
+			problems = append(problems, fmt.Sprintf("%s: core.hooksPath is %q, not %q as in the main checkout", wt.Name(), own, path))
+			continue
+		}
+		if !isRelativeHooksPath(path) {
+			continue
+		}
+		_, mainErr := os.Stat(filepath.Join(wm.repoRoot, path))
+		if _, err := os.Stat(filepath.Join(wt.Path, path)); err != nil && mainErr == nil {
+			problems = append(problems, fmt.Sprintf("%s: hooks directory %s is missing", wt.Name(), path))
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,97 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+)
+
+// initHuskyRepo sets up a repository whose hooks live in an untracked
+// .husky/_ directory, as husky install leaves them
+func initHuskyRepo(t *testing.T) (string, *WorktreeManager) {
+	repoRoot := initTestRepo(t)
+	hooksDir := filepath.Join(repoRoot, ".husky", "_")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hooksDir, "pre-commit"), []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGitCommand(t, repoRoot, "config", "core.hooksPath", ".husky/_")
+
+	wm, err := NewWorktreeManagerForRepo(repoRoot)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	return repoRoot, wm
+}
+
+func TestCreateWorktreeLinksGitHooks(t *testing.T) {
+	repoRoot, wm := initHuskyRepo(t)
+
+	worktreePath, err := wm.CreateWorktreeWithOptions("linked", CreateOptions{GitHooks: config.GitHooksLink})
+	if err != nil {
+		t.Fatalf("CreateWorktreeWithOptions failed: %v", err)
+	}
+	target, err := os.Readlink(filepath.Join(worktreePath, ".husky", "_"))
+	if err != nil || target != filepath.Join(repoRoot, ".husky", "_") {
+		t.Fatalf("Expected .husky/_ linked to the main checkout's, got %q (%v)", target, err)
+	}
+	exclude, err := os.ReadFile(filepath.Join(repoRoot, ".git", "info", "exclude"))
+	if err != nil || strings.Count(string(exclude), "/.husky/_\n") != 1 {
+		t.Fatalf("Expected the link excluded from git status once, got %q (%v)", exclude, err)
+	}
+	if wm.HasUncommittedChanges(worktreePath) {
+		t.Fatal("Expected the linked hooks not to show as changes")
+	}
+
+	if _, err := wm.CreateWorktreeWithOptions("linked-again", CreateOptions{GitHooks: config.GitHooksLink}); err != nil {
+		t.Fatalf("CreateWorktreeWithOptions failed: %v", err)
+	}
+	exclude, _ = os.ReadFile(filepath.Join(repoRoot, ".git", "info", "exclude"))
+	if strings.Count(string(exclude), "/.husky/_\n") != 1 {
+		t.Fatalf("Expected the exclude pattern added only once, got %q", exclude)
+	}
+}
+
+func TestCreateWorktreeCopiesGitHooks(t *testing.T) {
+	_, wm := initHuskyRepo(t)
+
+	worktreePath, err := wm.CreateWorktreeWithOptions("copied", CreateOptions{GitHooks: config.GitHooksCopy})
+	if err != nil {
+		t.Fatalf("CreateWorktreeWithOptions failed: %v", err)
+	}
+	info, err := os.Lstat(filepath.Join(worktreePath, ".husky", "_", "pre-commit"))
+	if err != nil {
+		t.Fatalf("Expected the hook copied: %v", err)
+	}
+	if info.Mode()&0100 == 0 {
+		t.Fatalf("Expected the copied hook to stay executable, got %v", info.Mode())
+	}
+}
+
+func TestCheckGitHooksReportsWorktreesMissingHooks(t *testing.T) {
+	_, wm := initHuskyRepo(t)
+
+	if problems, err := wm.CheckGitHooks(); err != nil || len(problems) != 0 {
+		t.Fatalf("Expected no problems with only the main checkout, got %v (%v)", problems, err)
+	}
+
+	if _, err := wm.CreateWorktreeWithOptions("linked", CreateOptions{GitHooks: config.GitHooksLink}); err != nil {
+		t.Fatalf("CreateWorktreeWithOptions failed: %v", err)
+	}
+	if _, err := wm.CreateWorktreeWithOptions("plain", CreateOptions{}); err != nil {
+		t.Fatalf("CreateWorktreeWithOptions failed: %v", err)
+	}
+
+	problems, err := wm.CheckGitHooks()
+	if err != nil {
+		t.Fatalf("CheckGitHooks failed: %v", err)
+	}
+	if len(problems) != 1 || problems[0] != "plain: hooks directory .husky/_ is missing" {
+		t.Fatalf("Expected only the plain worktree reported, got %v", problems)
+	}
+}
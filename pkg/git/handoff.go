@@ -0,0 +1,166 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sprout/pkg/metadata"
+)
+
+// HandoffExtension ends the name of the file ExportWorktree writes
+const HandoffExtension = ".sprout.tgz"
+
+// HandoffFileName is the file a branch is exported to unless told otherwise
+func HandoffFileName(branchName string) string {
+	return strings.ReplaceAll(branchName, "/", "-") + HandoffExtension
+}
+
+// ExportWorktree saves a worktree the way ArchiveWorktree does, with the
+// issue its branch is linked to, but packs it all into the single file at
+// path for a teammate to ImportWorktree. The worktree is left as it was. The
+// returned Archive's Dir is the file written
+func (wm *WorktreeManager) ExportWorktree(branchName, path string) (*Archive, error) {
+	if branchName == "" {
+		return nil, fmt.Errorf("branch name cannot be empty")
+	}
+
+	cfg, err := wm.loadConfig()
+	if err != nil {
+		wm.presenter().Warning(fmt.Sprintf("failed to load config, using default worktree path: %v", err))
+	}
+	worktreePath := wm.resolveWorktreePath(cfg, branchName)
+	if !isValidWorktree(worktreePath) {
+		return nil, fmt.Errorf("worktree does not exist: %s", branchName)
+	}
+
+	staging, err := os.MkdirTemp("", "sprout-export-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	archive := &Archive{Branch: branchName, Issue: wm.metadata.IssueForBranch(branchName), Dir: filepath.Join(staging, "handoff"), CreatedAt: time.Now().UTC()}
+	if err := wm.saveArchive(archive, worktreePath); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(archive.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	if err := writeTarball(path, archive.Dir, files); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	archive.Dir = path
+	return archive, nil
+}
+
+// ImportWorktree recreates the worktree exported to the file at path, with
+// its branch name, unmerged commits, uncommitted changes, untracked files and
+// issue link. It returns what was exported and the new worktree's path
+func (wm *WorktreeManager) ImportWorktree(path string) (*Archive, string, error) {
+	staging, err := os.MkdirTemp("", "sprout-import-")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create a staging directory: %w", err)
+	}
+	keepStaging := false
+	defer func() {
+		if !keepStaging {
+			os.RemoveAll(staging)
+		}
+	}()
+
+	if err := extractTarball(path, staging); err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	data, err := os.ReadFile(filepath.Join(staging, archiveManifest))
+	if err != nil {
+		return nil, "", fmt.Errorf("%s isn't a sprout export: %w", path, err)
+	}
+	var archive Archive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, "", fmt.Errorf("%s isn't a sprout export: %w", path, err)
+	}
+	if archive.Branch == "" || archive.Head == "" {
+		return nil, "", fmt.Errorf("%s isn't a sprout export: it names no branch", path)
+	}
+	if err := wm.checkExportedRefs(&archive); err != nil {
+		return nil, "", fmt.Errorf("%s isn't a sprout export: %w", path, err)
+	}
+
+	// The export only carries commits that weren't on its base, so the rest
+	// have to come from origin
+	if !wm.hasExportedHistory(&archive, staging) {
+		if err := wm.CheckRemoteAccess(); err != nil {
+			return &archive, "", fmt.Errorf("%s builds on commits from %s that aren't here: %w", archive.Branch, archive.Base, err)
+		}
+		if err := wm.fetchRemoteBranch(archive.Base); err != nil {
+			return &archive, "", fmt.Errorf("%s builds on commits from %s that aren't here, and fetching them from origin failed: %w", archive.Branch, archive.Base, err)
+		}
+	}
+
+	cfg, err := wm.loadConfig()
+	if err != nil {
+		wm.presenter().Warning(fmt.Sprintf("failed to load config, using default worktree path: %v", err))
+	}
+	worktreePath, err := wm.restoreArchive(cfg, &archive, staging)
+	if err != nil {
+		// A worktree was made but not everything applied; the error says
+		// where the rest is
+		keepStaging = worktreePath != ""
+		return &archive, worktreePath, err
+	}
+	wm.publish(metadata.HistoryEvent{Kind: metadata.HistoryWorktreeRestored, Branch: archive.Branch, Path: worktreePath, Detail: "from " + filepath.Base(path)})
+	return &archive, worktreePath, nil
+}
+
+// checkExportedRefs rejects an export whose branch, base or commit isn't one
+// git would take as such. They come from a file someone else wrote and are
+// passed to git fetch, where a name like --upload-pack=<cmd> would run it
+func (wm *WorktreeManager) checkExportedRefs(archive *Archive) error {
+	names := []string{archive.Branch}
+	if archive.Base != "" {
+		names = append(names, archive.Base)
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, "-") {
+			return fmt.Errorf("%q isn't a branch name", name)
+		}
+		if err := wm.gitCommand(wm.repoRoot, "check-ref-format", "--branch", name).Run(); err != nil {
+			return fmt.Errorf("%q isn't a branch name", name)
+		}
+	}
+	if !isObjectID(archive.Head) {
+		return fmt.Errorf("%q isn't a commit", archive.Head)
+	}
+	return nil
+}
+
+// isObjectID reports whether s is a full SHA-1 or SHA-256 object name
+func isObjectID(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// hasExportedHistory reports whether this repository has every commit
+// needed to restore archive's branch from the files in dir
+func (wm *WorktreeManager) hasExportedHistory(archive *Archive, dir string) bool {
+	if archive.Commits > 0 {
+		return wm.gitCommand(wm.repoRoot, "bundle", "verify", "--", filepath.Join(dir, archiveBundle)).Run() == nil
+	}
+	return wm.gitCommand(wm.repoRoot, "cat-file", "-e", "--", archive.Head+"^{commit}").Run() == nil
+}
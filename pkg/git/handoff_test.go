@@ -0,0 +1,108 @@
+package git
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/metadata"
+)
+
+func TestExportedWorktreeImportsIntoATeammatesClone(t *testing.T) {
+	wm := newConfiguredTestManager(t, &config.Config{})
+	worktreePath, err := wm.CreateWorktree("login-retry")
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	wm.metadata.LinkIssue("login-retry", "spr-7")
+	if err := os.WriteFile(filepath.Join(worktreePath, "retry.go"), []byte("package retry\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, worktreePath, "add", "retry.go")
+	runGit(t, worktreePath, "commit", "-m", "Retry logins")
+	head := currentCommit(t, worktreePath, "HEAD")
+	if err := os.WriteFile(filepath.Join(worktreePath, "README.md"), []byte("half-done\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, "notes.md"), []byte("- try backoff\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), HandoffFileName("login-retry"))
+	exported, err := wm.ExportWorktree("login-retry", file)
+	if err != nil {
+		t.Fatalf("ExportWorktree failed: %v", err)
+	}
+	if exported.Commits != 1 || !exported.Changes || exported.Untracked != 1 || exported.Issue != "SPR-7" || exported.Dir != file {
+		t.Fatalf("Expected 1 commit, changes, 1 untracked file and SPR-7 in %s, got %+v", file, exported)
+	}
+	if !isValidWorktree(worktreePath) {
+		t.Fatal("Expected exporting to leave the worktree alone")
+	}
+
+	// The teammate has their own clone, which has never seen the branch
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGit(t, wm.repoRoot, "clone", "-q", wm.repoRoot, clone)
+	teammate := &WorktreeManager{
+		repoRoot:     clone,
+		repoName:     "clone",
+		configLoader: &config.DefaultLoader{Config: &config.Config{WorktreeBasePath: t.TempDir()}},
+		metadata:     metadata.NewStoreWithPath(clone, filepath.Join(t.TempDir(), "metadata.json")),
+	}
+
+	imported, importedPath, err := teammate.ImportWorktree(file)
+	if err != nil {
+		t.Fatalf("ImportWorktree failed: %v", err)
+	}
+	if imported.Branch != "login-retry" || currentCommit(t, importedPath, "HEAD") != head {
+		t.Fatalf("Expected login-retry at %s, got %s at %s", head, imported.Branch, currentCommit(t, importedPath, "HEAD"))
+	}
+	for name, want := range map[string]string{"README.md": "half-done\n", "notes.md": "- try backoff\n"} {
+		if got, err := os.ReadFile(filepath.Join(importedPath, name)); err != nil || string(got) != want {
+			t.Fatalf("Expected %s to hold %q, got %q (%v)", name, want, got, err)
+		}
+	}
+	if issue := teammate.metadata.IssueForBranch("login-retry"); issue != "SPR-7" {
+		t.Fatalf("Expected the import linked to SPR-7, got %q", issue)
+	}
+
+	if _, _, err := teammate.ImportWorktree(file); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("Expected importing twice to refuse, got %v", err)
+	}
+}
+
+func TestImportRefusesRefsGitWouldReadAsOptions(t *testing.T) {
+	wm := newConfiguredTestManager(t, &config.Config{})
+	marker := filepath.Join(t.TempDir(), "ran")
+	head := strings.Repeat("a", 40)
+
+	for _, archive := range []Archive{
+		{Branch: "login-retry", Base: "--upload-pack=touch " + marker, Head: head},
+		{Branch: "--upload-pack=touch " + marker, Base: "main", Head: head, Commits: 1},
+		{Branch: "login..retry", Base: "main", Head: head},
+		{Branch: "login-retry", Base: "main", Head: "--output=" + marker},
+	} {
+		staging := t.TempDir()
+		manifest, err := json.Marshal(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(staging, archiveManifest), manifest, 0644); err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(t.TempDir(), HandoffFileName("login-retry"))
+		if err := writeTarball(file, staging, []string{archiveManifest}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := wm.ImportWorktree(file); err == nil || !strings.Contains(err.Error(), "isn't a sprout export") {
+			t.Fatalf("Expected %+v to be refused, got %v", archive, err)
+		}
+		if _, err := os.Stat(marker); err == nil {
+			t.Fatalf("Expected nothing to run for %+v", archive)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sprout/pkg/metadata"
+)
+
+// migrationStagingDir holds the working files while the checkout is rearranged
+const migrationStagingDir = ".sprout-migrate"
+
+// MigratorInterface converts a normal clone into the bare-repo-with-worktrees layout
+type MigratorInterface interface {
+	PlanMigration(repoRoot string) (*MigrationPlan, error)
+	Migrate(plan *MigrationPlan, progress func(step string)) error
+}
+
+// MigrationPlan describes how a checkout will be converted
+type MigrationPlan struct {
+	Root            string   // the existing checkout, which becomes the layout's root
+	Branch          string   // branch checked out in the existing checkout
+	WorktreePath    string   // where the existing working files end up
+	LinkedWorktrees []string // other worktrees whose links to the repository need repairing
+	Steps           []string // what Migrate will do, in order
+}
+
+// Migrator rearranges checkouts on disk with the git CLI
+type Migrator struct{}
+
+type migrationStep struct {
+	description string
+	run         func() error
+	undo        func() error // nil when there is nothing to roll back
+}
+
+// PlanMigration checks that repoRoot is a normal clone and works out how to convert it
+func (m *Migrator) PlanMigration(repoRoot string) (*MigrationPlan, error) {
+	info, err := os.Stat(filepath.Join(repoRoot, ".git"))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a git checkout", repoRoot)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is a linked worktree or already uses the bare layout; run sprout migrate from the main checkout", repoRoot)
+	}
+	for _, name := range []string{BareDirName, migrationStagingDir} {
+		if _, err := os.Stat(filepath.Join(repoRoot, name)); err == nil {
+			return nil, fmt.Errorf("%s already contains %s; move it out of the way first", repoRoot, name)
+		}
+	}
+
+	branch, err := gitOutputIn(repoRoot, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("HEAD is detached; check out a branch before migrating")
+	}
+
+	output, err := gitOutputIn(repoRoot, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	plan := &MigrationPlan{
+		Root:         repoRoot,
+		Branch:       branch,
+		WorktreePath: filepath.Join(repoRoot, strings.ReplaceAll(sanitizeBranchName(branch), "/", "-")),
+	}
+	for _, wt := range parseWorktreeList(output) {
+		if wt.Path != repoRoot {
+			plan.LinkedWorktrees = append(plan.LinkedWorktrees, wt.Path)
+		}
+	}
+
+	for _, step := range migrationSteps(plan) {
+		plan.Steps = append(plan.Steps, step.description)
+	}
+	return plan, nil
+}
+
+// Migrate carries out plan, rolling back the steps already taken if one fails
+func (m *Migrator) Migrate(plan *MigrationPlan, progress func(step string)) error {
+	steps := migrationSteps(plan)
+	for i, step := range steps {
+		if err := step.run(); err != nil {
+			if rollbackErr := rollbackMigration(steps[:i]); rollbackErr != nil {
+				return fmt.Errorf("%s: %w; rolling back also failed: %v", step.description, err, rollbackErr)
+			}
+			return fmt.Errorf("%s: %w; all changes were rolled back", step.description, err)
+		}
+		if progress != nil {
+			progress(step.description)
+		}
+	}
+
+	metadata.NewStore(plan.WorktreePath).RegisterRepo()
+	return nil
+}
+
+func rollbackMigration(done []migrationStep) error {
+	for i := len(done) - 1; i >= 0; i-- {
+		if done[i].undo == nil {
+			continue
+		}
+		if err := done[i].undo(); err != nil {
+			return fmt.Errorf("undoing %q: %w", done[i].description, err)
+		}
+	}
+	return nil
+}
+
+func migrationSteps(plan *MigrationPlan) []migrationStep {
+	root := plan.Root
+	gitDir := filepath.Join(root, ".git")
+	bareDir := filepath.Join(root, BareDirName)
+	staging := filepath.Join(root, migrationStagingDir)
+	worktree := plan.WorktreePath
+
+	// Recorded when the worktree is added, so the index can follow the files
+	var worktreeGitDir string
+
+	steps := []migrationStep{
+		{
+			description: fmt.Sprintf("Move the working files, including untracked ones, aside into %s", migrationStagingDir),
+			run: func() error {
+				if err := os.Mkdir(staging, 0755); err != nil {
+					return err
+				}
+				return moveEntries(root, staging, ".git", migrationStagingDir)
+			},
+			undo: func() error {
+				if err := moveEntries(staging, root); err != nil {
+					return err
+				}
+				return os.Remove(staging)
+			},
+		},
+		{
+			description: fmt.Sprintf("Move .git to %s and mark it as a bare repository", BareDirName),
+			run: func() error {
+				if err := os.Rename(gitDir, bareDir); err != nil {
+					return err
+				}
+				return runGitIn(bareDir, "config", "core.bare", "true")
+			},
+			undo: func() error {
+				if err := runGitIn(bareDir, "config", "core.bare", "false"); err != nil {
+					return err
+				}
+				return os.Rename(bareDir, gitDir)
+			},
+		},
+		{
+			description: fmt.Sprintf("Point %s/.git at %s", filepath.Base(root), BareDirName),
+			run: func() error {
+				return os.WriteFile(gitDir, []byte("gitdir: ./"+BareDirName+"\n"), 0644)
+			},
+			undo: func() error {
+				return os.Remove(gitDir)
+			},
+		},
+		{
+			description: fmt.Sprintf("Add a worktree for %s at %s", plan.Branch, worktree),
+			run: func() error {
+				if err := runGitIn(bareDir, "worktree", "add", "--no-checkout", worktree, plan.Branch); err != nil {
+					return err
+				}
+				dir, err := gitOutputIn(worktree, "rev-parse", "--absolute-git-dir")
+				if err != nil {
+					return err
+				}
+				worktreeGitDir = dir
+				return nil
+			},
+			undo: func() error {
+				if err := os.RemoveAll(worktree); err != nil {
+					return err
+				}
+				return runGitIn(bareDir, "worktree", "prune")
+			},
+		},
+		{
+			description: fmt.Sprintf("Move the working files and staged changes into %s", worktree),
+			run: func() error {
+				if err := moveEntries(staging, worktree); err != nil {
+					return err
+				}
+				if err := os.Rename(filepath.Join(bareDir, "index"), filepath.Join(worktreeGitDir, "index")); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+				return os.Remove(staging)
+			},
+			undo: func() error {
+				if err := os.MkdirAll(staging, 0755); err != nil {
+					return err
+				}
+				if err := os.Rename(filepath.Join(worktreeGitDir, "index"), filepath.Join(bareDir, "index")); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+				return moveEntries(worktree, staging, ".git")
+			},
+		},
+	}
+
+	if len(plan.LinkedWorktrees) > 0 {
+		steps = append(steps, migrationStep{
+			description: fmt.Sprintf("Repair the links to %d existing worktree(s)", len(plan.LinkedWorktrees)),
+			run: func() error {
+				return runGitIn(worktree, append([]string{"worktree", "repair"}, plan.LinkedWorktrees...)...)
+			},
+		})
+	}
+
+	return steps
+}
+
+// moveEntries moves everything in from into to, except the named entries
+func moveEntries(from, to string, except ...string) error {
+	entries, err := os.ReadDir(from)
+	if err != nil {
+		return err
+	}
+	skip := make(map[string]bool, len(except))
+	for _, name := range except {
+		skip[name] = true
+	}
+	for _, entry := range entries {
+		if skip[entry.Name()] {
+			continue
+		}
+		if err := os.Rename(filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateConvertsCheckoutToBareLayout(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	branch := currentBranch(t, repoRoot)
+
+	linked := filepath.Join(t.TempDir(), "feature")
+	runGitCommand(t, repoRoot, "worktree", "add", "-b", "feature", linked)
+	if err := os.WriteFile(filepath.Join(repoRoot, "notes.txt"), []byte("untracked"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoRoot, "README.md"), []byte("# Staged"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGitCommand(t, repoRoot, "add", "README.md")
+
+	migrator := &Migrator{}
+	plan, err := migrator.PlanMigration(repoRoot)
+	if err != nil {
+		t.Fatalf("PlanMigration failed: %v", err)
+	}
+	if plan.WorktreePath != filepath.Join(repoRoot, branch) || len(plan.LinkedWorktrees) != 1 {
+		t.Fatalf("Unexpected plan: %+v", plan)
+	}
+
+	var done []string
+	if err := migrator.Migrate(plan, func(step string) { done = append(done, step) }); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(done) != len(plan.Steps) {
+		t.Fatalf("Expected progress for %d steps, got %v", len(plan.Steps), done)
+	}
+
+	if info, err := os.Stat(filepath.Join(repoRoot, BareDirName)); err != nil || !info.IsDir() {
+		t.Fatalf("Expected bare repository in %s", BareDirName)
+	}
+	if content, err := os.ReadFile(filepath.Join(plan.WorktreePath, "notes.txt")); err != nil || string(content) != "untracked" {
+		t.Fatalf("Expected untracked file to be preserved, got %q (%v)", content, err)
+	}
+	if status := gitStatus(t, plan.WorktreePath); !strings.Contains(status, "M  README.md") || !strings.Contains(status, "?? notes.txt") {
+		t.Fatalf("Expected staged and untracked changes to survive, got status:\n%s", status)
+	}
+	if status := gitStatus(t, linked); status != "" {
+		t.Fatalf("Expected linked worktree to keep working, got status:\n%s", status)
+	}
+
+	if _, err := migrator.PlanMigration(plan.WorktreePath); err == nil {
+		t.Fatalf("Expected a migrated worktree to be rejected")
+	}
+}
+
+func TestMigrateRollsBackOnFailure(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	if err := os.WriteFile(filepath.Join(repoRoot, "notes.txt"), []byte("untracked"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	migrator := &Migrator{}
+	plan, err := migrator.PlanMigration(repoRoot)
+	if err != nil {
+		t.Fatalf("PlanMigration failed: %v", err)
+	}
+	// Adding the worktree fails when its directory is already in use
+	plan.WorktreePath = t.TempDir()
+	if err := os.WriteFile(filepath.Join(plan.WorktreePath, "occupied"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrator.Migrate(plan, nil)
+	if err == nil || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("Expected a rolled back failure, got %v", err)
+	}
+
+	if info, err := os.Stat(filepath.Join(repoRoot, ".git")); err != nil || !info.IsDir() {
+		t.Fatalf("Expected .git directory to be restored")
+	}
+	for _, name := range []string{BareDirName, migrationStagingDir} {
+		if _, err := os.Stat(filepath.Join(repoRoot, name)); !os.IsNotExist(err) {
+			t.Fatalf("Expected %s to be removed, stat err: %v", name, err)
+		}
+	}
+	if status := gitStatus(t, repoRoot); status != "?? notes.txt" {
+		t.Fatalf("Expected checkout to be restored, got status:\n%s", status)
+	}
+}
+
+func currentBranch(t *testing.T, dir string) string {
+	t.Helper()
+	branch, err := gitOutputIn(dir, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		t.Fatalf("Failed to read current branch: %v", err)
+	}
+	return branch
+}
+
+func gitStatus(t *testing.T, dir string) string {
+	t.Helper()
+	status, err := gitOutputIn(dir, "status", "--porcelain")
+	if err != nil {
+		t.Fatalf("git status failed in %s: %v", dir, err)
+	}
+	return status
+}
@@ -0,0 +1,28 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetNote keeps note on branchName's worktree, shown beside it in sprout list
+// and the TUI; an empty note removes it
+func (wm *WorktreeManager) SetNote(branchName, note string) error {
+	if _, err := wm.findWorktree(branchName); err != nil {
+		return err
+	}
+	if err := wm.metadata.SetNote(branchName, strings.Join(strings.Fields(note), " ")); err != nil {
+		return fmt.Errorf("failed to save the note: %w", err)
+	}
+	return nil
+}
+
+// markNotes gives each of worktrees the note kept on its branch
+func (wm *WorktreeManager) markNotes(worktrees []Worktree) {
+	notes := wm.metadata.Notes()
+	for i := range worktrees {
+		if worktrees[i].Branch != "" {
+			worktrees[i].Note = notes[worktrees[i].Branch]
+		}
+	}
+}
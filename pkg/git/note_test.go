@@ -0,0 +1,41 @@
+package git
+
+import (
+	"testing"
+
+	"sprout/pkg/config"
+)
+
+func TestNotesAreListedWithTheirWorktree(t *testing.T) {
+	wm := newConfiguredTestManager(t, &config.Config{})
+	if _, err := wm.CreateWorktree("feature-login"); err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+
+	if err := wm.SetNote("feature-login", "waiting on\n  design review"); err != nil {
+		t.Fatalf("SetNote failed: %v", err)
+	}
+	worktrees, err := wm.ListWorktreesForTUI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, wt := range worktrees {
+		want := ""
+		if wt.Branch == "feature-login" {
+			want = "waiting on design review"
+		}
+		if wt.Note != want {
+			t.Errorf("expected %s noted %q, got %q", wt.Name(), want, wt.Note)
+		}
+	}
+
+	if err := wm.SetNote("feature-login", ""); err != nil {
+		t.Fatal(err)
+	}
+	if wt, _ := wm.findWorktree("feature-login"); wt.Note != "" {
+		t.Errorf("expected an empty note to clear it, got %q", wt.Note)
+	}
+	if err := wm.SetNote("missing", "note"); err == nil {
+		t.Error("expected a note on a worktree that doesn't exist to fail")
+	}
+}
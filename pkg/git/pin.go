@@ -0,0 +1,85 @@
+package git
+
+import "fmt"
+
+// pinLockReason is the reason git worktree lock is given for a pinned
+// worktree, so git worktree list says who locked it
+const pinLockReason = "pinned with sprout pin"
+
+// PinWorktree protects branchName's worktree from being removed: git worktree
+// lock stops git removing it, and sprout's record of the pin keeps prune and
+// the TUI's bulk prune away from it even if the lock is lifted by hand.
+// UnpinWorktree undoes it
+func (wm *WorktreeManager) PinWorktree(branchName string) error {
+	wt, err := wm.findWorktree(branchName)
+	if err != nil {
+		return err
+	}
+	if wt.Path == wm.repoRoot {
+		return fmt.Errorf("%s is checked out in the main worktree, which is never pruned", branchName)
+	}
+	locked := false
+	if !wt.Locked {
+		if output, err := wm.gitCommand(wm.repoRoot, "worktree", "lock", "--reason", pinLockReason, wt.Path).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to lock %s: %w\nOutput: %s", branchName, err, string(output))
+		}
+		locked = true
+	}
+	if err := wm.metadata.RecordPinned(branchName); err != nil {
+		// Half a pin would be lifted by an unlock alone, so don't leave one
+		if locked {
+			_ = wm.gitCommand(wm.repoRoot, "worktree", "unlock", wt.Path).Run()
+		}
+		return fmt.Errorf("failed to record the pin on %s: %w", branchName, err)
+	}
+	return nil
+}
+
+// UnpinWorktree lifts the pin PinWorktree put on branchName's worktree,
+// along with the lock it added, so it can be pruned again. A lock that was
+// there before the pin is left for git worktree unlock
+func (wm *WorktreeManager) UnpinWorktree(branchName string) error {
+	wt, err := wm.findWorktree(branchName)
+	if err != nil {
+		return err
+	}
+	if !wt.Pinned {
+		return fmt.Errorf("%s isn't pinned", branchName)
+	}
+	if err := wm.metadata.ForgetPinned(branchName); err != nil {
+		return fmt.Errorf("failed to forget the pin on %s: %w", branchName, err)
+	}
+	if wt.Locked && wt.LockReason == pinLockReason {
+		if output, err := wm.gitCommand(wm.repoRoot, "worktree", "unlock", wt.Path).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to unlock %s: %w\nOutput: %s", branchName, err, string(output))
+		}
+	}
+	return nil
+}
+
+// findWorktree is the worktree git has checked out branchName in
+func (wm *WorktreeManager) findWorktree(branchName string) (Worktree, error) {
+	if branchName == "" {
+		return Worktree{}, fmt.Errorf("branch name cannot be empty")
+	}
+	worktrees, err := wm.gitWorktrees()
+	if err != nil {
+		return Worktree{}, err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == branchName {
+			return wt, nil
+		}
+	}
+	return Worktree{}, fmt.Errorf("worktree does not exist: %s", branchName)
+}
+
+// markPinned notes which of worktrees are pinned
+func (wm *WorktreeManager) markPinned(worktrees []Worktree) {
+	pinned := wm.metadata.Pinned()
+	for i := range worktrees {
+		if _, ok := pinned[worktrees[i].Branch]; ok && worktrees[i].Branch != "" {
+			worktrees[i].Pinned = true
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/metadata"
+	"sprout/pkg/progress"
+)
+
+func TestPinnedWorktreeIsKeptUntilUnpinned(t *testing.T) {
+	wm := newConfiguredTestManager(t, &config.Config{})
+
+	worktreePath, err := wm.CreateWorktree("release-2")
+	if err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	if err := wm.PinWorktree("release-2"); err != nil {
+		t.Fatalf("PinWorktree failed: %v", err)
+	}
+	wt, err := wm.findWorktree("release-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wt.Pinned || !wt.Locked || wt.LockReason != pinLockReason {
+		t.Fatalf("expected the worktree pinned and locked by sprout, got %+v", wt)
+	}
+
+	// Lifting git's lock by hand doesn't lift the pin
+	runGitCommand(t, wm.repoRoot, "worktree", "unlock", worktreePath)
+	wt.PRStatus = "Merged"
+	if ReadyToPrune(wt) {
+		t.Fatal("expected a pinned worktree left out of pruning merged worktrees")
+	}
+	err = wm.PruneWorktree("release-2", PruneOptions{Unlock: true, Progress: progress.Discard})
+	if err == nil || !strings.Contains(err.Error(), "release-2 is pinned; sprout unpin release-2 first") {
+		t.Fatalf("expected a pinned worktree error, got %v", err)
+	}
+	if _, err := os.Stat(worktreePath); err != nil {
+		t.Fatalf("expected the pinned worktree left in place: %v", err)
+	}
+
+	if err := wm.UnpinWorktree("release-2"); err != nil {
+		t.Fatalf("UnpinWorktree failed: %v", err)
+	}
+	if err := wm.PruneWorktree("release-2", PruneOptions{Progress: progress.Discard}); err != nil {
+		t.Fatalf("expected the unpinned worktree pruned, got %v", err)
+	}
+	if err := wm.UnpinWorktree("release-2"); err == nil {
+		t.Fatal("expected unpinning a worktree that's gone to fail")
+	}
+}
+
+func TestUnpinningLeavesALockSetBeforeThePin(t *testing.T) {
+	wm := newConfiguredTestManager(t, &config.Config{})
+	worktreePath, err := wm.CreateWorktree("release-3")
+	if err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	runGitCommand(t, wm.repoRoot, "worktree", "lock", "--reason", "on a USB drive", worktreePath)
+
+	if err := wm.PinWorktree("release-3"); err != nil {
+		t.Fatalf("PinWorktree failed: %v", err)
+	}
+	if err := wm.UnpinWorktree("release-3"); err != nil {
+		t.Fatalf("UnpinWorktree failed: %v", err)
+	}
+
+	wt, err := wm.findWorktree("release-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wt.Pinned || !wt.Locked || wt.LockReason != "on a USB drive" {
+		t.Fatalf("expected the pin lifted and the earlier lock kept, got %+v", wt)
+	}
+}
+
+func TestPinReportsAPinItCantRecord(t *testing.T) {
+	wm := newConfiguredTestManager(t, &config.Config{})
+	if _, err := wm.CreateWorktree("release-4"); err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	// The metadata file can't go inside a file
+	blocked := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	wm.metadata = metadata.NewStoreWithPath(wm.repoRoot, filepath.Join(blocked, "metadata.json"))
+
+	if err := wm.PinWorktree("release-4"); err == nil {
+		t.Fatal("expected the failure to record the pin reported")
+	}
+	wt, err := wm.findWorktree("release-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wt.Locked {
+		t.Fatal("expected the lock taken for the pin lifted again")
+	}
+}
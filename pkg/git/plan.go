@@ -0,0 +1,140 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"sprout/pkg/config"
+	"sprout/pkg/progress"
+)
+
+// CreatePlan is what creating a worktree for a branch will do, worked out
+// without touching anything. CreateWorktreeWithOptions carries out the plan
+// PlanWorktree makes, so a dry run shows what really happens
+type CreatePlan struct {
+	Branch            string   // the branch name as git will have it
+	Path              string   // where the worktree goes
+	Reuse             bool     // a worktree is already there, so it's reused and none of the rest happens
+	BranchExists      bool     // the local branch exists already, so it's checked out rather than started
+	Base              string   // the ref a new branch starts from, resolved locally or on origin
+	SparseDirectories []string // the only directories checked out, or the whole repo when empty
+	EnvTemplate       string   // the template .env.local is written from when the worktree lacks one
+	GitHooksPath      string   // the core.hooksPath the worktree is given, when gitHooks is set
+	GitHooksSource    string   // the hooks directory linked or copied into the worktree
+	GitHooks          string   // config.GitHooksLink or GitHooksCopy
+	Submodules        Setup
+	LFS               Setup
+	Hooks             []string // shell commands run in the new worktree
+}
+
+// PlanWorktree works out what creating a worktree for branchName with opts
+// will do: where it goes, what it starts from and checks out, and the files
+// and steps that finish it off. Resolving the default branch fetches it from
+// origin as creating does, so the base is the one a new worktree would get,
+// once CheckRemoteAccess has made sure the fetch can't hang on a prompt;
+// when origin can't be reached, or with opts.NoFetch, it uses what's already
+// fetched instead
+func (wm *WorktreeManager) PlanWorktree(branchName string, opts CreateOptions) (CreatePlan, error) {
+	sanitized, err := ValidateBranchName(branchName)
+	if err != nil {
+		return CreatePlan{}, err
+	}
+
+	cfg, cfgErr := wm.loadConfig()
+	plan := CreatePlan{
+		Branch:       sanitized,
+		Path:         wm.resolveWorktreePath(cfg, sanitized),
+		BranchExists: wm.branchExists("refs/heads/" + sanitized),
+	}
+	if _, err := os.Stat(plan.Path); err == nil {
+		if !isValidWorktree(plan.Path) {
+			return CreatePlan{}, fmt.Errorf("%w: %s", ErrPathNotWorktree, plan.Path)
+		}
+		plan.Reuse = true
+		if cfgErr == nil {
+			plan.EnvTemplate, _ = cfg.GetEnvTemplatePath(wm.repoRoot)
+		}
+		return plan, nil
+	}
+
+	// Only the default branch is fetched, so only then is origin checked
+	// first. Offline, the worktree starts from what was last fetched
+	fetch := !opts.NoFetch
+	if opts.BaseBranch == "" && fetch {
+		if err := wm.CheckRemoteAccess(); err != nil {
+			progress.Or(opts.Progress).Warning(fmt.Sprintf("%v; starting from the refs already fetched", err))
+			fetch = false
+		}
+	}
+	if plan.Base, err = wm.resolveBaseBranch(opts.BaseBranch, fetch); err != nil {
+		return CreatePlan{}, err
+	}
+	plan.SparseDirectories = opts.SparseDirectories
+	if cfgErr != nil {
+		// Log warning but continue with normal worktree creation
+		progress.Or(opts.Progress).Warning(fmt.Sprintf("failed to load config, using normal checkout: %v", cfgErr))
+	} else {
+		if len(plan.SparseDirectories) == 0 {
+			plan.SparseDirectories, _ = cfg.GetSparseCheckoutDirectories(wm.repoRoot)
+		}
+		plan.EnvTemplate, _ = cfg.GetEnvTemplatePath(wm.repoRoot)
+	}
+	plan.GitHooks = opts.GitHooks
+	plan.GitHooksPath, plan.GitHooksSource = wm.planGitHooks(opts.GitHooks)
+	plan.Submodules = opts.Submodules
+	plan.LFS = opts.LFS
+	plan.Hooks = opts.Hooks
+	return plan, nil
+}
+
+// Steps describes what the plan does, in the order it's done, one line each
+func (plan CreatePlan) Steps() []string {
+	if plan.Reuse {
+		steps := []string{"reuse the existing worktree at " + plan.Path}
+		if plan.EnvTemplate != "" {
+			steps = append(steps, "write .env.local from "+plan.EnvTemplate+" unless it's there")
+		}
+		return steps
+	}
+
+	var steps []string
+	if plan.BranchExists {
+		steps = append(steps, "check out the existing branch "+plan.Branch)
+	} else {
+		steps = append(steps, fmt.Sprintf("create branch %s from %s", plan.Branch, plan.Base))
+	}
+	if len(plan.SparseDirectories) > 0 {
+		steps = append(steps, fmt.Sprintf("check it out at %s with only %s", plan.Path, strings.Join(plan.SparseDirectories, ", ")))
+	} else {
+		steps = append(steps, "check it out at "+plan.Path)
+	}
+	if plan.EnvTemplate != "" {
+		steps = append(steps, "write .env.local from "+plan.EnvTemplate)
+	}
+	if plan.GitHooksPath != "" {
+		steps = append(steps, "set core.hooksPath to "+plan.GitHooksPath)
+	}
+	switch {
+	case plan.GitHooksSource != "" && plan.GitHooks == config.GitHooksLink:
+		steps = append(steps, "link git hooks from "+plan.GitHooksSource)
+	case plan.GitHooksSource != "" && plan.GitHooks == config.GitHooksCopy:
+		steps = append(steps, "copy git hooks from "+plan.GitHooksSource)
+	}
+	switch plan.Submodules {
+	case SetupAlways:
+		steps = append(steps, "update submodules (git submodule update --init --recursive)")
+	case SetupDetect:
+		steps = append(steps, "update submodules if the checkout has any")
+	}
+	switch plan.LFS {
+	case SetupAlways:
+		steps = append(steps, "fetch LFS files (git lfs pull)")
+	case SetupDetect:
+		steps = append(steps, "fetch LFS files if the checkout uses LFS")
+	}
+	for _, hook := range plan.Hooks {
+		steps = append(steps, "run hook: "+hook)
+	}
+	return steps
+}
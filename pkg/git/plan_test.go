@@ -0,0 +1,112 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/progress"
+)
+
+func TestPlanWorktreeTouchesNothing(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	basePath := t.TempDir()
+	cfg := &config.Config{WorktreeBasePath: basePath}
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: cfg},
+	}
+	base := currentBranch(t, repoRoot)
+
+	plan, err := wm.PlanWorktree("feature/plan", CreateOptions{SparseDirectories: []string{"api"}, Hooks: []string{"make setup"}, LFS: SetupSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Path != filepath.Join(basePath, "feature/plan") || plan.Base != base || plan.BranchExists || plan.Reuse {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	want := []string{
+		"create branch feature/plan from " + base,
+		"check it out at " + plan.Path + " with only api",
+		"update submodules if the checkout has any",
+		"run hook: make setup",
+	}
+	if steps := plan.Steps(); !slices.Equal(steps, want) {
+		t.Errorf("expected steps %q, got %q", want, steps)
+	}
+	if _, err := os.Stat(plan.Path); !os.IsNotExist(err) {
+		t.Fatalf("expected planning to leave %s alone, stat returned %v", plan.Path, err)
+	}
+	if wm.branchExists("refs/heads/feature/plan") {
+		t.Fatal("expected planning not to create the branch")
+	}
+
+	path, err := wm.CreateWorktree("feature/plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != plan.Path {
+		t.Errorf("expected the worktree where it was planned, %s, got %s", plan.Path, path)
+	}
+	again, err := wm.PlanWorktree("feature/plan", CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.Reuse || !slices.Equal(again.Steps(), []string{"reuse the existing worktree at " + path}) {
+		t.Errorf("expected the existing worktree reused, got %+v", again)
+	}
+}
+
+func TestPlanWorktreeRefusesAMissingBase(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: &config.Config{WorktreeBasePath: t.TempDir()}},
+	}
+	if _, err := wm.PlanWorktree("feature", CreateOptions{BaseBranch: "develop"}); err == nil {
+		t.Fatal("expected a base that doesn't exist to fail the plan")
+	}
+}
+
+// brokenConfigLoader stands in for a config file that doesn't parse
+type brokenConfigLoader struct{}
+
+func (brokenConfigLoader) GetConfig() (*config.Config, error) {
+	return nil, errors.New("invalid character '}'")
+}
+
+func TestPlanWorktreeWarnsThroughItsPresenter(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	wm := &WorktreeManager{repoRoot: repoRoot, repoName: filepath.Base(repoRoot), configLoader: brokenConfigLoader{}}
+
+	var events bytes.Buffer
+	if _, err := wm.PlanWorktree("feature/plan", CreateOptions{NoFetch: true, Progress: progress.NewJSON(&events)}); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"warning","message":"failed to load config, using normal checkout: invalid character '}'"}`
+	if strings.TrimSpace(events.String()) != want {
+		t.Fatalf("expected the warning as an event, got %q", events.String())
+	}
+}
+
+func TestManagerWarnsThroughThePresenterItWasGiven(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	wm := &WorktreeManager{repoRoot: repoRoot, repoName: filepath.Base(repoRoot), configLoader: brokenConfigLoader{}}
+
+	var events bytes.Buffer
+	wm.SetProgress(progress.NewJSON(&events))
+	if _, err := wm.MergedWorktrees(); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"warning","message":"failed to load config, using default worktree path: invalid character '}'"}`
+	if strings.TrimSpace(events.String()) != want {
+		t.Fatalf("expected the warning as an event, got %q", events.String())
+	}
+}
@@ -0,0 +1,136 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sprout/pkg/github"
+	"sprout/pkg/metadata"
+	"sprout/pkg/progress"
+)
+
+// PRCheckout is what CheckoutPR picked back up
+type PRCheckout struct {
+	PullRequest  github.PullRequest
+	WorktreePath string
+	Created      bool // a worktree was made for the PR's branch rather than reusing one
+}
+
+// CheckoutPR gets a pull request ready to work on again, such as after review
+// comments come in days later. ref is the PR's number, its branch, or the
+// issue it was for; empty means the branch checked out here. The PR's branch
+// is fetched from origin, given a worktree unless it has one already, and
+// fast-forwarded to the latest pushed head. Local changes or commits that
+// would stop the fast-forward leave the worktree as it is, with a warning
+func (wm *WorktreeManager) CheckoutPR(ref string, opts CreateOptions) (PRCheckout, error) {
+	pr, err := wm.findPullRequest(ref)
+	if err != nil {
+		return PRCheckout{}, err
+	}
+	checkout := PRCheckout{PullRequest: pr}
+	branch := pr.HeadBranch
+	switch {
+	case pr.CrossRepository:
+		return checkout, fmt.Errorf("PR #%d is from a fork, so %s isn't on origin; gh pr checkout %d fetches it", pr.Number, branch, pr.Number)
+	case pr.State == "Merged":
+		return checkout, fmt.Errorf("PR #%d was merged already; sprout create starts something new", pr.Number)
+	case pr.State == "Closed":
+		progress.Or(opts.Progress).Warning(fmt.Sprintf("PR #%d is closed; reopen it on GitHub before pushing to it", pr.Number))
+	}
+
+	if err := wm.CheckRemoteAccess(); err != nil {
+		return checkout, err
+	}
+	if err := wm.fetchRemoteBranch(branch); err != nil {
+		return checkout, fmt.Errorf("failed to fetch %s from origin: %w", branch, err)
+	}
+
+	cfg, _ := wm.loadConfig()
+	existing, err := wm.findWorktree(branch)
+	checkout.WorktreePath = existing.Path
+	if err != nil {
+		checkout.WorktreePath = wm.resolveWorktreePath(cfg, sanitizeBranchName(branch))
+		if err := wm.addPRWorktree(branch, checkout.WorktreePath); err != nil {
+			return checkout, err
+		}
+		checkout.Created = true
+	}
+	if err := wm.finishWorktree(cfg, branch, checkout.WorktreePath, !checkout.Created, opts); err != nil {
+		return checkout, err
+	}
+
+	if warning := wm.fastForward(branch, checkout.WorktreePath); warning != "" {
+		progress.Or(opts.Progress).Warning(warning)
+	}
+	return checkout, nil
+}
+
+// findPullRequest looks up the PR ref names, as described for CheckoutPR
+func (wm *WorktreeManager) findPullRequest(ref string) (github.PullRequest, error) {
+	if wm.githubClient == nil {
+		return github.PullRequest{}, fmt.Errorf("GitHub is not available to look up pull requests")
+	}
+	if ref == "" {
+		here, err := wm.branchHere()
+		if err != nil {
+			return github.PullRequest{}, fmt.Errorf("not on a branch; name the PR number, branch or issue to check out")
+		}
+		ref = here
+	}
+
+	var pr github.PullRequest
+	var err error
+	if number, ok := github.ParsePullRequestNumber(ref); ok {
+		pr, err = wm.githubClient.PullRequestByNumber(number)
+	} else if metadata.IsIssueIdentifier(ref) {
+		// The branch sprout made for the issue is the surest way to its PR;
+		// failing that, PRs usually name the issue in their title or branch
+		err = github.ErrNoPullRequest
+		if branch := wm.metadata.BranchForIssue(ref); branch != "" {
+			pr, err = wm.githubClient.PullRequestForBranch(branch)
+		}
+		if errors.Is(err, github.ErrNoPullRequest) {
+			pr, err = wm.githubClient.SearchPullRequest(strings.ToUpper(ref))
+		}
+	} else {
+		pr, err = wm.githubClient.PullRequestForBranch(ref)
+	}
+	if errors.Is(err, github.ErrNoPullRequest) {
+		return pr, fmt.Errorf("%w for %s", err, ref)
+	}
+	return pr, err
+}
+
+// addPRWorktree checks branch out at worktreePath, tracking origin's copy of
+// it when there's no local branch yet
+func (wm *WorktreeManager) addPRWorktree(branch, worktreePath string) error {
+	if isValidWorktree(worktreePath) {
+		return fmt.Errorf("%s is already a worktree for another branch", worktreePath)
+	}
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+		return fmt.Errorf("failed to create worktree base directory: %w", err)
+	}
+	args := []string{"worktree", "add", "--track", "-b", branch, worktreePath, "origin/" + branch}
+	if wm.branchExists("refs/heads/" + branch) {
+		args = []string{"worktree", "add", worktreePath, branch}
+	}
+	if output, err := wm.gitCommand(wm.repoRoot, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create worktree: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// fastForward brings branch's worktree up to origin's copy of the branch,
+// saying why not when it can't be done without losing or merging work
+func (wm *WorktreeManager) fastForward(branch, worktreePath string) string {
+	if wm.HasUncommittedChanges(worktreePath) {
+		return fmt.Sprintf("%s has uncommitted changes, so it wasn't updated to origin/%s; commit or stash them and git pull", branch, branch)
+	}
+	if err := wm.gitCommand(worktreePath, "merge", "--ff-only", "origin/"+branch).Run(); err != nil {
+		return fmt.Sprintf("%s has commits that aren't on origin/%s, so it wasn't updated; git pull merges the two", branch, branch)
+	}
+	return ""
+}
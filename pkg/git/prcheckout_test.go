@@ -0,0 +1,80 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/github"
+	"sprout/pkg/progress"
+)
+
+func TestCheckoutPRPullsTheLatestHeadIntoItsWorktree(t *testing.T) {
+	wm := newConfiguredTestManager(t, &config.Config{})
+	pr := `{"number":42,"title":"SPR-12 Fix login","headRefName":"spr-12-fix-login","state":"OPEN","url":"https://github.com/laurenkt/sprout/pull/42","isCrossRepository":false}`
+	wm.githubClient = github.NewClientWithRunnerAndCachePath(wm.repoRoot, func(dir string, name string, args ...string) ([]byte, error) {
+		if args[1] == "view" {
+			return []byte(pr), nil
+		}
+		return []byte("[" + pr + "]"), nil
+	}, filepath.Join(t.TempDir(), "pr-status-cache.json"))
+
+	// The PR's branch is only on origin, as if its worktree was pruned
+	remote := t.TempDir()
+	runGitCommand(t, remote, "init", "--bare")
+	runGitCommand(t, wm.repoRoot, "remote", "add", "origin", remote)
+	runGitCommand(t, wm.repoRoot, "checkout", "-b", "spr-12-fix-login")
+	runGitCommand(t, wm.repoRoot, "commit", "--allow-empty", "-m", "Retry login")
+	runGitCommand(t, wm.repoRoot, "push", "origin", "spr-12-fix-login")
+	runGitCommand(t, wm.repoRoot, "checkout", "-")
+	runGitCommand(t, wm.repoRoot, "branch", "-D", "spr-12-fix-login")
+
+	var out bytes.Buffer
+	checkout, err := wm.CheckoutPR("SPR-12", CreateOptions{Progress: progress.NewText(&out)})
+	if err != nil {
+		t.Fatalf("CheckoutPR failed: %v", err)
+	}
+	if !checkout.Created || checkout.PullRequest.Number != 42 || checkout.PullRequest.State != "Open" {
+		t.Fatalf("expected a new worktree for open PR #42, got %+v", checkout)
+	}
+	if currentCommit(t, checkout.WorktreePath, "HEAD") != currentCommit(t, wm.repoRoot, "origin/spr-12-fix-login") {
+		t.Fatal("expected the new worktree at the PR's head")
+	}
+
+	// Review comments were addressed elsewhere and pushed
+	runGitCommand(t, checkout.WorktreePath, "commit", "--allow-empty", "-m", "Address review")
+	runGitCommand(t, checkout.WorktreePath, "push", "origin", "spr-12-fix-login")
+	runGitCommand(t, checkout.WorktreePath, "reset", "--hard", "HEAD~1")
+
+	again, err := wm.CheckoutPR("#42", CreateOptions{Progress: progress.NewText(&out)})
+	if err != nil {
+		t.Fatalf("CheckoutPR failed: %v", err)
+	}
+	if again.Created || again.WorktreePath != checkout.WorktreePath {
+		t.Fatalf("expected the worktree reused, got %+v", again)
+	}
+	if currentCommit(t, again.WorktreePath, "HEAD") != currentCommit(t, wm.repoRoot, "origin/spr-12-fix-login") {
+		t.Fatal("expected the worktree fast-forwarded to the PR's latest head")
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no warnings, got %q", out.String())
+	}
+
+	// Uncommitted work is never touched
+	runGitCommand(t, again.WorktreePath, "reset", "--hard", "HEAD~1")
+	if err := os.WriteFile(filepath.Join(again.WorktreePath, "notes.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wm.CheckoutPR("spr-12-fix-login", CreateOptions{Progress: progress.NewText(&out)}); err != nil {
+		t.Fatalf("CheckoutPR failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "spr-12-fix-login has uncommitted changes, so it wasn't updated") {
+		t.Fatalf("expected a warning about uncommitted changes, got %q", out.String())
+	}
+	if currentCommit(t, again.WorktreePath, "HEAD") == currentCommit(t, wm.repoRoot, "origin/spr-12-fix-login") {
+		t.Fatal("expected a worktree with uncommitted changes left where it was")
+	}
+}
@@ -0,0 +1,143 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// pruneParallel is how many worktrees PruneMergedWorktrees removes at once.
+// Most of a prune is waiting on the disk or on origin, but git's own
+// bookkeeping is done one worktree at a time, so more doesn't help much
+const pruneParallel = 4
+
+// PruneStatus is how pruning one worktree of several went. The names are the
+// ones prune's porcelain lines start with
+type PruneStatus string
+
+const (
+	PrunePruned     PruneStatus = "pruned"
+	PruneWouldPrune PruneStatus = "would-prune" // a dry run found nothing in the way
+	PruneSkipped    PruneStatus = "skipped"
+	PruneFailed     PruneStatus = "failed"
+)
+
+// PruneOutcome is how pruning one worktree went
+type PruneOutcome struct {
+	Branch   string
+	Path     string
+	Status   PruneStatus
+	Reason   string   // why it failed or was skipped
+	Warnings []string // what went wrong without stopping the prune, such as the branch being left behind
+
+	err     error
+	trashed bool
+}
+
+// PruneResult is how each of several worktrees pruned together went, in the
+// order they were given
+type PruneResult struct {
+	Outcomes []PruneOutcome
+}
+
+// Count is how many worktrees ended with status
+func (r PruneResult) Count(status PruneStatus) int {
+	count := 0
+	for _, outcome := range r.Outcomes {
+		if outcome.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Failed lists the worktrees that couldn't be pruned
+func (r PruneResult) Failed() []PruneOutcome {
+	var failed []PruneOutcome
+	for _, outcome := range r.Outcomes {
+		if outcome.Status == PruneFailed {
+			failed = append(failed, outcome)
+		}
+	}
+	return failed
+}
+
+// ReadyToPrune is whether pruning merged worktrees takes wt: its PR has been
+// merged, and it isn't the default branch, detached or bare. Locked worktrees
+// are only removed when asked for by name, and pinned ones not until unpinned
+func ReadyToPrune(wt Worktree) bool {
+	if wt.keptBecause() != "" {
+		return false
+	}
+	return wt.PRStatus == "Merged"
+}
+
+// PruneAllMerged removes every worktree whose branch has been merged
+func (wm *WorktreeManager) PruneAllMerged(opts PruneOptions) (PruneResult, error) {
+	mergedWorktrees, err := wm.MergedWorktrees()
+	if err != nil {
+		return PruneResult{}, err
+	}
+	return wm.PruneMergedWorktrees(mergedWorktrees, opts)
+}
+
+// PruneMergedWorktrees removes worktrees listed by MergedWorktrees or
+// GCCandidates, several at a time, as one operation that sprout undo brings
+// back together. It reports nothing itself: opts.OnProgress hears about each
+// worktree as it's done, and the result says how every one went. The error
+// is only for failures
+func (wm *WorktreeManager) PruneMergedWorktrees(mergedWorktrees []Worktree, opts PruneOptions) (PruneResult, error) {
+	result := PruneResult{Outcomes: make([]PruneOutcome, len(mergedWorktrees))}
+	if len(mergedWorktrees) == 0 {
+		return result, nil
+	}
+
+	opts.operation = newTrashID()
+	jobs := make(chan int)
+	var progressMu sync.Mutex
+	var wg sync.WaitGroup
+	for range min(pruneParallel, len(mergedWorktrees)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				outcome := wm.pruneMergedWorktree(mergedWorktrees[i], opts)
+				result.Outcomes[i] = outcome
+				if opts.OnProgress != nil {
+					progressMu.Lock()
+					opts.OnProgress(outcome)
+					progressMu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range mergedWorktrees {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if failed := result.Failed(); len(failed) > 0 {
+		branches := make([]string, len(failed))
+		for i, outcome := range failed {
+			branches[i] = outcome.Branch
+		}
+		return result, fmt.Errorf("failed to prune %d of %d worktree(s): %s", len(failed), len(mergedWorktrees), strings.Join(branches, ", "))
+	}
+	return result, nil
+}
+
+// pruneMergedWorktree prunes one of the worktrees MergedWorktrees listed,
+// skipping it when it's gone since
+func (wm *WorktreeManager) pruneMergedWorktree(wt Worktree, opts PruneOptions) PruneOutcome {
+	if wt.Path != "" {
+		if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+			return PruneOutcome{Branch: wt.Branch, Path: wt.Path, Status: PruneSkipped, Reason: "it's already gone"}
+		}
+	}
+	if opts.KeepUnmerged && wt.PRStatus != "Merged" {
+		opts.KeepBranch = true
+	}
+	return wm.pruneWorktree(wt.Branch, opts)
+}
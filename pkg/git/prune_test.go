@@ -0,0 +1,119 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/github"
+	"sprout/pkg/metadata"
+	"sprout/pkg/progress"
+)
+
+func TestPruneMergedWorktreesPrunesSideBySideAndReportsEach(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: &config.Config{WorktreeBasePath: t.TempDir()}},
+		metadata:     metadata.NewStoreWithPath(repoRoot, filepath.Join(t.TempDir(), "metadata.json")),
+	}
+
+	branches := []string{"one", "two", "three", "four", "five", "gone", "locked"}
+	var worktrees []Worktree
+	for _, branch := range branches {
+		path, err := wm.CreateWorktree(branch)
+		if err != nil {
+			t.Fatalf("Failed to create worktree: %v", err)
+		}
+		worktrees = append(worktrees, Worktree{Branch: branch, Path: path, PRStatus: "Merged"})
+	}
+	// One disappears after being listed and one is locked since
+	runGitCommand(t, repoRoot, "worktree", "remove", "--force", worktrees[5].Path)
+	runGitCommand(t, repoRoot, "worktree", "lock", worktrees[6].Path)
+
+	var heard []string
+	result, err := wm.PruneMergedWorktrees(worktrees, PruneOptions{OnProgress: func(outcome PruneOutcome) {
+		heard = append(heard, outcome.Branch)
+	}})
+	if err == nil || !strings.Contains(err.Error(), "failed to prune 1 of 7 worktree(s): locked") {
+		t.Fatalf("expected the locked worktree's failure, got %v", err)
+	}
+
+	sort.Strings(heard)
+	want := append([]string{}, branches...)
+	sort.Strings(want)
+	if strings.Join(heard, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected to hear about every worktree, got %v", heard)
+	}
+	for i, outcome := range result.Outcomes {
+		if outcome.Branch != branches[i] {
+			t.Fatalf("expected outcomes in the order given, got %s at %d", outcome.Branch, i)
+		}
+	}
+	if result.Count(PrunePruned) != 5 || result.Outcomes[5].Status != PruneSkipped || result.Outcomes[6].Status != PruneFailed {
+		t.Fatalf("expected five pruned, one skipped and one failed, got %+v", result.Outcomes)
+	}
+	if !strings.Contains(result.Outcomes[6].Reason, "is locked") {
+		t.Fatalf("expected the failure to say why, got %q", result.Outcomes[6].Reason)
+	}
+	for _, wt := range worktrees[:5] {
+		if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s removed, stat err: %v", wt.Path, err)
+		}
+		if wm.branchExists("refs/heads/" + wt.Branch) {
+			t.Fatalf("expected branch %s deleted", wt.Branch)
+		}
+	}
+
+	var prunedRecords int
+	for _, record := range wm.metadata.Worktrees() {
+		if record.PrunedAt != nil {
+			prunedRecords++
+		}
+	}
+	if prunedRecords != 5 {
+		t.Fatalf("expected every prune recorded despite running side by side, got %d", prunedRecords)
+	}
+
+	restored, err := wm.UndoPrune()
+	if err != nil {
+		t.Fatalf("UndoPrune failed: %v", err)
+	}
+	if len(restored) != 5 {
+		t.Fatalf("expected undo to bring back all five pruned together, got %d", len(restored))
+	}
+}
+
+func TestPruneLargerThanOnlyDeletesTheRemotesOfBranchesItDeletes(t *testing.T) {
+	wm := newConfiguredTestManager(t, &config.Config{})
+	origin := t.TempDir()
+	runGitCommand(t, origin, "init", "--bare")
+	runGitCommand(t, wm.repoRoot, "remote", "add", "origin", origin)
+	wm.githubClient = github.NewClientWithRunnerAndCachePath(wm.repoRoot, func(dir, name string, args ...string) ([]byte, error) {
+		return []byte(`[]`), nil
+	}, filepath.Join(t.TempDir(), "pr-status-cache.json"))
+
+	path, err := wm.CreateWorktree("big-feature")
+	if err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	runGitCommand(t, wm.repoRoot, "push", "origin", "big-feature")
+	wm.metadata.RememberDiskUsage(map[string]int64{path: 10 << 30})
+
+	if err := wm.PruneLargerThan(5<<30, PruneOptions{DeleteRemote: true, Progress: progress.Discard}); err != nil {
+		t.Fatalf("PruneLargerThan failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the big worktree pruned, stat err: %v", err)
+	}
+	if !wm.branchExists("refs/heads/big-feature") {
+		t.Fatal("expected the unmerged branch kept")
+	}
+	if output, err := wm.gitCommand(origin, "rev-parse", "--verify", "refs/heads/big-feature").CombinedOutput(); err != nil {
+		t.Fatalf("expected the unmerged branch kept on origin too: %v\n%s", err, output)
+	}
+}
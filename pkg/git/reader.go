@@ -0,0 +1,339 @@
+package git
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// repoReader answers the read-only questions sprout asks most, such as which
+// worktrees there are and whether a branch exists, in-process with go-git
+// instead of a git subprocess each. Those add up on Windows and network
+// filesystems. Anything that writes still runs git, and each read falls back
+// to git when the reader can't answer it, so a repository go-git doesn't
+// understand just isn't sped up
+type repoReader struct {
+	repo      *gogit.Repository
+	commonDir string // the git directory shared by all the repository's worktrees
+}
+
+// openRepoReader opens the repository whose checkout is at repoRoot. It fails
+// for repositories go-git can't read, such as those using extensions it
+// doesn't support
+func openRepoReader(repoRoot string) (*repoReader, error) {
+	commonDir, err := commonGitDir(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	// Opened at the shared git directory, the repository has the refs and
+	// objects every worktree sees; what's per worktree is read from its own
+	// directory under worktrees/
+	repo, err := gogit.PlainOpen(commonDir)
+	if err != nil {
+		return nil, fmt.Errorf("go-git can't read %s: %w", commonDir, err)
+	}
+	return &repoReader{repo: repo, commonDir: commonDir}, nil
+}
+
+// commonGitDir is the git directory shared by the worktrees of the checkout
+// at root, following a .git file to a linked worktree's git directory and
+// from there to the repository's
+func commonGitDir(root string) (string, error) {
+	gitDir := filepath.Join(root, ".git")
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(gitDir)
+		if err != nil {
+			return "", err
+		}
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+		if !ok {
+			return "", fmt.Errorf("%s doesn't point at a git directory", gitDir)
+		}
+		gitDir = resolveFrom(root, strings.TrimSpace(target))
+	}
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		gitDir = resolveFrom(gitDir, strings.TrimSpace(string(data)))
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+// resolveFrom is path taken relative to dir unless it's absolute
+func resolveFrom(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// worktrees lists the repository's worktrees as git worktree list --porcelain
+// does: the main one first, then the linked ones by path
+func (r *repoReader) worktrees() ([]Worktree, error) {
+	main, err := r.mainWorktree()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(r.commonDir, "worktrees"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var linked []Worktree
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		wt, err := r.linkedWorktree(filepath.Join(r.commonDir, "worktrees", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		linked = append(linked, wt)
+	}
+	sort.Slice(linked, func(i, j int) bool { return linked[i].Path < linked[j].Path })
+	return append([]Worktree{main}, linked...), nil
+}
+
+// mainWorktree is the checkout the shared git directory belongs to, or the
+// directory itself for a bare repository
+func (r *repoReader) mainWorktree() (Worktree, error) {
+	path, err := filepath.EvalSymlinks(r.commonDir)
+	if err != nil {
+		return Worktree{}, err
+	}
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return Worktree{}, err
+	}
+	if cfg.Core.IsBare {
+		return Worktree{Path: path, Bare: true}, nil
+	}
+
+	wt := Worktree{Path: strings.TrimSuffix(path, string(filepath.Separator)+".git")}
+	head, err := r.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return Worktree{}, err
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		err = r.checkedOut(&wt, head.Target().String())
+	} else {
+		err = r.checkedOut(&wt, head.Hash().String())
+	}
+	return wt, err
+}
+
+// linkedWorktree is the worktree git keeps the administrative directory dir
+// for
+func (r *repoReader) linkedWorktree(dir string) (Worktree, error) {
+	var wt Worktree
+	if data, err := os.ReadFile(filepath.Join(dir, "gitdir")); err == nil {
+		gitFile := resolveFrom(dir, strings.TrimSpace(string(data)))
+		wt.Path = strings.TrimSuffix(gitFile, string(filepath.Separator)+".git")
+		if _, err := os.Stat(gitFile); err != nil {
+			wt.Prunable = true
+		}
+	} else {
+		// git names a worktree it can't place by its administrative directory
+		wt.Path = dir
+		wt.Prunable = true
+	}
+	if reason, err := os.ReadFile(filepath.Join(dir, "locked")); err == nil {
+		// A locked worktree is kept however it looks
+		wt.Locked = true
+		wt.LockReason = strings.TrimSpace(string(reason))
+		wt.Prunable = false
+	}
+
+	head, err := os.ReadFile(filepath.Join(dir, "HEAD"))
+	if err != nil {
+		return Worktree{}, err
+	}
+	target, _ := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: ")
+	return wt, r.checkedOut(&wt, target)
+}
+
+// checkedOut fills in what wt has checked out from its HEAD, which is either
+// a ref or a commit
+func (r *repoReader) checkedOut(wt *Worktree, head string) error {
+	if !strings.HasPrefix(head, "refs/") {
+		if !plumbing.IsHash(head) {
+			return fmt.Errorf("%s has an unreadable HEAD: %q", wt.Path, head)
+		}
+		wt.Commit = head
+		wt.Detached = true
+		return nil
+	}
+
+	// git lists a branch with no commits yet at the zero commit
+	wt.Commit = plumbing.ZeroHash.String()
+	if branch, ok := strings.CutPrefix(head, "refs/heads/"); ok {
+		wt.Branch = branch
+	}
+	ref, err := r.repo.Reference(plumbing.ReferenceName(head), true)
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+	wt.Commit = ref.Hash().String()
+	return nil
+}
+
+// refExists is whether the full ref name, such as refs/heads/main, exists
+func (r *repoReader) refExists(name string) (bool, error) {
+	_, err := r.repo.Storer.Reference(plumbing.ReferenceName(name))
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	return true, nil
+}
+
+// symbolicRef is the ref name points at, or "" when it isn't a symbolic ref
+func (r *repoReader) symbolicRef(name string) (string, error) {
+	ref, err := r.repo.Storer.Reference(plumbing.ReferenceName(name))
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		return "", nil
+	case err != nil:
+		return "", err
+	}
+	if ref.Type() != plumbing.SymbolicReference {
+		return "", nil
+	}
+	return ref.Target().String(), nil
+}
+
+// commitTimes is when the last commit on each of branches was made, or on
+// every local branch when branches is nil. Branches that don't exist are
+// left out
+func (r *repoReader) commitTimes(branches []string) (map[string]time.Time, error) {
+	if branches == nil {
+		refs, err := r.repo.Branches()
+		if err != nil {
+			return nil, err
+		}
+		err = refs.ForEach(func(ref *plumbing.Reference) error {
+			branches = append(branches, ref.Name().Short())
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(map[string]time.Time, len(branches))
+	for _, branch := range branches {
+		ref, err := r.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		commit, err := r.repo.CommitObject(ref.Hash())
+		if err != nil {
+			return nil, err
+		}
+		result[branch] = commit.Committer.When
+	}
+	return result, nil
+}
+
+// aheadBehind counts the commits on branch that base doesn't have, and
+// those on base that branch doesn't, as git rev-list --left-right --count
+// branch...base does
+func (r *repoReader) aheadBehind(branch, base string) (int, int, error) {
+	left, err := r.repo.ResolveRevision(plumbing.Revision(branch))
+	if err != nil {
+		return 0, 0, err
+	}
+	right, err := r.repo.ResolveRevision(plumbing.Revision(base))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Walk back from both, newest first, marking each commit with the sides
+	// it's reachable from, until every commit left to walk is reachable from
+	// both and so can't change either count
+	const onLeft, onRight, onBoth = 1, 2, 3
+	sides := map[plumbing.Hash]int{}
+	queue := &commitQueue{}
+	reach := func(hash plumbing.Hash, side int) error {
+		if sides[hash]|side == sides[hash] {
+			return nil
+		}
+		sides[hash] |= side
+		commit, err := r.repo.CommitObject(hash)
+		if err != nil {
+			return err
+		}
+		heap.Push(queue, commit)
+		return nil
+	}
+	if err := reach(*left, onLeft); err != nil {
+		return 0, 0, err
+	}
+	if err := reach(*right, onRight); err != nil {
+		return 0, 0, err
+	}
+	for queue.Len() > 0 && !queue.settled(sides, onBoth) {
+		commit := heap.Pop(queue).(*object.Commit)
+		for _, parent := range commit.ParentHashes {
+			if err := reach(parent, sides[commit.Hash]); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	ahead, behind := 0, 0
+	for _, side := range sides {
+		switch side {
+		case onLeft:
+			ahead++
+		case onRight:
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// commitQueue holds commits newest first, in the order git walks history
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int { return len(q) }
+func (q commitQueue) Less(i, j int) bool {
+	return q[i].Committer.When.After(q[j].Committer.When)
+}
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)   { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	commit := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return commit
+}
+
+// settled is whether every commit in q is marked as reachable from both sides
+func (q commitQueue) settled(sides map[plumbing.Hash]int, both int) bool {
+	for _, commit := range q {
+		if sides[commit.Hash] != both {
+			return false
+		}
+	}
+	return true
+}
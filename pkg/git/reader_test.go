@@ -0,0 +1,229 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// commitOn adds an empty commit to the branch checked out in dir
+func commitOn(t testing.TB, dir, message string) {
+	t.Helper()
+	runGitCommand(t, dir, "commit", "--allow-empty", "-m", message)
+}
+
+func TestRepoReaderListsWorktreesAsGitDoes(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	worktrees := t.TempDir()
+	runGitCommand(t, repoRoot, "worktree", "add", "-b", "feature", filepath.Join(worktrees, "feature"))
+	runGitCommand(t, repoRoot, "worktree", "add", "--detach", filepath.Join(worktrees, "detached"))
+	runGitCommand(t, repoRoot, "worktree", "add", "-b", "kept", filepath.Join(worktrees, "kept"))
+	runGitCommand(t, repoRoot, "worktree", "lock", "--reason", "on a USB drive", filepath.Join(worktrees, "kept"))
+	runGitCommand(t, repoRoot, "worktree", "add", "-b", "gone", filepath.Join(worktrees, "gone"))
+	if err := os.RemoveAll(filepath.Join(worktrees, "gone")); err != nil {
+		t.Fatal(err)
+	}
+	commitOn(t, filepath.Join(worktrees, "feature"), "Feature work")
+
+	reader, err := openRepoReader(repoRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reader.worktrees()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := gitOutputIn(repoRoot, "worktree", "list", "--porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := parseWorktreeList(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the worktrees git lists\n%+v\ngot\n%+v", want, got)
+	}
+
+	// Read from a linked worktree, the repository is the same
+	fromLinked, err := openRepoReader(filepath.Join(worktrees, "feature"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err := fromLinked.worktrees(); err != nil || !reflect.DeepEqual(again, got) {
+		t.Errorf("expected the same worktrees from a linked worktree, got %+v (%v)", again, err)
+	}
+}
+
+func TestRepoReaderListsABareRepository(t *testing.T) {
+	bare := filepath.Join(t.TempDir(), "app.git")
+	runGitCommand(t, initTestRepo(t), "clone", "--bare", ".", bare)
+	checkout := filepath.Join(t.TempDir(), "main")
+	runGitCommand(t, bare, "worktree", "add", checkout, currentBranch(t, bare))
+
+	reader, err := openRepoReader(checkout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reader.worktrees()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := gitOutputIn(bare, "worktree", "list", "--porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := parseWorktreeList(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the worktrees git lists\n%+v\ngot\n%+v", want, got)
+	}
+}
+
+func TestRepoReaderAnswersAsGitDoes(t *testing.T) {
+	origin := initTestRepo(t)
+	repoRoot := filepath.Join(t.TempDir(), "clone")
+	runGitCommand(t, origin, "clone", ".", repoRoot)
+	runGitCommand(t, repoRoot, "config", "user.email", "test@example.com")
+	runGitCommand(t, repoRoot, "config", "user.name", "Test User")
+	base := currentBranch(t, repoRoot)
+
+	// feature branches off, base moves on, then takes a merge of feature
+	runGitCommand(t, repoRoot, "checkout", "-b", "feature")
+	commitOn(t, repoRoot, "Feature one")
+	commitOn(t, repoRoot, "Feature two")
+	runGitCommand(t, repoRoot, "checkout", base)
+	commitOn(t, repoRoot, "Base one")
+	runGitCommand(t, repoRoot, "merge", "--no-ff", "-m", "Merge feature", "feature")
+	runGitCommand(t, repoRoot, "checkout", "feature")
+	commitOn(t, repoRoot, "Feature three")
+	runGitCommand(t, repoRoot, "pack-refs", "--all")
+	runGitCommand(t, repoRoot, "checkout", "-b", "loose")
+
+	reader, err := openRepoReader(repoRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A manager whose reader is never opened asks git
+	wm := &WorktreeManager{repoRoot: repoRoot}
+	wm.readerOnce.Do(func() {})
+
+	for _, pair := range [][2]string{{"feature", base}, {base, "feature"}, {"feature", "origin/" + base}, {"loose", "feature"}} {
+		output, err := gitOutputIn(repoRoot, "rev-list", "--left-right", "--count", pair[0]+"..."+pair[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		fields := strings.Fields(output)
+		wantAhead, _ := strconv.Atoi(fields[0])
+		wantBehind, _ := strconv.Atoi(fields[1])
+		ahead, behind, err := reader.aheadBehind(pair[0], pair[1])
+		if err != nil || ahead != wantAhead || behind != wantBehind {
+			t.Errorf("%s...%s: expected %d ahead and %d behind, got %d and %d (%v)", pair[0], pair[1], wantAhead, wantBehind, ahead, behind, err)
+		}
+	}
+
+	for _, ref := range []string{"refs/heads/feature", "refs/heads/loose", "refs/remotes/origin/" + base, "refs/heads/missing", "refs/remotes/origin/missing"} {
+		_, missing := gitOutputIn(repoRoot, "show-ref", "--verify", "--quiet", ref)
+		want := missing == nil
+		if got, err := reader.refExists(ref); err != nil || got != want {
+			t.Errorf("expected %s to exist: %v, got %v (%v)", ref, want, got, err)
+		}
+	}
+
+	if ref, err := reader.symbolicRef("refs/remotes/origin/HEAD"); err != nil || ref != "refs/remotes/origin/"+base {
+		t.Errorf("expected origin/HEAD to point at origin's %s, got %q (%v)", base, ref, err)
+	}
+	if ref, err := reader.symbolicRef("refs/heads/feature"); err != nil || ref != "" {
+		t.Errorf("expected a branch not to be a symbolic ref, got %q (%v)", ref, err)
+	}
+
+	times, err := reader.commitTimes(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := wm.branchCommitTimesFor([]string{base, "feature", "loose"}, nil)
+	if len(times) != len(want) {
+		t.Errorf("expected times for %v, got %v", want, times)
+	}
+	for branch, when := range want {
+		if !times[branch].Equal(when) {
+			t.Errorf("expected %s last committed at %s, got %s", branch, when, times[branch])
+		}
+	}
+}
+
+// benchmarkRepo is a repository with count worktrees, each a commit ahead of
+// the default branch
+func benchmarkRepo(b *testing.B, count int) (string, string) {
+	b.Helper()
+	repoRoot := initTestRepo(b)
+	base := mustGitOutput(b, repoRoot, "symbolic-ref", "--short", "HEAD")
+	worktrees := b.TempDir()
+	for i := range count {
+		path := filepath.Join(worktrees, fmt.Sprintf("feature-%d", i))
+		runGitCommand(b, repoRoot, "worktree", "add", "-b", fmt.Sprintf("feature-%d", i), path)
+		commitOn(b, path, "Work")
+	}
+	return repoRoot, base
+}
+
+func mustGitOutput(b *testing.B, dir string, args ...string) string {
+	b.Helper()
+	output, err := gitOutputIn(dir, args...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return output
+}
+
+// BenchmarkListWorktrees compares listing worktrees and how far each is ahead
+// of the default branch, as sprout list and the TUI do, with git and go-git
+func BenchmarkListWorktrees(b *testing.B) {
+	repoRoot, base := benchmarkRepo(b, 50)
+	list := func(b *testing.B, wm *WorktreeManager) {
+		for b.Loop() {
+			worktrees, err := wm.listWorktrees()
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, wt := range worktrees[1:] {
+				if ahead, _ := wm.aheadBehind(wt.Branch, base); ahead != 1 {
+					b.Fatalf("expected %s a commit ahead, got %d", wt.Branch, ahead)
+				}
+			}
+		}
+	}
+
+	b.Run("git", func(b *testing.B) {
+		wm := &WorktreeManager{repoRoot: repoRoot}
+		wm.readerOnce.Do(func() {}) // leaves the reader unopened, so git answers
+		list(b, wm)
+	})
+	b.Run("go-git", func(b *testing.B) {
+		wm := &WorktreeManager{repoRoot: repoRoot}
+		if wm.refs() == nil {
+			b.Fatal("expected go-git to read the repository")
+		}
+		list(b, wm)
+	})
+}
+
+// BenchmarkDefaultBase compares resolving the default branch to start from,
+// which checks several refs, with git and go-git
+func BenchmarkDefaultBase(b *testing.B) {
+	repoRoot, _ := benchmarkRepo(b, 50)
+	resolve := func(b *testing.B, wm *WorktreeManager) {
+		for b.Loop() {
+			if _, err := wm.defaultBase(false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("git", func(b *testing.B) {
+		wm := &WorktreeManager{repoRoot: repoRoot}
+		wm.readerOnce.Do(func() {})
+		resolve(b, wm)
+	})
+	b.Run("go-git", func(b *testing.B) {
+		resolve(b, &WorktreeManager{repoRoot: repoRoot})
+	})
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sprout/pkg/metadata"
+)
+
+// RenameWorktree renames oldBranch to newBranch and moves its worktree to
+// where a worktree for newBranch belongs, keeping sprout's records in step.
+// newBranch is sanitized the way CreateWorktree sanitizes branch names.
+func (wm *WorktreeManager) RenameWorktree(oldBranch, newBranch string) (Worktree, error) {
+	if oldBranch == "" {
+		return Worktree{}, fmt.Errorf("branch name cannot be empty")
+	}
+	sanitized, err := ValidateBranchName(newBranch)
+	if err != nil {
+		return Worktree{}, err
+	}
+	if sanitized == oldBranch {
+		return Worktree{}, fmt.Errorf("%s is already called that", oldBranch)
+	}
+
+	worktrees, err := wm.gitWorktrees()
+	if err != nil {
+		return Worktree{}, err
+	}
+	var renamed *Worktree
+	for i := range worktrees {
+		if worktrees[i].Branch == oldBranch {
+			renamed = &worktrees[i]
+			break
+		}
+	}
+	if renamed == nil {
+		return Worktree{}, fmt.Errorf("worktree does not exist: %s", oldBranch)
+	}
+	if renamed.Path == wm.repoRoot {
+		return Worktree{}, fmt.Errorf("%s is checked out in the main worktree, which can't be moved", oldBranch)
+	}
+	if renamed.Pinned {
+		return Worktree{}, fmt.Errorf("%s is pinned; sprout unpin %s first to rename it", oldBranch, oldBranch)
+	}
+	if wm.branchExists("refs/heads/" + sanitized) {
+		return Worktree{}, fmt.Errorf("branch %s already exists", sanitized)
+	}
+
+	cfg, err := wm.loadConfig()
+	if err != nil {
+		wm.presenter().Warning(fmt.Sprintf("failed to load config, using default worktree path: %v", err))
+	}
+	newPath := wm.resolveWorktreePath(cfg, sanitized)
+	if _, err := os.Stat(newPath); err == nil {
+		return Worktree{}, fmt.Errorf("%s already exists", newPath)
+	}
+
+	if output, err := wm.gitCommand(wm.repoRoot, "branch", "-m", oldBranch, sanitized).CombinedOutput(); err != nil {
+		return Worktree{}, fmt.Errorf("failed to rename branch: %w\nOutput: %s", err, string(output))
+	}
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		wm.undoBranchRename(sanitized, oldBranch)
+		return Worktree{}, fmt.Errorf("failed to create worktree base directory: %w", err)
+	}
+	if output, err := wm.gitCommand(wm.repoRoot, "worktree", "move", renamed.Path, newPath).CombinedOutput(); err != nil {
+		wm.undoBranchRename(sanitized, oldBranch)
+		return Worktree{}, fmt.Errorf("failed to move the worktree: %w\nOutput: %s", err, string(output))
+	}
+
+	wm.metadata.RecordRenamed(oldBranch, sanitized, renamed.Path, newPath)
+	wm.publish(metadata.HistoryEvent{Kind: metadata.HistoryWorktreeRenamed, Branch: sanitized, Path: newPath, Detail: "from " + oldBranch})
+
+	renamed.Branch = sanitized
+	renamed.Path = newPath
+	return *renamed, nil
+}
+
+// undoBranchRename puts a branch back after the worktree it belongs to
+// couldn't be moved, so a failed rename leaves things as they were
+func (wm *WorktreeManager) undoBranchRename(from, to string) {
+	if output, err := wm.gitCommand(wm.repoRoot, "branch", "-m", from, to).CombinedOutput(); err != nil {
+		wm.presenter().Warning(fmt.Sprintf("failed to rename branch '%s' back to '%s': %v\nOutput: %s", from, to, err, string(output)))
+	}
+}
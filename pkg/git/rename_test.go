@@ -0,0 +1,92 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+)
+
+func TestRenameWorktreeMovesBranchAndDirectory(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	basePath := t.TempDir()
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: &config.Config{WorktreeBasePath: basePath}},
+	}
+
+	oldPath, err := wm.CreateWorktree("feature-tpyo")
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	head := currentCommit(t, oldPath, "HEAD")
+	if err := os.WriteFile(filepath.Join(oldPath, "untracked.txt"), []byte("keep me\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	renamed, err := wm.RenameWorktree("feature-tpyo", "Feature Typo")
+	if err != nil {
+		t.Fatalf("RenameWorktree failed: %v", err)
+	}
+	if renamed.Branch != "feature-typo" || renamed.Path != filepath.Join(basePath, "feature-typo") {
+		t.Fatalf("Expected feature-typo at %s, got %+v", filepath.Join(basePath, "feature-typo"), renamed)
+	}
+	if wm.branchExists("refs/heads/feature-tpyo") {
+		t.Fatal("Expected the old branch to be gone")
+	}
+	if branch := currentCommit(t, repoRoot, "feature-typo"); branch != head {
+		t.Fatalf("Expected feature-typo at %s, got %s", head, branch)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Fatalf("Expected the old directory to be gone, stat returned %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(renamed.Path, "untracked.txt")); err != nil {
+		t.Fatalf("Expected untracked files to move with the worktree: %v", err)
+	}
+
+	existing, err := wm.FindExisting("feature-typo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if existing.WorktreePath != renamed.Path {
+		t.Fatalf("Expected git to know the worktree's new path, got %q", existing.WorktreePath)
+	}
+	status, err := wm.gitCommand(renamed.Path, "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(status) != "?? untracked.txt\n" {
+		t.Fatalf("Expected only the untracked file in status, got:\n%s", status)
+	}
+}
+
+func TestRenameWorktreeRefusesNamesAlreadyTaken(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: &config.Config{WorktreeBasePath: t.TempDir()}},
+	}
+
+	path, err := wm.CreateWorktree("first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repoRoot, "branch", "taken")
+
+	if _, err := wm.RenameWorktree("first", "taken"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("Expected an error about the existing branch, got %v", err)
+	}
+	if _, err := wm.RenameWorktree("missing", "anything"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("Expected an error about the missing worktree, got %v", err)
+	}
+	if !wm.branchExists("refs/heads/first") {
+		t.Fatal("Expected first to be left alone")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected first's worktree to be left alone: %v", err)
+	}
+}
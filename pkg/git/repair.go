@@ -0,0 +1,63 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sprout/pkg/metadata"
+)
+
+// RepairReport lists what Repair fixed, and what it found but left for the user
+type RepairReport struct {
+	PrunedWorktrees   []string   // stale entries git dropped because their directory was deleted
+	MissingBranches   []Worktree // worktrees whose branch no longer exists
+	ForgottenBranches []string   // branches whose sprout records were closed because the worktree is gone
+}
+
+// Empty reports whether there was nothing to repair
+func (r *RepairReport) Empty() bool {
+	return len(r.PrunedWorktrees) == 0 && len(r.MissingBranches) == 0 && len(r.ForgottenBranches) == 0
+}
+
+// Repair drops git's records of worktrees deleted by hand, finds worktrees whose
+// branch has gone, and closes sprout's records of worktrees that no longer exist
+func (wm *WorktreeManager) Repair() (*RepairReport, error) {
+	before, err := wm.gitWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := wm.gitCommand(wm.repoRoot, "worktree", "prune")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to prune worktrees: %w\nOutput: %s", err, string(output))
+	}
+
+	after, err := wm.gitWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RepairReport{}
+	remaining := make(map[string]bool, len(after))
+	for _, wt := range after {
+		remaining[filepath.Clean(wt.Path)] = true
+		if wt.Branch != "" && !wm.branchExists("refs/heads/"+wt.Branch) {
+			report.MissingBranches = append(report.MissingBranches, wt)
+		}
+	}
+	for _, wt := range before {
+		if !remaining[filepath.Clean(wt.Path)] {
+			report.PrunedWorktrees = append(report.PrunedWorktrees, wt.Path)
+		}
+	}
+
+	for _, record := range wm.metadata.Worktrees() {
+		if record.Active() && record.Path != "" && !remaining[filepath.Clean(record.Path)] {
+			wm.metadata.RecordPruned(record.Branch)
+			wm.publish(metadata.HistoryEvent{Kind: metadata.HistoryWorktreePruned, Branch: record.Branch, Path: record.Path, Detail: "after its directory was deleted"})
+			report.ForgottenBranches = append(report.ForgottenBranches, record.Branch)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sprout/pkg/metadata"
+)
+
+func TestRepairPrunesDeletedWorktreesAndReconcilesMetadata(t *testing.T) {
+	repoRoot := setupRepoWithFeatureWorktrees(t, "repair-deleted", "repair-orphaned", "repair-kept")
+	deletedPath := filepath.Join(filepath.Dir(repoRoot), "repair-deleted")
+	orphanedPath := filepath.Join(filepath.Dir(repoRoot), "repair-orphaned")
+	keptPath := filepath.Join(filepath.Dir(repoRoot), "repair-kept")
+
+	store := metadata.NewStoreWithPath(repoRoot, filepath.Join(t.TempDir(), "metadata.json"))
+	store.RecordCreated("repair-deleted", deletedPath)
+	store.RecordCreated("repair-kept", keptPath)
+	wm := &WorktreeManager{repoRoot: repoRoot, metadata: store}
+
+	if err := os.RemoveAll(deletedPath); err != nil {
+		t.Fatal(err)
+	}
+	// Deleting the ref directly leaves the worktree on a branch that no longer exists
+	runGit(t, repoRoot, "update-ref", "-d", "refs/heads/repair-orphaned")
+
+	report, err := wm.Repair()
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+
+	if len(report.PrunedWorktrees) != 1 || filepath.Base(report.PrunedWorktrees[0]) != "repair-deleted" {
+		t.Fatalf("Expected the deleted worktree to be pruned, got %v", report.PrunedWorktrees)
+	}
+	if len(report.MissingBranches) != 1 || filepath.Base(report.MissingBranches[0].Path) != filepath.Base(orphanedPath) {
+		t.Fatalf("Expected the orphaned worktree to be reported, got %v", report.MissingBranches)
+	}
+	if len(report.ForgottenBranches) != 1 || report.ForgottenBranches[0] != "repair-deleted" {
+		t.Fatalf("Expected the deleted worktree's record to be closed, got %v", report.ForgottenBranches)
+	}
+	for _, record := range store.Worktrees() {
+		if record.Active() != (record.Branch == "repair-kept") {
+			t.Fatalf("Unexpected record state for %s: active=%v", record.Branch, record.Active())
+		}
+	}
+	if events := store.History(time.Time{}); len(events) != 1 || events[0].Kind != metadata.HistoryWorktreePruned || events[0].Branch != "repair-deleted" {
+		t.Fatalf("Expected the prune to be logged, got %+v", events)
+	}
+
+	report, err = wm.Repair()
+	if err != nil {
+		t.Fatalf("Second repair failed: %v", err)
+	}
+	if len(report.PrunedWorktrees) != 0 || len(report.ForgottenBranches) != 0 {
+		t.Fatalf("Expected nothing left to fix, got %+v", report)
+	}
+}
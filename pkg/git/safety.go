@@ -0,0 +1,74 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sprout/pkg/config"
+)
+
+// checkRemovable refuses to let a prune delete worktreePath unless it's one
+// of this repository's worktrees and sits where sprout puts them. A branch
+// name is turned into a path before anything is deleted, so a name that
+// doesn't belong to this repository, or a layout where worktrees share a
+// directory with other projects, mustn't be able to point it somewhere else
+func (wm *WorktreeManager) checkRemovable(cfg *config.Config, branchName, worktreePath string) error {
+	target := canonicalPath(worktreePath)
+	repoRoot := canonicalPath(wm.repoRoot)
+	if target == repoRoot {
+		return fmt.Errorf("refusing to remove %s: it's the main checkout of %s", worktreePath, wm.repoRoot)
+	}
+	if isWithin(repoRoot, target) {
+		return fmt.Errorf("refusing to remove %s: the main checkout %s is inside it", worktreePath, wm.repoRoot)
+	}
+
+	if base, includesBranch := wm.getWorktreeBasePath(cfg, branchName); !includesBranch {
+		if !isWithin(target, canonicalPath(base)) {
+			return fmt.Errorf("refusing to remove %s: it's outside the worktrees directory %s", worktreePath, base)
+		}
+	}
+
+	worktrees, err := wm.gitWorktrees()
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		if !wt.Bare && canonicalPath(wt.Path) == target {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to remove %s: git doesn't list it as a worktree of %s", worktreePath, wm.repoRoot)
+}
+
+// canonicalPath is path made absolute with symlinks resolved, so two routes
+// to the same directory compare equal; what doesn't exist is only cleaned
+func canonicalPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// isWithin is whether path is strictly inside dir
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// gitError is err with what git printed to stderr, when it printed anything,
+// which says far more than an exit status
+func gitError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if msg := strings.TrimSpace(string(exitErr.Stderr)); msg != "" {
+			return errors.New(msg)
+		}
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+)
+
+func TestPruneWorktreeRefusesPathsThatArentWorktrees(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	base := t.TempDir()
+	cfg := &config.Config{WorktreeBasePath: base}
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: cfg},
+	}
+	if _, err := wm.CreateWorktree("feature-safe"); err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+
+	// A directory beside the worktrees, one inside them that git doesn't
+	// know about, and the main checkout reached by climbing out of the base
+	outside := filepath.Join(filepath.Dir(base), filepath.Base(base)+"-notes")
+	stray := filepath.Join(base, "stray")
+	for _, dir := range []string{outside, stray} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { os.RemoveAll(outside) })
+	toRepo, err := filepath.Rel(base, repoRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		branch string
+		dir    string
+		want   string
+	}{
+		{branch: "../" + filepath.Base(outside), dir: outside, want: "outside the worktrees directory"},
+		{branch: "stray", dir: stray, want: "git doesn't list it as a worktree"},
+		{branch: toRepo, dir: repoRoot, want: "it's the main checkout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			for _, opts := range []PruneOptions{{DryRun: true}, {KeepBranch: true}, {Unlock: true, Permanent: true}} {
+				err := wm.PruneWorktree(tt.branch, opts)
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Fatalf("expected an error containing %q with %+v, got %v", tt.want, opts, err)
+				}
+			}
+			if _, err := os.Stat(tt.dir); err != nil {
+				t.Fatalf("expected %s left in place: %v", tt.dir, err)
+			}
+		})
+	}
+
+	if err := wm.PruneWorktree("feature-safe", PruneOptions{KeepBranch: true}); err != nil {
+		t.Fatalf("expected a real worktree to still be pruned: %v", err)
+	}
+}
+
+func TestPruneWorktreeLeavesOtherProjectsBesideABareLayout(t *testing.T) {
+	origin := initTestRepo(t)
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "project")
+	result, err := (&Cloner{}).Clone(origin, dir)
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	wm, err := NewWorktreeManagerForRepo(result.PrimaryWorktree)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	// In a bare layout worktrees sit beside the default branch, so a
+	// directory there is only pruned when git says it's a worktree
+	notes := filepath.Join(dir, "notes")
+	if err := os.MkdirAll(notes, 0755); err != nil {
+		t.Fatal(err)
+	}
+	err = wm.PruneWorktree("notes", PruneOptions{Permanent: true})
+	if err == nil || !strings.Contains(err.Error(), "git doesn't list it as a worktree") {
+		t.Fatalf("expected the unregistered directory refused, got %v", err)
+	}
+	if _, err := os.Stat(notes); err != nil {
+		t.Fatalf("expected %s left in place: %v", notes, err)
+	}
+
+	err = wm.PruneWorktree(filepath.Base(result.PrimaryWorktree), PruneOptions{Permanent: true})
+	if err == nil || !strings.Contains(err.Error(), "it's the main checkout") {
+		t.Fatalf("expected the default branch's checkout refused, got %v", err)
+	}
+}
+
+func TestFindRepoRootExplainsWhyItFailed(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".git"), []byte("gitdir: "+filepath.Join(dir, "missing")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := FindRepoRoot(dir)
+	if err == nil || !strings.Contains(err.Error(), dir+" isn't inside a git checkout") || !strings.Contains(err.Error(), "not a git repository") {
+		t.Fatalf("expected an error naming the directory and git's reason, got %v", err)
+	}
+	if _, err := NewWorktreeManagerForRepo(dir); err == nil {
+		t.Fatalf("expected no manager for a checkout whose repository is missing")
+	}
+}
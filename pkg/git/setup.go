@@ -0,0 +1,95 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sprout/pkg/progress"
+)
+
+// Setup says whether one of the steps that finish off a new worktree, such
+// as updating submodules, runs
+type Setup int
+
+const (
+	SetupDetect Setup = iota // run when the worktree turns out to need it
+	SetupAlways
+	SetupSkip
+)
+
+// SetupFromConfig turns a repo config setting into a Setup: unset detects,
+// true always runs the step and false skips it
+func SetupFromConfig(enabled *bool) Setup {
+	switch {
+	case enabled == nil:
+		return SetupDetect
+	case *enabled:
+		return SetupAlways
+	}
+	return SetupSkip
+}
+
+func (s Setup) runs(detected bool) bool {
+	return s == SetupAlways || (s == SetupDetect && detected)
+}
+
+// setUpWorktree fetches what a plain checkout leaves out: git hooks kept
+// outside the repository, submodules and the contents of files stored with
+// git-lfs
+func (wm *WorktreeManager) setUpWorktree(worktreePath string, opts CreateOptions) error {
+	presenter := progress.Or(opts.Progress)
+	if err := wm.shareGitHooks(worktreePath, opts.GitHooks, presenter); err != nil {
+		return err
+	}
+
+	if opts.Submodules.runs(hasSubmodules(worktreePath)) {
+		err := progress.Step(presenter, "Updating submodules (git submodule update --init --recursive)", func() error {
+			return wm.runSetupStep(worktreePath, "submodule", "update", "--init", "--recursive")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.LFS.runs(usesLFS(worktreePath)) {
+		if _, err := exec.LookPath("git-lfs"); err != nil {
+			if opts.LFS == SetupAlways {
+				return errors.New("git lfs pull needs git-lfs, which isn't installed")
+			}
+			presenter.Warning("skipping git lfs pull: the repo uses LFS but git-lfs isn't installed")
+			return nil
+		}
+		err := progress.Step(presenter, "Fetching LFS files (git lfs pull)", func() error {
+			return wm.runSetupStep(worktreePath, "lfs", "pull")
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wm *WorktreeManager) runSetupStep(worktreePath string, args ...string) error {
+	if output, err := wm.gitCommand(worktreePath, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %w\nOutput: %s", strings.Join(args, " "), err, string(output))
+	}
+	return nil
+}
+
+// hasSubmodules is whether the checkout declares any submodules
+func hasSubmodules(worktreePath string) bool {
+	_, err := os.Stat(filepath.Join(worktreePath, ".gitmodules"))
+	return err == nil
+}
+
+// usesLFS is whether the checkout's top-level .gitattributes hands any files
+// to the lfs filter
+func usesLFS(worktreePath string) bool {
+	data, err := os.ReadFile(filepath.Join(worktreePath, ".gitattributes"))
+	return err == nil && bytes.Contains(data, []byte("filter=lfs"))
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/progress"
+)
+
+func TestCreateWorktreeUpdatesSubmodules(t *testing.T) {
+	// Submodules cloned from a local path need file transport allowed
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	library := initTestRepo(t)
+	repoRoot := initTestRepo(t)
+	runGitCommand(t, repoRoot, "submodule", "add", library, "vendor/library")
+	runGitCommand(t, repoRoot, "commit", "-m", "Add library")
+
+	wm, err := NewWorktreeManagerForRepo(repoRoot)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	var out bytes.Buffer
+	worktreePath, err := wm.CreateWorktreeWithOptions("with-library", CreateOptions{Progress: progress.NewText(&out)})
+	if err != nil {
+		t.Fatalf("CreateWorktreeWithOptions failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktreePath, "vendor", "library", "README.md")); err != nil {
+		t.Fatalf("Expected the submodule checked out in the new worktree: %v", err)
+	}
+	if !strings.Contains(out.String(), "git submodule update --init --recursive") {
+		t.Fatalf("Expected progress for the submodule update, got %q", out.String())
+	}
+
+	worktreePath, err = wm.CreateWorktreeWithOptions("without-library", CreateOptions{Submodules: SetupSkip})
+	if err != nil {
+		t.Fatalf("CreateWorktreeWithOptions failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktreePath, "vendor", "library", "README.md")); err == nil {
+		t.Fatal("Expected the submodule left alone when skipped")
+	}
+}
+
+func TestCreateWorktreeDetectsLFS(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	attributes := filepath.Join(repoRoot, ".gitattributes")
+	if err := os.WriteFile(attributes, []byte("*.bin filter=lfs diff=lfs merge=lfs -text\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGitCommand(t, repoRoot, "add", ".gitattributes")
+	runGitCommand(t, repoRoot, "commit", "-m", "Track binaries with LFS")
+
+	wm, err := NewWorktreeManagerForRepo(repoRoot)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	var out bytes.Buffer
+	worktreePath, err := wm.CreateWorktreeWithOptions("assets", CreateOptions{Progress: progress.NewText(&out)})
+	if _, lookErr := exec.LookPath("git-lfs"); lookErr != nil {
+		if err != nil {
+			t.Fatalf("Expected a missing git-lfs to be skipped, got %v", err)
+		}
+		if !strings.Contains(out.String(), "skipping git lfs pull") {
+			t.Fatalf("Expected a note that git lfs pull was skipped, got %q", out.String())
+		}
+		if _, err := wm.CreateWorktreeWithOptions("assets-required", CreateOptions{LFS: SetupAlways}); err == nil || !strings.Contains(err.Error(), "git-lfs") {
+			t.Fatalf("Expected LFS configured on to fail without git-lfs, got %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("CreateWorktreeWithOptions failed in %s: %v", worktreePath, err)
+	}
+	if !strings.Contains(out.String(), "git lfs pull") {
+		t.Fatalf("Expected progress for git lfs pull, got %q", out.String())
+	}
+}
+
+func TestSetupFromConfig(t *testing.T) {
+	on, off := true, false
+	if SetupFromConfig(nil) != SetupDetect || SetupFromConfig(&on) != SetupAlways || SetupFromConfig(&off) != SetupSkip {
+		t.Fatal("Expected unset to detect, true to always run and false to skip")
+	}
+}
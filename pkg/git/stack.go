@@ -0,0 +1,223 @@
+package git
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sprout/pkg/progress"
+)
+
+// Stack is the stack of branches built on one another that a branch is part
+// of, as sprout stack shows it
+type Stack struct {
+	Branch   string        // the branch the stack was found for
+	Trunk    string        // the default branch the bottom of the stack is on
+	Branches []StackBranch // from the bottom up, each followed by the branches built on it
+}
+
+// StackBranch is one of a stack of branches built on one another, each
+// created from the one before with sprout create --base-from-issue or a
+// template's base
+type StackBranch struct {
+	Branch string
+	Parent string // the branch it was created from, or the default branch at the bottom of the stack
+	Path   string // its worktree, or "" when it has none
+	Depth  int    // how many branches it's built on, 0 at the bottom of the stack
+	Ahead  int    // commits on Branch that Parent doesn't have
+	Behind int    // commits on Parent that Branch doesn't have, which restacking brings in
+}
+
+// Stack finds the stack branchName is part of: the chain of branches it was
+// built on, from the bottom up, then branchName, then the branches built on
+// it, each followed by its own. An empty branchName means the branch
+// checked out here. A parent branch that's been deleted, such as once it's
+// merged, no longer holds up the branches built on it
+func (wm *WorktreeManager) Stack(branchName string) (Stack, error) {
+	if branchName == "" {
+		here, err := wm.branchHere()
+		if err != nil {
+			return Stack{}, err
+		}
+		branchName = here
+	}
+	if !wm.branchExists("refs/heads/" + branchName) {
+		return Stack{}, fmt.Errorf("branch %s does not exist", branchName)
+	}
+
+	parents := wm.stackParents()
+	chain := []string{branchName}
+	for parent, ok := parents[branchName]; ok; parent, ok = parents[parent] {
+		if slices.Contains(chain, parent) {
+			break
+		}
+		chain = append([]string{parent}, chain...)
+	}
+
+	children := make(map[string][]string)
+	for branch, parent := range parents {
+		children[parent] = append(children[parent], branch)
+	}
+	for _, branches := range children {
+		sort.Strings(branches)
+	}
+
+	trunk, err := wm.getBaseBranch()
+	if err != nil {
+		return Stack{}, err
+	}
+	paths := make(map[string]string)
+	if worktrees, err := wm.gitWorktrees(); err == nil {
+		for _, wt := range worktrees {
+			paths[wt.Branch] = wt.Path
+		}
+	}
+
+	stack := Stack{Branch: branchName, Trunk: trunk}
+	for depth, branch := range chain {
+		parent := trunk
+		if depth > 0 {
+			parent = chain[depth-1]
+		}
+		stack.Branches = append(stack.Branches, wm.stackBranch(branch, parent, depth, paths))
+	}
+	seen := map[string]bool{}
+	for _, branch := range chain {
+		seen[branch] = true
+	}
+	var addChildren func(branch string, depth int)
+	addChildren = func(branch string, depth int) {
+		for _, child := range children[branch] {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			stack.Branches = append(stack.Branches, wm.stackBranch(child, branch, depth+1, paths))
+			addChildren(child, depth+1)
+		}
+	}
+	addChildren(branchName, len(chain)-1)
+	return stack, nil
+}
+
+// Restack rebases each branch in branchName's stack onto the one it was
+// built on, from the bottom up, so they all carry the latest work beneath
+// them. Only the commits a branch added are replayed, even when its parent
+// was amended or rebased since. The bottom of the stack is left on the
+// default branch where it is. Every branch being rebased needs a worktree
+// without uncommitted changes. A rebase that conflicts is aborted, leaving
+// that branch and those above it as they were. It returns the stack as it
+// ends up
+func (wm *WorktreeManager) Restack(branchName string, presenter progress.Presenter) (Stack, error) {
+	stack, err := wm.Stack(branchName)
+	if err != nil {
+		return stack, err
+	}
+
+	tips := make(map[string]string)
+	for _, entry := range stack.Branches {
+		if entry.Depth == 0 {
+			continue
+		}
+		if entry.Path == "" {
+			return stack, fmt.Errorf("%s has no worktree to rebase in; sprout create %s gives it one", entry.Branch, entry.Branch)
+		}
+		if wm.HasUncommittedChanges(entry.Path) {
+			return stack, fmt.Errorf("%s has uncommitted changes; commit or stash them before restacking", entry.Branch)
+		}
+	}
+	for _, entry := range stack.Branches {
+		output, err := wm.gitCommand(wm.repoRoot, "rev-parse", "refs/heads/"+entry.Branch).Output()
+		if err != nil {
+			return stack, fmt.Errorf("failed to read %s: %w", entry.Branch, err)
+		}
+		tips[entry.Branch] = strings.TrimSpace(string(output))
+	}
+
+	for _, entry := range stack.Branches {
+		if entry.Depth == 0 {
+			continue
+		}
+		forkPoint, err := wm.forkPoint(entry.Branch, entry.Parent, tips[entry.Parent])
+		if err != nil {
+			return stack, err
+		}
+		err = progress.Step(presenter, fmt.Sprintf("Rebasing %s onto %s", entry.Branch, entry.Parent), func() error {
+			if output, err := wm.gitCommand(entry.Path, "rebase", "--onto", entry.Parent, forkPoint).CombinedOutput(); err != nil {
+				_ = wm.gitCommand(entry.Path, "rebase", "--abort").Run()
+				return fmt.Errorf("rebasing %s onto %s conflicts, so it and the branches above it were left as they were: %w\nOutput: %s", entry.Branch, entry.Parent, err, string(output))
+			}
+			return nil
+		})
+		if err != nil {
+			return stack, err
+		}
+	}
+	return wm.Stack(stack.Branch)
+}
+
+// forkPoint is where branch left parent, so the commits after it are the
+// ones branch added. parent's reflog remembers where it was before being
+// amended or rebased; failing that, parentTip is where it was before
+// restacking began
+func (wm *WorktreeManager) forkPoint(branch, parent, parentTip string) (string, error) {
+	output, err := wm.gitCommand(wm.repoRoot, "merge-base", "--fork-point", "refs/heads/"+parent, "refs/heads/"+branch).Output()
+	if err != nil {
+		if output, err = wm.gitCommand(wm.repoRoot, "merge-base", parentTip, "refs/heads/"+branch).Output(); err != nil {
+			return "", fmt.Errorf("%s has nothing in common with %s: %w", branch, parent, err)
+		}
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// stackParents is the branch each branch was created from, leaving out
+// parents that have since been deleted
+func (wm *WorktreeManager) stackParents() map[string]string {
+	parents := make(map[string]string)
+	for branch, parent := range wm.metadata.Parents() {
+		if wm.branchExists("refs/heads/" + parent) {
+			parents[branch] = parent
+		}
+	}
+	return parents
+}
+
+// stackBranch is branch as it stands on parent
+func (wm *WorktreeManager) stackBranch(branch, parent string, depth int, paths map[string]string) StackBranch {
+	entry := StackBranch{Branch: branch, Parent: parent, Path: paths[branch], Depth: depth}
+	entry.Ahead, entry.Behind = wm.aheadBehind(branch, parent)
+	return entry
+}
+
+// aheadBehind counts the commits on branch that base doesn't have, and
+// those on base that branch doesn't
+func (wm *WorktreeManager) aheadBehind(branch, base string) (int, int) {
+	if reader := wm.refs(); reader != nil {
+		if ahead, behind, err := reader.aheadBehind(branch, base); err == nil {
+			return ahead, behind
+		}
+	}
+	output, err := wm.gitCommand(wm.repoRoot, "rev-list", "--left-right", "--count", branch+"..."+base).Output()
+	if err != nil {
+		return 0, 0
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0
+	}
+	ahead, _ := strconv.Atoi(fields[0])
+	behind, _ := strconv.Atoi(fields[1])
+	return ahead, behind
+}
+
+// branchHere is the branch checked out where sprout is run
+func (wm *WorktreeManager) branchHere() (string, error) {
+	output, err := wm.gitCommand("", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	branch := strings.TrimSpace(string(output))
+	if err != nil || branch == "HEAD" {
+		return "", fmt.Errorf("not on a branch; name the branch to use")
+	}
+	return branch, nil
+}
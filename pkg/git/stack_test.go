@@ -0,0 +1,84 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/progress"
+)
+
+func TestRestackReplaysEachBranchOntoTheOneBelow(t *testing.T) {
+	wm := newConfiguredTestManager(t, &config.Config{})
+	commit := func(dir, file, message string, args ...string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(message+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, "add", file)
+		runGit(t, dir, append([]string{"commit", "-m", message}, args...)...)
+	}
+
+	auditLog, err := wm.CreateWorktree("audit-log")
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	commit(auditLog, "audit.go", "Log audits")
+	auditTests, err := wm.CreateWorktreeWithOptions("audit-tests", CreateOptions{BaseBranch: "audit-log", Progress: progress.Discard})
+	if err != nil {
+		t.Fatalf("Failed to create stacked worktree: %v", err)
+	}
+	commit(auditTests, "audit_test.go", "Test audits")
+	auditDocs, err := wm.CreateWorktreeWithOptions("audit-docs", CreateOptions{BaseBranch: "audit-tests", Progress: progress.Discard})
+	if err != nil {
+		t.Fatalf("Failed to create stacked worktree: %v", err)
+	}
+	commit(auditDocs, "AUDIT.md", "Document audits")
+
+	// Review comments on the bottom branch: its commit is amended and another added
+	commit(auditLog, "audit.go", "Log audits properly", "--amend")
+	commit(auditLog, "retention.go", "Keep audits a year")
+
+	stack, err := wm.Stack("audit-tests")
+	if err != nil {
+		t.Fatalf("Stack failed: %v", err)
+	}
+	var got []string
+	for _, entry := range stack.Branches {
+		got = append(got, strings.Repeat(" ", entry.Depth)+entry.Branch)
+	}
+	if strings.Join(got, ",") != "audit-log, audit-tests,  audit-docs" || stack.Branch != "audit-tests" {
+		t.Fatalf("Expected audit-log, audit-tests then audit-docs stacked, got %q", got)
+	}
+	if tests := stack.Branches[1]; tests.Parent != "audit-log" || tests.Path != auditTests || tests.Ahead != 2 || tests.Behind != 2 {
+		t.Fatalf("Expected audit-tests 2 ahead of and 2 behind audit-log, got %+v", tests)
+	}
+
+	if err := os.WriteFile(filepath.Join(auditDocs, "AUDIT.md"), []byte("draft\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wm.Restack("audit-tests", progress.Discard); err == nil || !strings.Contains(err.Error(), "audit-docs has uncommitted changes") {
+		t.Fatalf("Expected restacking with uncommitted changes to refuse, got %v", err)
+	}
+	runGit(t, auditDocs, "checkout", "AUDIT.md")
+
+	stack, err = wm.Restack("audit-tests", progress.Discard)
+	if err != nil {
+		t.Fatalf("Restack failed: %v", err)
+	}
+	for _, entry := range stack.Branches[1:] {
+		if entry.Ahead != 1 || entry.Behind != 0 {
+			t.Fatalf("Expected %s to carry only its own commit on %s, got %+v", entry.Branch, entry.Parent, entry)
+		}
+	}
+	for _, file := range []string{"retention.go", "audit_test.go"} {
+		if _, err := os.Stat(filepath.Join(auditDocs, file)); err != nil {
+			t.Fatalf("Expected %s brought up the stack into audit-docs: %v", file, err)
+		}
+	}
+	if content, _ := os.ReadFile(filepath.Join(auditDocs, "audit.go")); string(content) != "Log audits properly\n" {
+		t.Fatalf("Expected the amended commit in audit-docs, got %q", content)
+	}
+}
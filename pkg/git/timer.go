@@ -0,0 +1,63 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"sprout/pkg/config"
+	"sprout/pkg/metadata"
+)
+
+// StartTimer runs timerStart, when it's set, for the worktree of branchName
+// at worktreePath, so a time tracker follows the worktree being worked in.
+// Creating a worktree starts it already; this is for opening one again
+func (wm *WorktreeManager) StartTimer(branchName, worktreePath string) error {
+	cfg, err := wm.loadConfig()
+	if err != nil {
+		return nil
+	}
+	return wm.startTimer(cfg, branchName, worktreePath)
+}
+
+func (wm *WorktreeManager) startTimer(cfg *config.Config, branchName, worktreePath string) error {
+	if cfg == nil {
+		return nil
+	}
+	return runTimer(cfg, "timerStart", cfg.TimerStartCommand(wm.timerContext(branchName, worktreePath)), worktreePath)
+}
+
+// stopTimer runs timerStop, when it's set, for a worktree that's been pruned.
+// It runs in the main checkout, as the worktree has gone
+func (wm *WorktreeManager) stopTimer(cfg *config.Config, branchName, worktreePath string) error {
+	if cfg == nil {
+		return nil
+	}
+	return runTimer(cfg, "timerStop", cfg.TimerStopCommand(wm.timerContext(branchName, worktreePath)), wm.repoRoot)
+}
+
+func (wm *WorktreeManager) timerContext(branchName, worktreePath string) config.TimerContext {
+	return config.TimerContext{
+		BranchName:   branchName,
+		IssueID:      metadata.IssueFromBranch(branchName),
+		WorktreePath: worktreePath,
+		RepoName:     wm.repoName,
+	}
+}
+
+// runTimer runs command in dir, giving up after the network timeout as time
+// trackers tend to call out to a service
+func runTimer(cfg *config.Config, setting string, command []string, dir string) error {
+	if len(command) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.NetworkTimeout())
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s %q failed: %w\nOutput: %s", setting, strings.Join(command, " "), err, string(output))
+	}
+	return nil
+}
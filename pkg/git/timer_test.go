@@ -0,0 +1,61 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/metadata"
+	"sprout/pkg/progress"
+)
+
+func newConfiguredTestManager(t *testing.T, cfg *config.Config) *WorktreeManager {
+	repoRoot := initTestRepo(t)
+	cfg.WorktreeBasePath = t.TempDir()
+	return &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: cfg},
+		metadata:     metadata.NewStoreWithPath(repoRoot, filepath.Join(t.TempDir(), "metadata.json")),
+	}
+}
+
+func TestTimerFollowsWorktreeFromCreationToPrune(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "timer.log")
+	wm := newConfiguredTestManager(t, &config.Config{
+		TimerStart: `sh -c 'echo start $BRANCH_NAME $ISSUE_ID >> ` + log + `'`,
+		TimerStop:  `sh -c 'echo stop $BRANCH_NAME $ISSUE_ID >> ` + log + `'`,
+	})
+
+	path, err := wm.CreateWorktree("spr-12-fix-login")
+	if err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	if err := wm.StartTimer("spr-12-fix-login", path); err != nil {
+		t.Fatalf("StartTimer failed: %v", err)
+	}
+	if err := wm.PruneWorktree("spr-12-fix-login", PruneOptions{Progress: progress.Discard}); err != nil {
+		t.Fatalf("PruneWorktree failed: %v", err)
+	}
+
+	got, _ := os.ReadFile(log)
+	want := "start spr-12-fix-login SPR-12\nstart spr-12-fix-login SPR-12\nstop spr-12-fix-login SPR-12\n"
+	if string(got) != want {
+		t.Fatalf("Expected the timer started on create and open and stopped on prune, got:\n%s", got)
+	}
+}
+
+func TestTimerThatFailsDoesNotFailCreation(t *testing.T) {
+	wm := newConfiguredTestManager(t, &config.Config{TimerStart: "false"})
+
+	var out bytes.Buffer
+	if _, err := wm.CreateWorktreeWithOptions("untracked", CreateOptions{Progress: progress.NewText(&out)}); err != nil {
+		t.Fatalf("Expected the worktree created anyway, got %v", err)
+	}
+	if !strings.Contains(out.String(), `Warning: timerStart "false" failed`) {
+		t.Fatalf("Expected a warning about the timer, got %q", out.String())
+	}
+}
@@ -0,0 +1,316 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sprout/pkg/config"
+	"sprout/pkg/metadata"
+)
+
+// TrashDirName is the directory among the worktrees that pruned worktrees wait
+// in until sprout undo brings them back or they expire
+const TrashDirName = ".trash"
+
+// TrashRefPrefix is where the commit each trashed worktree had checked out is
+// kept, so deleting its branch doesn't let git collect the commits
+const TrashRefPrefix = "refs/sprout/trash/"
+
+const (
+	trashManifest = "trash.json"
+	trashWorktree = "worktree"
+	trashGitFiles = "git"
+)
+
+// trashedGitFiles are the files git keeps for a worktree outside it that are
+// worth more than a fresh checkout gives back: what's staged and what's sparse
+var trashedGitFiles = []string{"index", "config.worktree", filepath.Join("info", "sparse-checkout")}
+
+// ErrNothingToUndo is returned by UndoPrune when the trash is empty
+var ErrNothingToUndo = errors.New("nothing to undo: no pruned worktrees are in the trash")
+
+// TrashedWorktree records a pruned worktree waiting in the trash
+type TrashedWorktree struct {
+	ID        string    `json:"id"`
+	Repo      string    `json:"repo"` // trash directories can be shared by repositories side by side
+	Branch    string    `json:"branch"`
+	Path      string    `json:"path"`
+	Head      string    `json:"head"`      // the commit the worktree had checked out, kept under TrashRefPrefix
+	Operation string    `json:"operation"` // worktrees pruned together share one, and are restored together
+	TrashedAt time.Time `json:"trashedAt"`
+	Dir       string    `json:"-"`
+}
+
+func newTrashID() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+// trashWorktree moves a worktree into the trash and unregisters it from git,
+// saving its commit under refs/sprout/trash so the branch can go. Anything
+// that goes wrong without stopping it is passed to warn
+func (wm *WorktreeManager) trashWorktree(cfg *config.Config, branchName, worktreePath, operation string, warn func(string)) error {
+	if err := wm.purgeExpiredTrash(cfg); err != nil {
+		warn(err.Error())
+	}
+
+	head, err := wm.gitCommand(worktreePath, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("failed to read the worktree's commit: %w", err)
+	}
+	gitDir, err := wm.gitCommand(worktreePath, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return fmt.Errorf("failed to find the worktree's git directory: %w", err)
+	}
+
+	entry := &TrashedWorktree{
+		Repo:      wm.repoRoot,
+		Branch:    branchName,
+		Path:      worktreePath,
+		Head:      strings.TrimSpace(string(head)),
+		TrashedAt: time.Now().UTC(),
+	}
+	if entry.Dir, entry.ID, err = makeTrashEntryDir(wm.trashDir(cfg)); err != nil {
+		return err
+	}
+	entry.Operation = operation
+	if entry.Operation == "" {
+		entry.Operation = entry.ID
+	}
+
+	if err := copyGitFiles(strings.TrimSpace(string(gitDir)), filepath.Join(entry.Dir, trashGitFiles)); err != nil {
+		os.RemoveAll(entry.Dir)
+		return fmt.Errorf("failed to save the worktree's index: %w", err)
+	}
+	if err := writeTrashManifest(entry); err != nil {
+		os.RemoveAll(entry.Dir)
+		return err
+	}
+	if output, err := wm.gitCommand(wm.repoRoot, "update-ref", TrashRefPrefix+entry.ID, entry.Head).CombinedOutput(); err != nil {
+		os.RemoveAll(entry.Dir)
+		return fmt.Errorf("failed to save %s's commit: %w\nOutput: %s", branchName, err, string(output))
+	}
+	if err := os.Rename(worktreePath, filepath.Join(entry.Dir, trashWorktree)); err != nil {
+		if cleanupErr := wm.deleteTrashEntry(entry); cleanupErr != nil {
+			warn(cleanupErr.Error())
+		}
+		return fmt.Errorf("failed to move the worktree: %w", err)
+	}
+
+	// The worktree's gone from where git expects it, which is what prune looks for
+	if output, err := wm.gitCommand(wm.repoRoot, "worktree", "prune").CombinedOutput(); err != nil {
+		warn(fmt.Sprintf("git worktree prune failed: %v\nOutput: %s", err, string(output)))
+	}
+	return nil
+}
+
+// UndoPrune restores the worktrees removed by the most recent prune still in
+// the trash, recreating their branches where the prune deleted them
+func (wm *WorktreeManager) UndoPrune() ([]TrashedWorktree, error) {
+	cfg, err := wm.loadConfig()
+	if err != nil {
+		wm.presenter().Warning(fmt.Sprintf("failed to load config, using default worktree path: %v", err))
+	}
+	// Whatever can't be emptied now is tried again by the next prune or gc,
+	// which report it
+	_ = wm.purgeExpiredTrash(cfg)
+
+	entries := wm.trashedWorktrees(cfg)
+	if len(entries) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	operation := entries[len(entries)-1].Operation
+
+	var restored []TrashedWorktree
+	var failures []string
+	for _, entry := range entries {
+		if entry.Operation != operation {
+			continue
+		}
+		if err := wm.restoreTrashed(&entry); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", entry.Branch, err))
+			continue
+		}
+		restored = append(restored, entry)
+	}
+	if len(failures) > 0 {
+		return restored, fmt.Errorf("failed to restore %d worktree(s), which are still in the trash:\n  %s", len(failures), strings.Join(failures, "\n  "))
+	}
+	return restored, nil
+}
+
+func (wm *WorktreeManager) restoreTrashed(entry *TrashedWorktree) error {
+	if _, err := os.Stat(entry.Path); err == nil {
+		return fmt.Errorf("%s already exists; remove it before undoing", entry.Path)
+	}
+
+	ref := "refs/heads/" + entry.Branch
+	if wm.branchExists(ref) {
+		tip, err := wm.gitCommand(wm.repoRoot, "rev-parse", ref).Output()
+		if err != nil || strings.TrimSpace(string(tip)) != entry.Head {
+			return fmt.Errorf("branch %s has moved on since it was pruned; rename or delete it before undoing", entry.Branch)
+		}
+	} else if output, err := wm.gitCommand(wm.repoRoot, "branch", entry.Branch, entry.Head).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore branch: %w\nOutput: %s", err, string(output))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(entry.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create worktree base directory: %w", err)
+	}
+	if output, err := wm.gitCommand(wm.repoRoot, "worktree", "add", "--no-checkout", entry.Path, entry.Branch).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to register the worktree: %w\nOutput: %s", err, string(output))
+	}
+
+	// Swap the fresh .git file, which points at the new registration, into the
+	// trashed directory and put that back where it was
+	trashed := filepath.Join(entry.Dir, trashWorktree)
+	if err := os.Remove(filepath.Join(trashed, ".git")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Rename(filepath.Join(entry.Path, ".git"), filepath.Join(trashed, ".git")); err != nil {
+		return err
+	}
+	if err := os.Remove(entry.Path); err != nil {
+		return err
+	}
+	if err := os.Rename(trashed, entry.Path); err != nil {
+		return err
+	}
+
+	gitDir, err := wm.gitCommand(entry.Path, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return fmt.Errorf("failed to find the worktree's git directory: %w", err)
+	}
+	if err := copyGitFiles(filepath.Join(entry.Dir, trashGitFiles), strings.TrimSpace(string(gitDir))); err != nil {
+		return fmt.Errorf("restored %s, but not its index: %w", entry.Path, err)
+	}
+	if _, err := os.Stat(filepath.Join(entry.Dir, trashGitFiles, "index")); os.IsNotExist(err) {
+		// Without the old index, rebuild one from the commit so nothing looks deleted
+		if output, err := wm.gitCommand(entry.Path, "reset", "--quiet").CombinedOutput(); err != nil {
+			return fmt.Errorf("restored %s, but not its index: %w\nOutput: %s", entry.Path, err, string(output))
+		}
+	}
+
+	wm.metadata.RecordCreated(entry.Branch, entry.Path)
+	wm.publish(metadata.HistoryEvent{Kind: metadata.HistoryWorktreeRestored, Branch: entry.Branch, Path: entry.Path, Detail: "from the trash"})
+	if err := wm.deleteTrashEntry(entry); err != nil {
+		return fmt.Errorf("restored %s, but %w", entry.Path, err)
+	}
+	return nil
+}
+
+// purgeExpiredTrash deletes whatever has been in the trash longer than
+// trashDays, and the commits kept for it
+func (wm *WorktreeManager) purgeExpiredTrash(cfg *config.Config) error {
+	cutoff := time.Now().Add(-cfg.TrashRetention())
+	var errs []error
+	for _, entry := range wm.trashedWorktrees(cfg) {
+		if entry.TrashedAt.Before(cutoff) {
+			errs = append(errs, wm.deleteTrashEntry(&entry))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// trashedWorktrees lists this repository's trash, oldest first
+func (wm *WorktreeManager) trashedWorktrees(cfg *config.Config) []TrashedWorktree {
+	dir := wm.trashDir(cfg)
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var entries []TrashedWorktree
+	for _, dirEntry := range dirEntries {
+		data, err := os.ReadFile(filepath.Join(dir, dirEntry.Name(), trashManifest))
+		if err != nil {
+			continue
+		}
+		var entry TrashedWorktree
+		if json.Unmarshal(data, &entry) != nil || entry.Repo != wm.repoRoot {
+			continue
+		}
+		entry.Dir = filepath.Join(dir, dirEntry.Name())
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].TrashedAt.Before(entries[j].TrashedAt)
+	})
+	return entries
+}
+
+// deleteTrashEntry empties entry from the trash, going as far as it can when
+// part of it won't go
+func (wm *WorktreeManager) deleteTrashEntry(entry *TrashedWorktree) error {
+	var errs []error
+	if output, err := wm.gitCommand(wm.repoRoot, "update-ref", "-d", TrashRefPrefix+entry.ID).CombinedOutput(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to delete %s%s: %w\nOutput: %s", TrashRefPrefix, entry.ID, err, string(output)))
+	}
+	if err := os.RemoveAll(entry.Dir); err != nil {
+		errs = append(errs, fmt.Errorf("failed to empty %s from the trash: %w", entry.Dir, err))
+	}
+	return errors.Join(errs...)
+}
+
+// trashDir is where pruned worktrees wait: a .trash directory alongside the
+// worktrees, or beside the default .worktrees when the configured path puts
+// each branch somewhere of its own
+func (wm *WorktreeManager) trashDir(cfg *config.Config) string {
+	basePath, includesBranch := wm.getWorktreeBasePath(cfg, "")
+	if includesBranch {
+		basePath = filepath.Join(filepath.Dir(wm.repoRoot), ".worktrees")
+	}
+	return filepath.Join(basePath, TrashDirName)
+}
+
+// makeTrashEntryDir creates a directory of its own for a trashed worktree
+func makeTrashEntryDir(trashDir string) (string, string, error) {
+	if err := os.MkdirAll(trashDir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	for {
+		id := newTrashID()
+		dir := filepath.Join(trashDir, id)
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			return dir, id, nil
+		}
+		if !os.IsExist(err) {
+			return "", "", fmt.Errorf("failed to create trash directory: %w", err)
+		}
+	}
+}
+
+func writeTrashManifest(entry *TrashedWorktree) error {
+	manifest, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(entry.Dir, trashManifest), manifest, 0644)
+}
+
+// copyGitFiles copies whichever of trashedGitFiles exist from one git
+// directory to another
+func copyGitFiles(from, to string) error {
+	for _, name := range trashedGitFiles {
+		data, err := os.ReadFile(filepath.Join(from, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(to, name)), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(to, name), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
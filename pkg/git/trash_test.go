@@ -0,0 +1,159 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sprout/pkg/config"
+	"sprout/pkg/progress"
+)
+
+func TestPruneMovesWorktreeToTrashAndUndoRestoresIt(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	cfg := &config.Config{WorktreeBasePath: t.TempDir()}
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: cfg},
+	}
+
+	worktreePath, err := wm.CreateWorktree("feature-trash")
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, "committed.txt"), []byte("committed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, worktreePath, "add", "committed.txt")
+	runGit(t, worktreePath, "commit", "-m", "Unmerged work")
+	head := currentCommit(t, worktreePath, "HEAD")
+	if err := os.WriteFile(filepath.Join(worktreePath, "staged.txt"), []byte("staged\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, worktreePath, "add", "staged.txt")
+	if err := os.WriteFile(filepath.Join(worktreePath, "untracked.txt"), []byte("untracked\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := wm.PruneWorktree("feature-trash", PruneOptions{Progress: progress.Discard}); err != nil {
+		t.Fatalf("PruneWorktree failed: %v", err)
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Fatalf("Expected the worktree to be gone, stat returned %v", err)
+	}
+	if wm.branchExists("refs/heads/feature-trash") {
+		t.Fatal("Expected the branch to be deleted")
+	}
+	trash := wm.trashedWorktrees(cfg)
+	if len(trash) != 1 || trash[0].Head != head {
+		t.Fatalf("Expected feature-trash in the trash at %s, got %+v", head, trash)
+	}
+	if saved := currentCommit(t, repoRoot, TrashRefPrefix+trash[0].ID); saved != head {
+		t.Fatalf("Expected the commit kept under %s, got %s", TrashRefPrefix, saved)
+	}
+
+	restored, err := wm.UndoPrune()
+	if err != nil {
+		t.Fatalf("UndoPrune failed: %v", err)
+	}
+	if len(restored) != 1 || restored[0].Path != worktreePath {
+		t.Fatalf("Expected feature-trash back at %s, got %+v", worktreePath, restored)
+	}
+	if branch := currentCommit(t, repoRoot, "feature-trash"); branch != head {
+		t.Fatalf("Expected the branch back at %s, got %s", head, branch)
+	}
+	status, err := wm.gitCommand(worktreePath, "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(status) != "A  staged.txt\n?? untracked.txt\n" {
+		t.Fatalf("Expected the staged and untracked files back as they were, got:\n%s", status)
+	}
+	if wm.branchExists(TrashRefPrefix + trash[0].ID) {
+		t.Fatal("Expected the trash ref to be deleted after undoing")
+	}
+	if _, err := wm.UndoPrune(); err != ErrNothingToUndo {
+		t.Fatalf("Expected nothing left to undo, got %v", err)
+	}
+}
+
+func TestUndoRestoresEveryMergedWorktreePrunedTogether(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: &config.Config{WorktreeBasePath: t.TempDir()}},
+	}
+
+	var paths []string
+	for _, branch := range []string{"first", "second", "third"} {
+		path, err := wm.CreateWorktree(branch)
+		if err != nil {
+			t.Fatalf("Failed to create worktree: %v", err)
+		}
+		paths = append(paths, path)
+	}
+	if err := wm.PruneWorktree("first", PruneOptions{Progress: progress.Discard}); err != nil {
+		t.Fatal(err)
+	}
+	batch := PruneOptions{Progress: progress.Discard, operation: newTrashID()}
+	for _, branch := range []string{"second", "third"} {
+		if err := wm.PruneWorktree(branch, batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	restored, err := wm.UndoPrune()
+	if err != nil {
+		t.Fatalf("UndoPrune failed: %v", err)
+	}
+	var branches []string
+	for _, wt := range restored {
+		branches = append(branches, wt.Branch)
+	}
+	if strings.Join(branches, ",") != "second,third" {
+		t.Fatalf("Expected the last prune's worktrees back, got %v", branches)
+	}
+	if _, err := os.Stat(paths[0]); !os.IsNotExist(err) {
+		t.Fatal("Expected the earlier prune to stay in the trash")
+	}
+
+	if restored, err = wm.UndoPrune(); err != nil || len(restored) != 1 || restored[0].Branch != "first" {
+		t.Fatalf("Expected a second undo to restore first, got %+v (%v)", restored, err)
+	}
+}
+
+func TestExpiredTrashIsEmptied(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	cfg := &config.Config{WorktreeBasePath: t.TempDir(), TrashDays: 1}
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: cfg},
+	}
+
+	if _, err := wm.CreateWorktree("stale"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wm.PruneWorktree("stale", PruneOptions{Progress: progress.Discard}); err != nil {
+		t.Fatal(err)
+	}
+	entry := wm.trashedWorktrees(cfg)[0]
+	entry.TrashedAt = time.Now().Add(-48 * time.Hour)
+	if err := writeTrashManifest(&entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := wm.UndoPrune(); err != ErrNothingToUndo {
+		t.Fatalf("Expected the expired worktree to be past undoing, got %v", err)
+	}
+	if _, err := os.Stat(entry.Dir); !os.IsNotExist(err) {
+		t.Fatalf("Expected the expired trash to be deleted, stat returned %v", err)
+	}
+	if wm.branchExists(TrashRefPrefix + entry.ID) {
+		t.Fatal("Expected the expired trash ref to be deleted")
+	}
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ReservedBranchNames are the usual names for a repository's main line, which
+// stays checked out in the main worktree rather than getting one of its own
+var ReservedBranchNames = []string{"main", "master", "head"}
+
+// Why ValidateBranchName refused a name, possibly wrapped with detail; match
+// them with errors.Is
+var (
+	ErrEmptyBranchName    = errors.New("branch name is empty")
+	ErrReservedBranchName = errors.New("branch name is reserved")
+	ErrInvalidBranchName  = errors.New("not a valid branch name")
+)
+
+// ValidateBranchName sanitizes name the way worktrees and branches are
+// created with it and reports whether the result can be used, so a name can
+// be checked before anything is created
+func ValidateBranchName(name string) (string, error) {
+	sanitized := sanitizeBranchName(name)
+	switch {
+	case sanitized == "":
+		if strings.TrimSpace(name) == "" {
+			return "", ErrEmptyBranchName
+		}
+		return "", fmt.Errorf("%w once the characters git can't use are removed from %q", ErrEmptyBranchName, name)
+	case slices.Contains(ReservedBranchNames, sanitized):
+		return sanitized, fmt.Errorf("%w: %s is the repository's main line; pick another name", ErrReservedBranchName, sanitized)
+	}
+	if problem := refFormatProblem(sanitized); problem != "" {
+		return sanitized, fmt.Errorf("%w: %s %s", ErrInvalidBranchName, sanitized, problem)
+	}
+	return sanitized, nil
+}
+
+// refFormatProblem describes what git check-ref-format would object to in a
+// sanitized branch name, or returns "" when there's nothing
+func refFormatProblem(name string) string {
+	switch {
+	case strings.Contains(name, ".."):
+		return "contains .."
+	case strings.Contains(name, "//"):
+		return "contains //"
+	case strings.HasSuffix(name, "/"):
+		return "ends with /"
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".lock") {
+			return "has a part starting with . or ending with .lock"
+		}
+	}
+	return ""
+}
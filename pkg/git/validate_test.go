@@ -0,0 +1,46 @@
+package git
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateBranchName(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		sanitized string
+		err       error
+	}{
+		{"Fix Login Bug", "fix-login-bug", nil},
+		{"feature/search", "feature/search", nil},
+		{"", "", ErrEmptyBranchName},
+		{"!!!", "", ErrEmptyBranchName},
+		{"main", "main", ErrReservedBranchName},
+		{"HEAD", "head", ErrReservedBranchName},
+		{"fix..typo", "fix..typo", ErrInvalidBranchName},
+		{"feature//search", "feature//search", ErrInvalidBranchName},
+		{"feature/.hidden", "feature/.hidden", ErrInvalidBranchName},
+		{"refs.lock/feature", "refs.lock/feature", ErrInvalidBranchName},
+	} {
+		sanitized, err := ValidateBranchName(tc.name)
+		if sanitized != tc.sanitized {
+			t.Errorf("ValidateBranchName(%q) sanitized to %q, expected %q", tc.name, sanitized, tc.sanitized)
+		}
+		if tc.err == nil && err != nil {
+			t.Errorf("ValidateBranchName(%q) failed: %v", tc.name, err)
+		}
+		if tc.err != nil && !errors.Is(err, tc.err) {
+			t.Errorf("ValidateBranchName(%q) returned %v, expected %v", tc.name, err, tc.err)
+		}
+	}
+}
+
+func TestCreateWorktreeRefusesReservedNames(t *testing.T) {
+	wm := &WorktreeManager{repoRoot: t.TempDir()}
+	if _, err := wm.CreateWorktree("master"); !errors.Is(err, ErrReservedBranchName) {
+		t.Fatalf("expected master to be refused, got %v", err)
+	}
+	if err := wm.CreateBranch("main"); !errors.Is(err, ErrReservedBranchName) {
+		t.Fatalf("expected main to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package git
+
+import (
+	"sprout/pkg/config"
+	"sprout/pkg/metadata"
+	"sprout/pkg/webhook"
+)
+
+// postToWebhook posts the events the webhook hears of to it, keeping the
+// error for WaitForNotifications when it can't be reached
+func (wm *WorktreeManager) postToWebhook(event any) {
+	var name, branch, path string
+	switch event := event.(type) {
+	case metadata.HistoryEvent:
+		switch event.Kind {
+		case metadata.HistoryWorktreeCreated:
+			name = config.WebhookWorktreeCreated
+		case metadata.HistoryWorktreePruned:
+			name = config.WebhookWorktreeDeleted
+		default:
+			return
+		}
+		branch, path = event.Branch, event.Path
+	case prMergedEvent:
+		name, branch, path = config.WebhookPRMerged, event.wt.Branch, event.wt.Path
+	default:
+		return
+	}
+
+	cfg, err := wm.loadConfig()
+	if err != nil || cfg == nil {
+		return
+	}
+	err = webhook.New(cfg.Webhook, cfg.NetworkTimeout()).Notify(webhook.Payload{
+		Event:  name,
+		Repo:   wm.repoName,
+		Branch: branch,
+		Path:   path,
+		Issue:  metadata.IssueFromBranch(branch),
+	})
+	if err != nil {
+		wm.notifyMu.Lock()
+		wm.notifyErrs = append(wm.notifyErrs, err)
+		wm.notifyMu.Unlock()
+	}
+}
+
+// mergeDetected remembers that wt's PR has merged and, the first time it's
+// seen, tells the webhook
+func (wm *WorktreeManager) mergeDetected(wt Worktree) {
+	if wm.githubClient.CachedMergedPRStatus(wt.Branch, wt.Commit) {
+		return
+	}
+	wm.githubClient.RememberMergedPRStatus(wt.Branch, wt.Commit)
+	wm.publish(prMergedEvent{wt: wt})
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sprout/pkg/config"
+	"sprout/pkg/progress"
+)
+
+func TestWebhookHearsWorktreesCreatedAndPruned(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct{ Event, Branch, Issue string }
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		events = append(events, payload.Event+" "+payload.Branch+" "+payload.Issue)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	wm := newConfiguredTestManager(t, &config.Config{Webhook: config.Webhook{URL: server.URL}})
+	if _, err := wm.CreateWorktree("spr-7-tidy"); err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	// Reusing the worktree isn't news
+	if _, err := wm.CreateWorktree("spr-7-tidy"); err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	if err := wm.PruneWorktree("spr-7-tidy", PruneOptions{Progress: progress.Discard}); err != nil {
+		t.Fatalf("PruneWorktree failed: %v", err)
+	}
+
+	if failed := WaitForNotifications(); len(failed) != 0 {
+		t.Fatalf("expected every post delivered, got %v", failed)
+	}
+	want := []string{"worktreeCreated spr-7-tidy SPR-7", "worktreeDeleted spr-7-tidy SPR-7"}
+	if !slices.Equal(events, want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+}
+
+func TestSlowWebhooksDontHoldUpWorktrees(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	wm := newConfiguredTestManager(t, &config.Config{Webhook: config.Webhook{URL: server.URL}})
+	done := make(chan error)
+	go func() {
+		_, err := wm.CreateWorktree("spr-8-slow")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("CreateWorktree failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the worktree created without waiting for the webhook")
+	}
+
+	close(release)
+	failed := WaitForNotifications()
+	if len(failed) != 1 || !strings.Contains(failed[0].Error(), "status 500") {
+		t.Fatalf("expected the rejected post reported once it finished, got %v", failed)
+	}
+}
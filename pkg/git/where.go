@@ -0,0 +1,66 @@
+package git
+
+import (
+	"fmt"
+
+	"sprout/pkg/config"
+)
+
+// Location is where a branch's worktree is, or would go, and where the
+// repository keeps its checkouts
+type Location struct {
+	Branch        string // the branch as git names it
+	Path          string // the worktree git has for the branch, or where sprout create would put one
+	Exists        bool   // git has a worktree for the branch at Path
+	RepoRoot      string // the main checkout, or the bare repository's directory
+	WorktreesRoot string // the directory new worktrees go in
+}
+
+// Where works out the Location for branchName without creating anything,
+// with the same rules sprout create follows. With no branch name it's the
+// branch checked out where sprout is run
+func (wm *WorktreeManager) Where(branchName string) (Location, error) {
+	if branchName == "" {
+		here, err := wm.branchHere()
+		if err != nil {
+			return Location{}, err
+		}
+		branchName = here
+	}
+	sanitized, err := ValidateBranchName(branchName)
+	if err != nil {
+		return Location{}, err
+	}
+	cfg, err := wm.loadConfig()
+	if err != nil {
+		return Location{}, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	location := Location{
+		Branch:        sanitized,
+		Path:          wm.resolveWorktreePath(cfg, sanitized),
+		RepoRoot:      wm.repoRoot,
+		WorktreesRoot: wm.worktreesRoot(cfg),
+	}
+	worktrees, err := wm.gitWorktrees()
+	if err != nil {
+		return Location{}, err
+	}
+	for _, wt := range worktrees {
+		// A worktree moved by hand or made before worktreeBasePath changed
+		// is wherever git has it
+		if wt.Branch == sanitized && !wt.Prunable {
+			location.Path, location.Exists = wt.Path, true
+			break
+		}
+	}
+	return location, nil
+}
+
+// worktreesRoot is the directory new worktrees go in. When
+// worktreeBasePath names each worktree's directory with $BRANCH_NAME, it's
+// what's left with the branch taken out
+func (wm *WorktreeManager) worktreesRoot(cfg *config.Config) string {
+	basePath, _ := wm.getWorktreeBasePath(cfg, "")
+	return basePath
+}
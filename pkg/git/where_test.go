@@ -0,0 +1,58 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sprout/pkg/config"
+)
+
+func TestWhereFindsWorktreesAndCreatesNothing(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	basePath := t.TempDir()
+	cfg := &config.Config{WorktreeBasePath: filepath.Join(basePath, "$REPO_NAME", "$BRANCH_NAME")}
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     "app",
+		configLoader: &config.DefaultLoader{Config: cfg},
+	}
+
+	location, err := wm.Where("fix/login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Location{
+		Branch:        "fix/login",
+		Path:          filepath.Join(basePath, "app", "fix/login"),
+		RepoRoot:      repoRoot,
+		WorktreesRoot: filepath.Join(basePath, "app"),
+	}
+	if location != want {
+		t.Fatalf("expected %+v, got %+v", want, location)
+	}
+	if _, err := os.Stat(location.Path); !os.IsNotExist(err) || wm.branchExists("refs/heads/fix/login") {
+		t.Fatalf("expected nothing created for fix/login, stat returned %v", err)
+	}
+
+	// A worktree is wherever git has it, even somewhere sprout wouldn't put it
+	elsewhere := filepath.Join(t.TempDir(), "by-hand")
+	runGitCommand(t, repoRoot, "worktree", "add", "-b", "fix/login", elsewhere)
+	if location, err := wm.Where("fix/login"); err != nil || !location.Exists || !sameFile(location.Path, elsewhere) {
+		t.Fatalf("expected the worktree at %s, got %+v (%v)", elsewhere, location, err)
+	}
+
+	// With no branch, it's the one checked out here
+	t.Chdir(elsewhere)
+	if location, err := wm.Where(""); err != nil || location.Branch != "fix/login" || !location.Exists {
+		t.Fatalf("expected the branch checked out here, got %+v (%v)", location, err)
+	}
+}
+
+// sameFile is whether a and b are the same directory, whatever symlinks
+// such as macOS's /var lead to it
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,18 +11,116 @@ import (
 	"time"
 
 	"sprout/pkg/config"
+	"sprout/pkg/envtemplate"
+	"sprout/pkg/events"
 	"sprout/pkg/github"
+	"sprout/pkg/metadata"
+	"sprout/pkg/progress"
+	"sprout/pkg/stats"
 )
 
+// ErrPathNotWorktree is wrapped by the error for a new worktree whose
+// directory is already there but isn't a worktree
+var ErrPathNotWorktree = errors.New("directory exists but is not a valid worktree")
+
 // WorktreeManagerInterface defines the interface for worktree operations
 type WorktreeManagerInterface interface {
 	CreateWorktree(branchName string) (string, error)
+	CreateWorktreeWithOptions(branchName string, opts CreateOptions) (string, error)
+	PlanWorktree(branchName string, opts CreateOptions) (CreatePlan, error)
 	CreateBranch(branchName string) error
 	ListWorktrees() ([]Worktree, error)
 	ListWorktreesForTUI() ([]Worktree, error)
-	ListWorktreesForTUIWithProgress(func(string)) ([]Worktree, error)
-	PruneWorktree(branchName string) error
-	PruneAllMerged() error
+	ListWorktreesForTUIWithProgress(progress.Presenter) ([]Worktree, error)
+	LookUpCIStatuses(worktrees []Worktree)
+	PruneWorktree(branchName string, opts PruneOptions) error
+	PruneAllMerged(opts PruneOptions) (PruneResult, error)
+	MergedWorktrees() ([]Worktree, error)
+	PruneMergedWorktrees(worktrees []Worktree, opts PruneOptions) (PruneResult, error)
+	HasUncommittedChanges(worktreePath string) bool
+	PruneLargerThan(threshold int64, opts PruneOptions) error
+	ApplySparseCheckout(branchName string, directories []string) error
+	Repair() (*RepairReport, error)
+	FindExisting(branchName string) (ExistingBranch, error)
+	ArchiveWorktree(branchName string) (*Archive, error)
+	RestoreWorktree(branchName string) (string, error)
+	ExportWorktree(branchName, path string) (*Archive, error)
+	ImportWorktree(path string) (*Archive, string, error)
+	RenameWorktree(oldBranch, newBranch string) (Worktree, error)
+	UndoPrune() ([]TrashedWorktree, error)
+	GCCandidates(policy GCPolicy) ([]GCCandidate, error)
+	PurgeExpiredTrash(dryRun bool) ([]TrashedWorktree, error)
+	CheckGitHooks() ([]string, error)
+	StartTimer(branchName, worktreePath string) error
+	PinWorktree(branchName string) error
+	UnpinWorktree(branchName string) error
+	SetNote(branchName, note string) error
+	Stack(branchName string) (Stack, error)
+	Restack(branchName string, presenter progress.Presenter) (Stack, error)
+	CheckoutPR(ref string, opts CreateOptions) (PRCheckout, error)
+	Where(branchName string) (Location, error)
+	SetProgress(presenter progress.Presenter)
+}
+
+// CreateOptions customises how a new worktree is checked out
+type CreateOptions struct {
+	SparseDirectories []string           // overrides the configured sparse-checkout directories when set
+	BaseBranch        string             // branch to start from instead of the default branch
+	Hooks             []string           // shell commands run in the worktree once it's first created
+	Submodules        Setup              // whether to run git submodule update --init --recursive in a new worktree
+	LFS               Setup              // whether to run git lfs pull in a new worktree
+	GitHooks          string             // config.GitHooksLink or GitHooksCopy to give a new worktree the main checkout's hooks
+	NoFetch           bool               // start from the refs already fetched rather than fetching the default branch from origin
+	Progress          progress.Presenter // where those steps and any warnings are presented; nowhere when nil
+}
+
+// PruneOptions controls what a prune removes besides the worktree directory
+type PruneOptions struct {
+	KeepBranch   bool               // leave the local branch in place
+	DeleteRemote bool               // also delete the branch on origin
+	DryRun       bool               // report what would be removed without touching anything
+	Unlock       bool               // lift git worktree lock from a named worktree so it can be removed
+	Porcelain    bool               // print only a tab-separated result line per worktree, for scripts
+	KeepUnmerged bool               // leave the local branch of any worktree whose PR isn't merged, so its commits outlast the trash
+	Progress     progress.Presenter // where progress is presented instead of stderr, for the CLI and callers embedding sprout
+	Results      progress.Presenter // where porcelain lines go instead of stdout
+
+	// OnProgress hears how each worktree PruneMergedWorktrees was given went
+	// as soon as it's done, one at a time, so callers can show progress
+	OnProgress func(PruneOutcome)
+
+	// Permanent deletes the worktree outright instead of moving it to the
+	// trash, freeing its space straight away at the cost of sprout undo
+	Permanent bool
+
+	operation string // shared by worktrees pruned together, so sprout undo restores them together
+}
+
+// presenter is where prune presents what it's doing and what went wrong:
+// stderr, so stdout only carries results, unless the caller says otherwise
+func (opts PruneOptions) presenter() progress.Presenter {
+	if opts.Progress != nil {
+		return opts.Progress
+	}
+	return progress.NewText(os.Stderr)
+}
+
+// porcelain is where the tab-separated result lines go: stdout, which
+// scripts read, unless the caller says otherwise
+func (opts PruneOptions) porcelain() progress.Presenter {
+	if opts.Results != nil {
+		return opts.Results
+	}
+	return progress.NewText(os.Stdout)
+}
+
+// progress is presenter, or nowhere when porcelain lines stand in for it.
+// Warnings still go to presenter
+func (opts PruneOptions) progress() progress.Presenter {
+	if opts.Porcelain {
+		return progress.Discard
+	}
+	return opts.presenter()
 }
 
 type WorktreeManager struct {
@@ -29,6 +128,16 @@ type WorktreeManager struct {
 	repoName     string
 	configLoader config.LoaderInterface
 	githubClient *github.Client
+	metadata     *metadata.Store
+	gitTimeout   time.Duration
+	pruneMu      sync.Mutex // held around the git bookkeeping of worktrees pruned side by side
+	readerOnce   sync.Once
+	reader       *repoReader // reads without running git; nil when go-git can't read the repository
+	eventsOnce   sync.Once
+	events       events.Bus // what the manager did, for the history log and the webhook
+	notifyMu     sync.Mutex
+	notifyErrs   []error            // webhook posts that failed, for WaitForNotifications
+	progress     progress.Presenter // where calls without options of their own present warnings; stderr when nil
 }
 
 func NewWorktreeManager() (*WorktreeManager, error) {
@@ -36,52 +145,182 @@ func NewWorktreeManager() (*WorktreeManager, error) {
 	if err != nil {
 		return nil, fmt.Errorf("not in a git repository: %w", err)
 	}
+	return NewWorktreeManagerForRepo(repoRoot)
+}
 
-	repoName, err := GetRepositoryName()
+// NewWorktreeManagerForRepo manages the repository at repoRoot rather than the
+// one containing the current directory
+func NewWorktreeManagerForRepo(repoRoot string) (*WorktreeManager, error) {
+	if !isValidWorktree(repoRoot) {
+		return nil, fmt.Errorf("%s is not a git repository", repoRoot)
+	}
+	// Managing the wrong repository would prune the wrong directories, so a
+	// checkout that can't be traced to its repository is an error
+	repoRoot, err := FindRepoRoot(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	repoName, err := repositoryNameFor(repoRoot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine repository name: %w", err)
 	}
 
-	return &WorktreeManager{
+	wm := &WorktreeManager{
 		repoRoot:     repoRoot,
 		repoName:     repoName,
 		configLoader: &config.FileLoader{},
 		githubClient: github.NewClient(repoRoot),
-	}, nil
+		metadata:     metadata.NewStore(repoRoot),
+	}
+	// A config that fails to load is reported by the commands that need it;
+	// until then the timeouts keep their defaults
+	if cfg, err := wm.loadConfig(); err == nil {
+		wm.gitTimeout = cfg.GitTimeout()
+		wm.githubClient.SetTimeouts(cfg.NetworkTimeout(), cfg.GitTimeout())
+		wm.githubClient.SetProvider(cfg.GetGitHubProvider(), &github.KeychainTokenStore{})
+	}
+	return wm, nil
+}
+
+// refs is the reader for the repository's worktrees, refs and history,
+// opened on first use. It's nil when go-git can't read the repository, in
+// which case git is asked instead
+func (wm *WorktreeManager) refs() *repoReader {
+	wm.readerOnce.Do(func() {
+		wm.reader, _ = openRepoReader(wm.repoRoot)
+	})
+	return wm.reader
+}
+
+// RepoRoot returns the top-level directory of the repository being managed
+func (wm *WorktreeManager) RepoRoot() string {
+	return wm.repoRoot
 }
 
 func (wm *WorktreeManager) CreateWorktree(branchName string) (string, error) {
-	sanitizedBranchName := sanitizeBranchName(branchName)
-	if sanitizedBranchName == "" {
-		return "", fmt.Errorf("branch name results in empty string after sanitization")
+	return wm.CreateWorktreeWithOptions(branchName, CreateOptions{})
+}
+
+func (wm *WorktreeManager) CreateWorktreeWithOptions(branchName string, opts CreateOptions) (string, error) {
+	plan, err := wm.PlanWorktree(branchName, opts)
+	if err != nil {
+		return "", err
 	}
+	cfg, _ := wm.loadConfig()
 
-	cfg, cfgErr := wm.loadConfig()
-	worktreePath := wm.resolveWorktreePath(cfg, sanitizedBranchName)
+	worktreePath, err := wm.createWorktree(plan, opts.Progress)
+	if err != nil {
+		return "", err
+	}
+	if !plan.BranchExists && opts.BaseBranch != "" {
+		// A branch started from another is stacked on it, for sprout stack
+		wm.metadata.RecordParent(plan.Branch, opts.BaseBranch)
+	}
+	// Setup steps and hooks only run for a new worktree, not one being reused
+	if err := wm.finishWorktree(cfg, plan.Branch, worktreePath, plan.Reuse, opts); err != nil {
+		return "", err
+	}
+	return worktreePath, nil
+}
 
-	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
-		return "", fmt.Errorf("failed to create worktree base directory: %w", err)
+// finishWorktree records a worktree that has just been created or reused and
+// gets it ready to work in, running the setup steps and hooks if it's new
+func (wm *WorktreeManager) finishWorktree(cfg *config.Config, branchName, worktreePath string, existed bool, opts CreateOptions) error {
+	wm.metadata.RecordCreated(branchName, worktreePath)
+	if !existed {
+		wm.publish(metadata.HistoryEvent{Kind: metadata.HistoryWorktreeCreated, Branch: branchName, Path: worktreePath})
 	}
 
-	if _, err := os.Stat(worktreePath); err == nil {
-		if isValidWorktree(worktreePath) {
-			return worktreePath, nil
+	vars, err := wm.envVars(branchName, worktreePath)
+	if err != nil {
+		return fmt.Errorf("worktree created at %s, but %w", worktreePath, err)
+	}
+	if err := wm.renderEnvTemplate(vars); err != nil {
+		return fmt.Errorf("worktree created at %s, but %w", worktreePath, err)
+	}
+	if !existed {
+		if err := wm.setUpWorktree(worktreePath, opts); err != nil {
+			return fmt.Errorf("worktree created at %s, but %w", worktreePath, err)
 		}
-		return "", fmt.Errorf("directory exists but is not a valid worktree: %s", worktreePath)
+		if err := runHooks(worktreePath, opts.Hooks, envtemplate.Environ(vars), opts.Progress); err != nil {
+			return fmt.Errorf("worktree created at %s, but %w", worktreePath, err)
+		}
+	}
+	// A time tracker that won't start is no reason to fail the worktree
+	if err := wm.startTimer(cfg, branchName, worktreePath); err != nil {
+		progress.Or(opts.Progress).Warning(err.Error())
 	}
+	return nil
+}
 
-	if cfgErr != nil {
-		// Log warning but continue with normal worktree creation
-		fmt.Printf("Warning: failed to load config, using normal checkout: %v\n", cfgErr)
-		return wm.createNormalWorktree(worktreePath, sanitizedBranchName)
+// runHooks runs each hook with sh in the worktree as a step of its own,
+// with env added to its environment, stopping at the first to fail
+func runHooks(worktreePath string, hooks []string, env []string, presenter progress.Presenter) error {
+	for _, hook := range hooks {
+		err := progress.Step(presenter, "Running hook: "+hook, func() error {
+			cmd := exec.Command("sh", "-c", hook)
+			cmd.Dir = worktreePath
+			cmd.Env = append(os.Environ(), env...)
+			if output, err := cmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("hook %q failed: %w\nOutput: %s", hook, err, string(output))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	directories, hasSparseCheckout := cfg.GetSparseCheckoutDirectories(wm.repoRoot)
-	if hasSparseCheckout {
-		return wm.createSparseWorktree(worktreePath, sanitizedBranchName, directories)
+// envVars describes branch's worktree at worktreePath for its env template
+// and hooks, allocating it a block of ports and a compose project the first
+// time it's asked for
+func (wm *WorktreeManager) envVars(branchName, worktreePath string) (envtemplate.Vars, error) {
+	composeProject := envtemplate.ComposeProject(wm.repoName, branchName)
+	allocation, err := wm.metadata.Allocate(branchName, worktreePath, composeProject, func(used map[int]bool) (int, error) {
+		return envtemplate.FreePort(worktreePath, used)
+	})
+	if err != nil {
+		return envtemplate.Vars{}, err
+	}
+	return envtemplate.Vars{
+		Branch:         branchName,
+		Issue:          metadata.IssueFromBranch(branchName),
+		WorktreePath:   worktreePath,
+		RepoName:       wm.repoName,
+		RepoRoot:       wm.repoRoot,
+		Port:           allocation.Port,
+		ComposeProject: allocation.ComposeProject,
+	}, nil
+}
+
+// renderEnvTemplate writes the configured env template to .env.local in the worktree
+func (wm *WorktreeManager) renderEnvTemplate(vars envtemplate.Vars) error {
+	cfg, err := wm.loadConfig()
+	if err != nil {
+		return nil
 	}
+	templatePath, ok := cfg.GetEnvTemplatePath(wm.repoRoot)
+	if !ok {
+		return nil
+	}
+	return envtemplate.WriteIfMissing(templatePath, vars)
+}
 
-	return wm.createNormalWorktree(worktreePath, sanitizedBranchName)
+// createWorktree checks out the worktree plan describes, or leaves the one
+// that's there when it's to be reused
+func (wm *WorktreeManager) createWorktree(plan CreatePlan, presenter progress.Presenter) (string, error) {
+	if plan.Reuse {
+		return plan.Path, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(plan.Path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create worktree base directory: %w", err)
+	}
+	if len(plan.SparseDirectories) > 0 {
+		return wm.createSparseWorktree(plan.Path, plan.Branch, plan.Base, plan.SparseDirectories, progress.Or(presenter))
+	}
+	return wm.createNormalWorktree(plan.Path, plan.Branch, plan.Base)
 }
 
 func (wm *WorktreeManager) loadConfig() (*config.Config, error) {
@@ -99,6 +338,10 @@ func (wm *WorktreeManager) getWorktreeBasePath(cfg *config.Config, branchName st
 		}
 	}
 
+	// Worktrees in the bare layout sit beside each other rather than in .worktrees
+	if usesBareLayout(wm.repoRoot) {
+		return filepath.Dir(wm.repoRoot), false
+	}
 	return filepath.Join(filepath.Dir(wm.repoRoot), ".worktrees"), false
 }
 
@@ -110,15 +353,13 @@ func (wm *WorktreeManager) resolveWorktreePath(cfg *config.Config, branchName st
 	return filepath.Join(basePath, branchName)
 }
 
-func (wm *WorktreeManager) createNormalWorktree(worktreePath, branchName string) (string, error) {
-	// Determine the base branch (master or main)
-	baseBranch, err := wm.getBaseBranch()
+func (wm *WorktreeManager) createNormalWorktree(worktreePath, branchName, base string) (string, error) {
+	baseBranch, err := wm.resolveBaseBranch(base, true)
 	if err != nil {
-		return "", fmt.Errorf("failed to determine base branch: %w", err)
+		return "", err
 	}
 
-	cmd := exec.Command("git", "worktree", "add", worktreePath, "-b", branchName, baseBranch)
-	cmd.Dir = wm.repoRoot
+	cmd := wm.gitCommand(wm.repoRoot, "worktree", "add", worktreePath, "-b", branchName, baseBranch)
 
 	if output, err := cmd.CombinedOutput(); err != nil {
 		if strings.Contains(string(output), "already exists") {
@@ -130,16 +371,14 @@ func (wm *WorktreeManager) createNormalWorktree(worktreePath, branchName string)
 	return worktreePath, nil
 }
 
-func (wm *WorktreeManager) createSparseWorktree(worktreePath, branchName string, directories []string) (string, error) {
-	// Determine the base branch (master or main)
-	baseBranch, err := wm.getBaseBranch()
+func (wm *WorktreeManager) createSparseWorktree(worktreePath, branchName, base string, directories []string, presenter progress.Presenter) (string, error) {
+	baseBranch, err := wm.resolveBaseBranch(base, true)
 	if err != nil {
-		return "", fmt.Errorf("failed to determine base branch: %w", err)
+		return "", err
 	}
 
 	// Create worktree without checkout
-	cmd := exec.Command("git", "worktree", "add", "--no-checkout", worktreePath, "-b", branchName, baseBranch)
-	cmd.Dir = wm.repoRoot
+	cmd := wm.gitCommand(wm.repoRoot, "worktree", "add", "--no-checkout", worktreePath, "-b", branchName, baseBranch)
 
 	if output, err := cmd.CombinedOutput(); err != nil {
 		if strings.Contains(string(output), "already exists") {
@@ -149,43 +388,73 @@ func (wm *WorktreeManager) createSparseWorktree(worktreePath, branchName string,
 	}
 
 	// Initialize sparse checkout with cone mode
-	cmd = exec.Command("git", "sparse-checkout", "init", "--cone")
-	cmd.Dir = worktreePath
+	cmd = wm.gitCommand(worktreePath, "sparse-checkout", "init", "--cone")
 
 	if output, err := cmd.CombinedOutput(); err != nil {
-		fmt.Printf("Warning: failed to initialize sparse checkout, falling back to normal checkout: %v\nOutput: %s\n", err, string(output))
+		presenter.Warning(fmt.Sprintf("failed to initialize sparse checkout, falling back to normal checkout: %v\nOutput: %s", err, string(output)))
 		// Fallback: checkout everything
 		return wm.checkoutAll(worktreePath)
 	}
 
 	// Set sparse checkout directories
 	args := append([]string{"sparse-checkout", "set"}, directories...)
-	cmd = exec.Command("git", args...)
-	cmd.Dir = worktreePath
+	cmd = wm.gitCommand(worktreePath, args...)
 
 	if output, err := cmd.CombinedOutput(); err != nil {
-		fmt.Printf("Warning: failed to set sparse checkout patterns, falling back to normal checkout: %v\nOutput: %s\n", err, string(output))
+		presenter.Warning(fmt.Sprintf("failed to set sparse checkout patterns, falling back to normal checkout: %v\nOutput: %s", err, string(output)))
 		// Fallback: checkout everything
 		return wm.checkoutAll(worktreePath)
 	}
 
 	// Checkout with sparse patterns applied
-	cmd = exec.Command("git", "checkout")
-	cmd.Dir = worktreePath
+	cmd = wm.gitCommand(worktreePath, "checkout")
 
 	if output, err := cmd.CombinedOutput(); err != nil {
-		fmt.Printf("Warning: failed to checkout with sparse patterns, falling back to normal checkout: %v\nOutput: %s\n", err, string(output))
+		presenter.Warning(fmt.Sprintf("failed to checkout with sparse patterns, falling back to normal checkout: %v\nOutput: %s", err, string(output)))
 		// Fallback: checkout everything
 		return wm.checkoutAll(worktreePath)
 	}
 
-	fmt.Printf("Created sparse worktree with directories: %s\n", strings.Join(directories, ", "))
+	presenter.Result("Created sparse worktree with directories: " + strings.Join(directories, ", "))
 	return worktreePath, nil
 }
 
+// ApplySparseCheckout narrows an existing worktree to directories, or restores
+// a full checkout when directories is empty
+func (wm *WorktreeManager) ApplySparseCheckout(branchName string, directories []string) error {
+	if branchName == "" {
+		return fmt.Errorf("branch name cannot be empty")
+	}
+
+	cfg, err := wm.loadConfig()
+	if err != nil {
+		wm.presenter().Warning(fmt.Sprintf("failed to load config, using default worktree path: %v", err))
+	}
+
+	worktreePath := wm.resolveWorktreePath(cfg, branchName)
+	if !isValidWorktree(worktreePath) {
+		return fmt.Errorf("worktree does not exist: %s", branchName)
+	}
+
+	if len(directories) == 0 {
+		cmd := wm.gitCommand(worktreePath, "sparse-checkout", "disable")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to disable sparse checkout: %w\nOutput: %s", err, string(output))
+		}
+		return nil
+	}
+
+	args := append([]string{"sparse-checkout", "set", "--cone"}, directories...)
+	cmd := wm.gitCommand(worktreePath, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set sparse checkout patterns: %w\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
 func (wm *WorktreeManager) checkoutAll(worktreePath string) (string, error) {
-	cmd := exec.Command("git", "checkout")
-	cmd.Dir = worktreePath
+	cmd := wm.gitCommand(worktreePath, "checkout")
 
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return "", fmt.Errorf("failed to checkout: %w\nOutput: %s", err, string(output))
@@ -208,18 +477,109 @@ func isValidWorktree(path string) bool {
 }
 
 func getRepositoryRoot() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err := cmd.Output()
+	return FindRepoRoot("")
+}
+
+// FindRepoRoot returns the primary checkout of the repository containing dir,
+// or the current directory when dir is empty. Run from inside one of sprout's
+// worktrees, that's the checkout the worktree was created from, so new
+// worktrees and listed paths are relative to it rather than nested inside
+// the worktree
+func FindRepoRoot(dir string) (string, error) {
+	toplevel, err := gitOutputIn(dir, "rev-parse", "--show-toplevel")
 	if err != nil {
-		return "", err
+		if dir == "" {
+			dir = "the current directory"
+		}
+		return "", fmt.Errorf("%s isn't inside a git checkout: %w", dir, gitError(err))
+	}
+
+	commonDir, err := gitOutputIn(toplevel, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("can't find the repository %s belongs to: %w", toplevel, gitError(err))
+	}
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(toplevel, commonDir)
 	}
+	return primaryCheckout(toplevel, commonDir), nil
+}
+
+// primaryCheckout finds the main checkout for the git directory shared by
+// all of a repository's worktrees, falling back to toplevel when there isn't
+// one to be found
+func primaryCheckout(toplevel, commonDir string) string {
+	if filepath.Base(commonDir) == ".git" {
+		if info, err := os.Stat(commonDir); err == nil && info.IsDir() {
+			return filepath.Dir(commonDir)
+		}
+	}
+
+	// A bare repository has no checkout of its own, so the worktree on its
+	// default branch stands in for one
+	branch, err := gitOutputIn(commonDir, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return toplevel
+	}
+	output, err := gitOutputIn(commonDir, "worktree", "list", "--porcelain")
+	if err != nil {
+		return toplevel
+	}
+	for _, wt := range parseWorktreeList(output) {
+		if wt.Branch == branch && !wt.Bare && !wt.Prunable {
+			return wt.Path
+		}
+	}
+	return toplevel
+}
 
-	return strings.TrimSpace(string(output)), nil
+// CheckoutForGitDir is the checkout a git directory belongs to, for when git
+// runs sprout as git sprout with GIT_DIR set rather than from inside the
+// checkout. A bare repository's is the worktree on its default branch
+func CheckoutForGitDir(gitDir string) (string, error) {
+	// A linked worktree's git directory records where its .git file is
+	if data, err := os.ReadFile(filepath.Join(gitDir, "gitdir")); err == nil {
+		return filepath.Dir(strings.TrimSpace(string(data))), nil
+	}
+	if filepath.Base(gitDir) == ".git" {
+		return filepath.Dir(gitDir), nil
+	}
+	if checkout := primaryCheckout(gitDir, gitDir); checkout != gitDir {
+		return checkout, nil
+	}
+	return "", fmt.Errorf("%s has no checkout to work in", gitDir)
 }
 
 func GetRepositoryName() (string, error) {
+	repoRoot, err := getRepositoryRoot()
+	if err != nil {
+		return "", err
+	}
+	return repositoryNameFor(repoRoot)
+}
+
+// RepoName returns the name of the repository being managed
+func (wm *WorktreeManager) RepoName() string {
+	return wm.repoName
+}
+
+// SetProgress sets where calls that take no options of their own, such as
+// RenameWorktree and UndoPrune, present their warnings: stderr until it's set
+func (wm *WorktreeManager) SetProgress(presenter progress.Presenter) {
+	wm.progress = presenter
+}
+
+// presenter is where calls without options of their own present warnings
+func (wm *WorktreeManager) presenter() progress.Presenter {
+	if wm.progress != nil {
+		return wm.progress
+	}
+	return progress.NewText(os.Stderr)
+}
+
+func repositoryNameFor(repoRoot string) (string, error) {
 	// Try to get repo name from remote URL first (works in worktrees)
 	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd.Dir = repoRoot
 	output, err := cmd.Output()
 	if err == nil {
 		remoteURL := strings.TrimSpace(string(output))
@@ -230,11 +590,9 @@ func GetRepositoryName() (string, error) {
 	}
 
 	// Fallback to directory name method
-	repoRoot, err := getRepositoryRoot()
-	if err != nil {
+	if _, err := os.Stat(repoRoot); err != nil {
 		return "", err
 	}
-
 	return filepath.Base(repoRoot), nil
 }
 
@@ -267,50 +625,127 @@ func extractRepoNameFromURL(url string) string {
 }
 
 type Worktree struct {
-	Path      string
-	Branch    string
-	Commit    string
-	PRStatus  string
-	UpdatedAt time.Time
-	Merged    bool
-	Prunable  bool
+	Path       string
+	Branch     string
+	Commit     string
+	PRStatus   string
+	CIStatus   string // what the checks on Commit add up to, looked up for open PRs; empty when none ran
+	UpdatedAt  time.Time
+	Merged     bool
+	Prunable   bool
+	Bare       bool   // the repository directory of a bare clone, not a checkout
+	Detached   bool   // HEAD is on a commit rather than a branch
+	Locked     bool   // git worktree lock protects it from removal
+	LockReason string // why it was locked, when a reason was given
+	Pinned     bool   // sprout pin protects it from prune, along with a lock
+	Note       string // what sprout note says about it, such as what it's waiting on
+	DiskUsage  int64  // last measured size in bytes, 0 when unknown
 }
 
-func (wm *WorktreeManager) ListWorktrees() ([]Worktree, error) {
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
-	cmd.Dir = wm.repoRoot
+// Name is what the worktree is called in list and the TUI: its branch, or the
+// commit a detached HEAD is on
+func (wt Worktree) Name() string {
+	switch {
+	case wt.Branch != "":
+		return wt.Branch
+	case wt.Detached:
+		commit := wt.Commit
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		return "(detached at " + commit + ")"
+	}
+	return filepath.Base(wt.Path)
+}
 
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+// States lists what git reports about the worktree beyond its branch, for
+// showing alongside its name
+func (wt Worktree) States() []string {
+	var states []string
+	switch {
+	case wt.Pinned:
+		states = append(states, "pinned")
+	case wt.Locked:
+		states = append(states, "locked")
+	}
+	if wt.Prunable {
+		states = append(states, "prunable")
 	}
+	return states
+}
 
-	worktrees := parseWorktreeList(string(output))
+// keptBecause says why prune, gc and archive leave wt alone: it's the
+// default branch, a detached or bare checkout, or locked or pinned. It's
+// empty when nothing keeps it
+func (wt Worktree) keptBecause() string {
+	switch {
+	case wt.Bare:
+		return "the bare repository"
+	case wt.Branch == "":
+		return "detached"
+	case wt.Branch == "master" || wt.Branch == "main":
+		return "the default branch"
+	case wt.Pinned:
+		return "pinned"
+	case wt.Locked:
+		return "locked"
+	}
+	return ""
+}
 
+func (wm *WorktreeManager) ListWorktrees() ([]Worktree, error) {
+	worktrees, err := wm.gitWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	// One for-each-ref covers every branch's last commit, so sprout list can
+	// sort by age without a git call per worktree
+	commitTimes := wm.branchCommitTimesFor(tuiWorktreeBranches(worktrees), nil)
 	for i := range worktrees {
 		worktrees[i].PRStatus = wm.githubClient.GetPRStatus(worktrees[i].Branch)
+		worktrees[i].Merged = worktrees[i].PRStatus == "Merged"
+		if worktrees[i].Merged {
+			wm.mergeDetected(worktrees[i])
+		}
+		if updatedAt, ok := commitTimes[worktrees[i].Branch]; ok {
+			worktrees[i].UpdatedAt = updatedAt
+		}
 	}
 
 	return worktrees, nil
 }
 
+// gitWorktrees lists the worktrees git knows about, without looking up PR statuses
+func (wm *WorktreeManager) gitWorktrees() ([]Worktree, error) {
+	worktrees, err := wm.listWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	wm.markPinned(worktrees)
+	wm.markNotes(worktrees)
+	return worktrees, nil
+}
+
 func (wm *WorktreeManager) ListWorktreesForTUI() ([]Worktree, error) {
 	return wm.ListWorktreesForTUIWithProgress(nil)
 }
 
-func (wm *WorktreeManager) ListWorktreesForTUIWithProgress(progress func(string)) ([]Worktree, error) {
-	reportProgress(progress, "git worktree list --porcelain")
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
-	cmd.Dir = wm.repoRoot
-
-	output, err := cmd.Output()
+// ListWorktreesForTUIWithProgress lists worktrees with what the TUI shows
+// about them, presenting each command it runs to find out as a step
+func (wm *WorktreeManager) ListWorktreesForTUIWithProgress(presenter progress.Presenter) ([]Worktree, error) {
+	var worktrees []Worktree
+	err := progress.Step(presenter, "git worktree list --porcelain", func() (err error) {
+		worktrees, err = wm.listWorktrees()
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+		return nil, err
 	}
-
-	worktrees := parseWorktreeList(string(output))
+	wm.markPinned(worktrees)
+	wm.markNotes(worktrees)
 	branches := tuiWorktreeBranches(worktrees)
-	commitTimes := wm.branchCommitTimesFor(branches, progress)
+	commitTimes := wm.branchCommitTimesFor(branches, presenter)
 
 	for i := range worktrees {
 		if updatedAt, ok := commitTimes[worktrees[i].Branch]; ok {
@@ -321,15 +756,35 @@ func (wm *WorktreeManager) ListWorktreesForTUIWithProgress(progress func(string)
 				worktrees[i].UpdatedAt = info.ModTime()
 			}
 		}
+		// Only show sizes that are already cached; walking large trees here would stall startup
+		if usage, ok := wm.metadata.DiskUsage(worktrees[i].Path); ok {
+			worktrees[i].DiskUsage = usage.Bytes
+		}
 	}
 
-	if err := wm.applyTUIWorktreePRStatuses(worktrees, progress); err != nil {
+	if err := wm.applyTUIWorktreePRStatuses(worktrees, presenter); err != nil {
 		return nil, err
 	}
+	wm.applyCIStatuses(worktrees, presenter)
 
 	return worktrees, nil
 }
 
+// listWorktrees is what git worktree list --porcelain says, read without
+// running it when the repository can be
+func (wm *WorktreeManager) listWorktrees() ([]Worktree, error) {
+	if reader := wm.refs(); reader != nil {
+		if worktrees, err := reader.worktrees(); err == nil {
+			return worktrees, nil
+		}
+	}
+	output, err := wm.gitCommand(wm.repoRoot, "worktree", "list", "--porcelain").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	return parseWorktreeList(string(output)), nil
+}
+
 const maxConcurrentTUIStatusChecks = 6
 
 type prStatusJob struct {
@@ -342,7 +797,7 @@ type prStatusResult struct {
 	err    error
 }
 
-func (wm *WorktreeManager) applyTUIWorktreePRStatuses(worktrees []Worktree, progress func(string)) error {
+func (wm *WorktreeManager) applyTUIWorktreePRStatuses(worktrees []Worktree, presenter progress.Presenter) error {
 	if wm.githubClient == nil {
 		return nil
 	}
@@ -378,9 +833,11 @@ func (wm *WorktreeManager) applyTUIWorktreePRStatuses(worktrees []Worktree, prog
 			defer wg.Done()
 			for job := range jobCh {
 				wt := worktrees[job.index]
-				command := github.PRStatusCommand(wt.Branch)
-				reportProgress(progress, command)
-				status, err := wm.githubClient.GetPRStatusFromGH(wt.Branch)
+				var status string
+				err := progress.Step(presenter, wm.githubClient.StatusCommand(wt.Branch), func() (err error) {
+					status, err = wm.githubClient.GetPRStatusFromGitHub(wt.Branch)
+					return err
+				})
 				resultCh <- prStatusResult{index: job.index, status: status, err: err}
 			}
 		}()
@@ -397,6 +854,11 @@ func (wm *WorktreeManager) applyTUIWorktreePRStatuses(worktrees []Worktree, prog
 
 	var firstErr error
 	for result := range resultCh {
+		if errors.Is(result.err, github.ErrTimeout) {
+			// GitHub being slow shouldn't hide the worktrees; this one just
+			// stays active until a later refresh learns its PR status
+			continue
+		}
 		if result.err != nil && firstErr == nil {
 			firstErr = result.err
 			continue
@@ -407,19 +869,13 @@ func (wm *WorktreeManager) applyTUIWorktreePRStatuses(worktrees []Worktree, prog
 		worktrees[result.index].PRStatus = result.status
 		if result.status == "Merged" {
 			worktrees[result.index].Merged = true
-			wm.githubClient.RememberMergedPRStatus(worktrees[result.index].Branch, worktrees[result.index].Commit)
+			wm.mergeDetected(worktrees[result.index])
 		}
 	}
 
 	return firstErr
 }
 
-func reportProgress(progress func(string), status string) {
-	if progress != nil {
-		progress(status)
-	}
-}
-
 func tuiWorktreeBranches(worktrees []Worktree) []string {
 	branches := make([]string, 0)
 	seen := make(map[string]bool)
@@ -459,12 +915,9 @@ func parseWorktreeList(output string) []Worktree {
 			continue
 		}
 
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) < 2 {
-			continue
-		}
-
-		key, value := parts[0], parts[1]
+		// Attributes like bare and detached stand alone; locked and
+		// prunable may be followed by a reason
+		key, value, _ := strings.Cut(line, " ")
 		switch key {
 		case "worktree":
 			current.Path = value
@@ -474,6 +927,13 @@ func parseWorktreeList(output string) []Worktree {
 			}
 		case "HEAD":
 			current.Commit = value
+		case "bare":
+			current.Bare = true
+		case "detached":
+			current.Detached = true
+		case "locked":
+			current.Locked = true
+			current.LockReason = value
 		case "prunable":
 			current.Prunable = true
 		}
@@ -490,17 +950,23 @@ func (wm *WorktreeManager) branchCommitTimes() map[string]time.Time {
 	return wm.branchCommitTimesFor(nil, nil)
 }
 
-func (wm *WorktreeManager) branchCommitTimesFor(branches []string, progress func(string)) map[string]time.Time {
+func (wm *WorktreeManager) branchCommitTimesFor(branches []string, presenter progress.Presenter) map[string]time.Time {
 	result := make(map[string]time.Time)
 	args := branchCommitTimesCommandArgs(branches)
 	if len(args) == 0 {
 		return result
 	}
-	reportProgress(progress, "git "+strings.Join(args, " "))
+	if reader := wm.refs(); reader != nil {
+		if times, err := reader.commitTimes(branches); err == nil {
+			return times
+		}
+	}
 
-	cmd := exec.Command("git", args...)
-	cmd.Dir = wm.repoRoot
-	output, err := cmd.Output()
+	var output []byte
+	err := progress.Step(presenter, "git "+strings.Join(args, " "), func() (err error) {
+		output, err = wm.gitCommand(wm.repoRoot, args...).Output()
+		return err
+	})
 	if err != nil {
 		return result
 	}
@@ -542,8 +1008,7 @@ func (wm *WorktreeManager) mergedBranches() map[string]bool {
 	}
 	remoteBranches := wm.remoteBranches()
 	pushedBranches := wm.pushedBranchEvidence()
-	cmd := exec.Command("git", "branch", "--merged", baseBranch, "--format=%(refname:short)")
-	cmd.Dir = wm.repoRoot
+	cmd := wm.gitCommand(wm.repoRoot, "branch", "--merged", baseBranch, "--format=%(refname:short)")
 	output, err := cmd.Output()
 	if err != nil {
 		return result
@@ -559,8 +1024,7 @@ func (wm *WorktreeManager) mergedBranches() map[string]bool {
 
 func (wm *WorktreeManager) remoteBranches() map[string]bool {
 	result := make(map[string]bool)
-	cmd := exec.Command("git", "for-each-ref", "refs/remotes/origin", "--format=%(refname:short)")
-	cmd.Dir = wm.repoRoot
+	cmd := wm.gitCommand(wm.repoRoot, "for-each-ref", "refs/remotes/origin", "--format=%(refname:short)")
 	output, err := cmd.Output()
 	if err != nil {
 		return result
@@ -579,8 +1043,7 @@ func (wm *WorktreeManager) remoteBranches() map[string]bool {
 
 func (wm *WorktreeManager) pushedBranchEvidence() map[string]bool {
 	result := make(map[string]bool)
-	cmd := exec.Command("git", "reflog", "--all", "--oneline")
-	cmd.Dir = wm.repoRoot
+	cmd := wm.gitCommand(wm.repoRoot, "reflog", "--all", "--oneline")
 	output, err := cmd.Output()
 	if err != nil {
 		return result
@@ -599,8 +1062,7 @@ func (wm *WorktreeManager) pushedBranchEvidence() map[string]bool {
 }
 
 func (wm *WorktreeManager) getCachedBaseBranch() string {
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
-	cmd.Dir = wm.repoRoot
+	cmd := wm.gitCommand(wm.repoRoot, "symbolic-ref", "refs/remotes/origin/HEAD")
 	if output, err := cmd.Output(); err == nil {
 		ref := strings.TrimSpace(string(output))
 		const prefix = "refs/remotes/origin/"
@@ -631,10 +1093,41 @@ func (wm *WorktreeManager) getCachedBaseBranch() string {
 	return ""
 }
 
+// resolveBaseBranch is the branch new worktrees start from: base when a
+// template names one, found locally or on origin, or else the default branch.
+// A base it has resolved already, such as origin/main, resolves to itself
+func (wm *WorktreeManager) resolveBaseBranch(base string, fetch bool) (string, error) {
+	if base == "" {
+		baseBranch, err := wm.defaultBase(fetch)
+		if err != nil {
+			return "", fmt.Errorf("failed to determine base branch: %w", err)
+		}
+		return baseBranch, nil
+	}
+	if wm.branchExists("refs/heads/" + base) {
+		return base, nil
+	}
+	if wm.branchExists("refs/remotes/origin/" + base) {
+		return "origin/" + base, nil
+	}
+	if strings.HasPrefix(base, "origin/") && wm.branchExists("refs/remotes/"+base) {
+		return base, nil
+	}
+	return "", fmt.Errorf("base branch %s not found locally or on origin", base)
+}
+
 func (wm *WorktreeManager) getBaseBranch() (string, error) {
-	defaultBranch, err := wm.getRemoteDefaultBranch()
+	return wm.defaultBase(true)
+}
+
+// defaultBase is the default branch, preferring origin's, which is fetched
+// first when fetch is set so it's up to date
+func (wm *WorktreeManager) defaultBase(fetch bool) (string, error) {
+	defaultBranch, err := wm.getRemoteDefaultBranch(fetch)
 	if err == nil && defaultBranch != "" {
-		_ = wm.fetchRemoteBranch(defaultBranch)
+		if fetch {
+			_ = wm.fetchRemoteBranch(defaultBranch)
+		}
 		if wm.branchExists("refs/remotes/origin/" + defaultBranch) {
 			return "origin/" + defaultBranch, nil
 		}
@@ -666,24 +1159,27 @@ func (wm *WorktreeManager) getBaseBranch() (string, error) {
 }
 
 func (wm *WorktreeManager) branchExists(ref string) bool {
-	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", ref)
-	cmd.Dir = wm.repoRoot
+	if reader := wm.refs(); reader != nil {
+		if exists, err := reader.refExists(ref); err == nil {
+			return exists
+		}
+	}
+	cmd := wm.gitCommand(wm.repoRoot, "show-ref", "--verify", "--quiet", ref)
 	return cmd.Run() == nil
 }
 
-func (wm *WorktreeManager) getRemoteDefaultBranch() (string, error) {
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
-	cmd.Dir = wm.repoRoot
-	if output, err := cmd.Output(); err == nil {
-		ref := strings.TrimSpace(string(output))
-		const prefix = "refs/remotes/origin/"
-		if strings.HasPrefix(ref, prefix) {
-			return strings.TrimPrefix(ref, prefix), nil
-		}
+// getRemoteDefaultBranch is the branch origin's HEAD points at, asking origin
+// when it isn't known locally and ask is set
+func (wm *WorktreeManager) getRemoteDefaultBranch(ask bool) (string, error) {
+	const prefix = "refs/remotes/origin/"
+	if ref := wm.symbolicRef("refs/remotes/origin/HEAD"); strings.HasPrefix(ref, prefix) {
+		return strings.TrimPrefix(ref, prefix), nil
 	}
 
-	cmd = exec.Command("git", "remote", "show", "origin")
-	cmd.Dir = wm.repoRoot
+	if !ask {
+		return "", fmt.Errorf("origin default branch not known without fetching")
+	}
+	cmd := wm.remoteCommand("remote", "show", "origin")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -699,9 +1195,22 @@ func (wm *WorktreeManager) getRemoteDefaultBranch() (string, error) {
 	return "", fmt.Errorf("origin default branch not found")
 }
 
+// symbolicRef is the ref name points at, or "" when it isn't a symbolic ref
+func (wm *WorktreeManager) symbolicRef(name string) string {
+	if reader := wm.refs(); reader != nil {
+		if ref, err := reader.symbolicRef(name); err == nil {
+			return ref
+		}
+	}
+	output, err := wm.gitCommand(wm.repoRoot, "symbolic-ref", name).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
 func (wm *WorktreeManager) fetchRemoteBranch(branchName string) error {
-	cmd := exec.Command("git", "fetch", "origin", branchName)
-	cmd.Dir = wm.repoRoot
+	cmd := wm.remoteCommand("fetch", "--", "origin", branchName)
 	return cmd.Run()
 }
 
@@ -746,114 +1255,316 @@ func sanitizeBranchName(name string) string {
 	return name
 }
 
-func (wm *WorktreeManager) PruneWorktree(branchName string) error {
+func (wm *WorktreeManager) PruneWorktree(branchName string, opts PruneOptions) error {
+	outcome := wm.pruneWorktree(branchName, opts)
+	for _, warning := range outcome.Warnings {
+		opts.presenter().Warning(warning)
+	}
+	if outcome.Status == PruneFailed {
+		return outcome.err
+	}
+
+	out := opts.progress()
+	if outcome.Status == PruneWouldPrune {
+		out.Result(fmt.Sprintf("Would remove worktree '%s' at %s", branchName, outcome.Path))
+		if !opts.KeepBranch {
+			out.Result(fmt.Sprintf("Would delete branch '%s'", branchName))
+		}
+		if opts.DeleteRemote {
+			out.Result(fmt.Sprintf("Would delete remote branch 'origin/%s'", branchName))
+		}
+	} else {
+		out.Result(fmt.Sprintf("Worktree '%s' has been pruned successfully", branchName))
+		if outcome.trashed {
+			out.Result("Changed your mind? sprout undo brings it back")
+		}
+	}
+	if opts.Porcelain {
+		opts.porcelain().Result(fmt.Sprintf("%s\t%s\t%s", outcome.Status, branchName, outcome.Path))
+	}
+	return nil
+}
+
+// pruneWorktree removes branchName's worktree without reporting anything, so
+// PruneWorktree and PruneMergedWorktrees can each say how it went their own
+// way. It's safe to run for several worktrees at once
+func (wm *WorktreeManager) pruneWorktree(branchName string, opts PruneOptions) PruneOutcome {
+	outcome := PruneOutcome{Branch: branchName, Status: PruneFailed}
+	fail := func(err error) PruneOutcome {
+		outcome.err = err
+		outcome.Reason = err.Error()
+		return outcome
+	}
+
 	// For pruning, we should use the branch name as-is since it comes from git worktree list
 	// But we still need to check it's not empty
 	if branchName == "" {
-		return fmt.Errorf("branch name cannot be empty")
+		return fail(fmt.Errorf("branch name cannot be empty"))
 	}
 
 	cfg, err := wm.loadConfig()
 	if err != nil {
-		fmt.Printf("Warning: failed to load config, using default worktree path: %v\n", err)
+		outcome.Warnings = append(outcome.Warnings, fmt.Sprintf("failed to load config, using default worktree path: %v", err))
 	}
 
 	worktreePath := wm.resolveWorktreePath(cfg, branchName)
+	outcome.Path = worktreePath
 
 	// Check if worktree exists
 	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
-		return fmt.Errorf("worktree does not exist: %s", branchName)
+		return fail(fmt.Errorf("worktree does not exist: %s", branchName))
+	}
+	if err := wm.checkRemovable(cfg, branchName, worktreePath); err != nil {
+		return fail(err)
+	}
+	if err := wm.checkUnlocked(branchName, worktreePath, opts); err != nil {
+		return fail(err)
 	}
 
-	// Remove worktree from git
-	cmd := exec.Command("git", "worktree", "remove", worktreePath, "--force")
-	cmd.Dir = wm.repoRoot
+	if opts.DryRun {
+		outcome.Status = PruneWouldPrune
+		return outcome
+	}
+	if err := wm.stopDetached(branchName); err != nil {
+		outcome.Warnings = append(outcome.Warnings, err.Error())
+	}
 
-	if output, err := cmd.CombinedOutput(); err != nil {
-		// If git worktree remove fails, we still want to try to remove the directory
-		fmt.Printf("Warning: git worktree remove failed: %v\nOutput: %s\n", err, string(output))
-		fmt.Println("Attempting to remove directory manually...")
+	if !opts.Permanent {
+		wm.pruneMu.Lock()
+		err := wm.trashWorktree(cfg, branchName, worktreePath, opts.operation, func(warning string) {
+			outcome.Warnings = append(outcome.Warnings, warning)
+		})
+		wm.pruneMu.Unlock()
+		if err != nil {
+			outcome.Warnings = append(outcome.Warnings, fmt.Sprintf("couldn't move %s to the trash, so it can't be undone: %v", worktreePath, err))
+		} else {
+			outcome.trashed = true
+		}
 	}
 
-	// Remove the directory and all its contents
-	if err := os.RemoveAll(worktreePath); err != nil {
-		return fmt.Errorf("failed to remove worktree directory: %w", err)
+	if !outcome.trashed {
+		// Remove worktree from git
+		cmd := wm.gitCommand(wm.repoRoot, "worktree", "remove", worktreePath, "--force")
+
+		if output, err := cmd.CombinedOutput(); err != nil {
+			// If git worktree remove fails, we still want to try to remove the directory
+			outcome.Warnings = append(outcome.Warnings, fmt.Sprintf("git worktree remove failed, so the directory was removed directly: %v\nOutput: %s", err, string(output)))
+		}
+
+		// Remove the directory and all its contents
+		if err := os.RemoveAll(worktreePath); err != nil {
+			return fail(fmt.Errorf("failed to remove worktree directory: %w", err))
+		}
 	}
 
-	// Delete the branch if it exists and has no commits beyond the base
-	cmd = exec.Command("git", "branch", "-D", branchName)
-	cmd.Dir = wm.repoRoot
+	if !opts.KeepBranch {
+		// Delete the branch if it exists and has no commits beyond the base.
+		// Deleting branches side by side can trip over git's lock on packed refs
+		wm.pruneMu.Lock()
+		output, err := wm.gitCommand(wm.repoRoot, "branch", "-D", branchName).CombinedOutput()
+		wm.pruneMu.Unlock()
+		if err != nil {
+			// Branch deletion might fail if it doesn't exist or has unmerged changes
+			// This is not necessarily an error, so we just warn
+			outcome.Warnings = append(outcome.Warnings, fmt.Sprintf("failed to delete branch '%s': %v\nOutput: %s", branchName, err, string(output)))
+		}
+	}
 
-	if output, err := cmd.CombinedOutput(); err != nil {
-		// Branch deletion might fail if it doesn't exist or has unmerged changes
-		// This is not necessarily an error, so we just warn
-		fmt.Printf("Warning: failed to delete branch '%s': %v\nOutput: %s\n", branchName, err, string(output))
+	if opts.DeleteRemote {
+		cmd := wm.remoteCommand("push", "origin", ":"+branchName)
+
+		if output, err := cmd.CombinedOutput(); err != nil {
+			// The remote branch may already have been deleted by the PR merge
+			outcome.Warnings = append(outcome.Warnings, fmt.Sprintf("failed to delete remote branch '%s': %v\nOutput: %s", branchName, err, string(output)))
+		}
 	}
 
-	fmt.Printf("Worktree '%s' has been pruned successfully\n", branchName)
-	return nil
+	wm.metadata.RecordPruned(branchName)
+	wm.publish(metadata.HistoryEvent{Kind: metadata.HistoryWorktreePruned, Branch: branchName, Path: worktreePath})
+	if err := wm.stopTimer(cfg, branchName, worktreePath); err != nil {
+		outcome.Warnings = append(outcome.Warnings, err.Error())
+	}
+
+	outcome.Status = PrunePruned
+	return outcome
 }
 
-func (wm *WorktreeManager) PruneAllMerged() error {
+// MergedWorktrees lists the worktrees PruneAllMerged would remove: those whose
+// PR has been merged, leaving out the default branch
+func (wm *WorktreeManager) MergedWorktrees() ([]Worktree, error) {
 	worktrees, err := wm.ListWorktrees()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	cfg, cfgErr := wm.loadConfig()
 	if cfgErr != nil {
-		fmt.Printf("Warning: failed to load config, using default worktree path: %v\n", cfgErr)
+		wm.presenter().Warning(fmt.Sprintf("failed to load config, using default worktree path: %v", cfgErr))
 	}
 
 	var mergedWorktrees []Worktree
 	for _, wt := range worktrees {
-		// Skip main/master branches and only include merged PRs
-		if wt.Branch == "master" || wt.Branch == "main" || wt.Branch == "" {
-			continue
-		}
-		if wt.PRStatus == "Merged" {
+		if ReadyToPrune(wt) {
 			// Check if worktree directory actually exists
 			worktreePath := wm.resolveWorktreePath(cfg, wt.Branch)
 			if _, err := os.Stat(worktreePath); err == nil {
+				wt.Path = worktreePath
 				mergedWorktrees = append(mergedWorktrees, wt)
 			}
 		}
 	}
+	return mergedWorktrees, nil
+}
+
+// checkUnlocked refuses to remove a locked worktree unless opts.Unlock says
+// to, in which case the lock is lifted first so git lets it go. A pinned
+// worktree is refused either way until it's unpinned
+func (wm *WorktreeManager) checkUnlocked(branchName, worktreePath string, opts PruneOptions) error {
+	worktrees, err := wm.gitWorktrees()
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch != branchName {
+			continue
+		}
+		if wt.Pinned {
+			return fmt.Errorf("%s is pinned; sprout unpin %s first to remove it", branchName, branchName)
+		}
+		if !wt.Locked {
+			continue
+		}
+		if !opts.Unlock {
+			reason := ""
+			if wt.LockReason != "" {
+				reason = " (" + wt.LockReason + ")"
+			}
+			return fmt.Errorf("%s is locked%s; unlock it with git worktree unlock, or pass --unlock to remove it anyway", branchName, reason)
+		}
+		if opts.DryRun {
+			return nil
+		}
+		if output, err := wm.gitCommand(wm.repoRoot, "worktree", "unlock", worktreePath).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to unlock %s: %w\nOutput: %s", branchName, err, string(output))
+		}
+	}
+	return nil
+}
+
+// PruneLargerThan removes every worktree whose directory exceeds threshold bytes.
+// Unmerged branches are always kept so committed work survives, and worktrees
+// with uncommitted changes are skipped entirely. The directories are deleted
+// outright rather than trashed, since the point is to reclaim the space.
+func (wm *WorktreeManager) PruneLargerThan(threshold int64, opts PruneOptions) error {
+	worktrees, err := wm.ListWorktrees()
+	if err != nil {
+		return err
+	}
+
+	cfg, cfgErr := wm.loadConfig()
+	if cfgErr != nil {
+		opts.presenter().Warning(fmt.Sprintf("failed to load config, using default worktree path: %v", cfgErr))
+	}
+
+	var candidates []Worktree
+	var paths []string
+	for _, wt := range worktrees {
+		if wt.keptBecause() != "" {
+			continue
+		}
+		worktreePath := wm.resolveWorktreePath(cfg, wt.Branch)
+		if _, err := os.Stat(worktreePath); err != nil {
+			continue
+		}
+		wt.Path = worktreePath
+		candidates = append(candidates, wt)
+		paths = append(paths, worktreePath)
+	}
+
+	sizes := stats.CachedDirSizes(wm.metadata, paths, stats.DiskUsageMaxAge)
+	var largeWorktrees []Worktree
+	for _, wt := range candidates {
+		if size := sizes[wt.Path]; size > threshold {
+			wt.DiskUsage = size
+			largeWorktrees = append(largeWorktrees, wt)
+		}
+	}
 
-	if len(mergedWorktrees) == 0 {
-		fmt.Println("No merged worktrees found to prune")
+	out := opts.progress()
+	if len(largeWorktrees) == 0 {
+		out.Result(fmt.Sprintf("No worktrees larger than %s found to prune", stats.FormatBytes(threshold)))
 		return nil
 	}
 
-	fmt.Printf("Found %d merged worktree(s) to prune:\n", len(mergedWorktrees))
-	for _, wt := range mergedWorktrees {
-		fmt.Printf("  - %s\n", wt.Branch)
+	listing := []string{fmt.Sprintf("Found %d worktree(s) larger than %s:", len(largeWorktrees), stats.FormatBytes(threshold))}
+	for _, wt := range largeWorktrees {
+		listing = append(listing, fmt.Sprintf("  - %s (%s)", wt.Branch, stats.FormatBytes(wt.DiskUsage)))
 	}
-	fmt.Println()
+	out.Result(strings.Join(listing, "\n") + "\n")
 
 	var failed []string
-	for _, wt := range mergedWorktrees {
-		fmt.Printf("Pruning %s...\n", wt.Branch)
-		if err := wm.PruneWorktree(wt.Branch); err != nil {
-			fmt.Printf("Failed to prune %s: %v\n", wt.Branch, err)
+	var reclaimed int64
+	for _, wt := range largeWorktrees {
+		if wm.HasUncommittedChanges(wt.Path) {
+			out.Result(fmt.Sprintf("Skipping %s: worktree has uncommitted changes", wt.Branch))
+			if opts.Porcelain {
+				opts.porcelain().Result(fmt.Sprintf("skipped\t%s\t%s", wt.Branch, wt.Path))
+			}
+			continue
+		}
+
+		worktreeOpts := opts
+		worktreeOpts.Permanent = true
+		if wt.PRStatus != "Merged" {
+			// An unmerged branch is kept, here and on origin
+			worktreeOpts.KeepBranch = true
+			worktreeOpts.DeleteRemote = false
+		}
+		prune := func() error { return wm.PruneWorktree(wt.Branch, worktreeOpts) }
+		var err error
+		if opts.DryRun {
+			err = prune()
+		} else {
+			err = progress.Step(out, fmt.Sprintf("Pruning %s...", wt.Branch), prune)
+		}
+		if err != nil {
+			opts.presenter().Warning(fmt.Sprintf("failed to prune %s: %v", wt.Branch, err))
 			failed = append(failed, wt.Branch)
+			continue
 		}
+		reclaimed += wt.DiskUsage
 	}
 
 	if len(failed) > 0 {
-		fmt.Printf("\nFailed to prune %d worktree(s): %s\n", len(failed), strings.Join(failed, ", "))
-		return fmt.Errorf("some worktrees could not be pruned")
+		return fmt.Errorf("failed to prune %d worktree(s): %s", len(failed), strings.Join(failed, ", "))
 	}
 
-	fmt.Printf("\nSuccessfully pruned %d merged worktree(s)\n", len(mergedWorktrees))
+	if opts.DryRun {
+		out.Result(fmt.Sprintf("\nDry run: %s would be reclaimed", stats.FormatBytes(reclaimed)))
+		return nil
+	}
+
+	out.Result(fmt.Sprintf("\nReclaimed %s", stats.FormatBytes(reclaimed)))
 	return nil
 }
 
+// HasUncommittedChanges reports whether a worktree has changes or untracked
+// files that pruning it would throw away
+func (wm *WorktreeManager) HasUncommittedChanges(worktreePath string) bool {
+	cmd := wm.gitCommand(worktreePath, "status", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		// If we can't tell, err on the side of keeping the worktree
+		return true
+	}
+	return strings.TrimSpace(string(output)) != ""
+}
+
 // CreateBranch creates a git branch without making a worktree
 func (wm *WorktreeManager) CreateBranch(branchName string) error {
-	sanitizedBranchName := sanitizeBranchName(branchName)
-	if sanitizedBranchName == "" {
-		return fmt.Errorf("branch name results in empty string after sanitization")
+	sanitizedBranchName, err := ValidateBranchName(branchName)
+	if err != nil {
+		return err
 	}
 
 	// Determine the base branch to branch from
@@ -863,17 +1574,16 @@ func (wm *WorktreeManager) CreateBranch(branchName string) error {
 	}
 
 	// If the branch already exists, treat it as success
-	checkCmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+sanitizedBranchName)
-	checkCmd.Dir = wm.repoRoot
+	checkCmd := wm.gitCommand(wm.repoRoot, "show-ref", "--verify", "--quiet", "refs/heads/"+sanitizedBranchName)
 	if err := checkCmd.Run(); err == nil {
 		return nil
 	}
 
-	cmd := exec.Command("git", "branch", sanitizedBranchName, baseBranch)
-	cmd.Dir = wm.repoRoot
+	cmd := wm.gitCommand(wm.repoRoot, "branch", sanitizedBranchName, baseBranch)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to create branch: %w\nOutput: %s", err, string(output))
 	}
 
+	wm.publish(metadata.HistoryEvent{Kind: metadata.HistoryBranchCreated, Branch: sanitizedBranchName, Detail: "from " + baseBranch})
 	return nil
 }
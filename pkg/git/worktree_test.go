@@ -1,7 +1,9 @@
 package git
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,65 +13,18 @@ import (
 	"time"
 
 	"sprout/pkg/config"
+	"sprout/pkg/envtemplate"
 	"sprout/pkg/github"
+	"sprout/pkg/gittest"
+	"sprout/pkg/metadata"
+	"sprout/pkg/progress"
 )
 
 func TestGetBaseBranch(t *testing.T) {
-	// Create a temporary git repository for testing
-	tempDir, err := os.MkdirTemp("", "sprout-test-*")
-	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	// Initialize git repo
-	cmd := exec.Command("git", "init")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to init git repo: %v", err)
-	}
-
-	// Configure git user for commits
-	cmd = exec.Command("git", "config", "user.email", "test@example.com")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to configure git email: %v", err)
-	}
-
-	cmd = exec.Command("git", "config", "user.name", "Test User")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to configure git name: %v", err)
-	}
-
-	// Create initial commit
-	testFile := filepath.Join(tempDir, "README.md")
-	if err := os.WriteFile(testFile, []byte("# Test"), 0644); err != nil {
-		t.Fatalf("Failed to create test file: %v", err)
-	}
-
-	cmd = exec.Command("git", "add", ".")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to add files: %v", err)
-	}
-
-	cmd = exec.Command("git", "commit", "-m", "Initial commit")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to commit: %v", err)
-	}
-
-	wm := &WorktreeManager{repoRoot: tempDir}
+	repo := gittest.New(t)
+	wm := &WorktreeManager{repoRoot: repo.Root}
 
 	t.Run("main branch exists", func(t *testing.T) {
-		// Rename branch to main
-		cmd := exec.Command("git", "branch", "-m", "main")
-		cmd.Dir = tempDir
-		if err := cmd.Run(); err != nil {
-			t.Fatalf("Failed to rename branch: %v", err)
-		}
-
 		branch, err := wm.getBaseBranch()
 		if err != nil {
 			t.Errorf("Expected no error, got: %v", err)
@@ -80,12 +35,7 @@ func TestGetBaseBranch(t *testing.T) {
 	})
 
 	t.Run("master branch exists", func(t *testing.T) {
-		// Rename branch to master
-		cmd := exec.Command("git", "branch", "-m", "master")
-		cmd.Dir = tempDir
-		if err := cmd.Run(); err != nil {
-			t.Fatalf("Failed to rename branch: %v", err)
-		}
+		repo.Git("branch", "-m", "master")
 
 		branch, err := wm.getBaseBranch()
 		if err != nil {
@@ -97,50 +47,10 @@ func TestGetBaseBranch(t *testing.T) {
 	})
 
 	t.Run("remote main exists", func(t *testing.T) {
-		// Create a bare repo to act as remote
-		remoteDir, err := os.MkdirTemp("", "sprout-remote-*")
-		if err != nil {
-			t.Fatalf("Failed to create remote dir: %v", err)
-		}
-		defer os.RemoveAll(remoteDir)
-
-		cmd := exec.Command("git", "init", "--bare")
-		cmd.Dir = remoteDir
-		if err := cmd.Run(); err != nil {
-			t.Fatalf("Failed to init bare repo: %v", err)
-		}
-
-		// Add remote
-		cmd = exec.Command("git", "remote", "add", "origin", remoteDir)
-		cmd.Dir = tempDir
-		if err := cmd.Run(); err != nil {
-			t.Fatalf("Failed to add remote: %v", err)
-		}
-
-		// Push current branch as main
-		cmd = exec.Command("git", "push", "-u", "origin", "master:main")
-		cmd.Dir = tempDir
-		if err := cmd.Run(); err != nil {
-			t.Fatalf("Failed to push: %v", err)
-		}
-
-		cmd = exec.Command("git", "--git-dir", remoteDir, "symbolic-ref", "HEAD", "refs/heads/main")
-		if err := cmd.Run(); err != nil {
-			t.Fatalf("Failed to set remote HEAD: %v", err)
-		}
-
-		// Delete local branch
-		cmd = exec.Command("git", "checkout", "--detach")
-		cmd.Dir = tempDir
-		if err := cmd.Run(); err != nil {
-			t.Fatalf("Failed to detach HEAD: %v", err)
-		}
-
-		cmd = exec.Command("git", "branch", "-D", "master")
-		cmd.Dir = tempDir
-		if err := cmd.Run(); err != nil {
-			t.Fatalf("Failed to delete branch: %v", err)
-		}
+		repo.Git("branch", "-m", "main")
+		repo.Remote("origin")
+		repo.Git("checkout", "--quiet", "--detach")
+		repo.Git("branch", "-D", "main")
 
 		branch, err := wm.getBaseBranch()
 		if err != nil {
@@ -153,188 +63,35 @@ func TestGetBaseBranch(t *testing.T) {
 }
 
 func TestCreateWorktreeFromBase(t *testing.T) {
-	// Create a temporary git repository for testing
-	tempDir, err := os.MkdirTemp("", "sprout-test-*")
-	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
-	}
-	defer func() {
-		// Clean up all temp directories
-		os.RemoveAll(tempDir)
-		worktreesDir := filepath.Join(filepath.Dir(tempDir), ".worktrees")
-		os.RemoveAll(worktreesDir)
-	}()
-
-	// Initialize git repo
-	cmd := exec.Command("git", "init")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to init git repo: %v", err)
-	}
-
-	// Configure git user for commits
-	cmd = exec.Command("git", "config", "user.email", "test@example.com")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to configure git email: %v", err)
-	}
-
-	cmd = exec.Command("git", "config", "user.name", "Test User")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to configure git name: %v", err)
-	}
-
-	// Create initial commit on master
-	testFile := filepath.Join(tempDir, "README.md")
-	if err := os.WriteFile(testFile, []byte("# Test"), 0644); err != nil {
-		t.Fatalf("Failed to create test file: %v", err)
-	}
-
-	cmd = exec.Command("git", "add", ".")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to add files: %v", err)
-	}
-
-	cmd = exec.Command("git", "commit", "-m", "Initial commit")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to commit: %v", err)
-	}
-
-	// Get the master commit hash
-	cmd = exec.Command("git", "rev-parse", "HEAD")
-	cmd.Dir = tempDir
-	masterCommitBytes, err := cmd.Output()
-	if err != nil {
-		t.Fatalf("Failed to get master commit: %v", err)
-	}
-	masterCommit := strings.TrimSpace(string(masterCommitBytes))
+	repo := gittest.New(t)
+	mainCommit := repo.Git("rev-parse", "HEAD")
 
-	// Create another branch with a different commit
-	cmd = exec.Command("git", "checkout", "-b", "feature-branch")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to create feature branch: %v", err)
-	}
-
-	// Make another commit on feature branch
-	if err := os.WriteFile(testFile, []byte("# Test\n\nFeature content"), 0644); err != nil {
-		t.Fatalf("Failed to update test file: %v", err)
-	}
-
-	cmd = exec.Command("git", "add", ".")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to add files: %v", err)
-	}
-
-	cmd = exec.Command("git", "commit", "-m", "Feature commit")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to commit: %v", err)
-	}
+	// Check another branch out with a commit of its own
+	repo.Git("checkout", "--quiet", "-b", "feature-branch")
+	repo.Commit("Feature commit", map[string]string{"README.md": "# Test\n\nFeature content"})
 
-	// Now create a worktree - it should be based on master, not feature-branch
-	// Create a custom WorktreeManager that uses a test-specific worktree path
-	testWorktreeDir := filepath.Join(tempDir, "test-worktrees")
+	// The worktree should start from main, not the branch checked out
+	wm := &WorktreeManager{repoRoot: repo.Root}
+	testWorktreePath := filepath.Join(t.TempDir(), "test-worktree")
 
-	// Temporarily override the CreateWorktree method logic by testing the low-level function
-	// We'll call createNormalWorktree directly with a test path
-	wm := &WorktreeManager{repoRoot: tempDir}
-	testWorktreePath := filepath.Join(testWorktreeDir, "test-worktree")
-
-	// Create the test worktree directory
-	if err := os.MkdirAll(testWorktreeDir, 0755); err != nil {
-		t.Fatalf("Failed to create test worktree dir: %v", err)
-	}
-
-	worktreePath, err := wm.createNormalWorktree(testWorktreePath, "test-worktree")
+	worktreePath, err := wm.createNormalWorktree(testWorktreePath, "test-worktree", "")
 	if err != nil {
 		t.Fatalf("Failed to create worktree: %v", err)
 	}
 
-	// Check that the worktree is based on master commit
-	cmd = exec.Command("git", "rev-parse", "HEAD")
-	cmd.Dir = worktreePath
-	worktreeCommitBytes, err := cmd.Output()
-	if err != nil {
-		t.Fatalf("Failed to get worktree commit: %v", err)
-	}
-	worktreeCommit := strings.TrimSpace(string(worktreeCommitBytes))
-
-	if worktreeCommit != masterCommit {
-		t.Errorf("Expected worktree to be based on master commit %s, but got %s", masterCommit, worktreeCommit)
-	}
-
-	// Verify the branch exists
-	cmd = exec.Command("git", "branch", "--show-current")
-	cmd.Dir = worktreePath
-	branchBytes, err := cmd.Output()
-	if err != nil {
-		t.Fatalf("Failed to get current branch: %v", err)
+	if worktreeCommit := repo.Git("-C", worktreePath, "rev-parse", "HEAD"); worktreeCommit != mainCommit {
+		t.Errorf("Expected worktree to be based on main commit %s, but got %s", mainCommit, worktreeCommit)
 	}
-	currentBranch := strings.TrimSpace(string(branchBytes))
-
-	if currentBranch != "test-worktree" {
+	if currentBranch := repo.Git("-C", worktreePath, "branch", "--show-current"); currentBranch != "test-worktree" {
 		t.Errorf("Expected branch to be 'test-worktree', got '%s'", currentBranch)
 	}
 }
 
 func TestListWorktreesForTUIDoesNotMarkFreshWorktreeAsMerged(t *testing.T) {
-	tempDir, err := os.MkdirTemp("", "sprout-fresh-worktree-*")
-	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
-	}
-	defer func() {
-		os.RemoveAll(tempDir)
-		os.RemoveAll(filepath.Join(filepath.Dir(tempDir), "fresh-worktree"))
-	}()
-
-	cmd := exec.Command("git", "init")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to init git repo: %v", err)
-	}
-
-	cmd = exec.Command("git", "config", "user.email", "test@example.com")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to configure git email: %v", err)
-	}
-
-	cmd = exec.Command("git", "config", "user.name", "Test User")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to configure git name: %v", err)
-	}
-
-	testFile := filepath.Join(tempDir, "README.md")
-	if err := os.WriteFile(testFile, []byte("# Test"), 0644); err != nil {
-		t.Fatalf("Failed to create test file: %v", err)
-	}
-
-	cmd = exec.Command("git", "add", ".")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to add files: %v", err)
-	}
-
-	cmd = exec.Command("git", "commit", "-m", "Initial commit")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to commit: %v", err)
-	}
+	repo := gittest.New(t)
+	repo.Worktree("fresh-worktree")
 
-	worktreePath := filepath.Join(filepath.Dir(tempDir), "fresh-worktree")
-	cmd = exec.Command("git", "worktree", "add", worktreePath, "-b", "fresh-worktree", "master")
-	cmd.Dir = tempDir
-	if output, err := cmd.CombinedOutput(); err != nil {
-		t.Fatalf("Failed to create fresh worktree: %v\nOutput: %s", err, string(output))
-	}
-
-	wm := &WorktreeManager{repoRoot: tempDir}
+	wm := &WorktreeManager{repoRoot: repo.Root}
 	worktrees, err := wm.ListWorktreesForTUI()
 	if err != nil {
 		t.Fatalf("ListWorktreesForTUI returned error: %v", err)
@@ -384,8 +141,7 @@ func TestTUIWorktreeBranchMetadataSkipsForEachRefWhenNoBranches(t *testing.T) {
 }
 
 func TestListWorktreesForTUIUsesGitHubMergedStateForSquashAndDeletedRemoteBranches(t *testing.T) {
-	tempDir, cleanup := setupRepoWithFeatureWorktree(t, "feature-search")
-	defer cleanup()
+	tempDir := setupRepoWithFeatureWorktree(t, "feature-search")
 
 	commands := []string{}
 	wm := &WorktreeManager{
@@ -429,8 +185,7 @@ func TestListWorktreesForTUIKeepsOpenClosedAndNoPRActive(t *testing.T) {
 		{name: "no-pr", output: `[]`},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			tempDir, cleanup := setupRepoWithFeatureWorktree(t, "feature-search")
-			defer cleanup()
+			tempDir := setupRepoWithFeatureWorktree(t, "feature-search")
 
 			wm := &WorktreeManager{
 				repoRoot: tempDir,
@@ -453,8 +208,7 @@ func TestListWorktreesForTUIKeepsOpenClosedAndNoPRActive(t *testing.T) {
 }
 
 func TestListWorktreesForTUIGitHubFailureIncludesExactCommand(t *testing.T) {
-	tempDir, cleanup := setupRepoWithFeatureWorktree(t, "feature-search")
-	defer cleanup()
+	tempDir := setupRepoWithFeatureWorktree(t, "feature-search")
 
 	wm := &WorktreeManager{
 		repoRoot: tempDir,
@@ -473,9 +227,33 @@ func TestListWorktreesForTUIGitHubFailureIncludesExactCommand(t *testing.T) {
 	}
 }
 
+func TestListWorktreesForTUIKeepsWorktreesWhenGitHubTimesOut(t *testing.T) {
+	tempDir := setupRepoWithFeatureWorktree(t, "feature-search")
+
+	wm := &WorktreeManager{
+		repoRoot: tempDir,
+		githubClient: github.NewClientWithRunner(tempDir, func(dir string, name string, args ...string) ([]byte, error) {
+			return nil, fmt.Errorf("%w after 30s", github.ErrTimeout)
+		}),
+	}
+
+	worktrees, err := wm.ListWorktreesForTUIWithProgress(nil)
+	if err != nil {
+		t.Fatalf("expected a GitHub timeout to be tolerated, got %v", err)
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == "feature-search" {
+			if wt.Merged || wt.PRStatus != "" {
+				t.Fatalf("expected an unknown PR status, got %+v", wt)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected feature-search to be listed, got %+v", worktrees)
+}
+
 func TestListWorktreesForTUIChecksGitHubStatusesInParallel(t *testing.T) {
-	tempDir, cleanup := setupRepoWithFeatureWorktrees(t, "feature-one", "feature-two")
-	defer cleanup()
+	tempDir := setupRepoWithFeatureWorktrees(t, "feature-one", "feature-two")
 
 	var active int32
 	var maxActive int32
@@ -505,8 +283,7 @@ func TestListWorktreesForTUIChecksGitHubStatusesInParallel(t *testing.T) {
 }
 
 func TestListWorktreesForTUISkipsGitHubLookupForCachedMergedBranch(t *testing.T) {
-	tempDir, cleanup := setupRepoWithFeatureWorktree(t, "feature-search")
-	defer cleanup()
+	tempDir := setupRepoWithFeatureWorktree(t, "feature-search")
 
 	cachePath := filepath.Join(t.TempDir(), "cache.json")
 	var calls int32
@@ -539,44 +316,17 @@ func TestListWorktreesForTUISkipsGitHubLookupForCachedMergedBranch(t *testing.T)
 	t.Fatalf("feature-search worktree was not returned: %#v", worktrees)
 }
 
-func setupRepoWithFeatureWorktree(t *testing.T, branch string) (string, func()) {
+func setupRepoWithFeatureWorktree(t *testing.T, branch string) string {
 	return setupRepoWithFeatureWorktrees(t, branch)
 }
 
-func setupRepoWithFeatureWorktrees(t *testing.T, branches ...string) (string, func()) {
+func setupRepoWithFeatureWorktrees(t *testing.T, branches ...string) string {
 	t.Helper()
-
-	tempDir, err := os.MkdirTemp("", "sprout-tui-worktree-*")
-	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
-	}
-	var worktreePaths []string
+	repo := gittest.New(t)
 	for _, branch := range branches {
-		worktreePaths = append(worktreePaths, filepath.Join(filepath.Dir(tempDir), branch))
-	}
-	cleanup := func() {
-		os.RemoveAll(tempDir)
-		for _, worktreePath := range worktreePaths {
-			os.RemoveAll(worktreePath)
-		}
+		repo.Worktree(branch)
 	}
-
-	runGit(t, tempDir, "init")
-	runGit(t, tempDir, "config", "user.email", "test@example.com")
-	runGit(t, tempDir, "config", "user.name", "Test User")
-
-	testFile := filepath.Join(tempDir, "README.md")
-	if err := os.WriteFile(testFile, []byte("# Test"), 0644); err != nil {
-		cleanup()
-		t.Fatalf("Failed to create test file: %v", err)
-	}
-	runGit(t, tempDir, "add", ".")
-	runGit(t, tempDir, "commit", "-m", "Initial commit")
-	for i, branch := range branches {
-		runGit(t, tempDir, "worktree", "add", worktreePaths[i], "-b", branch, "master")
-	}
-
-	return tempDir, cleanup
+	return repo.Root
 }
 
 func currentCommit(t *testing.T, dir string, branch string) string {
@@ -600,79 +350,21 @@ func runGit(t *testing.T, dir string, args ...string) {
 }
 
 func TestCreateBranch(t *testing.T) {
-	tempDir, err := os.MkdirTemp("", "sprout-branch-*")
-	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	cmd := exec.Command("git", "init")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to init git repo: %v", err)
-	}
-
-	cmd = exec.Command("git", "config", "user.email", "test@example.com")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to configure git email: %v", err)
-	}
-
-	cmd = exec.Command("git", "config", "user.name", "Test User")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to configure git name: %v", err)
-	}
-
-	testFile := filepath.Join(tempDir, "README.md")
-	if err := os.WriteFile(testFile, []byte("# Branch Test"), 0644); err != nil {
-		t.Fatalf("Failed to create test file: %v", err)
-	}
-
-	cmd = exec.Command("git", "add", ".")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to add files: %v", err)
-	}
-
-	cmd = exec.Command("git", "commit", "-m", "Initial commit")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Failed to commit: %v", err)
-	}
-
-	wm := &WorktreeManager{repoRoot: tempDir}
+	repo := gittest.New(t)
+	wm := &WorktreeManager{repoRoot: repo.Root}
 
 	if err := wm.CreateBranch("Feature Branch!"); err != nil {
 		t.Fatalf("CreateBranch returned error: %v", err)
 	}
-
-	cmd = exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/feature-branch")
-	cmd.Dir = tempDir
-	if err := cmd.Run(); err != nil {
-		t.Fatalf("Expected branch 'feature-branch' to exist: %v", err)
+	if !wm.branchExists("refs/heads/feature-branch") {
+		t.Fatal("Expected branch 'feature-branch' to exist")
 	}
 
 	baseBranch, err := wm.getBaseBranch()
 	if err != nil {
 		t.Fatalf("Failed to determine base branch: %v", err)
 	}
-
-	cmd = exec.Command("git", "rev-parse", baseBranch)
-	cmd.Dir = tempDir
-	baseCommit, err := cmd.Output()
-	if err != nil {
-		t.Fatalf("Failed to get base branch commit: %v", err)
-	}
-
-	cmd = exec.Command("git", "rev-parse", "feature-branch")
-	cmd.Dir = tempDir
-	featureCommit, err := cmd.Output()
-	if err != nil {
-		t.Fatalf("Failed to get feature branch commit: %v", err)
-	}
-
-	if strings.TrimSpace(string(baseCommit)) != strings.TrimSpace(string(featureCommit)) {
+	if repo.Git("rev-parse", baseBranch) != repo.Git("rev-parse", "feature-branch") {
 		t.Fatalf("Expected feature branch to point to base branch commit")
 	}
 
@@ -732,33 +424,164 @@ func TestCreateWorktreeUsesConfiguredBasePath(t *testing.T) {
 	}
 }
 
-func initTestRepo(t *testing.T) string {
-	tempDir, err := os.MkdirTemp("", "sprout-test-*")
+func TestPruneWorktreeHonoursOptions(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	cfg := &config.Config{WorktreeBasePath: t.TempDir()}
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: cfg},
+	}
+
+	worktreePath, err := wm.CreateWorktree("feature-prune")
 	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
+		t.Fatalf("Failed to create worktree: %v", err)
 	}
 
-	t.Cleanup(func() {
-		os.RemoveAll(tempDir)
-		os.RemoveAll(filepath.Join(filepath.Dir(tempDir), ".worktrees"))
-	})
+	if err := wm.PruneWorktree("feature-prune", PruneOptions{DryRun: true}); err != nil {
+		t.Fatalf("dry run returned error: %v", err)
+	}
+	if _, err := os.Stat(worktreePath); err != nil {
+		t.Fatalf("expected dry run to leave worktree in place: %v", err)
+	}
+
+	if err := wm.PruneWorktree("feature-prune", PruneOptions{KeepBranch: true}); err != nil {
+		t.Fatalf("prune returned error: %v", err)
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Fatalf("expected worktree directory to be removed, stat err: %v", err)
+	}
+	if !wm.branchExists("refs/heads/feature-prune") {
+		t.Fatalf("expected --keep-branch to preserve the local branch")
+	}
+}
+
+func TestSparseCreateAndPorcelainPruneReportThroughPresenters(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	if err := os.MkdirAll(filepath.Join(repoRoot, "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoRoot, "api", "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGitCommand(t, repoRoot, "add", ".")
+	runGitCommand(t, repoRoot, "commit", "-m", "Add api")
+	cfg := &config.Config{WorktreeBasePath: t.TempDir()}
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: cfg},
+	}
+
+	var events, results bytes.Buffer
+	worktreePath, err := wm.CreateWorktreeWithOptions("feature-sparse", CreateOptions{SparseDirectories: []string{"api"}, Progress: progress.NewJSON(&events)})
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	if want := `{"type":"result","message":"Created sparse worktree with directories: api"}`; !strings.Contains(events.String(), want) {
+		t.Fatalf("expected the sparse checkout reported as an event, got %q", events.String())
+	}
+
+	events.Reset()
+	opts := PruneOptions{Porcelain: true, Progress: progress.NewJSON(&events), Results: progress.NewText(&results)}
+	if err := wm.PruneWorktree("feature-sparse", opts); err != nil {
+		t.Fatalf("prune returned error: %v", err)
+	}
+	if want := "pruned\tfeature-sparse\t" + worktreePath + "\n"; results.String() != want {
+		t.Fatalf("expected the porcelain line %q, got %q", want, results.String())
+	}
+	if events.Len() != 0 {
+		t.Fatalf("expected porcelain to stand in for progress, got %q", events.String())
+	}
+}
+
+func TestPruneWorktreeLeavesLockedWorktreesUnlessUnlocked(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	cfg := &config.Config{WorktreeBasePath: t.TempDir()}
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: cfg},
+	}
+
+	worktreePath, err := wm.CreateWorktree("feature-locked")
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	runGitCommand(t, repoRoot, "worktree", "lock", "--reason", "on a USB drive", worktreePath)
 
-	runGitCommand(t, tempDir, "init")
-	runGitCommand(t, tempDir, "config", "user.email", "test@example.com")
-	runGitCommand(t, tempDir, "config", "user.name", "Test User")
+	err = wm.PruneWorktree("feature-locked", PruneOptions{})
+	if err == nil || !strings.Contains(err.Error(), "feature-locked is locked (on a USB drive)") {
+		t.Fatalf("expected a locked worktree error, got %v", err)
+	}
+	if _, err := os.Stat(worktreePath); err != nil {
+		t.Fatalf("expected the locked worktree left in place: %v", err)
+	}
 
-	testFile := filepath.Join(tempDir, "README.md")
-	if err := os.WriteFile(testFile, []byte("# Test"), 0644); err != nil {
-		t.Fatalf("Failed to create test file: %v", err)
+	if err := wm.PruneWorktree("feature-locked", PruneOptions{Unlock: true, KeepBranch: true}); err != nil {
+		t.Fatalf("prune with Unlock returned error: %v", err)
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Fatalf("expected the unlocked worktree removed, stat err: %v", err)
+	}
+	worktrees, err := wm.gitWorktrees()
+	if err != nil {
+		t.Fatal(err)
 	}
+	for _, wt := range worktrees {
+		if wt.Branch == "feature-locked" {
+			t.Fatalf("expected git to have forgotten the worktree, got %+v", wt)
+		}
+	}
+}
+
+func TestParseWorktreeListModelsEveryState(t *testing.T) {
+	output := `worktree /repos/sprout.git
+bare
 
-	runGitCommand(t, tempDir, "add", ".")
-	runGitCommand(t, tempDir, "commit", "-m", "Initial commit")
+worktree /repos/worktrees/feature
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/feature
+locked
+
+worktree /repos/worktrees/bisect
+HEAD 2222222222222222222222222222222222222222
+detached
+
+worktree /repos/worktrees/usb
+HEAD 3333333333333333333333333333333333333333
+branch refs/heads/usb
+locked on a USB drive
+prunable gitdir file points to non-existent location
+`
+
+	worktrees := parseWorktreeList(output)
+	if len(worktrees) != 4 {
+		t.Fatalf("expected 4 worktrees, got %+v", worktrees)
+	}
+	if !worktrees[0].Bare || worktrees[0].Branch != "" {
+		t.Errorf("expected the bare repository marked bare, got %+v", worktrees[0])
+	}
+	if !worktrees[1].Locked || worktrees[1].LockReason != "" || worktrees[1].Branch != "feature" {
+		t.Errorf("expected feature locked without a reason, got %+v", worktrees[1])
+	}
+	if !worktrees[2].Detached || worktrees[2].Name() != "(detached at 22222222)" {
+		t.Errorf("expected a detached worktree named by its commit, got %+v", worktrees[2])
+	}
+	if !worktrees[3].Locked || worktrees[3].LockReason != "on a USB drive" || !worktrees[3].Prunable {
+		t.Errorf("expected usb locked with its reason and prunable, got %+v", worktrees[3])
+	}
+	if states := strings.Join(worktrees[3].States(), ", "); states != "locked, prunable" {
+		t.Errorf("expected usb to show as locked, prunable, got %q", states)
+	}
+}
 
-	return tempDir
+func initTestRepo(t testing.TB) string {
+	t.Helper()
+	return gittest.New(t).Root
 }
 
-func runGitCommand(t *testing.T, dir string, args ...string) {
+func runGitCommand(t testing.TB, dir string, args ...string) {
 	t.Helper()
 	cmd := exec.Command("git", args...)
 	cmd.Dir = dir
@@ -767,3 +590,219 @@ func runGitCommand(t *testing.T, dir string, args ...string) {
 		t.Fatalf("Failed to run git %v in %s: %v", strings.Join(args, " "), dir, err)
 	}
 }
+
+func TestCreateWorktreeRendersEnvTemplate(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	templateContent := "BRANCH={{.Branch}}\nISSUE={{.Issue}}\nWORKTREE={{.WorktreePath}}\nWEB_PORT={{.Port}}\nAPI_PORT={{port 1}}\n"
+	if err := os.WriteFile(filepath.Join(repoRoot, ".env.template"), []byte(templateContent), 0644); err != nil {
+		t.Fatalf("Failed to write env template: %v", err)
+	}
+
+	cfg := &config.Config{WorktreeBasePath: t.TempDir(), EnvTemplate: ".env.template"}
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: cfg},
+	}
+
+	worktreePath, err := wm.CreateWorktree("eng-42-add-login")
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+
+	rendered, err := os.ReadFile(filepath.Join(worktreePath, ".env.local"))
+	if err != nil {
+		t.Fatalf("Expected .env.local to be rendered: %v", err)
+	}
+
+	port := envtemplate.PortFor(worktreePath)
+	expected := fmt.Sprintf("BRANCH=eng-42-add-login\nISSUE=ENG-42\nWORKTREE=%s\nWEB_PORT=%d\nAPI_PORT=%d\n", worktreePath, port, port+1)
+	if string(rendered) != expected {
+		t.Fatalf("Expected rendered env file:\n%s\ngot:\n%s", expected, rendered)
+	}
+
+	// Re-running create must not clobber local edits
+	if err := os.WriteFile(filepath.Join(worktreePath, ".env.local"), []byte("EDITED=1\n"), 0644); err != nil {
+		t.Fatalf("Failed to edit .env.local: %v", err)
+	}
+	if _, err := wm.CreateWorktree("eng-42-add-login"); err != nil {
+		t.Fatalf("Failed to re-run create: %v", err)
+	}
+	if rendered, _ := os.ReadFile(filepath.Join(worktreePath, ".env.local")); string(rendered) != "EDITED=1\n" {
+		t.Fatalf("Expected existing .env.local to be preserved, got %q", rendered)
+	}
+}
+
+func TestWorktreesHoldTheirOwnPortsUntilPruned(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	metadataPath := filepath.Join(t.TempDir(), "metadata.json")
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     "Web App",
+		configLoader: &config.DefaultLoader{Config: &config.Config{WorktreeBasePath: t.TempDir()}},
+		metadata:     metadata.NewStoreWithPath(repoRoot, metadataPath),
+	}
+	location, err := wm.Where("eng-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A worktree of another repository already holds the block eng-7's path leads to
+	taken := envtemplate.PortFor(location.Path)
+	metadata.NewStoreWithPath("/repos/other", metadataPath).Allocate("main", repoRoot, "", func(map[int]bool) (int, error) { return taken, nil })
+
+	worktreePath, err := wm.CreateWorktreeWithOptions("eng-7", CreateOptions{Hooks: []string{`echo "$SPROUT_PORT $COMPOSE_PROJECT_NAME" > env.log`}})
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	allocation := wm.metadata.Allocations()["eng-7"]
+	if allocation.Port == taken || allocation.Port == 0 || allocation.ComposeProject != "web-app-eng-7" {
+		t.Fatalf("expected a free block and the compose project allocated, got %+v (taken %d)", allocation, taken)
+	}
+	if log, _ := os.ReadFile(filepath.Join(worktreePath, "env.log")); string(log) != fmt.Sprintf("%d web-app-eng-7\n", allocation.Port) {
+		t.Fatalf("expected the hook to see the allocation, got %q", log)
+	}
+
+	if err := wm.PruneWorktree("eng-7", PruneOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, held := wm.metadata.Allocations()["eng-7"]; held {
+		t.Fatal("expected pruning to release the ports")
+	}
+}
+
+func TestCreateWorktreeFromTemplateBaseRunsHooksOnce(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	runGit(t, repoRoot, "branch", "develop")
+	runGit(t, repoRoot, "checkout", "develop")
+	runGit(t, repoRoot, "commit", "--allow-empty", "-m", "Develop only")
+	runGit(t, repoRoot, "checkout", "-")
+	develop := currentCommit(t, repoRoot, "develop")
+
+	wm := &WorktreeManager{
+		repoRoot:     repoRoot,
+		repoName:     filepath.Base(repoRoot),
+		configLoader: &config.DefaultLoader{Config: &config.Config{WorktreeBasePath: t.TempDir()}},
+	}
+	opts := CreateOptions{BaseBranch: "develop", Hooks: []string{"echo ran >> hooks.log"}}
+
+	worktreePath, err := wm.CreateWorktreeWithOptions("fix/login", opts)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	if head := currentCommit(t, worktreePath, "HEAD"); head != develop {
+		t.Fatalf("Expected the worktree to start from develop at %s, got %s", develop, head)
+	}
+
+	if _, err := wm.CreateWorktreeWithOptions("fix/login", opts); err != nil {
+		t.Fatalf("Failed to re-run create: %v", err)
+	}
+	if log, _ := os.ReadFile(filepath.Join(worktreePath, "hooks.log")); string(log) != "ran\n" {
+		t.Fatalf("Expected the hook to run once, for the new worktree, got %q", log)
+	}
+
+	_, err = wm.CreateWorktreeWithOptions("fix/broken", CreateOptions{Hooks: []string{"echo nope; exit 3"}})
+	if err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Fatalf("Expected the failing hook's output in the error, got %v", err)
+	}
+	if _, err := wm.CreateWorktreeWithOptions("fix/other", CreateOptions{BaseBranch: "missing"}); err == nil {
+		t.Fatal("Expected a missing base branch to fail")
+	}
+}
+
+func TestNewWorktreeManagerForRepoUsesGivenRoot(t *testing.T) {
+	repoRoot := initTestRepo(t)
+
+	wm, err := NewWorktreeManagerForRepo(repoRoot)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if wm.RepoRoot() != repoRoot || wm.RepoName() != filepath.Base(repoRoot) {
+		t.Fatalf("Expected manager for %s, got root %s name %s", repoRoot, wm.RepoRoot(), wm.RepoName())
+	}
+
+	if _, err := NewWorktreeManagerForRepo(t.TempDir()); err == nil {
+		t.Fatalf("Expected an error for a directory that is not a repository")
+	}
+}
+
+func TestFindRepoRootFromInsideAWorktree(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	worktreePath := filepath.Join(filepath.Dir(repoRoot), ".worktrees", "foo")
+	runGitCommand(t, repoRoot, "worktree", "add", "-b", "foo", worktreePath)
+	subdir := filepath.Join(worktreePath, "pkg")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+
+	for _, dir := range []string{repoRoot, worktreePath, subdir} {
+		got, err := FindRepoRoot(dir)
+		if err != nil {
+			t.Fatalf("FindRepoRoot(%s) failed: %v", dir, err)
+		}
+		if got != repoRoot {
+			t.Fatalf("Expected %s from %s, got %s", repoRoot, dir, got)
+		}
+	}
+
+	wm, err := NewWorktreeManagerForRepo(worktreePath)
+	if err != nil {
+		t.Fatalf("Failed to create manager from the worktree: %v", err)
+	}
+	if wm.RepoRoot() != repoRoot || wm.RepoName() != filepath.Base(repoRoot) {
+		t.Fatalf("Expected manager for %s, got root %s name %s", repoRoot, wm.RepoRoot(), wm.RepoName())
+	}
+	want := filepath.Join(filepath.Dir(repoRoot), ".worktrees", "bar")
+	if got := wm.resolveWorktreePath(nil, "bar"); got != want {
+		t.Fatalf("Expected new worktrees beside foo at %s, got %s", want, got)
+	}
+}
+
+func TestFindRepoRootFromInsideABareLayoutWorktree(t *testing.T) {
+	origin := initTestRepo(t)
+	dir := filepath.Join(t.TempDir(), "project")
+	result, err := (&Cloner{}).Clone(origin, dir)
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	featurePath := filepath.Join(dir, "feature")
+	runGitCommand(t, result.PrimaryWorktree, "worktree", "add", "-b", "feature", featurePath)
+
+	got, err := FindRepoRoot(featurePath)
+	if err != nil {
+		t.Fatalf("FindRepoRoot failed: %v", err)
+	}
+	if got != result.PrimaryWorktree {
+		t.Fatalf("Expected the default branch's worktree %s, got %s", result.PrimaryWorktree, got)
+	}
+}
+
+func TestCheckoutForGitDir(t *testing.T) {
+	repoRoot := initTestRepo(t)
+	worktreePath := filepath.Join(filepath.Dir(repoRoot), ".worktrees", "foo")
+	runGitCommand(t, repoRoot, "worktree", "add", "-b", "foo", worktreePath)
+
+	origin := initTestRepo(t)
+	result, err := (&Cloner{}).Clone(origin, filepath.Join(t.TempDir(), "project"))
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+
+	tests := map[string]string{
+		filepath.Join(repoRoot, ".git"):                     repoRoot,
+		filepath.Join(repoRoot, ".git", "worktrees", "foo"): worktreePath,
+		result.BareDir: result.PrimaryWorktree,
+	}
+	for gitDir, want := range tests {
+		got, err := CheckoutForGitDir(gitDir)
+		if err != nil {
+			t.Fatalf("CheckoutForGitDir(%s) failed: %v", gitDir, err)
+		}
+		if got != want {
+			t.Fatalf("Expected %s for %s, got %s", want, gitDir, got)
+		}
+	}
+
+	if _, err := CheckoutForGitDir(t.TempDir()); err == nil {
+		t.Fatalf("Expected an error for a directory that isn't a git directory")
+	}
+}
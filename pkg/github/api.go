@@ -0,0 +1,134 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// APIEndpoint is GitHub's REST API
+const APIEndpoint = "https://api.github.com"
+
+// APIClient talks to GitHub's REST API with a token, for machines without
+// the gh CLI
+type APIClient struct {
+	token      string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewAPIClient authenticates with token, which needs read access to pull
+// requests
+func NewAPIClient(token string) *APIClient {
+	return NewAPIClientWithEndpoint(token, APIEndpoint, nil)
+}
+
+func NewAPIClientWithEndpoint(token, endpoint string, httpClient *http.Client) *APIClient {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &APIClient{
+		token:      token,
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// apiPullRequest is the part of a pull request the API returns that PR
+// status needs
+type apiPullRequest struct {
+	State    string     `json:"state"`
+	MergedAt *time.Time `json:"merged_at"`
+}
+
+// PullRequestStatus is the status of the newest pull request from branch in
+// owner/repo, the same as gh reports it: Open, Merged, Closed or No PR
+func (a *APIClient) PullRequestStatus(ctx context.Context, owner, repo, branch string) (string, error) {
+	query := url.Values{
+		"head":     {owner + ":" + branch},
+		"state":    {"all"},
+		"per_page": {"1"},
+	}
+	var prs []apiPullRequest
+	if err := a.get(ctx, fmt.Sprintf("/repos/%s/%s/pulls?%s", url.PathEscape(owner), url.PathEscape(repo), query.Encode()), &prs); err != nil {
+		return "", err
+	}
+
+	if len(prs) == 0 {
+		return "No PR", nil
+	}
+	switch {
+	case prs[0].State == "open":
+		return "Open", nil
+	case prs[0].MergedAt != nil:
+		return "Merged", nil
+	case prs[0].State == "closed":
+		return "Closed", nil
+	default:
+		return prs[0].State, nil
+	}
+}
+
+// get fetches path from the API and decodes the JSON response into result
+func (a *APIClient) get(ctx context.Context, path string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", a.endpoint+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("GitHub rejected the token, check GH_TOKEN or run sprout auth github again")
+	case resp.StatusCode != http.StatusOK:
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("GitHub API request failed with status %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("GitHub API request failed with status %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// ParseRemoteURL finds the owner and repository in a GitHub remote URL, in
+// any of the forms git accepts: https://github.com/owner/repo.git,
+// git@github.com:owner/repo.git or ssh://git@github.com/owner/repo
+func ParseRemoteURL(remote string) (owner, repo string, err error) {
+	remote = strings.TrimSpace(remote)
+	var path string
+	if parsed, parseErr := url.Parse(remote); parseErr == nil && parsed.Scheme != "" && parsed.Host != "" {
+		path = parsed.Path
+	} else if _, after, found := strings.Cut(remote, ":"); found && !strings.Contains(remote, "://") {
+		// scp-like syntax: [user@]host:owner/repo
+		path = after
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(path, ".git"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("not a GitHub repository URL: %s", remote)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,120 @@
+package github_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sprout/pkg/github"
+)
+
+// pullsEndpoint serves GitHub's pull request list for owner/repo, answering
+// for each branch with the JSON in prs
+func pullsEndpoint(t *testing.T, prs map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ghp_test" {
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/repos/laurenkt/sprout/pulls" || r.URL.Query().Get("state") != "all" {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		head := r.URL.Query().Get("head")
+		body, ok := prs[strings.TrimPrefix(head, "laurenkt:")]
+		if !ok || !strings.HasPrefix(head, "laurenkt:") {
+			body = "[]"
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAPIClientReportsPullRequestStatusLikeGH(t *testing.T) {
+	server := pullsEndpoint(t, map[string]string{
+		"feature-open":   `[{"state":"open","merged_at":null}]`,
+		"feature-merged": `[{"state":"closed","merged_at":"2026-01-02T03:04:05Z"}]`,
+		"feature-closed": `[{"state":"closed","merged_at":null}]`,
+	})
+	api := github.NewAPIClientWithEndpoint("ghp_test", server.URL, nil)
+
+	for branch, want := range map[string]string{
+		"feature-open":   "Open",
+		"feature-merged": "Merged",
+		"feature-closed": "Closed",
+		"feature-new":    "No PR",
+	} {
+		got, err := api.PullRequestStatus(context.Background(), "laurenkt", "sprout", branch)
+		if err != nil {
+			t.Fatalf("PullRequestStatus(%s) failed: %v", branch, err)
+		}
+		if got != want {
+			t.Errorf("expected %s for %s, got %s", want, branch, got)
+		}
+	}
+}
+
+func TestAPIClientRejectedToken(t *testing.T) {
+	server := pullsEndpoint(t, nil)
+	api := github.NewAPIClientWithEndpoint("ghp_revoked", server.URL, nil)
+
+	_, err := api.PullRequestStatus(context.Background(), "laurenkt", "sprout", "feature")
+	if err == nil || !strings.Contains(err.Error(), "GitHub rejected the token") {
+		t.Fatalf("expected a rejected token error, got %v", err)
+	}
+}
+
+func TestClientUsesTheAPIWhenConfigured(t *testing.T) {
+	server := pullsEndpoint(t, map[string]string{"feature": `[{"state":"open","merged_at":null}]`})
+	client := github.NewClientWithRunner(t.TempDir(), func(dir string, name string, args ...string) ([]byte, error) {
+		t.Errorf("expected no commands with the api provider, ran %s %v", name, args)
+		return nil, fmt.Errorf("unexpected command")
+	})
+	client.SetProvider(github.ProviderAPI, nil)
+	client.SetAPI(github.NewAPIClientWithEndpoint("ghp_test", server.URL, nil), "laurenkt", "sprout")
+
+	status, err := client.GetPRStatusFromGitHub("feature")
+	if err != nil {
+		t.Fatalf("GetPRStatusFromGitHub failed: %v", err)
+	}
+	if status != "Open" {
+		t.Fatalf("expected Open, got %s", status)
+	}
+}
+
+func TestClientWithoutGHOrAToken(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	client := github.NewClient(t.TempDir())
+	client.SetProvider(github.ProviderAPI, nil)
+
+	_, err := client.GetPRStatusFromGitHub("feature")
+	if err == nil || !strings.Contains(err.Error(), "no GitHub token was found") {
+		t.Fatalf("expected a missing token error, got %v", err)
+	}
+}
+
+func TestParseRemoteURL(t *testing.T) {
+	for _, remote := range []string{
+		"https://github.com/laurenkt/sprout.git",
+		"https://github.com/laurenkt/sprout",
+		"git@github.com:laurenkt/sprout.git",
+		"ssh://git@github.com/laurenkt/sprout.git\n",
+	} {
+		owner, repo, err := github.ParseRemoteURL(remote)
+		if err != nil {
+			t.Fatalf("ParseRemoteURL(%q) failed: %v", remote, err)
+		}
+		if owner != "laurenkt" || repo != "sprout" {
+			t.Errorf("expected laurenkt/sprout from %q, got %s/%s", remote, owner, repo)
+		}
+	}
+
+	if _, _, err := github.ParseRemoteURL("/srv/git/sprout.git"); err == nil {
+		t.Fatal("expected a local path to be rejected")
+	}
+}
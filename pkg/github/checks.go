@@ -0,0 +1,274 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// What a commit's checks add up to. A commit no check has run on has no CI
+// status, which is the empty string
+const (
+	CIPassing = "passing"
+	CIFailing = "failing"
+	CIPending = "pending"
+)
+
+// How long a commit's CI status is cached before GitHub is asked again.
+// Checks still running are worth asking about again soon; finished ones only
+// change when someone re-runs them
+const (
+	ciPendingTTL  = time.Minute
+	ciFinishedTTL = 30 * time.Minute
+)
+
+// apiCheckRuns is the part of a commit's check runs CI status needs
+type apiCheckRuns struct {
+	CheckRuns []struct {
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	} `json:"check_runs"`
+}
+
+// apiCombinedStatus is the part of a commit's combined status CI status
+// needs, for CI that reports with the older statuses API
+type apiCombinedStatus struct {
+	State      string `json:"state"`
+	TotalCount int    `json:"total_count"`
+}
+
+// summarizeCI adds up check runs and statuses: failing if any failed, then
+// pending if any are still going, then passing if any ran at all
+func summarizeCI(runs apiCheckRuns, combined apiCombinedStatus) string {
+	pending, passed := false, false
+	for _, run := range runs.CheckRuns {
+		if run.Status != "completed" {
+			pending = true
+			continue
+		}
+		switch run.Conclusion {
+		case "failure", "timed_out", "cancelled", "action_required", "startup_failure":
+			return CIFailing
+		case "success":
+			passed = true
+		}
+	}
+	if combined.TotalCount > 0 {
+		switch combined.State {
+		case "failure", "error":
+			return CIFailing
+		case "pending":
+			pending = true
+		case "success":
+			passed = true
+		}
+	}
+	switch {
+	case pending:
+		return CIPending
+	case passed:
+		return CIPassing
+	}
+	return ""
+}
+
+// CommitCIStatus is what the checks on commit sha in owner/repo add up to:
+// CIPassing, CIFailing, CIPending, or "" when none have run
+func (a *APIClient) CommitCIStatus(ctx context.Context, owner, repo, sha string) (string, error) {
+	commit := fmt.Sprintf("/repos/%s/%s/commits/%s", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(sha))
+	var runs apiCheckRuns
+	if err := a.get(ctx, commit+"/check-runs?per_page=100", &runs); err != nil {
+		return "", err
+	}
+	var combined apiCombinedStatus
+	if err := a.get(ctx, commit+"/status", &combined); err != nil {
+		return "", err
+	}
+	return summarizeCI(runs, combined), nil
+}
+
+// CIStatusCommand describes how the client looks up the CI status of commit,
+// for progress messages and errors
+func (c *Client) CIStatusCommand(commit string) string {
+	if c.UsesAPI() {
+		return fmt.Sprintf("GET /repos/{owner}/{repo}/commits/%s/check-runs", commit)
+	}
+	return fmt.Sprintf("gh api repos/{owner}/{repo}/commits/%s/check-runs", commit)
+}
+
+// GetCIStatus is what the checks on commit add up to, asking GitHub through
+// gh or the API unless it was asked recently
+func (c *Client) GetCIStatus(commit string) (string, error) {
+	if commit == "" {
+		return "", nil
+	}
+	if status, ok := c.ciCache.Get(commit); ok {
+		return status, nil
+	}
+
+	var status string
+	var err error
+	if c.UsesAPI() {
+		status, err = c.getCIStatusFromAPI(commit)
+	} else {
+		status, err = c.getCIStatusFromGH(commit)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", c.CIStatusCommand(commit), err)
+	}
+	c.ciCache.Remember(commit, status)
+	return status, nil
+}
+
+func (c *Client) getCIStatusFromGH(commit string) (string, error) {
+	// gh fills in {owner} and {repo} from the repository it runs in
+	path := "repos/{owner}/{repo}/commits/" + commit
+	var runs apiCheckRuns
+	output, err := c.runner(c.repoRoot, "gh", "api", path+"/check-runs?per_page=100")
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(output, &runs); err != nil {
+		return "", err
+	}
+	var combined apiCombinedStatus
+	if output, err = c.runner(c.repoRoot, "gh", "api", path+"/status"); err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(output, &combined); err != nil {
+		return "", err
+	}
+	return summarizeCI(runs, combined), nil
+}
+
+func (c *Client) getCIStatusFromAPI(commit string) (string, error) {
+	api, owner, repo, err := c.apiClient()
+	if err != nil {
+		return "", err
+	}
+	ctx := context.Background()
+	if c.networkTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.networkTimeout)
+		defer cancel()
+	}
+	status, err := api.CommitCIStatus(ctx, owner, repo, commit)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("%w after %s", ErrTimeout, c.networkTimeout)
+	}
+	return status, err
+}
+
+// CIStatusCache remembers the CI status of commits for a while, so listing
+// worktrees again doesn't ask GitHub about every one each time
+type CIStatusCache struct {
+	repoRoot string
+	path     string
+	now      func() time.Time
+	mu       sync.Mutex // held while reading and rewriting the file, as statuses are fetched side by side
+}
+
+type ciStatusCacheFile struct {
+	Repos map[string]map[string]ciStatusEntry `json:"repos"` // by repository, then commit
+}
+
+type ciStatusEntry struct {
+	Status    string    `json:"status"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+func NewCIStatusCache(repoRoot string) *CIStatusCache {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	return NewCIStatusCacheWithPath(repoRoot, filepath.Join(cacheDir, "sprout", "ci-status-cache.json"))
+}
+
+func NewCIStatusCacheWithPath(repoRoot, path string) *CIStatusCache {
+	if path == "" {
+		return nil
+	}
+	return &CIStatusCache{repoRoot: repoRoot, path: path, now: time.Now}
+}
+
+// Get returns the status remembered for commit, unless it's too old to trust
+func (c *CIStatusCache) Get(commit string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cacheFile, err := c.load()
+	if err != nil {
+		return "", false
+	}
+	entry, ok := cacheFile.Repos[c.repoRoot][commit]
+	if !ok {
+		return "", false
+	}
+	ttl := ciFinishedTTL
+	if entry.Status == CIPending {
+		ttl = ciPendingTTL
+	}
+	if c.now().Sub(entry.CheckedAt) > ttl {
+		return "", false
+	}
+	return entry.Status, true
+}
+
+// Remember notes commit's status as of now
+func (c *CIStatusCache) Remember(commit, status string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cacheFile, err := c.load()
+	if err != nil {
+		cacheFile = ciStatusCacheFile{Repos: make(map[string]map[string]ciStatusEntry)}
+	}
+	if cacheFile.Repos[c.repoRoot] == nil {
+		cacheFile.Repos[c.repoRoot] = make(map[string]ciStatusEntry)
+	}
+	// Entries for commits long since replaced would pile up otherwise
+	for cached, entry := range cacheFile.Repos[c.repoRoot] {
+		if c.now().Sub(entry.CheckedAt) > ciFinishedTTL {
+			delete(cacheFile.Repos[c.repoRoot], cached)
+		}
+	}
+	cacheFile.Repos[c.repoRoot][commit] = ciStatusEntry{Status: status, CheckedAt: c.now()}
+	_ = c.save(cacheFile)
+}
+
+func (c *CIStatusCache) load() (ciStatusCacheFile, error) {
+	cacheFile := ciStatusCacheFile{Repos: make(map[string]map[string]ciStatusEntry)}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return cacheFile, err
+	}
+	if err := json.Unmarshal(data, &cacheFile); err != nil {
+		return cacheFile, err
+	}
+	if cacheFile.Repos == nil {
+		cacheFile.Repos = make(map[string]map[string]ciStatusEntry)
+	}
+	return cacheFile, nil
+}
+
+func (c *CIStatusCache) save(cacheFile ciStatusCacheFile) error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cacheFile, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
@@ -0,0 +1,83 @@
+package github_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/github"
+)
+
+func TestAPIClientAddsUpChecksAndStatuses(t *testing.T) {
+	checks := map[string][2]string{ // by commit: check runs, then combined status
+		"green":   {`{"check_runs":[{"status":"completed","conclusion":"success"},{"status":"completed","conclusion":"skipped"}]}`, `{"state":"pending","total_count":0}`},
+		"red":     {`{"check_runs":[{"status":"in_progress","conclusion":null},{"status":"completed","conclusion":"failure"}]}`, `{"state":"success","total_count":1}`},
+		"running": {`{"check_runs":[{"status":"queued","conclusion":null},{"status":"completed","conclusion":"success"}]}`, `{"state":"pending","total_count":0}`},
+		"legacy":  {`{"check_runs":[]}`, `{"state":"error","total_count":1}`},
+		"none":    {`{"check_runs":[]}`, `{"state":"pending","total_count":0}`},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		commit, endpoint, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/repos/laurenkt/sprout/commits/"), "/")
+		answers, ok := checks[commit]
+		switch {
+		case !ok:
+			http.Error(w, `{"message":"No commit found"}`, http.StatusUnprocessableEntity)
+		case endpoint == "check-runs":
+			fmt.Fprint(w, answers[0])
+		case endpoint == "status":
+			fmt.Fprint(w, answers[1])
+		default:
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	api := github.NewAPIClientWithEndpoint("ghp_test", server.URL, nil)
+
+	for commit, want := range map[string]string{
+		"green":   github.CIPassing,
+		"red":     github.CIFailing,
+		"running": github.CIPending,
+		"legacy":  github.CIFailing,
+		"none":    "",
+	} {
+		got, err := api.CommitCIStatus(context.Background(), "laurenkt", "sprout", commit)
+		if err != nil {
+			t.Fatalf("CommitCIStatus(%s) failed: %v", commit, err)
+		}
+		if got != want {
+			t.Errorf("expected %q for %s, got %q", want, commit, got)
+		}
+	}
+}
+
+func TestClientCachesCIStatusFromGH(t *testing.T) {
+	calls := 0
+	runner := func(dir string, name string, args ...string) ([]byte, error) {
+		calls++
+		if name != "gh" || len(args) != 2 || args[0] != "api" {
+			return nil, fmt.Errorf("unexpected command %s %v", name, args)
+		}
+		if strings.HasSuffix(args[1], "/status") {
+			return []byte(`{"state":"success","total_count":1}`), nil
+		}
+		return []byte(`{"check_runs":[]}`), nil
+	}
+	client := github.NewClientWithRunnerAndCachePath(t.TempDir(), runner, filepath.Join(t.TempDir(), "pr-status-cache.json"))
+
+	for range 2 {
+		status, err := client.GetCIStatus("abc123")
+		if err != nil {
+			t.Fatalf("GetCIStatus failed: %v", err)
+		}
+		if status != github.CIPassing {
+			t.Fatalf("expected %s, got %q", github.CIPassing, status)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected the second lookup served from the cache, gh ran %d times", calls)
+	}
+}
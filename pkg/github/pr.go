@@ -1,12 +1,16 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
 type PR struct {
@@ -14,43 +18,139 @@ type PR struct {
 	Title string `json:"title"`
 }
 
+// Providers the client can look PR status up with
+const (
+	ProviderAuto = "auto" // gh when it's installed, otherwise the API
+	ProviderGH   = "gh"
+	ProviderAPI  = "api"
+)
+
 type Client struct {
-	repoRoot string
-	runner   commandRunner
-	cache    *PRStatusCache
+	repoRoot       string
+	runner         commandRunner
+	cache          *PRStatusCache
+	ciCache        *CIStatusCache
+	networkTimeout time.Duration
+	gitTimeout     time.Duration
+
+	provider    string
+	tokens      TokenStoreInterface
+	ghInstalled func() bool
+
+	apiMu    sync.Mutex
+	api      *APIClient
+	apiOwner string
+	apiRepo  string
 }
 
+// ErrTimeout is wrapped by errors from gh or git commands stopped by a timeout
+var ErrTimeout = errors.New("timed out")
+
 type commandRunner func(dir string, name string, args ...string) ([]byte, error)
 
 func NewClient(repoRoot string) *Client {
-	return &Client{
-		repoRoot: repoRoot,
-		runner:   runCommandOutput,
-		cache:    NewPRStatusCache(repoRoot),
-	}
+	return NewClientWithRunner(repoRoot, nil)
 }
 
 func NewClientWithRunner(repoRoot string, runner commandRunner) *Client {
-	if runner == nil {
-		runner = runCommandOutput
+	client := &Client{
+		repoRoot:    repoRoot,
+		runner:      runner,
+		cache:       NewPRStatusCache(repoRoot),
+		ciCache:     NewCIStatusCache(repoRoot),
+		provider:    ProviderAuto,
+		tokens:      &KeychainTokenStore{},
+		ghInstalled: ghOnPath,
 	}
-	return &Client{
-		repoRoot: repoRoot,
-		runner:   runner,
-		cache:    NewPRStatusCache(repoRoot),
+	if client.runner == nil {
+		client.runner = client.runCommandOutput
+	} else {
+		// A runner given in place of running commands stands in for gh too
+		client.ghInstalled = func() bool { return true }
 	}
+	return client
+}
+
+func ghOnPath() bool {
+	_, err := exec.LookPath("gh")
+	return err == nil
+}
+
+// SetProvider picks how PR status is looked up: with gh, with the GitHub API
+// and a token from the environment or tokens, or auto to use gh when it's
+// installed and the API otherwise
+func (c *Client) SetProvider(provider string, tokens TokenStoreInterface) {
+	if provider == "" {
+		provider = ProviderAuto
+	}
+	c.provider = provider
+	c.tokens = tokens
+}
+
+// SetAPI points the client at api for the repository owner/repo instead of
+// finding a token and reading the origin remote, for tests
+func (c *Client) SetAPI(api *APIClient, owner, repo string) {
+	c.apiMu.Lock()
+	defer c.apiMu.Unlock()
+	c.api, c.apiOwner, c.apiRepo = api, owner, repo
+}
+
+// UsesAPI reports whether PR status comes from the GitHub API rather than gh
+func (c *Client) UsesAPI() bool {
+	switch c.provider {
+	case ProviderAPI:
+		return true
+	case ProviderGH:
+		return false
+	default:
+		return !c.ghInstalled()
+	}
+}
+
+// SetTimeouts bounds how long gh may wait on GitHub and how long the local git
+// checks may run; zero leaves either unbounded
+func (c *Client) SetTimeouts(network, git time.Duration) {
+	c.networkTimeout = network
+	c.gitTimeout = git
 }
 
 func NewClientWithRunnerAndCachePath(repoRoot string, runner commandRunner, cachePath string) *Client {
 	client := NewClientWithRunner(repoRoot, runner)
 	client.cache = NewPRStatusCacheWithPath(repoRoot, cachePath)
+	client.ciCache = nil
+	if cachePath != "" {
+		client.ciCache = NewCIStatusCacheWithPath(repoRoot, filepath.Join(filepath.Dir(cachePath), "ci-status-cache.json"))
+	}
 	return client
 }
 
-func runCommandOutput(dir string, name string, args ...string) ([]byte, error) {
-	cmd := exec.Command(name, args...)
+// runCommandOutput runs name in dir, killing it once the timeout for that tool
+// passes: gh talks to GitHub, so it gets the network timeout
+func (c *Client) runCommandOutput(dir string, name string, args ...string) ([]byte, error) {
+	timeout := c.gitTimeout
+	if name == "gh" {
+		timeout = c.networkTimeout
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = dir
-	return cmd.Output()
+	output, err := cmd.Output()
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return output, fmt.Errorf("%w after %s", ErrTimeout, timeout)
+	}
+	return output, err
+}
+
+// git runs a local git check in the repository, bounded by the git timeout
+func (c *Client) git(args ...string) ([]byte, error) {
+	return c.runCommandOutput(c.repoRoot, "git", args...)
 }
 
 func (c *Client) GetPRStatus(branchName string) string {
@@ -64,15 +164,13 @@ func (c *Client) GetPRStatus(branchName string) string {
 		return status
 	}
 
-	// Fallback to gh command if git checks are inconclusive
-	return c.checkPRStatusWithGH(branchName)
+	// Fallback to asking GitHub if git checks are inconclusive
+	return c.checkPRStatusWithGitHub(branchName)
 }
 
 func (c *Client) checkBranchStatusWithGit(branchName string) string {
 	// Check if remote tracking branch exists
-	cmd := exec.Command("git", "rev-parse", "--verify", "origin/"+branchName)
-	cmd.Dir = c.repoRoot
-	if err := cmd.Run(); err != nil {
+	if _, err := c.git("rev-parse", "--verify", "origin/"+branchName); err != nil {
 		// Remote branch doesn't exist - could be never pushed or merged and deleted
 		// Only check for "Merged" if we have evidence the branch was previously pushed
 		if c.wasBranchPushed(branchName) && c.isBranchMerged(branchName) {
@@ -82,7 +180,7 @@ func (c *Client) checkBranchStatusWithGit(branchName string) string {
 	}
 
 	// Remote branch exists, check if it's ahead/behind
-	return "" // Let gh command handle this case
+	return "" // Let GitHub answer this case
 }
 
 func (c *Client) isBranchMerged(branchName string) bool {
@@ -93,16 +191,13 @@ func (c *Client) isBranchMerged(branchName string) bool {
 	}
 
 	// Check if branch commits are in main branch history
-	cmd := exec.Command("git", "merge-base", "--is-ancestor", branchName, mainBranch)
-	cmd.Dir = c.repoRoot
-	return cmd.Run() == nil
+	_, err := c.git("merge-base", "--is-ancestor", branchName, mainBranch)
+	return err == nil
 }
 
 func (c *Client) getMainBranch() string {
 	// Try to get default branch from remote
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
-	cmd.Dir = c.repoRoot
-	if output, err := cmd.Output(); err == nil {
+	if output, err := c.git("symbolic-ref", "refs/remotes/origin/HEAD"); err == nil {
 		// Output format: refs/remotes/origin/main
 		parts := strings.Split(strings.TrimSpace(string(output)), "/")
 		if len(parts) > 0 {
@@ -112,9 +207,7 @@ func (c *Client) getMainBranch() string {
 
 	// Fallback to common names
 	for _, branch := range []string{"main", "master"} {
-		cmd := exec.Command("git", "rev-parse", "--verify", "origin/"+branch)
-		cmd.Dir = c.repoRoot
-		if err := cmd.Run(); err == nil {
+		if _, err := c.git("rev-parse", "--verify", "origin/"+branch); err == nil {
 			return branch
 		}
 	}
@@ -124,9 +217,7 @@ func (c *Client) getMainBranch() string {
 
 func (c *Client) wasBranchPushed(branchName string) bool {
 	// Check git reflog for evidence the branch was pushed
-	cmd := exec.Command("git", "reflog", "--grep-reflog=origin/"+branchName, "--all", "--oneline")
-	cmd.Dir = c.repoRoot
-	output, err := cmd.Output()
+	output, err := c.git("reflog", "--grep-reflog=origin/"+branchName, "--all", "--oneline")
 	if err != nil {
 		return false
 	}
@@ -135,8 +226,8 @@ func (c *Client) wasBranchPushed(branchName string) bool {
 	return len(strings.TrimSpace(string(output))) > 0
 }
 
-func (c *Client) checkPRStatusWithGH(branchName string) string {
-	status, err := c.GetPRStatusFromGH(branchName)
+func (c *Client) checkPRStatusWithGitHub(branchName string) string {
+	status, err := c.GetPRStatusFromGitHub(branchName)
 	if err != nil {
 		return "-"
 	}
@@ -147,6 +238,15 @@ func PRStatusCommand(branchName string) string {
 	return fmt.Sprintf("gh pr list --head %s --state all --json state --limit 1", branchName)
 }
 
+// StatusCommand describes how the client looks up branchName's PR status, for
+// progress messages
+func (c *Client) StatusCommand(branchName string) string {
+	if c.UsesAPI() {
+		return fmt.Sprintf("GET /repos/{owner}/{repo}/pulls?head=%s", branchName)
+	}
+	return PRStatusCommand(branchName)
+}
+
 func (c *Client) CachedMergedPRStatus(branchName, commit string) bool {
 	return c.cache != nil && c.cache.IsMerged(branchName, commit)
 }
@@ -157,10 +257,15 @@ func (c *Client) RememberMergedPRStatus(branchName, commit string) {
 	}
 }
 
-func (c *Client) GetPRStatusFromGH(branchName string) (string, error) {
+// GetPRStatusFromGitHub asks GitHub for the status of branchName's PR, through
+// gh or the API depending on the provider
+func (c *Client) GetPRStatusFromGitHub(branchName string) (string, error) {
 	if branchName == "" || branchName == "master" || branchName == "main" {
 		return "-", nil
 	}
+	if c.UsesAPI() {
+		return c.getPRStatusFromAPI(branchName)
+	}
 
 	output, err := c.runner(c.repoRoot, "gh", "pr", "list", "--head", branchName, "--state", "all", "--json", "state", "--limit", "1")
 	if err != nil {
@@ -188,6 +293,57 @@ func (c *Client) GetPRStatusFromGH(branchName string) (string, error) {
 	}
 }
 
+func (c *Client) getPRStatusFromAPI(branchName string) (string, error) {
+	api, owner, repo, err := c.apiClient()
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	if c.networkTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.networkTimeout)
+		defer cancel()
+	}
+	status, err := api.PullRequestStatus(ctx, owner, repo, branchName)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%s: %w after %s", c.StatusCommand(branchName), ErrTimeout, c.networkTimeout)
+		}
+		return "", fmt.Errorf("%s: %w", c.StatusCommand(branchName), err)
+	}
+	return status, nil
+}
+
+// apiClient sets up the API client the first time it's needed, with a token
+// and the repository the origin remote points at
+func (c *Client) apiClient() (*APIClient, string, string, error) {
+	c.apiMu.Lock()
+	defer c.apiMu.Unlock()
+	if c.api != nil {
+		return c.api, c.apiOwner, c.apiRepo, nil
+	}
+
+	token, err := FindToken(c.tokens)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to read the GitHub token: %w", err)
+	}
+	if token == "" {
+		return nil, "", "", fmt.Errorf("gh is not installed and no GitHub token was found; set GH_TOKEN or run sprout auth github")
+	}
+	remote, err := c.git("remote", "get-url", "origin")
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to read the origin remote: %w", err)
+	}
+	owner, repo, err := ParseRemoteURL(string(remote))
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	c.api, c.apiOwner, c.apiRepo = NewAPIClient(token), owner, repo
+	return c.api, owner, repo, nil
+}
+
 type PRStatusCache struct {
 	repoRoot string
 	path     string
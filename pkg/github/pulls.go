@@ -0,0 +1,214 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoPullRequest is returned when no pull request matches what was asked for
+var ErrNoPullRequest = errors.New("no pull request found")
+
+// PullRequest is a pull request to pick work back up from
+type PullRequest struct {
+	Number          int    `json:"number"`
+	Title           string `json:"title"`
+	HeadBranch      string `json:"headRefName"`
+	State           string `json:"state"` // Open, Merged or Closed, as GetPRStatusFromGitHub reports them
+	URL             string `json:"url"`
+	CrossRepository bool   `json:"isCrossRepository"` // opened from a fork, so its branch isn't on origin
+}
+
+// pullRequestFields are the fields gh is asked for to fill in a PullRequest
+const pullRequestFields = "number,title,headRefName,state,url,isCrossRepository"
+
+// ParsePullRequestNumber reads a pull request number written as 123 or #123
+func ParsePullRequestNumber(ref string) (int, bool) {
+	number, err := strconv.Atoi(strings.TrimPrefix(ref, "#"))
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+	return number, true
+}
+
+// prState is a pull request's state as gh names it: Open, Merged or Closed
+func prState(state string, merged bool) string {
+	switch {
+	case strings.EqualFold(state, "open"):
+		return "Open"
+	case merged || strings.EqualFold(state, "merged"):
+		return "Merged"
+	case strings.EqualFold(state, "closed"):
+		return "Closed"
+	}
+	return state
+}
+
+// apiPullRequestDetail is the part of a pull request the API returns that a
+// PullRequest needs
+type apiPullRequestDetail struct {
+	Number   int        `json:"number"`
+	Title    string     `json:"title"`
+	State    string     `json:"state"`
+	MergedAt *time.Time `json:"merged_at"`
+	HTMLURL  string     `json:"html_url"`
+	Head     struct {
+		Ref  string `json:"ref"`
+		Repo *struct {
+			FullName string `json:"full_name"`
+		} `json:"repo"`
+	} `json:"head"`
+}
+
+func (d apiPullRequestDetail) pullRequest(owner, repo string) PullRequest {
+	return PullRequest{
+		Number:     d.Number,
+		Title:      d.Title,
+		HeadBranch: d.Head.Ref,
+		State:      prState(d.State, d.MergedAt != nil),
+		URL:        d.HTMLURL,
+		// A fork that has since been deleted leaves no repo behind
+		CrossRepository: d.Head.Repo == nil || !strings.EqualFold(d.Head.Repo.FullName, owner+"/"+repo),
+	}
+}
+
+// PullRequest fetches pull request number in owner/repo
+func (a *APIClient) PullRequest(ctx context.Context, owner, repo string, number int) (PullRequest, error) {
+	var detail apiPullRequestDetail
+	if err := a.get(ctx, fmt.Sprintf("/repos/%s/%s/pulls/%d", url.PathEscape(owner), url.PathEscape(repo), number), &detail); err != nil {
+		return PullRequest{}, err
+	}
+	return detail.pullRequest(owner, repo), nil
+}
+
+// PullRequestForBranch fetches the newest pull request from branch in
+// owner/repo, or ErrNoPullRequest when it has none
+func (a *APIClient) PullRequestForBranch(ctx context.Context, owner, repo, branch string) (PullRequest, error) {
+	query := url.Values{
+		"head":     {owner + ":" + branch},
+		"state":    {"all"},
+		"per_page": {"1"},
+	}
+	var prs []apiPullRequestDetail
+	if err := a.get(ctx, fmt.Sprintf("/repos/%s/%s/pulls?%s", url.PathEscape(owner), url.PathEscape(repo), query.Encode()), &prs); err != nil {
+		return PullRequest{}, err
+	}
+	if len(prs) == 0 {
+		return PullRequest{}, ErrNoPullRequest
+	}
+	return prs[0].pullRequest(owner, repo), nil
+}
+
+// SearchPullRequest fetches the best match for text among the pull requests
+// in owner/repo, or ErrNoPullRequest when nothing matches
+func (a *APIClient) SearchPullRequest(ctx context.Context, owner, repo, text string) (PullRequest, error) {
+	query := url.Values{
+		"q":        {fmt.Sprintf("%s repo:%s/%s is:pr", text, owner, repo)},
+		"per_page": {"1"},
+	}
+	var found struct {
+		Items []struct {
+			Number int `json:"number"`
+		} `json:"items"`
+	}
+	if err := a.get(ctx, "/search/issues?"+query.Encode(), &found); err != nil {
+		return PullRequest{}, err
+	}
+	if len(found.Items) == 0 {
+		return PullRequest{}, ErrNoPullRequest
+	}
+	return a.PullRequest(ctx, owner, repo, found.Items[0].Number)
+}
+
+// PullRequestByNumber fetches pull request number, through gh or the API
+func (c *Client) PullRequestByNumber(number int) (PullRequest, error) {
+	describe := fmt.Sprintf("gh pr view %d --json %s", number, pullRequestFields)
+	if c.UsesAPI() {
+		return c.pullRequestFromAPI(fmt.Sprintf("GET /repos/{owner}/{repo}/pulls/%d", number), func(ctx context.Context, api *APIClient, owner, repo string) (PullRequest, error) {
+			return api.PullRequest(ctx, owner, repo, number)
+		})
+	}
+	output, err := c.runner(c.repoRoot, "gh", "pr", "view", strconv.Itoa(number), "--json", pullRequestFields)
+	if err != nil {
+		return PullRequest{}, fmt.Errorf("%s: %w", describe, err)
+	}
+	var pr PullRequest
+	if err := json.Unmarshal(output, &pr); err != nil {
+		return PullRequest{}, fmt.Errorf("%s: %w", describe, err)
+	}
+	pr.State = prState(pr.State, false)
+	return pr, nil
+}
+
+// PullRequestForBranch fetches the newest pull request from branch, or
+// ErrNoPullRequest when it has none
+func (c *Client) PullRequestForBranch(branch string) (PullRequest, error) {
+	if c.UsesAPI() {
+		return c.pullRequestFromAPI(c.StatusCommand(branch), func(ctx context.Context, api *APIClient, owner, repo string) (PullRequest, error) {
+			return api.PullRequestForBranch(ctx, owner, repo, branch)
+		})
+	}
+	return c.listOnePullRequest("--head", branch)
+}
+
+// SearchPullRequest fetches the best match for text, such as an issue
+// identifier in a PR's title or branch, or ErrNoPullRequest when nothing
+// matches
+func (c *Client) SearchPullRequest(text string) (PullRequest, error) {
+	if c.UsesAPI() {
+		return c.pullRequestFromAPI("GET /search/issues?q="+text, func(ctx context.Context, api *APIClient, owner, repo string) (PullRequest, error) {
+			return api.SearchPullRequest(ctx, owner, repo, text)
+		})
+	}
+	return c.listOnePullRequest("--search", text)
+}
+
+// listOnePullRequest runs gh pr list with filter, keeping the first result
+func (c *Client) listOnePullRequest(filter ...string) (PullRequest, error) {
+	args := append([]string{"pr", "list"}, filter...)
+	args = append(args, "--state", "all", "--json", pullRequestFields, "--limit", "1")
+	describe := "gh " + strings.Join(args, " ")
+	output, err := c.runner(c.repoRoot, "gh", args...)
+	if err != nil {
+		return PullRequest{}, fmt.Errorf("%s: %w", describe, err)
+	}
+	var prs []PullRequest
+	if err := json.Unmarshal(output, &prs); err != nil {
+		return PullRequest{}, fmt.Errorf("%s: %w", describe, err)
+	}
+	if len(prs) == 0 {
+		return PullRequest{}, ErrNoPullRequest
+	}
+	prs[0].State = prState(prs[0].State, false)
+	return prs[0], nil
+}
+
+// pullRequestFromAPI runs fetch with the API client, bounded by the network
+// timeout, naming the request describe in errors
+func (c *Client) pullRequestFromAPI(describe string, fetch func(ctx context.Context, api *APIClient, owner, repo string) (PullRequest, error)) (PullRequest, error) {
+	api, owner, repo, err := c.apiClient()
+	if err != nil {
+		return PullRequest{}, err
+	}
+	ctx := context.Background()
+	if c.networkTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.networkTimeout)
+		defer cancel()
+	}
+	pr, err := fetch(ctx, api, owner, repo)
+	switch {
+	case errors.Is(err, ErrNoPullRequest):
+		return PullRequest{}, err
+	case err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return PullRequest{}, fmt.Errorf("%s: %w after %s", describe, ErrTimeout, c.networkTimeout)
+	case err != nil:
+		return PullRequest{}, fmt.Errorf("%s: %w", describe, err)
+	}
+	return pr, nil
+}
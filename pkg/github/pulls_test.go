@@ -0,0 +1,54 @@
+package github_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sprout/pkg/github"
+)
+
+func TestAPIClientFindsPullRequestsByNumberAndSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/laurenkt/sprout/pulls/42":
+			fmt.Fprint(w, `{"number":42,"title":"SPR-12 Fix login","state":"open","merged_at":null,"html_url":"https://github.com/laurenkt/sprout/pull/42","head":{"ref":"spr-12-fix-login","repo":{"full_name":"laurenkt/sprout"}}}`)
+		case "/repos/laurenkt/sprout/pulls/43":
+			fmt.Fprint(w, `{"number":43,"title":"Typo","state":"closed","merged_at":"2026-01-02T03:04:05Z","html_url":"https://github.com/laurenkt/sprout/pull/43","head":{"ref":"patch-1","repo":{"full_name":"someone/sprout"}}}`)
+		case "/search/issues":
+			if r.URL.Query().Get("q") == "SPR-12 repo:laurenkt/sprout is:pr" {
+				fmt.Fprint(w, `{"items":[{"number":42}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"items":[]}`)
+		default:
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	api := github.NewAPIClientWithEndpoint("ghp_test", server.URL, nil)
+	ctx := context.Background()
+
+	pr, err := api.SearchPullRequest(ctx, "laurenkt", "sprout", "SPR-12")
+	if err != nil {
+		t.Fatalf("SearchPullRequest failed: %v", err)
+	}
+	if pr.Number != 42 || pr.HeadBranch != "spr-12-fix-login" || pr.State != "Open" || pr.CrossRepository {
+		t.Fatalf("unexpected PR found for SPR-12: %+v", pr)
+	}
+
+	fork, err := api.PullRequest(ctx, "laurenkt", "sprout", 43)
+	if err != nil {
+		t.Fatalf("PullRequest failed: %v", err)
+	}
+	if fork.State != "Merged" || !fork.CrossRepository {
+		t.Fatalf("expected a merged PR from a fork, got %+v", fork)
+	}
+
+	if _, err := api.SearchPullRequest(ctx, "laurenkt", "sprout", "SPR-99"); !errors.Is(err, github.ErrNoPullRequest) {
+		t.Fatalf("expected ErrNoPullRequest, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"sprout/pkg/keychain"
+)
+
+// keychainAccount names the token sprout auth github stores in the system
+// keychain
+const keychainAccount = "github-token"
+
+// TokenStoreInterface keeps the token the GitHub API is called with between
+// runs
+type TokenStoreInterface interface {
+	Token() (string, error) // "" when nothing is stored
+	SaveToken(token string) error
+	DeleteToken() error
+}
+
+// KeychainTokenStore keeps the token in the system keychain
+type KeychainTokenStore struct{}
+
+func (s *KeychainTokenStore) Token() (string, error) {
+	return keychain.Get(keychainAccount)
+}
+
+func (s *KeychainTokenStore) SaveToken(token string) error {
+	return keychain.Set(keychainAccount, "sprout GitHub token", token)
+}
+
+func (s *KeychainTokenStore) DeleteToken() error {
+	return keychain.Delete(keychainAccount)
+}
+
+// TokenFromEnv is the token gh and GitHub Actions read, GH_TOKEN before
+// GITHUB_TOKEN, or "" when neither is set
+func TokenFromEnv() string {
+	for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// FindToken is the token to call the API with: one from the environment wins
+// over one in store, so CI can supply its own
+func FindToken(store TokenStoreInterface) (string, error) {
+	if token := TokenFromEnv(); token != "" {
+		return token, nil
+	}
+	if store == nil {
+		return "", nil
+	}
+	token, err := store.Token()
+	if errors.Is(err, keychain.ErrUnavailable) {
+		return "", nil
+	}
+	return token, err
+}
@@ -0,0 +1,142 @@
+// Package gittest builds throwaway git repositories for tests. It imports
+// nothing of sprout's, so any package's tests can use it, pkg/git's included.
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// DefaultBranch is the branch a Repo starts out on
+const DefaultBranch = "main"
+
+// fixtureDate is when every fixture commit is made, so the same steps make
+// the same commits in every run
+const fixtureDate = "2024-01-01T00:00:00Z"
+
+// Repo is a throwaway git repository in a temporary directory,
+// removed when the test ends. Its git commands ignore the user's and the
+// system's git config, so tests don't depend on the machine they run on
+type Repo struct {
+	Root string // the main checkout, in a directory of its own beside any worktrees
+
+	t testing.TB
+}
+
+// New makes a repository with one commit on DefaultBranch
+func New(t testing.TB) *Repo {
+	t.Helper()
+	f := &Repo{Root: filepath.Join(t.TempDir(), "repo"), t: t}
+	f.run(filepath.Dir(f.Root), "init", "--initial-branch", DefaultBranch, f.Root)
+	f.Git("config", "user.email", "test@example.com")
+	f.Git("config", "user.name", "Test User")
+	f.Commit("Initial commit", map[string]string{"README.md": "# Test\n"})
+	return f
+}
+
+// Git runs git with args in the main checkout, failing the test if it
+// fails, and returns what it printed with surrounding space trimmed
+func (f *Repo) Git(args ...string) string {
+	f.t.Helper()
+	return f.run(f.Root, args...)
+}
+
+func (f *Repo) run(dir string, args ...string) string {
+	f.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_AUTHOR_DATE="+fixtureDate,
+		"GIT_COMMITTER_DATE="+fixtureDate,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		f.t.Fatalf("git %s failed: %v\nOutput: %s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// Commit writes files, by their path in the repository, and commits them
+// with message on the branch checked out in the main checkout. It returns
+// the new commit
+func (f *Repo) Commit(message string, files map[string]string) string {
+	f.t.Helper()
+	for name, content := range files {
+		path := filepath.Join(f.Root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			f.t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			f.t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	f.Git("add", "--all")
+	f.Git("commit", "--allow-empty", "--quiet", "-m", message)
+	return f.Git("rev-parse", "HEAD")
+}
+
+// Branch starts branch from what the main checkout has checked out,
+// without checking it out
+func (f *Repo) Branch(branch string) {
+	f.t.Helper()
+	f.Git("branch", branch)
+}
+
+// Checkout checks branch out in the main checkout
+func (f *Repo) Checkout(branch string) {
+	f.t.Helper()
+	f.Git("checkout", "--quiet", branch)
+}
+
+// Worktree adds a worktree beside the main checkout for branch, starting
+// the branch from DefaultBranch if it doesn't exist yet, and returns its path
+func (f *Repo) Worktree(branch string) string {
+	f.t.Helper()
+	path := filepath.Join(filepath.Dir(f.Root), branch)
+	if f.hasBranch(branch) {
+		f.Git("worktree", "add", "--quiet", path, branch)
+	} else {
+		f.Git("worktree", "add", "--quiet", "-b", branch, path, DefaultBranch)
+	}
+	return path
+}
+
+func (f *Repo) hasBranch(branch string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	cmd.Dir = f.Root
+	return cmd.Run() == nil
+}
+
+// Remote adds a bare repository as the remote name, pushes every branch to
+// it and, for origin, has origin/HEAD point at DefaultBranch as a clone
+// would. It returns the bare repository's path
+func (f *Repo) Remote(name string) string {
+	f.t.Helper()
+	path := filepath.Join(f.t.TempDir(), name+".git")
+	f.run(filepath.Dir(path), "init", "--bare", "--quiet", "--initial-branch", DefaultBranch, path)
+	f.Git("remote", "add", name, path)
+	f.Git("push", "--quiet", name, "--all")
+	if name == "origin" {
+		f.Git("remote", "set-head", "origin", DefaultBranch)
+	}
+	return path
+}
+
+// Push pushes branch to origin, as git push -u would
+func (f *Repo) Push(branch string) {
+	f.t.Helper()
+	f.Git("push", "--quiet", "--set-upstream", "origin", branch)
+}
+
+// Merge merges branch into DefaultBranch with a merge commit, as a PR is
+// merged, and checks DefaultBranch out in the main checkout
+func (f *Repo) Merge(branch string) {
+	f.t.Helper()
+	f.Checkout(DefaultBranch)
+	f.Git("merge", "--quiet", "--no-ff", "-m", "Merge "+branch, branch)
+}
@@ -0,0 +1,35 @@
+// Package testhook lets sprouttest hand sprout.New a fake worktree manager
+// for a repository root, without the fake's interface appearing in the
+// public sprout.Options.
+package testhook
+
+import (
+	"sync"
+
+	"sprout/pkg/git"
+)
+
+var (
+	mu        sync.Mutex
+	worktrees = map[string]git.WorktreeManagerInterface{}
+)
+
+// SetWorktrees has Worktrees return wm for root until unset is called
+func SetWorktrees(root string, wm git.WorktreeManagerInterface) (unset func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	worktrees[root] = wm
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(worktrees, root)
+	}
+}
+
+// Worktrees is the worktree manager set for root, if any
+func Worktrees(root string) (git.WorktreeManagerInterface, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	wm, ok := worktrees[root]
+	return wm, ok
+}
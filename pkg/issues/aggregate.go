@@ -0,0 +1,333 @@
+package issues
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"sprout/pkg/linear"
+)
+
+// Workspace is one of several issue tracker accounts whose issues are merged
+type Workspace struct {
+	Name   string
+	Client linear.LinearClientInterface
+}
+
+// Aggregate merges the issues assigned to the user in several workspaces into
+// one list, tagging each issue with its workspace and sending changes to an
+// issue back to the workspace it came from
+type Aggregate struct {
+	workspaces []Workspace
+
+	mu     sync.Mutex
+	owners map[string]int // issue ID or identifier to the index of its workspace
+}
+
+// NewAggregate fans calls out to workspaces, listing their issues in the
+// order the workspaces are given
+func NewAggregate(workspaces []Workspace) *Aggregate {
+	return &Aggregate{workspaces: workspaces, owners: make(map[string]int)}
+}
+
+// GetCurrentUser is the user in the first workspace, after checking every
+// workspace's credentials still work
+func (a *Aggregate) GetCurrentUser() (*linear.User, error) {
+	var first *linear.User
+	for _, workspace := range a.workspaces {
+		user, err := workspace.Client.GetCurrentUser()
+		if err != nil {
+			return nil, fmt.Errorf("workspace %s: %w", workspace.Name, err)
+		}
+		if first == nil {
+			first = user
+		}
+	}
+	return first, nil
+}
+
+// GetAssignedIssues loads every workspace's issues at once; if any workspace
+// fails the whole list does, so issues never silently go missing
+func (a *Aggregate) GetAssignedIssues() ([]linear.Issue, error) {
+	return a.StreamAssignedIssues(nil)
+}
+
+// StreamAssignedIssues loads every workspace's issues at once, calling onPage
+// with what every workspace has loaded so far, in workspace order, whenever
+// one of them has another page of issues
+func (a *Aggregate) StreamAssignedIssues(onPage func(linear.IssuesPage)) ([]linear.Issue, error) {
+	results := make([][]linear.Issue, len(a.workspaces))
+	errs := make([]error, len(a.workspaces))
+	pages := make([]linear.IssuesPage, len(a.workspaces))
+	done := make([]bool, len(a.workspaces))
+	var mu sync.Mutex // guards pages and done, and serialises calls to onPage
+	var wg sync.WaitGroup
+	for i, workspace := range a.workspaces {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var pageFunc func(linear.IssuesPage)
+			if onPage != nil {
+				pageFunc = func(page linear.IssuesPage) {
+					a.claim(i, page.Issues)
+					mu.Lock()
+					defer mu.Unlock()
+					pages[i] = page
+					onPage(mergePages(pages, done))
+				}
+			}
+			issues, err := linear.StreamAssignedIssues(workspace.Client, pageFunc)
+			if err != nil {
+				errs[i] = fmt.Errorf("workspace %s: %w", workspace.Name, err)
+				return
+			}
+			results[i] = issues
+			mu.Lock()
+			defer mu.Unlock()
+			pages[i] = linear.IssuesPage{Issues: issues, Loaded: max(pages[i].Loaded, len(issues))}
+			done[i] = true
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	var merged []linear.Issue
+	for i, issues := range results {
+		a.claim(i, issues)
+		merged = append(merged, issues...)
+	}
+	return merged, nil
+}
+
+// mergePages is how far loading has got across every workspace, with a
+// total only once every workspace that's still loading knows its own
+func mergePages(pages []linear.IssuesPage, done []bool) linear.IssuesPage {
+	var merged linear.IssuesPage
+	totalKnown := true
+	for i, page := range pages {
+		merged.Issues = append(merged.Issues, page.Issues...)
+		merged.Loaded += page.Loaded
+		if done[i] {
+			merged.Total += page.Loaded
+		} else {
+			merged.Total += page.Total
+			totalKnown = totalKnown && page.Total > 0
+		}
+	}
+	if !totalKnown {
+		merged.Total = 0
+	}
+	return merged
+}
+
+func (a *Aggregate) GetIssueChildren(issueID string) ([]linear.Issue, error) {
+	owner, err := a.owner(issueID)
+	if err != nil {
+		return nil, err
+	}
+	children, err := a.workspaces[owner].Client.GetIssueChildren(issueID)
+	if err != nil {
+		return nil, err
+	}
+	a.claim(owner, children)
+	return children, nil
+}
+
+// GetChildrenOfIssues asks each workspace for the children of the issues it
+// owns, all at once, and merges what they send back
+func (a *Aggregate) GetChildrenOfIssues(issueIDs []string) (map[string][]linear.Issue, error) {
+	byOwner := make(map[int][]string)
+	for _, issueID := range issueIDs {
+		owner, err := a.owner(issueID)
+		if err != nil {
+			return nil, err
+		}
+		byOwner[owner] = append(byOwner[owner], issueID)
+	}
+
+	results := make(map[int]map[string][]linear.Issue, len(byOwner))
+	errs := make([]error, len(a.workspaces))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for owner, ids := range byOwner {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			children, err := a.workspaces[owner].Client.GetChildrenOfIssues(ids)
+			if err != nil {
+				errs[owner] = fmt.Errorf("workspace %s: %w", a.workspaces[owner].Name, err)
+				return
+			}
+			mu.Lock()
+			results[owner] = children
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string][]linear.Issue, len(issueIDs))
+	for owner, children := range results {
+		for issueID, issues := range children {
+			a.claim(owner, issues)
+			merged[issueID] = issues
+		}
+	}
+	return merged, nil
+}
+
+// GetIssue asks each workspace in turn until one has the issue, since an
+// identifier doesn't say which workspace it belongs to
+func (a *Aggregate) GetIssue(identifier string) (*linear.Issue, error) {
+	var errs []error
+	for i, workspace := range a.workspaces {
+		issue, err := workspace.Client.GetIssue(identifier)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("workspace %s: %w", workspace.Name, err))
+			continue
+		}
+		if issue != nil {
+			found := []linear.Issue{*issue}
+			a.claim(i, found)
+			return &found[0], nil
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (a *Aggregate) CreateSubtask(parentID, title string) (*linear.Issue, error) {
+	return a.CreateSubtaskWithOptions(parentID, title, linear.SubtaskOptions{})
+}
+
+func (a *Aggregate) CreateSubtaskWithOptions(parentID, title string, opts linear.SubtaskOptions) (*linear.Issue, error) {
+	owner, err := a.owner(parentID)
+	if err != nil {
+		return nil, err
+	}
+	subtask, err := a.workspaces[owner].Client.CreateSubtaskWithOptions(parentID, title, opts)
+	if err != nil || subtask == nil {
+		return subtask, err
+	}
+	created := []linear.Issue{*subtask}
+	a.claim(owner, created)
+	return &created[0], nil
+}
+
+func (a *Aggregate) UnassignIssue(issueID string) error {
+	owner, err := a.owner(issueID)
+	if err != nil {
+		return err
+	}
+	return a.workspaces[owner].Client.UnassignIssue(issueID)
+}
+
+func (a *Aggregate) AssignIssueToMe(issueID string) error {
+	owner, err := a.owner(issueID)
+	if err != nil {
+		return err
+	}
+	return a.workspaces[owner].Client.AssignIssueToMe(issueID)
+}
+
+func (a *Aggregate) MarkIssueDone(issueID string) error {
+	owner, err := a.owner(issueID)
+	if err != nil {
+		return err
+	}
+	return a.workspaces[owner].Client.MarkIssueDone(issueID)
+}
+
+func (a *Aggregate) GetWorkflowStates(issueID string) ([]linear.State, error) {
+	owner, err := a.owner(issueID)
+	if err != nil {
+		return nil, err
+	}
+	return a.workspaces[owner].Client.GetWorkflowStates(issueID)
+}
+
+func (a *Aggregate) UpdateIssueState(issueID, stateID string) error {
+	owner, err := a.owner(issueID)
+	if err != nil {
+		return err
+	}
+	return a.workspaces[owner].Client.UpdateIssueState(issueID, stateID)
+}
+
+// GetIssueStates asks every workspace, since identifiers like ENG-123 don't
+// say which workspace they belong to. It only fails when every workspace does.
+func (a *Aggregate) GetIssueStates(identifiers []string) (map[string]linear.State, error) {
+	states := make(map[string]linear.State)
+	var errs []error
+	for _, workspace := range a.workspaces {
+		found, err := workspace.Client.GetIssueStates(identifiers)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("workspace %s: %w", workspace.Name, err))
+			continue
+		}
+		for identifier, state := range found {
+			if _, seen := states[identifier]; !seen {
+				states[identifier] = state
+			}
+		}
+	}
+	if len(errs) == len(a.workspaces) && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return states, nil
+}
+
+func (a *Aggregate) TestConnection() error {
+	var errs []error
+	for _, workspace := range a.workspaces {
+		if err := workspace.Client.TestConnection(); err != nil {
+			errs = append(errs, fmt.Errorf("workspace %s: %w", workspace.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Invalidate has each workspace that keeps what it fetched fetch afresh, so
+// a refresh reaches every workspace rather than none
+func (a *Aggregate) Invalidate() {
+	for _, workspace := range a.workspaces {
+		Invalidate(workspace.Client)
+	}
+}
+
+// claim tags issues and their children with the name of the workspace they
+// came from, and remembers it for later changes to them
+func (a *Aggregate) claim(owner int, issues []linear.Issue) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.claimLocked(owner, issues)
+}
+
+func (a *Aggregate) claimLocked(owner int, issues []linear.Issue) {
+	for i := range issues {
+		issues[i].Workspace = a.workspaces[owner].Name
+		a.owners[issues[i].ID] = owner
+		if issues[i].Identifier != "" {
+			a.owners[issues[i].Identifier] = owner
+		}
+		a.claimLocked(owner, issues[i].Children)
+	}
+}
+
+// owner finds the workspace issueID was loaded from; with one workspace
+// there's nowhere else it could be
+func (a *Aggregate) owner(issueID string) (int, error) {
+	a.mu.Lock()
+	owner, ok := a.owners[issueID]
+	a.mu.Unlock()
+	if ok {
+		return owner, nil
+	}
+	if len(a.workspaces) == 1 {
+		return 0, nil
+	}
+	return 0, fmt.Errorf("no workspace has loaded issue %s", issueID)
+}
@@ -0,0 +1,167 @@
+package issues
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"sprout/pkg/linear"
+)
+
+// workspaceClient is one workspace's issues, recording what was changed there
+type workspaceClient struct {
+	linear.LinearClientInterface
+	issues   []linear.Issue
+	err      error
+	doneIDs  []string
+	children map[string][]linear.Issue
+	batches  [][]string // the issue IDs each GetChildrenOfIssues asked about
+}
+
+func (c *workspaceClient) GetAssignedIssues() ([]linear.Issue, error) {
+	return append([]linear.Issue(nil), c.issues...), c.err
+}
+
+func (c *workspaceClient) GetIssueChildren(issueID string) ([]linear.Issue, error) {
+	return c.children[issueID], nil
+}
+
+func (c *workspaceClient) GetChildrenOfIssues(issueIDs []string) (map[string][]linear.Issue, error) {
+	c.batches = append(c.batches, issueIDs)
+	children := make(map[string][]linear.Issue)
+	for _, issueID := range issueIDs {
+		children[issueID] = c.children[issueID]
+	}
+	return children, nil
+}
+
+func (c *workspaceClient) MarkIssueDone(issueID string) error {
+	c.doneIDs = append(c.doneIDs, issueID)
+	return nil
+}
+
+func TestAggregateMergesWorkspacesAndRoutesChanges(t *testing.T) {
+	work := &workspaceClient{
+		issues:   []linear.Issue{{ID: "w1", Identifier: "ENG-1", Children: []linear.Issue{{ID: "w2", Identifier: "ENG-2"}}}},
+		children: map[string][]linear.Issue{"w1": {{ID: "w3", Identifier: "ENG-3"}}},
+	}
+	client := &workspaceClient{issues: []linear.Issue{{ID: "c1", Identifier: "ACME-1"}}}
+	aggregate := NewAggregate([]Workspace{{Name: "work", Client: work}, {Name: "client", Client: client}})
+
+	issues, err := aggregate.GetAssignedIssues()
+	if err != nil {
+		t.Fatalf("GetAssignedIssues failed: %v", err)
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Identifier+"@"+issue.Workspace)
+	}
+	if strings.Join(got, " ") != "ENG-1@work ACME-1@client" {
+		t.Fatalf("Expected issues from both workspaces in order, got %v", got)
+	}
+	if issues[0].Children[0].Workspace != "work" {
+		t.Fatalf("Expected children tagged too, got %+v", issues[0].Children[0])
+	}
+
+	children, err := aggregate.GetIssueChildren("w1")
+	if err != nil || len(children) != 1 || children[0].Workspace != "work" {
+		t.Fatalf("Expected w1's children from work, got %+v, %v", children, err)
+	}
+	for _, id := range []string{"c1", "w2", "w3"} {
+		if err := aggregate.MarkIssueDone(id); err != nil {
+			t.Fatalf("MarkIssueDone(%s) failed: %v", id, err)
+		}
+	}
+	if fmt.Sprint(work.doneIDs) != "[w2 w3]" || fmt.Sprint(client.doneIDs) != "[c1]" {
+		t.Fatalf("Expected each change sent to its own workspace, got work %v and client %v", work.doneIDs, client.doneIDs)
+	}
+	if err := aggregate.MarkIssueDone("unknown"); err == nil {
+		t.Fatal("Expected an issue no workspace loaded to be refused")
+	}
+}
+
+func TestAggregateFetchesChildrenFromEachWorkspaceInOneCall(t *testing.T) {
+	work := &workspaceClient{
+		issues: []linear.Issue{{ID: "w1"}, {ID: "w2"}},
+		children: map[string][]linear.Issue{
+			"w1": {{ID: "w3", Identifier: "ENG-3"}},
+			"w2": {{ID: "w4", Identifier: "ENG-4"}},
+		},
+	}
+	client := &workspaceClient{
+		issues:   []linear.Issue{{ID: "c1"}},
+		children: map[string][]linear.Issue{"c1": {{ID: "c2", Identifier: "ACME-2"}}},
+	}
+	aggregate := NewAggregate([]Workspace{{Name: "work", Client: work}, {Name: "client", Client: client}})
+	if _, err := aggregate.GetAssignedIssues(); err != nil {
+		t.Fatal(err)
+	}
+
+	children, err := aggregate.GetChildrenOfIssues([]string{"w1", "c1", "w2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(work.batches) != "[[w1 w2]]" || fmt.Sprint(client.batches) != "[[c1]]" {
+		t.Fatalf("Expected one call per workspace for its own issues, got work %v and client %v", work.batches, client.batches)
+	}
+	if children["w2"][0].Workspace != "work" || children["c1"][0].Workspace != "client" {
+		t.Fatalf("Expected children tagged with their workspace, got %+v", children)
+	}
+	if err := aggregate.MarkIssueDone("c2"); err != nil || fmt.Sprint(client.doneIDs) != "[c2]" {
+		t.Fatalf("Expected a fetched child's changes sent to its workspace, got %v (%v)", client.doneIDs, err)
+	}
+	if _, err := aggregate.GetChildrenOfIssues([]string{"unknown"}); err == nil {
+		t.Fatal("Expected an issue no workspace loaded to be refused")
+	}
+}
+
+func TestAggregateFailsWhenAnyWorkspaceFails(t *testing.T) {
+	aggregate := NewAggregate([]Workspace{
+		{Name: "work", Client: &workspaceClient{issues: []linear.Issue{{ID: "w1"}}}},
+		{Name: "client", Client: &workspaceClient{err: fmt.Errorf("invalid API key")}},
+	})
+
+	_, err := aggregate.GetAssignedIssues()
+	if err == nil || err.Error() != "workspace client: invalid API key" {
+		t.Fatalf("Expected the failing workspace named, got %v", err)
+	}
+}
+
+// pagedClient hands over its first issue as a page before the rest
+type pagedClient struct {
+	*workspaceClient
+}
+
+func (c pagedClient) StreamAssignedIssues(onPage func(linear.IssuesPage)) ([]linear.Issue, error) {
+	if onPage != nil {
+		onPage(linear.IssuesPage{Issues: append([]linear.Issue(nil), c.issues[:1]...), Loaded: 1})
+	}
+	return c.GetAssignedIssues()
+}
+
+func TestAggregateStreamsPagesFromEachWorkspace(t *testing.T) {
+	work := pagedClient{&workspaceClient{issues: []linear.Issue{{ID: "w1", Identifier: "ENG-1"}, {ID: "w2", Identifier: "ENG-2"}}}}
+	client := &workspaceClient{issues: []linear.Issue{{ID: "c1", Identifier: "ACME-1"}}}
+	aggregate := NewAggregate([]Workspace{{Name: "work", Client: work}, {Name: "client", Client: client}})
+
+	var pages []linear.IssuesPage
+	issues, err := aggregate.StreamAssignedIssues(func(page linear.IssuesPage) {
+		pages = append(pages, page)
+	})
+	if err != nil {
+		t.Fatalf("StreamAssignedIssues failed: %v", err)
+	}
+	if len(pages) != 1 {
+		t.Fatalf("Expected one page before every workspace finished, got %d", len(pages))
+	}
+	page := pages[0]
+	if page.Issues[0].Identifier != "ENG-1" || page.Issues[0].Workspace != "work" {
+		t.Fatalf("Expected the page to lead with work's first issue, got %+v", page.Issues)
+	}
+	if page.Total != 0 {
+		t.Fatalf("Expected no total while work hasn't said how many it has, got %d", page.Total)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("Expected every issue once loading finished, got %+v", issues)
+	}
+}
@@ -0,0 +1,333 @@
+package issues
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sprout/pkg/linear"
+)
+
+// Invalidator is a client that keeps what it fetched and can be told to
+// forget it
+type Invalidator interface {
+	Invalidate()
+}
+
+// Invalidate has client fetch everything afresh next time it's asked, if it
+// keeps what it fetched
+func Invalidate(client linear.LinearClientInterface) {
+	if cached, ok := client.(Invalidator); ok {
+		cached.Invalidate()
+	}
+}
+
+// Cache answers repeated questions about a workspace's issues, such as its
+// assigned issues and their subtasks, with what was fetched within the last
+// ttl, so moving between repositories and views doesn't fetch the same issues
+// again. Changing an issue through the cache forgets everything it kept,
+// since a new subtask or status can show up in any of it. The issue states
+// sprout list shows are cached by the client itself and pass straight through.
+type Cache struct {
+	linear.LinearClientInterface
+
+	scope string // keeps workspaces apart in the file they share
+	ttl   time.Duration
+	path  string // where the cache is kept between runs; "" keeps it in memory
+	now   func() time.Time
+
+	mu         sync.Mutex
+	entries    map[string]cacheEntry
+	generation int // bumped by Invalidate, so fetches already under way aren't kept
+}
+
+// cacheEntry is the answer to one question, kept in the form written to disk
+// so every hit hands out a copy the caller is free to change
+type cacheEntry struct {
+	Issues    []storedIssue  `json:"issues,omitempty"`
+	States    []linear.State `json:"states,omitempty"`
+	FetchedAt time.Time      `json:"fetchedAt"`
+}
+
+// storedIssue is an Issue as the cache keeps it, labels and all
+type storedIssue struct {
+	linear.Issue
+	Labels   []linear.Label `json:"labels,omitempty"`
+	Children []storedIssue  `json:"children,omitempty"`
+}
+
+// NewCache keeps what client fetches for ttl, in memory only
+func NewCache(client linear.LinearClientInterface, scope string, ttl time.Duration) *Cache {
+	return NewCacheWithPath(client, scope, ttl, "")
+}
+
+// NewCacheWithPath keeps what client fetches for ttl, and in the file at path
+// too so later runs can use it
+func NewCacheWithPath(client linear.LinearClientInterface, scope string, ttl time.Duration, path string) *Cache {
+	return &Cache{
+		LinearClientInterface: client,
+		scope:                 scope,
+		ttl:                   ttl,
+		path:                  path,
+		now:                   time.Now,
+		entries:               make(map[string]cacheEntry),
+	}
+}
+
+// DefaultCachePath is the file issueCacheOnDisk keeps fetched issues in
+func DefaultCachePath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "sprout", "issue-cache.json")
+}
+
+func (c *Cache) GetAssignedIssues() ([]linear.Issue, error) {
+	return c.issues("assigned", c.LinearClientInterface.GetAssignedIssues)
+}
+
+// StreamAssignedIssues hands over pages of assigned issues as they arrive
+// when the cache has to fetch them, and keeps the whole list once it has
+func (c *Cache) StreamAssignedIssues(onPage func(linear.IssuesPage)) ([]linear.Issue, error) {
+	return c.issues("assigned", func() ([]linear.Issue, error) {
+		return linear.StreamAssignedIssues(c.LinearClientInterface, onPage)
+	})
+}
+
+func (c *Cache) GetIssueChildren(issueID string) ([]linear.Issue, error) {
+	return c.issues("children/"+issueID, func() ([]linear.Issue, error) {
+		return c.LinearClientInterface.GetIssueChildren(issueID)
+	})
+}
+
+// GetChildrenOfIssues answers what it can from the cache and fetches the
+// rest in one go
+func (c *Cache) GetChildrenOfIssues(issueIDs []string) (map[string][]linear.Issue, error) {
+	children := make(map[string][]linear.Issue, len(issueIDs))
+	var missing []string
+	for _, issueID := range issueIDs {
+		if entry, ok := c.lookup("children/" + issueID); ok {
+			children[issueID] = restoreIssues(entry.Issues)
+		} else {
+			missing = append(missing, issueID)
+		}
+	}
+	if len(missing) == 0 {
+		return children, nil
+	}
+
+	generation := c.currentGeneration()
+	fetched, err := c.LinearClientInterface.GetChildrenOfIssues(missing)
+	if err != nil {
+		return nil, err
+	}
+	kept := make(map[string]cacheEntry, len(missing))
+	for _, issueID := range missing {
+		children[issueID] = fetched[issueID]
+		kept["children/"+issueID] = cacheEntry{Issues: storeIssues(fetched[issueID])}
+	}
+	c.remember(generation, kept)
+	return children, nil
+}
+
+func (c *Cache) GetIssue(identifier string) (*linear.Issue, error) {
+	found, err := c.issues("issue/"+strings.ToUpper(identifier), func() ([]linear.Issue, error) {
+		issue, err := c.LinearClientInterface.GetIssue(identifier)
+		if err != nil || issue == nil {
+			return nil, err
+		}
+		return []linear.Issue{*issue}, nil
+	})
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+	return &found[0], nil
+}
+
+func (c *Cache) GetWorkflowStates(issueID string) ([]linear.State, error) {
+	key := "states/" + issueID
+	if entry, ok := c.lookup(key); ok {
+		return append([]linear.State(nil), entry.States...), nil
+	}
+	generation := c.currentGeneration()
+	states, err := c.LinearClientInterface.GetWorkflowStates(issueID)
+	if err != nil {
+		return nil, err
+	}
+	c.remember(generation, map[string]cacheEntry{key: {States: append([]linear.State(nil), states...)}})
+	return states, nil
+}
+
+func (c *Cache) CreateSubtask(parentID, title string) (*linear.Issue, error) {
+	return c.CreateSubtaskWithOptions(parentID, title, linear.SubtaskOptions{})
+}
+
+func (c *Cache) CreateSubtaskWithOptions(parentID, title string, opts linear.SubtaskOptions) (*linear.Issue, error) {
+	subtask, err := c.LinearClientInterface.CreateSubtaskWithOptions(parentID, title, opts)
+	if err == nil {
+		c.Invalidate()
+	}
+	return subtask, err
+}
+
+func (c *Cache) UnassignIssue(issueID string) error {
+	return c.changed(c.LinearClientInterface.UnassignIssue(issueID))
+}
+
+func (c *Cache) AssignIssueToMe(issueID string) error {
+	return c.changed(c.LinearClientInterface.AssignIssueToMe(issueID))
+}
+
+func (c *Cache) MarkIssueDone(issueID string) error {
+	return c.changed(c.LinearClientInterface.MarkIssueDone(issueID))
+}
+
+func (c *Cache) UpdateIssueState(issueID, stateID string) error {
+	return c.changed(c.LinearClientInterface.UpdateIssueState(issueID, stateID))
+}
+
+// Invalidate forgets everything the cache kept for this workspace, on disk
+// as well as in memory
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = make(map[string]cacheEntry)
+	if c.path == "" {
+		return
+	}
+	file := c.load()
+	for key := range file {
+		if strings.HasPrefix(key, c.scope+"/") {
+			delete(file, key)
+		}
+	}
+	c.save(file)
+}
+
+// changed forgets what the cache kept once a change has gone through
+func (c *Cache) changed(err error) error {
+	if err == nil {
+		c.Invalidate()
+	}
+	return err
+}
+
+// issues answers the question key from the cache, or with fetch, keeping
+// what it returns
+func (c *Cache) issues(key string, fetch func() ([]linear.Issue, error)) ([]linear.Issue, error) {
+	if entry, ok := c.lookup(key); ok {
+		return restoreIssues(entry.Issues), nil
+	}
+	generation := c.currentGeneration()
+	issues, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.remember(generation, map[string]cacheEntry{key: {Issues: storeIssues(issues)}})
+	return issues, nil
+}
+
+// lookup is the answer kept for key, if it's still fresh
+func (c *Cache) lookup(key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok && c.path != "" {
+		entry, ok = c.load()[c.scope+"/"+key]
+		if ok {
+			c.entries[key] = entry
+		}
+	}
+	if !ok || c.ttl <= 0 || c.now().Sub(entry.FetchedAt) > c.ttl {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *Cache) currentGeneration() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// remember keeps entries fetched since generation, unless the cache was
+// invalidated while they were on their way
+func (c *Cache) remember(generation int, entries map[string]cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation || c.ttl <= 0 {
+		return
+	}
+	now := c.now()
+	for key, entry := range entries {
+		entry.FetchedAt = now
+		c.entries[key] = entry
+	}
+	if c.path == "" {
+		return
+	}
+	file := c.load()
+	for key, entry := range entries {
+		entry.FetchedAt = now
+		file[c.scope+"/"+key] = entry
+	}
+	c.save(file)
+}
+
+// load reads the cache file, which is empty when there isn't one yet
+func (c *Cache) load() map[string]cacheEntry {
+	file := make(map[string]cacheEntry)
+	if data, err := os.ReadFile(c.path); err == nil {
+		_ = json.Unmarshal(data, &file)
+	}
+	return file
+}
+
+// save writes the cache file, dropping entries past their ttl. Failing to is
+// no worse than not having cached them
+func (c *Cache) save(file map[string]cacheEntry) {
+	for key, entry := range file {
+		if c.now().Sub(entry.FetchedAt) > c.ttl {
+			delete(file, key)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return
+	}
+	data, err := json.Marshal(file)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(c.path, data, 0600)
+}
+
+func storeIssues(issues []linear.Issue) []storedIssue {
+	if issues == nil {
+		return nil
+	}
+	stored := make([]storedIssue, len(issues))
+	for i, issue := range issues {
+		stored[i] = storedIssue{Issue: issue, Labels: append([]linear.Label(nil), issue.Labels...), Children: storeIssues(issue.Children)}
+		stored[i].Issue.Labels = nil
+		stored[i].Issue.Children = nil
+	}
+	return stored
+}
+
+func restoreIssues(stored []storedIssue) []linear.Issue {
+	if stored == nil {
+		return nil
+	}
+	issues := make([]linear.Issue, len(stored))
+	for i, s := range stored {
+		issues[i] = s.Issue
+		issues[i].Labels = append([]linear.Label(nil), s.Labels...)
+		issues[i].Children = restoreIssues(s.Children)
+	}
+	return issues
+}
@@ -0,0 +1,167 @@
+package issues
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sprout/pkg/linear"
+)
+
+// countingClient is a workspace that counts what it's asked
+type countingClient struct {
+	workspaceClient
+	assignedCalls int
+	childCalls    int
+}
+
+func (c *countingClient) GetAssignedIssues() ([]linear.Issue, error) {
+	c.assignedCalls++
+	return c.workspaceClient.GetAssignedIssues()
+}
+
+func (c *countingClient) GetIssueChildren(issueID string) ([]linear.Issue, error) {
+	c.childCalls++
+	return c.workspaceClient.GetIssueChildren(issueID)
+}
+
+func newCountingClient() *countingClient {
+	return &countingClient{workspaceClient: workspaceClient{
+		issues: []linear.Issue{{ID: "a1", Identifier: "ENG-1", HasChildren: true, Labels: []linear.Label{{Name: "bug"}}}},
+		children: map[string][]linear.Issue{
+			"a1": {{ID: "a2", Identifier: "ENG-2"}},
+			"a3": {{ID: "a4", Identifier: "ENG-4"}},
+		},
+	}}
+}
+
+func TestCacheReusesAnswersUntilAnIssueChanges(t *testing.T) {
+	client := newCountingClient()
+	cache := NewCache(client, "work", time.Minute)
+
+	first, err := cache.GetAssignedIssues()
+	if err != nil {
+		t.Fatalf("GetAssignedIssues failed: %v", err)
+	}
+	// What the caller does with its issues doesn't reach the cache
+	first[0].Expanded = true
+	first[0].Labels[0].Name = "changed"
+	again, _ := cache.GetAssignedIssues()
+	if client.assignedCalls != 1 {
+		t.Fatalf("Expected the issues fetched once, got %d", client.assignedCalls)
+	}
+	if again[0].Expanded || again[0].Labels[0].Name != "bug" {
+		t.Fatalf("Expected the cached issues as fetched, got %+v", again[0])
+	}
+
+	if _, err := cache.GetIssueChildren("a1"); err != nil {
+		t.Fatalf("GetIssueChildren failed: %v", err)
+	}
+	children, err := cache.GetChildrenOfIssues([]string{"a1", "a3"})
+	if err != nil {
+		t.Fatalf("GetChildrenOfIssues failed: %v", err)
+	}
+	if len(children["a1"]) != 1 || len(children["a3"]) != 1 {
+		t.Fatalf("Expected the children of both issues, got %v", children)
+	}
+	if client.childCalls != 1 || len(client.batches) != 1 || strings.Join(client.batches[0], ",") != "a3" {
+		t.Fatalf("Expected only a3's children fetched again, got %d single fetches and batches %v", client.childCalls, client.batches)
+	}
+
+	if err := cache.MarkIssueDone("a1"); err != nil {
+		t.Fatalf("MarkIssueDone failed: %v", err)
+	}
+	cache.GetAssignedIssues()
+	cache.GetIssueChildren("a1")
+	if client.assignedCalls != 2 || client.childCalls != 2 {
+		t.Fatalf("Expected a change to make the cache ask again, got %d and %d fetches", client.assignedCalls, client.childCalls)
+	}
+
+	Invalidate(cache)
+	cache.GetAssignedIssues()
+	if client.assignedCalls != 3 {
+		t.Fatalf("Expected Invalidate to make the cache ask again, got %d fetches", client.assignedCalls)
+	}
+}
+
+func TestInvalidatingAnAggregateReachesEveryWorkspace(t *testing.T) {
+	work, home := newCountingClient(), newCountingClient()
+	aggregate := NewAggregate([]Workspace{
+		{Name: "work", Client: NewCache(work, "work", time.Minute)},
+		{Name: "home", Client: NewCache(home, "home", time.Minute)},
+	})
+
+	aggregate.GetAssignedIssues()
+	aggregate.GetAssignedIssues()
+	if work.assignedCalls != 1 || home.assignedCalls != 1 {
+		t.Fatalf("Expected each workspace fetched once, got %d and %d", work.assignedCalls, home.assignedCalls)
+	}
+
+	Invalidate(aggregate)
+	aggregate.GetAssignedIssues()
+	if work.assignedCalls != 2 || home.assignedCalls != 2 {
+		t.Fatalf("Expected Invalidate to make every workspace ask again, got %d and %d", work.assignedCalls, home.assignedCalls)
+	}
+}
+
+func TestCacheWithNoTTLAlwaysAsks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "issue-cache.json")
+	client := newCountingClient()
+	cache := NewCacheWithPath(client, "work", 0, path)
+
+	cache.GetAssignedIssues()
+	cache.GetAssignedIssues()
+	if client.assignedCalls != 2 {
+		t.Fatalf("Expected issueCacheSeconds 0 to fetch every time, got %d fetches", client.assignedCalls)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Expected nothing kept on disk, stat returned %v", err)
+	}
+}
+
+func TestCacheExpiresAndKeepsAnswersOnDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "issue-cache.json")
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	client := newCountingClient()
+	cache := NewCacheWithPath(client, "work", time.Minute, path)
+	cache.now = func() time.Time { return now }
+	cache.GetAssignedIssues()
+
+	// Another run finds the issues on disk, labels and all
+	later := newCountingClient()
+	reopened := NewCacheWithPath(later, "work", time.Minute, path)
+	reopened.now = func() time.Time { return now.Add(30 * time.Second) }
+	issues, err := reopened.GetAssignedIssues()
+	if err != nil || later.assignedCalls != 0 || len(issues) != 1 || issues[0].LabelNames()[0] != "bug" {
+		t.Fatalf("Expected the issues read back from disk, got %+v (%v) after %d fetches", issues, err, later.assignedCalls)
+	}
+
+	// Another workspace's issues are its own
+	other := newCountingClient()
+	NewCacheWithPath(other, "home", time.Minute, path).GetAssignedIssues()
+	if other.assignedCalls != 1 {
+		t.Fatalf("Expected another workspace to fetch its own issues, got %d fetches", other.assignedCalls)
+	}
+
+	reopened.now = func() time.Time { return now.Add(2 * time.Minute) }
+	reopened.GetAssignedIssues()
+	if later.assignedCalls != 1 {
+		t.Fatalf("Expected issues older than the ttl fetched again, got %d fetches", later.assignedCalls)
+	}
+}
+
+func TestCacheDropsFetchesOvertakenByAChange(t *testing.T) {
+	client := newCountingClient()
+	cache := NewCache(client, "work", time.Minute)
+	cache.issues("assigned", func() ([]linear.Issue, error) {
+		// The issues change while they're on their way
+		cache.Invalidate()
+		return client.GetAssignedIssues()
+	})
+	cache.GetAssignedIssues()
+	if client.assignedCalls != 2 {
+		t.Fatalf("Expected issues fetched before a change not to be kept, got %d fetches", client.assignedCalls)
+	}
+}
@@ -0,0 +1,118 @@
+package issues
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"sprout/pkg/linear"
+)
+
+// CycleFilter narrows the issue tree to one Linear cycle. The zero value lets
+// every issue through
+type CycleFilter struct {
+	Current bool          // whichever cycle is under way
+	Cycle   *linear.Cycle // this cycle, matched by ID or, without one, by number
+}
+
+// ParseCycleFilter reads the issueCycle setting: "all" for every cycle,
+// "current" for the one under way, or a cycle's number. Unset, it's every
+// cycle until DefaultCycleFilter has seen the issues
+func ParseCycleFilter(value string) CycleFilter {
+	switch value {
+	case "", "all":
+		return CycleFilter{}
+	case "current":
+		return CycleFilter{Current: true}
+	}
+	if number, err := strconv.Atoi(value); err == nil && number > 0 {
+		return CycleFilter{Cycle: &linear.Cycle{Number: float64(number)}}
+	}
+	return CycleFilter{}
+}
+
+// DefaultCycleFilter is what the issue tree is narrowed to when no cycle has
+// been chosen: the current cycle, unless none of the issues is in it, as for
+// a team that doesn't plan in cycles, when every issue is let through
+func DefaultCycleFilter(issues []linear.Issue, now time.Time) CycleFilter {
+	current := CycleFilter{Current: true}
+	for _, issue := range issues {
+		if current.Includes(issue, now) {
+			return current
+		}
+	}
+	return CycleFilter{}
+}
+
+// Active reports whether the filter leaves any issues out
+func (f CycleFilter) Active() bool {
+	return f.Current || f.Cycle != nil
+}
+
+// Equal reports whether both filters let the same cycle through
+func (f CycleFilter) Equal(other CycleFilter) bool {
+	if f.Current != other.Current || (f.Cycle == nil) != (other.Cycle == nil) {
+		return false
+	}
+	return f.Cycle == nil || f.Cycle.Number == other.Cycle.Number
+}
+
+// Label is what the filter shows, such as "current" or "Cycle 12"
+func (f CycleFilter) Label() string {
+	switch {
+	case f.Current:
+		return "current"
+	case f.Cycle != nil:
+		return f.Cycle.Label()
+	}
+	return "all"
+}
+
+// Includes reports whether the issue, or any of its loaded subtasks, is in
+// the filter's cycle, so a parent stays in the tree for the subtasks
+// scheduled in it. now is when the current cycle is judged
+func (f CycleFilter) Includes(issue linear.Issue, now time.Time) bool {
+	if !f.Active() || f.matches(issue.Cycle, now) {
+		return true
+	}
+	for _, child := range issue.Children {
+		if f.Includes(child, now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f CycleFilter) matches(cycle *linear.Cycle, now time.Time) bool {
+	switch {
+	case cycle == nil:
+		return false
+	case f.Current:
+		return cycle.ActiveAt(now)
+	case f.Cycle.ID != "":
+		return cycle.ID == f.Cycle.ID
+	}
+	return cycle.Number == f.Cycle.Number
+}
+
+// Cycles lists the cycles the issues and their loaded subtasks are
+// scheduled in, each once, by number
+func Cycles(issues []linear.Issue) []linear.Cycle {
+	seen := make(map[float64]bool)
+	var cycles []linear.Cycle
+	var collect func(issues []linear.Issue)
+	collect = func(issues []linear.Issue) {
+		for _, issue := range issues {
+			if issue.Cycle != nil && !seen[issue.Cycle.Number] {
+				seen[issue.Cycle.Number] = true
+				cycles = append(cycles, *issue.Cycle)
+			}
+			collect(issue.Children)
+		}
+	}
+	collect(issues)
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i].Number < cycles[j].Number
+	})
+	return cycles
+}
@@ -0,0 +1,77 @@
+package issues
+
+import (
+	"testing"
+	"time"
+
+	"sprout/pkg/linear"
+)
+
+func TestCycleFilter(t *testing.T) {
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	past := &linear.Cycle{ID: "c11", Number: 11, StartsAt: now.AddDate(0, 0, -21), EndsAt: now.AddDate(0, 0, -7)}
+	current := &linear.Cycle{ID: "c12", Number: 12, StartsAt: now.AddDate(0, 0, -7), EndsAt: now.AddDate(0, 0, 7)}
+
+	unscheduled := linear.Issue{Identifier: "SPR-1"}
+	parent := linear.Issue{Identifier: "SPR-2", Cycle: past, Children: []linear.Issue{
+		{Identifier: "SPR-3", Cycle: current},
+	}}
+	earlier := linear.Issue{Identifier: "SPR-4", Cycle: past}
+
+	tests := []struct {
+		name     string
+		filter   CycleFilter
+		label    string
+		included []string
+	}{
+		{"all", ParseCycleFilter("all"), "all", []string{"SPR-1", "SPR-2", "SPR-4"}},
+		{"current", ParseCycleFilter("current"), "current", []string{"SPR-2"}},
+		{"by number", ParseCycleFilter("11"), "Cycle 11", []string{"SPR-2", "SPR-4"}},
+		{"by ID", CycleFilter{Cycle: current}, "Cycle 12", []string{"SPR-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Label(); got != tt.label {
+				t.Errorf("expected label %q, got %q", tt.label, got)
+			}
+			var included []string
+			for _, issue := range []linear.Issue{unscheduled, parent, earlier} {
+				if tt.filter.Includes(issue, now) {
+					included = append(included, issue.Identifier)
+				}
+			}
+			if len(included) != len(tt.included) {
+				t.Fatalf("expected %v, got %v", tt.included, included)
+			}
+			for i := range included {
+				if included[i] != tt.included[i] {
+					t.Fatalf("expected %v, got %v", tt.included, included)
+				}
+			}
+		})
+	}
+
+	cycles := Cycles([]linear.Issue{parent, earlier, unscheduled})
+	if len(cycles) != 2 || cycles[0].Number != 11 || cycles[1].Number != 12 {
+		t.Errorf("expected cycles 11 and 12, got %+v", cycles)
+	}
+}
+
+func TestDefaultCycleFilterIsTheCurrentCycleWhenIssuesAreInIt(t *testing.T) {
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	current := &linear.Cycle{ID: "c12", Number: 12, StartsAt: now.AddDate(0, 0, -7), EndsAt: now.AddDate(0, 0, 7)}
+	next := &linear.Cycle{ID: "c13", Number: 13, StartsAt: now.AddDate(0, 0, 7), EndsAt: now.AddDate(0, 0, 21)}
+
+	scheduled := []linear.Issue{{Identifier: "SPR-1"}, {Identifier: "SPR-2", Children: []linear.Issue{
+		{Identifier: "SPR-3", Cycle: current},
+	}}}
+	if got := DefaultCycleFilter(scheduled, now); !got.Equal(CycleFilter{Current: true}) {
+		t.Errorf("expected the current cycle, got %s", got.Label())
+	}
+
+	unscheduled := []linear.Issue{{Identifier: "SPR-1"}, {Identifier: "SPR-4", Cycle: next}}
+	if got := DefaultCycleFilter(unscheduled, now); got.Active() {
+		t.Errorf("expected every cycle with no issue in the current one, got %s", got.Label())
+	}
+}
@@ -0,0 +1,398 @@
+package issues
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"sprout/pkg/config"
+	"sprout/pkg/github"
+	"sprout/pkg/linear"
+)
+
+// GitHub issues are either open or closed, so those are the only states they
+// move between
+var (
+	gitHubOpen   = linear.State{ID: "open", Name: "Open", Type: "unstarted"}
+	gitHubClosed = linear.State{ID: "closed", Name: "Closed", Type: "completed"}
+)
+
+// GitHubIssues is the issue tracker for GitHub Issues: the open issues
+// assigned to the user across their repositories, with sub-issues as
+// subtasks. Issues are identified as the repository's name and the issue's
+// number, such as WEB-42, so their branches are named like Linear's.
+type GitHubIssues struct {
+	api *restAPI
+
+	mu     sync.Mutex
+	login  string            // the user's login, once asked for
+	repos  map[string]string // identifier prefix to owner/repo, for issues seen so far
+	listed bool              // whether the assigned issues have been listed, naming their repositories
+}
+
+// NewGitHubIssues calls the REST API at endpoint with token
+func NewGitHubIssues(token, endpoint string, httpClient *http.Client) *GitHubIssues {
+	return &GitHubIssues{
+		api: &restAPI{
+			tracker:    "GitHub",
+			signIn:     "check GH_TOKEN or run sprout auth github again",
+			endpoint:   endpoint,
+			httpClient: httpClient,
+			sign: func(req *http.Request) {
+				req.Header.Set("Accept", "application/vnd.github+json")
+				req.Header.Set("Authorization", "Bearer "+token)
+				req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+			},
+		},
+		repos: make(map[string]string),
+	}
+}
+
+// gitHubTokens is where sprout auth github keeps its token
+var gitHubTokens = func() github.TokenStoreInterface { return &github.KeychainTokenStore{} }
+
+// newGitHubIssues signs in with the token PR status uses, leaving issues off
+// until there is one
+func newGitHubIssues(cfg *config.Config) (Repository, error) {
+	token, err := github.FindToken(gitHubTokens())
+	if err != nil || token == "" {
+		return nil, err
+	}
+	client := NewGitHubIssues(token, github.APIEndpoint, &http.Client{Timeout: cfg.NetworkTimeout()})
+	return NewCacheWithPath(client, "github", cfg.IssueCacheTTL(), cachePathFor(cfg)), nil
+}
+
+// gitHubIssue is the part of an issue the REST API returns that sprout shows
+type gitHubIssue struct {
+	ID            int64     `json:"id"`
+	Number        int       `json:"number"`
+	Title         string    `json:"title"`
+	Body          string    `json:"body"`
+	State         string    `json:"state"`
+	HTMLURL       string    `json:"html_url"`
+	RepositoryURL string    `json:"repository_url"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Assignee      *struct {
+		Login string `json:"login"`
+	} `json:"assignee"`
+	Labels []struct {
+		ID    int64  `json:"id"`
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	} `json:"labels"`
+	PullRequest      *struct{} `json:"pull_request"`
+	SubIssuesSummary struct {
+		Total int `json:"total"`
+	} `json:"sub_issues_summary"`
+}
+
+// repository is the owner/repo the issue is in
+func (i gitHubIssue) repository() string {
+	_, repo, _ := strings.Cut(i.RepositoryURL, "/repos/")
+	return repo
+}
+
+// gitHubIdentifierPrefix turns a repository's name into the letters and
+// digits that start its issues' identifiers
+func gitHubIdentifierPrefix(repository string) string {
+	name := repository[strings.LastIndex(repository, "/")+1:]
+	prefix := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, name)
+	if prefix == "" || !unicode.IsLetter(rune(prefix[0])) {
+		prefix = "GH" + prefix
+	}
+	return prefix
+}
+
+// issue converts i, remembering its repository so its identifier can be
+// looked up again
+func (g *GitHubIssues) issue(i gitHubIssue) linear.Issue {
+	repository := i.repository()
+	prefix := gitHubIdentifierPrefix(repository)
+	g.mu.Lock()
+	g.repos[prefix] = repository
+	g.mu.Unlock()
+
+	issue := linear.Issue{
+		ID:          fmt.Sprintf("%s#%d", repository, i.Number),
+		Identifier:  fmt.Sprintf("%s-%d", prefix, i.Number),
+		Title:       i.Title,
+		Description: i.Body,
+		State:       gitHubOpen,
+		URL:         i.HTMLURL,
+		CreatedAt:   i.CreatedAt,
+		UpdatedAt:   i.UpdatedAt,
+		Project:     &linear.Project{ID: repository, Name: repository},
+		HasChildren: i.SubIssuesSummary.Total > 0,
+	}
+	if i.State == "closed" {
+		issue.State = gitHubClosed
+	}
+	if i.Assignee != nil {
+		issue.Assignee = &linear.User{ID: i.Assignee.Login, Name: i.Assignee.Login, DisplayName: i.Assignee.Login}
+	}
+	for _, label := range i.Labels {
+		issue.Labels = append(issue.Labels, linear.Label{ID: strconv.FormatInt(label.ID, 10), Name: label.Name, Color: "#" + label.Color})
+	}
+	return issue
+}
+
+// issuePath is the API path of the issue with ID or identifier ref, such as
+// acme/web#42 or WEB-42
+func (g *GitHubIssues) issuePath(ref string) (string, error) {
+	repository, number, ok := strings.Cut(ref, "#")
+	if !ok {
+		prefix, n, found := strings.Cut(ref, "-")
+		repository, ok = g.repository(prefix)
+		if !ok && found && !g.hasListed() {
+			// The assigned issues name the repositories identifiers are from
+			if _, err := g.GetAssignedIssues(); err != nil {
+				return "", err
+			}
+			repository, ok = g.repository(prefix)
+		}
+		if !found || !ok {
+			return "", fmt.Errorf("GitHub issue %s isn't assigned to you; name it as owner/repo#number", ref)
+		}
+		number = n
+	}
+	if _, err := strconv.Atoi(number); err != nil || !strings.Contains(repository, "/") {
+		return "", fmt.Errorf("%q isn't a GitHub issue; name it as owner/repo#number", ref)
+	}
+	return "/repos/" + repository + "/issues/" + number, nil
+}
+
+func (g *GitHubIssues) hasListed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.listed
+}
+
+// repository is the owner/repo whose issues' identifiers start with prefix
+func (g *GitHubIssues) repository(prefix string) (string, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	repository, ok := g.repos[strings.ToUpper(prefix)]
+	return repository, ok
+}
+
+func (g *GitHubIssues) GetCurrentUser() (*linear.User, error) {
+	var user struct {
+		Login string `json:"login"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if err := g.api.call("GET", "/user", nil, &user); err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	g.login = user.Login
+	g.mu.Unlock()
+	name := user.Name
+	if name == "" {
+		name = user.Login
+	}
+	return &linear.User{ID: user.Login, Name: name, DisplayName: user.Login, Email: user.Email}, nil
+}
+
+// currentLogin is the user's login, asking for it the first time
+func (g *GitHubIssues) currentLogin() (string, error) {
+	g.mu.Lock()
+	login := g.login
+	g.mu.Unlock()
+	if login != "" {
+		return login, nil
+	}
+	user, err := g.GetCurrentUser()
+	if err != nil {
+		return "", err
+	}
+	return user.ID, nil
+}
+
+// GetAssignedIssues lists the open issues assigned to the user in every
+// repository they can see, leaving out pull requests
+func (g *GitHubIssues) GetAssignedIssues() ([]linear.Issue, error) {
+	var issues []linear.Issue
+	for page := 1; ; page++ {
+		query := url.Values{
+			"filter":   {"assigned"},
+			"state":    {"open"},
+			"sort":     {"updated"},
+			"per_page": {"100"},
+			"page":     {strconv.Itoa(page)},
+		}
+		var batch []gitHubIssue
+		if err := g.api.call("GET", "/issues?"+query.Encode(), nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, i := range batch {
+			if i.PullRequest == nil {
+				issues = append(issues, g.issue(i))
+			}
+		}
+		if len(batch) < 100 {
+			g.mu.Lock()
+			g.listed = true
+			g.mu.Unlock()
+			return issues, nil
+		}
+	}
+}
+
+func (g *GitHubIssues) GetIssueChildren(issueID string) ([]linear.Issue, error) {
+	path, err := g.issuePath(issueID)
+	if err != nil {
+		return nil, err
+	}
+	var batch []gitHubIssue
+	if err := g.api.call("GET", path+"/sub_issues?per_page=100", nil, &batch); err != nil {
+		return nil, err
+	}
+	children := make([]linear.Issue, 0, len(batch))
+	for _, i := range batch {
+		children = append(children, g.issue(i))
+	}
+	return children, nil
+}
+
+// GetChildrenOfIssues asks for each issue's sub-issues in turn, as the REST
+// API has no way to ask for several at once
+func (g *GitHubIssues) GetChildrenOfIssues(issueIDs []string) (map[string][]linear.Issue, error) {
+	children := make(map[string][]linear.Issue, len(issueIDs))
+	for _, id := range issueIDs {
+		issues, err := g.GetIssueChildren(id)
+		if err != nil {
+			return nil, err
+		}
+		children[id] = issues
+	}
+	return children, nil
+}
+
+// GetIssue looks up an issue by ID or identifier, nil when there's no such
+// issue
+func (g *GitHubIssues) GetIssue(identifier string) (*linear.Issue, error) {
+	path, err := g.issuePath(identifier)
+	if err != nil {
+		return nil, err
+	}
+	var found gitHubIssue
+	if err := g.api.call("GET", path, nil, &found); err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	issue := g.issue(found)
+	return &issue, nil
+}
+
+func (g *GitHubIssues) CreateSubtask(parentID, title string) (*linear.Issue, error) {
+	return g.CreateSubtaskWithOptions(parentID, title, linear.SubtaskOptions{})
+}
+
+// CreateSubtaskWithOptions opens an issue assigned to the user in the
+// parent's repository and adds it as the parent's sub-issue. GitHub issues
+// have no priority or estimate, so those options are left out.
+func (g *GitHubIssues) CreateSubtaskWithOptions(parentID, title string, opts linear.SubtaskOptions) (*linear.Issue, error) {
+	parentPath, err := g.issuePath(parentID)
+	if err != nil {
+		return nil, err
+	}
+	login, err := g.currentLogin()
+	if err != nil {
+		return nil, err
+	}
+	var created gitHubIssue
+	request := map[string]any{"title": title, "body": opts.Description, "assignees": []string{login}}
+	if err := g.api.call("POST", parentPath[:strings.LastIndex(parentPath, "/")], request, &created); err != nil {
+		return nil, fmt.Errorf("failed to create subtask: %w", err)
+	}
+	if err := g.api.call("POST", parentPath+"/sub_issues", map[string]any{"sub_issue_id": created.ID}, nil); err != nil {
+		return nil, fmt.Errorf("created %s but failed to add it as a sub-issue: %w", created.HTMLURL, err)
+	}
+	issue := g.issue(created)
+	return &issue, nil
+}
+
+func (g *GitHubIssues) UnassignIssue(issueID string) error {
+	return g.assignees("DELETE", issueID)
+}
+
+func (g *GitHubIssues) AssignIssueToMe(issueID string) error {
+	return g.assignees("POST", issueID)
+}
+
+// assignees adds the user to or removes them from the issue's assignees
+func (g *GitHubIssues) assignees(method, issueID string) error {
+	path, err := g.issuePath(issueID)
+	if err != nil {
+		return err
+	}
+	login, err := g.currentLogin()
+	if err != nil {
+		return err
+	}
+	return g.api.call(method, path+"/assignees", map[string]any{"assignees": []string{login}}, nil)
+}
+
+func (g *GitHubIssues) MarkIssueDone(issueID string) error {
+	return g.UpdateIssueState(issueID, gitHubClosed.ID)
+}
+
+func (g *GitHubIssues) GetWorkflowStates(issueID string) ([]linear.State, error) {
+	return []linear.State{gitHubOpen, gitHubClosed}, nil
+}
+
+// UpdateIssueState opens or closes the issue, closing it as completed
+func (g *GitHubIssues) UpdateIssueState(issueID, stateID string) error {
+	path, err := g.issuePath(issueID)
+	if err != nil {
+		return err
+	}
+	request := map[string]any{"state": stateID}
+	switch stateID {
+	case gitHubClosed.ID:
+		request["state_reason"] = "completed"
+	case gitHubOpen.ID:
+	default:
+		return fmt.Errorf("GitHub issues can only be open or closed, not %q", stateID)
+	}
+	return g.api.call("PATCH", path, request, nil)
+}
+
+// GetIssueStates looks up each issue in turn, leaving out ones that are gone
+// or from repositories none of the user's issues are in
+func (g *GitHubIssues) GetIssueStates(identifiers []string) (map[string]linear.State, error) {
+	states := make(map[string]linear.State, len(identifiers))
+	for _, identifier := range identifiers {
+		if _, err := g.issuePath(identifier); err != nil {
+			continue
+		}
+		issue, err := g.GetIssue(identifier)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			states[identifier] = issue.State
+		}
+	}
+	return states, nil
+}
+
+func (g *GitHubIssues) TestConnection() error {
+	_, err := g.GetCurrentUser()
+	return err
+}
@@ -0,0 +1,161 @@
+package issues
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/github"
+)
+
+// fakeGitHubIssues serves acme/web's issue 42, with sub-issue 43, and a pull
+// request assigned to the user, logging each request's method, path and body
+func fakeGitHubIssues(t *testing.T) (*GitHubIssues, *[]string) {
+	var requests []string
+	issue := func(number int, title, state string, subIssues int) map[string]any {
+		return map[string]any{
+			"id": 1000 + number, "number": number, "title": title, "state": state,
+			"html_url":           fmt.Sprintf("https://github.com/acme/web/issues/%d", number),
+			"repository_url":     "https://api.github.com/repos/acme/web",
+			"updated_at":         "2026-10-01T10:00:00Z",
+			"labels":             []map[string]any{{"id": 7, "name": "bug", "color": "d73a4a"}},
+			"sub_issues_summary": map[string]any{"total": subIssues},
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		encoded, _ := json.Marshal(body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(encoded))
+		if r.Header.Get("Authorization") != "Bearer ghp_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /user":
+			w.Write([]byte(`{"login": "octocat", "name": "Mona"}`))
+		case "GET /issues":
+			pr := issue(12, "Bump deps", "open", 0)
+			pr["pull_request"] = map[string]any{}
+			json.NewEncoder(w).Encode([]any{issue(42, "Fix the login form", "open", 1), pr})
+		case "GET /repos/acme/web/issues/42":
+			json.NewEncoder(w).Encode(issue(42, "Fix the login form", "open", 1))
+		case "GET /repos/acme/web/issues/42/sub_issues":
+			json.NewEncoder(w).Encode([]any{issue(43, "Add a test", "closed", 0)})
+		case "POST /repos/acme/web/issues":
+			json.NewEncoder(w).Encode(issue(44, body["title"].(string), "open", 0))
+		case "POST /repos/acme/web/issues/42/sub_issues", "PATCH /repos/acme/web/issues/42", "DELETE /repos/acme/web/issues/42/assignees":
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return NewGitHubIssues("ghp_test", server.URL, server.Client()), &requests
+}
+
+func TestGitHubIssuesListsAssignedIssuesWithSubIssues(t *testing.T) {
+	client, _ := fakeGitHubIssues(t)
+
+	issues, err := client.GetAssignedIssues()
+	if err != nil {
+		t.Fatalf("GetAssignedIssues failed: %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("expected the pull request left out, got %+v", issues)
+	}
+	issue := issues[0]
+	if issue.ID != "acme/web#42" || issue.Identifier != "WEB-42" || issue.Title != "Fix the login form" || issue.State.Type != "unstarted" || !issue.HasChildren {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	if len(issue.Labels) != 1 || issue.Labels[0].Name != "bug" || issue.Labels[0].Color != "#d73a4a" {
+		t.Fatalf("expected the bug label, got %+v", issue.Labels)
+	}
+	if branch := issue.GetBranchName(); branch != "web-42-fix-the-login-form" {
+		t.Fatalf("expected a branch named like Linear's, got %q", branch)
+	}
+
+	children, err := client.GetIssueChildren(issue.ID)
+	if err != nil || len(children) != 1 || children[0].Identifier != "WEB-43" || children[0].State.Type != "completed" {
+		t.Fatalf("expected the closed sub-issue, got %+v, %v", children, err)
+	}
+}
+
+func TestGitHubIssuesLooksUpIdentifiersFromAFreshStart(t *testing.T) {
+	client, _ := fakeGitHubIssues(t)
+
+	issue, err := client.GetIssue("web-42")
+	if err != nil || issue == nil || issue.Title != "Fix the login form" {
+		t.Fatalf("expected WEB-42 found through the assigned issues, got %+v, %v", issue, err)
+	}
+	states, err := client.GetIssueStates([]string{"WEB-42", "ENG-7"})
+	if err != nil || len(states) != 1 || states["WEB-42"].ID != "open" {
+		t.Fatalf("expected only WEB-42's state, got %v, %v", states, err)
+	}
+}
+
+func TestGitHubIssuesCreatesSubIssuesAndClosesIssues(t *testing.T) {
+	client, requests := fakeGitHubIssues(t)
+
+	created, err := client.CreateSubtask("acme/web#42", "Write docs")
+	if err != nil || created.Identifier != "WEB-44" || created.Title != "Write docs" {
+		t.Fatalf("expected WEB-44 created, got %+v, %v", created, err)
+	}
+	if err := client.MarkIssueDone("acme/web#42"); err != nil {
+		t.Fatalf("MarkIssueDone failed: %v", err)
+	}
+	if err := client.UnassignIssue("acme/web#42"); err != nil {
+		t.Fatalf("UnassignIssue failed: %v", err)
+	}
+	if err := client.UpdateIssueState("acme/web#42", "in_review"); err == nil {
+		t.Fatal("expected a state GitHub doesn't have to be refused")
+	}
+
+	log := strings.Join(*requests, "\n")
+	for _, want := range []string{
+		`POST /repos/acme/web/issues {"assignees":["octocat"],"body":"","title":"Write docs"}`,
+		`POST /repos/acme/web/issues/42/sub_issues {"sub_issue_id":1044}`,
+		`PATCH /repos/acme/web/issues/42 {"state":"closed","state_reason":"completed"}`,
+		`DELETE /repos/acme/web/issues/42/assignees {"assignees":["octocat"]}`,
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("expected %s, got:\n%s", want, log)
+		}
+	}
+}
+
+func TestNewSelectsGitHubIssuesOnceSignedIn(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	store := &fakeTokenStore{}
+	gitHubTokens = func() github.TokenStoreInterface { return store }
+	defer func() { gitHubTokens = func() github.TokenStoreInterface { return &github.KeychainTokenStore{} } }()
+	cfg := &config.Config{IssueProvider: "github"}
+
+	client, err := New(cfg)
+	if err != nil || client != nil {
+		t.Fatalf("expected no client before signing in, got %v, %v", client, err)
+	}
+
+	store.token = "ghp_test"
+	client, err = New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if cached, ok := client.(*Cache); !ok {
+		t.Fatalf("expected a cached client, got %T", client)
+	} else if _, ok := cached.LinearClientInterface.(*GitHubIssues); !ok {
+		t.Fatalf("expected GitHub issues, got %T", cached.LinearClientInterface)
+	}
+}
+
+// fakeTokenStore keeps a GitHub token in memory
+type fakeTokenStore struct{ token string }
+
+func (s *fakeTokenStore) Token() (string, error)       { return s.token, nil }
+func (s *fakeTokenStore) SaveToken(token string) error { s.token = token; return nil }
+func (s *fakeTokenStore) DeleteToken() error           { s.token = ""; return nil }
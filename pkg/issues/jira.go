@@ -0,0 +1,452 @@
+package issues
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"sprout/pkg/config"
+	"sprout/pkg/linear"
+)
+
+// jiraFields are the fields sprout asks Jira for with each issue
+const jiraFields = "summary,description,status,priority,labels,project,parent,subtasks,assignee,created,updated"
+
+// Jira's status categories, as the state types sprout sorts and colours by
+var jiraStateTypes = map[string]string{
+	"new":           "unstarted",
+	"indeterminate": "started",
+	"done":          "completed",
+}
+
+// Jira's default priorities, as Linear's 1 urgent to 4 low
+var jiraPriorities = map[string]int{
+	"Highest": 1,
+	"High":    2,
+	"Medium":  3,
+	"Low":     4,
+	"Lowest":  4,
+}
+
+// JiraIssues is the issue tracker for Jira Cloud: the unfinished issues
+// assigned to the user, with Jira's subtasks as subtasks and its statuses as
+// states, moved between with the issue's transitions
+type JiraIssues struct {
+	api     *restAPI
+	baseURL string
+
+	mu        sync.Mutex
+	accountID string // the user's account, once asked for
+}
+
+// NewJiraIssues signs in to the Jira site at baseURL with an Atlassian
+// account's email and API token
+func NewJiraIssues(baseURL, email, token string, httpClient *http.Client) *JiraIssues {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &JiraIssues{
+		api: &restAPI{
+			tracker:    "Jira",
+			signIn:     "check JIRA_EMAIL and JIRA_API_TOKEN",
+			endpoint:   baseURL + "/rest/api/2",
+			httpClient: httpClient,
+			sign:       func(req *http.Request) { req.SetBasicAuth(email, token) },
+		},
+		baseURL: baseURL,
+	}
+}
+
+// jiraGetenv reads the variables Jira signs in with, the same ones issue
+// links use
+var jiraGetenv = os.Getenv
+
+// newJiraIssues signs in with JIRA_BASE_URL, JIRA_EMAIL and JIRA_API_TOKEN,
+// leaving issues off until they're all set
+func newJiraIssues(cfg *config.Config) (Repository, error) {
+	baseURL := strings.TrimSpace(jiraGetenv("JIRA_BASE_URL"))
+	email := strings.TrimSpace(jiraGetenv("JIRA_EMAIL"))
+	token := strings.TrimSpace(jiraGetenv("JIRA_API_TOKEN"))
+	if baseURL == "" || email == "" || token == "" {
+		return nil, nil
+	}
+	if parsed, err := url.Parse(baseURL); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("JIRA_BASE_URL must be an https URL, such as https://acme.atlassian.net, not %q", baseURL)
+	}
+	client := NewJiraIssues(baseURL, email, token, &http.Client{Timeout: cfg.NetworkTimeout()})
+	return NewCacheWithPath(client, "jira", cfg.IssueCacheTTL(), cachePathFor(cfg)), nil
+}
+
+// jiraTime reads Jira's timestamps, which leave the colon out of the zone
+type jiraTime struct{ time.Time }
+
+func (t *jiraTime) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		return nil
+	}
+	parsed, err := time.Parse("2006-01-02T15:04:05.000-0700", value)
+	if err != nil {
+		parsed, err = time.Parse(time.RFC3339, value)
+	}
+	t.Time = parsed
+	return err
+}
+
+// jiraStatus is an issue's status, or where a transition leads
+type jiraStatus struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	StatusCategory struct {
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+func (s jiraStatus) state() linear.State {
+	return linear.State{ID: s.ID, Name: s.Name, Type: jiraStateTypes[s.StatusCategory.Key]}
+}
+
+// jiraIssue is the part of an issue the REST API returns that sprout shows
+type jiraIssue struct {
+	ID     string `json:"id"`
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string     `json:"summary"`
+		Description string     `json:"description"`
+		Status      jiraStatus `json:"status"`
+		Priority    *struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+		Labels  []string `json:"labels"`
+		Project *struct {
+			ID   string `json:"id"`
+			Key  string `json:"key"`
+			Name string `json:"name"`
+		} `json:"project"`
+		Parent *struct {
+			Key string `json:"key"`
+		} `json:"parent"`
+		Subtasks []struct {
+			Key string `json:"key"`
+		} `json:"subtasks"`
+		Assignee *struct {
+			AccountID    string `json:"accountId"`
+			DisplayName  string `json:"displayName"`
+			EmailAddress string `json:"emailAddress"`
+		} `json:"assignee"`
+		Created jiraTime `json:"created"`
+		Updated jiraTime `json:"updated"`
+	} `json:"fields"`
+}
+
+func (j *JiraIssues) issue(i jiraIssue) linear.Issue {
+	issue := linear.Issue{
+		ID:          i.Key,
+		Identifier:  i.Key,
+		Title:       i.Fields.Summary,
+		Description: i.Fields.Description,
+		State:       i.Fields.Status.state(),
+		URL:         j.baseURL + "/browse/" + i.Key,
+		CreatedAt:   i.Fields.Created.Time,
+		UpdatedAt:   i.Fields.Updated.Time,
+		HasChildren: len(i.Fields.Subtasks) > 0,
+	}
+	if i.Fields.Priority != nil {
+		issue.Priority = jiraPriorities[i.Fields.Priority.Name]
+	}
+	if i.Fields.Project != nil {
+		issue.Project = &linear.Project{ID: i.Fields.Project.Key, Name: i.Fields.Project.Name}
+	}
+	if i.Fields.Parent != nil {
+		issue.Parent = &linear.Issue{ID: i.Fields.Parent.Key, Identifier: i.Fields.Parent.Key}
+	}
+	if assignee := i.Fields.Assignee; assignee != nil {
+		issue.Assignee = &linear.User{ID: assignee.AccountID, Name: assignee.DisplayName, DisplayName: assignee.DisplayName, Email: assignee.EmailAddress}
+	}
+	for _, label := range i.Fields.Labels {
+		issue.Labels = append(issue.Labels, linear.Label{ID: label, Name: label})
+	}
+	return issue
+}
+
+// search lists every issue jql matches, a page at a time
+func (j *JiraIssues) search(jql string) ([]jiraIssue, error) {
+	var issues []jiraIssue
+	pageToken := ""
+	for {
+		query := url.Values{"jql": {jql}, "fields": {jiraFields}, "maxResults": {"100"}}
+		if pageToken != "" {
+			query.Set("nextPageToken", pageToken)
+		}
+		var page struct {
+			Issues        []jiraIssue `json:"issues"`
+			NextPageToken string      `json:"nextPageToken"`
+			IsLast        bool        `json:"isLast"`
+		}
+		if err := j.api.call("GET", "/search/jql?"+query.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		issues = append(issues, page.Issues...)
+		if page.IsLast || page.NextPageToken == "" {
+			return issues, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// jqlKeys quotes issue keys for a JQL list
+func jqlKeys(keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key) + `"`
+	}
+	return "(" + strings.Join(quoted, ", ") + ")"
+}
+
+func (j *JiraIssues) GetCurrentUser() (*linear.User, error) {
+	var user struct {
+		AccountID    string `json:"accountId"`
+		DisplayName  string `json:"displayName"`
+		EmailAddress string `json:"emailAddress"`
+	}
+	if err := j.api.call("GET", "/myself", nil, &user); err != nil {
+		return nil, err
+	}
+	j.mu.Lock()
+	j.accountID = user.AccountID
+	j.mu.Unlock()
+	return &linear.User{ID: user.AccountID, Name: user.DisplayName, DisplayName: user.DisplayName, Email: user.EmailAddress}, nil
+}
+
+// currentAccount is the user's account ID, asking for it the first time
+func (j *JiraIssues) currentAccount() (string, error) {
+	j.mu.Lock()
+	accountID := j.accountID
+	j.mu.Unlock()
+	if accountID != "" {
+		return accountID, nil
+	}
+	user, err := j.GetCurrentUser()
+	if err != nil {
+		return "", err
+	}
+	return user.ID, nil
+}
+
+// GetAssignedIssues lists the issues assigned to the user that aren't done,
+// most recently updated first
+func (j *JiraIssues) GetAssignedIssues() ([]linear.Issue, error) {
+	found, err := j.search("assignee = currentUser() AND statusCategory != Done ORDER BY updated DESC")
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]linear.Issue, 0, len(found))
+	for _, i := range found {
+		issues = append(issues, j.issue(i))
+	}
+	return issues, nil
+}
+
+func (j *JiraIssues) GetIssueChildren(issueID string) ([]linear.Issue, error) {
+	children, err := j.GetChildrenOfIssues([]string{issueID})
+	if err != nil {
+		return nil, err
+	}
+	return children[issueID], nil
+}
+
+// GetChildrenOfIssues finds the subtasks of all of issueIDs in one search
+func (j *JiraIssues) GetChildrenOfIssues(issueIDs []string) (map[string][]linear.Issue, error) {
+	children := make(map[string][]linear.Issue, len(issueIDs))
+	if len(issueIDs) == 0 {
+		return children, nil
+	}
+	found, err := j.search("parent in " + jqlKeys(issueIDs) + " ORDER BY created ASC")
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range issueIDs {
+		children[id] = []linear.Issue{}
+	}
+	for _, i := range found {
+		if i.Fields.Parent != nil {
+			children[i.Fields.Parent.Key] = append(children[i.Fields.Parent.Key], j.issue(i))
+		}
+	}
+	return children, nil
+}
+
+// GetIssue looks up an issue by key, nil when there's no such issue
+func (j *JiraIssues) GetIssue(identifier string) (*linear.Issue, error) {
+	var found jiraIssue
+	if err := j.api.call("GET", "/issue/"+url.PathEscape(identifier)+"?fields="+jiraFields, nil, &found); err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	issue := j.issue(found)
+	return &issue, nil
+}
+
+func (j *JiraIssues) CreateSubtask(parentID, title string) (*linear.Issue, error) {
+	return j.CreateSubtaskWithOptions(parentID, title, linear.SubtaskOptions{})
+}
+
+// CreateSubtaskWithOptions creates a subtask assigned to the user under the
+// parent, in the parent's project. Jira has no estimate field everywhere, so
+// that option is left out.
+func (j *JiraIssues) CreateSubtaskWithOptions(parentID, title string, opts linear.SubtaskOptions) (*linear.Issue, error) {
+	parent, err := j.GetIssue(parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil || parent.Project == nil {
+		return nil, fmt.Errorf("Jira issue %s not found", parentID)
+	}
+	issueType, err := j.subtaskType(parent.Project.ID)
+	if err != nil {
+		return nil, err
+	}
+	accountID, err := j.currentAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{
+		"project":   map[string]string{"key": parent.Project.ID},
+		"parent":    map[string]string{"key": parent.Identifier},
+		"issuetype": map[string]string{"id": issueType},
+		"summary":   title,
+		"assignee":  map[string]string{"accountId": accountID},
+	}
+	if opts.Description != "" {
+		fields["description"] = opts.Description
+	}
+	for name, priority := range jiraPriorities {
+		if priority == opts.Priority && name != "Lowest" {
+			fields["priority"] = map[string]string{"name": name}
+		}
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := j.api.call("POST", "/issue", map[string]any{"fields": fields}, &created); err != nil {
+		return nil, fmt.Errorf("failed to create subtask: %w", err)
+	}
+	issue, err := j.GetIssue(created.Key)
+	if err != nil || issue == nil {
+		return &linear.Issue{ID: created.Key, Identifier: created.Key, Title: title, URL: j.baseURL + "/browse/" + created.Key}, nil
+	}
+	return issue, nil
+}
+
+// subtaskType is the ID of the issue type subtasks are made with in project,
+// named Sub-task or Subtask depending on the kind of project
+func (j *JiraIssues) subtaskType(project string) (string, error) {
+	var meta struct {
+		IssueTypes []struct {
+			ID      string `json:"id"`
+			Subtask bool   `json:"subtask"`
+		} `json:"issueTypes"`
+	}
+	if err := j.api.call("GET", "/issue/createmeta/"+url.PathEscape(project)+"/issuetypes", nil, &meta); err != nil {
+		return "", err
+	}
+	for _, issueType := range meta.IssueTypes {
+		if issueType.Subtask {
+			return issueType.ID, nil
+		}
+	}
+	return "", fmt.Errorf("Jira project %s has no subtask issue type", project)
+}
+
+func (j *JiraIssues) UnassignIssue(issueID string) error {
+	return j.api.call("PUT", "/issue/"+url.PathEscape(issueID)+"/assignee", map[string]any{"accountId": nil}, nil)
+}
+
+func (j *JiraIssues) AssignIssueToMe(issueID string) error {
+	accountID, err := j.currentAccount()
+	if err != nil {
+		return err
+	}
+	return j.api.call("PUT", "/issue/"+url.PathEscape(issueID)+"/assignee", map[string]any{"accountId": accountID}, nil)
+}
+
+// jiraTransition moves an issue from its status to another
+type jiraTransition struct {
+	ID string     `json:"id"`
+	To jiraStatus `json:"to"`
+}
+
+func (j *JiraIssues) transitions(issueID string) ([]jiraTransition, error) {
+	var result struct {
+		Transitions []jiraTransition `json:"transitions"`
+	}
+	if err := j.api.call("GET", "/issue/"+url.PathEscape(issueID)+"/transitions", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Transitions, nil
+}
+
+// transition moves the issue along the first transition match accepts
+func (j *JiraIssues) transition(issueID string, match func(jiraStatus) bool) error {
+	transitions, err := j.transitions(issueID)
+	if err != nil {
+		return err
+	}
+	for _, t := range transitions {
+		if match(t.To) {
+			return j.api.call("POST", "/issue/"+url.PathEscape(issueID)+"/transitions", map[string]any{"transition": map[string]string{"id": t.ID}}, nil)
+		}
+	}
+	return fmt.Errorf("Jira has no transition for %s to that status from where it is", issueID)
+}
+
+// MarkIssueDone moves the issue along the first transition to a done status
+func (j *JiraIssues) MarkIssueDone(issueID string) error {
+	return j.transition(issueID, func(to jiraStatus) bool { return to.StatusCategory.Key == "done" })
+}
+
+// GetWorkflowStates lists the statuses the issue's transitions lead to
+func (j *JiraIssues) GetWorkflowStates(issueID string) ([]linear.State, error) {
+	transitions, err := j.transitions(issueID)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]linear.State, 0, len(transitions))
+	for _, t := range transitions {
+		states = append(states, t.To.state())
+	}
+	return states, nil
+}
+
+// UpdateIssueState moves the issue to the status with stateID, along the
+// transition that leads there
+func (j *JiraIssues) UpdateIssueState(issueID, stateID string) error {
+	return j.transition(issueID, func(to jiraStatus) bool { return to.ID == stateID })
+}
+
+// GetIssueStates looks up each issue in turn, leaving out ones that are gone;
+// one search would be turned away whole for a single key Jira doesn't have
+func (j *JiraIssues) GetIssueStates(identifiers []string) (map[string]linear.State, error) {
+	states := make(map[string]linear.State, len(identifiers))
+	for _, identifier := range identifiers {
+		issue, err := j.GetIssue(identifier)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			states[identifier] = issue.State
+		}
+	}
+	return states, nil
+}
+
+func (j *JiraIssues) TestConnection() error {
+	_, err := j.GetCurrentUser()
+	return err
+}
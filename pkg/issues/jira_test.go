@@ -0,0 +1,184 @@
+package issues
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/linear"
+)
+
+// fakeJira serves PROJ-12, with subtask PROJ-13, as assigned to the user,
+// logging each request's method, path and body
+func fakeJira(t *testing.T) (*JiraIssues, *[]string) {
+	var requests []string
+	issue := func(key, summary, status, category string, parent string) map[string]any {
+		fields := map[string]any{
+			"summary":  summary,
+			"status":   map[string]any{"id": status, "name": map[string]string{"1": "To Do", "3": "In Progress", "4": "Done"}[status], "statusCategory": map[string]string{"key": category}},
+			"priority": map[string]string{"name": "High"},
+			"labels":   []string{"backend"},
+			"project":  map[string]string{"id": "100", "key": "PROJ", "name": "Project"},
+			"updated":  "2026-10-01T10:00:00.000+0100",
+		}
+		if parent != "" {
+			fields["parent"] = map[string]string{"key": parent}
+		} else {
+			fields["subtasks"] = []map[string]string{{"key": "PROJ-13"}}
+		}
+		return map[string]any{"id": "1" + key[5:], "key": key, "fields": fields}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		encoded, _ := json.Marshal(body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(encoded))
+		if user, token, _ := r.BasicAuth(); user != "me@example.com" || token != "jira_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /rest/api/2/myself":
+			w.Write([]byte(`{"accountId": "acc-1", "displayName": "Mona"}`))
+		case "GET /rest/api/2/search/jql":
+			var found []any
+			switch jql := r.URL.Query().Get("jql"); {
+			case strings.HasPrefix(jql, "assignee = currentUser()"):
+				found = []any{issue("PROJ-12", "Audit log", "3", "indeterminate", "")}
+			case strings.HasPrefix(jql, `parent in ("PROJ-12")`):
+				found = []any{issue("PROJ-13", "Write the migration", "1", "new", "PROJ-12")}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"issues": found, "isLast": true})
+		case "GET /rest/api/2/issue/PROJ-12":
+			json.NewEncoder(w).Encode(issue("PROJ-12", "Audit log", "3", "indeterminate", ""))
+		case "GET /rest/api/2/issue/PROJ-14":
+			json.NewEncoder(w).Encode(issue("PROJ-14", "Add an index", "1", "new", "PROJ-12"))
+		case "GET /rest/api/2/issue/createmeta/PROJ/issuetypes":
+			w.Write([]byte(`{"issueTypes": [{"id": "10001", "subtask": false}, {"id": "10003", "subtask": true}]}`))
+		case "POST /rest/api/2/issue":
+			w.Write([]byte(`{"id": "1014", "key": "PROJ-14"}`))
+		case "GET /rest/api/2/issue/PROJ-12/transitions":
+			w.Write([]byte(`{"transitions": [
+				{"id": "11", "to": {"id": "1", "name": "To Do", "statusCategory": {"key": "new"}}},
+				{"id": "31", "to": {"id": "4", "name": "Done", "statusCategory": {"key": "done"}}}
+			]}`))
+		case "POST /rest/api/2/issue/PROJ-12/transitions", "PUT /rest/api/2/issue/PROJ-12/assignee":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorMessages": ["Issue does not exist or you do not have permission to see it."]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return NewJiraIssues(server.URL, "me@example.com", "jira_test", server.Client()), &requests
+}
+
+func TestJiraIssuesListsAssignedIssuesWithSubtasks(t *testing.T) {
+	client, _ := fakeJira(t)
+
+	issues, err := client.GetAssignedIssues()
+	if err != nil {
+		t.Fatalf("GetAssignedIssues failed: %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("expected PROJ-12, got %+v", issues)
+	}
+	issue := issues[0]
+	if issue.Identifier != "PROJ-12" || issue.Title != "Audit log" || issue.State.Type != "started" || issue.State.Name != "In Progress" || issue.Priority != 2 || !issue.HasChildren {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	if issue.URL != client.baseURL+"/browse/PROJ-12" || issue.UpdatedAt.IsZero() || len(issue.Labels) != 1 {
+		t.Fatalf("expected the link, update time and label, got %+v", issue)
+	}
+
+	children, err := client.GetChildrenOfIssues([]string{"PROJ-12"})
+	if err != nil || len(children["PROJ-12"]) != 1 || children["PROJ-12"][0].Identifier != "PROJ-13" {
+		t.Fatalf("expected PROJ-13 under PROJ-12, got %+v, %v", children, err)
+	}
+
+	states, err := client.GetIssueStates([]string{"PROJ-12", "ENG-7"})
+	if err != nil || len(states) != 1 || states["PROJ-12"].ID != "3" {
+		t.Fatalf("expected only PROJ-12's state, got %v, %v", states, err)
+	}
+}
+
+func TestJiraIssuesMovesIssuesAlongTheirTransitions(t *testing.T) {
+	client, requests := fakeJira(t)
+
+	states, err := client.GetWorkflowStates("PROJ-12")
+	if err != nil || len(states) != 2 || states[1].ID != "4" || states[1].Type != "completed" {
+		t.Fatalf("expected the statuses the transitions lead to, got %+v, %v", states, err)
+	}
+	if err := client.UpdateIssueState("PROJ-12", "1"); err != nil {
+		t.Fatalf("UpdateIssueState failed: %v", err)
+	}
+	if err := client.MarkIssueDone("PROJ-12"); err != nil {
+		t.Fatalf("MarkIssueDone failed: %v", err)
+	}
+	if err := client.UpdateIssueState("PROJ-12", "99"); err == nil {
+		t.Fatal("expected a status with no transition to be refused")
+	}
+	if err := client.UnassignIssue("PROJ-12"); err != nil {
+		t.Fatalf("UnassignIssue failed: %v", err)
+	}
+
+	log := strings.Join(*requests, "\n")
+	for _, want := range []string{
+		`POST /rest/api/2/issue/PROJ-12/transitions {"transition":{"id":"11"}}`,
+		`POST /rest/api/2/issue/PROJ-12/transitions {"transition":{"id":"31"}}`,
+		`PUT /rest/api/2/issue/PROJ-12/assignee {"accountId":null}`,
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("expected %s, got:\n%s", want, log)
+		}
+	}
+}
+
+func TestJiraIssuesCreatesSubtasksInTheParentsProject(t *testing.T) {
+	client, requests := fakeJira(t)
+
+	created, err := client.CreateSubtaskWithOptions("PROJ-12", "Add an index", linear.SubtaskOptions{Priority: 1})
+	if err != nil || created.Identifier != "PROJ-14" || created.Title != "Add an index" {
+		t.Fatalf("expected PROJ-14 created, got %+v, %v", created, err)
+	}
+	want := `POST /rest/api/2/issue {"fields":{"assignee":{"accountId":"acc-1"},"issuetype":{"id":"10003"},"parent":{"key":"PROJ-12"},"priority":{"name":"Highest"},"project":{"key":"PROJ"},"summary":"Add an index"}}`
+	if log := strings.Join(*requests, "\n"); !strings.Contains(log, want) {
+		t.Fatalf("expected %s, got:\n%s", want, log)
+	}
+
+	if issue, err := client.GetIssue("PROJ-99"); err != nil || issue != nil {
+		t.Fatalf("expected no issue for a missing key, got %+v, %v", issue, err)
+	}
+}
+
+func TestNewSelectsJiraIssuesOnceConfigured(t *testing.T) {
+	env := map[string]string{}
+	jiraGetenv = func(name string) string { return env[name] }
+	defer func() { jiraGetenv = os.Getenv }()
+	cfg := &config.Config{IssueProvider: "jira"}
+
+	client, err := New(cfg)
+	if err != nil || client != nil {
+		t.Fatalf("expected no client before JIRA_API_TOKEN is set, got %v, %v", client, err)
+	}
+
+	env["JIRA_BASE_URL"], env["JIRA_EMAIL"], env["JIRA_API_TOKEN"] = "http://jira.example.com", "me@example.com", "jira_test"
+	if _, err := New(cfg); err == nil || !strings.Contains(err.Error(), "must be an https URL") {
+		t.Fatalf("expected the token kept off plain http, got %v", err)
+	}
+
+	env["JIRA_BASE_URL"] = "https://acme.atlassian.net"
+	client, err = New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if cached, ok := client.(*Cache); !ok {
+		t.Fatalf("expected a cached client, got %T", client)
+	} else if _, ok := cached.LinearClientInterface.(*JiraIssues); !ok {
+		t.Fatalf("expected Jira issues, got %T", cached.LinearClientInterface)
+	}
+}
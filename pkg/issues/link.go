@@ -0,0 +1,240 @@
+package issues
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"sprout/pkg/github"
+)
+
+// Trackers a Link can point at
+const (
+	LinkLinear = "linear"
+	LinkGitHub = "github"
+	LinkGitLab = "gitlab"
+	LinkJira   = "jira"
+)
+
+// Link is an issue pasted as the URL its tracker shows it at
+type Link struct {
+	Tracker    string // LinkLinear, LinkGitHub, LinkGitLab or LinkJira
+	Identifier string // ENG-123 on Linear and Jira, the issue number on GitHub and GitLab
+	Project    string // owner/repo on GitHub, the project's path on GitLab
+	Slug       string // the title as Linear puts it in the URL, when it does
+	URL        *url.URL
+}
+
+var (
+	linkIdentifierPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*-[0-9]+$`)
+	linkNumberPattern     = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// ParseLink reads an https issue URL copied from Linear, GitHub, GitLab or
+// Jira, such as https://linear.app/acme/issue/ENG-123/fix-login. GitLab and
+// Jira are recognised on any host, for self-hosted ones, though they're only
+// signed in to on the hosts configured for them. It's false for anything
+// else, which is taken as a branch name
+func ParseLink(raw string) (Link, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return Link{}, false
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	link := Link{URL: parsed}
+
+	switch {
+	case host == "linear.app" && len(parts) >= 3 && parts[1] == "issue" && linkIdentifierPattern.MatchString(parts[2]):
+		link.Tracker, link.Identifier = LinkLinear, strings.ToUpper(parts[2])
+		if len(parts) >= 4 {
+			link.Slug = parts[3]
+		}
+		return link, true
+	case host == "github.com" && len(parts) >= 4 && parts[2] == "issues" && linkNumberPattern.MatchString(parts[3]):
+		link.Tracker, link.Project, link.Identifier = LinkGitHub, parts[0]+"/"+parts[1], parts[3]
+		return link, true
+	}
+	for i := 1; i+2 < len(parts); i++ {
+		if parts[i] == "-" && (parts[i+1] == "issues" || parts[i+1] == "work_items") && linkNumberPattern.MatchString(parts[i+2]) {
+			link.Tracker, link.Project, link.Identifier = LinkGitLab, strings.Join(parts[:i], "/"), parts[i+2]
+			return link, true
+		}
+	}
+	// Jira shows an issue at /browse/KEY-1
+	if len(parts) == 2 && parts[0] == "browse" && linkIdentifierPattern.MatchString(parts[1]) {
+		link.Tracker, link.Identifier = LinkJira, strings.ToUpper(parts[1])
+		return link, true
+	}
+	return Link{}, false
+}
+
+// SlugTitle is the title the link's URL carries, for when it can't be
+// fetched
+func (l Link) SlugTitle() string {
+	return strings.ReplaceAll(l.Slug, "-", " ")
+}
+
+// TitleFetcher looks up the title of the issue a link points at
+type TitleFetcher interface {
+	Title(link Link) (string, error)
+}
+
+// LinkTitles fetches the titles of issues on GitHub, GitLab and Jira, so a
+// link can name a branch as an issue from the issue tracker would. Linear's
+// come from its client instead
+type LinkTitles struct {
+	HTTPClient  *http.Client
+	GitHubAPI   string                 // GitHub's REST API, github.APIEndpoint unless testing
+	GitHubToken func() (string, error) // public repositories need none
+	Getenv      func(string) string    // reads GITLAB_HOST, GITLAB_TOKEN, JIRA_BASE_URL, JIRA_EMAIL and JIRA_API_TOKEN
+}
+
+// gitLabHost is where GITLAB_TOKEN is sent unless GITLAB_HOST names another
+const gitLabHost = "gitlab.com"
+
+// NewLinkTitles fetches titles within timeout, signing in to GitHub with
+// the token sprout auth github keeps when there is one
+func NewLinkTitles(tokens github.TokenStoreInterface, timeout time.Duration) *LinkTitles {
+	return &LinkTitles{
+		HTTPClient:  &http.Client{Timeout: timeout},
+		GitHubAPI:   github.APIEndpoint,
+		GitHubToken: func() (string, error) { return github.FindToken(tokens) },
+		Getenv:      os.Getenv,
+	}
+}
+
+// Title fetches the title of the issue link points at
+func (t *LinkTitles) Title(link Link) (string, error) {
+	var title string
+	var err error
+	switch link.Tracker {
+	case LinkGitHub:
+		title, err = t.gitHubTitle(link)
+	case LinkGitLab:
+		title, err = t.gitLabTitle(link)
+	case LinkJira:
+		title, err = t.jiraTitle(link)
+	default:
+		return "", fmt.Errorf("%s issues are looked up with the issue tracker, not by link", link.Tracker)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the title of %s: %w", link.URL, err)
+	}
+	return title, nil
+}
+
+func (t *LinkTitles) gitHubTitle(link Link) (string, error) {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(t.GitHubAPI, "/")+"/repos/"+link.Project+"/issues/"+link.Identifier, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if t.GitHubToken != nil {
+		if token, err := t.GitHubToken(); err == nil && token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	var issue struct {
+		Title string `json:"title"`
+	}
+	if err := t.fetch(req, "GitHub", "set GH_TOKEN or run sprout auth github for private repositories", &issue); err != nil {
+		return "", err
+	}
+	return issue.Title, nil
+}
+
+func (t *LinkTitles) gitLabTitle(link Link) (string, error) {
+	endpoint := fmt.Sprintf("%s://%s/api/v4/projects/%s/issues/%s", link.URL.Scheme, link.URL.Host, url.PathEscape(link.Project), link.Identifier)
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	if token := t.getenv("GITLAB_TOKEN"); token != "" && t.configuredHost(link, "GITLAB_HOST", gitLabHost) {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+	var issue struct {
+		Title string `json:"title"`
+	}
+	if err := t.fetch(req, "GitLab", "set GITLAB_TOKEN, and GITLAB_HOST when it isn't gitlab.com, for private projects", &issue); err != nil {
+		return "", err
+	}
+	return issue.Title, nil
+}
+
+func (t *LinkTitles) jiraTitle(link Link) (string, error) {
+	endpoint := fmt.Sprintf("%s://%s/rest/api/2/issue/%s?fields=summary", link.URL.Scheme, link.URL.Host, link.Identifier)
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	if email, token := t.getenv("JIRA_EMAIL"), t.getenv("JIRA_API_TOKEN"); token != "" && t.configuredHost(link, "JIRA_BASE_URL", "") {
+		req.SetBasicAuth(email, token)
+	}
+	var issue struct {
+		Fields struct {
+			Summary string `json:"summary"`
+		} `json:"fields"`
+	}
+	if err := t.fetch(req, "Jira", "set JIRA_BASE_URL, JIRA_EMAIL and JIRA_API_TOKEN to sign in", &issue); err != nil {
+		return "", err
+	}
+	return issue.Fields.Summary, nil
+}
+
+// fetch sends req to tracker and decodes its JSON response into result,
+// suggesting how to sign in when it's turned away
+func (t *LinkTitles) fetch(req *http.Request, tracker, signIn string, result any) error {
+	client := t.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", tracker, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		// Private issues are hidden as not found rather than refused
+		return fmt.Errorf("%s answered with status %d; %s", tracker, resp.StatusCode, signIn)
+	default:
+		return fmt.Errorf("%s answered with status %d", tracker, resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func (t *LinkTitles) getenv(name string) string {
+	if t.Getenv == nil {
+		return ""
+	}
+	return strings.TrimSpace(t.Getenv(name))
+}
+
+// configuredHost reports whether link is on the host the variable name sets,
+// as a host name or a URL, or on fallback when it's unset. Tokens are only
+// sent there, not to any host a pasted link happens to name
+func (t *LinkTitles) configuredHost(link Link, name, fallback string) bool {
+	host := t.getenv(name)
+	if parsed, err := url.Parse(host); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+	if host == "" {
+		host = fallback
+	}
+	return host != "" && strings.EqualFold(strings.TrimSuffix(host, "/"), link.URL.Host)
+}
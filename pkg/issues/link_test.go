@@ -0,0 +1,109 @@
+package issues
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseLinkRecognisesEachTracker(t *testing.T) {
+	tests := []struct {
+		raw        string
+		tracker    string
+		identifier string
+		project    string
+		slug       string
+	}{
+		{"https://linear.app/acme/issue/ENG-123/fix-the-login-form", LinkLinear, "ENG-123", "", "fix-the-login-form"},
+		{"https://linear.app/acme/issue/eng-9", LinkLinear, "ENG-9", "", ""},
+		{"https://github.com/acme/web/issues/42", LinkGitHub, "42", "acme/web", ""},
+		{"https://github.com/acme/web/issues/42#issuecomment-1", LinkGitHub, "42", "acme/web", ""},
+		{"https://gitlab.com/acme/platform/web/-/issues/7", LinkGitLab, "7", "acme/platform/web", ""},
+		{"https://git.example.com/team/app/-/work_items/8", LinkGitLab, "8", "team/app", ""},
+		{"https://acme.atlassian.net/browse/PROJ-12", LinkJira, "PROJ-12", "", ""},
+	}
+	for _, tt := range tests {
+		link, ok := ParseLink(tt.raw)
+		if !ok || link.Tracker != tt.tracker || link.Identifier != tt.identifier || link.Project != tt.project || link.Slug != tt.slug {
+			t.Errorf("ParseLink(%q) = %+v, %v", tt.raw, link, ok)
+		}
+	}
+
+	for _, raw := range []string{"fix/login", "ENG-123", "https://github.com/acme/web/pull/42", "https://example.com/browse", "git@github.com:acme/web.git", "http://gitlab.com/acme/web/-/issues/7", "https://jira.example.com/jira/software/projects/PROJ/boards/1?selectedIssue=PROJ-3"} {
+		if link, ok := ParseLink(raw); ok {
+			t.Errorf("expected %q taken as a branch, got %+v", raw, link)
+		}
+	}
+}
+
+func TestLinkTitlesFetchesFromEachTracker(t *testing.T) {
+	var auth []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization")+r.Header.Get("PRIVATE-TOKEN"))
+		switch r.URL.EscapedPath() {
+		case "/repos/acme/web/issues/42":
+			w.Write([]byte(`{"title": "Fix the login form"}`))
+		case "/api/v4/projects/acme%2Fweb/issues/7":
+			w.Write([]byte(`{"title": "Speed up search"}`))
+		case "/rest/api/2/issue/PROJ-12":
+			w.Write([]byte(`{"fields": {"summary": "Audit log"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	env := map[string]string{"GITLAB_HOST": server.URL, "GITLAB_TOKEN": "glpat", "JIRA_BASE_URL": server.URL, "JIRA_EMAIL": "me@example.com", "JIRA_API_TOKEN": "jira"}
+	titles := &LinkTitles{
+		HTTPClient:  server.Client(),
+		GitHubAPI:   server.URL,
+		GitHubToken: func() (string, error) { return "", nil },
+		Getenv:      func(name string) string { return env[name] },
+	}
+	for raw, want := range map[string]string{
+		"https://github.com/acme/web/issues/42": "Fix the login form",
+		server.URL + "/acme/web/-/issues/7":     "Speed up search",
+		server.URL + "/browse/PROJ-12":          "Audit log",
+	} {
+		link, ok := ParseLink(raw)
+		if !ok {
+			t.Fatalf("expected %q to be a link", raw)
+		}
+		if title, err := titles.Title(link); err != nil || title != want {
+			t.Errorf("expected %q for %s, got %q, %v", want, raw, title, err)
+		}
+	}
+	if sent := strings.Join(auth, ","); !strings.Contains(sent, "glpat") || !strings.Contains(sent, "Basic ") {
+		t.Errorf("expected the GitLab and Jira tokens sent, got %v", auth)
+	}
+
+	link, _ := ParseLink("https://github.com/acme/private/issues/1")
+	if _, err := titles.Title(link); err == nil || !strings.Contains(err.Error(), "set GH_TOKEN or run sprout auth github") {
+		t.Fatalf("expected a hint about signing in, got %v", err)
+	}
+}
+
+func TestLinkTitlesOnlySignInToConfiguredHosts(t *testing.T) {
+	var auth []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization")+r.Header.Get("PRIVATE-TOKEN"))
+		w.Write([]byte(`{"title": "Speed up search", "fields": {"summary": "Audit log"}}`))
+	}))
+	defer server.Close()
+
+	env := map[string]string{"GITLAB_HOST": "gitlab.example.com", "GITLAB_TOKEN": "glpat", "JIRA_BASE_URL": "https://acme.atlassian.net", "JIRA_EMAIL": "me@example.com", "JIRA_API_TOKEN": "jira"}
+	titles := &LinkTitles{HTTPClient: server.Client(), Getenv: func(name string) string { return env[name] }}
+	for _, raw := range []string{server.URL + "/acme/web/-/issues/7", server.URL + "/browse/PROJ-12"} {
+		link, ok := ParseLink(raw)
+		if !ok {
+			t.Fatalf("expected %q to be a link", raw)
+		}
+		if _, err := titles.Title(link); err != nil {
+			t.Fatalf("expected %s to be fetched without signing in, got %v", raw, err)
+		}
+	}
+	if sent := strings.Join(auth, ""); sent != "" {
+		t.Errorf("expected no tokens sent to a host that isn't configured, got %v", auth)
+	}
+}
@@ -0,0 +1,86 @@
+package issues
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"sprout/pkg/linear"
+)
+
+// Suggestion is the issue NextUp thinks is most likely to be worked on next,
+// with why, such as "In Progress, high priority"
+type Suggestion struct {
+	Issue  linear.Issue
+	Reason string
+}
+
+// How much each signal counts towards an issue being next up. Work already
+// started outweighs everything else, then priority, then how recently the
+// issue changed; small estimates only break near ties. Only issues that are
+// started or planned are suggested, not those still in triage or the backlog
+var (
+	stateScores    = map[string]int{"started": 100, "unstarted": 50}
+	priorityScores = map[int]int{1: 40, 2: 30, 3: 15, 4: 5} // urgent, high, medium, low
+	priorityNames  = map[int]string{1: "urgent", 2: "high priority", 3: "medium priority", 4: "low priority"}
+)
+
+// NextUp ranks the issues and their loaded subtasks and returns the one most
+// likely to be picked up next, or nil when none are started or planned. now
+// is when recency is measured from
+func NextUp(issues []linear.Issue, now time.Time) *Suggestion {
+	var candidates []linear.Issue
+	var collect func(issues []linear.Issue)
+	collect = func(issues []linear.Issue) {
+		for _, issue := range issues {
+			if _, open := stateScores[issue.State.Type]; open {
+				candidates = append(candidates, issue)
+			}
+			collect(issue.Children)
+		}
+	}
+	collect(issues)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := nextUpScore(candidates[i], now), nextUpScore(candidates[j], now)
+		if a != b {
+			return a > b
+		}
+		if !candidates[i].UpdatedAt.Equal(candidates[j].UpdatedAt) {
+			return candidates[i].UpdatedAt.After(candidates[j].UpdatedAt)
+		}
+		return candidates[i].Identifier < candidates[j].Identifier
+	})
+
+	best := candidates[0]
+	reasons := []string{best.State.Name}
+	if name, ok := priorityNames[best.Priority]; ok {
+		reasons = append(reasons, name)
+	}
+	return &Suggestion{Issue: best, Reason: strings.Join(reasons, ", ")}
+}
+
+func nextUpScore(issue linear.Issue, now time.Time) int {
+	score := stateScores[issue.State.Type] + priorityScores[issue.Priority]
+
+	switch age := now.Sub(issue.UpdatedAt); {
+	case issue.UpdatedAt.IsZero():
+	case age < 24*time.Hour:
+		score += 15
+	case age < 7*24*time.Hour:
+		score += 8
+	}
+
+	// A quick win is a little likelier to be picked up than a big one
+	switch {
+	case issue.Estimate <= 0:
+	case issue.Estimate <= 2:
+		score += 4
+	case issue.Estimate <= 3:
+		score += 2
+	}
+	return score
+}
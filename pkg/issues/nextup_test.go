@@ -0,0 +1,93 @@
+package issues
+
+import (
+	"testing"
+	"time"
+
+	"sprout/pkg/linear"
+)
+
+func TestNextUp(t *testing.T) {
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	inProgress := linear.State{Name: "In Progress", Type: "started"}
+	todo := linear.State{Name: "Todo", Type: "unstarted"}
+	done := linear.State{Name: "Done", Type: "completed"}
+	backlog := linear.State{Name: "Backlog", Type: "backlog"}
+
+	tests := []struct {
+		name       string
+		issues     []linear.Issue
+		identifier string
+		reason     string
+	}{
+		{
+			name: "started work beats an urgent todo",
+			issues: []linear.Issue{
+				{Identifier: "SPR-1", State: todo, Priority: 1, UpdatedAt: now},
+				{Identifier: "SPR-2", State: inProgress, Priority: 2, UpdatedAt: now.AddDate(0, 0, -30)},
+			},
+			identifier: "SPR-2",
+			reason:     "In Progress, high priority",
+		},
+		{
+			name: "priority decides between todos",
+			issues: []linear.Issue{
+				{Identifier: "SPR-1", State: todo, Priority: 4, UpdatedAt: now},
+				{Identifier: "SPR-2", State: todo, Priority: 1, UpdatedAt: now.AddDate(0, 0, -3)},
+			},
+			identifier: "SPR-2",
+			reason:     "Todo, urgent",
+		},
+		{
+			name: "recent activity decides between equal priorities",
+			issues: []linear.Issue{
+				{Identifier: "SPR-1", State: todo, Priority: 3, UpdatedAt: now.AddDate(0, 0, -20)},
+				{Identifier: "SPR-2", State: todo, Priority: 3, UpdatedAt: now.Add(-time.Hour)},
+			},
+			identifier: "SPR-2",
+			reason:     "Todo, medium priority",
+		},
+		{
+			name: "a small estimate breaks a tie",
+			issues: []linear.Issue{
+				{Identifier: "SPR-1", State: todo, Estimate: 8, UpdatedAt: now.AddDate(0, 0, -20)},
+				{Identifier: "SPR-2", State: todo, Estimate: 1, UpdatedAt: now.AddDate(0, 0, -21)},
+			},
+			identifier: "SPR-2",
+			reason:     "Todo",
+		},
+		{
+			name: "subtasks are candidates and closed or backlog issues aren't",
+			issues: []linear.Issue{
+				{Identifier: "SPR-1", State: done, Priority: 1, UpdatedAt: now, Children: []linear.Issue{
+					{Identifier: "SPR-3", State: inProgress, UpdatedAt: now},
+				}},
+				{Identifier: "SPR-2", State: backlog, Priority: 1, UpdatedAt: now},
+			},
+			identifier: "SPR-3",
+			reason:     "In Progress",
+		},
+		{
+			name:   "nothing started or planned",
+			issues: []linear.Issue{{Identifier: "SPR-1", State: done}, {Identifier: "SPR-2", State: backlog}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestion := NextUp(tt.issues, now)
+			if tt.identifier == "" {
+				if suggestion != nil {
+					t.Fatalf("expected no suggestion, got %s", suggestion.Issue.Identifier)
+				}
+				return
+			}
+			if suggestion == nil {
+				t.Fatalf("expected %s, got no suggestion", tt.identifier)
+			}
+			if suggestion.Issue.Identifier != tt.identifier || suggestion.Reason != tt.reason {
+				t.Fatalf("expected %s (%s), got %s (%s)", tt.identifier, tt.reason, suggestion.Issue.Identifier, suggestion.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package issues
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"sprout/pkg/config"
+	"sprout/pkg/linear"
+)
+
+// Repository is what sprout needs from an issue tracker: the issues assigned
+// to the user and their subtasks, and moving them between states. Every
+// tracker describes its issues with the linear package's Issue and State,
+// which is where sprout's issue model began; a tracker leaves out what it has
+// no equivalent for, such as cycles or estimates.
+type Repository interface {
+	GetCurrentUser() (*linear.User, error)
+	GetAssignedIssues() ([]linear.Issue, error)
+	GetIssueChildren(issueID string) ([]linear.Issue, error)
+	GetChildrenOfIssues(issueIDs []string) (map[string][]linear.Issue, error)
+	GetIssue(identifier string) (*linear.Issue, error)
+	CreateSubtask(parentID, title string) (*linear.Issue, error)
+	CreateSubtaskWithOptions(parentID, title string, opts linear.SubtaskOptions) (*linear.Issue, error)
+	UnassignIssue(issueID string) error
+	AssignIssueToMe(issueID string) error
+	MarkIssueDone(issueID string) error
+	GetWorkflowStates(issueID string) ([]linear.State, error)
+	UpdateIssueState(issueID, stateID string) error
+	GetIssueStates(identifiers []string) (map[string]linear.State, error)
+	TestConnection() error
+}
+
+// Provider builds an issue tracker's client from the loaded config, reading
+// whatever credentials it needs from there. A nil client with no error means
+// the tracker isn't set up yet, which switches sprout's issue features off.
+type Provider func(cfg *config.Config) (Repository, error)
+
+// None is the provider name that turns issue integration off
+const None = "none"
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{
+		"linear": newLinearClient,
+		"github": newGitHubIssues,
+		"jira":   newJiraIssues,
+		None:     func(*config.Config) (Repository, error) { return nil, nil },
+	}
+)
+
+// Register makes a provider available under name for the issueProvider config
+// key. It panics if the name is taken, so two providers can't shadow each other.
+func Register(name string, provider Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	if provider == nil {
+		panic("issues: Register provider is nil")
+	}
+	if _, taken := providers[name]; taken {
+		panic("issues: Register called twice for provider " + name)
+	}
+	providers[name] = provider
+}
+
+// Names lists the registered providers in name order
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New builds the client for the provider cfg selects; it is nil when that
+// provider has nothing to connect to
+func New(cfg *config.Config) (Repository, error) {
+	name := cfg.GetIssueProvider()
+	mu.RLock()
+	provider, ok := providers[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown issueProvider %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+
+	client, err := provider(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return client, nil
+}
+
+// NewForRepo builds the client for repoRoot, which may take its issues from
+// just one of the configured Linear workspaces
+func NewForRepo(cfg *config.Config, repoRoot string) (Repository, error) {
+	return New(cfg.ForRepo(repoRoot))
+}
+
+// linearTokens is where sprout auth linear keeps its sign-in
+var linearTokens = linear.NewTokenStore
+
+// newLinearClient connects to each configured workspace, merging them behind
+// an Aggregate when there's more than one. A workspace that signs in with
+// OAuth is left out until sprout auth linear has been run. Each workspace's
+// issues are cached for issueCacheSeconds, and on disk with issueCacheOnDisk.
+func newLinearClient(cfg *config.Config) (Repository, error) {
+	configured := cfg.GetLinearWorkspaces()
+	cachePath := cachePathFor(cfg)
+	workspaces := make([]Workspace, 0, len(configured))
+	for _, workspace := range configured {
+		var client *linear.Client
+		if workspace.OAuth {
+			oauth := linear.NewOAuth(cfg.LinearOAuthClientID, linearTokens())
+			token, err := oauth.StoredToken()
+			if err != nil {
+				return nil, err
+			}
+			if token == nil {
+				continue
+			}
+			client = linear.NewOAuthClient(oauth)
+		} else {
+			client = linear.NewClient(workspace.APIKey)
+		}
+		client.SetTimeout(cfg.NetworkTimeout())
+		cached := NewCacheWithPath(client, workspace.Name, cfg.IssueCacheTTL(), cachePath)
+		workspaces = append(workspaces, Workspace{Name: workspace.Name, Client: cached})
+	}
+	switch len(workspaces) {
+	case 0:
+		return nil, nil
+	case 1:
+		return workspaces[0].Client, nil
+	}
+	return NewAggregate(workspaces), nil
+}
+
+// cachePathFor is where fetched issues are kept between runs, "" unless
+// issueCacheOnDisk is set
+func cachePathFor(cfg *config.Config) string {
+	if cfg.IssueCacheOnDisk {
+		return DefaultCachePath()
+	}
+	return ""
+}
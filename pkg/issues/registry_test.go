@@ -0,0 +1,132 @@
+package issues
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/config"
+	"sprout/pkg/linear"
+)
+
+// stubClient stands in for a third-party tracker's client
+type stubClient struct {
+	linear.LinearClientInterface
+	token string
+}
+
+// isCachedLinearClient is whether client is a Linear client behind a Cache
+func isCachedLinearClient(client linear.LinearClientInterface) bool {
+	cached, ok := client.(*Cache)
+	if !ok {
+		return false
+	}
+	_, ok = cached.LinearClientInterface.(*linear.Client)
+	return ok
+}
+
+func TestNewDefaultsToLinear(t *testing.T) {
+	client, err := New(&config.Config{LinearAPIKey: "lin_api_test"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if !isCachedLinearClient(client) {
+		t.Fatalf("Expected a cached Linear client, got %T", client)
+	}
+
+	client, err = New(&config.Config{})
+	if err != nil || client != nil {
+		t.Fatalf("Expected no client without an API key, got %v, %v", client, err)
+	}
+}
+
+func TestNewWithNoneTurnsIssuesOff(t *testing.T) {
+	client, err := New(&config.Config{IssueProvider: None, LinearAPIKey: "lin_api_test"})
+	if err != nil || client != nil {
+		t.Fatalf("Expected no client, got %v, %v", client, err)
+	}
+}
+
+func TestNewRejectsAnUnknownProvider(t *testing.T) {
+	_, err := New(&config.Config{IssueProvider: "redmine"})
+	if err == nil || !strings.Contains(err.Error(), `unknown issueProvider "redmine" (available: github, jira, linear, none`) {
+		t.Fatalf("Expected an unknown provider error, got %v", err)
+	}
+}
+
+func TestRegisteredProvidersAreSelectedByConfig(t *testing.T) {
+	Register("test-tracker", func(cfg *config.Config) (Repository, error) {
+		return &stubClient{token: cfg.GetLinearAPIKey()}, nil
+	})
+	Register("test-broken", func(cfg *config.Config) (Repository, error) {
+		return nil, fmt.Errorf("token expired")
+	})
+
+	client, err := New(&config.Config{IssueProvider: "test-tracker", LinearAPIKey: "secret"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if stub, ok := client.(*stubClient); !ok || stub.token != "secret" {
+		t.Fatalf("Expected the registered provider's client, got %#v", client)
+	}
+
+	_, err = New(&config.Config{IssueProvider: "test-broken"})
+	if err == nil || err.Error() != "test-broken: token expired" {
+		t.Fatalf("Expected the provider's error, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected registering a taken name to panic")
+		}
+	}()
+	Register("linear", newLinearClient)
+}
+
+func TestNewMergesSeveralLinearWorkspaces(t *testing.T) {
+	cfg := &config.Config{
+		LinearAPIKey:     "lin_api_personal",
+		LinearWorkspaces: map[string]string{"client": "lin_api_client"},
+		LinearWorkspace:  map[string]string{"/repos/client-app": "client"},
+	}
+
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, ok := client.(*Aggregate); !ok {
+		t.Fatalf("Expected the workspaces merged, got %T", client)
+	}
+
+	client, err = NewForRepo(cfg, "/repos/client-app")
+	if err != nil {
+		t.Fatalf("NewForRepo failed: %v", err)
+	}
+	if !isCachedLinearClient(client) {
+		t.Fatalf("Expected just the client workspace's Linear client, got %T", client)
+	}
+}
+
+func TestNewSignsInWithOAuthOnceAuthenticated(t *testing.T) {
+	store := &linear.FileTokenStore{Path: filepath.Join(t.TempDir(), "linear-oauth.json")}
+	linearTokens = func() linear.TokenStore { return store }
+	defer func() { linearTokens = linear.NewTokenStore }()
+	cfg := &config.Config{LinearOAuthClientID: "client-id"}
+
+	client, err := New(cfg)
+	if err != nil || client != nil {
+		t.Fatalf("Expected no client before sprout auth linear, got %v, %v", client, err)
+	}
+
+	if err := store.SaveToken(&linear.Token{AccessToken: "access", RefreshToken: "refresh"}); err != nil {
+		t.Fatal(err)
+	}
+	client, err = New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if !isCachedLinearClient(client) {
+		t.Fatalf("Expected a cached Linear client, got %T", client)
+	}
+}
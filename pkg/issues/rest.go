@@ -0,0 +1,107 @@
+package issues
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// errNotFound is wrapped by errors for issues the tracker doesn't have, or
+// hides from the user
+var errNotFound = errors.New("not found")
+
+// restAPI calls a tracker's JSON REST API, signing each request with sign
+type restAPI struct {
+	tracker    string // named in errors
+	signIn     string // how to sign in, suggested when credentials are turned away
+	endpoint   string
+	httpClient *http.Client
+	sign       func(req *http.Request)
+}
+
+// call sends body as JSON to path with method and decodes the response into
+// result, when there is one
+func (a *restAPI) call(method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(a.endpoint, "/")+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	a.sign(req)
+
+	client := a.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", a.tracker, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("%s rejected the credentials; %s", a.tracker, a.signIn)
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s answered %s with status 404: %w", a.tracker, path, errNotFound)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		if message := apiErrorMessage(respBody); message != "" {
+			return fmt.Errorf("%s API request failed with status %d: %s", a.tracker, resp.StatusCode, message)
+		}
+		return fmt.Errorf("%s API request failed with status %d", a.tracker, resp.StatusCode)
+	}
+
+	if result == nil || len(bytes.TrimSpace(respBody)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// apiErrorMessage picks the explanation out of an error response, in
+// GitHub's message or Jira's errorMessages and errors
+func apiErrorMessage(body []byte) string {
+	var apiErr struct {
+		Message       string            `json:"message"`
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return ""
+	}
+	messages := apiErr.ErrorMessages
+	if apiErr.Message != "" {
+		messages = append(messages, apiErr.Message)
+	}
+	fields := make([]string, 0, len(apiErr.Errors))
+	for field := range apiErr.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		messages = append(messages, field+": "+apiErr.Errors[field])
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,132 @@
+// Package keychain keeps sprout's secrets in the system keychain: the login
+// keychain on macOS through security, or the Secret Service keyring used by
+// GNOME and KDE on Linux through secret-tool
+package keychain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// service groups sprout's items in the keychain; each secret is an account
+// under it
+const service = "sprout"
+
+// ErrUnavailable is returned when sprout doesn't know how to reach a keychain
+// on this system
+var ErrUnavailable = errors.New("no system keychain is available")
+
+// The system's keychain tools, and how they're run, which tests swap out
+var (
+	goos     = runtime.GOOS
+	lookPath = exec.LookPath
+	command  = exec.Command
+)
+
+// Available reports whether the tool for this system's keychain is installed
+func Available() bool {
+	return tool() != ""
+}
+
+func tool() string {
+	var name string
+	switch goos {
+	case "darwin":
+		name = "security"
+	case "linux":
+		name = "secret-tool"
+	default:
+		return ""
+	}
+	if _, err := lookPath(name); err != nil {
+		return ""
+	}
+	return name
+}
+
+// Get reads the secret stored for account, or "" when there isn't one
+func Get(account string) (string, error) {
+	switch tool() {
+	case "security":
+		output, err := command("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+		if err != nil {
+			// security exits 44 when there's no such item
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+				return "", nil
+			}
+			return "", fmt.Errorf("failed to read the keychain: %w", err)
+		}
+		return strings.TrimSpace(string(output)), nil
+	case "secret-tool":
+		output, err := command("secret-tool", "lookup", "service", service, "account", account).Output()
+		if err != nil {
+			// secret-tool exits 1 with no output when there's no such secret
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && len(output) == 0 && len(exitErr.Stderr) == 0 {
+				return "", nil
+			}
+			return "", fmt.Errorf("failed to read the keyring: %w", err)
+		}
+		return strings.TrimSpace(string(output)), nil
+	default:
+		return "", ErrUnavailable
+	}
+}
+
+// Set stores secret for account, replacing any secret already there; label
+// is what the keyring shows the item as
+func Set(account, label, secret string) error {
+	switch tool() {
+	case "security":
+		// security -i reads its commands from stdin, so the secret, given to
+		// -X in hex, never shows up in the process list as it would after -w
+		cmd := command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -l %q -X %s\n", service, account, label, hex.EncodeToString([]byte(secret))))
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to write the keychain: %w\nOutput: %s", err, string(output))
+		}
+		// It doesn't exit with the status of the commands it reads, so the
+		// secret is read back to be sure it was written
+		if stored, err := Get(account); err != nil || stored != secret {
+			return fmt.Errorf("failed to write the keychain\nOutput: %s", string(output))
+		}
+		return nil
+	case "secret-tool":
+		// The secret goes in on stdin so it never shows up in the process list
+		cmd := command("secret-tool", "store", "--label="+label, "service", service, "account", account)
+		cmd.Stdin = strings.NewReader(secret)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to write the keyring: %w\nOutput: %s", err, string(output))
+		}
+		return nil
+	default:
+		return ErrUnavailable
+	}
+}
+
+// Delete removes the secret stored for account; removing one that isn't
+// there is not an error
+func Delete(account string) error {
+	switch tool() {
+	case "security":
+		output, err := command("security", "delete-generic-password", "-s", service, "-a", account).CombinedOutput()
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 44) {
+			return fmt.Errorf("failed to remove the keychain item: %w\nOutput: %s", err, string(output))
+		}
+		return nil
+	case "secret-tool":
+		if output, err := command("secret-tool", "clear", "service", service, "account", account).CombinedOutput(); err != nil && len(output) > 0 {
+			return fmt.Errorf("failed to remove the keyring item: %w\nOutput: %s", err, string(output))
+		}
+		return nil
+	default:
+		return ErrUnavailable
+	}
+}
@@ -0,0 +1,127 @@
+package keychain
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// useFakeTool has the keychain reached through a fake security or
+// secret-tool, as on goos, which keeps secrets as files in a temporary
+// directory and logs the command lines it's run with there
+func useFakeTool(t *testing.T, system string) string {
+	dir := t.TempDir()
+	origGOOS, origLookPath, origCommand := goos, lookPath, command
+	t.Cleanup(func() { goos, lookPath, command = origGOOS, origLookPath, origCommand })
+
+	goos = system
+	lookPath = func(name string) (string, error) { return name, nil }
+	command = func(name string, args ...string) *exec.Cmd {
+		cmd := exec.Command(os.Args[0], append([]string{"-test.run=TestFakeKeychainTool", "--", name}, args...)...)
+		cmd.Env = append(os.Environ(), "SPROUT_FAKE_KEYCHAIN="+dir)
+		return cmd
+	}
+	return dir
+}
+
+// TestFakeKeychainTool is the fake security and secret-tool useFakeTool
+// runs, rather than a test
+func TestFakeKeychainTool(t *testing.T) {
+	dir := os.Getenv("SPROUT_FAKE_KEYCHAIN")
+	if dir == "" {
+		t.Skip("only run as a fake keychain tool")
+	}
+	args := os.Args[slices.Index(os.Args, "--")+1:]
+	log, _ := os.OpenFile(filepath.Join(dir, "commands"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	log.WriteString(strings.Join(args, " ") + "\n")
+	log.Close()
+
+	stdin, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if args[0] == "security" && args[1] == "-i" {
+		args = append([]string{"security"}, strings.Fields(stdin)...)
+	}
+	valueOf := func(flag string) string {
+		if i := slices.Index(args, flag); i >= 0 && i+1 < len(args) {
+			return args[i+1]
+		}
+		return ""
+	}
+	account := valueOf("-a")
+	if args[0] == "secret-tool" {
+		account = valueOf("account")
+	}
+	item := filepath.Join(dir, "item-"+account)
+
+	switch args[1] {
+	case "add-generic-password":
+		secret, _ := hex.DecodeString(valueOf("-X"))
+		os.WriteFile(item, secret, 0600)
+	case "store":
+		os.WriteFile(item, []byte(stdin), 0600)
+	case "find-generic-password", "lookup":
+		secret, err := os.ReadFile(item)
+		if err != nil && args[0] == "security" {
+			os.Exit(44)
+		} else if err != nil {
+			os.Exit(1)
+		}
+		os.Stdout.Write(secret)
+	case "delete-generic-password", "clear":
+		if os.Remove(item) != nil && args[0] == "security" {
+			os.Exit(44)
+		}
+	}
+	os.Exit(0)
+}
+
+func TestSecretsAreStoredWithoutShowingInTheProcessList(t *testing.T) {
+	for _, system := range []string{"darwin", "linux"} {
+		t.Run(system, func(t *testing.T) {
+			dir := useFakeTool(t, system)
+			secret := `{"access_token":"lin_oauth_secret"}`
+
+			if got, err := Get("linear-oauth"); err != nil || got != "" {
+				t.Fatalf("expected no secret yet, got %q, %v", got, err)
+			}
+			if err := Set("linear-oauth", "sprout Linear sign-in", secret); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			if got, err := Get("linear-oauth"); err != nil || got != secret {
+				t.Fatalf("expected %q stored, got %q, %v", secret, got, err)
+			}
+			commands, err := os.ReadFile(filepath.Join(dir, "commands"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(commands), "lin_oauth_secret") || strings.Contains(string(commands), hex.EncodeToString([]byte(secret))) {
+				t.Fatalf("expected the secret kept out of the command lines, got:\n%s", commands)
+			}
+
+			if err := Delete("linear-oauth"); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if got, err := Get("linear-oauth"); err != nil || got != "" {
+				t.Fatalf("expected the secret removed, got %q, %v", got, err)
+			}
+			if err := Delete("linear-oauth"); err != nil {
+				t.Fatalf("expected removing a missing secret to succeed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestKeychainIsUnavailableWithoutItsTool(t *testing.T) {
+	useFakeTool(t, "windows")
+	if Available() {
+		t.Fatal("expected no keychain on windows")
+	}
+	if err := Set("github-token", "sprout GitHub token", "ghp_secret"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package linear
+
+import (
+	"regexp"
+	"strings"
+)
+
+// checklistMarker matches what a planning list puts before each item:
+// bullets, numbers and markdown task boxes
+var checklistMarker = regexp.MustCompile(`^(?:[-*+•](?:\s+|$)|\d+[.)](?:\s+|$))?(?:\[[ xX]\](?:\s+|$))?`)
+
+// ChecklistTitles turns a pasted list into subtask titles, one per non-blank
+// line, without their bullets, numbers or task boxes
+func ChecklistTitles(text string) []string {
+	var titles []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		title := strings.TrimSpace(line[len(checklistMarker.FindString(line)):])
+		if title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return titles
+}
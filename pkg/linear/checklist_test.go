@@ -0,0 +1,16 @@
+package linear_test
+
+import (
+	"reflect"
+	"testing"
+
+	"sprout/pkg/linear"
+)
+
+func TestChecklistTitles(t *testing.T) {
+	pasted := "- Write migration\n* [ ] Backfill old rows\r\n\n  2. Drop the column  \n- [x] Update docs\n+ 3 retries\n-\nNo marker"
+	expected := []string{"Write migration", "Backfill old rows", "Drop the column", "Update docs", "3 retries", "No marker"}
+	if titles := linear.ChecklistTitles(pasted); !reflect.DeepEqual(titles, expected) {
+		t.Fatalf("ChecklistTitles() = %q, want %q", titles, expected)
+	}
+}
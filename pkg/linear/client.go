@@ -2,18 +2,28 @@ package linear
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	APIEndpoint = "https://api.linear.app/graphql"
+
+	// DefaultTimeout bounds each API request until SetTimeout says otherwise
+	DefaultTimeout = 30 * time.Second
 )
 
+// ErrTimeout is wrapped by errors from requests Linear didn't answer in time
+var ErrTimeout = errors.New("Linear didn't respond in time")
+
 // Issue represents a Linear issue/ticket
 type Issue struct {
 	ID          string    `json:"id"`
@@ -25,12 +35,17 @@ type Issue struct {
 	UpdatedAt   time.Time `json:"updatedAt"`
 	URL         string    `json:"url"`
 	Identifier  string    `json:"identifier"`
-	Priority    int       `json:"priority"`
+	Priority    int       `json:"priority"` // 0 no priority, 1 urgent, 2 high, 3 medium, 4 low
+	Estimate    float64   `json:"estimate"` // 0 when the issue isn't estimated
+	Cycle       *Cycle    `json:"cycle,omitempty"`
 	Children    []Issue   `json:"children,omitempty"`
 	Parent      *Issue    `json:"parent,omitempty"`
+	Labels      []Label   `json:"-"`
+	Project     *Project  `json:"project,omitempty"`
 	HasChildren bool      `json:"hasChildren"`
 	Expanded    bool      `json:"expanded"`
 	Depth       int       `json:"depth"`
+	Workspace   string    `json:"workspace,omitempty"` // set when issues from several workspaces are merged
 
 	// UI state for inline subtask creation
 	IsAddSubtask        bool   `json:"-"` // true if this is an "add subtask" placeholder
@@ -49,6 +64,58 @@ type State struct {
 	Type string `json:"type"`
 }
 
+// Label represents a Linear issue label
+type Label struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"` // hex, e.g. #bb87fc
+}
+
+// Project represents the Linear project an issue belongs to
+type Project struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// LabelNames returns the names of the issue's labels
+func (i *Issue) LabelNames() []string {
+	names := make([]string, 0, len(i.Labels))
+	for _, label := range i.Labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+// ProjectName returns the name of the issue's project, or "" when it has none
+func (i *Issue) ProjectName() string {
+	if i.Project == nil {
+		return ""
+	}
+	return i.Project.Name
+}
+
+// Cycle represents the Linear cycle an issue is scheduled in
+type Cycle struct {
+	ID       string    `json:"id"`
+	Number   float64   `json:"number"`
+	Name     string    `json:"name"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+}
+
+// ActiveAt reports whether the cycle is under way at now
+func (c *Cycle) ActiveAt(now time.Time) bool {
+	return c != nil && !c.StartsAt.IsZero() && !now.Before(c.StartsAt) && now.Before(c.EndsAt)
+}
+
+// Label is what the cycle is called: its name, or Cycle and its number
+func (c Cycle) Label() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return fmt.Sprintf("Cycle %d", int(c.Number))
+}
+
 // User represents a Linear user
 type User struct {
 	ID          string `json:"id"`
@@ -62,41 +129,69 @@ type LinearClientInterface interface {
 	GetCurrentUser() (*User, error)
 	GetAssignedIssues() ([]Issue, error)
 	GetIssueChildren(issueID string) ([]Issue, error)
+	GetChildrenOfIssues(issueIDs []string) (map[string][]Issue, error)
+	GetIssue(identifier string) (*Issue, error)
 	CreateSubtask(parentID, title string) (*Issue, error)
+	CreateSubtaskWithOptions(parentID, title string, opts SubtaskOptions) (*Issue, error)
 	UnassignIssue(issueID string) error
 	AssignIssueToMe(issueID string) error
 	MarkIssueDone(issueID string) error
+	GetWorkflowStates(issueID string) ([]State, error)
+	UpdateIssueState(issueID, stateID string) error
+	GetIssueStates(identifiers []string) (map[string]State, error)
 	TestConnection() error
 }
 
 // Client is a Linear API client
 type Client struct {
 	apiKey     string
+	oauth      *OAuth // signs requests instead of apiKey when set
 	endpoint   string
 	httpClient *http.Client
+	stateCache *IssueStateCache
+	timeout    time.Duration
 }
 
 // NewClient creates a new Linear API client
 func NewClient(apiKey string) *Client {
-	return NewClientWithEndpoint(apiKey, APIEndpoint, &http.Client{
-		Timeout: 30 * time.Second,
-	})
+	client := NewClientWithEndpoint(apiKey, APIEndpoint, nil)
+	client.stateCache = NewIssueStateCache()
+	return client
+}
+
+// NewOAuthClient creates a Linear API client that signs its requests with the
+// access token from an OAuth sign-in, refreshing it as needed
+func NewOAuthClient(oauth *OAuth) *Client {
+	client := NewOAuthClientWithEndpoint(oauth, APIEndpoint, nil)
+	client.stateCache = NewIssueStateCache()
+	return client
+}
+
+// NewOAuthClientWithEndpoint creates an OAuth client for a specific GraphQL endpoint.
+func NewOAuthClientWithEndpoint(oauth *OAuth, endpoint string, httpClient *http.Client) *Client {
+	client := NewClientWithEndpoint("", endpoint, httpClient)
+	client.oauth = oauth
+	return client
 }
 
 // NewClientWithEndpoint creates a Linear API client for a specific GraphQL endpoint.
 func NewClientWithEndpoint(apiKey, endpoint string, httpClient *http.Client) *Client {
 	if httpClient == nil {
-		httpClient = &http.Client{
-			Timeout: 30 * time.Second,
-		}
+		httpClient = &http.Client{}
 	}
 	return &Client{
 		apiKey:     apiKey,
 		endpoint:   endpoint,
 		httpClient: httpClient,
+		timeout:    DefaultTimeout,
 	}
 }
 
+// SetTimeout bounds how long each API request may take; zero means no limit
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
 // GraphQLRequest represents a GraphQL request
 type GraphQLRequest struct {
 	Query     string      `json:"query"`
@@ -127,16 +222,23 @@ func (c *Client) makeRequest(query string, variables interface{}) (*GraphQLRespo
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequest("POST", c.endpoint, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	ctx := context.Background()
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.post(ctx, reqBody, false)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && c.oauth != nil {
+		// The access token may have been revoked before it expired; a fresh one settles it
+		resp.Body.Close()
+		resp, err = c.post(ctx, reqBody, true)
+	}
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w (waited %s)", ErrTimeout, c.timeout)
+		}
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
 	defer resp.Body.Close()
@@ -148,6 +250,9 @@ func (c *Client) makeRequest(query string, variables interface{}) (*GraphQLRespo
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w (waited %s)", ErrTimeout, c.timeout)
+		}
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
@@ -167,6 +272,29 @@ func (c *Client) makeRequest(query string, variables interface{}) (*GraphQLRespo
 	return &gqlResp, nil
 }
 
+// post sends a GraphQL request body, signed with the API key or an OAuth
+// access token; forceRefresh swaps the access token for a new one first
+func (c *Client) post(ctx context.Context, reqBody []byte, forceRefresh bool) (*http.Response, error) {
+	authorization := c.apiKey
+	if c.oauth != nil {
+		accessToken, err := c.oauth.AccessToken(forceRefresh)
+		if err != nil {
+			return nil, err
+		}
+		authorization = "Bearer " + accessToken
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", authorization)
+
+	return c.httpClient.Do(httpReq)
+}
+
 // GetCurrentUser returns information about the current user
 func (c *Client) GetCurrentUser() (*User, error) {
 	query := `
@@ -198,13 +326,53 @@ func (c *Client) GetCurrentUser() (*User, error) {
 
 // GetAssignedIssues returns issues assigned to the current user
 func (c *Client) GetAssignedIssues() ([]Issue, error) {
+	return c.StreamAssignedIssues(nil)
+}
+
+// assignedIssueNode is an assigned issue as the issues query returns it
+type assignedIssueNode struct {
+	Issue
+	Parent *struct {
+		ID string `json:"id"`
+	} `json:"parent"`
+	Labels struct {
+		Nodes []Label `json:"nodes"`
+	} `json:"labels"`
+	Children struct {
+		Nodes []struct {
+			ID string `json:"id"`
+		} `json:"nodes"`
+	} `json:"children"`
+}
+
+// assignedIssuesCount counts the assigned issues alongside the first page of
+// them, asking only for their IDs. Linear's connections have no count, so the
+// total is only known when they all fit in one page of 250, the most Linear
+// hands over at once
+const assignedIssuesCount = `
+			total: issues(filter: { assignee: { isMe: { eq: true } } }, first: 250) {
+				nodes {
+					id
+				}
+				pageInfo {
+					hasNextPage
+				}
+			}`
+
+// StreamAssignedIssues returns issues assigned to the current user, fetching
+// them AssignedIssuesPageSize at a time and handing onPage everything loaded
+// so far, and how many there are in all when that's known, as each page but
+// the last arrives
+func (c *Client) StreamAssignedIssues(onPage func(IssuesPage)) ([]Issue, error) {
 	query := `
-		query {
+		query AssignedIssues($first: Int, $after: String) {
 			issues(
 				filter: {
 					assignee: { isMe: { eq: true } }
 				}
 				orderBy: updatedAt
+				first: $first
+				after: $after
 			) {
 				nodes {
 					id
@@ -213,8 +381,16 @@ func (c *Client) GetAssignedIssues() ([]Issue, error) {
 					identifier
 					url
 					priority
+					estimate
 					createdAt
 					updatedAt
+					cycle {
+						id
+						number
+						name
+						startsAt
+						endsAt
+					}
 					parent {
 						id
 					}
@@ -229,48 +405,89 @@ func (c *Client) GetAssignedIssues() ([]Issue, error) {
 						displayName
 						email
 					}
+					labels {
+						nodes {
+							id
+							name
+							color
+						}
+					}
+					project {
+						id
+						name
+					}
 					children {
 						nodes {
 							id
 						}
 					}
 				}
-			}
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+			}%s
 		}
 	`
 
-	resp, err := c.makeRequest(query, nil)
-	if err != nil {
-		return nil, err
-	}
+	var nodes []assignedIssueNode
+	total := 0
+	count := assignedIssuesCount
+	variables := map[string]interface{}{"first": AssignedIssuesPageSize}
+	for {
+		resp, err := c.makeRequest(fmt.Sprintf(query, count), variables)
+		if err != nil {
+			return nil, err
+		}
 
-	var result struct {
-		Issues struct {
-			Nodes []struct {
-				Issue
-				Parent *struct {
+		var result struct {
+			Issues struct {
+				Nodes    []assignedIssueNode `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"issues"`
+			Total *struct {
+				Nodes []struct {
 					ID string `json:"id"`
-				} `json:"parent"`
-				Children struct {
-					Nodes []struct {
-						ID string `json:"id"`
-					} `json:"nodes"`
-				} `json:"children"`
-			} `json:"nodes"`
-		} `json:"issues"`
-	}
+				} `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool `json:"hasNextPage"`
+				} `json:"pageInfo"`
+			} `json:"total"`
+		}
+		if err := json.Unmarshal(resp.Data, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal issues data: %w", err)
+		}
+		nodes = append(nodes, result.Issues.Nodes...)
+		if result.Total != nil && !result.Total.PageInfo.HasNextPage {
+			total = len(result.Total.Nodes)
+		}
+		count = ""
 
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal issues data: %w", err)
+		pageInfo := result.Issues.PageInfo
+		if !pageInfo.HasNextPage || pageInfo.EndCursor == "" {
+			return foldAssignedIssues(nodes), nil
+		}
+		if onPage != nil {
+			onPage(IssuesPage{Issues: foldAssignedIssues(nodes), Loaded: len(nodes), Total: total})
+		}
+		variables["after"] = pageInfo.EndCursor
 	}
+}
 
+// foldAssignedIssues lists nodes as the top-level issues, with assigned
+// children left to appear under their assigned parents when expanded
+func foldAssignedIssues(nodes []assignedIssueNode) []Issue {
 	// First pass: collect all issues and build a map by ID, preserving order
 	allIssues := make(map[string]Issue)
 	issueParents := make(map[string]string) // childID -> parentID
 	var issueOrder []string                 // preserve the order from API response
 
-	for _, node := range result.Issues.Nodes {
+	for _, node := range nodes {
 		issue := node.Issue
+		issue.Labels = node.Labels.Nodes
 		issue.HasChildren = len(node.Children.Nodes) > 0
 		issue.Depth = 0
 		issue.Expanded = false
@@ -316,40 +533,218 @@ func (c *Client) GetAssignedIssues() ([]Issue, error) {
 		// Otherwise, skip this issue as it will appear under its parent when expanded
 	}
 
-	return filteredIssues, nil
+	return filteredIssues
+}
+
+// childFields is what's asked for about each child issue
+const childFields = `
+	children {
+		nodes {
+			id
+			title
+			description
+			identifier
+			url
+			priority
+			estimate
+			createdAt
+			updatedAt
+			cycle {
+				id
+				number
+				name
+				startsAt
+				endsAt
+			}
+			state {
+				id
+				name
+				type
+			}
+			assignee {
+				id
+				name
+				displayName
+				email
+			}
+			labels {
+				nodes {
+					id
+					name
+					color
+				}
+			}
+			project {
+				id
+				name
+			}
+			children {
+				nodes {
+					id
+				}
+			}
+		}
+	}
+`
+
+// childrenResult is an issue's children as childFields has them returned
+type childrenResult struct {
+	Children struct {
+		Nodes []struct {
+			Issue
+			Labels struct {
+				Nodes []Label `json:"nodes"`
+			} `json:"labels"`
+			Children struct {
+				Nodes []struct {
+					ID string `json:"id"`
+				} `json:"nodes"`
+			} `json:"children"`
+		} `json:"nodes"`
+	} `json:"children"`
+}
+
+func (r childrenResult) issues() []Issue {
+	children := make([]Issue, len(r.Children.Nodes))
+	for i, node := range r.Children.Nodes {
+		children[i] = node.Issue
+		children[i].Labels = node.Labels.Nodes
+		children[i].HasChildren = len(node.Children.Nodes) > 0
+		children[i].Expanded = false
+	}
+	return children
 }
 
 // GetIssueChildren fetches children/sub-issues for a given issue ID
 func (c *Client) GetIssueChildren(issueID string) ([]Issue, error) {
+	query := `
+		query($issueId: String!) {
+			issue(id: $issueId) {` + childFields + `}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"issueId": issueID,
+	}
+
+	resp, err := c.makeRequest(query, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Issue childrenResult `json:"issue"`
+	}
+
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal children data: %w", err)
+	}
+
+	return result.Issue.issues(), nil
+}
+
+// childrenBatchSize is how many issues' children one request asks for, few
+// enough to stay inside the query complexity Linear allows
+const childrenBatchSize = 25
+
+// GetChildrenOfIssues fetches the children of each of issueIDs, by issue ID,
+// asking for up to childrenBatchSize issues' children in each request rather
+// than one request an issue
+func (c *Client) GetChildrenOfIssues(issueIDs []string) (map[string][]Issue, error) {
+	result := make(map[string][]Issue, len(issueIDs))
+	for start := 0; start < len(issueIDs); start += childrenBatchSize {
+		batch := issueIDs[start:min(start+childrenBatchSize, len(issueIDs))]
+		if err := c.getChildrenBatch(batch, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// getChildrenBatch fetches the children of issueIDs in one request, as one
+// aliased issue query each, adding them to result
+func (c *Client) getChildrenBatch(issueIDs []string, result map[string][]Issue) error {
+	var params, fields strings.Builder
+	variables := make(map[string]interface{}, len(issueIDs))
+	for i, issueID := range issueIDs {
+		name := fmt.Sprintf("issue%d", i)
+		if i > 0 {
+			params.WriteString(", ")
+		}
+		fmt.Fprintf(&params, "$%s: String!", name)
+		fmt.Fprintf(&fields, "\n\t\t\t%s: issue(id: $%s) {%s}", name, name, childFields)
+		variables[name] = issueID
+	}
+	query := "query(" + params.String() + ") {" + fields.String() + "\n\t\t}"
+
+	resp, err := c.makeRequest(query, variables)
+	if err != nil {
+		return err
+	}
+
+	var issues map[string]*childrenResult
+	if err := json.Unmarshal(resp.Data, &issues); err != nil {
+		return fmt.Errorf("failed to unmarshal children data: %w", err)
+	}
+	for i, issueID := range issueIDs {
+		if issue := issues[fmt.Sprintf("issue%d", i)]; issue != nil {
+			result[issueID] = issue.issues()
+		}
+	}
+	return nil
+}
+
+// GetIssue looks up one issue by its identifier, such as SPR-123, whoever
+// it's assigned to. It returns nil when there's no such issue
+func (c *Client) GetIssue(identifier string) (*Issue, error) {
 	query := `
 		query($issueId: String!) {
 			issue(id: $issueId) {
+				id
+				title
+				description
+				identifier
+				url
+				priority
+				estimate
+				createdAt
+				updatedAt
+				cycle {
+					id
+					number
+					name
+					startsAt
+					endsAt
+				}
+				parent {
+					id
+					identifier
+				}
+				state {
+					id
+					name
+					type
+				}
+				assignee {
+					id
+					name
+					displayName
+					email
+				}
+				labels {
+					nodes {
+						id
+						name
+						color
+					}
+				}
+				project {
+					id
+					name
+				}
 				children {
 					nodes {
 						id
-						title
-						description
-						identifier
-						url
-						priority
-						createdAt
-						updatedAt
-						state {
-							id
-							name
-							type
-						}
-						assignee {
-							id
-							name
-							displayName
-							email
-						}
-						children {
-							nodes {
-								id
-							}
-						}
 					}
 				}
 			}
@@ -357,7 +752,7 @@ func (c *Client) GetIssueChildren(issueID string) ([]Issue, error) {
 	`
 
 	variables := map[string]interface{}{
-		"issueId": issueID,
+		"issueId": identifier,
 	}
 
 	resp, err := c.makeRequest(query, variables)
@@ -366,36 +761,47 @@ func (c *Client) GetIssueChildren(issueID string) ([]Issue, error) {
 	}
 
 	var result struct {
-		Issue struct {
+		Issue *struct {
+			Issue
+			Labels struct {
+				Nodes []Label `json:"nodes"`
+			} `json:"labels"`
 			Children struct {
 				Nodes []struct {
-					Issue
-					Children struct {
-						Nodes []struct {
-							ID string `json:"id"`
-						} `json:"nodes"`
-					} `json:"children"`
+					ID string `json:"id"`
 				} `json:"nodes"`
 			} `json:"children"`
 		} `json:"issue"`
 	}
 
 	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal children data: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal issue data: %w", err)
 	}
 
-	children := make([]Issue, len(result.Issue.Children.Nodes))
-	for i, node := range result.Issue.Children.Nodes {
-		children[i] = node.Issue
-		children[i].HasChildren = len(node.Children.Nodes) > 0
-		children[i].Expanded = false
+	if result.Issue == nil {
+		return nil, nil
 	}
+	issue := result.Issue.Issue
+	issue.Labels = result.Issue.Labels.Nodes
+	issue.HasChildren = len(result.Issue.Children.Nodes) > 0
+	return &issue, nil
+}
 
-	return children, nil
+// SubtaskOptions holds the optional fields sent when creating a subtask.
+// Zero values are left unset.
+type SubtaskOptions struct {
+	Description string
+	Estimate    int
+	Priority    int // 1 urgent, 2 high, 3 medium, 4 low
 }
 
 // CreateSubtask creates a new subtask under the given parent issue
 func (c *Client) CreateSubtask(parentID, title string) (*Issue, error) {
+	return c.CreateSubtaskWithOptions(parentID, title, SubtaskOptions{})
+}
+
+// CreateSubtaskWithOptions creates a new subtask with a description, estimate and priority
+func (c *Client) CreateSubtaskWithOptions(parentID, title string, opts SubtaskOptions) (*Issue, error) {
 	// First, get the parent issue to extract teamId and current user
 	parentQuery := `
 		query($issueId: String!) {
@@ -438,12 +844,15 @@ func (c *Client) CreateSubtask(parentID, title string) (*Issue, error) {
 
 	// Now create the subtask with the correct teamId and assignee
 	query := `
-		mutation($parentId: String!, $title: String!, $teamId: String!, $assigneeId: String!) {
+		mutation($parentId: String!, $title: String!, $teamId: String!, $assigneeId: String!, $description: String, $estimate: Int, $priority: Int) {
 			issueCreate(input: {
 				title: $title
 				parentId: $parentId
 				teamId: $teamId
 				assigneeId: $assigneeId
+				description: $description
+				estimate: $estimate
+				priority: $priority
 			}) {
 				success
 				issue {
@@ -482,6 +891,15 @@ func (c *Client) CreateSubtask(parentID, title string) (*Issue, error) {
 		"teamId":     parentResult.Issue.Team.ID,
 		"assigneeId": parentResult.Viewer.ID,
 	}
+	if opts.Description != "" {
+		variables["description"] = opts.Description
+	}
+	if opts.Estimate > 0 {
+		variables["estimate"] = opts.Estimate
+	}
+	if opts.Priority > 0 {
+		variables["priority"] = opts.Priority
+	}
 
 	resp, err := c.makeRequest(query, variables)
 	if err != nil {
@@ -656,7 +1074,11 @@ func (c *Client) MarkIssueDone(issueID string) error {
 	if err != nil {
 		return err
 	}
+	return c.UpdateIssueState(issueID, stateID)
+}
 
+// UpdateIssueState moves an issue to the given workflow state.
+func (c *Client) UpdateIssueState(issueID, stateID string) error {
 	query := `
 		mutation($issueId: String!, $stateId: String!) {
 			issueUpdate(
@@ -687,16 +1109,90 @@ func (c *Client) MarkIssueDone(issueID string) error {
 	}
 
 	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return fmt.Errorf("failed to unmarshal issue done response: %w", err)
+		return fmt.Errorf("failed to unmarshal issue state update response: %w", err)
 	}
 
 	if !result.IssueUpdate.Success {
-		return fmt.Errorf("failed to mark issue as done")
+		return fmt.Errorf("failed to update issue state")
 	}
 
 	return nil
 }
 
+// workflowStateOrder ranks state types the way Linear lays out a team's board
+var workflowStateOrder = map[string]int{
+	"triage":    0,
+	"backlog":   1,
+	"unstarted": 2,
+	"started":   3,
+	"completed": 4,
+	"canceled":  5,
+}
+
+// GetWorkflowStates returns the workflow states of the issue's team, in board order.
+func (c *Client) GetWorkflowStates(issueID string) ([]State, error) {
+	query := `
+		query($issueId: String!) {
+			issue(id: $issueId) {
+				team {
+					states {
+						nodes {
+							id
+							name
+							type
+							position
+						}
+					}
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"issueId": issueID,
+	}
+
+	resp, err := c.makeRequest(query, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Issue *struct {
+			Team *struct {
+				States struct {
+					Nodes []struct {
+						State
+						Position float64 `json:"position"`
+					} `json:"nodes"`
+				} `json:"states"`
+			} `json:"team"`
+		} `json:"issue"`
+	}
+
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal workflow states response: %w", err)
+	}
+
+	if result.Issue == nil || result.Issue.Team == nil {
+		return nil, fmt.Errorf("no team found for issue")
+	}
+
+	nodes := result.Issue.Team.States.Nodes
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Type != nodes[j].Type {
+			return workflowStateOrder[nodes[i].Type] < workflowStateOrder[nodes[j].Type]
+		}
+		return nodes[i].Position < nodes[j].Position
+	})
+
+	states := make([]State, 0, len(nodes))
+	for _, node := range nodes {
+		states = append(states, node.State)
+	}
+	return states, nil
+}
+
 func (c *Client) getCompletedStateID(issueID string) (string, error) {
 	query := `
 		query($issueId: String!) {
@@ -744,6 +1240,107 @@ func (c *Client) getCompletedStateID(issueID string) (string, error) {
 	return result.Issue.Team.States.Nodes[0].ID, nil
 }
 
+// SetIssueStateCache sets the cache consulted by GetIssueStates; nil disables caching
+func (c *Client) SetIssueStateCache(cache *IssueStateCache) {
+	c.stateCache = cache
+}
+
+// issueStateWorkers bounds how many issue state lookups run at once
+const issueStateWorkers = 4
+
+// GetIssueStates returns the current state of each issue identifier, fetching
+// uncached ones in parallel. Unknown identifiers are left out of the result.
+func (c *Client) GetIssueStates(identifiers []string) (map[string]State, error) {
+	states := make(map[string]State)
+	seen := make(map[string]bool)
+	var missing []string
+	for _, identifier := range identifiers {
+		if seen[identifier] || identifier == "" {
+			continue
+		}
+		seen[identifier] = true
+		if state, ok := c.stateCache.Get(identifier); ok {
+			states[identifier] = state
+			continue
+		}
+		missing = append(missing, identifier)
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		fetched  = make(map[string]State)
+		jobs     = make(chan string)
+	)
+	for i := 0; i < issueStateWorkers && i < len(missing); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for identifier := range jobs {
+				state, err := c.getIssueState(identifier)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				} else if state != nil {
+					fetched[identifier] = *state
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, identifier := range missing {
+		jobs <- identifier
+	}
+	close(jobs)
+	wg.Wait()
+
+	c.stateCache.Remember(fetched)
+	for identifier, state := range fetched {
+		states[identifier] = state
+	}
+	return states, firstErr
+}
+
+func (c *Client) getIssueState(identifier string) (*State, error) {
+	query := `
+		query($issueId: String!) {
+			issue(id: $issueId) {
+				identifier
+				state {
+					id
+					name
+					type
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"issueId": identifier,
+	}
+
+	resp, err := c.makeRequest(query, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Issue *struct {
+			State State `json:"state"`
+		} `json:"issue"`
+	}
+
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal issue state response: %w", err)
+	}
+
+	if result.Issue == nil {
+		return nil, nil
+	}
+	return &result.Issue.State, nil
+}
+
 // TestConnection tests the connection to Linear API and returns basic info
 func (c *Client) TestConnection() error {
 	_, err := c.GetCurrentUser()
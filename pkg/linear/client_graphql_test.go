@@ -2,9 +2,14 @@ package linear_test
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"sprout/pkg/linear"
 	"sprout/pkg/linear/lineartest"
@@ -32,6 +37,180 @@ func TestGetIssueChildrenUsesValidGraphQL(t *testing.T) {
 	}
 }
 
+func TestGetChildrenOfIssuesBatchesParentsIntoFewRequests(t *testing.T) {
+	api := lineartest.NewServer(t)
+	var parentIDs []string
+	for i := range 30 {
+		parentID := fmt.Sprintf("TICK-%d", i+1)
+		api.AddIssue(linear.Issue{Identifier: parentID, Title: "Parent"}, "")
+		api.AddIssue(linear.Issue{Identifier: parentID + "-A", Title: "Child of " + parentID}, parentID)
+		parentIDs = append(parentIDs, parentID)
+	}
+	api.AddIssue(linear.Issue{Identifier: "TICK-LONE", Title: "No children"}, "")
+	parentIDs = append(parentIDs, "TICK-LONE")
+	client := api.Client()
+
+	children, err := client.GetChildrenOfIssues(parentIDs)
+	if err != nil {
+		t.Fatalf("GetChildrenOfIssues returned error: %v", err)
+	}
+	if len(api.Requests) != 2 {
+		t.Fatalf("expected 31 parents fetched in 2 requests, got %d", len(api.Requests))
+	}
+	if len(children) != len(parentIDs) {
+		t.Fatalf("expected children for every parent, got %d", len(children))
+	}
+	if got := children["TICK-27"]; len(got) != 1 || got[0].Title != "Child of TICK-27" {
+		t.Fatalf("unexpected children of TICK-27: %+v", got)
+	}
+	if got, ok := children["TICK-LONE"]; !ok || len(got) != 0 {
+		t.Fatalf("expected TICK-LONE to have no children, got %+v", got)
+	}
+
+	api.FailChildFetch("TICK-3", errors.New("rate limited"))
+	if _, err := client.GetChildrenOfIssues(parentIDs); err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Fatalf("expected the batch with TICK-3 in it to fail, got %v", err)
+	}
+}
+
+func TestStreamAssignedIssuesHandsOverEachPage(t *testing.T) {
+	api := lineartest.NewServer(t)
+	addParentAndChild(api)
+	for i := 3; i <= 5; i++ {
+		api.AddIssue(linear.Issue{Identifier: fmt.Sprintf("TICK-%d", i), Title: "Task"}, "")
+	}
+	api.SetPageSize(2)
+	client := api.Client()
+
+	var pages []string
+	issues, err := client.StreamAssignedIssues(func(page linear.IssuesPage) {
+		pages = append(pages, fmt.Sprintf("%d/%d: %s", page.Loaded, page.Total, identifiers(page.Issues)))
+	})
+	if err != nil {
+		t.Fatalf("StreamAssignedIssues returned error: %v", err)
+	}
+	if len(api.Requests) != 3 {
+		t.Fatalf("expected 5 issues fetched in 3 requests, got %d", len(api.Requests))
+	}
+	expected := []string{"2/5: TICK-1", "4/5: TICK-1, TICK-3, TICK-4"}
+	if strings.Join(pages, "; ") != strings.Join(expected, "; ") {
+		t.Fatalf("expected pages %q, got %q", expected, pages)
+	}
+	if got := identifiers(issues); got != "TICK-1, TICK-3, TICK-4, TICK-5" {
+		t.Fatalf("expected every top-level issue once loading finished, got %s", got)
+	}
+
+	all, err := client.GetAssignedIssues()
+	if err != nil {
+		t.Fatalf("GetAssignedIssues returned error: %v", err)
+	}
+	if got := identifiers(all); got != identifiers(issues) {
+		t.Fatalf("expected GetAssignedIssues to load every page too, got %s", got)
+	}
+}
+
+func TestStreamAssignedIssuesLeavesTheTotalUnknownPastWhatOneCountFetches(t *testing.T) {
+	api := lineartest.NewServer(t)
+	for i := 1; i <= 251; i++ {
+		api.AddIssue(linear.Issue{Identifier: fmt.Sprintf("TICK-%d", i), Title: "Task"}, "")
+	}
+	client := api.Client()
+
+	var totals []int
+	issues, err := client.StreamAssignedIssues(func(page linear.IssuesPage) {
+		totals = append(totals, page.Total)
+	})
+	if err != nil {
+		t.Fatalf("StreamAssignedIssues returned error: %v", err)
+	}
+	if len(issues) != 251 || len(totals) != 5 {
+		t.Fatalf("expected 251 issues over 6 pages, got %d after %d pages", len(issues), len(totals)+1)
+	}
+	for _, total := range totals {
+		if total != 0 {
+			t.Fatalf("expected no total past 250 issues, got %v", totals)
+		}
+	}
+}
+
+func identifiers(issues []linear.Issue) string {
+	var ids []string
+	for _, issue := range issues {
+		ids = append(ids, issue.Identifier)
+	}
+	return strings.Join(ids, ", ")
+}
+
+func TestGetIssueFindsAnyIssueByIdentifier(t *testing.T) {
+	api := lineartest.NewServer(t)
+	addParentAndChild(api)
+	client := api.Client()
+
+	issue, err := client.GetIssue("tick-2")
+	if err != nil {
+		t.Fatalf("GetIssue returned error: %v", err)
+	}
+	if issue == nil || issue.Identifier != "TICK-2" || issue.Title != "Child Task" {
+		t.Fatalf("unexpected issue: %+v", issue)
+	}
+	if issue.Parent == nil || issue.Parent.Identifier != "TICK-1" {
+		t.Fatalf("expected TICK-2's parent to be TICK-1, got %+v", issue.Parent)
+	}
+
+	missing, err := client.GetIssue("TICK-404")
+	if err != nil {
+		t.Fatalf("GetIssue returned error: %v", err)
+	}
+	if missing != nil {
+		t.Fatalf("expected no issue, got %+v", missing)
+	}
+}
+
+func TestGetWorkflowStatesReturnsBoardOrder(t *testing.T) {
+	api := lineartest.NewServer(t)
+	addParentAndChild(api)
+	client := api.Client()
+
+	states, err := client.GetWorkflowStates("TICK-1")
+	if err != nil {
+		t.Fatalf("GetWorkflowStates returned error: %v", err)
+	}
+
+	var names []string
+	for _, state := range states {
+		names = append(names, state.Name)
+	}
+	expected := "Backlog, Todo, In Progress, In Review, Done, Canceled"
+	if got := strings.Join(names, ", "); got != expected {
+		t.Fatalf("expected states %q, got %q", expected, got)
+	}
+}
+
+func TestGetIssueStatesFetchesInParallelAndCaches(t *testing.T) {
+	api := lineartest.NewServer(t)
+	addParentAndChild(api)
+	client := api.Client()
+	client.SetIssueStateCache(linear.NewIssueStateCacheWithPath(filepath.Join(t.TempDir(), "states.json"), time.Minute))
+
+	states, err := client.GetIssueStates([]string{"TICK-1", "TICK-2", "TICK-404", "TICK-1"})
+	if err != nil {
+		t.Fatalf("GetIssueStates returned error: %v", err)
+	}
+	if len(states) != 2 || states["TICK-1"].Name != "In Progress" || states["TICK-2"].Name != "Todo" {
+		t.Fatalf("unexpected states: %+v", states)
+	}
+	if len(api.Requests) != 3 {
+		t.Fatalf("expected 3 requests for 3 distinct identifiers, got %d", len(api.Requests))
+	}
+
+	if _, err := client.GetIssueStates([]string{"TICK-1", "TICK-2"}); err != nil {
+		t.Fatalf("GetIssueStates returned error: %v", err)
+	}
+	if len(api.Requests) != 3 {
+		t.Fatalf("expected cached states to be reused, got %d requests", len(api.Requests))
+	}
+}
+
 func TestLinearGraphQLHarnessRejectsInvalidSyntax(t *testing.T) {
 	api := lineartest.NewServer(t)
 
@@ -79,6 +258,13 @@ func TestLinearClientOperationsUseValidGraphQL(t *testing.T) {
 				return err
 			},
 		},
+		{
+			name: "GetIssue",
+			run: func(client *linear.Client) error {
+				_, err := client.GetIssue("TICK-2")
+				return err
+			},
+		},
 		{
 			name: "CreateSubtask",
 			run: func(client *linear.Client) error {
@@ -104,6 +290,19 @@ func TestLinearClientOperationsUseValidGraphQL(t *testing.T) {
 				return client.MarkIssueDone("TICK-1")
 			},
 		},
+		{
+			name: "GetWorkflowStates",
+			run: func(client *linear.Client) error {
+				_, err := client.GetWorkflowStates("TICK-1")
+				return err
+			},
+		},
+		{
+			name: "UpdateIssueState",
+			run: func(client *linear.Client) error {
+				return client.UpdateIssueState("TICK-1", "state-review")
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -121,6 +320,29 @@ func TestLinearClientOperationsUseValidGraphQL(t *testing.T) {
 	}
 }
 
+func TestRequestsGiveUpAfterTheTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	client := linear.NewClientWithEndpoint("test-key", server.URL, nil)
+	client.SetTimeout(50 * time.Millisecond)
+
+	_, err := client.GetAssignedIssues()
+	if !errors.Is(err, linear.ErrTimeout) {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "waited 50ms") {
+		t.Fatalf("expected the error to say how long it waited, got %v", err)
+	}
+}
+
 func addParentAndChild(api *lineartest.Server) {
 	api.AddIssue(linear.Issue{
 		ID:         "TICK-1",
@@ -3,13 +3,16 @@ package lineartest
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -19,6 +22,7 @@ import (
 )
 
 type Server struct {
+	mu             sync.Mutex // serialises requests, which clients may send in parallel
 	t              testing.TB
 	server         *httptest.Server
 	schema         *ast.Schema
@@ -26,8 +30,12 @@ type Server struct {
 	issueOrder     []string
 	childrenMap    map[string][]string
 	childFetchErrs map[string]error
+	createErrs     map[string]error // by title
 	currentUser    *linear.User
 	nextIssue      int
+	stalled        bool
+	pageSize       int           // the most issues a page holds, or 0 for as many as asked for
+	heldPages      chan struct{} // closed to answer requests for later pages of issues
 	Requests       []linear.GraphQLRequest
 }
 
@@ -41,6 +49,7 @@ func NewServer(t testing.TB) *Server {
 		issueOrder:     []string{},
 		childrenMap:    make(map[string][]string),
 		childFetchErrs: make(map[string]error),
+		createErrs:     make(map[string]error),
 		currentUser: &linear.User{
 			ID:          "fake-user-id",
 			Name:        "Test User",
@@ -115,6 +124,47 @@ func (s *Server) FailChildFetch(issueID string, err error) {
 	s.childFetchErrs[issueID] = err
 }
 
+// FailIssueCreate makes creating an issue titled title fail with err
+func (s *Server) FailIssueCreate(title string, err error) {
+	s.createErrs[title] = err
+}
+
+// Stall stops the server answering, so requests wait until the client gives up
+func (s *Server) Stall() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stalled = true
+}
+
+// SetPageSize caps how many assigned issues each page of them holds
+func (s *Server) SetPageSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = size
+}
+
+// HoldPages answers only the first page of assigned issues, leaving requests
+// for later pages waiting until ReleasePages
+func (s *Server) HoldPages() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.heldPages == nil {
+		s.heldPages = make(chan struct{})
+		// Held requests would keep the server from closing
+		s.t.Cleanup(s.ReleasePages)
+	}
+}
+
+// ReleasePages answers the requests for later pages HoldPages left waiting
+func (s *Server) ReleasePages() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.heldPages != nil {
+		close(s.heldPages)
+		s.heldPages = nil
+	}
+}
+
 func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -126,6 +176,26 @@ func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+
+	s.mu.Lock()
+	if s.stalled {
+		// Once the body is drained, the request context ends when the client hangs up
+		s.mu.Unlock()
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+		return
+	}
+	if _, later := stringVariable(req, "after"); later && s.heldPages != nil {
+		held := s.heldPages
+		s.mu.Unlock()
+		select {
+		case <-held:
+		case <-r.Context().Done():
+			return
+		}
+		s.mu.Lock()
+	}
+	defer s.mu.Unlock()
 	s.Requests = append(s.Requests, req)
 
 	if _, err := gqlparser.LoadQuery(s.schema, req.Query); err != nil {
@@ -145,11 +215,20 @@ func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) requestError(req linear.GraphQLRequest) error {
+	if strings.Contains(req.Query, "issueCreate") {
+		title, _ := stringVariable(req, "title")
+		return s.createErrs[title]
+	}
 	if !strings.Contains(req.Query, "children") || !strings.Contains(req.Query, "issue(id:") {
 		return nil
 	}
-	issueID, _ := stringVariable(req, "issueId")
-	return s.childFetchErrs[issueID]
+	// Fetching several issues' children fails as a whole when any of them does
+	for _, issueID := range s.childQueryIssues(req) {
+		if err := s.childFetchErrs[issueID]; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *Server) writeGraphQLError(w http.ResponseWriter, err error) {
@@ -164,7 +243,7 @@ func (s *Server) responseData(req linear.GraphQLRequest) json.RawMessage {
 	query := req.Query
 	switch {
 	case strings.Contains(query, "issues("):
-		return rawJSON(`{"issues":{"nodes":` + mustJSON(s.assignedIssueNodes()) + `}}`)
+		return rawJSON(mustJSON(s.assignedIssuesPages(req)))
 	case strings.Contains(query, "issueCreate"):
 		return rawJSON(`{"issueCreate":{"success":true,"issue":` + mustJSON(s.createIssue(req)) + `}}`)
 	case strings.Contains(query, "issueUpdate"):
@@ -172,11 +251,20 @@ func (s *Server) responseData(req linear.GraphQLRequest) json.RawMessage {
 		return rawJSON(`{"issueUpdate":{"success":true}}`)
 	case strings.Contains(query, "states("):
 		return rawJSON(`{"issue":{"team":{"states":{"nodes":[{"id":"state-completed"}]}}}}`)
+	case strings.Contains(query, "states {"):
+		return rawJSON(`{"issue":{"team":{"states":{"nodes":` + mustJSON(s.workflowStateNodes()) + `}}}}`)
 	case strings.Contains(query, "team") && strings.Contains(query, "viewer"):
 		return rawJSON(`{"issue":{"id":` + quote(stringVarOrDefault(req, "issueId", "issue-1")) + `,"team":{"id":"team-1"}},"viewer":` + mustJSON(s.currentUser) + `}`)
+	case strings.Contains(query, "issue(id:") && strings.Contains(query, "parent {"):
+		return rawJSON(`{"issue":` + mustJSON(s.issueByIdentifier(stringVarOrDefault(req, "issueId", ""))) + `}`)
 	case strings.Contains(query, "children") && strings.Contains(query, "issue(id:"):
-		issueID, _ := stringVariable(req, "issueId")
-		return rawJSON(`{"issue":{"children":{"nodes":` + mustJSON(s.childNodes(issueID)) + `}}}`)
+		data := map[string]any{}
+		for alias, issueID := range s.childQueryIssues(req) {
+			data[alias] = map[string]any{"children": map[string]any{"nodes": s.childNodes(issueID)}}
+		}
+		return rawJSON(mustJSON(data))
+	case strings.Contains(query, "issue(id:") && strings.Contains(query, "state {"):
+		return rawJSON(`{"issue":` + mustJSON(s.issueStateNode(stringVarOrDefault(req, "issueId", ""))) + `}`)
 	case strings.Contains(query, "viewer"):
 		return rawJSON(`{"viewer":` + mustJSON(s.currentUser) + `}`)
 	default:
@@ -207,6 +295,85 @@ func (s *Server) assignedIssueNodes() []map[string]any {
 	return nodes
 }
 
+// assignedIssuesPages answers each issues field in the request, by the name
+// it's asked for under, with the page of assigned issues it asks for
+func (s *Server) assignedIssuesPages(req linear.GraphQLRequest) map[string]any {
+	doc, err := gqlparser.LoadQuery(s.schema, req.Query)
+	if err != nil || len(doc.Operations) == 0 {
+		return nil
+	}
+	pages := map[string]any{}
+	for _, selection := range doc.Operations[0].SelectionSet {
+		field, ok := selection.(*ast.Field)
+		if !ok || field.Name != "issues" {
+			continue
+		}
+		first, _ := intArgument(req, field, "first")
+		after, _ := stringArgument(req, field, "after")
+		pages[field.Alias] = s.assignedIssuesPage(first, after, !idsOnly(field))
+	}
+	return pages
+}
+
+// idsOnly reports whether field asks for nothing of its issues but their IDs,
+// as a count of them does
+func idsOnly(field *ast.Field) bool {
+	for _, selection := range field.SelectionSet {
+		if nodes, ok := selection.(*ast.Field); ok && nodes.Name == "nodes" && len(nodes.SelectionSet) == 1 {
+			id, ok := nodes.SelectionSet[0].(*ast.Field)
+			return ok && id.Name == "id"
+		}
+	}
+	return false
+}
+
+// assignedIssuesPage is the page of up to first assigned issues after the
+// cursor, with cursors counting issues from the start of the list. The page
+// size set with SetPageSize caps pages of issues asked for in detail
+func (s *Server) assignedIssuesPage(first int, after string, detailed bool) map[string]any {
+	nodes := s.assignedIssueNodes()
+	start := 0
+	if after != "" {
+		fmt.Sscanf(after, "%d", &start)
+	}
+	start = min(start, len(nodes))
+	size := first
+	if size <= 0 {
+		size = len(nodes)
+	}
+	if s.pageSize > 0 && detailed {
+		size = min(size, s.pageSize)
+	}
+	end := min(start+size, len(nodes))
+	return map[string]any{
+		"nodes": nodes[start:end],
+		"pageInfo": map[string]any{
+			"hasNextPage": end < len(nodes),
+			"endCursor":   fmt.Sprint(end),
+		},
+	}
+}
+
+// childQueryIssues is the issue ID each issue field in a query for children
+// asks about, by the name it's answered under
+func (s *Server) childQueryIssues(req linear.GraphQLRequest) map[string]string {
+	doc, err := gqlparser.LoadQuery(s.schema, req.Query)
+	if err != nil || len(doc.Operations) == 0 {
+		return nil
+	}
+	issues := map[string]string{}
+	for _, selection := range doc.Operations[0].SelectionSet {
+		field, ok := selection.(*ast.Field)
+		if !ok || field.Name != "issue" {
+			continue
+		}
+		if arg := field.Arguments.ForName("id"); arg != nil && arg.Value.Kind == ast.Variable {
+			issues[field.Alias], _ = stringVariable(req, arg.Value.Raw)
+		}
+	}
+	return issues
+}
+
 func (s *Server) childNodes(parentID string) []map[string]any {
 	childIDs := s.childrenMap[parentID]
 	nodes := make([]map[string]any, 0, len(childIDs))
@@ -224,10 +391,16 @@ func (s *Server) issueNode(issue linear.Issue, includeParent bool) map[string]an
 		"identifier":  issue.Identifier,
 		"url":         issue.URL,
 		"priority":    issue.Priority,
+		"estimate":    issue.Estimate,
+		"cycle":       issue.Cycle,
 		"createdAt":   graphTime(issue.CreatedAt),
 		"updatedAt":   graphTime(issue.UpdatedAt),
 		"state":       issue.State,
 		"assignee":    issue.Assignee,
+		"labels": map[string]any{
+			"nodes": issueLabels(issue),
+		},
+		"project": issue.Project,
 		"children": map[string]any{
 			"nodes": s.childIDNodes(issue.ID),
 		},
@@ -242,6 +415,40 @@ func (s *Server) issueNode(issue linear.Issue, includeParent bool) map[string]an
 	return node
 }
 
+func issueLabels(issue linear.Issue) []linear.Label {
+	if issue.Labels == nil {
+		return []linear.Label{}
+	}
+	return issue.Labels
+}
+
+// issueByIdentifier is the node for one issue whoever it's assigned to, or
+// nil when there's no such issue
+func (s *Server) issueByIdentifier(identifier string) map[string]any {
+	for _, issueID := range s.issueOrder {
+		issue := s.issues[issueID]
+		if !strings.EqualFold(issue.Identifier, identifier) {
+			continue
+		}
+		node := s.issueNode(issue, false)
+		node["parent"] = nil
+		if issue.Parent != nil && issue.Parent.ID != "" {
+			node["parent"] = map[string]any{"id": issue.Parent.ID, "identifier": issue.Parent.Identifier}
+		}
+		return node
+	}
+	return nil
+}
+
+func (s *Server) issueStateNode(identifier string) map[string]any {
+	for _, issue := range s.issues {
+		if strings.EqualFold(issue.Identifier, identifier) {
+			return map[string]any{"identifier": issue.Identifier, "state": issue.State}
+		}
+	}
+	return nil
+}
+
 func (s *Server) childIDNodes(parentID string) []map[string]string {
 	childIDs := s.childrenMap[parentID]
 	nodes := make([]map[string]string, 0, len(childIDs))
@@ -254,6 +461,8 @@ func (s *Server) childIDNodes(parentID string) []map[string]string {
 func (s *Server) createIssue(req linear.GraphQLRequest) map[string]any {
 	parentID, _ := stringVariable(req, "parentId")
 	title, _ := stringVariable(req, "title")
+	description, _ := stringVariable(req, "description")
+	priority, _ := intVariable(req, "priority")
 	s.nextIssue++
 	identifier := fmt.Sprintf("TICK-%d", s.nextIssue)
 	if parent := s.issues[parentID]; parent.Identifier != "" {
@@ -264,6 +473,8 @@ func (s *Server) createIssue(req linear.GraphQLRequest) map[string]any {
 		ID:          fmt.Sprintf("fake-subtask-%d", s.nextIssue),
 		Identifier:  identifier,
 		Title:       title,
+		Description: description,
+		Priority:    priority,
 		State:       linear.State{ID: "state-todo", Name: "Todo", Type: "unstarted"},
 		Assignee:    s.currentUser,
 		CreatedAt:   time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC),
@@ -283,12 +494,41 @@ func (s *Server) updateIssue(req linear.GraphQLRequest) {
 		issue.Assignee = nil
 	} else if _, ok := stringVariable(req, "assigneeId"); ok {
 		issue.Assignee = s.currentUser
-	} else if _, ok := stringVariable(req, "stateId"); ok {
-		issue.State = linear.State{ID: "state-completed", Name: "Done", Type: "completed"}
+	} else if stateID, ok := stringVariable(req, "stateId"); ok {
+		for _, state := range WorkflowStates {
+			if state.ID == stateID {
+				issue.State = state
+			}
+		}
 	}
 	s.issues[issueID] = issue
 }
 
+// WorkflowStates are the team workflow states served by the fake, in board order
+var WorkflowStates = []linear.State{
+	{ID: "state-backlog", Name: "Backlog", Type: "backlog"},
+	{ID: "state-todo", Name: "Todo", Type: "unstarted"},
+	{ID: "state-started", Name: "In Progress", Type: "started"},
+	{ID: "state-review", Name: "In Review", Type: "started"},
+	{ID: "state-completed", Name: "Done", Type: "completed"},
+	{ID: "state-canceled", Name: "Canceled", Type: "canceled"},
+}
+
+// workflowStateNodes returns the states in reverse so clients must sort them
+func (s *Server) workflowStateNodes() []map[string]any {
+	nodes := make([]map[string]any, 0, len(WorkflowStates))
+	for i := len(WorkflowStates) - 1; i >= 0; i-- {
+		state := WorkflowStates[i]
+		nodes = append(nodes, map[string]any{
+			"id":       state.ID,
+			"name":     state.Name,
+			"type":     state.Type,
+			"position": float64(i),
+		})
+	}
+	return nodes
+}
+
 func stringVarOrDefault(req linear.GraphQLRequest, key, fallback string) string {
 	if value, ok := stringVariable(req, key); ok {
 		return value
@@ -305,6 +545,42 @@ func stringVariable(req linear.GraphQLRequest, key string) (string, bool) {
 	return value, ok
 }
 
+// intArgument is the value given for field's argument name, literally or by
+// a variable
+func intArgument(req linear.GraphQLRequest, field *ast.Field, name string) (int, bool) {
+	arg := field.Arguments.ForName(name)
+	if arg == nil {
+		return 0, false
+	}
+	if arg.Value.Kind == ast.Variable {
+		return intVariable(req, arg.Value.Raw)
+	}
+	value, err := strconv.Atoi(arg.Value.Raw)
+	return value, err == nil
+}
+
+// stringArgument is the value given for field's argument name, literally or
+// by a variable
+func stringArgument(req linear.GraphQLRequest, field *ast.Field, name string) (string, bool) {
+	arg := field.Arguments.ForName(name)
+	if arg == nil {
+		return "", false
+	}
+	if arg.Value.Kind == ast.Variable {
+		return stringVariable(req, arg.Value.Raw)
+	}
+	return arg.Value.Raw, true
+}
+
+func intVariable(req linear.GraphQLRequest, key string) (int, bool) {
+	vars, ok := req.Variables.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+	value, ok := vars[key].(float64)
+	return int(value), ok
+}
+
 func mustJSON(value any) string {
 	data, err := json.Marshal(value)
 	if err != nil {
@@ -0,0 +1,314 @@
+package linear
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	OAuthAuthorizeURL = "https://linear.app/oauth/authorize"
+	OAuthTokenURL     = "https://api.linear.app/oauth/token"
+
+	// OAuthRedirectURI is where the browser hands the sign-in back to sprout;
+	// it must be listed as a callback URL on the Linear OAuth application
+	OAuthRedirectURI = "http://localhost:47823/callback"
+
+	// OAuthLoginTimeout is how long sprout auth linear waits for the browser
+	OAuthLoginTimeout = 5 * time.Minute
+)
+
+// ErrNotSignedIn is returned when OAuth is configured but sprout auth linear
+// hasn't been run, or its sign-in was removed
+var ErrNotSignedIn = errors.New("not signed in to Linear; run sprout auth linear")
+
+// Token is an OAuth sign-in: a short-lived access token and the refresh token
+// that replaces it
+type Token struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	ExpiresAt    time.Time `json:"expiresAt,omitempty"` // zero when Linear didn't say
+}
+
+// expiresSoon reports whether the access token should be refreshed before use,
+// leaving a minute's grace for the request itself
+func (t *Token) expiresSoon(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && now.Add(time.Minute).After(t.ExpiresAt)
+}
+
+// TokenStore keeps the OAuth sign-in between runs
+type TokenStore interface {
+	LoadToken() (*Token, error) // nil when nothing is stored
+	SaveToken(token *Token) error
+	DeleteToken() error
+}
+
+// AuthenticatorInterface signs sprout in to Linear and reports on the sign-in
+type AuthenticatorInterface interface {
+	Login(output io.Writer) (*Token, error)
+	Logout() error
+	StoredToken() (*Token, error)
+}
+
+// OAuth signs in to Linear through the browser with PKCE, and keeps the
+// resulting access token fresh for the GraphQL client
+type OAuth struct {
+	ClientID     string
+	AuthorizeURL string
+	TokenURL     string
+	RedirectURI  string // a port of 0 listens on any free port, for tests
+	Store        TokenStore
+	HTTPClient   *http.Client
+	OpenBrowser  func(url string) error
+	LoginTimeout time.Duration
+
+	now   func() time.Time
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewOAuth signs in with the Linear OAuth application clientID, keeping the
+// sign-in in store
+func NewOAuth(clientID string, store TokenStore) *OAuth {
+	return &OAuth{
+		ClientID:     clientID,
+		AuthorizeURL: OAuthAuthorizeURL,
+		TokenURL:     OAuthTokenURL,
+		RedirectURI:  OAuthRedirectURI,
+		Store:        store,
+		HTTPClient:   &http.Client{Timeout: DefaultTimeout},
+		OpenBrowser:  OpenBrowser,
+		LoginTimeout: OAuthLoginTimeout,
+		now:          time.Now,
+	}
+}
+
+// Login opens Linear's consent page in the browser, waits for it to redirect
+// back with a code, and stores the tokens the code is exchanged for
+func (o *OAuth) Login(output io.Writer) (*Token, error) {
+	if o.ClientID == "" {
+		return nil, fmt.Errorf("no Linear OAuth client ID is configured")
+	}
+	redirect, err := url.Parse(o.RedirectURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect URI %q: %w", o.RedirectURI, err)
+	}
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the sign-in on %s: %w", redirect.Host, err)
+	}
+	defer listener.Close()
+	if redirect.Port() == "0" {
+		redirect.Host = listener.Addr().String()
+	}
+	redirectURI := redirect.String()
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	authorizeURL := o.AuthorizeURL + "?" + url.Values{
+		"client_id":             {o.ClientID},
+		"redirect_uri":          {redirectURI},
+		"response_type":         {"code"},
+		"scope":                 {"read,write"},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+
+	type callback struct {
+		code string
+		err  error
+	}
+	callbacks := make(chan callback, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != redirect.Path {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		var result callback
+		switch {
+		case query.Get("state") != state:
+			result.err = fmt.Errorf("the sign-in didn't come from this request, so it was ignored")
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("Linear declined the sign-in: %s", query.Get("error"))
+		default:
+			result.code = query.Get("code")
+		}
+		if result.err != nil {
+			fmt.Fprintf(w, "Signing in to Linear failed: %v\n", result.err)
+		} else {
+			fmt.Fprintln(w, "Signed in to Linear. You can close this tab and return to the terminal.")
+		}
+		select {
+		case callbacks <- result:
+		default:
+		}
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Fprintln(output, "Opening Linear in your browser to sign in...")
+	if err := o.OpenBrowser(authorizeURL); err != nil {
+		fmt.Fprintf(output, "Couldn't open a browser. Visit this URL to continue:\n%s\n", authorizeURL)
+	}
+
+	var result callback
+	select {
+	case result = <-callbacks:
+	case <-time.After(o.LoginTimeout):
+		return nil, fmt.Errorf("gave up waiting for the browser after %s", o.LoginTimeout)
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	token, err := o.requestToken(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {result.code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {o.ClientID},
+		"code_verifier": {verifier},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := o.Store.SaveToken(token); err != nil {
+		return nil, fmt.Errorf("failed to store the sign-in: %w", err)
+	}
+
+	o.mu.Lock()
+	o.token = token
+	o.mu.Unlock()
+	return token, nil
+}
+
+// Logout forgets the stored sign-in
+func (o *OAuth) Logout() error {
+	o.mu.Lock()
+	o.token = nil
+	o.mu.Unlock()
+	return o.Store.DeleteToken()
+}
+
+// StoredToken is the sign-in kept between runs, or nil when there isn't one
+func (o *OAuth) StoredToken() (*Token, error) {
+	return o.Store.LoadToken()
+}
+
+// AccessToken is a token to send to Linear, refreshed first when it's about
+// to expire or when force says Linear has already rejected it
+func (o *OAuth) AccessToken(force bool) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token == nil {
+		token, err := o.Store.LoadToken()
+		if err != nil {
+			return "", fmt.Errorf("failed to read the Linear sign-in: %w", err)
+		}
+		if token == nil {
+			return "", ErrNotSignedIn
+		}
+		o.token = token
+	}
+	if (force || o.token.expiresSoon(o.now())) && o.token.RefreshToken != "" {
+		refreshed, err := o.requestToken(url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {o.token.RefreshToken},
+			"client_id":     {o.ClientID},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to refresh the Linear sign-in, run sprout auth linear again: %w", err)
+		}
+		// Linear may keep the refresh token the same and leave it out
+		if refreshed.RefreshToken == "" {
+			refreshed.RefreshToken = o.token.RefreshToken
+		}
+		if err := o.Store.SaveToken(refreshed); err != nil {
+			return "", fmt.Errorf("failed to store the refreshed sign-in: %w", err)
+		}
+		o.token = refreshed
+	}
+	return o.token.AccessToken, nil
+}
+
+// requestToken posts form to Linear's token endpoint
+func (o *OAuth) requestToken(form url.Values) (*Token, error) {
+	req, err := http.NewRequestWithContext(context.Background(), "POST", o.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := o.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Linear: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var granted struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &granted); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if granted.AccessToken == "" {
+		return nil, fmt.Errorf("Linear's token response had no access token")
+	}
+	token := &Token{AccessToken: granted.AccessToken, RefreshToken: granted.RefreshToken}
+	if granted.ExpiresIn > 0 {
+		token.ExpiresAt = o.now().Add(time.Duration(granted.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func randomString(bytes int) (string, error) {
+	buf := make([]byte, bytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate a random value: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// OpenBrowser shows url in the user's default browser
+func OpenBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
@@ -0,0 +1,156 @@
+package linear_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sprout/pkg/linear"
+)
+
+// tokenEndpoint serves Linear's OAuth token endpoint, answering each grant
+// with the next access token in turn
+func tokenEndpoint(t *testing.T, forms *[]url.Values, accessTokens ...string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse token request: %v", err)
+		}
+		*forms = append(*forms, r.PostForm)
+		if len(*forms) > len(accessTokens) {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":%q,"refresh_token":"refresh-%d","expires_in":3600,"token_type":"Bearer"}`, accessTokens[len(*forms)-1], len(*forms))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOAuthLoginExchangesTheBrowserCodeAndStoresTheToken(t *testing.T) {
+	var forms []url.Values
+	tokens := tokenEndpoint(t, &forms, "access-1")
+	store := &linear.FileTokenStore{Path: filepath.Join(t.TempDir(), "sprout", "linear-oauth.json")}
+
+	oauth := linear.NewOAuth("client-id", store)
+	oauth.TokenURL = tokens.URL
+	oauth.RedirectURI = "http://127.0.0.1:0/callback"
+	var authorizeURL *url.URL
+	oauth.OpenBrowser = func(page string) error {
+		authorizeURL, _ = url.Parse(page)
+		query := authorizeURL.Query()
+		// Play the part of the user approving sprout in the browser
+		go func() {
+			resp, err := http.Get(query.Get("redirect_uri") + "?code=the-code&state=" + query.Get("state"))
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+
+	token, err := oauth.Login(io.Discard)
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if token.AccessToken != "access-1" || token.RefreshToken != "refresh-1" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+
+	query := authorizeURL.Query()
+	if query.Get("client_id") != "client-id" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("expected a PKCE authorization request, got %s", authorizeURL)
+	}
+	form := forms[0]
+	if form.Get("grant_type") != "authorization_code" || form.Get("code") != "the-code" || form.Get("code_verifier") == "" || form.Get("redirect_uri") != query.Get("redirect_uri") {
+		t.Fatalf("unexpected token request: %v", form)
+	}
+
+	stored, err := store.LoadToken()
+	if err != nil || stored == nil || stored.RefreshToken != "refresh-1" {
+		t.Fatalf("expected the token stored, got %+v, %v", stored, err)
+	}
+	info, err := os.Stat(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("expected the token file readable only by its owner, got %v", info.Mode().Perm())
+	}
+}
+
+func TestOAuthLoginRejectsAForeignState(t *testing.T) {
+	oauth := linear.NewOAuth("client-id", &linear.FileTokenStore{Path: filepath.Join(t.TempDir(), "token.json")})
+	oauth.RedirectURI = "http://127.0.0.1:0/callback"
+	oauth.OpenBrowser = func(page string) error {
+		authorizeURL, _ := url.Parse(page)
+		go func() {
+			resp, err := http.Get(authorizeURL.Query().Get("redirect_uri") + "?code=the-code&state=forged")
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+
+	if _, err := oauth.Login(io.Discard); err == nil {
+		t.Fatal("expected a sign-in with the wrong state to be refused")
+	}
+}
+
+func TestOAuthClientRefreshesTheAccessToken(t *testing.T) {
+	var forms []url.Values
+	tokens := tokenEndpoint(t, &forms, "access-2", "access-3")
+	store := &linear.FileTokenStore{Path: filepath.Join(t.TempDir(), "token.json")}
+	if err := store.SaveToken(&linear.Token{AccessToken: "access-1", RefreshToken: "refresh-0", ExpiresAt: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	var authorizations []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		// Linear revokes access-2 early, which a refresh should recover from
+		if r.Header.Get("Authorization") != "Bearer access-3" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"viewer":{"id":"u1","name":"Test User","email":"test@example.com"}}}`)
+	}))
+	t.Cleanup(api.Close)
+
+	oauth := linear.NewOAuth("client-id", store)
+	oauth.TokenURL = tokens.URL
+	client := linear.NewOAuthClientWithEndpoint(oauth, api.URL, nil)
+
+	user, err := client.GetCurrentUser()
+	if err != nil {
+		t.Fatalf("GetCurrentUser failed: %v", err)
+	}
+	if user.Name != "Test User" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+	if fmt.Sprint(authorizations) != "[Bearer access-2 Bearer access-3]" {
+		t.Fatalf("expected the expired token refreshed before use and again after a 401, got %v", authorizations)
+	}
+	if forms[0].Get("grant_type") != "refresh_token" || forms[0].Get("refresh_token") != "refresh-0" || forms[1].Get("refresh_token") != "refresh-1" {
+		t.Fatalf("unexpected refresh requests: %v", forms)
+	}
+	if stored, _ := store.LoadToken(); stored.AccessToken != "access-3" {
+		t.Fatalf("expected the latest token stored, got %+v", stored)
+	}
+}
+
+func TestOAuthClientWithoutASignIn(t *testing.T) {
+	oauth := linear.NewOAuth("client-id", &linear.FileTokenStore{Path: filepath.Join(t.TempDir(), "token.json")})
+	client := linear.NewOAuthClientWithEndpoint(oauth, "http://127.0.0.1:0", nil)
+
+	if _, err := client.GetCurrentUser(); err == nil || !errors.Is(err, linear.ErrNotSignedIn) {
+		t.Fatalf("expected a not signed in error, got %v", err)
+	}
+}
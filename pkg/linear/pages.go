@@ -0,0 +1,27 @@
+package linear
+
+// AssignedIssuesPageSize is how many assigned issues are asked for at a time
+const AssignedIssuesPageSize = 50
+
+// IssuesPage is how far loading the assigned issues has got
+type IssuesPage struct {
+	Issues []Issue // everything loaded so far, folded as GetAssignedIssues folds it
+	Loaded int     // how many assigned issues have been loaded, folded children included
+	Total  int     // how many there are in all, or 0 when that isn't known yet
+}
+
+// IssueStreamer is a client that can hand over assigned issues a page at a
+// time while the rest load
+type IssueStreamer interface {
+	StreamAssignedIssues(onPage func(IssuesPage)) ([]Issue, error)
+}
+
+// StreamAssignedIssues returns the issues assigned to the current user,
+// calling onPage with what's loaded so far as pages arrive if client loads
+// them a page at a time. Clients that don't are asked for them all at once
+func StreamAssignedIssues(client LinearClientInterface, onPage func(IssuesPage)) ([]Issue, error) {
+	if streamer, ok := client.(IssueStreamer); ok {
+		return streamer.StreamAssignedIssues(onPage)
+	}
+	return client.GetAssignedIssues()
+}
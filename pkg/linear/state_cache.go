@@ -0,0 +1,104 @@
+package linear
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// IssueStateTTL is how long a fetched issue state is trusted before refetching
+const IssueStateTTL = 5 * time.Minute
+
+// IssueStateCache remembers recently fetched issue states so listing
+// worktrees repeatedly doesn't query Linear for every linked ticket
+type IssueStateCache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+type issueStateCacheFile struct {
+	Issues map[string]cachedIssueState `json:"issues"`
+}
+
+type cachedIssueState struct {
+	State     State     `json:"state"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+func NewIssueStateCache() *IssueStateCache {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	return NewIssueStateCacheWithPath(filepath.Join(cacheDir, "sprout", "issue-state-cache.json"), IssueStateTTL)
+}
+
+func NewIssueStateCacheWithPath(path string, ttl time.Duration) *IssueStateCache {
+	if path == "" {
+		return nil
+	}
+	return &IssueStateCache{
+		path: path,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// Get returns the cached state for identifier if it is still fresh
+func (c *IssueStateCache) Get(identifier string) (State, bool) {
+	if c == nil || identifier == "" {
+		return State{}, false
+	}
+	cacheFile, err := c.load()
+	if err != nil {
+		return State{}, false
+	}
+	cached, ok := cacheFile.Issues[identifier]
+	if !ok || c.now().Sub(cached.FetchedAt) > c.ttl {
+		return State{}, false
+	}
+	return cached.State, true
+}
+
+// Remember stores freshly fetched states, keyed by issue identifier
+func (c *IssueStateCache) Remember(states map[string]State) {
+	if c == nil || len(states) == 0 {
+		return
+	}
+	cacheFile, err := c.load()
+	if err != nil {
+		cacheFile = issueStateCacheFile{Issues: make(map[string]cachedIssueState)}
+	}
+	for identifier, state := range states {
+		cacheFile.Issues[identifier] = cachedIssueState{State: state, FetchedAt: c.now()}
+	}
+	_ = c.save(cacheFile)
+}
+
+func (c *IssueStateCache) load() (issueStateCacheFile, error) {
+	cacheFile := issueStateCacheFile{Issues: make(map[string]cachedIssueState)}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return cacheFile, err
+	}
+	if err := json.Unmarshal(data, &cacheFile); err != nil {
+		return cacheFile, err
+	}
+	if cacheFile.Issues == nil {
+		cacheFile.Issues = make(map[string]cachedIssueState)
+	}
+	return cacheFile, nil
+}
+
+func (c *IssueStateCache) save(cacheFile issueStateCacheFile) error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cacheFile, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
@@ -0,0 +1,98 @@
+package linear
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sprout/pkg/keychain"
+)
+
+// keychainAccount names the sign-in in the system keychain
+const keychainAccount = "linear-oauth"
+
+// NewTokenStore keeps the sign-in in the system keychain where sprout knows
+// how to reach one (security on macOS, secret-tool on Linux), and otherwise in
+// a file only the user can read
+func NewTokenStore() TokenStore {
+	if keychain.Available() {
+		return &KeychainTokenStore{}
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = os.TempDir()
+	}
+	return &FileTokenStore{Path: filepath.Join(configDir, "sprout", "linear-oauth.json")}
+}
+
+// FileTokenStore keeps the sign-in in a JSON file readable only by its owner
+type FileTokenStore struct {
+	Path string
+}
+
+func (s *FileTokenStore) LoadToken() (*Token, error) {
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeToken(data)
+}
+
+func (s *FileTokenStore) SaveToken(token *Token) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return err
+	}
+	// Write beside the old file and swap it in, so a crash can't lose the sign-in
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+func (s *FileTokenStore) DeleteToken() error {
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// KeychainTokenStore keeps the sign-in in the system keychain
+type KeychainTokenStore struct{}
+
+func (s *KeychainTokenStore) LoadToken() (*Token, error) {
+	data, err := keychain.Get(keychainAccount)
+	if err != nil || data == "" {
+		return nil, err
+	}
+	return decodeToken([]byte(data))
+}
+
+func (s *KeychainTokenStore) SaveToken(token *Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return keychain.Set(keychainAccount, "sprout Linear sign-in", string(data))
+}
+
+func (s *KeychainTokenStore) DeleteToken() error {
+	return keychain.Delete(keychainAccount)
+}
+
+func decodeToken(data []byte) (*Token, error) {
+	var token Token
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(data))), &token); err != nil {
+		return nil, fmt.Errorf("the stored Linear sign-in is unreadable, run sprout auth linear again: %w", err)
+	}
+	return &token, nil
+}
@@ -0,0 +1,109 @@
+package metadata
+
+import (
+	"os"
+	"time"
+)
+
+// Allocation is what a worktree is given so its dev servers and containers
+// don't collide with those of other worktrees
+type Allocation struct {
+	Port           int       `json:"port"`                     // first of the worktree's block of ports
+	ComposeProject string    `json:"composeProject,omitempty"` // docker compose project name
+	Path           string    `json:"path,omitempty"`           // the worktree holding it
+	AllocatedAt    time.Time `json:"allocatedAt"`
+}
+
+// Allocate returns branch's allocation for its worktree at worktreePath,
+// giving it one the first time it's asked for. pick chooses the first port
+// of a free block, given the ports every known repository's worktrees hold,
+// since ports are shared by all of them. The allocation is kept until the
+// worktree is pruned, or until it's found gone from disk when another
+// worktree asks for one, as when it was removed outside sprout
+func (s *Store) Allocate(branch, worktreePath, composeProject string, pick func(used map[int]bool) (int, error)) (Allocation, error) {
+	if s == nil {
+		port, err := pick(nil)
+		if err != nil {
+			return Allocation{}, err
+		}
+		return Allocation{Port: port, ComposeProject: composeProject, Path: worktreePath}, nil
+	}
+
+	var allocation Allocation
+	err := s.updateFile(func(file storeFile) error {
+		if file.Repos[s.repoRoot] == nil {
+			file.Repos[s.repoRoot] = &repoMetadata{}
+		}
+		repo := file.Repos[s.repoRoot]
+		if held, ok := repo.Allocations[branch]; ok {
+			allocation = held
+			return nil
+		}
+
+		used := make(map[int]bool)
+		for _, other := range file.Repos {
+			for heldBy, held := range other.Allocations {
+				if worktreeGone(other.allocationPath(heldBy, held)) {
+					delete(other.Allocations, heldBy)
+					continue
+				}
+				used[held.Port] = true
+			}
+		}
+		port, err := pick(used)
+		if err != nil {
+			return err
+		}
+		allocation = Allocation{Port: port, ComposeProject: composeProject, Path: worktreePath, AllocatedAt: s.now()}
+		if repo.Allocations == nil {
+			repo.Allocations = make(map[string]Allocation)
+		}
+		repo.Allocations[branch] = allocation
+		return nil
+	})
+	if err != nil {
+		return Allocation{}, err
+	}
+	return allocation, nil
+}
+
+// allocationPath is the worktree holding branch's allocation, falling back
+// to where the branch's worktree was recorded for allocations made before
+// their path was kept
+func (repo *repoMetadata) allocationPath(branch string, allocation Allocation) string {
+	if allocation.Path != "" {
+		return allocation.Path
+	}
+	for i := len(repo.Worktrees) - 1; i >= 0; i-- {
+		if record := repo.Worktrees[i]; record.Branch == branch && record.Active() {
+			return record.Path
+		}
+	}
+	return ""
+}
+
+// worktreeGone is whether the worktree at path no longer exists. An unknown
+// path is assumed to still be held
+func worktreeGone(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return os.IsNotExist(err)
+}
+
+// Allocations returns each of the repository's worktrees' allocations, by branch
+func (s *Store) Allocations() map[string]Allocation {
+	if s == nil {
+		return nil
+	}
+	file, err := s.load()
+	if err != nil {
+		return nil
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil {
+		return nil
+	}
+	return repo.Allocations
+}
@@ -0,0 +1,77 @@
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DetachedRun is a command sprout create --detach left running for a
+// worktree, so pruning the worktree can stop it
+type DetachedRun struct {
+	PID          int       `json:"pid,omitempty"`          // leads the process group, when run in the background
+	ProcessStart string    `json:"processStart,omitempty"` // when that process started, so a reused PID isn't stopped
+	Session      string    `json:"session,omitempty"`      // the tmux session, when run in one
+	Command      string    `json:"command"`
+	Log          string    `json:"log,omitempty"`
+	StartedAt    time.Time `json:"startedAt"`
+}
+
+// RecordDetached notes the command left running for branch's worktree,
+// replacing any before it
+func (s *Store) RecordDetached(branch string, run DetachedRun) {
+	if s == nil || branch == "" {
+		return
+	}
+	if run.StartedAt.IsZero() {
+		run.StartedAt = s.now()
+	}
+	_ = s.update(func(repo *repoMetadata) {
+		if repo.Detached == nil {
+			repo.Detached = make(map[string]DetachedRun)
+		}
+		repo.Detached[branch] = run
+	})
+}
+
+// Detached returns the command left running for branch's worktree, if any
+func (s *Store) Detached(branch string) (DetachedRun, bool) {
+	if s == nil {
+		return DetachedRun{}, false
+	}
+	file, err := s.load()
+	if err != nil {
+		return DetachedRun{}, false
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil {
+		return DetachedRun{}, false
+	}
+	run, ok := repo.Detached[branch]
+	return run, ok
+}
+
+// ForgetDetached drops the record of the command left running for branch
+func (s *Store) ForgetDetached(branch string) {
+	if s == nil {
+		return
+	}
+	if _, ok := s.Detached(branch); !ok {
+		return
+	}
+	_ = s.update(func(repo *repoMetadata) {
+		delete(repo.Detached, branch)
+	})
+}
+
+// DetachedLogPath is where the output of a command detached for branch goes:
+// beside the metadata file, or the temporary directory without one
+func (s *Store) DetachedLogPath(branch string) string {
+	flatten := strings.NewReplacer("/", "-", string(filepath.Separator), "-")
+	if s == nil {
+		return filepath.Join(os.TempDir(), "sprout-"+flatten.Replace(branch)+".log")
+	}
+	name := flatten.Replace(filepath.Base(s.repoRoot) + "-" + branch)
+	return filepath.Join(filepath.Dir(s.path), "logs", name+".log")
+}
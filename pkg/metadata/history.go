@@ -0,0 +1,106 @@
+package metadata
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Kinds of entry in a repository's history
+const (
+	HistoryWorktreeCreated  = "worktreeCreated"
+	HistoryWorktreePruned   = "worktreePruned"
+	HistoryWorktreeRenamed  = "worktreeRenamed"
+	HistoryWorktreeArchived = "worktreeArchived"
+	HistoryWorktreeRestored = "worktreeRestored"
+	HistoryBranchCreated    = "branchCreated"
+	HistoryCommandRun       = "commandRun"
+)
+
+// HistoryEvent is one thing sprout did in a repository, as sprout history
+// lists it
+type HistoryEvent struct {
+	Time    time.Time `json:"time"`
+	Repo    string    `json:"repo"`
+	Kind    string    `json:"kind"`
+	Branch  string    `json:"branch,omitempty"`
+	Path    string    `json:"path,omitempty"`
+	Issue   string    `json:"issue,omitempty"`
+	Command string    `json:"command,omitempty"` // what ran, for commandRun
+	Detail  string    `json:"detail,omitempty"`  // such as the old name of a renamed branch
+}
+
+// historyPath is the log shared by all repos, kept beside the metadata file.
+// Entries are only ever appended, one JSON object per line, so concurrent
+// sprout processes can't lose each other's writes
+func (s *Store) historyPath() string {
+	return filepath.Join(filepath.Dir(s.path), "history.jsonl")
+}
+
+// LogEvent appends event to the repository's history, filling in the time,
+// the repository and the issue the branch is for when they're left empty.
+// History is best effort: a failed write is dropped rather than failing the
+// action it describes
+func (s *Store) LogEvent(event HistoryEvent) {
+	if s == nil || event.Kind == "" {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = s.now()
+	}
+	if event.Repo == "" {
+		event.Repo = s.repoRoot
+	}
+	if event.Issue == "" {
+		event.Issue = IssueFromBranch(event.Branch)
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.historyPath()), 0755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(s.historyPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write(append(line, '\n'))
+}
+
+// History returns the repository's events at or after since, oldest first;
+// a zero since returns them all. Lines that don't parse are skipped
+func (s *Store) History(since time.Time) []HistoryEvent {
+	if s == nil {
+		return nil
+	}
+	f, err := os.Open(s.historyPath())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var events []HistoryEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event HistoryEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if event.Repo != s.repoRoot || event.Time.Before(since) {
+			continue
+		}
+		events = append(events, event)
+	}
+	// Processes running side by side may append a little out of order
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events
+}
@@ -0,0 +1,29 @@
+package metadata
+
+// SetIssueSort remembers order as how the TUI last sorted the repository's
+// issue tree, so it opens sorted the same way
+func (s *Store) SetIssueSort(order string) error {
+	if s == nil {
+		return nil
+	}
+	return s.update(func(repo *repoMetadata) {
+		repo.IssueSort = order
+	})
+}
+
+// IssueSort returns the order SetIssueSort last remembered, or "" when the
+// issue tree hasn't been sorted in the TUI
+func (s *Store) IssueSort() string {
+	if s == nil {
+		return ""
+	}
+	file, err := s.load()
+	if err != nil {
+		return ""
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil {
+		return ""
+	}
+	return repo.IssueSort
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package metadata
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile blocks until this process holds an exclusive lock on f
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
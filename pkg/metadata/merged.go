@@ -0,0 +1,39 @@
+package metadata
+
+import "sort"
+
+// SeeMerged remembers which of the repository's worktree branches have
+// merged PRs, returning those in merged that hadn't when it was last called,
+// so merges that happened between sessions can be pointed out once
+func (s *Store) SeeMerged(branches, merged []string) []string {
+	if s == nil {
+		return nil
+	}
+	var newlyMerged []string
+	_ = s.update(func(repo *repoMetadata) {
+		seen := make(map[string]bool, len(repo.Merged))
+		for _, branch := range repo.Merged {
+			seen[branch] = true
+		}
+		remembered := make(map[string]bool, len(merged))
+		for _, branch := range merged {
+			if !seen[branch] {
+				newlyMerged = append(newlyMerged, branch)
+			}
+			remembered[branch] = true
+		}
+		// A branch keeps its place while it has a worktree, even when its PR
+		// couldn't be looked up this time, and drops off once it's pruned
+		for _, branch := range branches {
+			if seen[branch] {
+				remembered[branch] = true
+			}
+		}
+		repo.Merged = repo.Merged[:0]
+		for branch := range remembered {
+			repo.Merged = append(repo.Merged, branch)
+		}
+		sort.Strings(repo.Merged)
+	})
+	return newlyMerged
+}
@@ -0,0 +1,39 @@
+package metadata
+
+import "strings"
+
+// SetNote keeps a short free-form note on branch's worktree, such as what
+// it's waiting on, so the context survives switching between many. An empty
+// note removes it
+func (s *Store) SetNote(branch, note string) error {
+	if s == nil || branch == "" {
+		return nil
+	}
+	note = strings.TrimSpace(note)
+	return s.update(func(repo *repoMetadata) {
+		if note == "" {
+			delete(repo.Notes, branch)
+			return
+		}
+		if repo.Notes == nil {
+			repo.Notes = make(map[string]string)
+		}
+		repo.Notes[branch] = note
+	})
+}
+
+// Notes returns the note kept on each branch with SetNote
+func (s *Store) Notes() map[string]string {
+	if s == nil {
+		return nil
+	}
+	file, err := s.load()
+	if err != nil {
+		return nil
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil {
+		return nil
+	}
+	return repo.Notes
+}
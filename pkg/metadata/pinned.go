@@ -0,0 +1,48 @@
+package metadata
+
+import "time"
+
+// RecordPinned notes that branch's worktree is pinned with sprout pin, so
+// nothing removes it until it's unpinned
+func (s *Store) RecordPinned(branch string) error {
+	if s == nil || branch == "" {
+		return nil
+	}
+	return s.update(func(repo *repoMetadata) {
+		if repo.Pinned == nil {
+			repo.Pinned = make(map[string]time.Time)
+		}
+		if _, ok := repo.Pinned[branch]; !ok {
+			repo.Pinned[branch] = s.now()
+		}
+	})
+}
+
+// ForgetPinned drops the pin on branch's worktree
+func (s *Store) ForgetPinned(branch string) error {
+	if s == nil {
+		return nil
+	}
+	if _, ok := s.Pinned()[branch]; !ok {
+		return nil
+	}
+	return s.update(func(repo *repoMetadata) {
+		delete(repo.Pinned, branch)
+	})
+}
+
+// Pinned returns when each pinned branch was pinned
+func (s *Store) Pinned() map[string]time.Time {
+	if s == nil {
+		return nil
+	}
+	file, err := s.load()
+	if err != nil {
+		return nil
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil {
+		return nil
+	}
+	return repo.Pinned
+}
@@ -0,0 +1,41 @@
+package metadata
+
+import "time"
+
+// LastCommand is the last command sprout ran in a worktree, which sprout
+// resume runs again
+type LastCommand struct {
+	Command []string  `json:"command"`
+	Path    string    `json:"path"`
+	RanAt   time.Time `json:"ranAt"`
+}
+
+// RecordLastCommand notes that command was just run in branch's worktree at
+// path, replacing the one before it
+func (s *Store) RecordLastCommand(branch, path string, command []string) {
+	if s == nil || branch == "" || len(command) == 0 {
+		return
+	}
+	_ = s.update(func(repo *repoMetadata) {
+		if repo.LastCommands == nil {
+			repo.LastCommands = make(map[string]LastCommand)
+		}
+		repo.LastCommands[branch] = LastCommand{Command: append([]string(nil), command...), Path: path, RanAt: s.now()}
+	})
+}
+
+// LastCommands returns the last command run in each branch's worktree
+func (s *Store) LastCommands() map[string]LastCommand {
+	if s == nil {
+		return nil
+	}
+	file, err := s.load()
+	if err != nil {
+		return nil
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil {
+		return nil
+	}
+	return repo.LastCommands
+}
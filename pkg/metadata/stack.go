@@ -0,0 +1,33 @@
+package metadata
+
+// RecordParent notes that branch was created from parent rather than the
+// default branch, so the branches built on one another can be shown and
+// restacked together
+func (s *Store) RecordParent(branch, parent string) {
+	if s == nil || branch == "" || parent == "" || branch == parent {
+		return
+	}
+	_ = s.update(func(repo *repoMetadata) {
+		if repo.Parents == nil {
+			repo.Parents = make(map[string]string)
+		}
+		repo.Parents[branch] = parent
+	})
+}
+
+// Parents returns the branch each branch recorded with RecordParent was
+// created from
+func (s *Store) Parents() map[string]string {
+	if s == nil {
+		return nil
+	}
+	file, err := s.load()
+	if err != nil {
+		return nil
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil {
+		return nil
+	}
+	return repo.Parents
+}
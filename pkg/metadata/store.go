@@ -0,0 +1,570 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WorktreeRecord captures the lifecycle of a single worktree created by sprout
+type WorktreeRecord struct {
+	Branch    string     `json:"branch"`
+	Path      string     `json:"path"`
+	Issue     string     `json:"issue,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	PrunedAt  *time.Time `json:"prunedAt,omitempty"`
+}
+
+// Active reports whether the worktree has not been pruned yet
+func (r WorktreeRecord) Active() bool {
+	return r.PrunedAt == nil
+}
+
+// Store persists worktree metadata for a repository in a JSON file shared by all repos
+type Store struct {
+	repoRoot string
+	path     string
+	now      func() time.Time
+}
+
+type storeFile struct {
+	Repos map[string]*repoMetadata `json:"repos"`
+}
+
+type repoMetadata struct {
+	Worktrees      []WorktreeRecord           `json:"worktrees"`
+	DiskUsage      map[string]DiskUsageRecord `json:"diskUsage,omitempty"`
+	SparseProfiles map[string][]string        `json:"sparseProfiles,omitempty"`
+	BranchHistory  map[string]BranchUse       `json:"branchHistory,omitempty"`
+	IssueTree      *IssueTreeState            `json:"issueTree,omitempty"`
+	Detached       map[string]DetachedRun     `json:"detached,omitempty"`     // by branch
+	Pinned         map[string]time.Time       `json:"pinned,omitempty"`       // by branch, when it was pinned
+	Parents        map[string]string          `json:"parents,omitempty"`      // by branch, the branch it was created from
+	Notes          map[string]string          `json:"notes,omitempty"`        // by branch, what sprout note says about it
+	LastCommands   map[string]LastCommand     `json:"lastCommands,omitempty"` // by branch, what sprout resume runs again
+	Merged         []string                   `json:"merged,omitempty"`       // branches whose PRs had merged when the TUI last listed worktrees
+	Allocations    map[string]Allocation      `json:"allocations,omitempty"`  // by branch, the ports and compose project its worktree holds
+	IssueSort      string                     `json:"issueSort,omitempty"`    // the order the TUI last sorted the issue tree in
+}
+
+// IssueTreeState is how the TUI's issue tree was left, so the next session
+// can open it the same way
+type IssueTreeState struct {
+	Expanded []string `json:"expanded,omitempty"` // IDs of issues showing their children
+	Selected string   `json:"selected,omitempty"` // ID of the selected issue, empty when the input was
+}
+
+// BranchUse counts how often a branch was created or resumed from the TUI
+type BranchUse struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// DiskUsageRecord is the last measured size of a worktree directory
+type DiskUsageRecord struct {
+	Bytes      int64     `json:"bytes"`
+	MeasuredAt time.Time `json:"measuredAt"`
+}
+
+var issueIdentifierPattern = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9]*-[0-9]+)(?:[-/]|$)`)
+
+func NewStore(repoRoot string) *Store {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	return NewStoreWithPath(repoRoot, filepath.Join(configDir, "sprout", "metadata.json"))
+}
+
+func NewStoreWithPath(repoRoot, path string) *Store {
+	if path == "" {
+		return nil
+	}
+	return &Store{
+		repoRoot: repoRoot,
+		path:     path,
+		now:      time.Now,
+	}
+}
+
+// SetClock overrides the time source used when recording events
+func (s *Store) SetClock(now func() time.Time) {
+	if s == nil || now == nil {
+		return
+	}
+	s.now = now
+}
+
+// IssueFromBranch extracts a Linear-style identifier (e.g. ENG-123) from the start of a branch name
+func IssueFromBranch(branch string) string {
+	match := issueIdentifierPattern.FindStringSubmatch(branch)
+	if match == nil {
+		return ""
+	}
+	return strings.ToUpper(match[1])
+}
+
+// RecordCreated notes that a worktree was created for branch at path
+func (s *Store) RecordCreated(branch, path string) {
+	if s == nil || branch == "" {
+		return
+	}
+
+	_ = s.update(func(repo *repoMetadata) {
+		for _, record := range repo.Worktrees {
+			if record.Branch == branch && record.Active() {
+				return
+			}
+		}
+		repo.Worktrees = append(repo.Worktrees, WorktreeRecord{
+			Branch:    branch,
+			Path:      path,
+			Issue:     IssueFromBranch(branch),
+			CreatedAt: s.now(),
+		})
+	})
+}
+
+// RecordPruned marks the active worktree for branch as pruned
+func (s *Store) RecordPruned(branch string) {
+	if s == nil || branch == "" {
+		return
+	}
+
+	_ = s.update(func(repo *repoMetadata) {
+		for i := len(repo.Worktrees) - 1; i >= 0; i-- {
+			if repo.Worktrees[i].Branch == branch && repo.Worktrees[i].Active() {
+				prunedAt := s.now()
+				repo.Worktrees[i].PrunedAt = &prunedAt
+				break
+			}
+		}
+		// Its ports are free for the next worktree
+		delete(repo.Allocations, branch)
+		// It's no longer in a stack, and its note and last command were
+		// about the worktree
+		delete(repo.Parents, branch)
+		delete(repo.Notes, branch)
+		delete(repo.LastCommands, branch)
+	})
+}
+
+// LinkIssue links the active worktree for branch to issue, for branches whose
+// name doesn't start with it
+func (s *Store) LinkIssue(branch, issue string) {
+	if s == nil || branch == "" || issue == "" {
+		return
+	}
+
+	_ = s.update(func(repo *repoMetadata) {
+		for i := len(repo.Worktrees) - 1; i >= 0; i-- {
+			if repo.Worktrees[i].Branch == branch && repo.Worktrees[i].Active() {
+				repo.Worktrees[i].Issue = strings.ToUpper(issue)
+				return
+			}
+		}
+	})
+}
+
+// RecordRenamed moves what's recorded for a worktree from oldBranch at oldPath
+// to newBranch at newPath, so its history and cached size follow it
+func (s *Store) RecordRenamed(oldBranch, newBranch, oldPath, newPath string) {
+	if s == nil || oldBranch == "" || newBranch == "" {
+		return
+	}
+
+	_ = s.update(func(repo *repoMetadata) {
+		for i := len(repo.Worktrees) - 1; i >= 0; i-- {
+			if repo.Worktrees[i].Branch == oldBranch && repo.Worktrees[i].Active() {
+				repo.Worktrees[i].Branch = newBranch
+				repo.Worktrees[i].Path = newPath
+				repo.Worktrees[i].Issue = IssueFromBranch(newBranch)
+				break
+			}
+		}
+		if use, ok := repo.BranchHistory[oldBranch]; ok {
+			delete(repo.BranchHistory, oldBranch)
+			repo.BranchHistory[newBranch] = use
+		}
+		if usage, ok := repo.DiskUsage[oldPath]; ok {
+			delete(repo.DiskUsage, oldPath)
+			repo.DiskUsage[newPath] = usage
+		}
+		for branch, parent := range repo.Parents {
+			if parent == oldBranch {
+				repo.Parents[branch] = newBranch
+			}
+		}
+		if parent, ok := repo.Parents[oldBranch]; ok {
+			delete(repo.Parents, oldBranch)
+			repo.Parents[newBranch] = parent
+		}
+		if note, ok := repo.Notes[oldBranch]; ok {
+			delete(repo.Notes, oldBranch)
+			repo.Notes[newBranch] = note
+		}
+		if last, ok := repo.LastCommands[oldBranch]; ok {
+			delete(repo.LastCommands, oldBranch)
+			last.Path = newPath
+			repo.LastCommands[newBranch] = last
+		}
+		if run, ok := repo.Detached[oldBranch]; ok {
+			delete(repo.Detached, oldBranch)
+			repo.Detached[newBranch] = run
+		}
+		if allocation, ok := repo.Allocations[oldBranch]; ok {
+			delete(repo.Allocations, oldBranch)
+			allocation.Path = newPath
+			repo.Allocations[newBranch] = allocation
+		}
+	})
+}
+
+// Worktrees returns every recorded worktree for the repository, oldest first
+func (s *Store) Worktrees() []WorktreeRecord {
+	if s == nil {
+		return nil
+	}
+	file, err := s.load()
+	if err != nil {
+		return nil
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil {
+		return nil
+	}
+	return repo.Worktrees
+}
+
+// RegisterRepo records the repository so commands run with --all-repos can find it
+func (s *Store) RegisterRepo() {
+	if s == nil || s.repoRoot == "" {
+		return
+	}
+	if file, err := s.load(); err == nil && file.Repos[s.repoRoot] != nil {
+		return
+	}
+	_ = s.update(func(repo *repoMetadata) {})
+}
+
+// KnownRepos returns the roots of every registered repository that still exists on disk, sorted
+func (s *Store) KnownRepos() []string {
+	if s == nil {
+		return nil
+	}
+	file, err := s.load()
+	if err != nil {
+		return nil
+	}
+	var roots []string
+	for root := range file.Repos {
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			roots = append(roots, root)
+		}
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// IssueForBranch returns the issue linked to the active worktree for branch,
+// falling back to the identifier at the start of the branch name
+func (s *Store) IssueForBranch(branch string) string {
+	records := s.Worktrees()
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Branch == branch && records[i].Active() && records[i].Issue != "" {
+			return records[i].Issue
+		}
+	}
+	return IssueFromBranch(branch)
+}
+
+// BranchForIssue returns the branch of the newest worktree made for issue,
+// pruned or not, or "" when sprout has made none
+func (s *Store) BranchForIssue(issue string) string {
+	records := s.Worktrees()
+	for i := len(records) - 1; i >= 0; i-- {
+		if strings.EqualFold(records[i].Issue, issue) {
+			return records[i].Branch
+		}
+	}
+	return ""
+}
+
+// IsIssueIdentifier reports whether s is a Linear-style identifier on its
+// own, such as ENG-123
+func IsIssueIdentifier(s string) bool {
+	return IssueFromBranch(s) != "" && strings.EqualFold(IssueFromBranch(s), s)
+}
+
+// DiskUsage returns the cached size of the worktree at path, if one has been measured
+func (s *Store) DiskUsage(path string) (DiskUsageRecord, bool) {
+	if s == nil || path == "" {
+		return DiskUsageRecord{}, false
+	}
+	file, err := s.load()
+	if err != nil {
+		return DiskUsageRecord{}, false
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil {
+		return DiskUsageRecord{}, false
+	}
+	record, ok := repo.DiskUsage[path]
+	return record, ok
+}
+
+// RememberDiskUsage caches freshly measured worktree sizes, keyed by path
+func (s *Store) RememberDiskUsage(sizes map[string]int64) {
+	if s == nil || len(sizes) == 0 {
+		return
+	}
+
+	_ = s.update(func(repo *repoMetadata) {
+		if repo.DiskUsage == nil {
+			repo.DiskUsage = make(map[string]DiskUsageRecord)
+		}
+		measuredAt := s.now()
+		for path, size := range sizes {
+			repo.DiskUsage[path] = DiskUsageRecord{Bytes: size, MeasuredAt: measuredAt}
+		}
+	})
+}
+
+// RecordBranchUse notes that branch was just created or resumed
+func (s *Store) RecordBranchUse(branch string) {
+	if s == nil || branch == "" {
+		return
+	}
+
+	_ = s.update(func(repo *repoMetadata) {
+		if repo.BranchHistory == nil {
+			repo.BranchHistory = make(map[string]BranchUse)
+		}
+		use := repo.BranchHistory[branch]
+		use.Count++
+		use.LastUsed = s.now()
+		repo.BranchHistory[branch] = use
+	})
+}
+
+// RecentBranches returns the branches used in the repository ranked by
+// frecency, so branches used often and lately come first. Worktrees created
+// outside the TUI count as a single use when they were created.
+func (s *Store) RecentBranches() []string {
+	if s == nil {
+		return nil
+	}
+	file, err := s.load()
+	if err != nil {
+		return nil
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil {
+		return nil
+	}
+
+	uses := make(map[string]BranchUse, len(repo.BranchHistory))
+	for branch, use := range repo.BranchHistory {
+		uses[branch] = use
+	}
+	for _, record := range repo.Worktrees {
+		if _, ok := uses[record.Branch]; !ok {
+			uses[record.Branch] = BranchUse{Count: 1, LastUsed: record.CreatedAt}
+		}
+	}
+
+	now := s.now()
+	branches := make([]string, 0, len(uses))
+	scores := make(map[string]int, len(uses))
+	for branch, use := range uses {
+		branches = append(branches, branch)
+		scores[branch] = use.Count * frecencyWeight(now.Sub(use.LastUsed))
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		a, b := branches[i], branches[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		if !uses[a].LastUsed.Equal(uses[b].LastUsed) {
+			return uses[a].LastUsed.After(uses[b].LastUsed)
+		}
+		return a < b
+	})
+	return branches
+}
+
+// frecencyWeight scores a use by how long ago it happened
+func frecencyWeight(age time.Duration) int {
+	const day = 24 * time.Hour
+	switch {
+	case age < 4*day:
+		return 100
+	case age < 14*day:
+		return 70
+	case age < 31*day:
+		return 50
+	case age < 90*day:
+		return 30
+	default:
+		return 10
+	}
+}
+
+// SparseProfiles returns the named sparse-checkout profiles defined for the repository
+func (s *Store) SparseProfiles() map[string][]string {
+	if s == nil {
+		return nil
+	}
+	file, err := s.load()
+	if err != nil {
+		return nil
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil {
+		return nil
+	}
+	return repo.SparseProfiles
+}
+
+// SetSparseProfile creates or replaces a named sparse-checkout profile
+func (s *Store) SetSparseProfile(name string, directories []string) error {
+	if s == nil {
+		return fmt.Errorf("metadata store is not available")
+	}
+	if name == "" {
+		return fmt.Errorf("profile name cannot be empty")
+	}
+
+	return s.update(func(repo *repoMetadata) {
+		if repo.SparseProfiles == nil {
+			repo.SparseProfiles = make(map[string][]string)
+		}
+		repo.SparseProfiles[name] = directories
+	})
+}
+
+// IssueTree returns how the issue tree was left in the repository's last TUI session
+func (s *Store) IssueTree() IssueTreeState {
+	if s == nil {
+		return IssueTreeState{}
+	}
+	file, err := s.load()
+	if err != nil {
+		return IssueTreeState{}
+	}
+	repo := file.Repos[s.repoRoot]
+	if repo == nil || repo.IssueTree == nil {
+		return IssueTreeState{}
+	}
+	return *repo.IssueTree
+}
+
+// SetIssueTree remembers how the issue tree was left for the next TUI session
+func (s *Store) SetIssueTree(state IssueTreeState) {
+	if s == nil {
+		return
+	}
+
+	_ = s.update(func(repo *repoMetadata) {
+		repo.IssueTree = &state
+	})
+}
+
+// updateMu keeps updates made side by side, such as by worktrees pruned
+// together, from each saving over the others'. Every repository's stores
+// share the one file, so it's shared too. The file lock does the same for
+// other sprout processes, such as a scheduled gc running beside the TUI
+var updateMu sync.Mutex
+
+func (s *Store) update(fn func(repo *repoMetadata)) error {
+	return s.updateFile(func(file storeFile) error {
+		if file.Repos[s.repoRoot] == nil {
+			file.Repos[s.repoRoot] = &repoMetadata{}
+		}
+		fn(file.Repos[s.repoRoot])
+		return nil
+	})
+}
+
+// updateFile reads every repository's metadata, applies fn and saves the
+// result, holding the lock from the read to the save. A file that can't be
+// read is an error rather than a fresh start, which would save over every
+// repository's pins, notes and allocations
+func (s *Store) updateFile(fn func(file storeFile) error) error {
+	updateMu.Lock()
+	defer updateMu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	file, err := s.load()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+	if err := fn(file); err != nil {
+		return err
+	}
+	return s.save(file)
+}
+
+// lock takes the lock file beside the metadata, waiting for any other
+// process holding it
+func (s *Store) lock() (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the metadata lock: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", s.path, err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+func (s *Store) load() (storeFile, error) {
+	file := storeFile{Repos: make(map[string]*repoMetadata)}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return file, err
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return file, err
+	}
+	if file.Repos == nil {
+		file.Repos = make(map[string]*repoMetadata)
+	}
+	return file, nil
+}
+
+func (s *Store) save(file storeFile) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write beside the old file and swap it in, so a crash mid-write can't
+	// leave it truncated
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,448 @@
+package metadata
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIssueFromBranch(t *testing.T) {
+	tests := []struct {
+		branch   string
+		expected string
+	}{
+		{"eng-123-fix-login", "ENG-123"},
+		{"ENG-42", "ENG-42"},
+		{"abc-7/subtask", "ABC-7"},
+		{"feature-login", ""},
+		{"main", ""},
+	}
+
+	for _, tt := range tests {
+		if got := IssueFromBranch(tt.branch); got != tt.expected {
+			t.Errorf("IssueFromBranch(%q) = %q, want %q", tt.branch, got, tt.expected)
+		}
+	}
+}
+
+func TestStoreRecordsWorktreeLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	store := NewStoreWithPath("/repo", path)
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	pruned := created.Add(48 * time.Hour)
+
+	store.SetClock(func() time.Time { return created })
+	store.RecordCreated("eng-1-thing", "/worktrees/eng-1-thing")
+	store.RecordCreated("eng-1-thing", "/worktrees/eng-1-thing")
+
+	store.SetClock(func() time.Time { return pruned })
+	store.RecordPruned("eng-1-thing")
+
+	// A second store on the same file only sees records for its own repo
+	if other := NewStoreWithPath("/other", path).Worktrees(); len(other) != 0 {
+		t.Fatalf("expected no records for another repo, got %d", len(other))
+	}
+
+	records := NewStoreWithPath("/repo", path).Worktrees()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	record := records[0]
+	if record.Issue != "ENG-1" || !record.CreatedAt.Equal(created) {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+	if record.Active() || !record.PrunedAt.Equal(pruned) {
+		t.Fatalf("expected record to be pruned at %v, got %+v", pruned, record.PrunedAt)
+	}
+}
+
+func TestRenamedWorktreeKeepsItsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	store := NewStoreWithPath("/repo", path)
+	store.RecordCreated("eng-1-typo", "/worktrees/eng-1-typo")
+	store.RecordBranchUse("eng-1-typo")
+	store.RememberDiskUsage(map[string]int64{"/worktrees/eng-1-typo": 1024})
+	store.RecordParent("eng-1-typo", "eng-0-base")
+	store.RecordParent("eng-3-tests", "eng-1-typo")
+	store.SetNote("eng-1-typo", "waiting on design review")
+	store.RecordLastCommand("eng-1-typo", "/worktrees/eng-1-typo", []string{"claude"})
+	store.RecordDetached("eng-1-typo", DetachedRun{PID: 4242, Command: "npm run dev"})
+	store.Allocate("eng-1-typo", "/worktrees/eng-1-typo", "web-eng-1-typo", func(map[int]bool) (int, error) { return 3040, nil })
+
+	store.RecordRenamed("eng-1-typo", "eng-2-fixed", "/worktrees/eng-1-typo", "/worktrees/eng-2-fixed")
+
+	records := store.Worktrees()
+	if len(records) != 1 || records[0].Branch != "eng-2-fixed" || records[0].Path != "/worktrees/eng-2-fixed" || records[0].Issue != "ENG-2" {
+		t.Fatalf("expected the record renamed, got %+v", records)
+	}
+	if branches := store.RecentBranches(); len(branches) != 1 || branches[0] != "eng-2-fixed" {
+		t.Fatalf("expected only the new name suggested, got %v", branches)
+	}
+	if usage, ok := store.DiskUsage("/worktrees/eng-2-fixed"); !ok || usage.Bytes != 1024 {
+		t.Fatalf("expected the cached size to follow the worktree, got %+v", usage)
+	}
+	if _, ok := store.DiskUsage("/worktrees/eng-1-typo"); ok {
+		t.Fatal("expected nothing cached for the old path")
+	}
+	if parents := store.Parents(); len(parents) != 2 || parents["eng-2-fixed"] != "eng-0-base" || parents["eng-3-tests"] != "eng-2-fixed" {
+		t.Fatalf("expected the stack to follow the rename, got %v", parents)
+	}
+	if notes := store.Notes(); len(notes) != 1 || notes["eng-2-fixed"] != "waiting on design review" {
+		t.Fatalf("expected the note to follow the rename, got %v", notes)
+	}
+	if last := store.LastCommands(); len(last) != 1 || last["eng-2-fixed"].Path != "/worktrees/eng-2-fixed" {
+		t.Fatalf("expected the last command to follow the rename, got %v", last)
+	}
+	if run, ok := store.Detached("eng-2-fixed"); !ok || run.PID != 4242 {
+		t.Fatalf("expected the detached command to follow the rename, got %+v", run)
+	}
+	if _, ok := store.Detached("eng-1-typo"); ok {
+		t.Fatal("expected no detached command left under the old name")
+	}
+	if allocations := store.Allocations(); len(allocations) != 1 || allocations["eng-2-fixed"].Port != 3040 || allocations["eng-2-fixed"].Path != "/worktrees/eng-2-fixed" {
+		t.Fatalf("expected the ports to follow the rename, got %v", allocations)
+	}
+}
+
+func TestAllocationsOfWorktreesGoneFromDiskAreReleased(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metadata.json")
+	live := filepath.Join(dir, "eng-1-live")
+	if err := os.Mkdir(live, 0755); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStoreWithPath("/repo", path)
+	pick := func(port int) func(map[int]bool) (int, error) {
+		return func(map[int]bool) (int, error) { return port, nil }
+	}
+	store.Allocate("eng-1-live", live, "", pick(3000))
+	// Removed with git worktree remove rather than sprout prune
+	NewStoreWithPath("/repos/other", path).Allocate("eng-2-removed", filepath.Join(dir, "eng-2-removed"), "", pick(3010))
+	// Allocated before allocations kept their path, so only its record says where it was
+	older := NewStoreWithPath("/repos/older", path)
+	older.RecordCreated("eng-4-removed", filepath.Join(dir, "eng-4-removed"))
+	older.Allocate("eng-4-removed", "", "", pick(3020))
+
+	var used map[int]bool
+	store.Allocate("eng-3-new", filepath.Join(dir, "eng-3-new"), "", func(u map[int]bool) (int, error) {
+		used = u
+		return 3010, nil
+	})
+
+	if !used[3000] || used[3010] || used[3020] {
+		t.Fatalf("expected only the live worktree's ports held, got %v", used)
+	}
+	if allocations := NewStoreWithPath("/repos/other", path).Allocations(); len(allocations) != 0 {
+		t.Fatalf("expected the removed worktree's allocation released, got %v", allocations)
+	}
+	if allocations := older.Allocations(); len(allocations) != 0 {
+		t.Fatalf("expected the older removed worktree's allocation released, got %v", allocations)
+	}
+}
+
+func TestAllocateReportsWhenNoPortIsFree(t *testing.T) {
+	store := NewStoreWithPath("/repo", filepath.Join(t.TempDir(), "metadata.json"))
+
+	_, err := store.Allocate("eng-1", "/worktrees/eng-1", "", func(map[int]bool) (int, error) {
+		return 0, errors.New("no ports free")
+	})
+
+	if err == nil {
+		t.Fatal("expected the error picking a port")
+	}
+	if allocations := store.Allocations(); len(allocations) != 0 {
+		t.Fatalf("expected nothing allocated, got %v", allocations)
+	}
+}
+
+func TestPrunedWorktreeLeavesItsStack(t *testing.T) {
+	store := NewStoreWithPath("/repo", filepath.Join(t.TempDir(), "metadata.json"))
+	store.RecordCreated("eng-2-tests", "/worktrees/eng-2-tests")
+	store.RecordParent("eng-2-tests", "eng-1-base")
+	store.RecordParent("eng-3-docs", "eng-1-base")
+
+	store.RecordPruned("eng-2-tests")
+
+	if parents := store.Parents(); len(parents) != 1 || parents["eng-3-docs"] != "eng-1-base" {
+		t.Fatalf("expected only the pruned branch's parent forgotten, got %v", parents)
+	}
+}
+
+func TestNotesAreSetAndCleared(t *testing.T) {
+	store := NewStoreWithPath("/repo", filepath.Join(t.TempDir(), "metadata.json"))
+	store.SetNote("eng-1-login", "  waiting on design review\n")
+	store.SetNote("eng-2-search", "blocked by ENG-1")
+	if notes := store.Notes(); len(notes) != 2 || notes["eng-1-login"] != "waiting on design review" {
+		t.Fatalf("expected both notes kept, trimmed, got %v", notes)
+	}
+
+	store.SetNote("eng-1-login", " ")
+	if notes := store.Notes(); len(notes) != 1 || notes["eng-2-search"] != "blocked by ENG-1" {
+		t.Fatalf("expected an empty note to clear it, got %v", notes)
+	}
+}
+
+func TestSetNoteReportsNotesItCantSave(t *testing.T) {
+	// The metadata file can't go inside a file
+	blocked := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStoreWithPath("/repo", filepath.Join(blocked, "metadata.json"))
+	if err := store.SetNote("eng-1-login", "waiting on design review"); err == nil {
+		t.Fatal("expected an error for a note that wasn't saved")
+	}
+}
+
+func TestPrunedWorktreeLosesItsNote(t *testing.T) {
+	store := NewStoreWithPath("/repo", filepath.Join(t.TempDir(), "metadata.json"))
+	store.RecordCreated("eng-1-login", "/worktrees/eng-1-login")
+	if err := store.SetNote("eng-1-login", "waiting on design review"); err != nil {
+		t.Fatalf("SetNote failed: %v", err)
+	}
+
+	store.RecordPruned("eng-1-login")
+
+	// A worktree for the same branch later starts without it
+	if notes := store.Notes(); len(notes) != 0 {
+		t.Fatalf("expected the note forgotten with the worktree, got %v", notes)
+	}
+}
+
+func TestPrunedWorktreeLosesItsLastCommand(t *testing.T) {
+	store := NewStoreWithPath("/repo", filepath.Join(t.TempDir(), "metadata.json"))
+	store.RecordCreated("eng-1-login", "/worktrees/eng-1-login")
+	store.RecordLastCommand("eng-1-login", "/worktrees/eng-1-login", []string{"claude", "--continue"})
+
+	store.RecordPruned("eng-1-login")
+
+	if last := store.LastCommands(); len(last) != 0 {
+		t.Fatalf("expected nothing left for sprout resume to run, got %v", last)
+	}
+}
+
+func TestSeeMergedReportsEachMergeOnce(t *testing.T) {
+	store := NewStoreWithPath("/repo", filepath.Join(t.TempDir(), "metadata.json"))
+	if merged := store.SeeMerged([]string{"eng-1-login", "eng-2-search"}, []string{"eng-1-login"}); !reflect.DeepEqual(merged, []string{"eng-1-login"}) {
+		t.Fatalf("expected the first merge reported, got %v", merged)
+	}
+	// Its PR couldn't be looked up this time, which isn't its merge undone
+	if merged := store.SeeMerged([]string{"eng-1-login", "eng-2-search"}, nil); len(merged) != 0 {
+		t.Fatalf("expected nothing new, got %v", merged)
+	}
+	if merged := store.SeeMerged([]string{"eng-1-login", "eng-2-search"}, []string{"eng-1-login", "eng-2-search"}); !reflect.DeepEqual(merged, []string{"eng-2-search"}) {
+		t.Fatalf("expected only the new merge reported, got %v", merged)
+	}
+
+	// Once pruned, the same branch made again is news when it merges
+	store.SeeMerged([]string{"eng-2-search"}, []string{"eng-2-search"})
+	if merged := store.SeeMerged([]string{"eng-1-login", "eng-2-search"}, []string{"eng-1-login", "eng-2-search"}); !reflect.DeepEqual(merged, []string{"eng-1-login"}) {
+		t.Fatalf("expected the branch made again reported, got %v", merged)
+	}
+}
+
+func TestKnownReposListsRegisteredRepositories(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	api := t.TempDir()
+	web := t.TempDir()
+
+	NewStoreWithPath(web, path).RegisterRepo()
+	NewStoreWithPath(api, path).RegisterRepo()
+	NewStoreWithPath(api, path).RegisterRepo()
+	NewStoreWithPath(filepath.Join(api, "deleted"), path).RegisterRepo()
+
+	repos := NewStoreWithPath(api, path).KnownRepos()
+	expected := []string{api, web}
+	if api > web {
+		expected = []string{web, api}
+	}
+	if len(repos) != 2 || repos[0] != expected[0] || repos[1] != expected[1] {
+		t.Fatalf("expected repos %v, got %v", expected, repos)
+	}
+}
+
+func TestRecentBranchesRanksByFrecency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	store := NewStoreWithPath("/repo", path)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Used often but long ago
+	store.SetClock(func() time.Time { return now.AddDate(0, -6, 0) })
+	for i := 0; i < 5; i++ {
+		store.RecordBranchUse("old-favourite")
+	}
+	// Created outside the TUI a week ago
+	store.SetClock(func() time.Time { return now.AddDate(0, 0, -7) })
+	store.RecordCreated("eng-2-cli-created", "/worktrees/eng-2-cli-created")
+	// Used once, yesterday and an hour ago
+	store.SetClock(func() time.Time { return now.AddDate(0, 0, -1) })
+	store.RecordBranchUse("fix-login")
+	store.RecordBranchUse("fix-logout")
+	store.SetClock(func() time.Time { return now.Add(-time.Hour) })
+	store.RecordBranchUse("fix-logout")
+
+	store.SetClock(func() time.Time { return now })
+	branches := store.RecentBranches()
+	expected := []string{"fix-logout", "fix-login", "eng-2-cli-created", "old-favourite"}
+	if len(branches) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, branches)
+	}
+	for i := range expected {
+		if branches[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, branches)
+		}
+	}
+}
+
+func TestIssueTreeIsRememberedPerRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	api := NewStoreWithPath("/repos/api", path)
+	web := NewStoreWithPath("/repos/web", path)
+
+	api.SetIssueTree(IssueTreeState{Expanded: []string{"issue-1", "issue-2"}, Selected: "issue-3"})
+	web.SetIssueTree(IssueTreeState{Selected: "issue-9"})
+
+	if state := NewStoreWithPath("/repos/api", path).IssueTree(); len(state.Expanded) != 2 || state.Selected != "issue-3" {
+		t.Fatalf("expected api's tree back, got %+v", state)
+	}
+	if state := web.IssueTree(); len(state.Expanded) != 0 || state.Selected != "issue-9" {
+		t.Fatalf("expected web's tree kept apart, got %+v", state)
+	}
+}
+
+func TestIssueSortIsRememberedPerRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	api := NewStoreWithPath("/repos/api", path)
+	web := NewStoreWithPath("/repos/web", path)
+
+	if err := api.SetIssueSort("priority"); err != nil {
+		t.Fatalf("SetIssueSort failed: %v", err)
+	}
+	if order := NewStoreWithPath("/repos/api", path).IssueSort(); order != "priority" {
+		t.Fatalf("expected api sorted by priority, got %q", order)
+	}
+	if order := web.IssueSort(); order != "" {
+		t.Fatalf("expected web left unsorted, got %q", order)
+	}
+}
+
+func TestHistoryIsKeptPerRepositoryInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	store := NewStoreWithPath("/repo", path)
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	store.SetClock(func() time.Time { return start.Add(time.Hour) })
+	store.LogEvent(HistoryEvent{Kind: HistoryWorktreeCreated, Branch: "eng-1-thing", Path: "/worktrees/eng-1-thing"})
+	// Written late by a process that started earlier
+	store.LogEvent(HistoryEvent{Time: start, Kind: HistoryBranchCreated, Branch: "eng-1-thing"})
+	NewStoreWithPath("/other", path).LogEvent(HistoryEvent{Kind: HistoryWorktreeCreated, Branch: "elsewhere"})
+	store.SetClock(func() time.Time { return start.Add(48 * time.Hour) })
+	store.LogEvent(HistoryEvent{Kind: HistoryWorktreePruned, Branch: "eng-1-thing"})
+
+	events := NewStoreWithPath("/repo", path).History(time.Time{})
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events for the repo, got %+v", events)
+	}
+	kinds := []string{events[0].Kind, events[1].Kind, events[2].Kind}
+	if kinds[0] != HistoryBranchCreated || kinds[1] != HistoryWorktreeCreated || kinds[2] != HistoryWorktreePruned {
+		t.Fatalf("expected events oldest first, got %v", kinds)
+	}
+	if events[0].Issue != "ENG-1" || events[0].Repo != "/repo" {
+		t.Fatalf("expected the issue and repo to be filled in, got %+v", events[0])
+	}
+
+	if recent := store.History(start.Add(24 * time.Hour)); len(recent) != 1 || recent[0].Kind != HistoryWorktreePruned {
+		t.Fatalf("expected only the prune since the day after, got %+v", recent)
+	}
+}
+
+func TestUnreadableMetadataIsntSavedOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	corrupt := []byte(`{"repos": {"/repo": {"notes": {"eng-1": "keep me"`)
+	if err := os.WriteFile(path, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStoreWithPath("/repo", path)
+
+	if err := store.SetNote("eng-2", "new note"); err == nil {
+		t.Fatal("expected the parse error reported")
+	}
+	if content, _ := os.ReadFile(path); string(content) != string(corrupt) {
+		t.Fatalf("expected the file left as it was, got %q", content)
+	}
+}
+
+func TestSavingLeavesNoTemporaryFile(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithPath("/repo", filepath.Join(dir, "metadata.json"))
+
+	if err := store.SetNote("eng-1", "saved"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "metadata.json.tmp")); !os.IsNotExist(err) {
+		t.Fatalf("expected the temporary file renamed into place, stat returned %v", err)
+	}
+	if notes := store.Notes(); notes["eng-1"] != "saved" {
+		t.Fatalf("expected the note saved, got %v", notes)
+	}
+}
+
+func TestLockWaitsForAnotherHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	// As another process would, through a lock file of its own
+	unlock, err := NewStoreWithPath("/repos/other", path).lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locked := make(chan struct{})
+	go func() {
+		unlockAgain, err := NewStoreWithPath("/repo", path).lock()
+		if err == nil {
+			unlockAgain()
+		}
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("expected the lock to wait while it's held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the lock once released")
+	}
+}
+
+func TestNilStoreIsNoop(t *testing.T) {
+	var store *Store
+	store.RecordCreated("branch", "/path")
+	store.RecordPruned("branch")
+	store.RecordRenamed("branch", "renamed", "/path", "/renamed")
+	store.RecordBranchUse("branch")
+	store.SetNote("branch", "note")
+	store.SetIssueTree(IssueTreeState{Selected: "issue-1"})
+	store.LogEvent(HistoryEvent{Kind: HistoryCommandRun, Branch: "branch"})
+	if events := store.History(time.Time{}); events != nil {
+		t.Fatalf("expected no history, got %v", events)
+	}
+	if state := store.IssueTree(); state.Selected != "" {
+		t.Fatalf("expected an empty issue tree, got %+v", state)
+	}
+	if branches := store.RecentBranches(); branches != nil {
+		t.Fatalf("expected no recent branches, got %v", branches)
+	}
+	if records := store.Worktrees(); records != nil {
+		t.Fatalf("expected nil records, got %v", records)
+	}
+	if issue := store.IssueForBranch("eng-9-thing"); issue != "ENG-9" {
+		t.Fatalf("expected issue parsed from branch, got %q", issue)
+	}
+}
@@ -0,0 +1,103 @@
+// Package problem turns the errors sprout runs into into what it shows for
+// them: a title, the detail behind it, and what to try next. The TUI's result
+// screen and the CLI's stderr both present errors this way
+package problem
+
+import (
+	"errors"
+	"strings"
+
+	"sprout/pkg/git"
+	"sprout/pkg/linear"
+	"sprout/pkg/sprout"
+)
+
+// Problem is an error as sprout presents it
+type Problem struct {
+	Title   string   // what went wrong, in one line
+	Details []string // what's known about why, such as git's output
+	Hints   []string // what to try next
+}
+
+// detailed adds detail lines to an error, written beneath its message so
+// they're kept wherever it's shown as plain text
+type detailed struct {
+	err     error
+	details []string
+}
+
+func (d *detailed) Error() string {
+	return d.err.Error() + "\n" + strings.Join(d.details, "\n")
+}
+
+func (d *detailed) Unwrap() error { return d.err }
+
+// WithDetail adds lines explaining err, shown beneath its title
+func WithDetail(err error, details ...string) error {
+	if err == nil || len(details) == 0 {
+		return err
+	}
+	return &detailed{err: err, details: details}
+}
+
+// hinted suggests what to do about an error. The hints aren't part of its
+// message, which stays the same for scripts and logs
+type hinted struct {
+	err   error
+	hints []string
+}
+
+func (h *hinted) Error() string { return h.err.Error() }
+
+func (h *hinted) Unwrap() error { return h.err }
+
+// WithHint suggests what to try next about err, such as the command to run
+func WithHint(err error, hints ...string) error {
+	if err == nil || len(hints) == 0 {
+		return err
+	}
+	return &hinted{err: err, hints: hints}
+}
+
+// knownHints are what to try next for errors sprout returns often, wherever
+// they were returned from
+var knownHints = []struct {
+	err   error
+	hints []string
+}{
+	{git.ErrEmptyBranchName, []string{"branch names are made of letters, numbers, -, . and /, such as fix/login-redirect"}},
+	{git.ErrInvalidBranchName, []string{"branch names are made of letters, numbers, -, . and /, such as fix/login-redirect"}},
+	{git.ErrPathNotWorktree, []string{"move or delete that directory, then try again"}},
+	{git.ErrNothingToUndo, []string{"sprout history shows what sprout removed and when"}},
+	{sprout.ErrNoIssueTracker, []string{"set linearApiKey in ~/.sprout.json5, or run sprout auth linear to sign in"}},
+	{linear.ErrTimeout, []string{"raise networkTimeoutSeconds if Linear is just slow, or run sprout doctor to check the connection"}},
+}
+
+// Describe presents err. The first line of its message is the title and any
+// more, such as git's output, are the details; hints come from WithHint and
+// from the errors sprout knows what to do about
+func Describe(err error) Problem {
+	if err == nil {
+		return Problem{}
+	}
+	lines := strings.Split(strings.TrimSpace(err.Error()), "\n")
+	p := Problem{Title: lines[0]}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "Output:"))
+		if line != "" {
+			p.Details = append(p.Details, line)
+		}
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if h, ok := e.(*hinted); ok {
+			p.Hints = append(p.Hints, h.hints...)
+		}
+	}
+	for _, known := range knownHints {
+		if errors.Is(err, known.err) {
+			p.Hints = append(p.Hints, known.hints...)
+		}
+	}
+	return p
+}
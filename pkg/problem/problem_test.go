@@ -0,0 +1,59 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"sprout/pkg/git"
+)
+
+func TestDescribeSplitsTitleDetailsAndHints(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Problem
+	}{
+		{
+			name: "plain error",
+			err:  errors.New("no archive found for feature-a"),
+			want: Problem{Title: "no archive found for feature-a"},
+		},
+		{
+			name: "git output beneath the message",
+			err:  fmt.Errorf("failed to create worktree: %w\nOutput: %s", errors.New("exit status 128"), "fatal: invalid reference: nope\n"),
+			want: Problem{Title: "failed to create worktree: exit status 128", Details: []string{"fatal: invalid reference: nope"}},
+		},
+		{
+			name: "detail and hint wrapped in",
+			err:  fmt.Errorf("create: %w", WithHint(WithDetail(errors.New("branch fix already exists"), "checked out at /w/fix"), "sprout switch fix goes to it")),
+			want: Problem{Title: "create: branch fix already exists", Details: []string{"checked out at /w/fix"}, Hints: []string{"sprout switch fix goes to it"}},
+		},
+		{
+			name: "an error sprout knows what to do about",
+			err:  fmt.Errorf("%w: /w/fix", git.ErrPathNotWorktree),
+			want: Problem{Title: "directory exists but is not a valid worktree: /w/fix", Hints: []string{"move or delete that directory, then try again"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Describe(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWrappingKeepsTheErrorMatchable(t *testing.T) {
+	err := WithHint(WithDetail(git.ErrNothingToUndo, "the trash is empty"), "sprout history")
+	if !errors.Is(err, git.ErrNothingToUndo) {
+		t.Fatal("expected the wrapped error to still match")
+	}
+	if want := git.ErrNothingToUndo.Error() + "\nthe trash is empty"; err.Error() != want {
+		t.Fatalf("expected the details kept in the message and the hint left out, got %q", err.Error())
+	}
+	if WithHint(nil, "anything") != nil || WithDetail(nil, "anything") != nil {
+		t.Fatal("expected no error to stay no error")
+	}
+}
@@ -0,0 +1,141 @@
+// Package progress is how sprout's longer operations say what they're doing
+// as they go: each step as it starts and finishes, warnings that don't stop
+// them, and what they came to. Operations present to a Presenter instead of
+// printing, so the CLI shows them as lines on stderr and the TUI in its
+// status line and result screen
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Presenter shows an operation's progress. Some operations run steps side
+// by side, so a Presenter has to be safe to call from several goroutines
+type Presenter interface {
+	// StepStarted is called as a step, named for what it runs, begins
+	StepStarted(step string)
+	// StepFinished is called once the step is done; err is why it failed
+	StepFinished(step string, err error)
+	// Warning is something that went wrong without stopping the operation
+	Warning(message string)
+	// Result is what the operation came to, or part of it
+	Result(message string)
+}
+
+// Discard is a Presenter that shows nothing
+var Discard Presenter = discard{}
+
+type discard struct{}
+
+func (discard) StepStarted(string)         {}
+func (discard) StepFinished(string, error) {}
+func (discard) Warning(string)             {}
+func (discard) Result(string)              {}
+
+// Or is p, or Discard when there's no Presenter to show anything on
+func Or(p Presenter) Presenter {
+	if p == nil {
+		return Discard
+	}
+	return p
+}
+
+// Step runs fn as a step called step, presenting its start and finish
+func Step(p Presenter, step string, fn func() error) error {
+	p = Or(p)
+	p.StepStarted(step)
+	err := fn()
+	p.StepFinished(step, err)
+	return err
+}
+
+// Text presents progress as plain lines: each step as it starts, warnings
+// prefixed with "Warning: " and results as they are. A failed step isn't
+// repeated, since its error is returned for the caller to report
+type Text struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewText returns a Presenter writing lines to out, usually stderr so that
+// stdout only carries what scripts read
+func NewText(out io.Writer) *Text {
+	return &Text{out: out}
+}
+
+func (t *Text) StepStarted(step string) {
+	t.println(step)
+}
+
+func (t *Text) StepFinished(string, error) {}
+
+func (t *Text) Warning(message string) {
+	t.println("Warning: " + message)
+}
+
+func (t *Text) Result(message string) {
+	t.println(message)
+}
+
+func (t *Text) println(line string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintln(t.out, line)
+}
+
+// Event is one line JSON presents: a step starting or finishing, a warning,
+// a result, or how the operation ended
+type Event struct {
+	Type    string `json:"type"`             // "step", "warning", "result" or "done"
+	Step    string `json:"step,omitempty"`   // what a step runs
+	Status  string `json:"status,omitempty"` // started, finished or failed for a step; how it ended for done
+	Message string `json:"message,omitempty"`
+	Branch  string `json:"branch,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// JSON presents progress as newline-delimited JSON events, for tools
+// wrapping sprout to follow without reading its messages
+type JSON struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSON returns a Presenter writing an Event per line to out
+func NewJSON(out io.Writer) *JSON {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	return &JSON{encoder: encoder}
+}
+
+func (j *JSON) StepStarted(step string) {
+	j.Emit(Event{Type: "step", Step: step, Status: "started"})
+}
+
+func (j *JSON) StepFinished(step string, err error) {
+	if err != nil {
+		j.Emit(Event{Type: "step", Step: step, Status: "failed", Error: err.Error()})
+		return
+	}
+	j.Emit(Event{Type: "step", Step: step, Status: "finished"})
+}
+
+func (j *JSON) Warning(message string) {
+	j.Emit(Event{Type: "warning", Message: message})
+}
+
+func (j *JSON) Result(message string) {
+	j.Emit(Event{Type: "result", Message: message})
+}
+
+// Emit writes event as a line of its own, such as the done event that ends
+// the stream
+func (j *JSON) Emit(event Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_ = j.encoder.Encode(event)
+}
@@ -0,0 +1,56 @@
+package progress
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestTextPresentsStepsWarningsAndResultsAsLines(t *testing.T) {
+	var out bytes.Buffer
+	text := NewText(&out)
+
+	if err := Step(text, "Updating submodules", func() error { return nil }); err != nil {
+		t.Fatalf("Step returned %v", err)
+	}
+	failure := errors.New("git lfs pull failed")
+	if err := Step(text, "Fetching LFS files", func() error { return failure }); err != failure {
+		t.Fatalf("expected the step's error back, got %v", err)
+	}
+	text.Warning("the timer didn't start")
+	text.Result("Worktree ready at: /tmp/wt")
+
+	want := "Updating submodules\nFetching LFS files\nWarning: the timer didn't start\nWorktree ready at: /tmp/wt\n"
+	if out.String() != want {
+		t.Fatalf("expected\n%q\ngot\n%q", want, out.String())
+	}
+
+	// Nowhere to present to is fine too
+	if err := Step(nil, "Nothing to show", func() error { return nil }); err != nil {
+		t.Fatalf("Step with no presenter returned %v", err)
+	}
+	Or(nil).Warning("ignored")
+}
+
+func TestJSONPresentsAnEventPerLine(t *testing.T) {
+	var out bytes.Buffer
+	events := NewJSON(&out)
+
+	_ = Step(events, "git fetch origin main", func() error { return nil })
+	_ = Step(events, "git lfs pull", func() error { return errors.New("exit status 2") })
+	events.Warning("the timer didn't start")
+	events.Result("Worktree ready at: /tmp/wt")
+	events.Emit(Event{Type: "done", Status: "created", Branch: "wt", Path: "/tmp/wt"})
+
+	want := `{"type":"step","step":"git fetch origin main","status":"started"}
+{"type":"step","step":"git fetch origin main","status":"finished"}
+{"type":"step","step":"git lfs pull","status":"started"}
+{"type":"step","step":"git lfs pull","status":"failed","error":"exit status 2"}
+{"type":"warning","message":"the timer didn't start"}
+{"type":"result","message":"Worktree ready at: /tmp/wt"}
+{"type":"done","status":"created","branch":"wt","path":"/tmp/wt"}
+`
+	if out.String() != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, out.String())
+	}
+}
@@ -0,0 +1,254 @@
+package release
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// APIEndpoint is GitHub's REST API, which lists sprout's releases
+	APIEndpoint = "https://api.github.com"
+
+	// Repo publishes sprout's releases
+	Repo = "laurenkt/sprout"
+
+	// ChecksumsAsset lists the SHA-256 of every binary in a release, in sha256sum format
+	ChecksumsAsset = "checksums.txt"
+
+	// latestTimeout bounds the release check so doctor stays quick when offline
+	latestTimeout = 10 * time.Second
+	// defaultStallTimeout is how long a download may go without receiving
+	// anything when no network timeout is given
+	defaultStallTimeout = 30 * time.Second
+)
+
+// Release is a published sprout version and the binaries built for it
+type Release struct {
+	Version string  `json:"tag_name"`
+	URL     string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// UpdaterInterface finds and installs sprout releases
+type UpdaterInterface interface {
+	Latest() (*Release, error)
+	Install(rel *Release, executable string) error
+}
+
+// Updater fetches releases from GitHub and swaps them in for the running binary
+type Updater struct {
+	endpoint   string
+	httpClient *http.Client
+	timeout    time.Duration // how long a download may stall before it's given up
+	goos       string
+	goarch     string
+}
+
+// NewUpdater creates an updater for this platform's builds, giving up on a
+// download that receives nothing for timeout
+func NewUpdater(timeout time.Duration) *Updater {
+	u := NewUpdaterWithEndpoint(APIEndpoint, &http.Client{})
+	if timeout > 0 {
+		u.timeout = timeout
+	}
+	return u
+}
+
+// NewUpdaterWithEndpoint creates an updater that reads releases from endpoint
+func NewUpdaterWithEndpoint(endpoint string, httpClient *http.Client) *Updater {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &Updater{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: httpClient,
+		timeout:    defaultStallTimeout,
+		goos:       runtime.GOOS,
+		goarch:     runtime.GOARCH,
+	}
+}
+
+// AssetName is the name of the release binary built for goos and goarch
+func AssetName(goos, goarch string) string {
+	name := fmt.Sprintf("sprout_%s_%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Latest returns the newest published release
+func (u *Updater) Latest() (*Release, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), latestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.endpoint+"/repos/"+Repo+"/releases/latest", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for releases: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("release check failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var rel Release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return nil, fmt.Errorf("failed to read release: %w", err)
+	}
+	return &rel, nil
+}
+
+// Install downloads this platform's binary from rel, checks it against the
+// release's checksums, and replaces executable with it. When executable is
+// a symlink, the binary it points at is replaced and the link left alone
+func (u *Updater) Install(rel *Release, executable string) error {
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	name := AssetName(u.goos, u.goarch)
+	binary := rel.asset(name)
+	if binary == nil {
+		return fmt.Errorf("release %s has no build for %s/%s", rel.Version, u.goos, u.goarch)
+	}
+	checksums := rel.asset(ChecksumsAsset)
+	if checksums == nil {
+		return fmt.Errorf("release %s has no %s to verify the download against", rel.Version, ChecksumsAsset)
+	}
+
+	expected, err := u.checksum(checksums.URL, name)
+	if err != nil {
+		return err
+	}
+
+	// The new binary is written next to the old one so the final rename stays
+	// on one filesystem and the swap is atomic
+	tmp, err := os.CreateTemp(filepath.Dir(executable), ".sprout-upgrade-*")
+	if err != nil {
+		return fmt.Errorf("failed to stage the new binary: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	err = u.download(binary.URL, io.MultiWriter(tmp, hash))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, expected, actual)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return fmt.Errorf("failed to make the new binary executable: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), executable); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", executable, err)
+	}
+	return nil
+}
+
+func (rel *Release) asset(name string) *Asset {
+	for i := range rel.Assets {
+		if rel.Assets[i].Name == name {
+			return &rel.Assets[i]
+		}
+	}
+	return nil
+}
+
+// checksum finds name's SHA-256 in the checksums file at url
+func (u *Updater) checksum(url, name string) (string, error) {
+	var body strings.Builder
+	if err := u.download(url, &body); err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(body.String()))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum marks binary-mode entries with a leading *
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no checksum for %s", ChecksumsAsset, name)
+}
+
+// download copies url's body to w, giving up once nothing has arrived for
+// u.timeout, however long the whole download takes
+func (u *Updater) download(url string, w io.Writer) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stalled := time.AfterFunc(u.timeout, cancel)
+	defer stalled.Stop()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return u.downloadError(ctx, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: status %d", url, resp.StatusCode)
+	}
+	if _, err := io.Copy(w, &stallReader{r: resp.Body, stalled: stalled, timeout: u.timeout}); err != nil {
+		return u.downloadError(ctx, url, err)
+	}
+	return nil
+}
+
+// downloadError says a download stalled when that's why err happened
+func (u *Updater) downloadError(ctx context.Context, url string, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("failed to download %s: nothing received for %s", url, u.timeout)
+	}
+	return fmt.Errorf("failed to download %s: %w", url, err)
+}
+
+// stallReader puts off the stalled timer each time r reads something
+type stallReader struct {
+	r       io.Reader
+	stalled *time.Timer
+	timeout time.Duration
+}
+
+func (s *stallReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if n > 0 {
+		s.stalled.Reset(s.timeout)
+	}
+	return n, err
+}
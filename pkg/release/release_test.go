@@ -0,0 +1,189 @@
+package release
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newReleaseServer serves a latest release whose linux/amd64 binary is
+// binary, listed in checksums.txt with checksum
+func newReleaseServer(t *testing.T, binary, checksum string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/repos/"+Repo+"/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tag_name": "v1.4.0", "html_url": "https://github.com/%s/releases/v1.4.0", "assets": [
+			{"name": "sprout_linux_amd64", "browser_download_url": "%s/download/sprout_linux_amd64"},
+			{"name": "checksums.txt", "browser_download_url": "%s/download/checksums.txt"}
+		]}`, Repo, server.URL, server.URL)
+	})
+	mux.HandleFunc("/download/sprout_linux_amd64", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, binary)
+	})
+	mux.HandleFunc("/download/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  sprout_darwin_arm64\n%s  sprout_linux_amd64\n", strings.Repeat("0", 64), checksum)
+	})
+	return server
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func newLinuxUpdater(server *httptest.Server) *Updater {
+	updater := NewUpdaterWithEndpoint(server.URL, server.Client())
+	updater.goos, updater.goarch = "linux", "amd64"
+	return updater
+}
+
+func TestInstallReplacesTheExecutable(t *testing.T) {
+	server := newReleaseServer(t, "new binary", sha256Hex("new binary"))
+	updater := newLinuxUpdater(server)
+	executable := filepath.Join(t.TempDir(), "sprout")
+	if err := os.WriteFile(executable, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	rel, err := updater.Latest()
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	if rel.Version != "v1.4.0" {
+		t.Fatalf("Expected v1.4.0, got %q", rel.Version)
+	}
+	if err := updater.Install(rel, executable); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	content, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "new binary" {
+		t.Fatalf("Expected the executable to be replaced, got %q", content)
+	}
+	info, err := os.Stat(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0o100 == 0 {
+		t.Fatalf("Expected the new binary to be executable, got mode %v", info.Mode())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(executable)); len(entries) != 1 {
+		t.Fatalf("Expected the staged download to be cleaned up, got %d files", len(entries))
+	}
+}
+
+func TestInstallRejectsAChecksumMismatch(t *testing.T) {
+	server := newReleaseServer(t, "tampered binary", sha256Hex("new binary"))
+	updater := newLinuxUpdater(server)
+	executable := filepath.Join(t.TempDir(), "sprout")
+	if err := os.WriteFile(executable, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	rel, err := updater.Latest()
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	err = updater.Install(rel, executable)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Expected a checksum mismatch, got %v", err)
+	}
+
+	content, _ := os.ReadFile(executable)
+	if string(content) != "old binary" {
+		t.Fatalf("Expected the executable to be left alone, got %q", content)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(executable)); len(entries) != 1 {
+		t.Fatalf("Expected the staged download to be cleaned up, got %d files", len(entries))
+	}
+}
+
+func TestInstallReplacesTheBinaryASymlinkPointsAt(t *testing.T) {
+	server := newReleaseServer(t, "new binary", sha256Hex("new binary"))
+	updater := newLinuxUpdater(server)
+	binary := filepath.Join(t.TempDir(), "sprout")
+	if err := os.WriteFile(binary, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "sprout")
+	if err := os.Symlink(binary, link); err != nil {
+		t.Fatal(err)
+	}
+
+	rel, err := updater.Latest()
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	if err := updater.Install(rel, link); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("Expected the symlink left in place, got %v", err)
+	}
+	if content, _ := os.ReadFile(binary); string(content) != "new binary" {
+		t.Fatalf("Expected the binary behind the link replaced, got %q", content)
+	}
+}
+
+func TestInstallGivesUpOnAStalledDownload(t *testing.T) {
+	server := newReleaseServer(t, "new binary", sha256Hex("new binary"))
+	release := make(chan struct{})
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "new ")
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	t.Cleanup(stalled.Close)
+	// Cleanups run last first, so the handler is let go before the server waits on it
+	t.Cleanup(func() { close(release) })
+	updater := newLinuxUpdater(server)
+	updater.timeout = 100 * time.Millisecond
+
+	rel, err := updater.Latest()
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	rel.asset("sprout_linux_amd64").URL = stalled.URL
+	err = updater.Install(rel, filepath.Join(t.TempDir(), "sprout"))
+	if err == nil || !strings.Contains(err.Error(), "nothing received for 100ms") {
+		t.Fatalf("Expected the stalled download given up, got %v", err)
+	}
+}
+
+func TestInstallRequiresABuildForThePlatform(t *testing.T) {
+	server := newReleaseServer(t, "new binary", sha256Hex("new binary"))
+	updater := NewUpdaterWithEndpoint(server.URL, server.Client())
+	updater.goos, updater.goarch = "plan9", "386"
+
+	rel, err := updater.Latest()
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	err = updater.Install(rel, filepath.Join(t.TempDir(), "sprout"))
+	if err == nil || !strings.Contains(err.Error(), "no build for plan9/386") {
+		t.Fatalf("Expected a missing build error, got %v", err)
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	if got := AssetName("darwin", "arm64"); got != "sprout_darwin_arm64" {
+		t.Errorf("Unexpected asset name %q", got)
+	}
+	if got := AssetName("windows", "amd64"); got != "sprout_windows_amd64.exe" {
+		t.Errorf("Unexpected asset name %q", got)
+	}
+}
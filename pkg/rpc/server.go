@@ -0,0 +1,220 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"sprout/pkg/sprout"
+)
+
+// Service is what the server exposes; *sprout.Client provides it
+type Service interface {
+	RepoRoot() string
+	CreateWorktree(branch string, opts sprout.CreateOptions) (*sprout.Worktree, error)
+	ListWorktrees() ([]sprout.Worktree, error)
+	Prune(branch string, opts sprout.PruneOptions) error
+	PruneMerged(opts sprout.PruneOptions) error
+	SearchIssues(query string) ([]sprout.Issue, error)
+	Subscribe(handler func(sprout.Event)) (unsubscribe func())
+}
+
+// ChangedNotification is the notification method pushing each sprout.Event
+const ChangedNotification = "worktrees/changed"
+
+// JSON-RPC 2.0 error codes: the reserved ones, then sprout's own
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	CodeWorktreeExists   = 1001
+	CodeWorktreeNotFound = 1002
+	CodeNoIssueTracker   = 1003
+	CodeIssueNotFound    = 1004
+	CodeTimeout          = 1005
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"` // absent for notifications, which get no response
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// Error is a JSON-RPC error object
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+type createParams struct {
+	Branch            string   `json:"branch"`
+	SparseDirectories []string `json:"sparseDirectories"`
+	BaseBranch        string   `json:"baseBranch"`
+	FailIfExists      bool     `json:"failIfExists"`
+}
+
+type pruneParams struct {
+	Branch       string `json:"branch"`
+	Merged       bool   `json:"merged"` // prune every merged worktree instead of one branch
+	KeepBranch   bool   `json:"keepBranch"`
+	DeleteRemote bool   `json:"deleteRemote"`
+	DryRun       bool   `json:"dryRun"`
+}
+
+type searchParams struct {
+	Query string `json:"query"`
+}
+
+// Server answers JSON-RPC 2.0 requests read one per line, writing each
+// response and notification as a line of its own
+type Server struct {
+	service Service
+	mu      sync.Mutex // keeps responses and notifications whole
+	out     *json.Encoder
+}
+
+// NewServer serves service
+func NewServer(service Service) *Server {
+	return &Server{service: service}
+}
+
+// Serve handles requests from in until it's closed, pushing a
+// worktrees/changed notification for every change the service makes
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = json.NewEncoder(out)
+	unsubscribe := s.service.Subscribe(func(event sprout.Event) {
+		s.write(notification{JSONRPC: "2.0", Method: ChangedNotification, Params: event})
+	})
+	defer unsubscribe()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.write(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{CodeParseError, "parse error: " + err.Error()}})
+			continue
+		}
+		result, err := s.handle(req)
+		if req.ID == nil {
+			continue
+		}
+		if err != nil {
+			s.write(response{JSONRPC: "2.0", ID: req.ID, Error: toError(err)})
+			continue
+		}
+		s.write(response{JSONRPC: "2.0", ID: req.ID, Result: result})
+	}
+	return scanner.Err()
+}
+
+func (s *Server) handle(req request) (any, error) {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return nil, &Error{CodeInvalidRequest, "invalid request: jsonrpc must be \"2.0\" and method is required"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return map[string]string{"name": "sprout", "apiVersion": sprout.APIVersion, "repoRoot": s.service.RepoRoot()}, nil
+
+	case "worktrees/list":
+		return s.service.ListWorktrees()
+
+	case "worktrees/create":
+		var params createParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.service.CreateWorktree(params.Branch, sprout.CreateOptions{
+			SparseDirectories: params.SparseDirectories,
+			BaseBranch:        params.BaseBranch,
+			FailIfExists:      params.FailIfExists,
+		})
+
+	case "worktrees/prune":
+		var params pruneParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		opts := sprout.PruneOptions{KeepBranch: params.KeepBranch, DeleteRemote: params.DeleteRemote, DryRun: params.DryRun}
+		if params.Merged {
+			return true, s.service.PruneMerged(opts)
+		}
+		return true, s.service.Prune(params.Branch, opts)
+
+	case "issues/search":
+		var params searchParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.service.SearchIssues(params.Query)
+	}
+	return nil, &Error{CodeMethodNotFound, fmt.Sprintf("method not found: %s", req.Method)}
+}
+
+func decodeParams(raw json.RawMessage, params any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return &Error{CodeInvalidParams, "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+// toError gives each of sprout's errors a code a plugin can act on
+func toError(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	codes := []struct {
+		target error
+		code   int
+	}{
+		{sprout.ErrEmptyBranch, CodeInvalidParams},
+		{sprout.ErrWorktreeExists, CodeWorktreeExists},
+		{sprout.ErrWorktreeNotFound, CodeWorktreeNotFound},
+		{sprout.ErrNoIssueTracker, CodeNoIssueTracker},
+		{sprout.ErrIssueNotFound, CodeIssueNotFound},
+		{sprout.ErrTimeout, CodeTimeout},
+	}
+	for _, c := range codes {
+		if errors.Is(err, c.target) {
+			return &Error{c.code, err.Error()}
+		}
+	}
+	return &Error{CodeInternalError, err.Error()}
+}
+
+func (s *Server) write(message any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.out.Encode(message)
+}
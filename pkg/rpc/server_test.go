@@ -0,0 +1,109 @@
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"sprout/pkg/sprout"
+	"sprout/pkg/sprouttest"
+)
+
+type fakeService struct {
+	worktrees []sprout.Worktree
+	notify    func(sprout.Event)
+}
+
+func (f *fakeService) RepoRoot() string { return "/repo" }
+
+func (f *fakeService) CreateWorktree(branch string, opts sprout.CreateOptions) (*sprout.Worktree, error) {
+	for _, wt := range f.worktrees {
+		if wt.Branch == branch && opts.FailIfExists {
+			return nil, fmt.Errorf("%w: %s", sprout.ErrWorktreeExists, branch)
+		}
+	}
+	wt := sprout.Worktree{Branch: branch, Path: "/worktrees/" + branch}
+	f.worktrees = append(f.worktrees, wt)
+	f.notify(sprout.Event{Kind: sprout.EventWorktreeCreated, Branch: wt.Branch, Path: wt.Path})
+	return &wt, nil
+}
+
+func (f *fakeService) ListWorktrees() ([]sprout.Worktree, error) { return f.worktrees, nil }
+
+func (f *fakeService) Prune(branch string, opts sprout.PruneOptions) error {
+	return fmt.Errorf("%w: %s", sprout.ErrWorktreeNotFound, branch)
+}
+
+func (f *fakeService) PruneMerged(opts sprout.PruneOptions) error { return nil }
+
+func (f *fakeService) SearchIssues(query string) ([]sprout.Issue, error) {
+	return nil, sprout.ErrNoIssueTracker
+}
+
+func (f *fakeService) Subscribe(handler func(sprout.Event)) func() {
+	f.notify = handler
+	return func() { f.notify = nil }
+}
+
+func TestServeAnswersRequestsAndPushesEvents(t *testing.T) {
+	requests := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"worktrees/create","params":{"branch":"login","failIfExists":true}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"worktrees/create","params":{"branch":"login","failIfExists":true}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"worktrees/list"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"worktrees/prune","params":{"branch":"gone"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"issues/search","params":{"query":"bug"}}`,
+		`{"jsonrpc":"2.0","method":"worktrees/list"}`,
+		`{"jsonrpc":"2.0","id":7,"method":"worktrees/rename"}`,
+		`{"jsonrpc":"2.0","id":8,"method":"worktrees/create","params":{"branch":7}}`,
+		`not json`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := NewServer(&fakeService{}).Serve(strings.NewReader(requests), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	expected := []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"apiVersion":"` + sprout.APIVersion + `","name":"sprout","repoRoot":"/repo"}}`,
+		`{"jsonrpc":"2.0","method":"worktrees/changed","params":{"kind":"worktreeCreated","branch":"login","path":"/worktrees/login"}}`,
+		`{"jsonrpc":"2.0","id":2,"result":{"branch":"login","path":"/worktrees/login","merged":false}}`,
+		`{"jsonrpc":"2.0","id":3,"error":{"code":1001,"message":"worktree already exists: login"}}`,
+		`{"jsonrpc":"2.0","id":4,"result":[{"branch":"login","path":"/worktrees/login","merged":false}]}`,
+		`{"jsonrpc":"2.0","id":5,"error":{"code":1002,"message":"worktree not found: gone"}}`,
+		`{"jsonrpc":"2.0","id":6,"error":{"code":1003,"message":"no issue tracker is configured"}}`,
+		`{"jsonrpc":"2.0","id":7,"error":{"code":-32601,"message":"method not found: worktrees/rename"}}`,
+		`{"jsonrpc":"2.0","id":8,"error":{"code":-32602,"message":"invalid params: json: cannot unmarshal number into Go struct field createParams.branch of type string"}}`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error: invalid character 'o' in literal null (expecting 'u')"}}`,
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d:\n%s", len(expected), len(lines), out.String())
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Fatalf("Line %d:\nexpected %s\n     got %s", i+1, expected[i], lines[i])
+		}
+	}
+}
+
+func TestServePushesAClientsChangesBeforeItsResponse(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := sprouttest.NewFakeWorktreeRepository("/code/payments")
+	client, err := sprouttest.NewClient(repo, sprout.Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	requests := `{"jsonrpc":"2.0","id":1,"method":"worktrees/create","params":{"branch":"login"}}`
+	var out bytes.Buffer
+	if err := NewServer(client).Serve(strings.NewReader(requests), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"method":"worktrees/changed"`) || !strings.Contains(lines[1], `"id":1`) {
+		t.Fatalf("Expected the notification ahead of the response, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,324 @@
+// Package schedule installs a job that runs a command on a timer in the
+// user's session: a launchd agent on macOS, a systemd user timer on Linux
+// where systemd is running, or a crontab line anywhere else
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// How often a job can run
+const (
+	Hourly = "hourly"
+	Daily  = "daily"
+	Weekly = "weekly"
+)
+
+// Intervals lists every interval a job can run at
+var Intervals = []string{Hourly, Daily, Weekly}
+
+var intervalDurations = map[string]time.Duration{
+	Hourly: time.Hour,
+	Daily:  24 * time.Hour,
+	Weekly: 7 * 24 * time.Hour,
+}
+
+// Job is a command to run on a timer
+type Job struct {
+	Name     string   // names the agent, units or crontab line, such as "gc"
+	Interval string   // one of Intervals
+	Dir      string   // where the command runs
+	Command  []string // the program, by absolute path, then its arguments
+}
+
+// InstallerInterface defines the scheduling sprout gc --schedule relies on
+type InstallerInterface interface {
+	Install(job Job) (string, error)
+	Remove(name string) (string, error)
+}
+
+// commandRunner runs a program, feeding it input when there is some
+type commandRunner func(input, name string, args ...string) ([]byte, error)
+
+// Installer schedules jobs with whatever runs them on this system
+type Installer struct {
+	goos    string
+	home    string
+	path    string // PATH for the job, so it finds git and gh where this shell does
+	systemd bool   // a systemd user manager is running
+	run     commandRunner
+}
+
+func NewInstaller() *Installer {
+	home, _ := os.UserHomeDir()
+	_, err := os.Stat("/run/systemd/system")
+	return NewInstallerWithRunner(runtime.GOOS, home, err == nil, runCommand)
+}
+
+func NewInstallerWithRunner(goos, home string, systemd bool, run commandRunner) *Installer {
+	return &Installer{goos: goos, home: home, path: os.Getenv("PATH"), systemd: systemd, run: run}
+}
+
+func runCommand(input, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	return cmd.CombinedOutput()
+}
+
+// Install schedules job, replacing any job of the same name, and says where
+// it was installed
+func (i *Installer) Install(job Job) (string, error) {
+	if !slices.Contains(Intervals, job.Interval) {
+		return "", fmt.Errorf("invalid interval %q (supported: %s)", job.Interval, strings.Join(Intervals, ", "))
+	}
+	switch {
+	case i.goos == "darwin":
+		return i.installLaunchd(job)
+	case i.goos == "windows":
+		return "", fmt.Errorf("scheduling isn't supported on Windows; have Task Scheduler run %s in %s", strings.Join(job.Command, " "), job.Dir)
+	case i.systemd:
+		return i.installSystemd(job)
+	}
+	return i.installCron(job)
+}
+
+// Remove unschedules the job called name, and says where it was removed from
+func (i *Installer) Remove(name string) (string, error) {
+	switch {
+	case i.goos == "darwin":
+		return i.removeLaunchd(name)
+	case i.goos == "windows":
+		return "", fmt.Errorf("scheduling isn't supported on Windows")
+	case i.systemd:
+		return i.removeSystemd(name)
+	}
+	return i.removeCron(name)
+}
+
+func (i *Installer) launchdPath(name string) string {
+	return filepath.Join(i.home, "Library", "LaunchAgents", "sprout."+name+".plist")
+}
+
+func (i *Installer) installLaunchd(job Job) (string, error) {
+	path := i.launchdPath(job.Name)
+	var args strings.Builder
+	for _, arg := range job.Command {
+		fmt.Fprintf(&args, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+	logPath := filepath.Join(i.home, "Library", "Logs", "sprout-"+job.Name+".log")
+	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>sprout.%s</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+	<key>WorkingDirectory</key>
+	<string>%s</string>
+	<key>EnvironmentVariables</key>
+	<dict>
+		<key>PATH</key>
+		<string>%s</string>
+	</dict>
+	<key>StartInterval</key>
+	<integer>%d</integer>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`, job.Name, args.String(), xmlEscape(job.Dir), xmlEscape(i.path), int(intervalDurations[job.Interval].Seconds()), xmlEscape(logPath), xmlEscape(logPath))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	// A loaded agent keeps its old settings until it's unloaded
+	_, _ = i.run("", "launchctl", "unload", path)
+	if err := os.WriteFile(path, []byte(plist), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if output, err := i.run("", "launchctl", "load", "-w", path); err != nil {
+		return "", fmt.Errorf("failed to load %s: %w\nOutput: %s", path, err, output)
+	}
+	return "the launchd agent " + path, nil
+}
+
+func (i *Installer) removeLaunchd(name string) (string, error) {
+	path := i.launchdPath(name)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", fmt.Errorf("nothing is scheduled: %s doesn't exist", path)
+	}
+	_, _ = i.run("", "launchctl", "unload", "-w", path)
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return "the launchd agent " + path, nil
+}
+
+func (i *Installer) systemdDir() string {
+	return filepath.Join(i.home, ".config", "systemd", "user")
+}
+
+func (i *Installer) installSystemd(job Job) (string, error) {
+	unit := "sprout-" + job.Name
+	service := fmt.Sprintf(`[Unit]
+Description=sprout %s
+
+[Service]
+Type=oneshot
+WorkingDirectory=%s
+Environment=%s
+ExecStart=%s
+`, job.Name, job.Dir, systemdQuote("PATH="+i.path), systemdCommand(job.Command))
+	timer := fmt.Sprintf(`[Unit]
+Description=Run sprout %s %s
+
+[Timer]
+OnCalendar=%s
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, job.Name, job.Interval, job.Interval)
+
+	dir := i.systemdDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, unit+".service"), []byte(service), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s.service: %w", unit, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, unit+".timer"), []byte(timer), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s.timer: %w", unit, err)
+	}
+	for _, args := range [][]string{{"--user", "daemon-reload"}, {"--user", "enable", "--now", unit + ".timer"}} {
+		if output, err := i.run("", "systemctl", args...); err != nil {
+			return "", fmt.Errorf("systemctl %s failed: %w\nOutput: %s", strings.Join(args, " "), err, output)
+		}
+	}
+	return "the systemd user timer " + unit + ".timer", nil
+}
+
+func (i *Installer) removeSystemd(name string) (string, error) {
+	unit := "sprout-" + name
+	timerPath := filepath.Join(i.systemdDir(), unit+".timer")
+	if _, err := os.Stat(timerPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("nothing is scheduled: %s doesn't exist", timerPath)
+	}
+	_, _ = i.run("", "systemctl", "--user", "disable", "--now", unit+".timer")
+	for _, path := range []string{timerPath, filepath.Join(i.systemdDir(), unit+".service")} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	_, _ = i.run("", "systemctl", "--user", "daemon-reload")
+	return "the systemd user timer " + unit + ".timer", nil
+}
+
+// cronMarker ends the crontab line for a job, so it can be found again
+func cronMarker(name string) string {
+	return "# sprout-" + name
+}
+
+func (i *Installer) installCron(job Job) (string, error) {
+	lines, err := i.crontab(job.Name)
+	if err != nil {
+		return "", err
+	}
+	var command []string
+	for _, arg := range job.Command {
+		command = append(command, shellQuote(arg))
+	}
+	line := fmt.Sprintf("@%s cd %s && PATH=%s %s", job.Interval, shellQuote(job.Dir), shellQuote(i.path), strings.Join(command, " "))
+	// cron reads an unescaped % as the end of the command
+	lines = append(lines, strings.ReplaceAll(line, "%", `\%`)+" "+cronMarker(job.Name))
+	if err := i.writeCrontab(lines); err != nil {
+		return "", err
+	}
+	return "your crontab", nil
+}
+
+func (i *Installer) removeCron(name string) (string, error) {
+	output, _ := i.run("", "crontab", "-l")
+	if !strings.Contains(string(output), cronMarker(name)) {
+		return "", fmt.Errorf("nothing is scheduled: your crontab has no sprout %s line", name)
+	}
+	lines, err := i.crontab(name)
+	if err != nil {
+		return "", err
+	}
+	if err := i.writeCrontab(lines); err != nil {
+		return "", err
+	}
+	return "your crontab", nil
+}
+
+// crontab is the user's crontab without the line for the job called name
+func (i *Installer) crontab(name string) ([]string, error) {
+	output, err := i.run("", "crontab", "-l")
+	if err != nil {
+		// crontab -l fails when there's no crontab yet
+		if strings.Contains(strings.ToLower(string(output)), "no crontab") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read your crontab: %w\nOutput: %s", err, output)
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if line != "" && !strings.HasSuffix(line, cronMarker(name)) {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+func (i *Installer) writeCrontab(lines []string) error {
+	content := strings.Join(lines, "\n") + "\n"
+	if output, err := i.run(content, "crontab", "-"); err != nil {
+		return fmt.Errorf("failed to write your crontab: %w\nOutput: %s", err, output)
+	}
+	return nil
+}
+
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// shellQuote single-quotes s for sh when it has anything sh would read
+// differently
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// systemdQuote double-quotes s for a unit file when it has spaces or
+// quotes, and doubles any % so it isn't read as a specifier
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func systemdCommand(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = systemdQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
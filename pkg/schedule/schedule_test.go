@@ -0,0 +1,126 @@
+package schedule
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeRunner records the commands run and stands in for crontab
+type fakeRunner struct {
+	commands []string
+	crontab  string
+}
+
+func (f *fakeRunner) run(input, name string, args ...string) ([]byte, error) {
+	f.commands = append(f.commands, strings.Join(append([]string{name}, args...), " "))
+	if name == "crontab" {
+		if args[0] == "-" {
+			f.crontab = input
+			return nil, nil
+		}
+		return []byte(f.crontab), nil
+	}
+	return nil, nil
+}
+
+var gcJob = Job{Name: "gc", Interval: Daily, Dir: "/repos/my project", Command: []string{"/usr/local/bin/sprout", "gc", "--all-repos", "--yes"}}
+
+func TestInstallWritesALaunchdAgentOnMacOS(t *testing.T) {
+	home := t.TempDir()
+	runner := &fakeRunner{}
+	installer := NewInstallerWithRunner("darwin", home, false, runner.run)
+
+	where, err := installer.Install(gcJob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(home, "Library", "LaunchAgents", "sprout.gc.plist")
+	if where != "the launchd agent "+path {
+		t.Errorf("unexpected description %q", where)
+	}
+	plist, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<string>sprout.gc</string>", "<string>--all-repos</string>", "<string>/repos/my project</string>", "<integer>86400</integer>"} {
+		if !strings.Contains(string(plist), want) {
+			t.Errorf("expected the plist to contain %s, got:\n%s", want, plist)
+		}
+	}
+	if last := runner.commands[len(runner.commands)-1]; last != "launchctl load -w "+path {
+		t.Errorf("expected the agent loaded, last ran %q", last)
+	}
+
+	if _, err := installer.Remove("gc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the plist removed, stat returned %v", err)
+	}
+}
+
+func TestInstallEnablesASystemdTimer(t *testing.T) {
+	home := t.TempDir()
+	runner := &fakeRunner{}
+	installer := NewInstallerWithRunner("linux", home, true, runner.run)
+
+	if _, err := installer.Install(gcJob); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(home, ".config", "systemd", "user")
+	service, err := os.ReadFile(filepath.Join(dir, "sprout-gc.service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(service), "ExecStart=/usr/local/bin/sprout gc --all-repos --yes\n") {
+		t.Errorf("unexpected service:\n%s", service)
+	}
+	timer, err := os.ReadFile(filepath.Join(dir, "sprout-gc.timer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(timer), "OnCalendar=daily\n") {
+		t.Errorf("unexpected timer:\n%s", timer)
+	}
+	if got := strings.Join(runner.commands, "; "); got != "systemctl --user daemon-reload; systemctl --user enable --now sprout-gc.timer" {
+		t.Errorf("unexpected commands: %s", got)
+	}
+}
+
+func TestInstallReplacesItsCrontabLine(t *testing.T) {
+	runner := &fakeRunner{crontab: "0 9 * * 1 backup.sh\n"}
+	installer := NewInstallerWithRunner("linux", t.TempDir(), false, runner.run)
+	installer.path = "/usr/bin:/bin"
+
+	for _, interval := range []string{Daily, Weekly} {
+		job := gcJob
+		job.Interval = interval
+		if _, err := installer.Install(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "0 9 * * 1 backup.sh\n@weekly cd '/repos/my project' && PATH=/usr/bin:/bin /usr/local/bin/sprout gc --all-repos --yes # sprout-gc\n"
+	if runner.crontab != want {
+		t.Errorf("expected crontab:\n%s\ngot:\n%s", want, runner.crontab)
+	}
+
+	if _, err := installer.Remove("gc"); err != nil {
+		t.Fatal(err)
+	}
+	if runner.crontab != "0 9 * * 1 backup.sh\n" {
+		t.Errorf("expected only the sprout line removed, got:\n%s", runner.crontab)
+	}
+	if _, err := installer.Remove("gc"); err == nil || !strings.Contains(err.Error(), "nothing is scheduled") {
+		t.Errorf("expected removing twice to say nothing is scheduled, got %v", err)
+	}
+}
+
+func TestInstallRejectsUnknownIntervals(t *testing.T) {
+	job := gcJob
+	job.Interval = "fortnightly"
+	if _, err := NewInstallerWithRunner("linux", t.TempDir(), false, (&fakeRunner{}).run).Install(job); err == nil || !strings.Contains(err.Error(), `invalid interval "fortnightly"`) {
+		t.Fatalf("expected the interval rejected, got %v", err)
+	}
+}
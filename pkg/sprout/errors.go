@@ -0,0 +1,18 @@
+package sprout
+
+import (
+	"errors"
+
+	"sprout/pkg/linear"
+)
+
+// Errors returned by Client, possibly wrapped with detail; match them with errors.Is
+var (
+	ErrNotRepository    = errors.New("not a git repository")
+	ErrEmptyBranch      = errors.New("branch name cannot be empty")
+	ErrWorktreeExists   = errors.New("worktree already exists")
+	ErrWorktreeNotFound = errors.New("worktree not found")
+	ErrNoIssueTracker   = errors.New("no issue tracker is configured")
+	ErrIssueNotFound    = errors.New("issue not found")
+	ErrTimeout          = linear.ErrTimeout // the issue tracker didn't respond within networkTimeoutSeconds
+)
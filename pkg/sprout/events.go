@@ -0,0 +1,111 @@
+package sprout
+
+// EventKind says how the worktrees changed
+type EventKind string
+
+const (
+	EventWorktreeCreated  EventKind = "worktreeCreated"
+	EventWorktreePruned   EventKind = "worktreePruned"
+	EventWorktreesChanged EventKind = "worktreesChanged" // several may have changed, such as after PruneMerged
+)
+
+// Event reports a change this Client made to the repository's worktrees
+type Event struct {
+	Kind   EventKind `json:"kind"`
+	Branch string    `json:"branch,omitempty"`
+	Path   string    `json:"path,omitempty"`
+}
+
+// Change is a change this Client made to the repository's worktrees, or an
+// issue it expanded. Each kind of change is a type of its own, so
+// Subscribe[WorktreeCreatedEvent] hears of just those, and Subscribe[Change]
+// of all of them
+type Change interface {
+	change()
+}
+
+// worktreeChange is a Change to the worktrees, which Client.Subscribe
+// reports as an Event
+type worktreeChange interface {
+	Change
+	event() Event
+}
+
+// WorktreeCreatedEvent reports a worktree CreateWorktree made
+type WorktreeCreatedEvent struct {
+	Branch string `json:"branch"`
+	Path   string `json:"path"`
+}
+
+// WorktreePrunedEvent reports a worktree Prune removed
+type WorktreePrunedEvent struct {
+	Branch string `json:"branch"`
+	Path   string `json:"path"`
+}
+
+// WorktreesChangedEvent reports that several worktrees may have changed, as
+// after PruneMerged, without saying which
+type WorktreesChangedEvent struct{}
+
+// IssueExpandedEvent reports the sub-issues IssueChildren listed for an issue
+type IssueExpandedEvent struct {
+	Identifier string  `json:"identifier"`
+	Children   []Issue `json:"children"`
+}
+
+func (WorktreeCreatedEvent) change()  {}
+func (WorktreePrunedEvent) change()   {}
+func (WorktreesChangedEvent) change() {}
+func (IssueExpandedEvent) change()    {}
+
+func (e WorktreeCreatedEvent) event() Event {
+	return Event{Kind: EventWorktreeCreated, Branch: e.Branch, Path: e.Path}
+}
+
+func (e WorktreePrunedEvent) event() Event {
+	return Event{Kind: EventWorktreePruned, Branch: e.Branch, Path: e.Path}
+}
+
+func (e WorktreesChangedEvent) event() Event {
+	return Event{Kind: EventWorktreesChanged}
+}
+
+// Subscribe calls handler with every change of type T that c makes until the
+// returned function is called. T is one of the event types, or Change for
+// all of them.
+//
+// Each subscriber is handed its changes in order on a goroutine of its own,
+// so a slow handler holds up neither c nor other subscribers. A handler that
+// panics is recovered, reported to Options.OnEventPanic, and still hears of
+// later changes. Unsubscribing waits for changes already made to be handled
+func Subscribe[T Change](c *Client, handler func(T)) (unsubscribe func()) {
+	return c.bus.Subscribe(func(change any) {
+		if event, ok := change.(T); ok {
+			handler(event)
+		}
+	})
+}
+
+// Subscribe calls handler with an Event for every change c makes to the
+// worktrees until the returned function is called. Unlike the generic
+// Subscribe, handler is called before the method making the change returns,
+// so whatever it reports goes out ahead of that method's result; a handler
+// that panics is still recovered
+func (c *Client) Subscribe(handler func(Event)) (unsubscribe func()) {
+	return c.bus.SubscribeInline(func(change any) {
+		if worktree, ok := change.(worktreeChange); ok {
+			handler(worktree.event())
+		}
+	})
+}
+
+func (c *Client) emit(change Change) {
+	c.bus.Publish(change)
+}
+
+// reportPanic passes a panicking handler on to Options.OnEventPanic
+func (c *Client) reportPanic(event, recovered any) {
+	if c.onEventPanic != nil {
+		c.onEventPanic(event.(Change), recovered)
+	}
+}
@@ -0,0 +1,148 @@
+package sprout
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"sprout/pkg/linear"
+	"sprout/pkg/linear/lineartest"
+)
+
+func TestSubscribeDeliversEventsOfItsType(t *testing.T) {
+	client := newClient("/repo", nil, nil, nil)
+
+	var created []string
+	stopCreated := Subscribe(client, func(e WorktreeCreatedEvent) {
+		created = append(created, e.Branch)
+	})
+	var all []string
+	stopAll := Subscribe(client, func(change Change) {
+		all = append(all, fmt.Sprintf("%T", change))
+	})
+
+	client.emit(WorktreeCreatedEvent{Branch: "one", Path: "/w/one"})
+	client.emit(WorktreePrunedEvent{Branch: "one", Path: "/w/one"})
+	client.emit(WorktreeCreatedEvent{Branch: "two", Path: "/w/two"})
+	client.emit(WorktreesChangedEvent{})
+	stopCreated()
+	stopAll()
+
+	if strings.Join(created, ", ") != "one, two" {
+		t.Errorf("Expected the two created events in order, got %v", created)
+	}
+	want := "sprout.WorktreeCreatedEvent, sprout.WorktreePrunedEvent, sprout.WorktreeCreatedEvent, sprout.WorktreesChangedEvent"
+	if strings.Join(all, ", ") != want {
+		t.Errorf("Expected every event in order, got %v", all)
+	}
+
+	// Unsubscribed, neither hears of more
+	client.emit(WorktreeCreatedEvent{Branch: "three"})
+	if len(created) != 2 || len(all) != 4 {
+		t.Errorf("Expected no events after unsubscribing, got %v and %v", created, all)
+	}
+}
+
+func TestSubscribeDoesNotWaitForSlowHandlers(t *testing.T) {
+	client := newClient("/repo", nil, nil, nil)
+	release := make(chan struct{})
+	var handled []string
+	stop := Subscribe(client, func(e WorktreePrunedEvent) {
+		<-release
+		handled = append(handled, e.Branch)
+	})
+
+	emitted := make(chan struct{})
+	go func() {
+		for _, branch := range []string{"one", "two", "three"} {
+			client.emit(WorktreePrunedEvent{Branch: branch})
+		}
+		close(emitted)
+	}()
+	select {
+	case <-emitted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected emitting not to wait for the handler")
+	}
+
+	close(release)
+	stop()
+	if strings.Join(handled, ", ") != "one, two, three" {
+		t.Errorf("Expected every event handled in order before unsubscribing returned, got %v", handled)
+	}
+}
+
+func TestSubscribeRecoversFromPanickingHandlers(t *testing.T) {
+	client := newClient("/repo", nil, nil, nil)
+	var panics []string
+	client.onEventPanic = func(change Change, recovered any) {
+		panics = append(panics, fmt.Sprintf("%v: %v", change.(WorktreeCreatedEvent).Branch, recovered))
+	}
+
+	var handled []string
+	stop := Subscribe(client, func(e WorktreeCreatedEvent) {
+		if e.Branch == "broken" {
+			panic("can't handle it")
+		}
+		handled = append(handled, e.Branch)
+	})
+	var others []string
+	stopOthers := client.Subscribe(func(event Event) {
+		others = append(others, event.Branch)
+	})
+
+	for _, branch := range []string{"first", "broken", "last"} {
+		client.emit(WorktreeCreatedEvent{Branch: branch})
+	}
+	stop()
+	stopOthers()
+
+	if strings.Join(handled, ", ") != "first, last" {
+		t.Errorf("Expected the handler to go on after panicking, got %v", handled)
+	}
+	if strings.Join(panics, "; ") != "broken: can't handle it" {
+		t.Errorf("Expected the panic reported, got %v", panics)
+	}
+	if strings.Join(others, ", ") != "first, broken, last" {
+		t.Errorf("Expected other subscribers unaffected, got %v", others)
+	}
+}
+
+func TestClientSubscribeHandlesWorktreeChangesBeforeReturning(t *testing.T) {
+	server := lineartest.NewServer(t)
+	server.AddIssue(linear.Issue{Identifier: "ENG-1", Title: "Payments"}, "")
+	server.AddIssue(linear.Issue{Identifier: "ENG-2", Title: "Refunds"}, "ENG-1")
+	client := newClient("/repo", nil, server.Client(), nil)
+
+	var handled []string
+	stop := client.Subscribe(func(event Event) {
+		handled = append(handled, string(event.Kind)+" "+event.Branch)
+	})
+	defer stop()
+	var expanded []IssueExpandedEvent
+	stopExpanded := Subscribe(client, func(e IssueExpandedEvent) {
+		expanded = append(expanded, e)
+	})
+
+	// Read straight after emitting, without unsubscribing first
+	client.emit(WorktreeCreatedEvent{Branch: "one"})
+	if strings.Join(handled, ", ") != "worktreeCreated one" {
+		t.Fatalf("Expected the event handled before emit returned, got %v", handled)
+	}
+
+	children, err := client.IssueChildren("ENG-1")
+	if err != nil {
+		t.Fatalf("IssueChildren failed: %v", err)
+	}
+	if len(children) != 1 || children[0].Identifier != "ENG-2" {
+		t.Fatalf("Expected ENG-2 under ENG-1, got %+v", children)
+	}
+	stopExpanded()
+	if len(expanded) != 1 || expanded[0].Identifier != "ENG-1" || len(expanded[0].Children) != 1 {
+		t.Errorf("Expected ENG-1's expansion reported, got %+v", expanded)
+	}
+	if len(handled) != 1 {
+		t.Errorf("Expected issue events kept from worktree subscribers, got %v", handled)
+	}
+}
@@ -0,0 +1,116 @@
+package sprout
+
+import (
+	"fmt"
+	"strings"
+
+	"sprout/pkg/linear"
+)
+
+// Issue is a ticket from the configured issue tracker
+type Issue struct {
+	ID         string   `json:"id"`
+	Identifier string   `json:"identifier"` // such as ENG-123
+	Title      string   `json:"title"`
+	State      string   `json:"state"`
+	URL        string   `json:"url"`
+	Priority   int      `json:"priority"` // 0 no priority, 1 urgent, 2 high, 3 medium, 4 low
+	Estimate   float64  `json:"estimate"` // 0 when the issue isn't estimated
+	Labels     []string `json:"labels"`
+	Project    string   `json:"project,omitempty"`
+	Workspace  string   `json:"workspace,omitempty"` // set when several Linear workspaces are configured
+	BranchName string   `json:"branchName"`          // the branch sprout creates for the issue
+}
+
+// AssignedIssues lists the issues assigned to the user
+func (c *Client) AssignedIssues() ([]Issue, error) {
+	if c.issues == nil {
+		return nil, ErrNoIssueTracker
+	}
+	assigned, err := c.issues.GetAssignedIssues()
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]Issue, 0, len(assigned))
+	for i := range assigned {
+		issues = append(issues, newIssue(&assigned[i]))
+	}
+	return issues, nil
+}
+
+// Issue finds the assigned issue with identifier, ignoring case
+func (c *Client) Issue(identifier string) (*Issue, error) {
+	issues, err := c.AssignedIssues()
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		if strings.EqualFold(issue.Identifier, identifier) {
+			return &issue, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s is not assigned to you", ErrIssueNotFound, identifier)
+}
+
+// IssueChildren lists the sub-issues of the issue with identifier, telling
+// subscribers with an IssueExpandedEvent
+func (c *Client) IssueChildren(identifier string) ([]Issue, error) {
+	if c.issues == nil {
+		return nil, ErrNoIssueTracker
+	}
+	parent, err := c.issues.GetIssue(identifier)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("%w: %s", ErrIssueNotFound, identifier)
+	}
+	found, err := c.issues.GetIssueChildren(parent.ID)
+	if err != nil {
+		return nil, err
+	}
+	children := make([]Issue, 0, len(found))
+	for i := range found {
+		children = append(children, newIssue(&found[i]))
+	}
+	c.emit(IssueExpandedEvent{Identifier: parent.Identifier, Children: children})
+	return children, nil
+}
+
+// SearchIssues lists the assigned issues matching every word of query, ignoring
+// case, in their identifier, title, labels or project; an empty query matches all
+func (c *Client) SearchIssues(query string) ([]Issue, error) {
+	issues, err := c.AssignedIssues()
+	if err != nil {
+		return nil, err
+	}
+	words := strings.Fields(strings.ToLower(query))
+	matches := []Issue{}
+	for _, issue := range issues {
+		text := strings.ToLower(strings.Join(append([]string{issue.Identifier, issue.Title, issue.Project}, issue.Labels...), " "))
+		matched := true
+		for _, word := range words {
+			matched = matched && strings.Contains(text, word)
+		}
+		if matched {
+			matches = append(matches, issue)
+		}
+	}
+	return matches, nil
+}
+
+func newIssue(issue *linear.Issue) Issue {
+	return Issue{
+		ID:         issue.ID,
+		Identifier: issue.Identifier,
+		Title:      issue.Title,
+		State:      issue.State.Name,
+		URL:        issue.URL,
+		Priority:   issue.Priority,
+		Estimate:   issue.Estimate,
+		Labels:     issue.LabelNames(),
+		Project:    issue.ProjectName(),
+		Workspace:  issue.Workspace,
+		BranchName: issue.GetBranchName(),
+	}
+}
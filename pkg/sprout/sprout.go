@@ -0,0 +1,209 @@
+// Package sprout is the supported Go API for embedding sprout's worktree
+// management and issue lookups in other tools.
+//
+// It follows semantic versioning, tracked by APIVersion: within a major
+// version exported names are only ever added, never removed or changed in
+// meaning, and the errors below stay matchable with errors.Is. The other
+// packages under sprout/pkg serve the sprout commands and may change in any
+// release, so embed through this one.
+package sprout
+
+import (
+	"fmt"
+	"io"
+
+	"sprout/pkg/config"
+	"sprout/pkg/events"
+	"sprout/pkg/git"
+	"sprout/pkg/internal/testhook"
+	"sprout/pkg/issues"
+	"sprout/pkg/linear"
+	"sprout/pkg/progress"
+)
+
+// APIVersion is the semantic version of this package's API
+const APIVersion = "1.3.0"
+
+// Options configures a Client
+type Options struct {
+	RepoPath string    // the repository to manage; the current directory's when empty
+	Progress io.Writer // where pruning reports what it's doing, and warnings go; discarded when nil
+
+	// OnEventPanic is told of a Subscribe handler that panicked, with the
+	// change it was handling and what it panicked with; ignored when nil
+	OnEventPanic func(change Change, recovered any)
+}
+
+// Client manages one repository's worktrees and looks up the issues assigned
+// to the user in the issue tracker their sprout config names
+type Client struct {
+	repoRoot  string
+	worktrees git.WorktreeManagerInterface
+	issues    linear.LinearClientInterface
+	progress  progress.Presenter
+	bus       events.Bus
+
+	onEventPanic func(Change, any)
+}
+
+// Worktree is a worktree of the managed repository
+type Worktree struct {
+	Branch   string `json:"branch"`
+	Path     string `json:"path"`
+	Commit   string `json:"commit,omitempty"`   // empty from CreateWorktree
+	PRStatus string `json:"prStatus,omitempty"` // "Open", "Merged", "Closed", "No PR", or "-" for the default branch
+	Merged   bool   `json:"merged"`
+}
+
+// CreateOptions customises how CreateWorktree checks out a new worktree
+type CreateOptions struct {
+	SparseDirectories []string // check out only these directories
+	BaseBranch        string   // branch to start from instead of the default branch
+	Hooks             []string // shell commands run in the worktree once it's created
+	FailIfExists      bool     // return ErrWorktreeExists rather than reuse an existing worktree
+}
+
+// PruneOptions controls what Prune and PruneMerged remove besides the
+// worktree directory
+type PruneOptions struct {
+	KeepBranch   bool // leave the local branch in place
+	DeleteRemote bool // also delete the branch on origin
+	DryRun       bool // report what would be removed without touching anything
+}
+
+// New opens the repository at opts.RepoPath, or the current directory's, and
+// the issue tracker configured in ~/.sprout.json5
+func New(opts Options) (*Client, error) {
+	repoRoot := opts.RepoPath
+	wm, faked := testhook.Worktrees(repoRoot)
+	if !faked {
+		var err error
+		if repoRoot, err = git.FindRepoRoot(opts.RepoPath); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotRepository, opts.RepoPath)
+		}
+		if wm, err = git.NewWorktreeManagerForRepo(repoRoot); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotRepository, err)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	issueClient, err := issues.NewForRepo(cfg, repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	client := newClient(repoRoot, wm, issueClient, opts.Progress)
+	wm.SetProgress(client.progress)
+	client.onEventPanic = opts.OnEventPanic
+	return client, nil
+}
+
+func newClient(repoRoot string, wm git.WorktreeManagerInterface, issueClient linear.LinearClientInterface, out io.Writer) *Client {
+	presenter := progress.Discard
+	if out != nil {
+		presenter = progress.NewText(out)
+	}
+	client := &Client{repoRoot: repoRoot, worktrees: wm, issues: issueClient, progress: presenter}
+	client.bus.OnPanic = client.reportPanic
+	return client
+}
+
+// RepoRoot is the top-level directory of the managed repository
+func (c *Client) RepoRoot() string {
+	return c.repoRoot
+}
+
+// CreateWorktree creates a worktree for branch, branching from the default
+// branch unless the branch already exists. An existing worktree for branch is
+// returned as it is unless opts.FailIfExists is set.
+func (c *Client) CreateWorktree(branch string, opts CreateOptions) (*Worktree, error) {
+	if branch == "" {
+		return nil, ErrEmptyBranch
+	}
+	if opts.FailIfExists {
+		existing, err := c.worktrees.FindExisting(branch)
+		if err != nil {
+			return nil, err
+		}
+		if existing.WorktreePath != "" {
+			return nil, fmt.Errorf("%w: %s at %s", ErrWorktreeExists, existing.Branch, existing.WorktreePath)
+		}
+	}
+
+	path, err := c.worktrees.CreateWorktreeWithOptions(branch, git.CreateOptions{
+		SparseDirectories: opts.SparseDirectories,
+		BaseBranch:        opts.BaseBranch,
+		Hooks:             opts.Hooks,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The branch is sanitized on the way in, so ask git what it ended up as
+	created, err := c.worktrees.FindExisting(branch)
+	if err != nil {
+		return nil, err
+	}
+	c.emit(WorktreeCreatedEvent{Branch: created.Branch, Path: path})
+	return &Worktree{Branch: created.Branch, Path: path}, nil
+}
+
+// ListWorktrees lists the repository's worktrees with their PR status
+func (c *Client) ListWorktrees() ([]Worktree, error) {
+	listed, err := c.worktrees.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	worktrees := make([]Worktree, 0, len(listed))
+	for _, wt := range listed {
+		worktrees = append(worktrees, Worktree{
+			Branch:   wt.Branch,
+			Path:     wt.Path,
+			Commit:   wt.Commit,
+			PRStatus: wt.PRStatus,
+			Merged:   wt.Merged || wt.PRStatus == "Merged",
+		})
+	}
+	return worktrees, nil
+}
+
+// Prune removes branch's worktree and, unless opts.KeepBranch is set, the branch
+func (c *Client) Prune(branch string, opts PruneOptions) error {
+	if branch == "" {
+		return ErrEmptyBranch
+	}
+	existing, err := c.worktrees.FindExisting(branch)
+	if err != nil {
+		return err
+	}
+	if existing.WorktreePath == "" {
+		return fmt.Errorf("%w: %s", ErrWorktreeNotFound, branch)
+	}
+	if err := c.worktrees.PruneWorktree(existing.Branch, c.pruneOptions(opts)); err != nil {
+		return err
+	}
+	if !opts.DryRun {
+		c.emit(WorktreePrunedEvent{Branch: existing.Branch, Path: existing.WorktreePath})
+	}
+	return nil
+}
+
+// PruneMerged removes every worktree whose branch has been merged
+func (c *Client) PruneMerged(opts PruneOptions) error {
+	_, err := c.worktrees.PruneAllMerged(c.pruneOptions(opts))
+	if !opts.DryRun {
+		// Some may have gone even when others failed
+		c.emit(WorktreesChangedEvent{})
+	}
+	return err
+}
+
+func (c *Client) pruneOptions(opts PruneOptions) git.PruneOptions {
+	return git.PruneOptions{
+		KeepBranch:   opts.KeepBranch,
+		DeleteRemote: opts.DeleteRemote,
+		DryRun:       opts.DryRun,
+		Progress:     c.progress,
+	}
+}
@@ -0,0 +1,106 @@
+package sprout
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sprout/pkg/gittest"
+	"sprout/pkg/linear"
+	"sprout/pkg/linear/lineartest"
+)
+
+func TestClientCreatesListsAndPrunesWorktrees(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repoRoot := gittest.New(t).Root
+	if err := os.Mkdir(filepath.Join(repoRoot, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Any directory inside the repository will do
+	client, err := New(Options{RepoPath: filepath.Join(repoRoot, "docs")})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(repoRoot); client.RepoRoot() != resolved {
+		t.Fatalf("Expected repo root %s, got %s", repoRoot, client.RepoRoot())
+	}
+
+	var events []string
+	unsubscribe := client.Subscribe(func(event Event) {
+		events = append(events, string(event.Kind)+" "+event.Branch)
+	})
+
+	created, err := client.CreateWorktree("feature-login", CreateOptions{FailIfExists: true})
+	if err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	if created.Branch != "feature-login" {
+		t.Fatalf("Expected branch feature-login, got %s", created.Branch)
+	}
+	if _, err := client.CreateWorktree("feature-login", CreateOptions{FailIfExists: true}); !errors.Is(err, ErrWorktreeExists) {
+		t.Fatalf("Expected ErrWorktreeExists, got %v", err)
+	}
+
+	worktrees, err := client.ListWorktrees()
+	if err != nil {
+		t.Fatalf("ListWorktrees failed: %v", err)
+	}
+	found := false
+	for _, wt := range worktrees {
+		found = found || (wt.Branch == "feature-login" && wt.Path == created.Path && wt.Commit != "")
+	}
+	if !found {
+		t.Fatalf("Expected feature-login at %s in %+v", created.Path, worktrees)
+	}
+
+	if err := client.Prune("feature-login", PruneOptions{}); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if _, err := os.Stat(created.Path); !os.IsNotExist(err) {
+		t.Fatalf("Expected the worktree to be removed, stat returned %v", err)
+	}
+	if err := client.Prune("feature-login", PruneOptions{}); !errors.Is(err, ErrWorktreeNotFound) {
+		t.Fatalf("Expected ErrWorktreeNotFound, got %v", err)
+	}
+	unsubscribe()
+	if strings.Join(events, ", ") != "worktreeCreated feature-login, worktreePruned feature-login" {
+		t.Fatalf("Expected a created and a pruned event, got %v", events)
+	}
+
+	if _, err := client.AssignedIssues(); !errors.Is(err, ErrNoIssueTracker) {
+		t.Fatalf("Expected ErrNoIssueTracker without a Linear key, got %v", err)
+	}
+	if _, err := New(Options{RepoPath: t.TempDir()}); !errors.Is(err, ErrNotRepository) {
+		t.Fatalf("Expected ErrNotRepository outside a repository, got %v", err)
+	}
+}
+
+func TestClientLooksUpAssignedIssues(t *testing.T) {
+	server := lineartest.NewServer(t)
+	server.AddIssue(linear.Issue{
+		Identifier: "ENG-42",
+		Title:      "Fix refund rounding",
+		State:      linear.State{Name: "Todo"},
+		Priority:   2,
+		Labels:     []linear.Label{{Name: "bug"}},
+	}, "")
+	client := newClient("/repo", nil, server.Client(), nil)
+
+	issue, err := client.Issue("eng-42")
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	if issue.Title != "Fix refund rounding" || issue.State != "Todo" || issue.Priority != 2 || strings.Join(issue.Labels, ",") != "bug" {
+		t.Fatalf("Unexpected issue %+v", issue)
+	}
+	if issue.BranchName != "eng-42-fix-refund-rounding" {
+		t.Fatalf("Expected the branch sprout would create, got %s", issue.BranchName)
+	}
+
+	if _, err := client.Issue("ENG-7"); !errors.Is(err, ErrIssueNotFound) {
+		t.Fatalf("Expected ErrIssueNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+package sprouttest
+
+import (
+	"sprout/pkg/internal/testhook"
+	"sprout/pkg/sprout"
+)
+
+// NewClient opens a sprout.Client on repo instead of a repository on disk,
+// taking repo.Root as the repository's root. opts.RepoPath is ignored
+func NewClient(repo *FakeWorktreeRepository, opts sprout.Options) (*sprout.Client, error) {
+	unset := testhook.SetWorktrees(repo.Root, repo)
+	defer unset()
+	opts.RepoPath = repo.Root
+	return sprout.New(opts)
+}
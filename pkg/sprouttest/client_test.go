@@ -0,0 +1,45 @@
+package sprouttest
+
+import (
+	"strings"
+	"testing"
+
+	"sprout/pkg/sprout"
+)
+
+func TestNewClientManagesAFakeRepository(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := NewFakeWorktreeRepository("/code/payments")
+	repo.AddWorktree("eng-1-done")
+	repo.Statuses.SetPR("eng-1-done", "Merged")
+
+	client, err := NewClient(repo, sprout.Options{})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if client.RepoRoot() != "/code/payments" {
+		t.Fatalf("Expected the fake's root, got %s", client.RepoRoot())
+	}
+	if _, err := client.CreateWorktree("eng-2-next", sprout.CreateOptions{FailIfExists: true}); err != nil {
+		t.Fatalf("CreateWorktree failed: %v", err)
+	}
+	if err := client.PruneMerged(sprout.PruneOptions{}); err != nil {
+		t.Fatalf("PruneMerged failed: %v", err)
+	}
+
+	worktrees, err := client.ListWorktrees()
+	if err != nil {
+		t.Fatalf("ListWorktrees failed: %v", err)
+	}
+	var branches []string
+	for _, wt := range worktrees {
+		branches = append(branches, wt.Branch)
+	}
+	if strings.Join(branches, ", ") != "main, eng-2-next" {
+		t.Fatalf("Expected the merged worktree pruned, got %v", branches)
+	}
+
+	if _, err := sprout.New(sprout.Options{RepoPath: repo.Root}); err == nil {
+		t.Fatalf("Expected the fake forgotten once the client was opened")
+	}
+}
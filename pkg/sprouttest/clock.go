@@ -0,0 +1,33 @@
+package sprouttest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a time that only moves when a test moves it, for anything that
+// takes a func() time.Time, such as ui.Deterministic. It's
+// safe to read from one goroutine while another moves it
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock is a clock stopped at now
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now is the time the clock is stopped at
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock on by d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
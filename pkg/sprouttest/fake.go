@@ -0,0 +1,565 @@
+// Package sprouttest helps test code built on sprout without a real
+// repository. FakeWorktreeRepository keeps worktrees in memory, NewClient
+// opens a sprout.Client on one, Statuses stands in for GitHub's PR and CI
+// statuses, and RepoFixture builds throwaway git repositories for the tests
+// that do need real ones.
+package sprouttest
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"sprout/pkg/git"
+	"sprout/pkg/github"
+	"sprout/pkg/gittest"
+	"sprout/pkg/progress"
+)
+
+// DefaultBranch is the branch a FakeWorktreeRepository's main checkout, and
+// a RepoFixture, is on
+const DefaultBranch = gittest.DefaultBranch
+
+// FakeWorktreeRepository is a git.WorktreeManagerInterface keeping worktrees
+// in memory. Worktrees go in WorktreesDir, commits are made up, and
+// nothing on disk is touched, so it's safe to use from parallel tests. Set
+// its fields up before handing it over; afterwards read them back through
+// its methods, which are safe to call from several goroutines
+type FakeWorktreeRepository struct {
+	Root         string         // the main checkout
+	WorktreesDir string         // where worktrees are added; .worktrees beside Root when empty
+	Worktrees    []git.Worktree // in the order they're listed, the main checkout first
+	Branches     []string       // local branches without a worktree
+	Statuses     *Statuses      // the PR and CI statuses listed worktrees get
+
+	mu       sync.Mutex
+	commits  int
+	archived []git.Worktree
+	trashed  []git.Worktree // removed by the last prune, for UndoPrune
+}
+
+// NewFakeWorktreeRepository fakes a repository at root with only its main
+// checkout, on DefaultBranch, and no statuses yet
+func NewFakeWorktreeRepository(root string) *FakeWorktreeRepository {
+	r := &FakeWorktreeRepository{Root: root, Statuses: &Statuses{}}
+	r.Worktrees = []git.Worktree{{Path: root, Branch: DefaultBranch, Commit: r.nextCommit()}}
+	return r
+}
+
+// AddWorktree adds a worktree for branch where CreateWorktree would, for
+// setting up what's there before a test starts
+func (r *FakeWorktreeRepository) AddWorktree(branch string) git.Worktree {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wt := git.Worktree{Path: r.pathFor(branch), Branch: branch, Commit: r.nextCommit()}
+	r.Worktrees = append(r.Worktrees, wt)
+	return wt
+}
+
+func (r *FakeWorktreeRepository) pathFor(branch string) string {
+	return filepath.Join(r.worktreesDir(), branch)
+}
+
+func (r *FakeWorktreeRepository) worktreesDir() string {
+	if r.WorktreesDir != "" {
+		return r.WorktreesDir
+	}
+	return filepath.Join(filepath.Dir(r.Root), ".worktrees")
+}
+
+// nextCommit makes up a commit that looks like git's
+func (r *FakeWorktreeRepository) nextCommit() string {
+	r.commits++
+	return fmt.Sprintf("%040x", r.commits)
+}
+
+// find is the index of branch's worktree, or -1
+func (r *FakeWorktreeRepository) find(branch string) int {
+	return slices.IndexFunc(r.Worktrees, func(wt git.Worktree) bool { return wt.Branch == branch })
+}
+
+func (r *FakeWorktreeRepository) CreateWorktree(branchName string) (string, error) {
+	return r.CreateWorktreeWithOptions(branchName, git.CreateOptions{})
+}
+
+// CreateWorktreeWithOptions adds a worktree for branchName, or returns the
+// one it already has. Checkout options, setup steps and hooks are ignored
+func (r *FakeWorktreeRepository) CreateWorktreeWithOptions(branchName string, opts git.CreateOptions) (string, error) {
+	plan, err := r.PlanWorktree(branchName, opts)
+	if err != nil || plan.Reuse {
+		return plan.Path, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Branches = slices.DeleteFunc(r.Branches, func(b string) bool { return b == plan.Branch })
+	r.Worktrees = append(r.Worktrees, git.Worktree{Path: plan.Path, Branch: plan.Branch, Commit: r.nextCommit()})
+	return plan.Path, nil
+}
+
+// PlanWorktree plans a worktree where CreateWorktreeWithOptions would put it
+func (r *FakeWorktreeRepository) PlanWorktree(branchName string, opts git.CreateOptions) (git.CreatePlan, error) {
+	branch, err := git.ValidateBranchName(branchName)
+	if err != nil {
+		return git.CreatePlan{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	plan := git.CreatePlan{
+		Branch:            branch,
+		Path:              r.pathFor(branch),
+		BranchExists:      slices.Contains(r.Branches, branch),
+		Base:              DefaultBranch,
+		SparseDirectories: opts.SparseDirectories,
+		GitHooks:          opts.GitHooks,
+		Submodules:        opts.Submodules,
+		LFS:               opts.LFS,
+		Hooks:             opts.Hooks,
+	}
+	if opts.BaseBranch != "" {
+		plan.Base = opts.BaseBranch
+	}
+	if i := r.find(branch); i >= 0 {
+		plan.Path, plan.Reuse, plan.BranchExists = r.Worktrees[i].Path, true, true
+	}
+	return plan, nil
+}
+
+// CreateBranch adds a local branch without a worktree, unless there's a
+// branch by that name already
+func (r *FakeWorktreeRepository) CreateBranch(branchName string) error {
+	branch, err := git.ValidateBranchName(branchName)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.find(branch) < 0 && !slices.Contains(r.Branches, branch) {
+		r.Branches = append(r.Branches, branch)
+	}
+	return nil
+}
+
+// ListWorktrees lists the worktrees with the PR statuses Statuses gives them
+func (r *FakeWorktreeRepository) ListWorktrees() ([]git.Worktree, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	listed := make([]git.Worktree, len(r.Worktrees))
+	for i, wt := range r.Worktrees {
+		listed[i] = r.Statuses.apply(wt)
+	}
+	return listed, nil
+}
+
+func (r *FakeWorktreeRepository) ListWorktreesForTUI() ([]git.Worktree, error) {
+	listed, err := r.ListWorktrees()
+	if err == nil {
+		r.LookUpCIStatuses(listed)
+	}
+	return listed, err
+}
+
+func (r *FakeWorktreeRepository) ListWorktreesForTUIWithProgress(progress.Presenter) ([]git.Worktree, error) {
+	return r.ListWorktreesForTUI()
+}
+
+// LookUpCIStatuses gives worktrees the CI statuses Statuses gives them
+func (r *FakeWorktreeRepository) LookUpCIStatuses(worktrees []git.Worktree) {
+	for i := range worktrees {
+		worktrees[i] = r.Statuses.applyCI(worktrees[i])
+	}
+}
+
+// PruneWorktree removes branchName's worktree and, unless opts.KeepBranch is
+// set, its branch. A pinned worktree is refused, as sprout prune refuses it
+func (r *FakeWorktreeRepository) PruneWorktree(branchName string, opts git.PruneOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.find(branchName)
+	if i < 0 {
+		return fmt.Errorf("worktree does not exist: %s", branchName)
+	}
+	if r.Worktrees[i].Pinned {
+		return fmt.Errorf("worktree %s is pinned; run sprout unpin %s first", branchName, branchName)
+	}
+	if !opts.DryRun {
+		r.trashed = []git.Worktree{r.remove(i, opts)}
+	}
+	return nil
+}
+
+// remove takes the worktree at i out of the list, keeping its branch when
+// opts asks to
+func (r *FakeWorktreeRepository) remove(i int, opts git.PruneOptions) git.Worktree {
+	wt := r.Worktrees[i]
+	r.Worktrees = slices.Delete(r.Worktrees, i, i+1)
+	if opts.KeepBranch {
+		r.Branches = append(r.Branches, wt.Branch)
+	}
+	return wt
+}
+
+// PruneAllMerged prunes every worktree whose PR is merged
+func (r *FakeWorktreeRepository) PruneAllMerged(opts git.PruneOptions) (git.PruneResult, error) {
+	merged, err := r.MergedWorktrees()
+	if err != nil {
+		return git.PruneResult{}, err
+	}
+	return r.PruneMergedWorktrees(merged, opts)
+}
+
+// MergedWorktrees lists the worktrees besides the main checkout whose PR
+// is merged
+func (r *FakeWorktreeRepository) MergedWorktrees() ([]git.Worktree, error) {
+	listed, err := r.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	var merged []git.Worktree
+	for _, wt := range listed {
+		if wt.Merged && wt.Path != r.Root {
+			merged = append(merged, wt)
+		}
+	}
+	return merged, nil
+}
+
+// PruneMergedWorktrees prunes each of worktrees, skipping pinned ones
+func (r *FakeWorktreeRepository) PruneMergedWorktrees(worktrees []git.Worktree, opts git.PruneOptions) (git.PruneResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result git.PruneResult
+	var trashed []git.Worktree
+	for _, pruned := range worktrees {
+		outcome := git.PruneOutcome{Branch: pruned.Branch, Path: pruned.Path, Status: git.PrunePruned}
+		i := r.find(pruned.Branch)
+		switch {
+		case i < 0:
+			outcome.Status, outcome.Reason = git.PruneFailed, "worktree does not exist"
+		case r.Worktrees[i].Pinned:
+			outcome.Status, outcome.Reason = git.PruneSkipped, "pinned"
+		case opts.DryRun:
+			outcome.Status = git.PruneWouldPrune
+		default:
+			trashed = append(trashed, r.remove(i, opts))
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(outcome)
+		}
+		result.Outcomes = append(result.Outcomes, outcome)
+	}
+	if len(trashed) > 0 {
+		r.trashed = trashed
+	}
+	return result, nil
+}
+
+// HasUncommittedChanges reports every worktree clean
+func (r *FakeWorktreeRepository) HasUncommittedChanges(worktreePath string) bool {
+	return false
+}
+
+// PruneLargerThan prunes the worktrees besides the main checkout whose
+// DiskUsage is over threshold
+func (r *FakeWorktreeRepository) PruneLargerThan(threshold int64, opts git.PruneOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if opts.DryRun {
+		return nil
+	}
+	r.Worktrees = slices.DeleteFunc(r.Worktrees, func(wt git.Worktree) bool {
+		return wt.Path != r.Root && wt.DiskUsage > threshold && !wt.Pinned
+	})
+	return nil
+}
+
+// ApplySparseCheckout checks branchName has a worktree
+func (r *FakeWorktreeRepository) ApplySparseCheckout(branchName string, directories []string) error {
+	return r.withWorktree(branchName, func(*git.Worktree) {})
+}
+
+// Repair finds nothing to fix
+func (r *FakeWorktreeRepository) Repair() (*git.RepairReport, error) {
+	return &git.RepairReport{}, nil
+}
+
+// FindExisting reports the worktree or local branch for branchName
+func (r *FakeWorktreeRepository) FindExisting(branchName string) (git.ExistingBranch, error) {
+	branch, err := git.ValidateBranchName(branchName)
+	if err != nil {
+		return git.ExistingBranch{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing := git.ExistingBranch{Branch: branch, BranchExists: slices.Contains(r.Branches, branch)}
+	if i := r.find(branch); i >= 0 {
+		existing.WorktreePath, existing.BranchExists = r.Worktrees[i].Path, true
+	}
+	return existing, nil
+}
+
+// ArchiveWorktree moves branchName's worktree out of the list until
+// RestoreWorktree puts it back. A pinned worktree is refused
+func (r *FakeWorktreeRepository) ArchiveWorktree(branchName string) (*git.Archive, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.find(branchName)
+	if i < 0 {
+		return nil, fmt.Errorf("worktree does not exist: %s", branchName)
+	}
+	if r.Worktrees[i].Pinned {
+		return nil, fmt.Errorf("%s is pinned; sprout unpin %s first to archive it", branchName, branchName)
+	}
+	wt := r.remove(i, git.PruneOptions{KeepBranch: true})
+	r.archived = append(r.archived, wt)
+	return &git.Archive{Branch: branchName, Head: wt.Commit, Dir: filepath.Join(filepath.Dir(wt.Path), git.ArchiveDirName, branchName)}, nil
+}
+
+// RestoreWorktree puts an archived worktree back in the list
+func (r *FakeWorktreeRepository) RestoreWorktree(branchName string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.archived, func(wt git.Worktree) bool { return wt.Branch == branchName })
+	if i < 0 {
+		return "", fmt.Errorf("no archive found for %s in %s", branchName, filepath.Join(r.worktreesDir(), git.ArchiveDirName))
+	}
+	wt := r.archived[i]
+	r.archived = slices.Delete(r.archived, i, i+1)
+	r.Branches = slices.DeleteFunc(r.Branches, func(b string) bool { return b == branchName })
+	r.Worktrees = append(r.Worktrees, wt)
+	return wt.Path, nil
+}
+
+// ExportWorktree reports exporting branchName's worktree to path without
+// writing anything
+func (r *FakeWorktreeRepository) ExportWorktree(branchName, path string) (*git.Archive, error) {
+	var archive *git.Archive
+	err := r.withWorktree(branchName, func(wt *git.Worktree) {
+		archive = &git.Archive{Branch: branchName, Head: wt.Commit, Dir: path}
+	})
+	return archive, err
+}
+
+// ImportWorktree adds a worktree for the branch the exported file at path
+// is named after
+func (r *FakeWorktreeRepository) ImportWorktree(path string) (*git.Archive, string, error) {
+	branch := strings.TrimSuffix(filepath.Base(path), git.HandoffExtension)
+	worktreePath, err := r.CreateWorktree(branch)
+	if err != nil {
+		return nil, "", err
+	}
+	return &git.Archive{Branch: branch, Dir: path}, worktreePath, nil
+}
+
+// RenameWorktree renames oldBranch's worktree and moves its path to match
+func (r *FakeWorktreeRepository) RenameWorktree(oldBranch, newBranch string) (git.Worktree, error) {
+	branch, err := git.ValidateBranchName(newBranch)
+	if err != nil {
+		return git.Worktree{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.find(branch) >= 0 || slices.Contains(r.Branches, branch) {
+		return git.Worktree{}, fmt.Errorf("branch %s already exists", branch)
+	}
+	i := r.find(oldBranch)
+	if i < 0 {
+		return git.Worktree{}, fmt.Errorf("worktree does not exist: %s", oldBranch)
+	}
+	r.Worktrees[i].Branch, r.Worktrees[i].Path = branch, r.pathFor(branch)
+	return r.Worktrees[i], nil
+}
+
+// UndoPrune puts the worktrees the last prune removed back in the list
+func (r *FakeWorktreeRepository) UndoPrune() ([]git.TrashedWorktree, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.trashed) == 0 {
+		return nil, git.ErrNothingToUndo
+	}
+	var restored []git.TrashedWorktree
+	for _, wt := range r.trashed {
+		r.Branches = slices.DeleteFunc(r.Branches, func(b string) bool { return b == wt.Branch })
+		r.Worktrees = append(r.Worktrees, wt)
+		restored = append(restored, git.TrashedWorktree{Branch: wt.Branch, Path: wt.Path, Head: wt.Commit})
+	}
+	r.trashed = nil
+	return restored, nil
+}
+
+// GCCandidates applies policy to the listed worktrees
+func (r *FakeWorktreeRepository) GCCandidates(policy git.GCPolicy) ([]git.GCCandidate, error) {
+	listed, err := r.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	var candidates []git.GCCandidate
+	for _, wt := range listed {
+		if candidate, ok := policy.Collects(wt); ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
+// PurgeExpiredTrash finds nothing expired, as nothing is kept in a trash
+func (r *FakeWorktreeRepository) PurgeExpiredTrash(dryRun bool) ([]git.TrashedWorktree, error) {
+	return nil, nil
+}
+
+// CheckGitHooks finds nothing wrong with the worktrees' hooks
+func (r *FakeWorktreeRepository) CheckGitHooks() ([]string, error) {
+	return nil, nil
+}
+
+// SetProgress has nothing to warn about
+func (r *FakeWorktreeRepository) SetProgress(presenter progress.Presenter) {}
+
+// StartTimer has no time tracker to start
+func (r *FakeWorktreeRepository) StartTimer(branchName, worktreePath string) error {
+	return nil
+}
+
+// PinWorktree pins and locks branchName's worktree
+func (r *FakeWorktreeRepository) PinWorktree(branchName string) error {
+	return r.withWorktree(branchName, func(wt *git.Worktree) { wt.Pinned, wt.Locked = true, true })
+}
+
+// UnpinWorktree unpins and unlocks branchName's worktree
+func (r *FakeWorktreeRepository) UnpinWorktree(branchName string) error {
+	pinned := false
+	err := r.withWorktree(branchName, func(wt *git.Worktree) {
+		pinned = wt.Pinned
+		wt.Pinned, wt.Locked = false, false
+	})
+	if err == nil && !pinned {
+		return fmt.Errorf("%s isn't pinned", branchName)
+	}
+	return err
+}
+
+// SetNote keeps note on branchName's worktree
+func (r *FakeWorktreeRepository) SetNote(branchName, note string) error {
+	return r.withWorktree(branchName, func(wt *git.Worktree) { wt.Note = note })
+}
+
+// withWorktree calls change with branchName's worktree
+func (r *FakeWorktreeRepository) withWorktree(branchName string, change func(*git.Worktree)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.find(branchName)
+	if i < 0 {
+		return fmt.Errorf("worktree does not exist: %s", branchName)
+	}
+	change(&r.Worktrees[i])
+	return nil
+}
+
+// Stack is a stack of branchName alone, on DefaultBranch
+func (r *FakeWorktreeRepository) Stack(branchName string) (git.Stack, error) {
+	var stack git.Stack
+	err := r.withWorktree(branchName, func(wt *git.Worktree) {
+		stack = git.Stack{Branch: branchName, Trunk: DefaultBranch, Branches: []git.StackBranch{{Branch: branchName, Parent: DefaultBranch, Path: wt.Path}}}
+	})
+	if err != nil {
+		return git.Stack{}, fmt.Errorf("branch %s does not exist", branchName)
+	}
+	return stack, nil
+}
+
+// Restack has nothing to rebase in a stack of one
+func (r *FakeWorktreeRepository) Restack(branchName string, presenter progress.Presenter) (git.Stack, error) {
+	return r.Stack(branchName)
+}
+
+// CheckoutPR takes ref as an open PR's branch, reusing its worktree or
+// adding one
+func (r *FakeWorktreeRepository) CheckoutPR(ref string, opts git.CreateOptions) (git.PRCheckout, error) {
+	plan, err := r.PlanWorktree(ref, opts)
+	if err != nil {
+		return git.PRCheckout{}, err
+	}
+	checkout := git.PRCheckout{PullRequest: github.PullRequest{HeadBranch: plan.Branch, State: "Open"}}
+	checkout.WorktreePath, err = r.CreateWorktreeWithOptions(ref, opts)
+	checkout.Created = err == nil && !plan.Reuse
+	return checkout, err
+}
+
+// Where is branchName's worktree, or where CreateWorktree would add one.
+// With no branch name it's the main checkout's
+func (r *FakeWorktreeRepository) Where(branchName string) (git.Location, error) {
+	if branchName == "" {
+		branchName = DefaultBranch
+	}
+	plan, err := r.PlanWorktree(branchName, git.CreateOptions{})
+	if err != nil {
+		return git.Location{}, err
+	}
+	return git.Location{
+		Branch:        plan.Branch,
+		Path:          plan.Path,
+		Exists:        plan.Reuse,
+		RepoRoot:      r.Root,
+		WorktreesRoot: r.worktreesDir(),
+	}, nil
+}
+
+// Statuses is what GitHub says of each branch's PR and the checks on it,
+// for a FakeWorktreeRepository to list its worktrees with. Its zero value
+// knows of no PRs, and it's safe to change from another goroutine while
+// worktrees are listed
+type Statuses struct {
+	mu  sync.Mutex
+	prs map[string]string
+	ci  map[string]string
+}
+
+// SetPR gives branch's PR status: "Open", "Merged", "Closed" or "No PR".
+// A worktree is merged once its PR is
+func (s *Statuses) SetPR(branch, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.prs == nil {
+		s.prs = map[string]string{}
+	}
+	s.prs[branch] = status
+}
+
+// SetCI gives what the checks on branch's commit add up to, such as
+// github.CIPassing
+func (s *Statuses) SetCI(branch, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ci == nil {
+		s.ci = map[string]string{}
+	}
+	s.ci[branch] = status
+}
+
+// apply gives wt the PR status set for its branch
+func (s *Statuses) apply(wt git.Worktree) git.Worktree {
+	if s == nil {
+		return wt
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status, ok := s.prs[wt.Branch]; ok {
+		wt.PRStatus = status
+		wt.Merged = wt.Merged || status == "Merged"
+	}
+	return wt
+}
+
+// applyCI gives wt the CI status set for its branch
+func (s *Statuses) applyCI(wt git.Worktree) git.Worktree {
+	if s == nil {
+		return wt
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status, ok := s.ci[wt.Branch]; ok {
+		wt.CIStatus = status
+	}
+	return wt
+}
@@ -0,0 +1,58 @@
+package sprouttest
+
+import (
+	"errors"
+	"testing"
+
+	"sprout/pkg/git"
+)
+
+func TestFakeWorktreeRepositoryPrunesMergedWorktrees(t *testing.T) {
+	var repo git.WorktreeManagerInterface = NewFakeWorktreeRepository("/code/app")
+	fake := repo.(*FakeWorktreeRepository)
+	fake.AddWorktree("fix/login")
+	fake.Statuses.SetPR("fix/login", "Merged")
+	fake.Statuses.SetCI("fix/login", "passing")
+
+	path, err := repo.CreateWorktree("feature/search")
+	if err != nil || path != "/code/.worktrees/feature/search" {
+		t.Fatalf("expected feature/search beside the main checkout, got %s (%v)", path, err)
+	}
+	if again, _ := repo.CreateWorktree("feature/search"); again != path {
+		t.Fatalf("expected the worktree reused, got %s", again)
+	}
+
+	merged, err := repo.MergedWorktrees()
+	if err != nil || len(merged) != 1 || merged[0].Branch != "fix/login" || merged[0].CIStatus != "" {
+		t.Fatalf("expected only fix/login merged, without its CI status, got %+v (%v)", merged, err)
+	}
+	if repo.LookUpCIStatuses(merged); merged[0].CIStatus != "passing" {
+		t.Fatalf("expected fix/login's CI status once looked up, got %+v", merged[0])
+	}
+	result, err := repo.PruneAllMerged(git.PruneOptions{})
+	if err != nil || result.Count(git.PrunePruned) != 1 {
+		t.Fatalf("expected fix/login pruned, got %+v (%v)", result, err)
+	}
+	if existing, _ := repo.FindExisting("fix/login"); existing.Found() {
+		t.Fatalf("expected fix/login gone with its branch, got %+v", existing)
+	}
+
+	restored, err := repo.UndoPrune()
+	if err != nil || len(restored) != 1 || restored[0].Branch != "fix/login" {
+		t.Fatalf("expected fix/login restored, got %+v (%v)", restored, err)
+	}
+	if _, err := repo.UndoPrune(); !errors.Is(err, git.ErrNothingToUndo) {
+		t.Fatalf("expected nothing left to undo, got %v", err)
+	}
+
+	if err := repo.PinWorktree("feature/search"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.PruneWorktree("feature/search", git.PruneOptions{}); err == nil {
+		t.Fatalf("expected a pinned worktree kept")
+	}
+	listed, _ := repo.ListWorktrees()
+	if len(listed) != 3 || listed[0].Branch != DefaultBranch || listed[0].Path != "/code/app" {
+		t.Fatalf("expected the main checkout and two worktrees, got %+v", listed)
+	}
+}
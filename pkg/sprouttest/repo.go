@@ -0,0 +1,17 @@
+package sprouttest
+
+import (
+	"testing"
+
+	"sprout/pkg/gittest"
+)
+
+// RepoFixture is a throwaway git repository, for the tests that need a
+// real one. It's gittest's, which sprout's own packages test with too
+type RepoFixture = gittest.Repo
+
+// NewRepoFixture makes a repository with one commit on DefaultBranch
+func NewRepoFixture(t testing.TB) *RepoFixture {
+	t.Helper()
+	return gittest.New(t)
+}
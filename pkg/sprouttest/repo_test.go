@@ -0,0 +1,33 @@
+package sprouttest
+
+import (
+	"testing"
+
+	"sprout/pkg/git"
+)
+
+func TestRepoFixtureBuildsARepositorySproutCanManage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	fixture := NewRepoFixture(t)
+	fixture.Remote("origin")
+	path := fixture.Worktree("fix/login")
+	fixture.Git("-C", path, "commit", "--allow-empty", "--quiet", "-m", "Fix the login form")
+	fixture.Push("fix/login")
+	fixture.Merge("fix/login")
+
+	if again := NewRepoFixture(t); again.Git("rev-parse", "HEAD") != fixture.Git("rev-parse", "HEAD~1") {
+		t.Fatalf("expected the same steps to make the same commits")
+	}
+	if remote := fixture.Git("rev-parse", "--abbrev-ref", "origin/HEAD"); remote != "origin/"+DefaultBranch {
+		t.Fatalf("expected origin/HEAD at %s, got %s", DefaultBranch, remote)
+	}
+
+	wm, err := git.NewWorktreeManagerForRepo(fixture.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing, err := wm.FindExisting("fix/login")
+	if err != nil || !existing.BranchExists || existing.WorktreePath == "" {
+		t.Fatalf("expected fix/login checked out in a worktree, got %+v (%v)", existing, err)
+	}
+}
@@ -0,0 +1,103 @@
+// Package startup times the phases sprout goes through before it's ready,
+// for sprout --profile-startup
+package startup
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Phase is one step of starting up and how long it took
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Profile records phases as they finish. A nil Profile records nothing, so
+// code can mark phases whether or not anyone asked for the timings
+type Profile struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	start  time.Time
+	last   time.Time
+	phases []Phase
+	marked map[string]bool
+}
+
+// New starts timing from now
+func New() *Profile {
+	return NewWithClock(time.Now)
+}
+
+// NewWithClock starts timing with now as the clock, for tests
+func NewWithClock(now func() time.Time) *Profile {
+	start := now()
+	return &Profile{now: now, start: start, last: start, marked: make(map[string]bool)}
+}
+
+// Mark records that the phase called name has just finished, taking the time
+// since the last phase finished
+func (p *Profile) Mark(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	p.phases = append(p.phases, Phase{Name: name, Duration: now.Sub(p.last)})
+	p.marked[name] = true
+	p.last = now
+}
+
+// MarkOnce is Mark for a phase that can finish more than once, such as
+// rendering, where only the first time counts
+func (p *Profile) MarkOnce(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	done := p.marked[name]
+	p.mu.Unlock()
+	if !done {
+		p.Mark(name)
+	}
+}
+
+// Phases returns the phases recorded so far, in the order they finished
+func (p *Profile) Phases() []Phase {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Phase(nil), p.phases...)
+}
+
+// Report writes each phase's time to w, then the total
+func (p *Profile) Report(w io.Writer) {
+	if p == nil {
+		return
+	}
+	phases := p.Phases()
+	p.mu.Lock()
+	total := p.last.Sub(p.start)
+	p.mu.Unlock()
+
+	width := len("total")
+	for _, phase := range phases {
+		width = max(width, len(phase.Name))
+	}
+	fmt.Fprintln(w, "Startup profile:")
+	for _, phase := range phases {
+		fmt.Fprintf(w, "  %-*s %8s\n", width, phase.Name, format(phase.Duration))
+	}
+	fmt.Fprintf(w, "  %-*s %8s\n", width, "total", format(total))
+}
+
+// format shows a duration in milliseconds to a tenth, which is as fine as
+// startup timings are worth reading
+func format(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,43 @@
+package startup
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestReportListsPhasesAndTheTotal(t *testing.T) {
+	clock := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	profile := NewWithClock(func() time.Time { return clock })
+
+	clock = clock.Add(12 * time.Millisecond)
+	profile.Mark("find repository")
+	clock = clock.Add(1500 * time.Microsecond)
+	profile.Mark("load config")
+	clock = clock.Add(40 * time.Millisecond)
+	profile.MarkOnce("first render")
+	clock = clock.Add(time.Second)
+	profile.MarkOnce("first render")
+
+	var out bytes.Buffer
+	profile.Report(&out)
+	want := "Startup profile:\n" +
+		"  find repository   12.0ms\n" +
+		"  load config        1.5ms\n" +
+		"  first render      40.0ms\n" +
+		"  total             53.5ms\n"
+	if out.String() != want {
+		t.Errorf("expected report:\n%s\ngot:\n%s", want, out.String())
+	}
+}
+
+func TestNilProfileRecordsNothing(t *testing.T) {
+	var profile *Profile
+	profile.Mark("find repository")
+	profile.MarkOnce("first render")
+	var out bytes.Buffer
+	profile.Report(&out)
+	if out.Len() != 0 || profile.Phases() != nil {
+		t.Errorf("expected a nil profile to stay quiet, got %q", out.String())
+	}
+}
@@ -0,0 +1,239 @@
+package stats
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sprout/pkg/metadata"
+)
+
+// maxConcurrentSizeScans bounds how many directory trees are walked at once
+const maxConcurrentSizeScans = 4
+
+// DiskUsageMaxAge is how long a cached worktree size is trusted before re-measuring
+const DiskUsageMaxAge = time.Hour
+
+// PeriodCount holds the number of worktrees created and pruned in a month
+type PeriodCount struct {
+	Period  string
+	Created int
+	Pruned  int
+}
+
+// IssueCount holds how many worktrees were created for an issue
+type IssueCount struct {
+	Issue     string
+	Worktrees int
+}
+
+// Summary aggregates the recorded worktree history for a repository
+type Summary struct {
+	Created         int
+	Pruned          int
+	Active          int
+	AverageLifetime time.Duration
+	ByMonth         []PeriodCount
+	TopIssues       []IssueCount
+}
+
+// Summarize computes counts and lifetimes from metadata records; only pruned
+// worktrees contribute to the average lifetime
+func Summarize(records []metadata.WorktreeRecord, topIssues int) Summary {
+	var summary Summary
+	var totalLifetime time.Duration
+	months := make(map[string]*PeriodCount)
+	issues := make(map[string]int)
+
+	monthFor := func(t time.Time) *PeriodCount {
+		key := t.Format("2006-01")
+		if months[key] == nil {
+			months[key] = &PeriodCount{Period: key}
+		}
+		return months[key]
+	}
+
+	for _, record := range records {
+		summary.Created++
+		monthFor(record.CreatedAt).Created++
+		if record.Issue != "" {
+			issues[record.Issue]++
+		}
+
+		if record.Active() {
+			summary.Active++
+			continue
+		}
+		summary.Pruned++
+		monthFor(*record.PrunedAt).Pruned++
+		totalLifetime += record.PrunedAt.Sub(record.CreatedAt)
+	}
+
+	if summary.Pruned > 0 {
+		summary.AverageLifetime = totalLifetime / time.Duration(summary.Pruned)
+	}
+
+	for _, count := range months {
+		summary.ByMonth = append(summary.ByMonth, *count)
+	}
+	sort.Slice(summary.ByMonth, func(i, j int) bool {
+		return summary.ByMonth[i].Period < summary.ByMonth[j].Period
+	})
+
+	for issue, count := range issues {
+		summary.TopIssues = append(summary.TopIssues, IssueCount{Issue: issue, Worktrees: count})
+	}
+	sort.Slice(summary.TopIssues, func(i, j int) bool {
+		if summary.TopIssues[i].Worktrees != summary.TopIssues[j].Worktrees {
+			return summary.TopIssues[i].Worktrees > summary.TopIssues[j].Worktrees
+		}
+		return summary.TopIssues[i].Issue < summary.TopIssues[j].Issue
+	})
+	if topIssues > 0 && len(summary.TopIssues) > topIssues {
+		summary.TopIssues = summary.TopIssues[:topIssues]
+	}
+
+	return summary
+}
+
+// DirSize returns the total size in bytes of regular files under path
+func DirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files can disappear mid-walk (build output, editors); skip them
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// DirSizes measures several directories concurrently, keyed by path.
+// Directories that cannot be read are omitted from the result.
+func DirSizes(paths []string) map[string]int64 {
+	sizes := make(map[string]int64, len(paths))
+	if len(paths) == 0 {
+		return sizes
+	}
+
+	workerCount := maxConcurrentSizeScans
+	if len(paths) < workerCount {
+		workerCount = len(paths)
+	}
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				size, err := DirSize(path)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				sizes[path] = size
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+
+	return sizes
+}
+
+// CachedDirSizes returns sizes for paths, reusing measurements in store that are
+// younger than maxAge and measuring (then caching) the rest
+func CachedDirSizes(store *metadata.Store, paths []string, maxAge time.Duration) map[string]int64 {
+	sizes := make(map[string]int64, len(paths))
+	var stale []string
+	for _, path := range paths {
+		if record, ok := store.DiskUsage(path); ok && time.Since(record.MeasuredAt) < maxAge {
+			sizes[path] = record.Bytes
+			continue
+		}
+		stale = append(stale, path)
+	}
+
+	measured := DirSizes(stale)
+	store.RememberDiskUsage(measured)
+	for path, size := range measured {
+		sizes[path] = size
+	}
+	return sizes
+}
+
+// ParseSize parses a human size such as "5GB", "500M" or "1024" into bytes,
+// using the same binary units as FormatBytes
+func ParseSize(value string) (int64, error) {
+	trimmed := strings.ToUpper(strings.TrimSpace(value))
+	trimmed = strings.TrimSuffix(trimmed, "IB")
+	trimmed = strings.TrimSuffix(trimmed, "B")
+
+	multiplier := int64(1)
+	if trimmed != "" {
+		if exp := strings.IndexByte("KMGTPE", trimmed[len(trimmed)-1]); exp >= 0 {
+			for i := 0; i <= exp; i++ {
+				multiplier *= 1024
+			}
+			trimmed = trimmed[:len(trimmed)-1]
+		}
+	}
+
+	number, err := strconv.ParseFloat(strings.TrimSpace(trimmed), 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 500MB or 5GB)", value)
+	}
+	return int64(number * float64(multiplier)), nil
+}
+
+// FormatBytes renders a byte count using binary units (e.g. 1.5 GB)
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// FormatDuration renders a lifetime in days and hours
+func FormatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "n/a"
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int((d % (24 * time.Hour)) / time.Hour)
+	if days == 0 {
+		if hours == 0 {
+			return "<1h"
+		}
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dd %dh", days, hours)
+}
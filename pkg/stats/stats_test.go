@@ -0,0 +1,80 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDirSizesMeasuresEachPath(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	if err := os.WriteFile(filepath.Join(first, "a.txt"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(second, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(second, "nested", "b.txt"), make([]byte, 250), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := DirSizes([]string{first, second})
+	if sizes[first] != 100 || sizes[second] != 250 {
+		t.Fatalf("unexpected sizes: %v", sizes)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:                    "512 B",
+		1536:                   "1.5 KB",
+		5 * 1024 * 1024 * 1024: "5.0 GB",
+	}
+	for size, expected := range tests {
+		if got := FormatBytes(size); got != expected {
+			t.Errorf("FormatBytes(%d) = %q, want %q", size, got, expected)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                      "n/a",
+		30 * time.Minute:       "<1h",
+		5 * time.Hour:          "5h",
+		(3*24 + 2) * time.Hour: "3d 2h",
+	}
+	for d, expected := range tests {
+		if got := FormatDuration(d); got != expected {
+			t.Errorf("FormatDuration(%v) = %q, want %q", d, got, expected)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"1024":  1024,
+		"5GB":   5 * 1024 * 1024 * 1024,
+		"500mb": 500 * 1024 * 1024,
+		"1.5K":  1536,
+		"2GiB":  2 * 1024 * 1024 * 1024,
+	}
+	for value, expected := range tests {
+		got, err := ParseSize(value)
+		if err != nil {
+			t.Errorf("ParseSize(%q) returned error: %v", value, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("ParseSize(%q) = %d, want %d", value, got, expected)
+		}
+	}
+
+	for _, value := range []string{"", "GB", "lots", "-1GB"} {
+		if _, err := ParseSize(value); err == nil {
+			t.Errorf("ParseSize(%q) expected error", value)
+		}
+	}
+}
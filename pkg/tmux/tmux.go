@@ -0,0 +1,127 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ClientInterface defines the tmux operations sprout relies on
+type ClientInterface interface {
+	Available() bool
+	LiveSessions() (map[string]bool, error)
+	Open(session, dir string, command []string) error
+	Start(session, dir string, command []string) error
+}
+
+type commandRunner func(name string, args ...string) ([]byte, error)
+
+type interactiveRunner func(name string, args ...string) error
+
+// Client drives the tmux binary on PATH
+type Client struct {
+	runner      commandRunner
+	interactive interactiveRunner
+	insideTmux  bool
+}
+
+func NewClient() *Client {
+	return NewClientWithRunner(runCommandOutput, runCommandInteractive)
+}
+
+func NewClientWithRunner(runner commandRunner, interactive interactiveRunner) *Client {
+	if runner == nil {
+		runner = runCommandOutput
+	}
+	if interactive == nil {
+		interactive = runCommandInteractive
+	}
+	return &Client{
+		runner:      runner,
+		interactive: interactive,
+		insideTmux:  os.Getenv("TMUX") != "",
+	}
+}
+
+func runCommandOutput(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+func runCommandInteractive(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// SessionName converts a branch name into a valid tmux session name; tmux
+// reserves '.' and ':' as target separators
+func SessionName(branch string) string {
+	return strings.NewReplacer(".", "-", ":", "-").Replace(branch)
+}
+
+// Available reports whether the tmux binary can be found
+func (c *Client) Available() bool {
+	_, err := exec.LookPath("tmux")
+	return err == nil
+}
+
+// LiveSessions returns the names of running tmux sessions. No running server
+// is not an error; it simply means there are no sessions.
+func (c *Client) LiveSessions() (map[string]bool, error) {
+	sessions := make(map[string]bool)
+	output, err := c.runner("tmux", "list-sessions", "-F", "#{session_name}")
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return sessions, nil
+		}
+		return nil, fmt.Errorf("failed to list tmux sessions: %w", err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			sessions[name] = true
+		}
+	}
+	return sessions, nil
+}
+
+// Open creates a detached session rooted at dir (running command, if given)
+// unless one already exists, then attaches to it, or switches the current
+// client when sprout itself is running inside tmux
+func (c *Client) Open(session, dir string, command []string) error {
+	if err := c.Start(session, dir, command); err != nil {
+		return err
+	}
+
+	if c.insideTmux {
+		return c.interactive("tmux", "switch-client", "-t", "="+session)
+	}
+	return c.interactive("tmux", "attach-session", "-t", "="+session)
+}
+
+// Start creates a detached session rooted at dir (running command, if given)
+// unless one already exists, leaving it running in the background
+func (c *Client) Start(session, dir string, command []string) error {
+	if _, err := c.runner("tmux", "has-session", "-t", "="+session); err == nil {
+		return nil
+	}
+	args := append([]string{"new-session", "-d", "-s", session, "-c", dir}, command...)
+	if _, err := c.runner("tmux", args...); err != nil {
+		return fmt.Errorf("failed to create tmux session %s: %w", session, err)
+	}
+	return nil
+}
+
+// Kill ends session and whatever it's running. A session that's already gone
+// isn't an error
+func (c *Client) Kill(session string) error {
+	if _, err := c.runner("tmux", "has-session", "-t", "="+session); err != nil {
+		return nil
+	}
+	if _, err := c.runner("tmux", "kill-session", "-t", "="+session); err != nil {
+		return fmt.Errorf("failed to end tmux session %s: %w", session, err)
+	}
+	return nil
+}
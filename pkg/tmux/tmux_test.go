@@ -0,0 +1,84 @@
+package tmux
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenCreatesMissingSessionThenAttaches(t *testing.T) {
+	var commands []string
+	runner := func(name string, args ...string) ([]byte, error) {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		if args[0] == "has-session" {
+			return nil, errors.New("no such session")
+		}
+		return nil, nil
+	}
+	interactive := func(name string, args ...string) error {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		return nil
+	}
+
+	client := NewClientWithRunner(runner, interactive)
+	client.insideTmux = false
+	if err := client.Open("feature-1-2", "/worktrees/feature-1.2", []string{"nvim", "."}); err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+
+	expected := []string{
+		"tmux has-session -t =feature-1-2",
+		"tmux new-session -d -s feature-1-2 -c /worktrees/feature-1.2 nvim .",
+		"tmux attach-session -t =feature-1-2",
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Fatalf("expected commands %q, got %q", expected, commands)
+	}
+}
+
+func TestOpenSwitchesClientWhenInsideTmux(t *testing.T) {
+	var commands []string
+	runner := func(name string, args ...string) ([]byte, error) {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		return nil, nil
+	}
+	interactive := func(name string, args ...string) error {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		return nil
+	}
+
+	client := NewClientWithRunner(runner, interactive)
+	client.insideTmux = true
+	if err := client.Open("main-work", "/worktrees/main-work", nil); err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+
+	expected := []string{
+		"tmux has-session -t =main-work",
+		"tmux switch-client -t =main-work",
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Fatalf("expected commands %q, got %q", expected, commands)
+	}
+}
+
+func TestLiveSessions(t *testing.T) {
+	client := NewClientWithRunner(func(name string, args ...string) ([]byte, error) {
+		return []byte("feature-1\nbugfix-2\n"), nil
+	}, nil)
+
+	sessions, err := client.LiveSessions()
+	if err != nil {
+		t.Fatalf("LiveSessions returned error: %v", err)
+	}
+	if !sessions["feature-1"] || !sessions["bugfix-2"] || len(sessions) != 2 {
+		t.Fatalf("unexpected sessions: %v", sessions)
+	}
+}
+
+func TestSessionName(t *testing.T) {
+	if name := SessionName("release/v1.2:hotfix"); name != "release/v1-2-hotfix" {
+		t.Fatalf("unexpected session name %q", name)
+	}
+}
@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"sprout/pkg/linear"
+)
+
+// boardColumnGap separates the board's columns
+const boardColumnGap = 2
+
+// boardColumn is one state's worth of cards on the board
+type boardColumn struct {
+	state  linear.State
+	issues []*linear.Issue
+}
+
+// boardColumns groups the assigned open issues by state, in workflow order
+func (m *model) boardColumns() []boardColumn {
+	var columns []boardColumn
+	index := make(map[string]int)
+	for i := range m.LinearIssues {
+		issue := &m.LinearIssues[i]
+		if isClosedIssue(*issue) {
+			continue
+		}
+		col, ok := index[issue.State.Name]
+		if !ok {
+			col = len(columns)
+			index[issue.State.Name] = col
+			columns = append(columns, boardColumn{state: issue.State})
+		}
+		columns[col].issues = append(columns[col].issues, issue)
+	}
+
+	// Stable, so states of the same kind keep the order their issues arrived in
+	sort.SliceStable(columns, func(i, j int) bool {
+		return boardStateRank(columns[i].state) < boardStateRank(columns[j].state)
+	})
+	return columns
+}
+
+// boardStateRank orders columns left to right as work moves through them
+func boardStateRank(state linear.State) int {
+	if strings.Contains(strings.ToLower(state.Name), "review") {
+		return 3
+	}
+	switch strings.ToLower(state.Type) {
+	case "backlog":
+		return 0
+	case "unstarted", "todo":
+		return 1
+	case "started", "in_progress":
+		return 2
+	case "in_review", "review":
+		return 3
+	default:
+		return 4
+	}
+}
+
+// openBoard switches to the board with the selected issue's card focused
+func (m *model) openBoard() {
+	m.BoardMode = true
+	m.BoardColumn, m.BoardRow = 0, 0
+	columns := m.boardColumns()
+	if m.SelectedIssue != nil {
+		for c, column := range columns {
+			for r, issue := range column.issues {
+				if issue.ID == m.SelectedIssue.ID {
+					m.BoardColumn, m.BoardRow = c, r
+				}
+			}
+		}
+	}
+	m.focusBoardCard(columns)
+}
+
+// focusBoardCard keeps the board's position on a card and selects that card's
+// issue, so the list's shortcuts act on it
+func (m *model) focusBoardCard(columns []boardColumn) {
+	if len(columns) == 0 {
+		m.BoardColumn, m.BoardRow = 0, 0
+		return
+	}
+	m.BoardColumn = max(0, min(m.BoardColumn, len(columns)-1))
+	m.BoardRow = max(0, min(m.BoardRow, len(columns[m.BoardColumn].issues)-1))
+	m.selectRow(workQueueRow{Kind: workQueueRowIssue, Issue: columns[m.BoardColumn].issues[m.BoardRow]})
+}
+
+// updateBoard moves around the board, reporting whether it handled msg; other
+// keys fall through to the list's handling, which acts on the focused card
+func (m model) updateBoard(msg tea.KeyMsg) (model, bool) {
+	columns := m.boardColumns()
+	handled := true
+	switch {
+	case m.keyMatches(msg, m.Keys.Board):
+		m.BoardMode = false
+		m.scrollToSelection()
+		return m, true
+	case m.keyMatches(msg, m.Keys.Up):
+		m.BoardRow--
+	case m.keyMatches(msg, m.Keys.Down):
+		m.BoardRow++
+	case m.keyMatches(msg, m.Keys.Collapse):
+		m.BoardColumn--
+	case m.keyMatches(msg, m.Keys.Expand):
+		m.BoardColumn++
+	case key.Matches(msg, m.Keys.Search):
+		// Search filters the list, so it isn't available on the board
+	default:
+		handled = false
+	}
+	m.focusBoardCard(columns)
+	return m, handled
+}
+
+// renderBoardView shows the assigned issues as cards in a column per state
+func (m model) renderBoardView() string {
+	s := strings.Builder{}
+	if m.showHeader() {
+		s.WriteString(headerStyle.Render("🌱 sprout"))
+		s.WriteString("\n\n")
+	}
+
+	columns := m.boardColumns()
+	if len(columns) == 0 {
+		s.WriteString(helpStyle.Render("No open tickets to plan"))
+		s.WriteString("\n")
+	} else {
+		width := 24
+		if m.Width > 0 {
+			width = max(16, (m.Width-boardColumnGap*(len(columns)-1))/len(columns))
+		}
+		column := lipgloss.NewStyle().Width(width).MarginRight(boardColumnGap)
+		blocks := make([]string, len(columns))
+		for c, col := range columns {
+			lines := []string{m.getStatusStyle(col.state).Render(fmt.Sprintf("%s (%d)", col.state.Name, len(col.issues)))}
+			for r, issue := range col.issues {
+				lines = append(lines, "")
+				title := issue.Title
+				if len(title) > width && width > 3 {
+					title = title[:width-3] + "..."
+				}
+				if c == m.BoardColumn && r == m.BoardRow {
+					card := selectedStyle.Width(width)
+					lines = append(lines, card.Render(issue.Identifier), card.Render(title))
+				} else {
+					lines = append(lines, identifierStyle.Render(issue.Identifier), titleStyle.Render(title))
+				}
+			}
+			if c == len(columns)-1 {
+				column = column.MarginRight(0)
+			}
+			blocks[c] = column.Render(strings.Join(lines, "\n"))
+		}
+		s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, blocks...))
+		s.WriteString("\n")
+	}
+
+	s.WriteString(helpStyle.Render(m.renderFooter(m.boardHotkeys())))
+	return s.String()
+}
+
+func (m model) boardHotkeys() string {
+	if m.BrowseOnly {
+		return fmt.Sprintf("[%s open] [%s copy] [%s list] [%s help]",
+			m.Keys.OpenIssue.Help().Key, m.Keys.CopyIssue.Help().Key, m.Keys.Board.Help().Key, m.Keys.Help.Help().Key)
+	}
+	mode := "worktree"
+	if m.CreationMode == creationModeBranchOnly {
+		mode = "branch"
+	}
+	return fmt.Sprintf("[%s <%s>] [%s create] [%s list] [%s help]",
+		mode, m.Keys.ToggleMode.Help().Key, m.Keys.Select.Help().Key, m.Keys.Board.Help().Key, m.Keys.Help.Help().Key)
+}
@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"sprout/pkg/config"
+	"sprout/pkg/git"
+	"sprout/pkg/issues"
+	"sprout/pkg/linear"
+	"sprout/pkg/metadata"
+)
+
+// issueLinkMsg reports opening an issue in the browser or copying its
+// identifier, with the notice to show in the footer
+type issueLinkMsg struct {
+	notice string
+	err    error
+}
+
+// NewIssueBrowser sets up sprout issues: the issue tree for triage, where
+// statuses, subtasks and links can be worked with but nothing creates a
+// branch or worktree. It works outside a repository too, using the global
+// issue settings
+func NewIssueBrowser() (model, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return model{}, err
+	}
+
+	repoRoot := ""
+	if wm, err := git.NewWorktreeManager(); err == nil {
+		repoRoot = wm.RepoRoot()
+	}
+	linearClient, err := issues.NewForRepo(cfg, repoRoot)
+	if err != nil {
+		return model{}, err
+	}
+	if linearClient == nil {
+		return model{}, fmt.Errorf("no issue tracker is connected; add linearApiKey to your config or run sprout auth linear")
+	}
+
+	m, err := NewIssueBrowserWithDependencies(linearClient, cfg)
+	if err != nil {
+		return model{}, err
+	}
+	if repoRoot != "" {
+		store := metadata.NewStore(repoRoot)
+		m.TreeState = store
+		m.RestoringTree = newTreeRestore(store.IssueTree())
+		m.RepoRoot = repoRoot
+		m.IssueSort = issueSortFor(cfg, repoRoot)
+		m.SaveIssueSort = saveIssueSort
+	}
+	return m, nil
+}
+
+// NewIssueBrowserWithDependencies is NewIssueBrowser with the issue tracker
+// and config given, and no worktrees to list
+func NewIssueBrowserWithDependencies(linearClient linear.LinearClientInterface, cfg *config.Config, opts ...Option) (model, error) {
+	m, err := NewTUIWithDependenciesAndConfig(nil, linearClient, cfg, opts...)
+	if err != nil {
+		return model{}, err
+	}
+	m.BrowseOnly = true
+	m.InputMode = false
+	m.TextInput.Blur()
+	return m, nil
+}
+
+// RunIssueBrowser runs sprout issues until the user quits
+func RunIssueBrowser() error {
+	m, err := NewIssueBrowser()
+	if err != nil {
+		return err
+	}
+
+	finalModel, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return err
+	}
+	if resultModel, ok := finalModel.(model); ok {
+		resultModel.saveIssueTree()
+		if !resultModel.Success && resultModel.ErrorMsg != "" {
+			return errors.New(resultModel.ErrorMsg)
+		}
+	}
+	return nil
+}
+
+// selectFirstRow selects the top of the tree, which is where the issue
+// browser keeps its selection in place of the branch name input
+func (m *model) selectFirstRow() {
+	m.SelectedIssue = nil
+	m.SelectedWorktree = ""
+	m.AddSubtaskSelected = ""
+	m.InputMode = false
+	m.TextInput.Blur()
+	m.ListOffset = 0
+	if rows := m.visibleWorkQueueRows(); len(rows) > 0 {
+		m.selectRow(rows[0])
+	}
+}
+
+// browseSelect is what Enter does in the issue browser: show or hide the
+// selected issue's subtasks, start one on "Add subtask", or leave search at
+// the selected issue
+func (m model) browseSelect() (tea.Model, tea.Cmd) {
+	switch {
+	case m.SearchMode:
+		m.leaveBrowseSearch()
+		return m, nil
+	case m.BoardMode:
+		return m, nil
+	case m.SelectedIssue != nil && m.SelectedIssue.Expanded:
+		m.updateIssueExpansion(m.SelectedIssue.ID, false)
+		return m, nil
+	}
+	return m.expandSelection()
+}
+
+// leaveBrowseSearch ends a search in the issue browser, staying on the
+// selected issue when the tree shows it
+func (m *model) leaveBrowseSearch() {
+	m.SearchMode = false
+	m.SearchQuery = ""
+	m.FilteredIssues = nil
+	m.TextInput.Placeholder = m.DefaultPlaceholder
+	m.TextInput.SetValue("")
+	if m.selectedRowIndex(m.visibleWorkQueueRows()) < 0 {
+		m.selectFirstRow()
+	}
+	m.InputMode = false
+	m.TextInput.Blur()
+	m.scrollToSelection()
+}
+
+// openIssue shows issue in the browser
+func (m model) openIssue(issue linear.Issue) tea.Cmd {
+	open := m.OpenURL
+	return func() tea.Msg {
+		if issue.URL == "" {
+			return issueLinkMsg{err: fmt.Errorf("%s has no link to open", issue.Identifier)}
+		}
+		if err := open(issue.URL); err != nil {
+			return issueLinkMsg{err: fmt.Errorf("failed to open %s: %w", issue.Identifier, err)}
+		}
+		return issueLinkMsg{notice: "Opened " + issue.Identifier}
+	}
+}
+
+// copyIssueIdentifier puts issue's identifier on the clipboard
+func (m model) copyIssueIdentifier(issue linear.Issue) tea.Cmd {
+	copyText := m.CopyText
+	return func() tea.Msg {
+		if err := copyText(issue.Identifier); err != nil {
+			return issueLinkMsg{err: fmt.Errorf("failed to copy %s: %w", issue.Identifier, err)}
+		}
+		return issueLinkMsg{notice: "Copied " + issue.Identifier}
+	}
+}
+
+// copyToClipboard puts text on the system clipboard with whichever of the
+// platform's clipboard commands is installed
+func copyToClipboard(text string) error {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		candidates = append(candidates, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+	}
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(candidate[0], candidate[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %w: %s", candidate[0], err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return errors.New("no clipboard command found; install xclip, xsel or wl-copy")
+}
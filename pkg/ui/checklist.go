@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"sprout/pkg/linear"
+)
+
+// checklistRun follows the subtasks of a checklist as they're created, one
+// at a time so the parent's children come back in the checklist's order
+type checklistRun struct {
+	ParentID string
+	Titles   []string
+	Results  []string // a line per title created so far, saying how it went
+	Failed   int
+}
+
+// checklistSubtaskMsg is the outcome of creating the checklist's next subtask
+type checklistSubtaskMsg struct {
+	parentID string
+	title    string
+	subtask  *linear.Issue
+	err      error
+}
+
+func newChecklistInput() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "one subtask per line"
+	ta.ShowLineNumbers = false
+	ta.Prompt = "  "
+	ta.CharLimit = 0
+	ta.SetHeight(6)
+	ta.SetWidth(60)
+	ta.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "shift+enter", "ctrl+j"))
+	ta.FocusedStyle.Text = titleStyle
+	ta.FocusedStyle.Placeholder = helpStyle
+	ta.BlurredStyle.Text = normalStyle
+	ta.BlurredStyle.Placeholder = helpStyle
+	return ta
+}
+
+// isMultilinePaste is whether msg brings more than one line, which in the
+// subtask title can only be a list pasted in
+func isMultilinePaste(msg tea.KeyMsg) bool {
+	return msg.Type == tea.KeyRunes && strings.ContainsAny(string(msg.Runes), "\r\n")
+}
+
+// openSubtaskChecklist turns the subtask form into a checklist, starting it
+// with the title typed so far and then text
+func (m *model) openSubtaskChecklist(text string) tea.Cmd {
+	start := strings.TrimSpace(m.SubtaskInput.Value())
+	if start != "" && text != "" {
+		start += "\n"
+	}
+	m.SubtaskChecklistMode = true
+	m.SubtaskInput.Blur()
+	m.SubtaskDescription.Blur()
+	m.SubtaskChecklist.SetValue(start + strings.ReplaceAll(text, "\r\n", "\n"))
+	return m.SubtaskChecklist.Focus()
+}
+
+// updateSubtaskChecklist handles keys while the subtask form holds a
+// checklist. Enter creates a subtask for every line
+func (m model) updateSubtaskChecklist(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.Cancelled = true
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.SubtaskInputMode = false
+		m.setSubtaskEntryMode(m.SubtaskParentID, false)
+		m.SubtaskParentID = ""
+		m.resetSubtaskForm()
+		return m, nil
+	case tea.KeyEnter:
+		if msg.Alt {
+			m.SubtaskChecklist.InsertRune('\n')
+			return m, nil
+		}
+		titles := linear.ChecklistTitles(m.SubtaskChecklist.Value())
+		if len(titles) == 0 {
+			return m, nil
+		}
+		m.Checklist = &checklistRun{ParentID: m.SubtaskParentID, Titles: titles}
+		m.CreatingSubtask = true
+		m.SubtaskInputMode = false
+		m.SubtaskChecklist.Blur()
+		return m, tea.Batch(m.createChecklistSubtask(), m.Spinner.Tick)
+	}
+
+	if msg.String() == "shift+enter" || msg.Type == tea.KeyCtrlJ {
+		m.SubtaskChecklist.InsertRune('\n')
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.SubtaskChecklist, cmd = m.SubtaskChecklist.Update(msg)
+	return m, cmd
+}
+
+// createChecklistSubtask creates the checklist's next subtask
+func (m model) createChecklistSubtask() tea.Cmd {
+	parentID := m.Checklist.ParentID
+	title := m.Checklist.Titles[len(m.Checklist.Results)]
+	return func() tea.Msg {
+		subtask, err := m.LinearClient.CreateSubtask(parentID, title)
+		return checklistSubtaskMsg{parentID: parentID, title: title, subtask: subtask, err: err}
+	}
+}
+
+// checklistSubtaskDone records how a checklist subtask went and moves on to
+// the next, or once they're all done closes the form and reports
+func (m model) checklistSubtaskDone(msg checklistSubtaskMsg) (tea.Model, tea.Cmd) {
+	run := *m.Checklist
+	if msg.err != nil {
+		run.Failed++
+		run.Results = append(append([]string{}, run.Results...), fmt.Sprintf("✗ %s: %v", msg.title, msg.err))
+	} else {
+		run.Results = append(append([]string{}, run.Results...), fmt.Sprintf("✓ %s %s", msg.subtask.Identifier, msg.subtask.Title))
+		m.addSubtaskToParent(msg.parentID, *msg.subtask)
+	}
+	m.Checklist = &run
+	if len(run.Results) < len(run.Titles) {
+		return m, m.createChecklistSubtask()
+	}
+
+	m.CreatingSubtask = false
+	m.Checklist = nil
+	m.resetSubtaskForm()
+	m.setSubtaskEntryMode(msg.parentID, false)
+	m.SubtaskParentID = ""
+	m.updateIssueExpansion(msg.parentID, true)
+	m.ChecklistReport = run.Results
+	created := len(run.Titles) - run.Failed
+	if run.Failed > 0 {
+		m.FooterError = fmt.Sprintf("Created %d of %d subtasks; %d failed", created, len(run.Titles), run.Failed)
+	} else {
+		m.FooterNotice = fmt.Sprintf("Created %d subtasks", created)
+	}
+	return m, nil
+}
+
+// renderSubtaskChecklist renders the checklist under the tree, in place of
+// the footer, while the subtask form holds one
+func (m model) renderSubtaskChecklist() string {
+	count := len(linear.ChecklistTitles(m.SubtaskChecklist.Value()))
+	s := strings.Builder{}
+	s.WriteString(selectedStyle.Render(fmt.Sprintf("Checklist: %d subtask(s), one per line", count)))
+	s.WriteString("\n")
+	s.WriteString(m.SubtaskChecklist.View())
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("[shift+enter new line] [enter create all] [esc cancel]"))
+	return s.String()
+}
+
+// renderChecklistReport lists how each line of the last checklist went,
+// until the next key press
+func (m model) renderChecklistReport() string {
+	lines := make([]string, len(m.ChecklistReport))
+	for i, line := range m.ChecklistReport {
+		style := normalStyle
+		if strings.HasPrefix(line, "✗") {
+			style = errorStyle
+		}
+		lines[i] = style.Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// subtaskEntryView is the inline "Add subtask" row while the form is open:
+// the title being typed, or while there's a checklist, how many it holds
+func (m model) subtaskEntryView() string {
+	if !m.SubtaskChecklistMode {
+		return m.SubtaskInput.View()
+	}
+	return addSubtaskStyle.Render(fmt.Sprintf("+ %d subtask(s) from the checklist", len(linear.ChecklistTitles(m.SubtaskChecklist.Value()))))
+}
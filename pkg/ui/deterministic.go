@@ -0,0 +1,20 @@
+package ui
+
+import "time"
+
+// Option changes how a TUI from NewTUI, or one of the constructors like it,
+// starts out
+type Option func(*model)
+
+// Deterministic has the TUI render the same screen from the same state every
+// time, for golden tests and screenshots in the docs: now is the time
+// throughout, spinners stay on their first frame, and nothing is coloured.
+// Only the TUI's own renders are left plain; lipgloss's colour profile is
+// put back after each
+func Deterministic(now func() time.Time) Option {
+	return func(m *model) {
+		m.Now = now
+		m.SpinnerFrozen = true
+		m.Plain = true
+	}
+}
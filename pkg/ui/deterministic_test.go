@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"sprout/pkg/linear/lineartest"
+)
+
+func TestDeterministicRendersPlainWithoutChangingTheColourProfile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	m, err := NewTUIWithDependencies(&testWorktreeManager{}, lineartest.NewServer(t).Client(), Deterministic(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	if view := updated.View(); strings.Contains(view, "\x1b[") {
+		t.Fatalf("expected a plain render, got %q", view)
+	}
+	if profile := lipgloss.ColorProfile(); profile != termenv.TrueColor {
+		t.Fatalf("expected the colour profile left as it was, got %v", profile)
+	}
+}
@@ -2,21 +2,29 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/exp/teatest"
 	"github.com/cucumber/godog"
-	"github.com/muesli/termenv"
 	"sprout/pkg/config"
 	"sprout/pkg/git"
+	"sprout/pkg/github"
+	"sprout/pkg/issues"
 	"sprout/pkg/linear"
 	"sprout/pkg/linear/lineartest"
+	"sprout/pkg/metadata"
+	"sprout/pkg/progress"
+	"sprout/pkg/sprouttest"
+	"sprout/pkg/stats"
 )
 
 // TUITestContext holds the state for our Gherkin tests
@@ -26,6 +34,7 @@ type TUITestContext struct {
 	fakeLinear          *lineartest.Server
 	fakeWorktreeManager *testWorktreeManager
 	defaultWorktreeCmd  string
+	defaultCommands     config.DefaultCommands
 	resumeWorktreeCmd   string
 	postCreateRuns      []string
 	postResumeRuns      []string
@@ -36,8 +45,62 @@ type TUITestContext struct {
 	terminalWidth       int
 	terminalHeight      int
 	pauseLinearLoading  bool
+	issuePagesHeld      bool // Linear sends the first page of issues and holds back the rest
+	linearTimeout       time.Duration
+	sparseProfiles      map[string][]string
+	repoConfig          *config.RepoConfig
+	otherRepos          map[string][]git.Worktree // registered repos besides the current one, by name
+	outsideRepo         bool                      // started outside any repository, on the repo picker
+	keybindings         map[string][]string
+	startErr            error
+	recentBranches      []string
+	history             recordingHistory
+	issueSort           string
+	savedIssueSorts     []string
+	issueCycle          string
+	currentCycle        int // cycles in the issues table are dated around this one, 0 leaves them undated
+	templates           config.Templates
+	treeStore           memoryTreeStore
+	mergedSince         []string          // merged worktrees sprout hadn't seen merged when it last ran
+	linearWorkspaces    []linearWorkspace // merged into one issue list when set
+	browseOnly          bool              // start sprout issues rather than the work queue
+	openedURLs          []string
+	clipboard           string
+	issueRefreshSeconds int
+	clock               *sprouttest.Clock // the TUI's clock, which only moves when minutes pass
 }
 
+// linearWorkspace is one of several Linear workspaces in a test, each with
+// its own fake server
+type linearWorkspace struct {
+	name   string
+	server *lineartest.Server
+}
+
+// memoryTreeStore keeps the issue tree between TUI sessions in a test
+type memoryTreeStore struct {
+	state metadata.IssueTreeState
+}
+
+func (s *memoryTreeStore) IssueTree() metadata.IssueTreeState {
+	return s.state
+}
+
+func (s *memoryTreeStore) SetIssueTree(state metadata.IssueTreeState) {
+	s.state = state
+}
+
+// recordingHistory captures the branches the TUI records as used
+type recordingHistory struct {
+	recorded []string
+}
+
+func (h *recordingHistory) RecordBranchUse(branch string) {
+	h.recorded = append(h.recorded, branch)
+}
+
+func (h *recordingHistory) RecordLastCommand(branch, path string, command []string) {}
+
 // NewTUITestContext creates a new test context
 func NewTUITestContext(t *testing.T) *TUITestContext {
 	return &TUITestContext{
@@ -53,6 +116,7 @@ func NewTUITestContext(t *testing.T) *TUITestContext {
 		t:                   t,
 		terminalWidth:       80, // Default width
 		terminalHeight:      24, // Default height
+		clock:               sprouttest.NewClock(time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)),
 	}
 }
 
@@ -67,14 +131,40 @@ type testWorktreeManager struct {
 	pauseStatus         string
 	failPRBranch        string
 	cachedMerged        map[string]bool
+	branches            []string                  // local branches without a worktree
+	pruneFailures       map[string]string         // why pruning each of these branches fails
+	remoteDeleted       []string                  // branches pruning deleted on origin
+	createErr           error                     // returned instead of creating a worktree
+	createWarning       string                    // what creating a worktree warns about as it finishes
+	pullRequests        map[string]git.PRCheckout // what CheckoutPR finds for each ref
 }
 
 func (m *testWorktreeManager) CreateWorktree(branchName string) (string, error) {
+	return m.CreateWorktreeWithOptions(branchName, git.CreateOptions{})
+}
+
+func (m *testWorktreeManager) CreateWorktreeWithOptions(branchName string, opts git.CreateOptions) (string, error) {
 	if branchName == "" {
 		return "", fmt.Errorf("branch name required")
 	}
+	if m.createErr != nil {
+		return "", m.createErr
+	}
+	if m.createWarning != "" {
+		progress.Or(opts.Progress).Warning(m.createWarning)
+	}
 	m.lastCreatedWorktree = branchName
-	m.gitCommands = append(m.gitCommands, fmt.Sprintf("git worktree add /mock/worktrees/%s -b %s main", branchName, branchName))
+	base := "main"
+	if opts.BaseBranch != "" {
+		base = opts.BaseBranch
+	}
+	m.gitCommands = append(m.gitCommands, fmt.Sprintf("git worktree add /mock/worktrees/%s -b %s %s", branchName, branchName, base))
+	if len(opts.SparseDirectories) > 0 {
+		m.gitCommands = append(m.gitCommands, "git sparse-checkout set "+strings.Join(opts.SparseDirectories, " "))
+	}
+	for _, hook := range opts.Hooks {
+		m.gitCommands = append(m.gitCommands, "sh -c "+hook)
+	}
 	if m.delayCreate {
 		if m.createUnblock == nil {
 			m.createUnblock = make(chan struct{})
@@ -86,6 +176,10 @@ func (m *testWorktreeManager) CreateWorktree(branchName string) (string, error)
 	return "/mock/worktrees/" + branchName, nil
 }
 
+func (m *testWorktreeManager) PlanWorktree(branchName string, opts git.CreateOptions) (git.CreatePlan, error) {
+	return git.CreatePlan{Branch: branchName, Path: "/mock/worktrees/" + branchName}, nil
+}
+
 func (m *testWorktreeManager) CreateBranch(branchName string) error {
 	if branchName == "" {
 		return fmt.Errorf("branch name required")
@@ -103,21 +197,17 @@ func (m *testWorktreeManager) ListWorktreesForTUI() ([]git.Worktree, error) {
 	return m.worktrees, nil
 }
 
-func (m *testWorktreeManager) ListWorktreesForTUIWithProgress(progress func(string)) ([]git.Worktree, error) {
+func (m *testWorktreeManager) LookUpCIStatuses(worktrees []git.Worktree) {}
+
+func (m *testWorktreeManager) ListWorktreesForTUIWithProgress(presenter progress.Presenter) ([]git.Worktree, error) {
 	if m.pauseStatus != "" {
-		if progress != nil {
-			progress(m.pauseStatus)
-		}
+		progress.Or(presenter).StepStarted(m.pauseStatus)
 		return nil, nil
 	}
-	if progress != nil {
-		progress("git worktree list --porcelain")
-	}
+	progress.Or(presenter).StepStarted("git worktree list --porcelain")
 	if m.failPRBranch != "" {
 		command := fmt.Sprintf("gh pr list --head %s --state all --json state --limit 1", m.failPRBranch)
-		if progress != nil {
-			progress(command)
-		}
+		progress.Or(presenter).StepStarted(command)
 		return nil, fmt.Errorf("%s: failed", command)
 	}
 	for i := range m.worktrees {
@@ -127,21 +217,173 @@ func (m *testWorktreeManager) ListWorktreesForTUIWithProgress(progress func(stri
 			continue
 		}
 		command := fmt.Sprintf("gh pr list --head %s --state all --json state --limit 1", m.worktrees[i].Branch)
-		if progress != nil {
-			progress(command)
-		}
+		progress.Or(presenter).StepStarted(command)
 	}
 	return m.worktrees, nil
 }
 
-func (m *testWorktreeManager) PruneWorktree(branchName string) error {
+func (m *testWorktreeManager) PruneWorktree(branchName string, opts git.PruneOptions) error {
 	return nil
 }
 
-func (m *testWorktreeManager) PruneAllMerged() error {
+func (m *testWorktreeManager) PruneAllMerged(opts git.PruneOptions) (git.PruneResult, error) {
+	return git.PruneResult{}, nil
+}
+
+func (m *testWorktreeManager) MergedWorktrees() ([]git.Worktree, error) {
+	return nil, nil
+}
+
+func (m *testWorktreeManager) PruneMergedWorktrees(worktrees []git.Worktree, opts git.PruneOptions) (git.PruneResult, error) {
+	var result git.PruneResult
+	for _, wt := range worktrees {
+		outcome := git.PruneOutcome{Branch: wt.Branch, Path: wt.Path, Status: git.PrunePruned}
+		if reason, fails := m.pruneFailures[wt.Branch]; fails {
+			outcome.Status, outcome.Reason = git.PruneFailed, reason
+		} else if opts.DryRun {
+			outcome.Status = git.PruneWouldPrune
+		} else {
+			m.worktrees = slices.DeleteFunc(m.worktrees, func(existing git.Worktree) bool { return existing.Branch == wt.Branch })
+			if opts.KeepBranch {
+				m.branches = append(m.branches, wt.Branch)
+			}
+			if opts.DeleteRemote {
+				m.remoteDeleted = append(m.remoteDeleted, wt.Branch)
+			}
+		}
+		result.Outcomes = append(result.Outcomes, outcome)
+		opts.OnProgress(outcome)
+	}
+	if failed := result.Failed(); len(failed) > 0 {
+		return result, fmt.Errorf("failed to prune %d of %d worktree(s)", len(failed), len(worktrees))
+	}
+	return result, nil
+}
+
+func (m *testWorktreeManager) HasUncommittedChanges(worktreePath string) bool {
+	return false
+}
+
+func (m *testWorktreeManager) PruneLargerThan(threshold int64, opts git.PruneOptions) error {
+	return nil
+}
+
+func (m *testWorktreeManager) ApplySparseCheckout(branchName string, directories []string) error {
 	return nil
 }
 
+func (m *testWorktreeManager) Repair() (*git.RepairReport, error) {
+	return &git.RepairReport{}, nil
+}
+
+func (m *testWorktreeManager) ArchiveWorktree(branchName string) (*git.Archive, error) {
+	return nil, fmt.Errorf("archive not supported in TUI tests")
+}
+
+func (m *testWorktreeManager) RestoreWorktree(branchName string) (string, error) {
+	return "", fmt.Errorf("restore not supported in TUI tests")
+}
+
+func (m *testWorktreeManager) ExportWorktree(branchName, path string) (*git.Archive, error) {
+	return nil, fmt.Errorf("export not supported in TUI tests")
+}
+
+func (m *testWorktreeManager) ImportWorktree(path string) (*git.Archive, string, error) {
+	return nil, "", fmt.Errorf("import not supported in TUI tests")
+}
+
+func (m *testWorktreeManager) RenameWorktree(oldBranch, newBranch string) (git.Worktree, error) {
+	for _, wt := range m.worktrees {
+		if wt.Branch == newBranch {
+			return git.Worktree{}, fmt.Errorf("branch %s already exists", newBranch)
+		}
+	}
+	for i, wt := range m.worktrees {
+		if wt.Branch == oldBranch {
+			m.worktrees[i].Branch = newBranch
+			m.worktrees[i].Path = filepath.Join(filepath.Dir(wt.Path), newBranch)
+			return m.worktrees[i], nil
+		}
+	}
+	return git.Worktree{}, fmt.Errorf("worktree does not exist: %s", oldBranch)
+}
+
+func (m *testWorktreeManager) UndoPrune() ([]git.TrashedWorktree, error) {
+	return nil, git.ErrNothingToUndo
+}
+
+func (m *testWorktreeManager) GCCandidates(policy git.GCPolicy) ([]git.GCCandidate, error) {
+	return nil, nil
+}
+
+func (m *testWorktreeManager) PurgeExpiredTrash(dryRun bool) ([]git.TrashedWorktree, error) {
+	return nil, nil
+}
+
+func (m *testWorktreeManager) CheckGitHooks() ([]string, error) {
+	return nil, nil
+}
+
+func (m *testWorktreeManager) StartTimer(branchName, worktreePath string) error {
+	return nil
+}
+
+func (m *testWorktreeManager) PinWorktree(branchName string) error {
+	return nil
+}
+
+func (m *testWorktreeManager) UnpinWorktree(branchName string) error {
+	return nil
+}
+
+func (m *testWorktreeManager) SetNote(branchName, note string) error {
+	for i, wt := range m.worktrees {
+		if wt.Branch == branchName {
+			m.worktrees[i].Note = note
+			return nil
+		}
+	}
+	return fmt.Errorf("worktree does not exist: %s", branchName)
+}
+
+func (m *testWorktreeManager) Stack(branchName string) (git.Stack, error) {
+	return git.Stack{Branch: branchName}, nil
+}
+
+func (m *testWorktreeManager) Restack(branchName string, presenter progress.Presenter) (git.Stack, error) {
+	return git.Stack{Branch: branchName}, nil
+}
+
+func (m *testWorktreeManager) SetProgress(presenter progress.Presenter) {}
+
+func (m *testWorktreeManager) Where(branchName string) (git.Location, error) {
+	return git.Location{Branch: branchName}, nil
+}
+
+func (m *testWorktreeManager) CheckoutPR(ref string, opts git.CreateOptions) (git.PRCheckout, error) {
+	checkout, ok := m.pullRequests[ref]
+	if !ok {
+		return git.PRCheckout{}, fmt.Errorf("%w for %s", github.ErrNoPullRequest, ref)
+	}
+	return checkout, nil
+}
+
+func (m *testWorktreeManager) FindExisting(branchName string) (git.ExistingBranch, error) {
+	existing := git.ExistingBranch{Branch: branchName}
+	for _, wt := range m.worktrees {
+		if wt.Branch == branchName {
+			existing.WorktreePath = wt.Path
+			existing.BranchExists = true
+		}
+	}
+	for _, branch := range m.branches {
+		if branch == branchName {
+			existing.BranchExists = true
+		}
+	}
+	return existing, nil
+}
+
 func (m *testWorktreeManager) delayWorktreeCreation() {
 	m.delayCreate = true
 	m.createUnblock = make(chan struct{})
@@ -230,10 +472,113 @@ func StripANSI(text string) string {
 func (tc *TUITestContext) theFollowingLinearIssuesExist(issueTable *godog.Table) error {
 	// Clear any existing data
 	tc.fakeLinear = lineartest.NewServer(tc.t)
+	addLinearIssues(tc.fakeLinear, issueTable, tc.currentCycle, tc.clock.Now())
+	return nil
+}
+
+// linearNowAlsoHas adds issues to those Linear already has, as if assigned
+// while the TUI was open
+func (tc *TUITestContext) linearNowAlsoHas(issueTable *godog.Table) error {
+	addLinearIssues(tc.fakeLinear, issueTable, tc.currentCycle, tc.clock.Now())
+	return nil
+}
+
+// theSpinnerTicks sends the spinner the tick that would move it to its next
+// frame
+func (tc *TUITestContext) theSpinnerTicks() error {
+	tc.processMsg(tc.model.Spinner.Tick())
+	return nil
+}
+
+// minutesPass moves the TUI's clock on, as its ticker would notice
+func (tc *TUITestContext) minutesPass(minutes int) error {
+	tc.clock.Advance(time.Duration(minutes) * time.Minute)
+	updatedModel, cmd := tc.model.Update(issueClockMsg{})
+	tc.model = updatedModel.(model)
+	tc.processCmd(cmd)
+	tc.drainWithTimeout(20 * time.Millisecond)
+	return nil
+}
+
+// linearShouldHaveListedMyIssues counts the requests for the issues assigned
+// to me
+func (tc *TUITestContext) linearShouldHaveListedMyIssues(want int) error {
+	tc.drainWithTimeout(20 * time.Millisecond)
+	got := 0
+	for _, req := range tc.fakeLinear.Requests {
+		if strings.Contains(req.Query, "issues(") {
+			got++
+		}
+	}
+	if got != want {
+		return fmt.Errorf("expected Linear to be asked for my issues %d time(s), got %d", want, got)
+	}
+	return nil
+}
+
+// workspaceNowAlsoHas adds issues to those a workspace already has, as if
+// assigned while the TUI was open
+func (tc *TUITestContext) workspaceNowAlsoHas(name string, issueTable *godog.Table) error {
+	for _, workspace := range tc.linearWorkspaces {
+		if workspace.name == name {
+			addLinearIssues(workspace.server, issueTable, tc.currentCycle, tc.clock.Now())
+			return nil
+		}
+	}
+	return fmt.Errorf("no workspace named %q", name)
+}
+
+func (tc *TUITestContext) theFollowingLinearIssuesExistInWorkspace(name string, issueTable *godog.Table) error {
+	server := lineartest.NewServer(tc.t)
+	addLinearIssues(server, issueTable, tc.currentCycle, tc.clock.Now())
+	tc.linearWorkspaces = append(tc.linearWorkspaces, linearWorkspace{name: name, server: server})
+	return nil
+}
 
+func (tc *TUITestContext) workspaceShouldHaveUpdated(name, issueID string) error {
+	tc.drainWithTimeout(20 * time.Millisecond)
+	for _, workspace := range tc.linearWorkspaces {
+		updated := false
+		for _, req := range workspace.server.Requests {
+			variables, _ := req.Variables.(map[string]any)
+			updated = updated || (strings.Contains(req.Query, "issueUpdate") && variables["issueId"] == issueID)
+		}
+		if updated != (workspace.name == name) {
+			return fmt.Errorf("expected only workspace %q to update %s, but workspace %q updated it: %v", name, issueID, workspace.name, updated)
+		}
+	}
+	return nil
+}
+
+// addLinearIssues populates a fake Linear GraphQL server from an issue table,
+// dating its cycles around currentCycle when it's set
+func addLinearIssues(server *lineartest.Server, issueTable *godog.Table, currentCycle int, now time.Time) {
 	// Parse table and populate fake Linear GraphQL server
+	labelsColumn, projectColumn := -1, -1
+	priorityColumn, estimateColumn, cycleColumn, stateTypeColumn, assigneeColumn := -1, -1, -1, -1, -1
+	descriptionColumn := -1
 	for i, row := range issueTable.Rows {
-		if i == 0 { // Skip header row
+		if i == 0 { // Header row; optional columns are located by name
+			for col, cell := range row.Cells {
+				switch strings.TrimSpace(cell.Value) {
+				case "labels":
+					labelsColumn = col
+				case "project":
+					projectColumn = col
+				case "priority":
+					priorityColumn = col
+				case "estimate":
+					estimateColumn = col
+				case "cycle":
+					cycleColumn = col
+				case "state_type":
+					stateTypeColumn = col
+				case "assignee":
+					assigneeColumn = col
+				case "description":
+					descriptionColumn = col
+				}
+			}
 			continue
 		}
 
@@ -279,18 +624,100 @@ func (tc *TUITestContext) theFollowingLinearIssuesExist(issueTable *godog.Table)
 			Children:    []linear.Issue{}, // Not used by the table loader
 			UpdatedAt:   updatedAt,
 		}
+		if labelsColumn >= 0 {
+			for _, name := range strings.Split(row.Cells[labelsColumn].Value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					issue.Labels = append(issue.Labels, linear.Label{ID: name, Name: name})
+				}
+			}
+		}
+		if projectColumn >= 0 {
+			if name := strings.TrimSpace(row.Cells[projectColumn].Value); name != "" {
+				issue.Project = &linear.Project{ID: name, Name: name}
+			}
+		}
+		if priorityColumn >= 0 {
+			issue.Priority, _ = strconv.Atoi(strings.TrimSpace(row.Cells[priorityColumn].Value))
+		}
+		if estimateColumn >= 0 {
+			issue.Estimate, _ = strconv.ParseFloat(strings.TrimSpace(row.Cells[estimateColumn].Value), 64)
+		}
+		if stateTypeColumn >= 0 {
+			if stateType := strings.TrimSpace(row.Cells[stateTypeColumn].Value); stateType != "" {
+				issue.State.Type = stateType
+			}
+		}
+		if assigneeColumn >= 0 {
+			// Someone else's issue, which isn't among those assigned to the user
+			if name := strings.TrimSpace(row.Cells[assigneeColumn].Value); name != "" {
+				issue.Assignee = &linear.User{ID: name, Name: name, DisplayName: name}
+			}
+		}
+		if descriptionColumn >= 0 {
+			// Tables can't hold line breaks, so \n stands for one
+			issue.Description = strings.ReplaceAll(strings.TrimSpace(row.Cells[descriptionColumn].Value), `\n`, "\n")
+		}
+		if cycleColumn >= 0 {
+			if number, err := strconv.Atoi(strings.TrimSpace(row.Cells[cycleColumn].Value)); err == nil {
+				issue.Cycle = &linear.Cycle{ID: fmt.Sprint("cycle-", number), Number: float64(number)}
+				if currentCycle > 0 {
+					// Two-week cycles, the current one a week in
+					issue.Cycle.StartsAt = now.AddDate(0, 0, 14*(number-currentCycle)-7)
+					issue.Cycle.EndsAt = issue.Cycle.StartsAt.AddDate(0, 0, 14)
+				}
+			}
+		}
 
 		// Add to fake Linear GraphQL server (it handles parent-child relationships)
-		tc.fakeLinear.AddIssue(issue, parentID)
+		server.AddIssue(issue, parentID)
 	}
+}
 
+func (tc *TUITestContext) branchAlreadyExists(branch string) error {
+	tc.fakeWorktreeManager.branches = append(tc.fakeWorktreeManager.branches, branch)
 	return nil
 }
 
 func (tc *TUITestContext) theFollowingWorktreesExist(worktreeTable *godog.Table) error {
+	worktrees, err := parseWorktreeTable(worktreeTable)
+	if err != nil {
+		return err
+	}
+	tc.fakeWorktreeManager.worktrees = worktrees
+	return nil
+}
+
+func (tc *TUITestContext) repoIsRegisteredWithWorktrees(name string, worktreeTable *godog.Table) error {
+	worktrees, err := parseWorktreeTable(worktreeTable)
+	if err != nil {
+		return err
+	}
+	if tc.otherRepos == nil {
+		tc.otherRepos = make(map[string][]git.Worktree)
+	}
+	tc.otherRepos[name] = worktrees
+	return nil
+}
+
+func parseWorktreeTable(worktreeTable *godog.Table) ([]git.Worktree, error) {
 	var worktrees []git.Worktree
+	sizeColumn, commitColumn, stateColumn, ciColumn, noteColumn := -1, -1, -1, -1, -1
 	for i, row := range worktreeTable.Rows {
 		if i == 0 {
+			for col, cell := range row.Cells {
+				switch strings.TrimSpace(cell.Value) {
+				case "size":
+					sizeColumn = col
+				case "commit":
+					commitColumn = col
+				case "state":
+					stateColumn = col
+				case "ci":
+					ciColumn = col
+				case "note":
+					noteColumn = col
+				}
+			}
 			continue
 		}
 		branch := strings.TrimSpace(row.Cells[0].Value)
@@ -304,15 +731,173 @@ func (tc *TUITestContext) theFollowingWorktreesExist(worktreeTable *godog.Table)
 		if merged {
 			prStatus = "Merged"
 		}
-		worktrees = append(worktrees, git.Worktree{
+		var diskUsage int64
+		if sizeColumn >= 0 {
+			size, err := stats.ParseSize(row.Cells[sizeColumn].Value)
+			if err != nil {
+				return nil, err
+			}
+			diskUsage = size
+		}
+		worktree := git.Worktree{
 			Branch:    branch,
 			Path:      path,
 			UpdatedAt: updatedAt,
 			Merged:    merged,
 			PRStatus:  prStatus,
-		})
+			DiskUsage: diskUsage,
+		}
+		if commitColumn >= 0 {
+			worktree.Commit = strings.TrimSpace(row.Cells[commitColumn].Value)
+		}
+		if ciColumn >= 0 {
+			worktree.CIStatus = strings.TrimSpace(row.Cells[ciColumn].Value)
+		}
+		if noteColumn >= 0 {
+			worktree.Note = strings.TrimSpace(row.Cells[noteColumn].Value)
+		}
+		if stateColumn >= 0 {
+			for _, state := range strings.Split(row.Cells[stateColumn].Value, ",") {
+				switch strings.TrimSpace(state) {
+				case "bare":
+					worktree.Bare = true
+				case "detached":
+					worktree.Detached = true
+				case "locked":
+					worktree.Locked = true
+				case "pinned":
+					worktree.Locked = true
+					worktree.Pinned = true
+				case "prunable":
+					worktree.Prunable = true
+				}
+			}
+		}
+		worktrees = append(worktrees, worktree)
+	}
+	return worktrees, nil
+}
+
+func (tc *TUITestContext) theKeybindingsAre(table *godog.Table) error {
+	tc.keybindings = make(map[string][]string)
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue
+		}
+		action := strings.TrimSpace(row.Cells[0].Value)
+		for _, k := range strings.Split(row.Cells[1].Value, ",") {
+			tc.keybindings[action] = append(tc.keybindings[action], strings.TrimSpace(k))
+		}
+	}
+	return nil
+}
+
+func (tc *TUITestContext) theBranchHistoryIs(table *godog.Table) error {
+	for i, row := range table.Rows {
+		if i == 0 {
+			continue
+		}
+		tc.recentBranches = append(tc.recentBranches, strings.TrimSpace(row.Cells[0].Value))
+	}
+	return nil
+}
+
+func (tc *TUITestContext) theBranchHistoryShouldRecord(branch string) error {
+	for _, recorded := range tc.history.recorded {
+		if recorded == branch {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected %q to be recorded in the branch history, got %v", branch, tc.history.recorded)
+}
+
+func (tc *TUITestContext) theTUIShouldFailToStartWith(expected string) error {
+	if tc.startErr == nil {
+		return fmt.Errorf("expected the TUI to fail to start")
+	}
+	if !strings.Contains(tc.startErr.Error(), expected) {
+		return fmt.Errorf("expected start error to contain %q, got %q", expected, tc.startErr.Error())
+	}
+	return nil
+}
+
+func (tc *TUITestContext) theFollowingSparseProfilesExist(profileTable *godog.Table) error {
+	tc.sparseProfiles = make(map[string][]string)
+	for i, row := range profileTable.Rows {
+		if i == 0 {
+			continue
+		}
+		name := strings.TrimSpace(row.Cells[0].Value)
+		var directories []string
+		for _, dir := range strings.Split(row.Cells[1].Value, ",") {
+			directories = append(directories, strings.TrimSpace(dir))
+		}
+		tc.sparseProfiles[name] = directories
+	}
+	return nil
+}
+
+func (tc *TUITestContext) theFollowingWorktreeTemplatesExist(templateTable *godog.Table) error {
+	tc.templates = make(config.Templates)
+	header := templateTable.Rows[0].Cells
+	for _, row := range templateTable.Rows[1:] {
+		var prefix string
+		var template config.Template
+		for i, cell := range row.Cells {
+			value := strings.TrimSpace(cell.Value)
+			if value == "" {
+				continue
+			}
+			switch header[i].Value {
+			case "prefix":
+				prefix = value
+			case "base":
+				template.Base = value
+			case "sparse_profile":
+				template.SparseProfile = value
+			case "hooks":
+				template.Hooks = strings.Split(value, "; ")
+			case "default_command":
+				template.DefaultCommand = value
+			case "labels":
+				template.Labels = strings.Split(value, ", ")
+			default:
+				return fmt.Errorf("unknown template column %q", header[i].Value)
+			}
+		}
+		tc.templates[prefix] = template
+	}
+	return nil
+}
+
+func (tc *TUITestContext) theRepoConfigMapsSparsePaths(rulesTable *godog.Table) error {
+	tc.repoConfig = &config.RepoConfig{
+		SparsePaths: config.SparsePathRules{
+			Labels:   make(map[string][]string),
+			Projects: make(map[string][]string),
+		},
+	}
+	for i, row := range rulesTable.Rows {
+		if i == 0 {
+			continue
+		}
+		kind := strings.TrimSpace(row.Cells[0].Value)
+		name := strings.TrimSpace(row.Cells[1].Value)
+		var directories []string
+		for _, dir := range strings.Split(row.Cells[2].Value, ",") {
+			directories = append(directories, strings.TrimSpace(dir))
+		}
+		switch kind {
+		case "label":
+			tc.repoConfig.SparsePaths.Labels[name] = directories
+		case "project":
+			tc.repoConfig.SparsePaths.Projects[name] = directories
+		case "always":
+			tc.repoConfig.SparsePaths.Always = directories
+		default:
+			return fmt.Errorf("unknown sparse path rule kind %q", kind)
+		}
 	}
-	tc.fakeWorktreeManager.worktrees = worktrees
 	return nil
 }
 
@@ -322,17 +907,95 @@ func (tc *TUITestContext) fetchingChildrenForFails(identifier string) error {
 }
 
 func (tc *TUITestContext) iStartTheSproutTUI() error {
-	// Set consistent color profile for testing
-	lipgloss.SetColorProfile(termenv.Ascii)
-
 	// Create test model with fake client and worktree manager stub
 	var err error
-	tc.model, err = NewTUIWithDependenciesAndConfig(tc.fakeWorktreeManager, tc.fakeLinear.Client(), &config.Config{
-		DefaultCommand: tc.defaultWorktreeCmd,
-		ResumeCommand:  tc.resumeWorktreeCmd,
-	})
+	fakeClient := tc.fakeLinear.Client()
+	if tc.linearTimeout > 0 {
+		fakeClient.SetTimeout(tc.linearTimeout)
+	}
+	// Cached as sprout caches each workspace, so what the TUI asks Linear
+	// for is what it would really ask
+	var linearClient linear.LinearClientInterface = issues.NewCache(fakeClient, config.DefaultLinearWorkspace, time.Minute)
+	if len(tc.linearWorkspaces) > 0 {
+		var workspaces []issues.Workspace
+		for _, workspace := range tc.linearWorkspaces {
+			workspaces = append(workspaces, issues.Workspace{Name: workspace.name, Client: issues.NewCache(workspace.server.Client(), workspace.name, time.Minute)})
+		}
+		linearClient = issues.NewAggregate(workspaces)
+	}
+	cfg := &config.Config{
+		DefaultCommand:      tc.defaultWorktreeCmd,
+		DefaultCommands:     tc.defaultCommands,
+		ResumeCommand:       tc.resumeWorktreeCmd,
+		Keybindings:         tc.keybindings,
+		Templates:           tc.templates,
+		IssueCycle:          tc.issueCycle,
+		IssueRefreshSeconds: tc.issueRefreshSeconds,
+	}
+	if tc.browseOnly {
+		tc.model, err = NewIssueBrowserWithDependencies(linearClient, cfg, Deterministic(tc.clock.Now))
+	} else {
+		tc.model, err = NewTUIWithDependenciesAndConfig(tc.fakeWorktreeManager, linearClient, cfg, Deterministic(tc.clock.Now))
+	}
 	if err != nil {
-		return err
+		tc.startErr = err
+		return nil
+	}
+	tc.model.OpenURL = func(url string) error {
+		tc.openedURLs = append(tc.openedURLs, url)
+		return nil
+	}
+	tc.model.CopyText = func(text string) error {
+		tc.clipboard = text
+		return nil
+	}
+	tc.model.SparseProfiles = tc.sparseProfiles
+	tc.model.RecentBranches = tc.recentBranches
+	tc.model.History = &tc.history
+	tc.model.TreeState = &tc.treeStore
+	// Other merged worktrees were already merged when sprout last ran
+	var mergedBefore []string
+	for _, wt := range tc.fakeWorktreeManager.worktrees {
+		if git.ReadyToPrune(wt) && !slices.Contains(tc.mergedSince, wt.Branch) {
+			mergedBefore = append(mergedBefore, wt.Branch)
+		}
+	}
+	mergedHistory := metadata.NewStoreWithPath("/repos/sprout", filepath.Join(tc.t.TempDir(), "metadata.json"))
+	mergedHistory.SeeMerged(mergedBefore, mergedBefore)
+	tc.model.MergedHistory = mergedHistory
+	tc.model.RestoringTree = newTreeRestore(tc.treeStore.IssueTree())
+	tc.model.RepoConfig = tc.repoConfig
+	if tc.issueSort != "" {
+		tc.model.IssueSort = tc.issueSort
+	}
+	tc.model.SaveIssueSort = func(repoRoot, order string) error {
+		tc.savedIssueSorts = append(tc.savedIssueSorts, order)
+		return nil
+	}
+	if len(tc.otherRepos) > 0 {
+		tc.model.RepoRoot = "/repos/sprout"
+		tc.model.RepoRoots = []string{tc.model.RepoRoot}
+		for name := range tc.otherRepos {
+			tc.model.RepoRoots = append(tc.model.RepoRoots, "/repos/"+name)
+		}
+		sort.Strings(tc.model.RepoRoots)
+		tc.model.OpenRepo = func(root string) (git.WorktreeManagerInterface, string, error) {
+			name := filepath.Base(root)
+			worktrees, ok := tc.otherRepos[name]
+			if !ok {
+				return nil, "", fmt.Errorf("%s is not a git repository", root)
+			}
+			return &testWorktreeManager{worktrees: worktrees}, name, nil
+		}
+		if tc.outsideRepo {
+			var roots []string
+			for _, root := range tc.model.RepoRoots {
+				if root != tc.model.RepoRoot {
+					roots = append(roots, root)
+				}
+			}
+			tc.model = tc.model.pickingRepo(roots, tc.model.OpenRepo)
+		}
 	}
 
 	// Manually execute the initialization to trigger loading
@@ -354,7 +1017,15 @@ func (tc *TUITestContext) iStartTheSproutTUI() error {
 func (tc *TUITestContext) executeInitialization() {
 	// Manually trigger the linear loading since we can't easily execute tea.Batch in tests
 	if tc.model.LinearClient != nil && tc.model.LinearLoading {
-		if tc.pauseLinearLoading {
+		if tc.issuePagesHeld {
+			// Load issues as the TUI does, a page at a time, up to the pages held back
+			tc.processCmd(tc.model.fetchLinearIssues())
+			for tc.model.IssuesLoadedSoFar == 0 {
+				if err := tc.waitForOneAsyncMessage(2 * time.Second); err != nil {
+					tc.t.Fatalf("waiting for the first page of issues: %v", err)
+				}
+			}
+		} else if tc.pauseLinearLoading {
 			tc.model.LinearLoadingStatus = "Loading Linear issues..."
 		} else {
 			// Simulate the fetchLinearIssues command
@@ -367,16 +1038,20 @@ func (tc *TUITestContext) executeInitialization() {
 				msg = linearIssuesLoadedMsg{issues}
 			}
 
-			// Update the model with the loading result
-			updatedModel, _ := tc.model.Update(msg)
+			// Update the model with the loading result, following any saved tree it restores
+			updatedModel, cmd := tc.model.Update(msg)
 			tc.model = updatedModel.(model)
+			tc.processCmd(cmd)
+			tc.drainWithTimeout(10 * time.Millisecond)
 		}
 	}
 	if tc.model.WorktreeManager != nil && tc.model.WorktreesLoading {
-		worktrees, err := tc.model.WorktreeManager.ListWorktreesForTUIWithProgress(func(status string) {
-			updatedModel, _ := tc.model.Update(worktreesLoadingStatusMsg{status: status})
+		presented := make(chan tea.Msg, 64)
+		worktrees, err := tc.model.WorktreeManager.ListWorktreesForTUIWithProgress(teaPresenter{operation: loadingWorktrees, ch: presented})
+		for len(presented) > 0 {
+			updatedModel, _ := tc.model.Update(<-presented)
 			tc.model = updatedModel.(model)
-		})
+		}
 		if tc.fakeWorktreeManager.pauseStatus != "" {
 			return
 		}
@@ -386,9 +1061,80 @@ func (tc *TUITestContext) executeInitialization() {
 		} else {
 			msg = worktreesLoadedMsg{worktrees}
 		}
-		updatedModel, _ := tc.model.Update(msg)
+		updatedModel, cmd := tc.model.Update(msg)
 		tc.model = updatedModel.(model)
+		tc.processCmd(cmd)
+	}
+}
+
+func (tc *TUITestContext) iStartTheIssueBrowser() error {
+	tc.browseOnly = true
+	return tc.iStartTheSproutTUI()
+}
+
+func (tc *TUITestContext) theBrowserShouldOpen(expected string) error {
+	if !slices.Contains(tc.openedURLs, expected) {
+		return fmt.Errorf("expected %s to be opened, opened: %v", expected, tc.openedURLs)
+	}
+	return nil
+}
+
+func (tc *TUITestContext) theClipboardShouldContain(expected string) error {
+	if tc.clipboard != expected {
+		return fmt.Errorf("expected the clipboard to contain %q, got %q", expected, tc.clipboard)
+	}
+	return nil
+}
+
+func (tc *TUITestContext) theSubtasksShouldHaveBeenFetchedInOneRequest(parents string) error {
+	tc.drainWithTimeout(20 * time.Millisecond)
+	var want []string
+	for _, id := range strings.Split(parents, ",") {
+		want = append(want, strings.TrimSpace(id))
 	}
+	sort.Strings(want)
+
+	var fetched [][]string
+	for _, req := range tc.fakeLinear.Requests {
+		if !strings.Contains(req.Query, "children") || !strings.Contains(req.Query, "issue(id:") {
+			continue
+		}
+		variables, _ := req.Variables.(map[string]any)
+		var ids []string
+		for _, value := range variables {
+			ids = append(ids, fmt.Sprint(value))
+		}
+		sort.Strings(ids)
+		if slices.Equal(ids, want) {
+			return nil
+		}
+		fetched = append(fetched, ids)
+	}
+	return fmt.Errorf("expected one request for the subtasks of %v, got requests for %v", want, fetched)
+}
+
+func (tc *TUITestContext) theLastSessionLeftTheTree(expanded, selected string) error {
+	tc.treeStore.state = metadata.IssueTreeState{Selected: selected}
+	for _, id := range strings.Split(expanded, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			tc.treeStore.state.Expanded = append(tc.treeStore.state.Expanded, id)
+		}
+	}
+	return nil
+}
+
+func (tc *TUITestContext) iQuitAndStartTheSproutTUIAgain() error {
+	tc.model.saveIssueTree()
+	return tc.iStartTheSproutTUI()
+}
+
+func (tc *TUITestContext) iPressTimes(key string, times int) error {
+	for i := 0; i < times; i++ {
+		if err := tc.iPress(key); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (tc *TUITestContext) iPress(key string) error {
@@ -411,6 +1157,10 @@ func (tc *TUITestContext) iPress(key string) error {
 		keyMsg = tea.KeyMsg{Type: tea.KeyEsc}
 	case "tab":
 		keyMsg = tea.KeyMsg{Type: tea.KeyTab}
+	case "space":
+		keyMsg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	case "shift+tab":
+		keyMsg = tea.KeyMsg{Type: tea.KeyShiftTab}
 	case "/":
 		keyMsg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}}
 	case "backspace":
@@ -422,6 +1172,22 @@ func (tc *TUITestContext) iPress(key string) error {
 		keyMsg = tea.KeyMsg{Type: tea.KeyCtrlJ}
 	case "ctrl+s":
 		keyMsg = tea.KeyMsg{Type: tea.KeyCtrlS}
+	case "ctrl+u":
+		keyMsg = tea.KeyMsg{Type: tea.KeyCtrlU}
+	case "ctrl+d":
+		keyMsg = tea.KeyMsg{Type: tea.KeyCtrlD}
+	case "home":
+		keyMsg = tea.KeyMsg{Type: tea.KeyHome}
+	case "end":
+		keyMsg = tea.KeyMsg{Type: tea.KeyEnd}
+	case "ctrl+l":
+		keyMsg = tea.KeyMsg{Type: tea.KeyCtrlL}
+	case "ctrl+p":
+		keyMsg = tea.KeyMsg{Type: tea.KeyCtrlP}
+	case "ctrl+r":
+		keyMsg = tea.KeyMsg{Type: tea.KeyCtrlR}
+	case "s":
+		keyMsg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}}
 	case "u":
 		keyMsg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}}
 	case "d":
@@ -430,7 +1196,13 @@ func (tc *TUITestContext) iPress(key string) error {
 		keyMsg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}}
 	case "a":
 		keyMsg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}}
+	case "r":
+		keyMsg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}}
 	default:
+		if runes := []rune(key); len(runes) == 1 {
+			keyMsg = tea.KeyMsg{Type: tea.KeyRunes, Runes: runes}
+			break
+		}
 		return fmt.Errorf("unknown key: %s", key)
 	}
 
@@ -464,6 +1236,16 @@ func (tc *TUITestContext) iTypeTheFollowingText(text *godog.DocString) error {
 	return tc.iType(text.Content)
 }
 
+// iPaste sends text as a terminal delivers a bracketed paste, all at once
+func (tc *TUITestContext) iPaste(text *godog.DocString) error {
+	updatedModel, cmd := tc.model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text.Content), Paste: true})
+	tc.model = updatedModel.(model)
+
+	tc.processCmd(cmd)
+	tc.drainWithTimeout(10 * time.Millisecond)
+	return nil
+}
+
 // processCmd executes a command and handles any resulting messages (including batches)
 func (tc *TUITestContext) processCmd(cmd tea.Cmd) {
 	if cmd == nil {
@@ -533,25 +1315,46 @@ func (tc *TUITestContext) processMsg(msg tea.Msg) {
 		return
 	}
 
-	updatedModel, _ := tc.model.Update(msg)
+	updatedModel, followUp := tc.model.Update(msg)
 	tc.model = updatedModel.(model)
 	tc.maybeRunPostCreateCommand()
 	tc.maybeRunPostResumeCommand()
+
+	// Follow worktree loading through to completion, as happens after switching repos
+	switch msg.(type) {
+	case worktreeLoadStartedMsg, worktreesLoadedMsg, worktreeRenamedMsg:
+		tc.processCmd(followUp)
+	case worktreeCreateStartedMsg, progressMsg:
+		// Follow loading or creating a worktree through each step it presents
+		tc.processCmd(followUp)
+	case childrenLoadedMsg, childrenErrorMsg, childrenBatchLoadedMsg, searchChildrenLoadedMsg:
+		// A restored tree or a search fetches grandchildren once their parents arrive
+		tc.processCmd(followUp)
+	case childrenPrefetchStartedMsg, childrenPrefetchedMsg:
+		// Subtasks are fetched in the background one batch after another
+		tc.processCmd(followUp)
+	case issuesLoadStartedMsg, issuesPageMsg, linearIssuesLoadedMsg:
+		// Issues arrive a page at a time, and refreshed issues reopen the tree they replaced
+		tc.processCmd(followUp)
+	case checklistSubtaskMsg:
+		// Each checklist subtask is created once the one before it is done
+		tc.processCmd(followUp)
+	case pruneStartedMsg, pruneProgressMsg, pruneFinishedMsg:
+		// Follow a prune through each worktree to the reload after it
+		tc.processCmd(followUp)
+	}
 }
 
 func (tc *TUITestContext) maybeRunPostCreateCommand() {
 	if tc.postCreateRan {
 		return
 	}
-	if tc.defaultWorktreeCmd == "" {
-		return
-	}
 	if !tc.model.Done || !tc.model.Success || tc.model.WorktreePath == "" {
 		return
 	}
 
-	cfg := &config.Config{DefaultCommand: tc.defaultWorktreeCmd}
-	resolved := config.ResolveDefaultCommand(cfg.GetDefaultCommand(), tc.model.CapturedPrompt)
+	// The model's command, as main runs it, so templates can override the configured one
+	resolved := config.ResolveDefaultCommand(tc.model.DefaultCommandArgs, tc.model.CapturedPrompt)
 	if len(resolved) == 0 {
 		return
 	}
@@ -597,6 +1400,27 @@ func formatCommandArgs(args []string) string {
 	return strings.Join(parts, " ")
 }
 
+// theSearchMatchShouldBe checks which characters of an issue's row search
+// highlights, since the highlight itself is only a style
+func (tc *TUITestContext) theSearchMatchShouldBe(identifier, expected string) error {
+	tc.drainWithTimeout(20 * time.Millisecond)
+	for _, row := range tc.model.visibleWorkQueueRows() {
+		if row.Issue == nil || row.Issue.Identifier != identifier {
+			continue
+		}
+		text := []rune(issueSearchText(*row.Issue))
+		var matched strings.Builder
+		for _, p := range row.Match {
+			matched.WriteRune(text[p])
+		}
+		if actual := strings.ToLower(matched.String()); actual != expected {
+			return fmt.Errorf("expected search to match %q in %s, got %q", expected, identifier, actual)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s is not in the search results", identifier)
+}
+
 func (tc *TUITestContext) theUIShouldDisplay(expected *godog.DocString) error {
 	if tc.testModel == nil {
 		return fmt.Errorf("test model not initialized")
@@ -753,6 +1577,11 @@ func (tc *TUITestContext) myTerminalWidthIsCharacters(width int) error {
 	return nil
 }
 
+func (tc *TUITestContext) myTerminalHeightIsLines(height int) error {
+	tc.terminalHeight = height
+	return nil
+}
+
 func (tc *TUITestContext) theUIShouldDisplayTitlesTruncatedToFitTheAvailableWidth() error {
 	if tc.testModel == nil {
 		return fmt.Errorf("test model not initialized")
@@ -836,6 +1665,14 @@ func (tc *TUITestContext) theDefaultWorktreeCommandIs(command string) error {
 	return nil
 }
 
+func (tc *TUITestContext) theDefaultCommandForBranchesIs(pattern, command string) error {
+	if tc.defaultCommands.Branches == nil {
+		tc.defaultCommands.Branches = make(map[string]string)
+	}
+	tc.defaultCommands.Branches[pattern] = command
+	return nil
+}
+
 func (tc *TUITestContext) aConfigWith(configTable *godog.Table) error {
 	for i, row := range configTable.Rows {
 		if i == 0 {
@@ -848,11 +1685,36 @@ func (tc *TUITestContext) aConfigWith(configTable *godog.Table) error {
 			tc.defaultWorktreeCmd = value
 		case "resumeCommand", "resume_command":
 			tc.resumeWorktreeCmd = value
+		case "issueCycle":
+			tc.issueCycle = value
+		case "issueRefreshSeconds":
+			seconds, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("issueRefreshSeconds must be a number, got %q", value)
+			}
+			tc.issueRefreshSeconds = seconds
 		}
 	}
 	return nil
 }
 
+func (tc *TUITestContext) issuesAreSortedBy(order string) error {
+	tc.issueSort = order
+	return nil
+}
+
+func (tc *TUITestContext) theCurrentLinearCycleIs(number int) error {
+	tc.currentCycle = number
+	return nil
+}
+
+func (tc *TUITestContext) theIssueSortShouldBeSavedAs(order string) error {
+	if len(tc.savedIssueSorts) == 0 || tc.savedIssueSorts[len(tc.savedIssueSorts)-1] != order {
+		return fmt.Errorf("expected issue sort %q to be saved, saved: %v", order, tc.savedIssueSorts)
+	}
+	return nil
+}
+
 func (tc *TUITestContext) theTUIShouldResumeWorktree(path string) error {
 	tc.drainWithTimeout(20 * time.Millisecond)
 	if !tc.model.Resumed {
@@ -879,6 +1741,59 @@ func (tc *TUITestContext) aWorktreeShouldBeCreatedForBranch(branch string) error
 	return nil
 }
 
+func (tc *TUITestContext) theSubtaskShouldBeCreatedWith(fieldsTable *godog.Table) error {
+	tc.drainWithTimeout(20 * time.Millisecond)
+
+	var variables map[string]any
+	for _, req := range tc.fakeLinear.Requests {
+		if strings.Contains(req.Query, "issueCreate") {
+			variables, _ = req.Variables.(map[string]any)
+		}
+	}
+	if variables == nil {
+		return fmt.Errorf("expected an issueCreate request, got none")
+	}
+
+	for _, row := range fieldsTable.Rows[1:] {
+		field, expected := row.Cells[0].Value, row.Cells[1].Value
+		actual, ok := variables[field]
+		if expected == "" {
+			if ok {
+				return fmt.Errorf("expected %s to be unset, got %v", field, actual)
+			}
+			continue
+		}
+		if !ok {
+			return fmt.Errorf("expected %s to be %q, but it was not sent", field, expected)
+		}
+		if fmt.Sprint(actual) != strings.ReplaceAll(expected, `\n`, "\n") {
+			return fmt.Errorf("expected %s to be %q, got %q", field, expected, fmt.Sprint(actual))
+		}
+	}
+	return nil
+}
+
+func (tc *TUITestContext) subtasksShouldBeCreatedWithTheTitles(titlesTable *godog.Table) error {
+	tc.drainWithTimeout(20 * time.Millisecond)
+
+	var titles []string
+	for _, req := range tc.fakeLinear.Requests {
+		if strings.Contains(req.Query, "issueCreate") {
+			variables, _ := req.Variables.(map[string]any)
+			titles = append(titles, fmt.Sprint(variables["title"]))
+		}
+	}
+
+	var expected []string
+	for _, row := range titlesTable.Rows[1:] {
+		expected = append(expected, row.Cells[0].Value)
+	}
+	if strings.Join(titles, "\n") != strings.Join(expected, "\n") {
+		return fmt.Errorf("expected subtasks %q to be created in order, got %q", expected, titles)
+	}
+	return nil
+}
+
 func (tc *TUITestContext) theUIShouldNotDisplay(text string) error {
 	tc.drainWithTimeout(20 * time.Millisecond)
 	actual := StripANSI(tc.model.View())
@@ -956,6 +1871,33 @@ func (tc *TUITestContext) linearIssueLoadingCompletes() error {
 	return nil
 }
 
+// linearSendsIssuesAtATime has Linear send the assigned issues in pages of
+// size, holding back every page after the first until the rest arrive
+func (tc *TUITestContext) linearSendsIssuesAtATime(size int) error {
+	tc.fakeLinear.SetPageSize(size)
+	tc.fakeLinear.HoldPages()
+	tc.issuePagesHeld = true
+	return nil
+}
+
+// theRestOfMyIssuesArrive lets Linear send the pages it held back and waits
+// for the TUI to finish loading them
+func (tc *TUITestContext) theRestOfMyIssuesArrive() error {
+	tc.fakeLinear.ReleasePages()
+	for tc.model.LinearLoading {
+		if err := tc.waitForOneAsyncMessage(2 * time.Second); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tc *TUITestContext) linearDoesNotRespondInTime() error {
+	tc.fakeLinear.Stall()
+	tc.linearTimeout = 20 * time.Millisecond
+	return nil
+}
+
 func (tc *TUITestContext) githubPRStatusLookupFailsForBranch(branch string) error {
 	tc.fakeWorktreeManager.failPRBranch = branch
 	tc.fakeWorktreeManager.worktrees = []git.Worktree{{
@@ -1016,6 +1958,7 @@ func InitializeScenario(ctx *godog.ScenarioContext, t *testing.T) {
 		tc.fakeLinear = lineartest.NewServer(t)
 		tc.fakeWorktreeManager = &testWorktreeManager{cachedMerged: make(map[string]bool)}
 		tc.defaultWorktreeCmd = ""
+		tc.defaultCommands = config.DefaultCommands{}
 		tc.resumeWorktreeCmd = ""
 		tc.postCreateRuns = nil
 		tc.postResumeRuns = nil
@@ -1031,20 +1974,110 @@ func InitializeScenario(ctx *godog.ScenarioContext, t *testing.T) {
 
 	// Step definitions
 	ctx.Step(`^the following Linear issues exist:$`, tc.theFollowingLinearIssuesExist)
+	ctx.Step(`^the following Linear issues exist in workspace "([^"]*)":$`, tc.theFollowingLinearIssuesExistInWorkspace)
+	ctx.Step(`^workspace "([^"]*)" should have updated "([^"]*)"$`, tc.workspaceShouldHaveUpdated)
+	ctx.Step(`^Linear now also has:$`, tc.linearNowAlsoHas)
+	ctx.Step(`^workspace "([^"]*)" now also has:$`, tc.workspaceNowAlsoHas)
+	ctx.Step(`^Linear should have been asked for my issues (\d+) times?$`, tc.linearShouldHaveListedMyIssues)
+	ctx.Step(`^(\d+) minutes? pass(?:es)?$`, tc.minutesPass)
+	ctx.Step(`^the spinner ticks$`, tc.theSpinnerTicks)
 	ctx.Step(`^the following worktrees exist:$`, tc.theFollowingWorktreesExist)
+	ctx.Step(`^pruning "([^"]*)" fails with "([^"]*)"$`, func(branch, reason string) error {
+		if tc.fakeWorktreeManager.pruneFailures == nil {
+			tc.fakeWorktreeManager.pruneFailures = map[string]string{}
+		}
+		tc.fakeWorktreeManager.pruneFailures[branch] = reason
+		return nil
+	})
+	ctx.Step(`^"([^"]*)" should (still|no longer) be a local branch$`, func(branch, still string) error {
+		if kept := slices.Contains(tc.fakeWorktreeManager.branches, branch); kept != (still == "still") {
+			return fmt.Errorf("expected %q %s a local branch, got branches %v", branch, still, tc.fakeWorktreeManager.branches)
+		}
+		return nil
+	})
+	ctx.Step(`^"([^"]*)" should (also|not) be deleted on origin$`, func(branch, also string) error {
+		if deleted := slices.Contains(tc.fakeWorktreeManager.remoteDeleted, branch); deleted != (also == "also") {
+			return fmt.Errorf("expected %q %s deleted on origin, got %v", branch, also, tc.fakeWorktreeManager.remoteDeleted)
+		}
+		return nil
+	})
+	ctx.Step(`^"([^"]*)" has merged since sprout last ran$`, func(branch string) error {
+		tc.mergedSince = append(tc.mergedSince, branch)
+		return nil
+	})
+	ctx.Step(`^"([^"]*)" has PR #(\d+) on branch "([^"]*)"$`, func(ref string, number int, branch string) error {
+		if tc.fakeWorktreeManager.pullRequests == nil {
+			tc.fakeWorktreeManager.pullRequests = map[string]git.PRCheckout{}
+		}
+		tc.fakeWorktreeManager.pullRequests[ref] = git.PRCheckout{
+			PullRequest:  github.PullRequest{Number: number, HeadBranch: branch, State: "Open"},
+			WorktreePath: "/mock/worktrees/" + branch,
+		}
+		return nil
+	})
+	ctx.Step(`^creating a worktree warns "([^"]*)"$`, func(warning string) error {
+		tc.fakeWorktreeManager.createWarning = warning
+		return nil
+	})
+	ctx.Step(`^creating a worktree fails with:$`, func(message *godog.DocString) error {
+		tc.fakeWorktreeManager.createErr = errors.New(message.Content)
+		return nil
+	})
+	ctx.Step(`^the directory for new worktrees is taken by something else$`, func() error {
+		tc.fakeWorktreeManager.createErr = fmt.Errorf("%w: /mock/worktrees/spr-123-add-user-authentication", git.ErrPathNotWorktree)
+		return nil
+	})
+	ctx.Step(`^branch "([^"]*)" already exists$`, tc.branchAlreadyExists)
+	ctx.Step(`^repo "([^"]*)" is registered with worktrees:$`, tc.repoIsRegisteredWithWorktrees)
+	ctx.Step(`^the keybindings are:$`, tc.theKeybindingsAre)
+	ctx.Step(`^the branch history is:$`, tc.theBranchHistoryIs)
+	ctx.Step(`^the branch history should record "([^"]*)"$`, tc.theBranchHistoryShouldRecord)
+	ctx.Step(`^the TUI should fail to start with "([^"]*)"$`, tc.theTUIShouldFailToStartWith)
+	ctx.Step(`^the following sparse profiles exist:$`, tc.theFollowingSparseProfilesExist)
+	ctx.Step(`^the repo config maps sparse paths:$`, tc.theRepoConfigMapsSparsePaths)
+	ctx.Step(`^the following worktree templates exist:$`, tc.theFollowingWorktreeTemplatesExist)
 	ctx.Step(`^fetching children for "([^"]*)" fails$`, tc.fetchingChildrenForFails)
 	ctx.Step(`^a config with:$`, tc.aConfigWith)
+	ctx.Step(`^issues are sorted by "([^"]*)"$`, tc.issuesAreSortedBy)
+	ctx.Step(`^the issue sort should be saved as "([^"]*)"$`, tc.theIssueSortShouldBeSavedAs)
+	ctx.Step(`^the current Linear cycle is (\d+)$`, tc.theCurrentLinearCycleIs)
 	ctx.Step(`^my terminal width is (\d+) characters$`, tc.myTerminalWidthIsCharacters)
+	ctx.Step(`^my terminal height is (\d+) lines$`, tc.myTerminalHeightIsLines)
 	ctx.Step(`^I start the Sprout TUI$`, tc.iStartTheSproutTUI)
+	ctx.Step(`^the TUI should have quit$`, func() error {
+		if !tc.model.Cancelled {
+			return fmt.Errorf("expected the TUI to have quit")
+		}
+		return nil
+	})
+	ctx.Step(`^I start the Sprout TUI outside any repository$`, func() error {
+		tc.outsideRepo = true
+		return tc.iStartTheSproutTUI()
+	})
+	ctx.Step(`^I start the issue browser$`, tc.iStartTheIssueBrowser)
+	ctx.Step(`^the browser should open "([^"]*)"$`, tc.theBrowserShouldOpen)
+	ctx.Step(`^the clipboard should contain "([^"]*)"$`, tc.theClipboardShouldContain)
+	ctx.Step(`^I quit and start the Sprout TUI again$`, tc.iQuitAndStartTheSproutTUIAgain)
+	ctx.Step(`^the last session left "([^"]*)" expanded and "([^"]*)" selected$`, tc.theLastSessionLeftTheTree)
+	ctx.Step(`^the subtasks of "([^"]*)" should have been fetched in one request$`, tc.theSubtasksShouldHaveBeenFetchedInOneRequest)
 	ctx.Step(`^I press "([^"]*)"$`, tc.iPress)
+	ctx.Step(`^I press "([^"]*)" (\d+) times$`, tc.iPressTimes)
 	ctx.Step(`^I type "([^"]*)"$`, tc.iType)
 	ctx.Step(`^I type the following text:$`, tc.iTypeTheFollowingText)
 	ctx.Step(`^the UI should display:$`, tc.theUIShouldDisplay)
 	ctx.Step(`^the UI should display "([^"]*)"$`, tc.theUIShouldDisplayText)
+	ctx.Step(`^the search match in "([^"]*)" should be "([^"]*)"$`, tc.theSearchMatchShouldBe)
 	ctx.Step(`^the UI should not display "([^"]*)"$`, tc.theUIShouldNotDisplay)
 	ctx.Step(`^the UI should contain "([^"]*)"$`, tc.theUIShouldContain)
 	ctx.Step(`^the following commands should be run:$`, tc.theFollowingCommandsShouldBeRun)
 	ctx.Step(`^the TUI should resume worktree "([^"]*)"$`, tc.theTUIShouldResumeWorktree)
+	ctx.Step(`^the subtask should be created with:$`, tc.theSubtaskShouldBeCreatedWith)
+	ctx.Step(`^subtasks should be created with the titles:$`, tc.subtasksShouldBeCreatedWithTheTitles)
+	ctx.Step(`^I paste:$`, tc.iPaste)
+	ctx.Step(`^Linear fails to create the subtask "([^"]*)"$`, func(title string) error {
+		tc.fakeLinear.FailIssueCreate(title, fmt.Errorf("title too long"))
+		return nil
+	})
 	ctx.Step(`^no new worktree should be created$`, tc.noNewWorktreeShouldBeCreated)
 	ctx.Step(`^a worktree should be created for branch "([^"]*)"$`, tc.aWorktreeShouldBeCreatedForBranch)
 	ctx.Step(`^(\d+) active work queue rows exist$`, tc.activeWorkQueueRowsExist)
@@ -1054,11 +2087,15 @@ func InitializeScenario(ctx *godog.ScenarioContext, t *testing.T) {
 	ctx.Step(`^Linear issue loading is paused$`, tc.linearIssueLoadingIsPaused)
 	ctx.Step(`^worktree loading has completed$`, tc.worktreeLoadingHasCompleted)
 	ctx.Step(`^Linear issue loading completes$`, tc.linearIssueLoadingCompletes)
+	ctx.Step(`^Linear doesn't respond in time$`, tc.linearDoesNotRespondInTime)
+	ctx.Step(`^Linear sends my issues (\d+) at a time$`, tc.linearSendsIssuesAtATime)
+	ctx.Step(`^the rest of my issues arrive$`, tc.theRestOfMyIssuesArrive)
 	ctx.Step(`^GitHub PR status lookup fails for branch "([^"]*)"$`, tc.githubPRStatusLookupFailsForBranch)
 	ctx.Step(`^worktree "([^"]*)" is cached as merged at its current commit$`, tc.worktreeIsCachedAsMergedAtItsCurrentCommit)
 	ctx.Step(`^the post-resume command should be "([^"]*)"$`, tc.postResumeCommandShouldBe)
 	ctx.Step(`^no post-resume command should run$`, tc.noPostResumeCommandShouldRun)
 	ctx.Step(`^the default worktree command is "([^"]*)"$`, tc.theDefaultWorktreeCommandIs)
+	ctx.Step(`^the default command for branches "([^"]*)" is "([^"]*)"$`, tc.theDefaultCommandForBranchesIs)
 	ctx.Step(`^the default worktree command is "([^"]*)"\$PROMPT\\"([^"]*)"$`, func(prefix, suffix string) error {
 		return tc.theDefaultWorktreeCommandIs(prefix + "$PROMPT" + suffix)
 	})
@@ -1080,15 +2117,45 @@ func TestFeatures(t *testing.T) {
 			Format: "pretty",
 			Paths: []string{
 				"../../features/async_prompt.feature",
+				"../../features/branch_suggestions.feature",
+				"../../features/board.feature",
+				"../../features/branch_validation.feature",
 				"../../features/duplicate_handling.feature",
+				"../../features/existing_branch.feature",
 				"../../features/expansion.feature",
+				"../../features/help_overlay.feature",
 				"../../features/interaction.feature",
+				"../../features/issue_browser.feature",
+				"../../features/issue_cycles.feature",
+				"../../features/issue_labels.feature",
+				"../../features/issue_preview.feature",
+				"../../features/issue_refresh.feature",
+				"../../features/issue_sorting.feature",
+				"../../features/keybindings.feature",
+				"../../features/linear_workspaces.feature",
+				"../../features/linked_ticket_status.feature",
 				"../../features/navigation.feature",
+				"../../features/next_up.feature",
+				"../../features/prune.feature",
+				"../../features/quick_jump.feature",
+				"../../features/rename.feature",
+				"../../features/worktree_notes.feature",
+				"../../features/repo_picker.feature",
+				"../../features/repo_switcher.feature",
 				"../../features/resume_command.feature",
 				"../../features/resume_work_queue.feature",
+				"../../features/scrolling.feature",
 				"../../features/search.feature",
+				"../../features/sparse_path_inference.feature",
+				"../../features/sparse_profiles.feature",
+				"../../features/status_picker.feature",
+				"../../features/subtask_form.feature",
+				"../../features/tree_persistence.feature",
+				"../../features/vim_navigation.feature",
 				"../../features/work_queue_loading.feature",
 				"../../features/window_width.feature",
+				"../../features/worktree_states.feature",
+				"../../features/worktree_templates.feature",
 			},
 			TestingT: t,
 		},
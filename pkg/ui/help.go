@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Help overlay panel - bordered so it stands out from the view underneath
+var helpPanelStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(primaryColor).
+	Padding(0, 1)
+
+// renderHelpPanel lists every binding in the active keymap, followed by what
+// the main keys do for the current selection
+func (m model) renderHelpPanel() string {
+	s := strings.Builder{}
+	s.WriteString(headerStyle.Render("Keybindings"))
+	s.WriteString("\n")
+	for _, action := range keyActions {
+		help := action.binding(&m.Keys).Help()
+		s.WriteString(normalStyle.Render(fmt.Sprintf("%-10s %s", help.Key, help.Desc)))
+		s.WriteString("\n")
+	}
+
+	s.WriteString(headerStyle.Render("Right now"))
+	s.WriteString("\n")
+	for _, action := range m.helpContextActions() {
+		s.WriteString(normalStyle.Render(fmt.Sprintf("%-10s %s", action[0], action[1])))
+		s.WriteString("\n")
+	}
+
+	s.WriteString(helpStyle.Render("[" + m.Keys.Help.Help().Key + "/esc close]"))
+	return helpPanelStyle.Render(s.String())
+}
+
+// helpContextActions pairs keys with what they do for the selected row, so the
+// overlay can point at actions that depend on where the user is
+func (m model) helpContextActions() [][2]string {
+	keyOf := func(binding key.Binding) string {
+		return binding.Help().Key
+	}
+	mode := "worktree"
+	otherMode := "branch only"
+	if m.CreationMode == creationModeBranchOnly {
+		mode = "branch"
+		otherMode = "worktree"
+	}
+
+	if m.BoardMode {
+		actions := [][2]string{
+			{keyOf(m.Keys.Collapse) + " " + keyOf(m.Keys.Expand), "move between columns"},
+			{keyOf(m.Keys.Up) + " " + keyOf(m.Keys.Down), "move between cards"},
+		}
+		if m.SelectedIssue != nil && m.BrowseOnly {
+			actions = append(actions, [2]string{keyOf(m.Keys.OpenIssue) + " " + keyOf(m.Keys.CopyIssue), "open in browser, copy " + m.SelectedIssue.Identifier})
+		} else if m.SelectedIssue != nil {
+			actions = append(actions, [2]string{keyOf(m.Keys.Select), "create a " + mode + " for " + m.SelectedIssue.Identifier})
+		}
+		return append(actions, [2]string{keyOf(m.Keys.Board), "back to the list"})
+	}
+
+	if m.AddSubtaskSelected != "" {
+		return [][2]string{
+			{keyOf(m.Keys.Expand), "start a subtask of " + m.findIssueIdentifier(m.AddSubtaskSelected)},
+			{"tab", "add description, estimate and priority"},
+			{keyOf(m.Keys.Collapse), "collapse the parent issue"},
+		}
+	}
+
+	if row := m.selectedRow(); row != nil && row.Worktree != nil {
+		actions := [][2]string{{keyOf(m.Keys.Select), "resume " + row.Worktree.Name()}}
+		// A detached HEAD has no branch to rename
+		if row.Worktree.Branch != "" {
+			actions = append(actions, [2]string{keyOf(m.Keys.Rename), "rename " + row.Worktree.Branch})
+			actions = append(actions, [2]string{keyOf(m.Keys.Note), "note on " + row.Worktree.Branch})
+			actions = append(actions, [2]string{keyOf(m.Keys.CheckoutPR), "pull the latest head of its PR"})
+		}
+		return append(actions, [2]string{keyOf(m.Keys.Search), "fuzzy search issues"})
+	}
+
+	if m.SelectedIssue != nil {
+		actions := [][2]string{
+			{keyOf(m.Keys.Select), "create a " + mode + " for " + m.SelectedIssue.Identifier},
+			{keyOf(m.Keys.Expand), "show subtasks or add one"},
+		}
+		if m.BrowseOnly {
+			actions = [][2]string{{keyOf(m.Keys.Select), "show or hide subtasks of " + m.SelectedIssue.Identifier}}
+		}
+		if m.LinearClient != nil {
+			actions = append(actions, [2]string{
+				keyOf(m.Keys.Status) + " " + keyOf(m.Keys.Unassign) + " " + keyOf(m.Keys.Done),
+				"change status, unassign, mark done",
+			})
+		}
+		if !m.BrowseOnly && m.WorktreeManager != nil {
+			actions = append(actions, [2]string{keyOf(m.Keys.CheckoutPR), "pick up " + m.SelectedIssue.Identifier + "'s PR again"})
+		}
+		return append(actions, [2]string{
+			keyOf(m.Keys.OpenIssue) + " " + keyOf(m.Keys.CopyIssue),
+			"open in browser, copy " + m.SelectedIssue.Identifier,
+		})
+	}
+
+	if m.BrowseOnly {
+		return [][2]string{{keyOf(m.Keys.Search), "fuzzy search issues"}}
+	}
+
+	return [][2]string{
+		{"type", "name a new branch"},
+		{keyOf(m.Keys.Select), "create the " + mode},
+		{keyOf(m.Keys.ToggleMode), "switch to " + otherMode},
+		{keyOf(m.Keys.Search), "fuzzy search issues"},
+	}
+}
+
+// findIssueIdentifier returns the identifier shown for issue id, falling back to the id
+func (m model) findIssueIdentifier(id string) string {
+	if issue := m.findIssueByID(id); issue != nil {
+		return issue.Identifier
+	}
+	return id
+}
+
+// placeOverlay draws panel centered over base, keeping the parts of base either
+// side of it visible; base is padded to height so the panel centers on screen
+func placeOverlay(base, panel string, width, height int) string {
+	baseLines := strings.Split(base, "\n")
+	panelLines := strings.Split(panel, "\n")
+	for len(baseLines) < height {
+		baseLines = append(baseLines, "")
+	}
+	if width <= 0 {
+		for _, line := range baseLines {
+			width = max(width, ansi.StringWidth(line))
+		}
+	}
+
+	panelWidth := lipgloss.Width(panel)
+	x := max(0, (width-panelWidth)/2)
+	y := max(0, (len(baseLines)-len(panelLines))/2)
+	for i, line := range panelLines {
+		row := y + i
+		if row >= len(baseLines) {
+			baseLines = append(baseLines, "")
+		}
+		left := ansi.Truncate(baseLines[row], x, "")
+		left += strings.Repeat(" ", x-ansi.StringWidth(left))
+		right := ansi.TruncateLeft(baseLines[row], x+panelWidth, "")
+		baseLines[row] = left + line + right
+	}
+	return strings.Join(baseLines, "\n")
+}
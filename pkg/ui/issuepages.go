@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"sprout/pkg/linear"
+)
+
+// issuesLoadStartedMsg is the channel pages of assigned issues arrive on,
+// followed by a linearIssuesLoadedMsg or linearErrorMsg once they're all in
+type issuesLoadStartedMsg struct {
+	ch <-chan tea.Msg
+}
+
+// issuesPageMsg is every assigned issue loaded so far, while the rest load
+type issuesPageMsg struct {
+	page linear.IssuesPage
+	ch   <-chan tea.Msg // where the next page, or the whole list, comes from
+}
+
+func (m model) fetchLinearIssues() tea.Cmd {
+	client := m.LinearClient
+	return func() tea.Msg {
+		ch := make(chan tea.Msg, 16)
+		go func() {
+			issues, err := linear.StreamAssignedIssues(client, func(page linear.IssuesPage) {
+				ch <- issuesPageMsg{page: page, ch: ch}
+			})
+			if err != nil {
+				ch <- linearErrorMsg{err}
+			} else {
+				ch <- linearIssuesLoadedMsg{issues}
+			}
+			close(ch)
+		}()
+		return issuesLoadStartedMsg{ch: ch}
+	}
+}
+
+// showIssuesPage lists the issues loaded so far while the rest load, so they
+// can be browsed and picked without waiting. A refresh carries on showing the
+// issues it's replacing until they've all arrived
+func (m *model) showIssuesPage(page linear.IssuesPage) tea.Cmd {
+	if !m.LinearLoading {
+		return nil
+	}
+	m.replaceLoadedIssues(page.Issues)
+	m.IssuesLoadedSoFar = page.Loaded
+	m.IssuesTotal = page.Total
+	m.followCycleDefault()
+	if m.BrowseOnly && m.SelectedIssue == nil && m.AddSubtaskSelected == "" {
+		m.selectFirstRow()
+	}
+	return m.restoreIssueTree()
+}
+
+// replaceLoadedIssues swaps in a longer list for the issues listed so far,
+// keeping those already listed as they were left: expanded with their
+// subtasks, and selected
+func (m *model) replaceLoadedIssues(issues []linear.Issue) {
+	listed := make(map[string]linear.Issue, len(m.LinearIssues))
+	for _, issue := range m.LinearIssues {
+		listed[issue.ID] = issue
+	}
+	for i := range issues {
+		if before, ok := listed[issues[i].ID]; ok {
+			issues[i].Expanded = before.Expanded
+			issues[i].Children = before.Children
+		}
+	}
+	m.LinearIssues = issues
+
+	if m.SelectedIssue == nil {
+		return
+	}
+	if selected := m.findIssueByID(m.SelectedIssue.ID); selected != nil {
+		m.SelectedIssue = selected
+	} else {
+		m.selectInput()
+	}
+}
+
+// issuesLoadProgress says in the footer how many issues have loaded while
+// they load a page at a time
+func (m model) issuesLoadProgress() string {
+	if m.IssuesTotal > 0 {
+		return fmt.Sprintf("%s loaded %d/%d issues", m.Spinner.View(), m.IssuesLoadedSoFar, m.IssuesTotal)
+	}
+	return fmt.Sprintf("%s loaded %d issues…", m.Spinner.View(), m.IssuesLoadedSoFar)
+}
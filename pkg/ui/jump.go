@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// jumpToRow selects the row at index, or the nearest row there is, and
+// scrolls the tree to show it
+func (m *model) jumpToRow(index int) {
+	rows := m.visibleWorkQueueRows()
+	if len(rows) == 0 {
+		return
+	}
+	m.selectRow(rows[max(0, min(index, len(rows)-1))])
+	m.scrollToSelection()
+}
+
+// scrollHalfPage moves the selection and the tree under it half a screen
+// down, or up when direction is negative. From the input it moves half a
+// screen into the tree without scrolling it, and it stops at the first and
+// last rows rather than leaving them
+func (m *model) scrollHalfPage(direction int) {
+	rows := m.visibleWorkQueueRows()
+	step := m.listHeight() / 2
+	if step == 0 {
+		// Without a window size every row is shown, so half the rows is half a page
+		step = len(rows) / 2
+	}
+	step = max(1, step)
+	current := m.selectedRowIndex(rows)
+	if current >= 0 {
+		m.ListOffset = max(0, m.ListOffset+direction*step)
+	}
+	m.jumpToRow(current + direction*step)
+}
+
+// quickSelectRow is the issue row msg picks out among those shown, counting
+// from the top of the tree as it's scrolled: the first QuickSelect key picks
+// the first issue, the second the second and so on. It's nil when there
+// aren't that many
+func (m *model) quickSelectRow(msg tea.KeyMsg) *workQueueRow {
+	n := slices.Index(m.Keys.QuickSelect.Keys(), msg.String())
+	if n < 0 {
+		return nil
+	}
+	rows := m.visibleWorkQueueRows()
+	start, end := m.listWindow(rows)
+	for i := start; i < end; i++ {
+		if rows[i].Kind != workQueueRowIssue {
+			continue
+		}
+		if n == 0 {
+			return &rows[i]
+		}
+		n--
+	}
+	return nil
+}
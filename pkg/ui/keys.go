@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyMap holds the work queue's bindings; each can be remapped with the
+// keybindings config section using the action names in keyActions
+type keyMap struct {
+	Up           key.Binding
+	Down         key.Binding
+	Expand       key.Binding
+	Collapse     key.Binding
+	Top          key.Binding
+	Bottom       key.Binding
+	HalfPageDown key.Binding
+	HalfPageUp   key.Binding
+	QuickSelect  key.Binding
+	Select       key.Binding
+	Search       key.Binding
+	ToggleMode   key.Binding
+	ToggleAll    key.Binding
+	Status       key.Binding
+	Unassign     key.Binding
+	Done         key.Binding
+	Undo         key.Binding
+	Rename       key.Binding
+	Note         key.Binding
+	CheckoutPR   key.Binding
+	PruneMerged  key.Binding
+	SwitchRepo   key.Binding
+	Refresh      key.Binding
+	Board        key.Binding
+	Sort         key.Binding
+	Label        key.Binding
+	Cycle        key.Binding
+	PickCycle    key.Binding
+	OpenIssue    key.Binding
+	CopyIssue    key.Binding
+	Preview      key.Binding
+	NextUp       key.Binding
+	Help         key.Binding
+	Quit         key.Binding
+}
+
+// keyAction names a remappable binding in the keybindings config section
+type keyAction struct {
+	name        string
+	description string
+	binding     func(k *keyMap) *key.Binding
+	keys        []string // default keys
+}
+
+// keyActions lists every remappable action in the order the help overlay shows them
+var keyActions = []keyAction{
+	{"up", "move up", func(k *keyMap) *key.Binding { return &k.Up }, []string{"up", "k"}},
+	{"down", "move down", func(k *keyMap) *key.Binding { return &k.Down }, []string{"down", "j"}},
+	{"expand", "expand issue / add subtask", func(k *keyMap) *key.Binding { return &k.Expand }, []string{"right", "l"}},
+	{"collapse", "collapse issue", func(k *keyMap) *key.Binding { return &k.Collapse }, []string{"left", "h"}},
+	{"top", "jump to the first row", func(k *keyMap) *key.Binding { return &k.Top }, []string{"home", "g g"}},
+	{"bottom", "jump to the last row", func(k *keyMap) *key.Binding { return &k.Bottom }, []string{"end", "G"}},
+	{"halfPageDown", "move down half a page", func(k *keyMap) *key.Binding { return &k.HalfPageDown }, []string{"ctrl+d"}},
+	{"halfPageUp", "move up half a page", func(k *keyMap) *key.Binding { return &k.HalfPageUp }, []string{"ctrl+u"}},
+	{"quickSelect", "select the nth issue shown", func(k *keyMap) *key.Binding { return &k.QuickSelect }, []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}},
+	{"select", "create or resume worktree", func(k *keyMap) *key.Binding { return &k.Select }, []string{"enter"}},
+	{"search", "fuzzy search issues", func(k *keyMap) *key.Binding { return &k.Search }, []string{"/"}},
+	{"toggleMode", "toggle worktree / branch only", func(k *keyMap) *key.Binding { return &k.ToggleMode }, []string{"tab"}},
+	{"toggleAll", "show all or active work items", func(k *keyMap) *key.Binding { return &k.ToggleAll }, []string{"a", "A"}},
+	{"status", "change issue status", func(k *keyMap) *key.Binding { return &k.Status }, []string{"s", "S"}},
+	{"unassign", "unassign issue", func(k *keyMap) *key.Binding { return &k.Unassign }, []string{"u", "U"}},
+	{"done", "mark issue done", func(k *keyMap) *key.Binding { return &k.Done }, []string{"d", "D"}},
+	{"undo", "undo unassign", func(k *keyMap) *key.Binding { return &k.Undo }, []string{"z", "Z"}},
+	{"rename", "rename worktree and branch", func(k *keyMap) *key.Binding { return &k.Rename }, []string{"n", "N"}},
+	{"note", "note on worktree", func(k *keyMap) *key.Binding { return &k.Note }, []string{"e", "E"}},
+	{"checkoutPR", "pull PR head and resume", func(k *keyMap) *key.Binding { return &k.CheckoutPR }, []string{"f", "F"}},
+	{"pruneMerged", "prune merged worktrees", func(k *keyMap) *key.Binding { return &k.PruneMerged }, []string{"p", "P"}},
+	{"switchRepo", "switch repository", func(k *keyMap) *key.Binding { return &k.SwitchRepo }, []string{"R", "ctrl+p"}},
+	{"refresh", "refresh issues", func(k *keyMap) *key.Binding { return &k.Refresh }, []string{"r", "ctrl+r"}},
+	{"board", "toggle board view", func(k *keyMap) *key.Binding { return &k.Board }, []string{"v", "V"}},
+	{"sort", "cycle issue sort order", func(k *keyMap) *key.Binding { return &k.Sort }, []string{"o", "O"}},
+	{"label", "filter issues by label", func(k *keyMap) *key.Binding { return &k.Label }, []string{"L"}},
+	{"cycle", "show the current cycle or all", func(k *keyMap) *key.Binding { return &k.Cycle }, []string{"t", "T"}},
+	{"pickCycle", "filter issues by cycle", func(k *keyMap) *key.Binding { return &k.PickCycle }, []string{"i", "I"}},
+	{"openIssue", "open issue in browser", func(k *keyMap) *key.Binding { return &k.OpenIssue }, []string{"b", "B"}},
+	{"copyIssue", "copy issue identifier", func(k *keyMap) *key.Binding { return &k.CopyIssue }, []string{"c", "C"}},
+	{"preview", "preview issue description", func(k *keyMap) *key.Binding { return &k.Preview }, []string{" "}},
+	{"nextUp", "select the suggested issue", func(k *keyMap) *key.Binding { return &k.NextUp }, []string{"x", "X"}},
+	{"help", "toggle this help", func(k *keyMap) *key.Binding { return &k.Help }, []string{"?"}},
+	{"quit", "quit, or leave search", func(k *keyMap) *key.Binding { return &k.Quit }, []string{"ctrl+c", "esc"}},
+}
+
+// keyDisplayNames shortens key names in the footer and help overlay
+var keyDisplayNames = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+	" ":     "space",
+}
+
+// newKeyMap builds the default bindings with any configured overrides applied
+func newKeyMap(overrides map[string][]string) (keyMap, error) {
+	var k keyMap
+	valid := make(map[string]bool, len(keyActions))
+	for _, action := range keyActions {
+		valid[action.name] = true
+		keys := action.keys
+		if remapped, ok := overrides[action.name]; ok && len(remapped) > 0 {
+			keys = remapped
+		}
+		*action.binding(&k) = key.NewBinding(key.WithKeys(keys...), key.WithHelp(displayKeys(keys), action.description))
+	}
+
+	var unknown []string
+	for name := range overrides {
+		if !valid[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		names := make([]string, len(keyActions))
+		for i, action := range keyActions {
+			names[i] = action.name
+		}
+		return keyMap{}, fmt.Errorf("unknown keybindings actions %v (valid actions: %s)", unknown, strings.Join(names, ", "))
+	}
+	return k, nil
+}
+
+// displayKeys renders keys for help text, listing letters only once whatever
+// their case, a sequence such as "g g" as gg, and a run of digits as 1-9
+func displayKeys(keys []string) string {
+	if digitRun(keys) {
+		return keys[0] + "-" + keys[len(keys)-1]
+	}
+	var shown []string
+	seen := make(map[string]bool)
+	for _, k := range keys {
+		if seen[strings.ToLower(k)] {
+			continue
+		}
+		seen[strings.ToLower(k)] = true
+		if name, ok := keyDisplayNames[k]; ok {
+			k = name
+		} else if isSequence(k) {
+			k = strings.ReplaceAll(k, " ", "")
+		}
+		shown = append(shown, k)
+	}
+	return strings.Join(shown, "/")
+}
+
+// digitRun reports whether keys are three or more digits counting up by one
+func digitRun(keys []string) bool {
+	if len(keys) < 3 {
+		return false
+	}
+	for i, k := range keys {
+		if len(k) != 1 || k[0] < '0' || k[0] > '9' || (i > 0 && k[0] != keys[i-1][0]+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSequence reports whether k is keys pressed one after another, such as
+// "g g", rather than a single key
+func isSequence(k string) bool {
+	return len(k) > 1 && strings.Contains(k, " ")
+}
+
+// startsSequence reports whether first is the first key of a sequence bound
+// to any action
+func (k keyMap) startsSequence(first string) bool {
+	for _, action := range keyActions {
+		for _, bound := range action.binding(&k).Keys() {
+			if isSequence(bound) && strings.HasPrefix(bound, first+" ") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bindsSequence reports whether sequence is bound to any action
+func (k keyMap) bindsSequence(sequence string) bool {
+	for _, action := range keyActions {
+		if slices.Contains(action.binding(&k).Keys(), sequence) {
+			return true
+		}
+	}
+	return false
+}
+
+// keySequence waits on the first key of a sequence such as "g g" while the
+// tree has focus, reporting true so nothing else acts on it. The key after it
+// comes back as the whole sequence when that's bound, or as itself when not
+func (m *model) keySequence(msg tea.KeyMsg) (tea.KeyMsg, bool) {
+	first := m.PendingKey
+	m.PendingKey = ""
+	if m.InputMode || m.Submitted || m.SearchMode {
+		return msg, false
+	}
+	if first == "" {
+		if m.Keys.startsSequence(msg.String()) {
+			m.PendingKey = msg.String()
+			return msg, true
+		}
+		return msg, false
+	}
+	if sequence := first + " " + msg.String(); m.Keys.bindsSequence(sequence) {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(sequence)}, false
+	}
+	return msg, false
+}
+
+// keyMatches reports whether msg triggers binding, ignoring printable keys
+// while the user is typing so remapped letters still reach the input
+func (m model) keyMatches(msg tea.KeyMsg, binding key.Binding) bool {
+	if (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace) && m.isTyping() {
+		return false
+	}
+	return key.Matches(msg, binding)
+}
+
+func (m model) isTyping() bool {
+	return m.SearchMode || (m.InputMode && m.TextInput.Value() != "")
+}
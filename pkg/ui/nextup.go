@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"sprout/pkg/issues"
+	"sprout/pkg/linear"
+)
+
+// nextUp is the issue the header row suggests picking up next: the best
+// ranked of the issues in the work queue and their loaded subtasks
+func (m *model) nextUp() *issues.Suggestion {
+	if m.LinearClient == nil || m.BrowseOnly || m.SearchMode || m.LinearLoading {
+		return nil
+	}
+	var roots []linear.Issue
+	for _, row := range m.buildWorkQueueRows() {
+		if row.Kind == workQueueRowIssue && row.Issue != nil && row.Issue.Depth == 0 {
+			roots = append(roots, *row.Issue)
+		}
+	}
+	return issues.NextUp(roots, m.now())
+}
+
+// selectNextUp moves the selection to the suggested issue, expanding the
+// issues above it, so Enter starts work on it
+func (m *model) selectNextUp() {
+	suggestion := m.nextUp()
+	if suggestion == nil {
+		return
+	}
+	for _, id := range m.issueAncestors(suggestion.Issue.ID) {
+		m.updateIssueExpansion(id, true)
+	}
+	for _, row := range m.buildWorkQueueRows() {
+		if row.Kind == workQueueRowIssue && row.Issue != nil && row.Issue.ID == suggestion.Issue.ID {
+			m.selectRow(row)
+			return
+		}
+	}
+}
+
+// issueAncestors lists the IDs of the issues id is nested under, outermost
+// first
+func (m *model) issueAncestors(id string) []string {
+	var find func(issues []linear.Issue, path []string) []string
+	find = func(issues []linear.Issue, path []string) []string {
+		for _, issue := range issues {
+			if issue.ID == id {
+				return path
+			}
+			if found := find(issue.Children, append(path, issue.ID)); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(m.LinearIssues, []string{})
+}
+
+// renderNextUp is the header row naming the suggested issue, or "" when
+// there's nothing to suggest
+func (m *model) renderNextUp() string {
+	suggestion := m.nextUp()
+	if suggestion == nil {
+		return ""
+	}
+	return helpStyle.Render("Suggested: ") + titleStyle.Render(suggestion.Issue.Identifier) +
+		helpStyle.Render(" — "+suggestion.Reason+"  ["+m.Keys.NextUp.Help().Key+" select]")
+}
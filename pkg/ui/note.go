@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type worktreeNotedMsg struct {
+	branch string
+	note   string
+}
+
+type worktreeNoteErrorMsg struct {
+	err error
+}
+
+// openNote starts editing the note on the worktree checked out on branch,
+// beginning from the note it has now
+func (m *model) openNote(branch string) tea.Cmd {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "what it's waiting on, say"
+	input.CharLimit = 200
+	input.Width = m.TextInput.Width
+	input.TextStyle = titleStyle
+	input.PlaceholderStyle = helpStyle
+	input.CursorStyle = cursorStyle
+	for _, wt := range m.Worktrees {
+		if wt.Branch == branch {
+			input.SetValue(wt.Note)
+		}
+	}
+	input.CursorEnd()
+
+	m.NoteMode = true
+	m.NoteBranch = branch
+	m.NoteInput = input
+	return m.NoteInput.Focus()
+}
+
+func (m *model) closeNote() {
+	m.NoteMode = false
+	m.NoteBranch = ""
+	m.NoteInput.Blur()
+}
+
+// updateNote handles keys while a note is being typed
+func (m model) updateNote(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.Cancelled = true
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.closeNote()
+		return m, nil
+	case tea.KeyEnter:
+		branch := m.NoteBranch
+		note := strings.TrimSpace(m.NoteInput.Value())
+		m.closeNote()
+		return m, m.setNote(branch, note)
+	}
+
+	var cmd tea.Cmd
+	m.NoteInput, cmd = m.NoteInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) setNote(branch, note string) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.WorktreeManager.SetNote(branch, note); err != nil {
+			return worktreeNoteErrorMsg{err: err}
+		}
+		return worktreeNotedMsg{branch: branch, note: note}
+	}
+}
+
+// finishNote shows the new note on the worktree's row straight away
+func (m *model) finishNote(msg worktreeNotedMsg) {
+	m.FooterError = ""
+	for i := range m.Worktrees {
+		if m.Worktrees[i].Branch == msg.branch {
+			m.Worktrees[i].Note = msg.note
+		}
+	}
+}
+
+func (m model) renderNoteView() string {
+	s := strings.Builder{}
+	s.WriteString(headerStyle.Render("🌱 sprout"))
+	s.WriteString("\n\n")
+	s.WriteString(titleStyle.Render("Note on " + m.NoteBranch + ":"))
+	s.WriteString("\n")
+	s.WriteString(m.NoteInput.View())
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("Shown beside the worktree here and in sprout list; leave it empty to remove it."))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("[enter save] [esc back]"))
+	return s.String()
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"sprout/pkg/git"
+)
+
+type prCheckedOutMsg struct {
+	checkout git.PRCheckout
+}
+
+type prCheckoutErrorMsg struct {
+	err error
+}
+
+// prCheckoutTarget is what the PR checkout action looks a PR up by: the
+// selected worktree's branch, or the selected issue, or "" when neither is
+// selected
+func (m *model) prCheckoutTarget() string {
+	if m.WorktreeManager == nil || m.BrowseOnly || m.Submitted {
+		return ""
+	}
+	if branch := m.renameTarget(); branch != "" {
+		return branch
+	}
+	if m.SelectedIssue != nil {
+		return m.SelectedIssue.Identifier
+	}
+	return ""
+}
+
+// checkoutPR finds the PR for ref and pulls its latest head into its
+// worktree, making one if it was pruned. What it warns about, such as a
+// worktree it couldn't update, is kept for the result as when creating one
+func (m model) checkoutPR(ref string) tea.Cmd {
+	return func() tea.Msg {
+		ch := make(chan tea.Msg, 16)
+		go func() {
+			checkout, err := m.WorktreeManager.CheckoutPR(ref, git.CreateOptions{Progress: teaPresenter{operation: creatingWorktree, ch: ch}})
+			if err != nil {
+				ch <- prCheckoutErrorMsg{err: err}
+			} else {
+				ch <- prCheckedOutMsg{checkout: checkout}
+			}
+			close(ch)
+		}()
+		return worktreeCreateStartedMsg{ch: ch}
+	}
+}
+
+// finishPRCheckout resumes the PR's worktree as if it had been selected,
+// passing on any warnings
+func (m model) finishPRCheckout(msg prCheckedOutMsg) (tea.Model, tea.Cmd) {
+	next, cmd := m.resumeWorktree(msg.checkout.WorktreePath, msg.checkout.PullRequest.HeadBranch)
+	resumed := next.(model)
+	resumed.Result = m.withCreationWarnings(fmt.Sprintf("PR #%d checked out at: %s", msg.checkout.PullRequest.Number, msg.checkout.WorktreePath))
+	return resumed, cmd
+}
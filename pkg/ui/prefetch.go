@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"context"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"sprout/pkg/linear"
+)
+
+// prefetchParallel is how many requests for subtasks run at once in the
+// background, few enough to leave Linear's rate limit for what's asked for
+const prefetchParallel = 4
+
+// prefetchBatch is how many issues' subtasks each of those requests asks for
+const prefetchBatch = 25
+
+// childrenPrefetchStartedMsg is the channel the background fetch of subtasks
+// reports on
+type childrenPrefetchStartedMsg struct {
+	ch <-chan tea.Msg
+}
+
+// childrenPrefetchedMsg is a batch of issues' subtasks, by issue ID, fetched
+// before they were asked for
+type childrenPrefetchedMsg struct {
+	children map[string][]linear.Issue
+	err      error
+	ch       <-chan tea.Msg
+}
+
+// prefetchChildren fetches the subtasks of the loaded issues that have some
+// in the background, so expanding them doesn't wait on Linear. Expanding an
+// issue before its subtasks arrive fetches them as it always has. Any
+// prefetch already under way is stopped
+func (m *model) prefetchChildren() tea.Cmd {
+	m.stopPrefetch()
+	if m.LinearClient == nil {
+		return nil
+	}
+	var parentIDs []string
+	var walk func(issues []linear.Issue)
+	walk = func(issues []linear.Issue) {
+		for _, issue := range issues {
+			if issue.HasChildren && len(issue.Children) == 0 {
+				parentIDs = append(parentIDs, issue.ID)
+			}
+			walk(issue.Children)
+		}
+	}
+	walk(m.LinearIssues)
+	if len(parentIDs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.PrefetchStop = cancel
+	stop := ctx.Done()
+	client := m.LinearClient
+	var batches [][]string
+	for start := 0; start < len(parentIDs); start += prefetchBatch {
+		batches = append(batches, parentIDs[start:min(start+prefetchBatch, len(parentIDs))])
+	}
+	return func() tea.Msg {
+		ch := make(chan tea.Msg)
+		jobs := make(chan []string)
+		var wg sync.WaitGroup
+		for range min(prefetchParallel, len(batches)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for batch := range jobs {
+					children, err := client.GetChildrenOfIssues(batch)
+					select {
+					case ch <- childrenPrefetchedMsg{children: children, err: err, ch: ch}:
+					case <-stop:
+						return
+					}
+				}
+			}()
+		}
+		go func() {
+			defer func() {
+				close(jobs)
+				wg.Wait()
+				close(ch)
+			}()
+			for _, batch := range batches {
+				select {
+				case jobs <- batch:
+				case <-stop:
+					return
+				}
+			}
+		}()
+		return childrenPrefetchStartedMsg{ch: ch}
+	}
+}
+
+// stopPrefetch stops fetching subtasks in the background, as when the issues
+// they're for are about to be replaced
+func (m *model) stopPrefetch() {
+	if m.PrefetchStop != nil {
+		m.PrefetchStop()
+		m.PrefetchStop = nil
+	}
+}
+
+func waitForPrefetch(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// childrenPrefetched keeps subtasks fetched in the background, unless they've
+// been fetched since. A failure is left for expanding the issue to report
+func (m *model) childrenPrefetched(msg childrenPrefetchedMsg) tea.Cmd {
+	if msg.err == nil {
+		for parentID, children := range msg.children {
+			if parent := m.findIssueByID(parentID); parent != nil && len(parent.Children) == 0 {
+				m.storeIssueChildren(parentID, children)
+			}
+		}
+	}
+	return waitForPrefetch(msg.ch)
+}
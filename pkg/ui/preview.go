@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+	"sprout/pkg/linear"
+)
+
+// maxPreviewLines caps how much of a description the preview shows, so a long
+// one doesn't push the rest of the tree off screen
+const maxPreviewLines = 15
+
+// issuePreview is an issue's description rendered from markdown, shown under
+// its row in the tree
+type issuePreview struct {
+	IssueID string
+	Width   int // the terminal width it was wrapped to
+	Lines   []string
+}
+
+// togglePreview shows the selected issue's description under its row, or
+// hides it when it's already shown
+func (m *model) togglePreview() {
+	if m.Preview != nil && m.Preview.IssueID == m.SelectedIssue.ID {
+		m.Preview = nil
+		return
+	}
+	m.Preview = renderPreview(*m.SelectedIssue, m.Width, m.Keys.OpenIssue.Help().Key)
+}
+
+// rewrapPreview renders the preview again for a new terminal width
+func (m *model) rewrapPreview() {
+	if m.Preview == nil || m.Preview.Width == m.Width {
+		return
+	}
+	if issue := m.findIssueByID(m.Preview.IssueID); issue != nil {
+		m.Preview = renderPreview(*issue, m.Width, m.Keys.OpenIssue.Help().Key)
+	}
+}
+
+// previewLines is the preview to draw under row, if it's the row's issue
+func (m model) previewLines(row workQueueRow) []string {
+	if m.Preview == nil || row.Kind != workQueueRowIssue || row.Issue == nil || row.Issue.ID != m.Preview.IssueID {
+		return nil
+	}
+	return m.Preview.Lines
+}
+
+// previewHeight is how many lines of the tree the preview takes up
+func (m model) previewHeight() int {
+	if m.Preview == nil {
+		return 0
+	}
+	return len(m.Preview.Lines)
+}
+
+// renderPreview renders issue's description wrapped to width; openKey is
+// the key that opens the whole issue when the preview is cut short
+func renderPreview(issue linear.Issue, width int, openKey string) *issuePreview {
+	preview := &issuePreview{IssueID: issue.ID, Width: width}
+	description := strings.TrimSpace(issue.Description)
+	if description == "" {
+		preview.Lines = []string{helpStyle.Render("No description")}
+		return preview
+	}
+
+	if width <= 0 {
+		width = 80
+	}
+	style := styles.DarkStyle
+	if !lipgloss.HasDarkBackground() {
+		style = styles.LightStyle
+	}
+	// The UI's own colour profile rather than glamour's notty style, which
+	// keeps markdown's asterisks; the width leaves room for the tree's
+	// indentation on the left
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle(style),
+		glamour.WithColorProfile(lipgloss.ColorProfile()),
+		glamour.WithWordWrap(max(20, width-12)),
+	)
+	rendered := description
+	if err == nil {
+		if out, err := renderer.Render(description); err == nil {
+			rendered = out
+		}
+	}
+
+	if lipgloss.ColorProfile() == termenv.Ascii {
+		// glamour still bolds and underlines where there are no colours
+		rendered = ansi.Strip(rendered)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(rendered, "\n") {
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	// glamour pads the document with blank lines
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > maxPreviewLines {
+		lines = append(lines[:maxPreviewLines-1], helpStyle.Render("… "+openKey+" opens the rest in your browser"))
+	}
+	preview.Lines = lines
+	return preview
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// operation is which of the TUI's background operations presented something
+type operation int
+
+const (
+	loadingWorktrees operation = iota
+	creatingWorktree
+)
+
+// teaPresenter is the TUI's progress.Presenter. Operations run in a tea.Cmd's
+// goroutine, so rather than drawing anything it sends what they present to
+// the model as progressMsgs on ch, where the operation's result follows
+type teaPresenter struct {
+	operation operation
+	ch        chan tea.Msg
+}
+
+// progressMsg is something an operation running in the background presented
+type progressMsg struct {
+	operation operation
+	step      string // the step started or finished
+	finished  bool
+	err       error // why a finished step failed
+	warning   string
+	result    string
+	ch        <-chan tea.Msg // where the operation's next message comes from
+}
+
+func (p teaPresenter) StepStarted(step string) {
+	p.ch <- progressMsg{operation: p.operation, step: step, ch: p.ch}
+}
+
+func (p teaPresenter) StepFinished(step string, err error) {
+	p.ch <- progressMsg{operation: p.operation, step: step, finished: true, err: err, ch: p.ch}
+}
+
+func (p teaPresenter) Warning(message string) {
+	p.ch <- progressMsg{operation: p.operation, warning: message, ch: p.ch}
+}
+
+func (p teaPresenter) Result(message string) {
+	p.ch <- progressMsg{operation: p.operation, result: message, ch: p.ch}
+}
+
+// presentProgress shows what an operation presented: the step it's on in
+// its spinner line, and warnings once it's done. It then waits for the
+// operation's next message
+func (m *model) presentProgress(msg progressMsg) tea.Cmd {
+	switch msg.operation {
+	case loadingWorktrees:
+		if msg.step != "" && !msg.finished {
+			m.WorktreesLoadingStatus = msg.step
+		}
+	case creatingWorktree:
+		switch {
+		case msg.step != "" && !msg.finished:
+			m.CreatingStatus = msg.step
+		case msg.step != "":
+			m.CreatingStatus = ""
+		case msg.warning != "":
+			m.CreationWarnings = append(m.CreationWarnings, msg.warning)
+		}
+	}
+	return waitForProgress(msg.ch)
+}
+
+// creatingStatus is the spinner line while a worktree is created, naming
+// the setup step under way
+func (m model) creatingStatus() string {
+	if m.CreatingStatus == "" {
+		return "Creating worktree..."
+	}
+	return fmt.Sprintf("Creating worktree: %s...", m.CreatingStatus)
+}
+
+// withCreationWarnings adds what creating the worktree warned about to result
+func (m model) withCreationWarnings(result string) string {
+	for _, warning := range m.CreationWarnings {
+		result += "\nWarning: " + strings.TrimSpace(warning)
+	}
+	return result
+}
+
+// warningLog is the worktree manager's progress.Presenter under the TUI, so
+// calls without a presenter of their own, like RenameWorktree, don't warn on
+// stderr over the screen. Their warnings wait here for the footer
+type warningLog struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (l *warningLog) StepStarted(string)         {}
+func (l *warningLog) StepFinished(string, error) {}
+func (l *warningLog) Result(string)              {}
+
+func (l *warningLog) Warning(message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, strings.TrimSpace(message))
+}
+
+// take is what was warned about since it was last taken, each warning
+// following "; " so it can go after an error in the footer
+func (l *warningLog) take() string {
+	if l == nil {
+		return ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var taken string
+	for _, warning := range l.warnings {
+		taken += "; Warning: " + warning
+	}
+	l.warnings = nil
+	return taken
+}
+
+func waitForProgress(ch <-chan tea.Msg) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
@@ -0,0 +1,261 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"sprout/pkg/git"
+)
+
+// pruneRun follows the merged worktrees being pruned, from asking whether to
+// go ahead until a key is pressed after the last is done
+type pruneRun struct {
+	Worktrees    []git.Worktree
+	Outcomes     map[string]git.PruneOutcome // by branch, as each is done
+	KeepBranch   bool                        // leave each local branch in place, as prune --keep-branch does
+	DeleteRemote bool                        // delete each branch on origin too, as prune --delete-remote does
+	DryRun       bool                        // only report what would be pruned, as prune --dry-run does
+	Started      bool
+	Finished     bool
+	Summary      string // how it went, for the footer once the report is dismissed
+	Failed       bool
+	ch           <-chan tea.Msg
+}
+
+type pruneStartedMsg struct {
+	ch <-chan tea.Msg
+}
+
+// pruneProgressMsg is how one of the worktrees being pruned went
+type pruneProgressMsg struct {
+	outcome git.PruneOutcome
+}
+
+type pruneFinishedMsg struct {
+	result git.PruneResult
+	err    error
+}
+
+// mergedHistory remembers which worktrees' PRs had merged when the TUI last
+// listed them, so ones that have merged since can be pointed out
+type mergedHistory interface {
+	SeeMerged(branches, merged []string) []string
+}
+
+// seeMerged notes which worktrees have merged PRs, showing the banner
+// suggesting a prune when any have merged since the TUI last looked
+func (m *model) seeMerged() {
+	if m.MergedHistory == nil {
+		return
+	}
+	var branches, merged []string
+	for _, wt := range m.Worktrees {
+		if wt.Branch == "" {
+			continue
+		}
+		branches = append(branches, wt.Branch)
+		if git.ReadyToPrune(wt) {
+			merged = append(merged, wt.Branch)
+		}
+	}
+	if len(m.MergedHistory.SeeMerged(branches, merged)) > 0 {
+		m.MergedBanner = true
+	}
+}
+
+// renderMergedBanner suggests pruning once worktrees have merged since the
+// TUI last looked, for as long as any merged worktrees are left
+func (m model) renderMergedBanner() string {
+	if !m.MergedBanner {
+		return ""
+	}
+	count := 0
+	for _, wt := range m.Worktrees {
+		if git.ReadyToPrune(wt) {
+			count++
+		}
+	}
+	switch count {
+	case 0:
+		return ""
+	case 1:
+		return helpStyle.Render("1 worktree is merged — press " + m.Keys.PruneMerged.Help().Key + " to prune")
+	}
+	return helpStyle.Render(fmt.Sprintf("%d worktrees are merged — press %s to prune", count, m.Keys.PruneMerged.Help().Key))
+}
+
+// openPrune asks whether to prune the worktrees whose PRs have merged
+func (m *model) openPrune() {
+	var merged []git.Worktree
+	for _, wt := range m.Worktrees {
+		if git.ReadyToPrune(wt) {
+			merged = append(merged, wt)
+		}
+	}
+	if len(merged) == 0 {
+		m.FooterNotice = "No merged worktrees to prune"
+		return
+	}
+	m.Prune = &pruneRun{Worktrees: merged, Outcomes: map[string]git.PruneOutcome{}}
+}
+
+// updatePrune handles keys while the prune is asked about, under way or
+// reporting how it went
+func (m model) updatePrune(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		m.Cancelled = true
+		return m, tea.Quit
+	}
+	switch {
+	case m.Prune.Finished:
+		if m.Prune.Failed {
+			m.FooterError = m.Prune.Summary
+		} else {
+			m.FooterNotice = m.Prune.Summary
+		}
+		m.Prune = nil
+	case m.Prune.Started:
+		// Nothing stops a prune part way through
+	case msg.String() == "y" || msg.String() == "Y" || msg.Type == tea.KeyEnter:
+		run := *m.Prune
+		run.Started = true
+		m.Prune = &run
+		return m, m.pruneMerged(run.Worktrees, git.PruneOptions{KeepBranch: run.KeepBranch, DeleteRemote: run.DeleteRemote, DryRun: run.DryRun})
+	case msg.String() == "n" || msg.String() == "N" || msg.Type == tea.KeyEsc:
+		m.Prune = nil
+	case msg.String() == "k":
+		run := *m.Prune
+		run.KeepBranch = !run.KeepBranch
+		m.Prune = &run
+	case msg.String() == "r":
+		run := *m.Prune
+		run.DeleteRemote = !run.DeleteRemote
+		m.Prune = &run
+	case msg.String() == "d":
+		run := *m.Prune
+		run.DryRun = !run.DryRun
+		m.Prune = &run
+	}
+	return m, nil
+}
+
+// pruneMerged prunes worktrees in the background with opts, sending how
+// each went as it's done and then the result
+func (m model) pruneMerged(worktrees []git.Worktree, opts git.PruneOptions) tea.Cmd {
+	return func() tea.Msg {
+		ch := make(chan tea.Msg, len(worktrees)+1)
+		opts.OnProgress = func(outcome git.PruneOutcome) {
+			ch <- pruneProgressMsg{outcome: outcome}
+		}
+		go func() {
+			result, err := m.WorktreeManager.PruneMergedWorktrees(worktrees, opts)
+			ch <- pruneFinishedMsg{result: result, err: err}
+			close(ch)
+		}()
+		return pruneStartedMsg{ch: ch}
+	}
+}
+
+func waitForPrune(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// prunedMsg takes in news of the prune under way
+func (m *model) prunedMsg(msg tea.Msg) tea.Cmd {
+	if m.Prune == nil {
+		return nil
+	}
+	run := *m.Prune
+	m.Prune = &run
+	switch msg := msg.(type) {
+	case pruneStartedMsg:
+		run.ch = msg.ch
+	case pruneProgressMsg:
+		outcomes := make(map[string]git.PruneOutcome, len(run.Outcomes)+1)
+		for branch, outcome := range run.Outcomes {
+			outcomes[branch] = outcome
+		}
+		outcomes[msg.outcome.Branch] = msg.outcome
+		run.Outcomes = outcomes
+	case pruneFinishedMsg:
+		run.Finished = true
+		run.Failed = msg.err != nil
+		pruned, total := msg.result.Count(git.PrunePruned), len(msg.result.Outcomes)
+		switch {
+		case run.DryRun && !run.Failed:
+			run.Summary = fmt.Sprintf("Would prune %d merged worktree(s); nothing was changed", msg.result.Count(git.PruneWouldPrune))
+			return waitForPrune(run.ch)
+		case run.Failed:
+			run.Summary = fmt.Sprintf("Pruned %d of %d merged worktree(s); %d failed", pruned, total, msg.result.Count(git.PruneFailed))
+		default:
+			run.Summary = fmt.Sprintf("Pruned %d merged worktree(s); sprout undo brings them back", pruned)
+		}
+		// The list shows what's left
+		m.WorktreesLoading = true
+		m.WorktreesLoadingStatus = "git worktree list --porcelain"
+		return tea.Batch(m.fetchWorktrees(), m.Spinner.Tick)
+	}
+	return waitForPrune(run.ch)
+}
+
+func (m model) renderPruneView() string {
+	run := m.Prune
+	s := strings.Builder{}
+	s.WriteString(headerStyle.Render("🌱 sprout"))
+	s.WriteString("\n\n")
+	switch {
+	case !run.Started:
+		s.WriteString(titleStyle.Render(fmt.Sprintf("Prune %d merged worktree(s)?", len(run.Worktrees))))
+	case !run.Finished:
+		s.WriteString(titleStyle.Render(fmt.Sprintf("%s Pruning merged worktrees (%d/%d)", m.Spinner.View(), len(run.Outcomes), len(run.Worktrees))))
+	case run.DryRun:
+		s.WriteString(titleStyle.Render("Merged worktrees that would be pruned"))
+	default:
+		s.WriteString(titleStyle.Render("Pruned merged worktrees"))
+	}
+	s.WriteString("\n")
+
+	for _, wt := range run.Worktrees {
+		outcome, done := run.Outcomes[wt.Branch]
+		switch {
+		case !run.Started:
+			s.WriteString(normalStyle.Render("  " + wt.Branch))
+		case !done:
+			s.WriteString(helpStyle.Render("… " + wt.Branch))
+		case outcome.Status == git.PruneFailed:
+			s.WriteString(errorStyle.Render("✗ " + wt.Branch + ": " + outcome.Reason))
+		case outcome.Status == git.PruneSkipped:
+			s.WriteString(helpStyle.Render("- " + wt.Branch + ": " + outcome.Reason))
+		case outcome.Status == git.PruneWouldPrune:
+			s.WriteString(normalStyle.Render("~ " + wt.Branch + ": would prune"))
+		default:
+			s.WriteString(normalStyle.Render("✓ " + wt.Branch))
+		}
+		s.WriteString("\n")
+	}
+
+	switch {
+	case !run.Started:
+		s.WriteString(helpStyle.Render(fmt.Sprintf("[k keep branches: %s] [r delete on origin: %s] [d dry run: %s]", onOff(run.KeepBranch), onOff(run.DeleteRemote), onOff(run.DryRun))))
+		s.WriteString("\n")
+		s.WriteString(helpStyle.Render("[y prune] [n back]"))
+	case run.Finished:
+		s.WriteString(helpStyle.Render("[any key back]"))
+	}
+	return s.String()
+}
+
+// onOff is how a prune option's toggle shows whether it's set
+func onOff(set bool) string {
+	if set {
+		return "on"
+	}
+	return "off"
+}
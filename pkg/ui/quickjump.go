@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"sprout/pkg/linear"
+)
+
+// typedIdentifierPattern is an issue identifier typed into the input, such as
+// SPR-123, which jumps to that issue rather than naming a branch
+var typedIdentifierPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*-[0-9]+$`)
+
+// quickJump is the issue an identifier typed into the input stands for. Enter
+// starts work on it just as if it had been picked from the tree
+type quickJump struct {
+	Identifier string
+	Issue      *linear.Issue // nil while it's looked up, or when there's no such issue
+	LookingUp  bool
+	Fetched    bool // looked up in Linear, as it isn't one of the issues listed
+	Err        error
+}
+
+// quickJumpLookedUpMsg is what looking up an identifier in Linear found
+type quickJumpLookedUpMsg struct {
+	identifier string
+	issue      *linear.Issue
+	err        error
+}
+
+// updateQuickJump follows the input as it's typed: an issue identifier
+// selects that issue in the tree, or looks it up when it isn't there
+func (m *model) updateQuickJump() tea.Cmd {
+	if m.QuickJump != nil {
+		if m.quickJumpIssue() != nil {
+			m.SelectedIssue = nil
+		}
+		m.QuickJump = nil
+	}
+
+	typed := strings.TrimSpace(m.TextInput.Value())
+	if !m.InputMode || m.SearchMode || m.BrowseOnly || !typedIdentifierPattern.MatchString(typed) {
+		return nil
+	}
+	identifier := strings.ToUpper(typed)
+	if issue := m.revealIssue(identifier); issue != nil {
+		m.QuickJump = &quickJump{Identifier: identifier, Issue: issue}
+		m.SelectedIssue = issue
+		m.scrollToSelection()
+		return nil
+	}
+	if m.LinearClient == nil {
+		return nil
+	}
+	m.QuickJump = &quickJump{Identifier: identifier, LookingUp: true}
+	return m.lookUpIssue(identifier)
+}
+
+func (m model) lookUpIssue(identifier string) tea.Cmd {
+	return func() tea.Msg {
+		issue, err := m.LinearClient.GetIssue(identifier)
+		return quickJumpLookedUpMsg{identifier: identifier, issue: issue, err: err}
+	}
+}
+
+// quickJumpLookedUp takes in what Linear found, unless something else has
+// been typed since
+func (m *model) quickJumpLookedUp(msg quickJumpLookedUpMsg) {
+	if m.QuickJump == nil || m.QuickJump.Identifier != msg.identifier {
+		return
+	}
+	jump := quickJump{Identifier: msg.identifier, Err: msg.err}
+	if msg.issue != nil {
+		// The tree may have loaded it while Linear was asked
+		if issue := m.revealIssue(msg.issue.Identifier); issue != nil {
+			jump.Issue = issue
+		} else {
+			jump.Issue, jump.Fetched = msg.issue, true
+		}
+		m.SelectedIssue = jump.Issue
+		m.scrollToSelection()
+	}
+	m.QuickJump = &jump
+}
+
+// quickJumpIssue is the issue the typed identifier stands for, while it's
+// still the one Enter would start work on
+func (m model) quickJumpIssue() *linear.Issue {
+	if m.QuickJump == nil || m.QuickJump.Issue == nil || m.SelectedIssue == nil || m.SelectedIssue.ID != m.QuickJump.Issue.ID {
+		return nil
+	}
+	return m.QuickJump.Issue
+}
+
+// revealIssue finds the listed issue with identifier, expanding the issues
+// above it so its row shows
+func (m *model) revealIssue(identifier string) *linear.Issue {
+	var find func(issues []linear.Issue, parents []string) *linear.Issue
+	find = func(issues []linear.Issue, parents []string) *linear.Issue {
+		for i := range issues {
+			if strings.EqualFold(issues[i].Identifier, identifier) {
+				for _, parentID := range parents {
+					m.updateIssueExpansion(parentID, true)
+				}
+				return &issues[i]
+			}
+			if found := find(issues[i].Children, append(parents, issues[i].ID)); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(m.LinearIssues, nil)
+}
+
+// renderQuickJump is the line under the input saying which issue the typed
+// identifier stands for
+func (m model) renderQuickJump() string {
+	jump := m.QuickJump
+	if jump == nil || !m.InputMode || m.SearchMode {
+		return ""
+	}
+	switch {
+	case jump.LookingUp:
+		return "\n" + helpStyle.Render("Looking up "+jump.Identifier+"…")
+	case jump.Err != nil:
+		return "\n" + errorStyle.Render("Couldn't look up "+jump.Identifier+": "+jump.Err.Error())
+	case jump.Issue == nil:
+		return "\n" + helpStyle.Render("No issue "+jump.Identifier+"; Enter creates a branch by that name")
+	case m.quickJumpIssue() == nil:
+		return ""
+	}
+	line := "→ " + jump.Issue.Identifier + " " + jump.Issue.Title
+	if jump.Fetched {
+		line += " (not in your issues)"
+	}
+	return "\n" + selectedStyle.Render(line)
+}
@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"sprout/pkg/issues"
+	"sprout/pkg/metadata"
+)
+
+// issueClockInterval is how often the footer's "last updated 2m ago" is brought
+// up to date, and auto-refresh checks whether it's due
+const issueClockInterval = 30 * time.Second
+
+// issueClockMsg ticks for as long as there's an issue tracker
+type issueClockMsg struct{}
+
+func (m model) tickIssueClock() tea.Cmd {
+	interval := issueClockInterval
+	if m.IssueRefresh > 0 && m.IssueRefresh < interval {
+		interval = m.IssueRefresh
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg { return issueClockMsg{} })
+}
+
+func (m model) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+// refreshIssues reloads the issue list, asking the issue tracker again
+// rather than reusing anything cached. Issues already listed stay until the
+// new ones arrive, which open with the same issues expanded and selected
+func (m *model) refreshIssues() tea.Cmd {
+	m.stopPrefetch()
+	issues.Invalidate(m.LinearClient)
+	m.LinearError = ""
+	if m.LinearIssues == nil {
+		m.LinearLoading = true
+		return tea.Batch(m.fetchLinearIssues(), m.Spinner.Tick)
+	}
+	m.IssuesRefreshing = true
+	return m.fetchLinearIssues()
+}
+
+// issueClock refreshes the issues once issueRefreshSeconds have passed since
+// they loaded, unless that would pull them from under something in progress
+func (m *model) issueClock() tea.Cmd {
+	next := m.tickIssueClock()
+	due := m.IssueRefresh > 0 && !m.IssuesLoadedAt.IsZero() && m.now().Sub(m.IssuesLoadedAt) >= m.IssueRefresh
+	if !due || m.LinearClient == nil || m.LinearLoading || m.IssuesRefreshing || m.busyWithIssues() {
+		return next
+	}
+	return tea.Batch(m.refreshIssues(), next)
+}
+
+// busyWithIssues is whether the user is in the middle of something that
+// replacing the issues would upset, such as typing or choosing from a picker
+func (m model) busyWithIssues() bool {
+	return m.Submitted || m.isTyping() || m.SubtaskInputMode || m.CreatingSubtask ||
+		m.StatusPickerMode || m.LabelPickerMode || m.CyclePickerMode || m.TemplatePickerMode ||
+		m.RepoPickerMode || m.BoardMode || m.RenameMode || m.NoteMode || m.SparseProfileMode ||
+		m.PromptCaptureMode || m.Prune != nil
+}
+
+// keepIssueTree opens refreshed issues as the ones they replaced were left:
+// the same issues expanded, their subtasks fetched afresh, and the same one
+// selected once it's shown again
+func (m *model) keepIssueTree(state metadata.IssueTreeState) {
+	if state.Selected == "" {
+		state.Selected = m.AddSubtaskSelected
+	}
+	m.RestoringTree = newTreeRestore(state)
+	if m.SelectedIssue != nil || m.AddSubtaskSelected != "" {
+		// The selection points into the issues just replaced
+		m.selectInput()
+	}
+}
+
+// issuesAge says in the footer how fresh the listed issues are, once they're
+// more than a minute old
+func (m model) issuesAge() string {
+	if m.LinearLoading && m.IssuesLoadedSoFar > 0 {
+		return m.issuesLoadProgress()
+	}
+	if m.IssuesRefreshing {
+		return "refreshing issues…"
+	}
+	if m.IssuesLoadedAt.IsZero() {
+		return ""
+	}
+	age := m.now().Sub(m.IssuesLoadedAt)
+	switch {
+	case age < time.Minute:
+		return ""
+	case age < time.Hour:
+		return fmt.Sprintf("last updated %dm ago", int(age.Minutes()))
+	default:
+		return fmt.Sprintf("last updated %dh ago", int(age.Hours()))
+	}
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"sprout/pkg/git"
+)
+
+type worktreeRenamedMsg struct {
+	oldBranch string
+	worktree  git.Worktree
+	warnings  string // what the rename warned about, for the footer
+}
+
+type worktreeRenameErrorMsg struct {
+	err      error
+	warnings string
+}
+
+// renameTarget is the branch of the selected worktree, which the rename
+// action applies to, or "" when no worktree is selected
+func (m *model) renameTarget() string {
+	row := m.selectedRow()
+	if m.WorktreeManager == nil || row == nil || row.Worktree == nil || row.Kind == workQueueRowAddSubtask {
+		return ""
+	}
+	return row.Worktree.Branch
+}
+
+// openRename starts editing the name of the worktree checked out on branch,
+// beginning from the name it has now
+func (m *model) openRename(branch string) tea.Cmd {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.CharLimit = 100
+	input.Width = m.TextInput.Width
+	input.TextStyle = titleStyle
+	input.PlaceholderStyle = helpStyle
+	input.CursorStyle = cursorStyle
+	input.SetValue(branch)
+	input.CursorEnd()
+
+	m.RenameMode = true
+	m.RenameBranch = branch
+	m.RenameInput = input
+	return m.RenameInput.Focus()
+}
+
+func (m *model) closeRename() {
+	m.RenameMode = false
+	m.RenameBranch = ""
+	m.RenameInput.Blur()
+}
+
+// updateRename handles keys while a new name is being typed
+func (m model) updateRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.Cancelled = true
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.closeRename()
+		return m, nil
+	case tea.KeyEnter:
+		oldBranch := m.RenameBranch
+		newBranch := strings.TrimSpace(m.RenameInput.Value())
+		m.closeRename()
+		if newBranch == "" || newBranch == oldBranch {
+			return m, nil
+		}
+		return m, m.renameWorktree(oldBranch, newBranch)
+	}
+
+	var cmd tea.Cmd
+	m.RenameInput, cmd = m.RenameInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) renameWorktree(oldBranch, newBranch string) tea.Cmd {
+	return func() tea.Msg {
+		renamed, err := m.WorktreeManager.RenameWorktree(oldBranch, newBranch)
+		warnings := m.ManagerWarnings.take()
+		if err != nil {
+			return worktreeRenameErrorMsg{err: err, warnings: warnings}
+		}
+		return worktreeRenamedMsg{oldBranch: oldBranch, worktree: renamed, warnings: warnings}
+	}
+}
+
+// finishRename keeps the renamed worktree selected and reloads the worktrees
+// so every row shows where things are now
+func (m *model) finishRename(msg worktreeRenamedMsg) tea.Cmd {
+	m.FooterError = strings.TrimPrefix(msg.warnings, "; ")
+	if m.SelectedWorktree == msg.oldBranch {
+		m.SelectedWorktree = msg.worktree.Branch
+		m.TextInput.Placeholder = msg.worktree.Branch
+	}
+	for i, branch := range m.RecentBranches {
+		if branch == msg.oldBranch {
+			m.RecentBranches[i] = msg.worktree.Branch
+		}
+	}
+	m.WorktreesLoading = true
+	m.WorktreesLoadingStatus = "git worktree list --porcelain"
+	return tea.Batch(m.fetchWorktrees(), m.Spinner.Tick)
+}
+
+func (m model) renderRenameView() string {
+	s := strings.Builder{}
+	s.WriteString(headerStyle.Render("🌱 sprout"))
+	s.WriteString("\n\n")
+	s.WriteString(titleStyle.Render("Rename " + m.RenameBranch + " to:"))
+	s.WriteString("\n")
+	s.WriteString(m.RenameInput.View())
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("The branch is renamed and its worktree moved to match."))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("[enter rename] [esc back]"))
+	return s.String()
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"sprout/pkg/git"
+	"sprout/pkg/linear"
+	"sprout/pkg/linear/lineartest"
+)
+
+// BenchmarkFirstRender times what stands between starting the TUI and
+// drawing it with issues and worktrees loaded: building the model, taking
+// in what was loaded and rendering the first view. Fetching is left out, as
+// it happens behind the first frame
+func BenchmarkFirstRender(b *testing.B) {
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	server := lineartest.NewServer(b)
+	for i := range 60 {
+		identifier := fmt.Sprintf("SPR-%d", i+1)
+		server.AddIssue(linear.Issue{
+			ID:         identifier,
+			Identifier: identifier,
+			Title:      fmt.Sprintf("Issue number %d with a title long enough to truncate", i+1),
+			State:      linear.State{ID: identifier + "-state", Name: "Todo", Type: "todo"},
+		}, "")
+	}
+	client := server.Client()
+	assigned, err := client.GetAssignedIssues()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	worktrees := make([]git.Worktree, 40)
+	for i := range worktrees {
+		branch := fmt.Sprintf("spr-%d-issue-number-%d", i+1, i+1)
+		worktrees[i] = git.Worktree{
+			Path:      "/worktrees/" + branch,
+			Branch:    branch,
+			Commit:    "abc1234",
+			PRStatus:  "Open",
+			UpdatedAt: time.Now().Add(-time.Duration(i) * time.Hour),
+		}
+	}
+	wm := &testWorktreeManager{worktrees: worktrees}
+
+	for b.Loop() {
+		m, err := NewTUIWithDependencies(wm, client)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, msg := range []tea.Msg{
+			tea.WindowSizeMsg{Width: 120, Height: 40},
+			linearIssuesLoadedMsg{assigned},
+			worktreesLoadedMsg{worktrees},
+		} {
+			updated, _ := m.Update(msg)
+			m = updated.(model)
+		}
+		if m.View() == "" {
+			b.Fatal("expected the first render to draw something")
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sprout/pkg/git"
+	"sprout/pkg/metadata"
+)
+
+// newLaunchRepoPicker is the TUI for sprout run outside any repository: the
+// repo switcher, offering the registered repositories inside the current
+// directory, or every registered one when none are. ok is false inside a
+// repository, or when none are registered
+func newLaunchRepoPicker() (model, bool) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return model{}, false
+	}
+	if _, err := git.FindRepoRoot(cwd); err == nil {
+		return model{}, false
+	}
+	store := metadata.NewStore("")
+	roots := reposToPick(store.KnownRepos(), cwd)
+	if len(roots) == 0 {
+		return model{}, false
+	}
+	m, err := NewTUIForRepoPicker(roots)
+	if err != nil {
+		return model{}, false
+	}
+	return m, true
+}
+
+// NewTUIForRepoPicker starts the TUI on a list of repositories to choose
+// from. Nothing is loaded until one is picked, which is then opened as the
+// repo switcher opens one; leaving the list quits
+func NewTUIForRepoPicker(roots []string) (model, error) {
+	m, err := NewTUIWithManager(nil, "")
+	if err != nil {
+		return model{}, err
+	}
+	m.SaveIssueSort = saveIssueSort
+	return m.pickingRepo(roots, openRepo), nil
+}
+
+// pickingRepo is m with no repository open, showing the repo picker for
+// roots, which open opens
+func (m model) pickingRepo(roots []string, open repoOpener) model {
+	m.WorktreeManager = nil
+	m.WorktreesLoading = false
+	m.RepoRoot = ""
+	m.RepoRoots = roots
+	m.OpenRepo = open
+	m.RepoPickerMode = true
+	m.RepoPickerIndex = 0
+	return m
+}
+
+// reposToPick is the registered repositories in known inside dir, or all of
+// them when none are
+func reposToPick(known []string, dir string) []string {
+	var inside []string
+	for _, root := range known {
+		if root == dir || strings.HasPrefix(root, dir+string(filepath.Separator)) {
+			inside = append(inside, root)
+		}
+	}
+	if len(inside) > 0 {
+		return inside
+	}
+	return known
+}
@@ -0,0 +1,262 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/text/unicode/norm"
+	"sprout/pkg/git"
+	"sprout/pkg/linear"
+)
+
+// searchHighlightStyle picks out the characters a search query matched
+var searchHighlightStyle = lipgloss.NewStyle().
+	Foreground(warningColor).
+	Bold(true).
+	Underline(true)
+
+// searchMatch is where a query's characters fall in the text it was matched
+// against, and how well they fit
+type searchMatch struct {
+	Positions []int // rune offsets into the text, in order
+	Score     int   // higher is a closer match
+}
+
+// matchSearch finds query's characters in text in order, ignoring case and
+// accents. A run of the query appearing whole is preferred to characters
+// scattered through the text; the score rewards adjacent characters and ones
+// starting a word, and counts gaps and a late start against the match
+func matchSearch(query, text string) (searchMatch, bool) {
+	q := foldRunes(query)
+	t := foldRunes(text)
+	if len(q) == 0 {
+		return searchMatch{}, true
+	}
+
+	positions := substringPositions(q, t)
+	if positions == nil {
+		positions = subsequencePositions(q, t)
+	}
+	if positions == nil {
+		return searchMatch{}, false
+	}
+
+	score := -min(positions[0], 20) / 4
+	for i, p := range positions {
+		score++
+		if isWordStart(t, p) {
+			score += 8
+		}
+		if i == 0 {
+			continue
+		}
+		if gap := p - positions[i-1] - 1; gap == 0 {
+			score += 5
+		} else {
+			score -= min(gap, 10)
+		}
+	}
+	return searchMatch{Positions: positions, Score: score}, true
+}
+
+// foldRunes lowercases s and drops accents one rune at a time, so offsets
+// into the result are offsets into s
+func foldRunes(s string) []rune {
+	folded := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r >= utf8.RuneSelf {
+			if base, _ := utf8.DecodeRuneInString(norm.NFD.String(string(r))); base != utf8.RuneError {
+				r = base
+			}
+		}
+		folded = append(folded, unicode.ToLower(r))
+	}
+	return folded
+}
+
+// substringPositions finds q whole in t, preferring an occurrence at the
+// start of a word, or returns nil
+func substringPositions(q, t []rune) []int {
+	first := -1
+	for start := 0; start+len(q) <= len(t); start++ {
+		if !runesEqual(t[start:start+len(q)], q) {
+			continue
+		}
+		if first < 0 {
+			first = start
+		}
+		if isWordStart(t, start) {
+			first = start
+			break
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+	positions := make([]int, len(q))
+	for i := range positions {
+		positions[i] = first + i
+	}
+	return positions
+}
+
+// subsequencePositions takes the earliest place for each of q's runes in t
+// after the one before, or returns nil when they don't all fit
+func subsequencePositions(q, t []rune) []int {
+	positions := make([]int, 0, len(q))
+	next := 0
+	for _, r := range q {
+		found := -1
+		for i := next; i < len(t); i++ {
+			if t[i] == r {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			return nil
+		}
+		positions = append(positions, found)
+		next = found + 1
+	}
+	return positions
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordStart(t []rune, i int) bool {
+	return i == 0 || !(unicode.IsLetter(t[i-1]) || unicode.IsDigit(t[i-1]))
+}
+
+// issueSearchText is what search matches an issue against
+func issueSearchText(issue linear.Issue) string {
+	return issue.Identifier + " " + issue.Title
+}
+
+// worktreeSearchText is what search matches a worktree with no issue against
+func worktreeSearchText(wt git.Worktree) string {
+	return wt.Name() + " " + wt.Path
+}
+
+// searchWorkQueueRows returns the rows matching query, best match first.
+// Subtasks that have been loaded are searched whether or not their parent is
+// expanded, and bring the issues above them along so it's clear where they
+// sit; a tree is ranked by the best match anywhere in it
+func (m *model) searchWorkQueueRows(query string) []workQueueRow {
+	active, closed, worktreesByIssue := m.workQueueRoots()
+	roots := active
+	if m.ShowAllWorkItems {
+		roots = append(roots, closed...)
+	}
+
+	type rankedTree struct {
+		rows  []workQueueRow
+		score int
+	}
+	var trees []rankedTree
+	for _, root := range roots {
+		rows, score, ok := m.searchRow(root, query, worktreesByIssue)
+		if ok {
+			trees = append(trees, rankedTree{rows: rows, score: score})
+		}
+	}
+	sort.SliceStable(trees, func(i, j int) bool {
+		return trees[i].score > trees[j].score
+	})
+
+	var rows []workQueueRow
+	for _, tree := range trees {
+		rows = append(rows, tree.rows...)
+	}
+	return rows
+}
+
+// searchRow matches row and, for an issue, its children against query. It
+// returns row followed by whichever of its descendants lead to a match, the
+// best score among them, and whether anything matched at all
+func (m *model) searchRow(row workQueueRow, query string, worktreesByIssue map[string]*git.Worktree) ([]workQueueRow, int, bool) {
+	var text string
+	switch {
+	case row.Issue != nil:
+		text = issueSearchText(*row.Issue)
+	case row.Worktree != nil:
+		text = worktreeSearchText(*row.Worktree)
+	}
+	match, ok := matchSearch(query, text)
+	if ok {
+		row.Match = match.Positions
+	}
+	best := match.Score
+
+	var descendants []workQueueRow
+	if row.Issue != nil {
+		for i := range row.Issue.Children {
+			child := m.issueRow(&row.Issue.Children[i], worktreesByIssue)
+			if child.Closed && len(m.Worktrees) > 0 && !m.ShowAllWorkItems {
+				continue
+			}
+			childRows, childScore, childOK := m.searchRow(child, query, worktreesByIssue)
+			if !childOK {
+				continue
+			}
+			descendants = append(descendants, childRows...)
+			if !ok || childScore > best {
+				best = childScore
+			}
+			ok = true
+		}
+	}
+	if !ok {
+		return nil, 0, false
+	}
+	return append([]workQueueRow{row}, descendants...), best, true
+}
+
+// highlightMatches renders text in style, with the runes at positions picked
+// out in searchHighlightStyle; positions are offsets into a longer text that
+// text starts offset runes into
+func highlightMatches(text string, positions []int, offset int, style lipgloss.Style) string {
+	if len(positions) == 0 {
+		return style.Render(text)
+	}
+	matched := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		matched[p-offset] = true
+	}
+
+	highlight := searchHighlightStyle.Inherit(style)
+	var out, run strings.Builder
+	runHighlighted := false
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if runHighlighted {
+			out.WriteString(highlight.Render(run.String()))
+		} else {
+			out.WriteString(style.Render(run.String()))
+		}
+		run.Reset()
+	}
+	i := 0
+	for _, r := range text {
+		if matched[i] != runHighlighted {
+			flush()
+			runHighlighted = matched[i]
+		}
+		run.WriteRune(r)
+		i++
+	}
+	flush()
+	return out.String()
+}
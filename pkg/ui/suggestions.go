@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// maxBranchSuggestions caps how many recent branches are offered under the input
+const maxBranchSuggestions = 5
+
+// branchHistory remembers the branches used from the TUI so they can be
+// suggested again, and the command run in each for sprout resume
+type branchHistory interface {
+	RecordBranchUse(branch string)
+	RecordLastCommand(branch, path string, command []string)
+}
+
+func (m model) recordBranchUse(branch string) {
+	if m.History != nil {
+		m.History.RecordBranchUse(branch)
+	}
+}
+
+// recordLastCommand remembers the command about to run in branch's worktree,
+// for sprout resume to run again
+func (m model) recordLastCommand(branch string, command []string) {
+	if m.History != nil {
+		m.History.RecordLastCommand(branch, m.WorktreePath, command)
+	}
+}
+
+// branchSuggestions returns the recent branches matching what has been typed
+// into the input, most frecent first
+func (m model) branchSuggestions() []string {
+	typed := strings.TrimSpace(m.TextInput.Value())
+	if !m.InputMode || m.SearchMode || typed == "" {
+		return nil
+	}
+
+	var suggestions []string
+	for _, branch := range m.RecentBranches {
+		if branch == typed || !fuzzy.MatchFold(typed, branch) {
+			continue
+		}
+		suggestions = append(suggestions, branch)
+		if len(suggestions) == maxBranchSuggestions {
+			break
+		}
+	}
+	return suggestions
+}
+
+// renderBranchSuggestions lists the suggestions on the lines under the input
+func (m model) renderBranchSuggestions() string {
+	s := strings.Builder{}
+	for i, branch := range m.branchSuggestions() {
+		s.WriteString("\n")
+		if i+1 == m.SuggestionIndex {
+			s.WriteString(selectedStyle.Render("  " + branch))
+		} else {
+			s.WriteString(helpStyle.Render("  " + branch))
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"sprout/pkg/linear"
+	"sprout/pkg/metadata"
+)
+
+// issueTreeStore remembers how a repository's issue tree was left between sessions
+type issueTreeStore interface {
+	IssueTree() metadata.IssueTreeState
+	SetIssueTree(state metadata.IssueTreeState)
+}
+
+// treeRestore is a saved issue tree being reapplied as issues and their children load
+type treeRestore struct {
+	expand   []string        // issues to expand once they've loaded
+	selected string          // issue to select once it's shown, dropped when a key is pressed first
+	fetching map[string]bool // parents whose children are being prefetched
+}
+
+func newTreeRestore(state metadata.IssueTreeState) *treeRestore {
+	return &treeRestore{expand: state.Expanded, selected: state.Selected, fetching: make(map[string]bool)}
+}
+
+// restoreIssueTree expands the saved issues that have loaded, prefetching the
+// children of any that haven't got them yet, and selects the saved issue once
+// it's shown. It runs again as each batch of children arrives, and is done
+// when there's nothing left to fetch.
+func (m *model) restoreIssueTree() tea.Cmd {
+	restore := m.RestoringTree
+	if restore == nil {
+		return nil
+	}
+
+	var fetch []string
+	var waiting []string
+	for _, id := range restore.expand {
+		issue := m.findIssueByID(id)
+		switch {
+		case issue == nil:
+			// Maybe a child of an issue whose children are still on their way
+			waiting = append(waiting, id)
+		case issue.HasChildren && len(issue.Children) == 0:
+			// childrenLoadedMsg expands it once they arrive
+			restore.fetching[id] = true
+			fetch = append(fetch, id)
+		default:
+			m.updateIssueExpansion(id, true)
+		}
+	}
+	restore.expand = waiting
+
+	if restore.selected != "" && (m.InputMode || m.BrowseOnly) && !m.SearchMode && m.TextInput.Value() == "" {
+		for _, row := range m.visibleWorkQueueRows() {
+			if row.Kind == workQueueRowIssue && row.Issue != nil && row.Issue.ID == restore.selected {
+				m.selectRow(row)
+				m.scrollToSelection()
+				restore.selected = ""
+				break
+			}
+		}
+	}
+
+	if len(restore.fetching) == 0 {
+		m.RestoringTree = nil
+	}
+	if len(fetch) == 0 {
+		return nil
+	}
+	return m.fetchChildrenOf(fetch)
+}
+
+// finishTreeFetch notes that a prefetch for the saved tree has come back and
+// carries on restoring with whatever it brought
+func (m *model) finishTreeFetch(parentID string) tea.Cmd {
+	if m.RestoringTree == nil || !m.RestoringTree.fetching[parentID] {
+		return nil
+	}
+	delete(m.RestoringTree.fetching, parentID)
+	return m.restoreIssueTree()
+}
+
+// issueTreeState captures which issues are expanded and which is selected,
+// counting ones a restore hasn't reached yet so quitting early loses nothing
+func (m model) issueTreeState() metadata.IssueTreeState {
+	var state metadata.IssueTreeState
+	var walk func(issues []linear.Issue)
+	walk = func(issues []linear.Issue) {
+		for _, issue := range issues {
+			if issue.Expanded {
+				state.Expanded = append(state.Expanded, issue.ID)
+			}
+			walk(issue.Children)
+		}
+	}
+	walk(m.LinearIssues)
+	if m.SelectedIssue != nil {
+		state.Selected = m.SelectedIssue.ID
+	}
+
+	if restore := m.RestoringTree; restore != nil {
+		state.Expanded = append(state.Expanded, restore.expand...)
+		for id := range restore.fetching {
+			state.Expanded = append(state.Expanded, id)
+		}
+		if state.Selected == "" && m.InputMode {
+			state.Selected = restore.selected
+		}
+	}
+	return state
+}
+
+// saveIssueTree remembers the tree for the next session, unless the issues
+// never loaded and there's nothing to go on
+func (m model) saveIssueTree() {
+	if m.TreeState == nil || m.LinearIssues == nil {
+		return
+	}
+	m.TreeState.SetIssueTree(m.issueTreeState())
+}
+
+// collapseIssueTree folds every issue back up
+func (m *model) collapseIssueTree() {
+	var collapse func(issues []linear.Issue)
+	collapse = func(issues []linear.Issue) {
+		for i := range issues {
+			issues[i].Expanded = false
+			collapse(issues[i].Children)
+		}
+	}
+	collapse(m.LinearIssues)
+}
@@ -1,13 +1,21 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -16,23 +24,34 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/tree"
-	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/muesli/termenv"
 	"sprout/pkg/config"
 	"sprout/pkg/git"
+	"sprout/pkg/github"
+	"sprout/pkg/issues"
 	"sprout/pkg/linear"
+	"sprout/pkg/metadata"
+	"sprout/pkg/problem"
+	"sprout/pkg/startup"
+	"sprout/pkg/stats"
 )
 
 type model struct {
 	TextInput              textinput.Model
 	PromptInput            textarea.Model
 	SubtaskInput           textinput.Model
+	SubtaskDescription     textarea.Model
+	SubtaskChecklist       textarea.Model
 	Spinner                spinner.Model
 	Submitted              bool
 	Creating               bool
+	CreatingStatus         string   // the setup step creating the worktree is on
+	CreationWarnings       []string // what creating the worktree warned about, for the result
 	Done                   bool
 	Success                bool
 	Cancelled              bool
 	ErrorMsg               string
+	Failure                problem.Problem // the error behind ErrorMsg, as the result screen presents it
 	Result                 string
 	WorktreePath           string
 	WorktreeManager        git.WorktreeManagerInterface
@@ -46,10 +65,10 @@ type model struct {
 	WorktreesLoading       bool
 	WorktreesLoadingStatus string
 	WorktreesError         string
-	WorktreeLoadCh         <-chan tea.Msg
 	ShowAllWorkItems       bool
 	SelectedWorktree       string
 	ResumeBranch           string
+	CreatedBranch          string // branch of the worktree just created
 	ResumeCommandArgs      []string
 	Resumed                bool
 	SelectedIssue          *linear.Issue  // nil for custom input mode
@@ -64,6 +83,7 @@ type model struct {
 	FilteredIssues         []linear.Issue // filtered list of issues based on search
 	Width                  int            // terminal width
 	Height                 int            // terminal height
+	ListOffset             int            // first tree row drawn when the tree is taller than the terminal
 	MaxIdentifierWidth     int            // maximum width of issue identifiers for alignment
 	MaxStatusWidth         int            // maximum width of issue statuses for alignment
 	CreationMode           creationMode   // user-selected creation mode
@@ -75,6 +95,114 @@ type model struct {
 	PromptSubmitted        bool
 	CreationFinished       bool
 	CapturedPrompt         string
+	SparseProfiles         map[string][]string     // named sparse-checkout profiles for this repo
+	RecentBranches         []string                // branches used before, most frecent first, suggested while typing
+	History                branchHistory           // records branches created or resumed so they can be suggested later
+	SuggestionIndex        int                     // 1-based highlighted suggestion, 0 while the input itself is selected
+	SparseProfileMode      bool                    // true while choosing a sparse profile for a new worktree
+	SparseProfileIndex     int                     // selected picker entry, 0 is a full checkout
+	PendingBranchName      string                  // branch waiting on a sparse profile or existing branch choice
+	ExistingPrompt         *git.ExistingBranch     // worktree or branch found for the branch being created, awaiting a choice
+	SuffixedBranch         string                  // the first free name-2, name-3 and so on the prompt offers instead
+	RepoConfig             *config.RepoConfig      // repo-local settings such as label to sparse path rules
+	InferredSparseDirs     []string                // directories inferred from the selected issue, if any
+	SubtaskFormExpanded    bool                    // true once tab has opened the description, estimate and priority fields
+	SubtaskField           subtaskField            // subtask form field receiving input
+	SubtaskEstimateIndex   int                     // index into subtaskEstimates
+	SubtaskPriority        int                     // Linear priority, 0 is no priority
+	SubtaskChecklistMode   bool                    // the subtask form takes a checklist, one subtask per line
+	Checklist              *checklistRun           // the checklist whose subtasks are being created
+	ChecklistReport        []string                // how each line of the last checklist went, shown until the next key
+	StatusPickerMode       bool                    // true while choosing a new workflow state for an issue
+	StatusPickerIssueID    string                  // issue whose state is being changed
+	StatusPickerIndex      int                     // selected entry in WorkflowStates
+	WorkflowStates         []linear.State          // states offered by the status picker
+	LinkedIssueStates      map[string]linear.State // states of tickets linked to worktrees, by identifier
+	RepoRoot               string                  // root of the repository whose worktrees are shown
+	RepoRoots              []string                // registered repositories offered by the repo switcher
+	RepoPickerMode         bool                    // true while choosing another repository
+	RepoPickerIndex        int                     // selected entry in RepoRoots
+	OpenRepo               repoOpener              // opens a repository picked in the repo switcher
+	Profile                *startup.Profile        // times starting up for --profile-startup; nil when not asked for
+	Keys                   keyMap                  // active bindings, defaults plus configured remaps
+	HelpMode               bool                    // true while the keybinding help is shown
+	BoardMode              bool                    // true while issues are shown as a board of state columns
+	BoardColumn            int                     // focused column on the board
+	BoardRow               int                     // focused card within the board column
+	IssueSort              string                  // order issues are listed in, one of config.IssueSortOrders
+	SaveIssueSort          issueSortSaver          // remembers the chosen order for the repo
+	LabelFilter            string                  // only issues with this label are listed, "" lists all
+	LabelPickerMode        bool                    // true while choosing a label to filter by
+	LabelPickerIndex       int                     // selected entry in labelFilterOptions
+	CycleFilter            issues.CycleFilter      // only issues in this Linear cycle are listed
+	CycleFilterUnset       bool                    // no cycle was chosen, so CycleFilter follows the issues loaded
+	CyclePickerMode        bool                    // true while choosing a cycle to filter by
+	CyclePickerIndex       int                     // selected entry in cycleFilterOptions
+	Templates              config.Templates        // worktree templates by branch prefix
+	TemplatePickerMode     bool                    // true while choosing a template for a new worktree
+	TemplatePickerIndex    int                     // selected entry in templateOptions, 0 is no template
+	ActiveTemplate         *config.Template        // template applied to the worktree being created
+	DefaultCommandFor      defaultCommandResolver  // picks the command for a new worktree over DefaultCommandArgs
+	TreeState              issueTreeStore          // remembers expanded issues and the selection between sessions
+	RestoringTree          *treeRestore            // saved tree still being reapplied as issues load
+	PrefetchStop           context.CancelFunc      // stops fetching subtasks in the background; safe to call twice
+	RenameMode             bool                    // true while typing a new name for a worktree
+	RenameBranch           string                  // branch of the worktree being renamed
+	RenameInput            textinput.Model         // the new name being typed
+	NoteMode               bool                    // true while typing a note on a worktree
+	NoteBranch             string                  // branch of the worktree the note is on
+	NoteInput              textinput.Model         // the note being typed
+	Prune                  *pruneRun               // the merged worktrees being pruned, from asking until the report is dismissed
+	MergedHistory          mergedHistory           // remembers which worktrees had merged, to point out merges since the last session
+	MergedBanner           bool                    // worktrees have merged since the last session, so pruning is suggested
+	BrowseOnly             bool                    // sprout issues: triage the issue tree without creating branches or worktrees
+	FooterNotice           string                  // brief confirmation shown in the footer, such as a copied identifier
+	ManagerWarnings        *warningLog             // what the worktree manager warned about outside an operation's own presenter
+	QuickJump              *quickJump              // the issue an identifier typed into the input stands for
+	Preview                *issuePreview           // the description shown under an issue's row, if any
+	OpenURL                func(url string) error  // shows an issue's link in the browser
+	CopyText               func(text string) error // puts text on the clipboard
+	IssuesRefreshing       bool                    // reloading issues behind the ones already listed
+	IssuesLoadedSoFar      int                     // issues loaded while the rest are still on their way, 0 before the first page
+	IssuesTotal            int                     // how many issues are on their way in all, 0 when that isn't known
+	IssuesLoadedAt         time.Time               // when the listed issues were loaded
+	IssueRefresh           time.Duration           // how often to reload issues unasked, 0 for never
+	Now                    func() time.Time        // the time, which tests hold still; time.Now when nil
+	SpinnerFrozen          bool                    // the spinner stays on its first frame, so renders can be compared
+	Plain                  bool                    // rendered without colour, whatever the terminal supports
+	PendingKey             string                  // first key of a sequence such as "g g", waiting for the next
+}
+
+// repoOpener opens the repository at root and returns its manager and display name
+type repoOpener func(root string) (git.WorktreeManagerInterface, string, error)
+
+// issueSortSaver records order as the issue order for the repository at repoRoot
+type issueSortSaver func(repoRoot, order string) error
+
+// defaultCommandResolver is the command to run in a new worktree of
+// branchName in repoRoot, and which setting it came from
+type defaultCommandResolver func(repoRoot, branchName string, template config.Template) ([]string, string)
+
+type subtaskField int
+
+const (
+	subtaskFieldTitle subtaskField = iota
+	subtaskFieldDescription
+	subtaskFieldEstimate
+	subtaskFieldPriority
+	subtaskFieldCount
+)
+
+// subtaskEstimates are the point values offered by the subtask form; 0 leaves the estimate unset
+var subtaskEstimates = []int{0, 1, 2, 3, 5, 8}
+
+// subtaskPriorities are Linear's priority labels indexed by priority value
+var subtaskPriorities = []string{"No priority", "Urgent", "High", "Medium", "Low"}
+
+// sparseOption is one entry in the sparse checkout picker
+type sparseOption struct {
+	Name        string
+	Directories []string
 }
 
 type unassignedIssueSnapshot struct {
@@ -135,6 +263,13 @@ var (
 	statusCancelledStyle = lipgloss.NewStyle().
 				Foreground(errorColor) // Red for cancelled
 
+	// Priority badge styles - only urgent and high stand out
+	priorityUrgentStyle = lipgloss.NewStyle().
+				Foreground(errorColor).
+				Bold(true)
+	priorityHighStyle = lipgloss.NewStyle().
+				Foreground(warningColor)
+
 	// Issue title style
 	titleStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("252"))
@@ -171,36 +306,80 @@ var (
 	helpStyle = lipgloss.NewStyle().
 			Foreground(secondaryColor).
 			Italic(true)
+
+	// Which Linear workspace an issue is from, when several are merged
+	workspaceStyle = lipgloss.NewStyle().
+			Foreground(secondaryColor)
 )
 
-func NewTUI() (model, error) {
+func NewTUI(opts ...Option) (model, error) {
+	return newTUI(nil, opts...)
+}
+
+// newTUI is NewTUI marking each phase of setting up on profile
+func newTUI(profile *startup.Profile, opts ...Option) (model, error) {
 	wm, err := git.NewWorktreeManager()
 	if err != nil {
 		return model{}, err
 	}
-	return NewTUIWithManager(wm)
+	profile.Mark("find repository")
+	m, err := NewTUIWithManager(wm, wm.RepoRoot(), opts...)
+	if err != nil {
+		return model{}, err
+	}
+	profile.Mark("connect issue tracker")
+	m.Profile = profile
+	store := metadata.NewStore(wm.RepoRoot())
+	store.RegisterRepo()
+	m.SparseProfiles = store.SparseProfiles()
+	m.RecentBranches = store.RecentBranches()
+	m.History = store
+	m.TreeState = store
+	m.MergedHistory = store
+	m.RestoringTree = newTreeRestore(store.IssueTree())
+	if repoConfig, err := config.LoadRepoConfig(wm.RepoRoot()); err == nil {
+		m.RepoConfig = repoConfig
+	}
+	m.RepoRoot = wm.RepoRoot()
+	m.RepoRoots = store.KnownRepos()
+	m.OpenRepo = openRepo
+	if cfg, err := config.Load(); err == nil {
+		m.IssueSort = issueSortFor(cfg, m.RepoRoot)
+	}
+	m.SaveIssueSort = saveIssueSort
+	profile.Mark("load metadata")
+	return m, nil
+}
+
+// openRepo opens a registered repository for the repo switcher
+func openRepo(root string) (git.WorktreeManagerInterface, string, error) {
+	wm, err := git.NewWorktreeManagerForRepo(root)
+	if err != nil {
+		return nil, "", err
+	}
+	return wm, wm.RepoName(), nil
 }
 
-func NewTUIWithManager(wm git.WorktreeManagerInterface) (model, error) {
-	// Load config to check for Linear API key
+func NewTUIWithManager(wm git.WorktreeManagerInterface, repoRoot string, opts ...Option) (model, error) {
+	// Load config to pick the issue provider
 	cfg, err := config.Load()
 	if err != nil {
 		cfg = config.DefaultConfig()
 	}
 
-	var linearClient linear.LinearClientInterface
-	if cfg.LinearAPIKey != "" {
-		linearClient = linear.NewClient(cfg.LinearAPIKey)
+	linearClient, err := issues.NewForRepo(cfg, repoRoot)
+	if err != nil {
+		return model{}, err
 	}
 
-	return NewTUIWithDependenciesAndConfig(wm, linearClient, cfg)
+	return NewTUIWithDependenciesAndConfig(wm, linearClient, cfg, opts...)
 }
 
-func NewTUIWithDependencies(wm git.WorktreeManagerInterface, linearClient linear.LinearClientInterface) (model, error) {
-	return NewTUIWithDependenciesAndConfig(wm, linearClient, config.DefaultConfig())
+func NewTUIWithDependencies(wm git.WorktreeManagerInterface, linearClient linear.LinearClientInterface, opts ...Option) (model, error) {
+	return NewTUIWithDependenciesAndConfig(wm, linearClient, config.DefaultConfig(), opts...)
 }
 
-func NewTUIWithDependenciesAndConfig(wm git.WorktreeManagerInterface, linearClient linear.LinearClientInterface, cfg *config.Config) (model, error) {
+func NewTUIWithDependenciesAndConfig(wm git.WorktreeManagerInterface, linearClient linear.LinearClientInterface, cfg *config.Config, opts ...Option) (model, error) {
 	if cfg == nil {
 		cfg = config.DefaultConfig()
 	}
@@ -208,6 +387,11 @@ func NewTUIWithDependenciesAndConfig(wm git.WorktreeManagerInterface, linearClie
 	defaultCommandArgs := cfg.GetDefaultCommand()
 	resumeCommandArgs := cfg.GetResumeCommand()
 
+	keys, err := newKeyMap(cfg.Keybindings)
+	if err != nil {
+		return model{}, err
+	}
+
 	// Get repository name for the prompt
 	repoName, err := git.GetRepositoryName()
 	if err != nil {
@@ -267,15 +451,30 @@ func NewTUIWithDependenciesAndConfig(wm git.WorktreeManagerInterface, linearClie
 	si.PlaceholderStyle = helpStyle
 	si.CursorStyle = cursorStyle
 
+	// Initialize subtask description, shown once the subtask form is expanded
+	sd := textarea.New()
+	sd.Placeholder = "optional description"
+	sd.ShowLineNumbers = false
+	sd.Prompt = "  "
+	sd.SetHeight(3)
+	sd.SetWidth(60)
+	sd.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "shift+enter", "ctrl+j"))
+	sd.FocusedStyle.Text = titleStyle
+	sd.FocusedStyle.Placeholder = helpStyle
+	sd.BlurredStyle.Text = normalStyle
+	sd.BlurredStyle.Placeholder = helpStyle
+
 	// Initialize spinner
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(warningColor)
 
-	return model{
+	m := model{
 		TextInput:              ti,
 		PromptInput:            pi,
 		SubtaskInput:           si,
+		SubtaskDescription:     sd,
+		SubtaskChecklist:       newChecklistInput(),
 		Spinner:                s,
 		Submitted:              false,
 		Creating:               false,
@@ -296,7 +495,6 @@ func NewTUIWithDependenciesAndConfig(wm git.WorktreeManagerInterface, linearClie
 		WorktreesLoading:       wm != nil,
 		WorktreesLoadingStatus: "git worktree list --porcelain",
 		WorktreesError:         "",
-		WorktreeLoadCh:         nil,
 		ShowAllWorkItems:       false,
 		SelectedWorktree:       "",
 		ResumeBranch:           "",
@@ -323,7 +521,24 @@ func NewTUIWithDependenciesAndConfig(wm git.WorktreeManagerInterface, linearClie
 		PromptSubmitted:        false,
 		CreationFinished:       false,
 		CapturedPrompt:         "",
-	}, nil
+		Keys:                   keys,
+		IssueSort:              config.IssueSortUpdated,
+		CycleFilter:            issues.ParseCycleFilter(cfg.IssueCycle),
+		CycleFilterUnset:       cfg.IssueCycle == "",
+		IssueRefresh:           cfg.IssueRefreshInterval(),
+		Templates:              cfg.Templates,
+		DefaultCommandFor:      cfg.DefaultCommandFor,
+		OpenURL:                linear.OpenBrowser,
+		CopyText:               copyToClipboard,
+		ManagerWarnings:        &warningLog{},
+	}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	if m.WorktreeManager != nil {
+		m.WorktreeManager.SetProgress(m.ManagerWarnings)
+	}
+	return m, nil
 }
 
 func (m model) Init() tea.Cmd {
@@ -334,7 +549,7 @@ func (m model) Init() tea.Cmd {
 
 	// Fetch Linear issues if client is available
 	if m.LinearClient != nil {
-		cmds = append(cmds, m.fetchLinearIssues())
+		cmds = append(cmds, m.fetchLinearIssues(), m.tickIssueClock())
 	}
 	if m.WorktreeManager != nil {
 		cmds = append(cmds, m.fetchWorktrees())
@@ -358,6 +573,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.Width = msg.Width
 		m.Height = msg.Height
+		m.rewrapPreview()
 
 		// Update text input width to use most of the terminal width
 		// Leave some space for the prompt and margins
@@ -383,6 +599,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.Done {
 			return m, tea.Quit
 		}
+		m.FooterNotice = ""
+		m.ChecklistReport = nil
+		if m.RestoringTree != nil {
+			// Whatever's selected now was chosen, so don't move it to the saved issue
+			m.RestoringTree.selected = ""
+		}
 
 		if m.PromptCaptureMode && m.ActiveCreationMode == creationModeWorktree {
 			switch msg.Type {
@@ -420,9 +642,237 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
-		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyEsc:
+		if m.SparseProfileMode {
+			switch {
+			case msg.Type == tea.KeyCtrlC:
+				m.Cancelled = true
+				return m, tea.Quit
+			case msg.Type == tea.KeyEsc:
+				m.SparseProfileMode = false
+				m.PendingBranchName = ""
+				m.InferredSparseDirs = nil
+				m.ActiveTemplate = nil
+				return m, nil
+			case key.Matches(msg, m.Keys.Up):
+				optionCount := len(m.sparseOptions())
+				m.SparseProfileIndex = (m.SparseProfileIndex + optionCount - 1) % optionCount
+				return m, nil
+			case key.Matches(msg, m.Keys.Down):
+				m.SparseProfileIndex = (m.SparseProfileIndex + 1) % len(m.sparseOptions())
+				return m, nil
+			case key.Matches(msg, m.Keys.Select):
+				directories := m.sparseOptions()[m.SparseProfileIndex].Directories
+				branchName := m.PendingBranchName
+				m.SparseProfileMode = false
+				m.PendingBranchName = ""
+				m.InferredSparseDirs = nil
+				return m.startCreation(branchName, directories)
+			}
+			return m, nil
+		}
+
+		if m.TemplatePickerMode {
+			options := m.templateOptions()
+			switch {
+			case msg.Type == tea.KeyCtrlC:
+				m.Cancelled = true
+				return m, tea.Quit
+			case msg.Type == tea.KeyEsc:
+				m.TemplatePickerMode = false
+				m.PendingBranchName = ""
+				m.InferredSparseDirs = nil
+				return m, nil
+			case key.Matches(msg, m.Keys.Up):
+				m.TemplatePickerIndex = (m.TemplatePickerIndex + len(options) - 1) % len(options)
+				return m, nil
+			case key.Matches(msg, m.Keys.Down):
+				m.TemplatePickerIndex = (m.TemplatePickerIndex + 1) % len(options)
+				return m, nil
+			case key.Matches(msg, m.Keys.Select):
+				branchName := m.PendingBranchName
+				m.TemplatePickerMode = false
+				m.PendingBranchName = ""
+				if prefix := options[m.TemplatePickerIndex]; prefix != "" {
+					branchName = m.applyTemplate(prefix, m.Templates[prefix], branchName)
+				}
+				return m.offerExistingOrCreate(branchName)
+			}
+			return m, nil
+		}
+
+		if m.ExistingPrompt != nil {
+			switch {
+			case msg.Type == tea.KeyCtrlC:
+				m.Cancelled = true
+				return m, tea.Quit
+			case msg.Type == tea.KeyEsc || msg.String() == "n" || msg.String() == "N":
+				m.closeExistingPrompt()
+				return m, nil
+			case key.Matches(msg, m.Keys.Select) || msg.String() == "y" || msg.String() == "Y":
+				existing := *m.ExistingPrompt
+				branchName := m.PendingBranchName
+				inferred := m.InferredSparseDirs
+				m.closeExistingPrompt()
+				if existing.WorktreePath != "" {
+					return m.resumeWorktree(existing.WorktreePath, existing.Branch)
+				}
+				// Only the branch exists, so creation checks it out rather than branching anew
+				m.InferredSparseDirs = inferred
+				return m.continueCreation(branchName)
+			case (msg.String() == "s" || msg.String() == "S") && m.SuffixedBranch != "":
+				branchName, inferred, template := m.SuffixedBranch, m.InferredSparseDirs, m.ActiveTemplate
+				m.closeExistingPrompt()
+				m.InferredSparseDirs, m.ActiveTemplate = inferred, template
+				return m.continueCreation(branchName)
+			}
+			return m, nil
+		}
+
+		if m.StatusPickerMode {
+			switch {
+			case msg.Type == tea.KeyCtrlC:
+				m.Cancelled = true
+				return m, tea.Quit
+			case msg.Type == tea.KeyEsc:
+				m.closeStatusPicker()
+				return m, nil
+			case key.Matches(msg, m.Keys.Up):
+				m.StatusPickerIndex = (m.StatusPickerIndex + len(m.WorkflowStates) - 1) % len(m.WorkflowStates)
+				return m, nil
+			case key.Matches(msg, m.Keys.Down):
+				m.StatusPickerIndex = (m.StatusPickerIndex + 1) % len(m.WorkflowStates)
+				return m, nil
+			case key.Matches(msg, m.Keys.Select):
+				issueID := m.StatusPickerIssueID
+				state := m.WorkflowStates[m.StatusPickerIndex]
+				m.closeStatusPicker()
+				return m, m.updateIssueState(issueID, state)
+			}
+			return m, nil
+		}
+
+		if m.RepoPickerMode {
+			switch {
+			case msg.Type == tea.KeyCtrlC:
+				m.Cancelled = true
+				return m, tea.Quit
+			case msg.Type == tea.KeyEsc && m.RepoRoot == "":
+				// Started outside any repository, there's nothing to go back to
+				m.Cancelled = true
+				return m, tea.Quit
+			case msg.Type == tea.KeyEsc:
+				m.RepoPickerMode = false
+				return m, nil
+			case key.Matches(msg, m.Keys.Up):
+				m.RepoPickerIndex = (m.RepoPickerIndex + len(m.RepoRoots) - 1) % len(m.RepoRoots)
+				return m, nil
+			case key.Matches(msg, m.Keys.Down):
+				m.RepoPickerIndex = (m.RepoPickerIndex + 1) % len(m.RepoRoots)
+				return m, nil
+			case key.Matches(msg, m.Keys.Select):
+				m.RepoPickerMode = false
+				return m.switchRepo(m.RepoRoots[m.RepoPickerIndex])
+			}
+			return m, nil
+		}
+
+		if m.LabelPickerMode {
+			options := m.labelFilterOptions()
+			switch {
+			case msg.Type == tea.KeyCtrlC:
+				m.Cancelled = true
+				return m, tea.Quit
+			case msg.Type == tea.KeyEsc:
+				m.LabelPickerMode = false
+				return m, nil
+			case key.Matches(msg, m.Keys.Up):
+				m.LabelPickerIndex = (m.LabelPickerIndex + len(options) - 1) % len(options)
+				return m, nil
+			case key.Matches(msg, m.Keys.Down):
+				m.LabelPickerIndex = (m.LabelPickerIndex + 1) % len(options)
+				return m, nil
+			case key.Matches(msg, m.Keys.Select):
+				m.LabelPickerMode = false
+				m.LabelFilter = options[m.LabelPickerIndex]
+				m.selectInput()
+				return m, nil
+			}
+			return m, nil
+		}
+
+		if m.CyclePickerMode {
+			options := m.cycleFilterOptions()
+			switch {
+			case msg.Type == tea.KeyCtrlC:
+				m.Cancelled = true
+				return m, tea.Quit
+			case msg.Type == tea.KeyEsc:
+				m.CyclePickerMode = false
+				return m, nil
+			case key.Matches(msg, m.Keys.Up):
+				m.CyclePickerIndex = (m.CyclePickerIndex + len(options) - 1) % len(options)
+				return m, nil
+			case key.Matches(msg, m.Keys.Down):
+				m.CyclePickerIndex = (m.CyclePickerIndex + 1) % len(options)
+				return m, nil
+			case key.Matches(msg, m.Keys.Select):
+				m.CyclePickerMode = false
+				m.CycleFilter = options[m.CyclePickerIndex]
+				m.CycleFilterUnset = false
+				m.selectInput()
+				return m, nil
+			}
+			return m, nil
+		}
+
+		if m.RenameMode {
+			return m.updateRename(msg)
+		}
+
+		if m.NoteMode {
+			return m.updateNote(msg)
+		}
+
+		if m.Prune != nil {
+			return m.updatePrune(msg)
+		}
+
+		if m.SubtaskInputMode {
+			return m.updateSubtaskForm(msg)
+		}
+
+		if m.HelpMode {
+			if msg.Type == tea.KeyCtrlC {
+				m.Cancelled = true
+				return m, tea.Quit
+			}
+			if msg.Type == tea.KeyEsc || key.Matches(msg, m.Keys.Help) {
+				m.HelpMode = false
+			}
+			return m, nil
+		}
+
+		var waiting bool
+		if msg, waiting = m.keySequence(msg); waiting {
+			return m, nil
+		}
+
+		if m.BoardMode && !m.Submitted {
+			var handled bool
+			if m, handled = m.updateBoard(msg); handled {
+				return m, nil
+			}
+		}
+
+		shortcutsActive := !m.Submitted && !m.SearchMode
+
+		switch {
+		case m.keyMatches(msg, m.Keys.Quit):
 			// Check if we're in search mode and exit that
+			if m.SearchMode && m.BrowseOnly {
+				m.leaveBrowseSearch()
+				return m, nil
+			}
 			if m.SearchMode {
 				m.SearchMode = false
 				m.SearchQuery = ""
@@ -435,48 +885,29 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			// Check if we're in subtask input mode and exit that
-			if m.SubtaskInputMode {
-				m.SubtaskInputMode = false
-				m.setSubtaskEntryMode(m.SubtaskParentID, false)
-				m.SubtaskParentID = ""
-				m.SubtaskInput.SetValue("")
-				m.SubtaskInput.Blur()
-				return m, nil
-			}
-
 			m.Cancelled = true
 			return m, tea.Quit
 
-		case tea.KeyEnter:
+		case m.keyMatches(msg, m.Keys.Select):
+			if m.BrowseOnly {
+				return m.browseSelect()
+			}
 			if !m.Submitted {
-				// Check if we're in subtask input mode
-				if m.SubtaskInputMode {
-					// Creating a new subtask
-					title := strings.TrimSpace(m.SubtaskInput.Value())
-					if title == "" {
-						return m, nil // Don't submit empty subtask title
-					}
-					m.CreatingSubtask = true
-					m.SubtaskInputMode = false
-					m.SubtaskInput.Blur()
-					return m, tea.Batch(m.createSubtaskInline(m.SubtaskParentID, title), m.Spinner.Tick)
+				if suggestions := m.branchSuggestions(); m.SuggestionIndex > 0 && m.SuggestionIndex <= len(suggestions) {
+					m.TextInput.SetValue(suggestions[m.SuggestionIndex-1])
+					m.SuggestionIndex = 0
+				}
+				if m.QuickJump != nil && m.QuickJump.LookingUp {
+					return m, nil // Enter waits to know which issue was typed
 				}
-
 				if selected := m.selectedRow(); selected != nil && selected.Worktree != nil && selected.Kind != workQueueRowAddSubtask {
-					m.Submitted = true
-					m.Creating = false
-					m.Done = true
-					m.Success = true
-					m.Resumed = true
-					m.WorktreePath = selected.Worktree.Path
-					m.ResumeBranch = selected.Worktree.Branch
-					m.Result = fmt.Sprintf("Worktree resumed at: %s", selected.Worktree.Path)
-					return m, tea.Quit
+					return m.resumeWorktree(selected.Worktree.Path, selected.Worktree.Branch)
 				}
 
 				// Regular worktree creation logic
 				var branchName string
+				m.InferredSparseDirs = nil
+				m.ActiveTemplate = nil
 				if m.SelectedIssue == nil {
 					// Check if we're on "Add subtask" selection (which shouldn't create worktree)
 					if m.AddSubtaskSelected != "" {
@@ -486,47 +917,36 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if strings.TrimSpace(m.TextInput.Value()) == "" {
 						return m, nil // Don't submit empty input
 					}
+					if check := m.checkTypedBranchName(); check != nil && check.Blocks {
+						return m, nil // The warning under the input says why
+					}
 					branchName = strings.TrimSpace(m.TextInput.Value())
 				} else {
 					// Using selected Linear ticket
 					branchName = m.SelectedIssue.GetBranchName()
+					m.InferredSparseDirs = m.RepoConfig.InferSparseDirectories(m.SelectedIssue.LabelNames(), m.SelectedIssue.ProjectName())
 				}
 
-				m.Submitted = true
-				m.Creating = true
-				m.ActiveCreationMode = m.CreationMode
-				m.CreationFinished = false
-				m.PromptSubmitted = false
-				m.CapturedPrompt = ""
-				m.PromptInput.Reset()
-				m.PromptInput.Blur()
-
-				if m.CreationMode == creationModeWorktree && m.NeedsPromptCapture {
-					m.PromptCaptureMode = true
-					m.SearchMode = false
-					m.SearchQuery = ""
-					m.FilteredIssues = nil
-					m.SelectedIssue = nil
-					m.AddSubtaskSelected = ""
-					m.InputMode = false
-					m.TextInput.Blur()
-					m.PromptInput.Focus()
-				} else {
-					m.PromptCaptureMode = false
-					m.TextInput.SetValue(branchName) // Set the input to the selected branch name
-				}
-
-				var creationCmd tea.Cmd
-				if m.CreationMode == creationModeBranchOnly {
-					creationCmd = m.createBranch(branchName)
-				} else {
-					creationCmd = m.createWorktree(branchName)
+				// Apply the template the branch or issue matches, or let the user pick one
+				if m.CreationMode == creationModeWorktree && len(m.Templates) > 0 {
+					var labels []string
+					if m.SelectedIssue != nil {
+						labels = m.SelectedIssue.LabelNames()
+					}
+					prefix, template, ok := m.Templates.Match(branchName, labels)
+					if !ok {
+						m.TemplatePickerMode = true
+						m.TemplatePickerIndex = 0
+						m.PendingBranchName = branchName
+						return m, nil
+					}
+					branchName = m.applyTemplate(prefix, template, branchName)
 				}
 
-				return m, tea.Batch(creationCmd, m.Spinner.Tick)
+				return m.offerExistingOrCreate(branchName)
 			}
-		case tea.KeyTab:
-			if !m.Submitted && !m.SubtaskInputMode {
+		case m.keyMatches(msg, m.Keys.ToggleMode):
+			if !m.Submitted && !m.SubtaskInputMode && !m.BrowseOnly {
 				if m.CreationMode == creationModeWorktree {
 					m.CreationMode = creationModeBranchOnly
 				} else {
@@ -535,44 +955,31 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case tea.KeyUp:
+		case m.keyMatches(msg, m.Keys.Up):
+			if m.SuggestionIndex > 0 {
+				m.SuggestionIndex--
+				return m, nil
+			}
 			if !m.Submitted {
 				m.moveSelection(-1)
 			}
 			return m, nil
 
-		case tea.KeyDown:
+		case m.keyMatches(msg, m.Keys.Down):
+			if m.InputMode && m.SuggestionIndex < len(m.branchSuggestions()) {
+				m.SuggestionIndex++
+				return m, nil
+			}
 			if !m.Submitted {
 				m.moveSelection(1)
 			}
 			return m, nil
 
-		case tea.KeyRight:
-			if !m.InputMode && !m.Submitted && !m.SearchMode {
-				if m.AddSubtaskSelected != "" {
-					// Start subtask input mode
-					m.SubtaskInputMode = true
-					m.SubtaskParentID = m.AddSubtaskSelected
-					m.setSubtaskEntryMode(m.AddSubtaskSelected, true)
-					m.SubtaskInput.SetValue("")
-					m.SubtaskInput.Focus()
-				} else if m.SelectedIssue != nil {
-					// Always expand - either to show children or the "add subtask" option
-					if !m.SelectedIssue.Expanded {
-						if m.SelectedIssue.HasChildren && len(m.SelectedIssue.Children) == 0 {
-							// Fetch children and expand
-							return m, m.fetchChildren(m.SelectedIssue.ID)
-						} else {
-							// Expand immediately (either shows existing children or just the "add subtask" option)
-							m.updateIssueExpansion(m.SelectedIssue.ID, true)
-						}
-					}
-					// If already expanded, do nothing (already showing children/add subtask option)
-				}
-			}
-			return m, nil
+		case m.keyMatches(msg, m.Keys.Expand):
+			return m.expandSelection()
 
-		case tea.KeyLeft:
+		case m.keyMatches(msg, m.Keys.Collapse) && (!m.InputMode || msg.Type != tea.KeyRunes):
+			// There's nothing to collapse from the input, so letters like h type into it
 			if !m.InputMode && !m.Submitted && !m.SearchMode {
 				if m.AddSubtaskSelected != "" {
 					// For add subtask selection, collapse the parent and select it
@@ -589,7 +996,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case tea.KeyBackspace:
+		case !m.Submitted && !m.isTyping() && m.keyMatches(msg, m.Keys.Top):
+			m.jumpToRow(0)
+			return m, nil
+
+		case !m.Submitted && !m.isTyping() && m.keyMatches(msg, m.Keys.Bottom):
+			m.jumpToRow(len(m.visibleWorkQueueRows()) - 1)
+			return m, nil
+
+		case !m.Submitted && !m.isTyping() && m.keyMatches(msg, m.Keys.HalfPageDown):
+			m.scrollHalfPage(1)
+			return m, nil
+
+		case !m.Submitted && !m.isTyping() && m.keyMatches(msg, m.Keys.HalfPageUp):
+			m.scrollHalfPage(-1)
+			return m, nil
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.QuickSelect) && m.quickSelectRow(msg) != nil:
+			m.selectRow(*m.quickSelectRow(msg))
+			m.scrollToSelection()
+			return m, nil
+
+		case msg.Type == tea.KeyBackspace:
 			// Handle backspace in search mode
 			if m.SearchMode && !m.Submitted && !m.SubtaskInputMode {
 				// Let the text input handle the backspace
@@ -606,52 +1034,125 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, cmd
 			}
 
-		case tea.KeyRunes:
-			if !m.Submitted && !m.SubtaskInputMode && !m.SearchMode && len(msg.Runes) == 1 {
-				switch msg.Runes[0] {
-				case 'a', 'A':
-					if m.InputMode && m.TextInput.Value() != "" {
-						break
-					}
-					if len(m.Worktrees) == 0 {
-						break
-					}
-					m.ShowAllWorkItems = !m.ShowAllWorkItems
-					m.selectInput()
-					return m, nil
-				case 'u', 'U':
-					if m.SelectedIssue != nil && m.LinearClient != nil {
-						return m, m.unassignIssue(m.SelectedIssue.ID)
-					}
-				case 'd', 'D':
-					if m.SelectedIssue != nil && m.LinearClient != nil {
-						return m, m.markIssueDone(m.SelectedIssue.ID)
-					}
-				case 'z', 'Z':
-					if m.LastUnassigned != nil && m.LinearClient != nil {
-						return m, m.assignIssueToMe(m.LastUnassigned.Issue.ID)
-					}
+		case shortcutsActive && m.keyMatches(msg, m.Keys.ToggleAll) && len(m.Worktrees) > 0:
+			m.ShowAllWorkItems = !m.ShowAllWorkItems
+			m.selectInput()
+			return m, nil
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Unassign) && m.SelectedIssue != nil && m.LinearClient != nil:
+			return m, m.unassignIssue(m.SelectedIssue.ID)
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Done) && m.SelectedIssue != nil && m.LinearClient != nil:
+			return m, m.markIssueDone(m.SelectedIssue.ID)
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Status) && m.SelectedIssue != nil && m.LinearClient != nil:
+			return m, m.fetchWorkflowStates(m.SelectedIssue.ID)
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Undo) && m.LastUnassigned != nil && m.LinearClient != nil:
+			return m, m.assignIssueToMe(m.LastUnassigned.Issue.ID)
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Rename) && m.renameTarget() != "":
+			return m, m.openRename(m.renameTarget())
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Note) && m.renameTarget() != "":
+			return m, m.openNote(m.renameTarget())
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.CheckoutPR) && m.prCheckoutTarget() != "":
+			m.FooterError = ""
+			m.FooterNotice = "Fetching the PR for " + m.prCheckoutTarget() + "…"
+			m.CreationWarnings = nil
+			return m, m.checkoutPR(m.prCheckoutTarget())
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.PruneMerged) && m.WorktreeManager != nil && !m.WorktreesLoading:
+			m.openPrune()
+			return m, nil
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.SwitchRepo) && len(m.RepoRoots) > 1 && m.OpenRepo != nil:
+			m.RepoPickerMode = true
+			m.RepoPickerIndex = 0
+			for i, root := range m.RepoRoots {
+				if root == m.RepoRoot {
+					m.RepoPickerIndex = i
 				}
 			}
+			return m, nil
 
-			// Handle "/" key to enter search mode
-			if !m.Submitted && !m.SubtaskInputMode && len(msg.Runes) == 1 && msg.Runes[0] == '/' {
-				if !m.SearchMode {
-					// Enter search mode
-					m.SearchMode = true
-					m.SearchQuery = ""
-					m.InputMode = true
-					m.SelectedIssue = nil
-					m.AddSubtaskSelected = ""
-					m.TextInput.Placeholder = "type to fuzzy search"
-					m.TextInput.SetValue("/")
-					m.TextInput.Focus()
-					// Initialize filtered issues to show all
-					m.FilteredIssues = m.LinearIssues
-					return m, nil
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Refresh) && m.LinearClient != nil && !m.LinearLoading && !m.IssuesRefreshing:
+			cmd := m.refreshIssues()
+			return m, cmd
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Board) && m.LinearClient != nil:
+			m.openBoard()
+			return m, nil
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Label) && len(m.labelFilterOptions()) > 1:
+			m.LabelPickerMode = true
+			m.LabelPickerIndex = 0
+			for i, label := range m.labelFilterOptions() {
+				if label == m.LabelFilter {
+					m.LabelPickerIndex = i
 				}
 			}
+			return m, nil
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Cycle) && m.LinearClient != nil:
+			if m.CycleFilter.Active() {
+				m.CycleFilter = issues.CycleFilter{}
+			} else {
+				m.CycleFilter = issues.CycleFilter{Current: true}
+			}
+			m.CycleFilterUnset = false
+			m.selectInput()
+			return m, nil
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.PickCycle) && len(m.LinearIssues) > 0:
+			m.CyclePickerMode = true
+			m.CyclePickerIndex = 0
+			for i, filter := range m.cycleFilterOptions() {
+				if filter.Equal(m.CycleFilter) {
+					m.CyclePickerIndex = i
+				}
+			}
+			return m, nil
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Sort) && len(m.LinearIssues) > 0:
+			m.cycleIssueSort()
+			return m, nil
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.OpenIssue) && m.SelectedIssue != nil:
+			return m, m.openIssue(*m.SelectedIssue)
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Preview) && m.SelectedIssue != nil:
+			m.togglePreview()
+			m.scrollToSelection()
+			return m, nil
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.CopyIssue) && m.SelectedIssue != nil:
+			return m, m.copyIssueIdentifier(*m.SelectedIssue)
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.NextUp) && !m.BrowseOnly:
+			m.selectNextUp()
+			return m, nil
+
+		case shortcutsActive && m.keyMatches(msg, m.Keys.Help):
+			m.HelpMode = true
+			return m, nil
 
+		case !m.Submitted && !m.SearchMode && key.Matches(msg, m.Keys.Search):
+			// Enter search mode
+			m.SearchMode = true
+			m.SearchQuery = ""
+			m.InputMode = true
+			m.SelectedIssue = nil
+			m.AddSubtaskSelected = ""
+			m.TextInput.Placeholder = "type to fuzzy search"
+			m.TextInput.SetValue("/")
+			m.TextInput.Focus()
+			// Initialize filtered issues to show all
+			m.FilteredIssues = m.LinearIssues
+			return m, m.fetchChildrenForSearch(m.LinearIssues)
+
+		case msg.Type == tea.KeyRunes:
 			// In search mode, handle typing
 			if m.SearchMode && !m.Submitted {
 				// Let the text input handle the typing
@@ -671,7 +1172,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case worktreeCreatedMsg:
 		m.Creating = false
+		m.CreatingStatus = ""
+		m.recordBranchUse(msg.branch)
 		m.WorktreePath = msg.path
+		m.CreatedBranch = msg.branch
 		m.CreationFinished = true
 
 		if m.PromptCaptureMode {
@@ -679,7 +1183,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.PromptCaptureMode = false
 				m.Done = true
 				m.Success = true
-				m.Result = fmt.Sprintf("Worktree created at: %s", msg.path)
+				m.Result = m.withCreationWarnings(fmt.Sprintf("Worktree created at: %s", msg.path))
 				return m, tea.Quit
 			}
 			return m, nil
@@ -687,11 +1191,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.Done = true
 		m.Success = true
-		m.Result = fmt.Sprintf("Worktree created at: %s", msg.path)
+		m.Result = m.withCreationWarnings(fmt.Sprintf("Worktree created at: %s", msg.path))
 		return m, tea.Quit
 
 	case branchCreatedMsg:
 		m.Creating = false
+		m.recordBranchUse(msg.branch)
 		m.Done = true
 		m.Success = true
 		m.Result = fmt.Sprintf("Branch created: %s", msg.branch)
@@ -703,41 +1208,86 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.Done = true
 		m.Success = false
 		m.ErrorMsg = msg.err.Error()
+		m.Failure = problem.Describe(msg.err)
 		return m, tea.Quit
 
 	case linearIssuesLoadedMsg:
+		refreshed := m.IssuesRefreshing
+		kept := m.issueTreeState()
 		m.LinearLoading = false
-		m.LinearIssues = msg.issues
+		m.IssuesRefreshing = false
+		m.IssuesLoadedAt = m.now()
+		if m.IssuesLoadedSoFar > 0 {
+			// Some of them are listed already, and may have been opened up
+			m.replaceLoadedIssues(msg.issues)
+		} else {
+			m.LinearIssues = msg.issues
+		}
+		m.IssuesLoadedSoFar = 0
+		m.IssuesTotal = 0
 		m.LinearError = ""
+		m.followCycleDefault()
+		if refreshed {
+			m.keepIssueTree(kept)
+		}
+		if m.BrowseOnly && m.SelectedIssue == nil && m.AddSubtaskSelected == "" {
+			m.selectFirstRow()
+		}
 		// Update placeholder if a Linear ticket is currently selected (but not in search mode)
 		if m.SelectedIssue != nil && !m.SearchMode {
 			m.TextInput.Placeholder = m.SelectedIssue.GetBranchName()
 		}
+		return m, tea.Batch(m.restoreIssueTree(), m.prefetchChildren())
+
+	case childrenPrefetchStartedMsg:
+		return m, waitForPrefetch(msg.ch)
+
+	case childrenPrefetchedMsg:
+		return m, m.childrenPrefetched(msg)
+
+	case issueClockMsg:
+		return m, m.issueClock()
+
+	case issuesLoadStartedMsg:
+		return m, waitForProgress(msg.ch)
+
+	case issuesPageMsg:
+		return m, tea.Batch(m.showIssuesPage(msg.page), waitForProgress(msg.ch))
 
 	case linearErrorMsg:
 		m.LinearLoading = false
+		m.IssuesRefreshing = false
+		m.IssuesLoadedSoFar = 0
+		m.IssuesTotal = 0
 		m.LinearError = msg.err.Error()
+		if errors.Is(msg.err, linear.ErrTimeout) {
+			m.LinearError += "; showing worktrees only"
+		}
 
 	case worktreeLoadStartedMsg:
-		m.WorktreeLoadCh = msg.ch
-		return m, waitForWorktreeLoad(msg.ch)
+		return m, waitForProgress(msg.ch)
 
-	case worktreesLoadingStatusMsg:
-		m.WorktreesLoadingStatus = msg.status
-		if m.WorktreeLoadCh != nil {
-			return m, waitForWorktreeLoad(m.WorktreeLoadCh)
-		}
+	case worktreeCreateStartedMsg:
+		return m, waitForProgress(msg.ch)
+
+	case progressMsg:
+		return m, m.presentProgress(msg)
 
 	case worktreesLoadedMsg:
 		m.WorktreesLoading = false
 		m.Worktrees = msg.worktrees
 		m.WorktreesError = ""
-		m.WorktreeLoadCh = nil
+		m.seeMerged()
+		if m.LinearClient != nil {
+			return m, m.fetchLinkedIssueStates(msg.worktrees)
+		}
+
+	case linkedIssueStatesLoadedMsg:
+		m.LinkedIssueStates = msg.states
 
 	case worktreesErrorMsg:
 		m.WorktreesLoading = false
 		m.WorktreesError = msg.err.Error()
-		m.WorktreeLoadCh = nil
 
 	case childrenLoadedMsg:
 		m.FooterError = ""
@@ -746,18 +1296,53 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.SelectedIssue != nil && !m.SearchMode {
 			m.TextInput.Placeholder = m.SelectedIssue.GetBranchName()
 		}
+		if restoreCmd := m.finishTreeFetch(msg.parentID); restoreCmd != nil {
+			return m, restoreCmd
+		}
+
+	case searchChildrenLoadedMsg:
+		// Search works with whatever has loaded, so a failure here isn't worth
+		// interrupting it for
+		if msg.err != nil {
+			break
+		}
+		var loaded []linear.Issue
+		for parentID, children := range msg.children {
+			if parent := m.findIssueByID(parentID); parent != nil && len(parent.Children) == 0 {
+				m.storeIssueChildren(parentID, children)
+				loaded = append(loaded, children...)
+			}
+		}
+		if m.SearchMode {
+			return m, m.fetchChildrenForSearch(loaded)
+		}
+
+	case childrenBatchLoadedMsg:
+		var cmds []tea.Cmd
+		for _, parentID := range msg.parentIDs {
+			var loaded tea.Msg = childrenLoadedMsg{parentID, msg.children[parentID]}
+			if msg.err != nil {
+				loaded = childrenErrorMsg{parentID: parentID, err: msg.err}
+			}
+			updated, cmd := m.Update(loaded)
+			m = updated.(model)
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(cmds...)
 
 	case childrenErrorMsg:
 		// Still disclose the row so users can add a subtask even if child loading fails.
 		m.updateIssueExpansion(msg.parentID, true)
 		m.FooterError = msg.err.Error()
+		if restoreCmd := m.finishTreeFetch(msg.parentID); restoreCmd != nil {
+			return m, restoreCmd
+		}
 
 	case subtaskCreatedMsg:
 		m.CreatingSubtask = false
 
 		// Clear subtask input
-		m.SubtaskInput.SetValue("")
-		m.SubtaskInput.Blur()
+		m.resetSubtaskForm()
 		m.SubtaskInputMode = false
 		m.setSubtaskEntryMode(m.SubtaskParentID, false)
 		m.SubtaskParentID = ""
@@ -773,11 +1358,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.InputMode = false
 		}
 
+	case checklistSubtaskMsg:
+		return m.checklistSubtaskDone(msg)
+
 	case subtaskErrorMsg:
 		m.CreatingSubtask = false
 		m.Done = true
 		m.Success = false
 		m.ErrorMsg = fmt.Sprintf("Failed to create subtask: %s", msg.err.Error())
+		m.Failure = problem.Describe(fmt.Errorf("Failed to create subtask: %w", msg.err))
 		return m, tea.Quit
 
 	case issueUnassignedMsg:
@@ -816,23 +1405,102 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case issueDoneErrorMsg:
 		m.LinearError = msg.err.Error()
-	}
 
-	// Update spinner if any loading state is active
-	if m.LinearLoading || m.WorktreesLoading || m.Creating || m.CreatingSubtask {
-		var spinnerCmd tea.Cmd
-		m.Spinner, spinnerCmd = m.Spinner.Update(msg)
-		if cmd != nil {
-			return m, tea.Batch(cmd, spinnerCmd)
+	case workflowStatesLoadedMsg:
+		if len(msg.states) == 0 {
+			m.FooterError = "no workflow states found for issue"
+			break
+		}
+		m.StatusPickerMode = true
+		m.StatusPickerIssueID = msg.issueID
+		m.WorkflowStates = msg.states
+		m.StatusPickerIndex = 0
+		// Preselect the issue's current state, matching by name when the ID is unknown
+		if issue := m.findIssueByID(msg.issueID); issue != nil {
+			for i, state := range msg.states {
+				if state.ID == issue.State.ID {
+					m.StatusPickerIndex = i
+					break
+				}
+				if state.Name == issue.State.Name {
+					m.StatusPickerIndex = i
+				}
+			}
 		}
-		cmd = spinnerCmd
-	}
 
-	// Update text inputs based on current mode
-	if m.PromptCaptureMode {
-		m.PromptInput, cmd = m.PromptInput.Update(msg)
+	case issueStateUpdatedMsg:
+		// Completed and canceled issues leave the work queue, just like marking them done
+		if msg.state.Type == "completed" || msg.state.Type == "canceled" {
+			if snapshot, ok := m.removeIssueByID(msg.issueID); ok {
+				m.selectAfterIssueRemoval(snapshot)
+			}
+			break
+		}
+		if issue := m.findIssueByID(msg.issueID); issue != nil {
+			issue.State = msg.state
+		}
+
+	case issueStateErrorMsg:
+		m.FooterError = msg.err.Error()
+
+	case worktreeRenamedMsg:
+		return m, m.finishRename(msg)
+
+	case pruneStartedMsg, pruneProgressMsg, pruneFinishedMsg:
+		return m, m.prunedMsg(msg)
+
+	case worktreeRenameErrorMsg:
+		m.FooterError = msg.err.Error() + msg.warnings
+
+	case worktreeNotedMsg:
+		m.finishNote(msg)
+
+	case worktreeNoteErrorMsg:
+		m.FooterError = msg.err.Error()
+
+	case prCheckedOutMsg:
+		return m.finishPRCheckout(msg)
+
+	case prCheckoutErrorMsg:
+		m.FooterNotice = ""
+		m.FooterError = msg.err.Error()
+
+	case quickJumpLookedUpMsg:
+		m.quickJumpLookedUp(msg)
+
+	case issueLinkMsg:
+		if msg.err != nil {
+			m.FooterError = msg.err.Error()
+			break
+		}
+		m.FooterError = ""
+		m.FooterNotice = msg.notice
+	}
+
+	// Update spinner if any loading state is active
+	if !m.SpinnerFrozen && (m.LinearLoading || m.WorktreesLoading || m.Creating || m.CreatingSubtask) {
+		var spinnerCmd tea.Cmd
+		m.Spinner, spinnerCmd = m.Spinner.Update(msg)
+		if cmd != nil {
+			return m, tea.Batch(cmd, spinnerCmd)
+		}
+		cmd = spinnerCmd
+	}
+
+	// Update text inputs based on current mode
+	if m.PromptCaptureMode {
+		m.PromptInput, cmd = m.PromptInput.Update(msg)
 	} else if m.InputMode && !m.SearchMode {
+		typed := m.TextInput.Value()
 		m.TextInput, cmd = m.TextInput.Update(msg)
+		if m.TextInput.Value() != typed {
+			m.SuggestionIndex = 0
+			cmd = tea.Batch(cmd, m.updateQuickJump())
+		}
+	} else if m.SubtaskInputMode && m.SubtaskChecklistMode {
+		m.SubtaskChecklist, cmd = m.SubtaskChecklist.Update(msg)
+	} else if m.SubtaskInputMode && m.SubtaskField == subtaskFieldDescription {
+		m.SubtaskDescription, cmd = m.SubtaskDescription.Update(msg)
 	} else if m.SubtaskInputMode {
 		m.SubtaskInput, cmd = m.SubtaskInput.Update(msg)
 	}
@@ -840,6 +1508,111 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// updateSubtaskForm handles keys while the inline subtask form is open. Tab
+// expands the form and cycles title, description, estimate and priority.
+func (m model) updateSubtaskForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if m.SubtaskChecklistMode {
+		return m.updateSubtaskChecklist(msg)
+	}
+	if msg.Type == tea.KeyCtrlL {
+		return m, m.openSubtaskChecklist("")
+	}
+	if m.SubtaskField == subtaskFieldTitle && isMultilinePaste(msg) {
+		return m, m.openSubtaskChecklist(string(msg.Runes))
+	}
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.Cancelled = true
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.SubtaskInputMode = false
+		m.setSubtaskEntryMode(m.SubtaskParentID, false)
+		m.SubtaskParentID = ""
+		m.resetSubtaskForm()
+		return m, nil
+	case tea.KeyTab:
+		m.SubtaskFormExpanded = true
+		return m, m.focusSubtaskField((m.SubtaskField + 1) % subtaskFieldCount)
+	case tea.KeyShiftTab:
+		m.SubtaskFormExpanded = true
+		return m, m.focusSubtaskField((m.SubtaskField + subtaskFieldCount - 1) % subtaskFieldCount)
+	case tea.KeyLeft, tea.KeyRight:
+		delta := 1
+		if msg.Type == tea.KeyLeft {
+			delta = -1
+		}
+		switch m.SubtaskField {
+		case subtaskFieldEstimate:
+			m.SubtaskEstimateIndex = (m.SubtaskEstimateIndex + len(subtaskEstimates) + delta) % len(subtaskEstimates)
+			return m, nil
+		case subtaskFieldPriority:
+			m.SubtaskPriority = (m.SubtaskPriority + len(subtaskPriorities) + delta) % len(subtaskPriorities)
+			return m, nil
+		}
+	case tea.KeyEnter:
+		if msg.Alt && m.SubtaskField == subtaskFieldDescription {
+			m.SubtaskDescription.InsertRune('\n')
+			return m, nil
+		}
+		title := strings.TrimSpace(m.SubtaskInput.Value())
+		if title == "" {
+			return m, nil // Don't submit empty subtask title
+		}
+		opts := linear.SubtaskOptions{
+			Description: strings.TrimSpace(m.SubtaskDescription.Value()),
+			Estimate:    subtaskEstimates[m.SubtaskEstimateIndex],
+			Priority:    m.SubtaskPriority,
+		}
+		m.CreatingSubtask = true
+		m.SubtaskInputMode = false
+		m.SubtaskInput.Blur()
+		m.SubtaskDescription.Blur()
+		return m, tea.Batch(m.createSubtaskInline(m.SubtaskParentID, title, opts), m.Spinner.Tick)
+	}
+
+	switch m.SubtaskField {
+	case subtaskFieldTitle:
+		m.SubtaskInput, cmd = m.SubtaskInput.Update(msg)
+	case subtaskFieldDescription:
+		if msg.String() == "shift+enter" || msg.Type == tea.KeyCtrlJ {
+			m.SubtaskDescription.InsertRune('\n')
+			return m, nil
+		}
+		m.SubtaskDescription, cmd = m.SubtaskDescription.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m *model) focusSubtaskField(field subtaskField) tea.Cmd {
+	m.SubtaskField = field
+	m.SubtaskInput.Blur()
+	m.SubtaskDescription.Blur()
+	switch field {
+	case subtaskFieldTitle:
+		return m.SubtaskInput.Focus()
+	case subtaskFieldDescription:
+		return m.SubtaskDescription.Focus()
+	}
+	return nil
+}
+
+func (m *model) resetSubtaskForm() {
+	m.SubtaskInput.SetValue("")
+	m.SubtaskInput.Blur()
+	m.SubtaskDescription.Reset()
+	m.SubtaskDescription.Blur()
+	m.SubtaskFormExpanded = false
+	m.SubtaskField = subtaskFieldTitle
+	m.SubtaskEstimateIndex = 0
+	m.SubtaskPriority = 0
+	m.SubtaskChecklistMode = false
+	m.SubtaskChecklist.Reset()
+	m.SubtaskChecklist.Blur()
+}
+
 // getFirstVisibleIssue returns the first visible issue in the tree
 func (m *model) getFirstVisibleIssue() *linear.Issue {
 	if len(m.LinearIssues) > 0 {
@@ -894,7 +1667,156 @@ func (m *model) setSubtaskEntryMode(issueID string, enabled bool) {
 	update(&m.LinearIssues)
 }
 
-func (m model) createWorktree(branchName string) tea.Cmd {
+// findExisting returns what already exists for branchName, or nil when
+// nothing does; lookup errors are left for creation itself to report
+func (m model) findExisting(branchName string) *git.ExistingBranch {
+	if m.WorktreeManager == nil {
+		return nil
+	}
+	existing, err := m.WorktreeManager.FindExisting(branchName)
+	if err != nil || !existing.Found() {
+		return nil
+	}
+	return &existing
+}
+
+func (m *model) closeExistingPrompt() {
+	m.ExistingPrompt = nil
+	m.SuffixedBranch = ""
+	m.PendingBranchName = ""
+	m.InferredSparseDirs = nil
+	m.ActiveTemplate = nil
+}
+
+// offerExistingOrCreate asks before reusing a worktree or branch that's
+// already there, and otherwise carries on creating branchName
+func (m model) offerExistingOrCreate(branchName string) (tea.Model, tea.Cmd) {
+	if existing := m.findExisting(branchName); existing != nil {
+		m.ExistingPrompt = existing
+		m.PendingBranchName = branchName
+		// Starting afresh beside it isn't offered when no name is free
+		m.SuffixedBranch, _ = git.FreeBranchName(m.WorktreeManager, existing.Branch)
+		return m, nil
+	}
+	return m.continueCreation(branchName)
+}
+
+// applyTemplate uses template for the worktree being created and returns
+// branchName with the template's prefix
+func (m *model) applyTemplate(prefix string, template config.Template, branchName string) string {
+	m.ActiveTemplate = &template
+	return config.ApplyTemplatePrefix(prefix, branchName)
+}
+
+// templateOptions lists the template picker entries: no template, then each
+// template prefix in name order
+func (m model) templateOptions() []string {
+	return append([]string{""}, m.Templates.Prefixes()...)
+}
+
+// resumeWorktree finishes by handing the existing worktree at path back to the caller
+func (m model) resumeWorktree(path, branch string) (tea.Model, tea.Cmd) {
+	m.Submitted = true
+	m.Creating = false
+	m.Done = true
+	m.Success = true
+	m.Resumed = true
+	m.WorktreePath = path
+	m.ResumeBranch = branch
+	m.recordBranchUse(branch)
+	m.Result = fmt.Sprintf("Worktree resumed at: %s", path)
+	return m, tea.Quit
+}
+
+// continueCreation lets the user pick a sparse profile, when the repo has any
+// and the template hasn't chosen one, before creating branchName
+func (m model) continueCreation(branchName string) (tea.Model, tea.Cmd) {
+	if m.CreationMode == creationModeWorktree && m.ActiveTemplate != nil {
+		if directories, ok := m.SparseProfiles[m.ActiveTemplate.SparseProfile]; ok {
+			return m.startCreation(branchName, directories)
+		}
+	}
+	if m.CreationMode == creationModeWorktree && len(m.SparseProfiles) > 0 {
+		m.SparseProfileMode = true
+		m.SparseProfileIndex = 0
+		m.PendingBranchName = branchName
+		return m, nil
+	}
+	return m.startCreation(branchName, m.InferredSparseDirs)
+}
+
+// startCreation kicks off branch or worktree creation for branchName, applying
+// sparseDirectories to new worktrees when set
+func (m model) startCreation(branchName string, sparseDirectories []string) (tea.Model, tea.Cmd) {
+	m.Submitted = true
+	m.Creating = true
+	m.CreatingStatus = ""
+	m.CreationWarnings = nil
+	m.ActiveCreationMode = m.CreationMode
+	m.CreationFinished = false
+	if m.DefaultCommandFor != nil {
+		template := config.Template{}
+		if m.ActiveTemplate != nil {
+			template = *m.ActiveTemplate
+		}
+		command, _ := m.DefaultCommandFor(m.RepoRoot, branchName, template)
+		m.DefaultCommandArgs = command
+		m.NeedsPromptCapture = config.NeedsPromptCapture(command)
+	}
+	m.PromptSubmitted = false
+	m.CapturedPrompt = ""
+	m.PromptInput.Reset()
+	m.PromptInput.Blur()
+
+	if m.CreationMode == creationModeWorktree && m.NeedsPromptCapture {
+		m.PromptCaptureMode = true
+		m.SearchMode = false
+		m.SearchQuery = ""
+		m.FilteredIssues = nil
+		m.SelectedIssue = nil
+		m.AddSubtaskSelected = ""
+		m.InputMode = false
+		m.TextInput.Blur()
+		m.PromptInput.Focus()
+	} else {
+		m.PromptCaptureMode = false
+		m.TextInput.SetValue(branchName) // Set the input to the selected branch name
+	}
+
+	var creationCmd tea.Cmd
+	if m.CreationMode == creationModeBranchOnly {
+		creationCmd = m.createBranch(branchName)
+	} else {
+		creationCmd = m.createWorktree(branchName, sparseDirectories)
+	}
+
+	return m, tea.Batch(creationCmd, m.Spinner.Tick)
+}
+
+// sparseOptions lists the picker entries: directories inferred from the issue
+// (when any), a full checkout, then the saved profiles in name order
+func (m model) sparseOptions() []sparseOption {
+	var options []sparseOption
+	if len(m.InferredSparseDirs) > 0 {
+		options = append(options, sparseOption{Name: "inferred", Directories: m.InferredSparseDirs})
+	}
+	options = append(options, sparseOption{Name: "full checkout"})
+	for _, name := range m.sparseProfileNames() {
+		options = append(options, sparseOption{Name: name, Directories: m.SparseProfiles[name]})
+	}
+	return options
+}
+
+func (m model) sparseProfileNames() []string {
+	names := make([]string, 0, len(m.SparseProfiles))
+	for name := range m.SparseProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m model) createWorktree(branchName string, sparseDirectories []string) tea.Cmd {
 	return func() tea.Msg {
 		if m.WorktreeManager == nil {
 			return errMsg{fmt.Errorf("worktree manager not configured")}
@@ -905,11 +1827,30 @@ func (m model) createWorktree(branchName string) tea.Cmd {
 			return errMsg{fmt.Errorf("branch name cannot be empty")}
 		}
 
-		worktreePath, err := m.WorktreeManager.CreateWorktree(branchName)
-		if err != nil {
-			return errMsg{err}
+		opts := git.CreateOptions{SparseDirectories: sparseDirectories}
+		if m.ActiveTemplate != nil {
+			opts.BaseBranch = m.ActiveTemplate.Base
+			opts.Hooks = m.ActiveTemplate.Hooks
+		}
+		if m.RepoConfig != nil {
+			opts.Submodules = git.SetupFromConfig(m.RepoConfig.Submodules)
+			opts.LFS = git.SetupFromConfig(m.RepoConfig.LFS)
+			opts.GitHooks = m.RepoConfig.GitHooks
 		}
-		return worktreeCreatedMsg{branchName, worktreePath}
+
+		// Setup steps can take a while, so the spinner follows them
+		ch := make(chan tea.Msg, 16)
+		opts.Progress = teaPresenter{operation: creatingWorktree, ch: ch}
+		go func() {
+			worktreePath, err := m.WorktreeManager.CreateWorktreeWithOptions(branchName, opts)
+			if err != nil {
+				ch <- errMsg{err}
+			} else {
+				ch <- worktreeCreatedMsg{branchName, worktreePath}
+			}
+			close(ch)
+		}()
+		return worktreeCreateStartedMsg{ch: ch}
 	}
 }
 
@@ -948,14 +1889,20 @@ func (m *model) updateIssueExpansion(issueID string, expanded bool) {
 	update(&m.LinearIssues)
 }
 
-// setIssueChildren sets the children for a specific issue
+// setIssueChildren sets the children for a specific issue and expands it
 func (m *model) setIssueChildren(parentID string, children []linear.Issue) {
+	m.storeIssueChildren(parentID, children)
+	m.updateIssueExpansion(parentID, true)
+}
+
+// storeIssueChildren sets the children for a specific issue, leaving it
+// collapsed or expanded as it was
+func (m *model) storeIssueChildren(parentID string, children []linear.Issue) {
 	var setChildren func(issues *[]linear.Issue)
 	setChildren = func(issues *[]linear.Issue) {
 		for i := range *issues {
 			if (*issues)[i].ID == parentID {
 				(*issues)[i].Children = children
-				(*issues)[i].Expanded = true
 				// Set depth and parent pointers for children
 				for j := range (*issues)[i].Children {
 					(*issues)[i].Children[j].Depth = (*issues)[i].Depth + 1
@@ -971,23 +1918,11 @@ func (m *model) setIssueChildren(parentID string, children []linear.Issue) {
 	setChildren(&m.LinearIssues)
 }
 
-func (m model) fetchLinearIssues() tea.Cmd {
-	return func() tea.Msg {
-		issues, err := m.LinearClient.GetAssignedIssues()
-		if err != nil {
-			return linearErrorMsg{err}
-		}
-		return linearIssuesLoadedMsg{issues}
-	}
-}
-
 func (m model) fetchWorktrees() tea.Cmd {
 	return func() tea.Msg {
 		ch := make(chan tea.Msg, 16)
 		go func() {
-			worktrees, err := m.WorktreeManager.ListWorktreesForTUIWithProgress(func(status string) {
-				ch <- worktreesLoadingStatusMsg{status: status}
-			})
+			worktrees, err := m.WorktreeManager.ListWorktreesForTUIWithProgress(teaPresenter{operation: loadingWorktrees, ch: ch})
 			if err != nil {
 				ch <- worktreesErrorMsg{err}
 			} else {
@@ -999,16 +1934,6 @@ func (m model) fetchWorktrees() tea.Cmd {
 	}
 }
 
-func waitForWorktreeLoad(ch <-chan tea.Msg) tea.Cmd {
-	return func() tea.Msg {
-		msg, ok := <-ch
-		if !ok {
-			return nil
-		}
-		return msg
-	}
-}
-
 func (m model) fetchChildren(issueID string) tea.Cmd {
 	return func() tea.Msg {
 		children, err := m.LinearClient.GetIssueChildren(issueID)
@@ -1019,9 +1944,47 @@ func (m model) fetchChildren(issueID string) tea.Cmd {
 	}
 }
 
-func (m model) createSubtaskInline(parentID, title string) tea.Cmd {
+// fetchChildrenForSearch loads the subtasks of issues in issues that haven't
+// been expanded yet, so search can look through them too
+func (m model) fetchChildrenForSearch(issues []linear.Issue) tea.Cmd {
+	if m.LinearClient == nil {
+		return nil
+	}
+	var parentIDs []string
+	var walk func(issues []linear.Issue)
+	walk = func(issues []linear.Issue) {
+		for _, issue := range issues {
+			if issue.HasChildren && len(issue.Children) == 0 {
+				parentIDs = append(parentIDs, issue.ID)
+			}
+			walk(issue.Children)
+		}
+	}
+	walk(issues)
+	if len(parentIDs) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		children, err := m.LinearClient.GetChildrenOfIssues(parentIDs)
+		return searchChildrenLoadedMsg{children: children, err: err}
+	}
+}
+
+// fetchChildrenOf loads the subtasks of each of parentIDs in as few requests
+// as the issue tracker allows, each arriving as if fetched on its own
+func (m model) fetchChildrenOf(parentIDs []string) tea.Cmd {
+	if len(parentIDs) == 1 {
+		return m.fetchChildren(parentIDs[0])
+	}
+	return func() tea.Msg {
+		children, err := m.LinearClient.GetChildrenOfIssues(parentIDs)
+		return childrenBatchLoadedMsg{parentIDs: parentIDs, children: children, err: err}
+	}
+}
+
+func (m model) createSubtaskInline(parentID, title string, opts linear.SubtaskOptions) tea.Cmd {
 	return func() tea.Msg {
-		subtask, err := m.LinearClient.CreateSubtask(parentID, title)
+		subtask, err := m.LinearClient.CreateSubtaskWithOptions(parentID, title, opts)
 		if err != nil {
 			return subtaskErrorMsg{err}
 		}
@@ -1065,54 +2028,164 @@ func (m model) markIssueDone(issueID string) tea.Cmd {
 	}
 }
 
-// filterIssuesBySearch filters issues using fuzzy search on identifier and title
-func (m *model) filterIssuesBySearch(query string) []linear.Issue {
-	if query == "" {
-		return m.LinearIssues
+// fetchLinkedIssueStates looks up the tickets linked to worktrees so worktree
+// rows without a matching assigned issue can still show their ticket status
+func (m model) fetchLinkedIssueStates(worktrees []git.Worktree) tea.Cmd {
+	var identifiers []string
+	for _, wt := range worktrees {
+		if identifier := metadata.IssueFromBranch(wt.Branch); identifier != "" {
+			identifiers = append(identifiers, identifier)
+		}
+	}
+	if len(identifiers) == 0 {
+		return nil
 	}
+	return func() tea.Msg {
+		// Statuses are supplementary, so a failed lookup just leaves them out
+		states, _ := m.LinearClient.GetIssueStates(identifiers)
+		return linkedIssueStatesLoadedMsg{states: states}
+	}
+}
 
-	var filtered []linear.Issue
+func (m model) fetchWorkflowStates(issueID string) tea.Cmd {
+	return func() tea.Msg {
+		states, err := m.LinearClient.GetWorkflowStates(issueID)
+		if err != nil {
+			return issueStateErrorMsg{err: err}
+		}
+		return workflowStatesLoadedMsg{issueID: issueID, states: states}
+	}
+}
 
-	// Helper function to recursively collect all issues (including children)
-	var collectAllIssues func(issues []linear.Issue) []linear.Issue
-	collectAllIssues = func(issues []linear.Issue) []linear.Issue {
-		var result []linear.Issue
-		for _, issue := range issues {
-			result = append(result, issue)
-			if len(issue.Children) > 0 {
-				result = append(result, collectAllIssues(issue.Children)...)
-			}
+func (m model) updateIssueState(issueID string, state linear.State) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.LinearClient.UpdateIssueState(issueID, state.ID); err != nil {
+			return issueStateErrorMsg{err: err}
 		}
-		return result
+		return issueStateUpdatedMsg{issueID: issueID, state: state}
+	}
+}
+
+// switchLinearWorkspaces reconnects to Linear when the repository being
+// switched to is pinned to different workspaces than the one being left
+func (m *model) switchLinearWorkspaces(cfg *config.Config, from, to string) tea.Cmd {
+	if reflect.DeepEqual(cfg.ForRepo(from).GetLinearWorkspaces(), cfg.ForRepo(to).GetLinearWorkspaces()) {
+		return nil
+	}
+	client, err := issues.NewForRepo(cfg, to)
+	if err != nil {
+		m.FooterError = err.Error()
+		return nil
+	}
+	m.stopPrefetch()
+	m.LinearClient = client
+	m.LinearIssues = nil
+	m.LinearError = ""
+	m.LinearLoading = client != nil
+	if client == nil {
+		return nil
 	}
+	return m.fetchLinearIssues()
+}
 
-	allIssues := collectAllIssues(m.LinearIssues)
+// switchRepo points the dashboard at another registered repository and reloads its worktrees
+func (m model) switchRepo(root string) (tea.Model, tea.Cmd) {
+	if root == m.RepoRoot {
+		return m, nil
+	}
+	wm, name, err := m.OpenRepo(root)
+	if err != nil {
+		m.FooterError = err.Error()
+		return m, nil
+	}
 
-	// Create search targets (identifier + title) for fuzzy matching
-	var targets []string
-	for _, issue := range allIssues {
-		targets = append(targets, strings.ToLower(issue.Identifier+" "+issue.Title))
+	m.FooterError = ""
+	// Each repository keeps its own issue tree, so leave this one's behind
+	m.saveIssueTree()
+	previousRoot := m.RepoRoot
+	// A TUI started on the repo picker has had nowhere to keep these yet
+	picked := previousRoot == ""
+	m.WorktreeManager = wm
+	wm.SetProgress(m.ManagerWarnings)
+	m.RepoRoot = root
+	m.TextInput.Prompt = "> " + name + "/"
+	m.RepoConfig = nil
+	if repoConfig, err := config.LoadRepoConfig(root); err == nil {
+		m.RepoConfig = repoConfig
+	}
+	// Only a TUI that saves its issue order has one to read back
+	var issuesCmd tea.Cmd
+	if m.SaveIssueSort != nil {
+		if cfg, err := config.Load(); err == nil {
+			m.IssueSort = issueSortFor(cfg, root)
+			issuesCmd = m.switchLinearWorkspaces(cfg, previousRoot, root)
+		}
 	}
+	store := metadata.NewStore(root)
+	m.SparseProfiles = store.SparseProfiles()
+	m.RecentBranches = store.RecentBranches()
+	if m.History != nil || picked {
+		m.History = store
+	}
+	if m.MergedHistory != nil || picked {
+		m.MergedHistory = store
+	}
+	m.MergedBanner = false
+	m.Worktrees = nil
+	m.WorktreesError = ""
+	m.LinkedIssueStates = nil
+	m.ShowAllWorkItems = false
+	m.WorktreesLoading = true
+	m.WorktreesLoadingStatus = "git worktree list --porcelain"
+	m.selectInput()
+
+	var restoreCmd tea.Cmd
+	if m.TreeState != nil || picked {
+		m.TreeState = store
+		m.collapseIssueTree()
+		m.RestoringTree = newTreeRestore(store.IssueTree())
+		restoreCmd = m.restoreIssueTree()
+	}
+	return m, tea.Batch(m.fetchWorktrees(), m.Spinner.Tick, restoreCmd, issuesCmd)
+}
 
-	// Perform fuzzy search
-	matches := fuzzy.FindNormalized(strings.ToLower(query), targets)
+func (m *model) closeStatusPicker() {
+	m.StatusPickerMode = false
+	m.StatusPickerIssueID = ""
+	m.StatusPickerIndex = 0
+	m.WorkflowStates = nil
+}
 
-	// Build filtered results maintaining only top-level issues
-	matchedTargets := make(map[string]bool)
-	for _, match := range matches {
-		matchedTargets[match] = true
+// filterIssuesBySearch returns the issues, subtasks included, whose
+// identifier and title match query, best match first
+func (m *model) filterIssuesBySearch(query string) []linear.Issue {
+	if query == "" {
+		return m.LinearIssues
 	}
 
-	for _, issue := range allIssues {
-		target := strings.ToLower(issue.Identifier + " " + issue.Title)
-		if matchedTargets[target] {
-			// Only include top-level issues (depth 0) in filtered results
-			if issue.Depth == 0 {
-				filtered = append(filtered, issue)
+	type rankedIssue struct {
+		issue linear.Issue
+		score int
+	}
+	var ranked []rankedIssue
+	var walk func(issues []linear.Issue)
+	walk = func(issues []linear.Issue) {
+		for _, issue := range issues {
+			if match, ok := matchSearch(query, issueSearchText(issue)); ok {
+				ranked = append(ranked, rankedIssue{issue: issue, score: match.Score})
 			}
+			walk(issue.Children)
 		}
 	}
+	walk(m.LinearIssues)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
 
+	filtered := make([]linear.Issue, 0, len(ranked))
+	for _, r := range ranked {
+		filtered = append(filtered, r.issue)
+	}
 	return filtered
 }
 
@@ -1258,10 +2331,7 @@ func (m *model) normalizeIssueTree() {
 
 func (m *model) selectAfterIssueRemoval(snapshot unassignedIssueSnapshot) {
 	if len(m.LinearIssues) == 0 {
-		m.SelectedIssue = nil
-		m.InputMode = true
-		m.TextInput.Focus()
-		m.TextInput.Placeholder = m.DefaultPlaceholder
+		m.selectInput()
 		return
 	}
 
@@ -1303,20 +2373,30 @@ func (m *model) selectAfterIssueRemoval(snapshot unassignedIssueSnapshot) {
 }
 
 func (m *model) visibleWorkQueueRows() []workQueueRow {
-	allRows := m.buildWorkQueueRows()
 	if m.SearchMode {
-		return m.filterWorkQueueRows(allRows, m.SearchQuery)
+		if query := strings.TrimSpace(m.SearchQuery); query != "" {
+			return m.searchWorkQueueRows(query)
+		}
 	}
-	return allRows
+	return m.buildWorkQueueRows()
 }
 
-func (m *model) buildWorkQueueRows() []workQueueRow {
+// workQueueRoots returns the top-level rows, active and closed, each sorted,
+// along with the worktree matched to each issue
+func (m *model) workQueueRoots() ([]workQueueRow, []workQueueRow, map[string]*git.Worktree) {
 	matchedBranches := make(map[string]bool)
 	worktreesByIssue := m.matchWorktreesToIssues(&matchedBranches)
 
 	var activeRows []workQueueRow
 	var closedRows []workQueueRow
+	now := m.now()
 	for i := range m.LinearIssues {
+		if m.LabelFilter != "" && !hasLabel(m.LinearIssues[i], m.LabelFilter) {
+			continue
+		}
+		if !m.CycleFilter.Includes(m.LinearIssues[i], now) {
+			continue
+		}
 		row := m.issueRow(&m.LinearIssues[i], worktreesByIssue)
 		if row.Closed && len(m.Worktrees) > 0 {
 			closedRows = append(closedRows, row)
@@ -1327,7 +2407,7 @@ func (m *model) buildWorkQueueRows() []workQueueRow {
 
 	for i := range m.Worktrees {
 		wt := m.Worktrees[i]
-		if !m.shouldConsiderWorktree(wt) || matchedBranches[wt.Branch] {
+		if !m.shouldConsiderWorktree(wt) || matchedBranches[wt.Branch] || m.LabelFilter != "" || m.CycleFilter.Active() {
 			continue
 		}
 		row := workQueueRow{
@@ -1343,8 +2423,13 @@ func (m *model) buildWorkQueueRows() []workQueueRow {
 		}
 	}
 
-	sortRows(activeRows)
-	sortRows(closedRows)
+	sortRows(activeRows, m.IssueSort)
+	sortRows(closedRows, m.IssueSort)
+	return activeRows, closedRows, worktreesByIssue
+}
+
+func (m *model) buildWorkQueueRows() []workQueueRow {
+	activeRows, closedRows, worktreesByIssue := m.workQueueRoots()
 
 	var rows []workQueueRow
 	for _, row := range activeRows {
@@ -1361,8 +2446,19 @@ func (m *model) buildWorkQueueRows() []workQueueRow {
 	return rows
 }
 
-func sortRows(rows []workQueueRow) {
+// sortRows orders rows by order, breaking ties with the most recently updated
+func sortRows(rows []workQueueRow, order string) {
 	sort.SliceStable(rows, func(i, j int) bool {
+		switch order {
+		case config.IssueSortPriority:
+			if a, b := priorityRank(rows[i]), priorityRank(rows[j]); a != b {
+				return a < b
+			}
+		case config.IssueSortEstimate:
+			if a, b := estimateRank(rows[i]), estimateRank(rows[j]); a != b {
+				return a < b
+			}
+		}
 		if rows[i].Updated.IsZero() && rows[j].Updated.IsZero() {
 			return false
 		}
@@ -1373,37 +2469,139 @@ func sortRows(rows []workQueueRow) {
 	})
 }
 
-func rowSortLabel(row workQueueRow) string {
-	if row.Issue != nil {
-		return strings.ToLower(row.Issue.Identifier)
+// labelFilterOptions lists the labels on loaded issues for the label filter,
+// after "" which lists every issue
+func (m model) labelFilterOptions() []string {
+	seen := make(map[string]bool)
+	var labels []string
+	var collect func(issues []linear.Issue)
+	collect = func(issues []linear.Issue) {
+		for _, issue := range issues {
+			for _, name := range issue.LabelNames() {
+				if !seen[name] {
+					seen[name] = true
+					labels = append(labels, name)
+				}
+			}
+			collect(issue.Children)
+		}
 	}
-	if row.Worktree != nil {
-		return strings.ToLower(row.Worktree.Branch)
+	collect(m.LinearIssues)
+	sort.Slice(labels, func(i, j int) bool {
+		return strings.ToLower(labels[i]) < strings.ToLower(labels[j])
+	})
+	return append([]string{""}, labels...)
+}
+
+// followCycleDefault narrows the tree to the current cycle when the issues
+// are in it and no cycle has been chosen, in the config or since
+func (m *model) followCycleDefault() {
+	if m.CycleFilterUnset {
+		m.CycleFilter = issues.DefaultCycleFilter(m.LinearIssues, m.now())
 	}
-	return ""
 }
 
-func (m *model) issueRow(issue *linear.Issue, worktreesByIssue map[string]*git.Worktree) workQueueRow {
-	wt := worktreesByIssue[strings.ToUpper(issue.Identifier)]
-	updated := issue.UpdatedAt
-	if updated.IsZero() && wt != nil && wt.UpdatedAt.After(updated) {
-		updated = wt.UpdatedAt
+// cycleFilterOptions lists what the cycle picker offers: any cycle, the
+// current one, then each cycle the loaded issues are in
+func (m model) cycleFilterOptions() []issues.CycleFilter {
+	options := []issues.CycleFilter{{}, {Current: true}}
+	for _, cycle := range issues.Cycles(m.LinearIssues) {
+		options = append(options, issues.CycleFilter{Cycle: &cycle})
 	}
-	return workQueueRow{
-		Kind:     workQueueRowIssue,
-		Issue:    issue,
-		Worktree: wt,
-		Closed:   isClosedIssue(*issue) || (wt != nil && wt.Merged),
-		Updated:  updated,
+	return options
+}
+
+// hasLabel reports whether issue carries the label called name
+func hasLabel(issue linear.Issue, name string) bool {
+	for _, label := range issue.Labels {
+		if strings.EqualFold(label.Name, name) {
+			return true
+		}
 	}
+	return false
 }
 
-func (m *model) expandRow(row workQueueRow, worktreesByIssue map[string]*git.Worktree) []workQueueRow {
-	rows := []workQueueRow{row}
-	if row.Kind != workQueueRowIssue || row.Issue == nil || !row.Issue.Expanded {
-		return rows
+// priorityRank puts urgent issues first and those without a priority, and
+// worktrees without an issue, last
+func priorityRank(row workQueueRow) int {
+	if row.Issue == nil || row.Issue.Priority <= 0 {
+		return len(subtaskPriorities)
 	}
-	for i := range row.Issue.Children {
+	return row.Issue.Priority
+}
+
+// estimateRank puts the smallest estimates first and unestimated rows last
+func estimateRank(row workQueueRow) float64 {
+	if row.Issue == nil || row.Issue.Estimate <= 0 {
+		return math.Inf(1)
+	}
+	return row.Issue.Estimate
+}
+
+// cycleIssueSort moves to the next issue order and remembers it for the repo
+func (m *model) cycleIssueSort() {
+	next := config.IssueSortOrders[0]
+	for i, order := range config.IssueSortOrders {
+		if order == m.IssueSort {
+			next = config.IssueSortOrders[(i+1)%len(config.IssueSortOrders)]
+		}
+	}
+	m.IssueSort = next
+	m.FooterError = ""
+	if m.SaveIssueSort != nil {
+		if err := m.SaveIssueSort(m.RepoRoot, next); err != nil {
+			m.FooterError = "Failed to save issue order: " + err.Error()
+		}
+	}
+	m.scrollToSelection()
+}
+
+// saveIssueSort remembers order for the repository at repoRoot in the
+// metadata store, leaving the config file as the user wrote it
+func saveIssueSort(repoRoot, order string) error {
+	return metadata.NewStore(repoRoot).SetIssueSort(order)
+}
+
+// issueSortFor is the order the repository's issue tree was last sorted in,
+// or else the one issueSort in the config gives it
+func issueSortFor(cfg *config.Config, repoRoot string) string {
+	if order := metadata.NewStore(repoRoot).IssueSort(); slices.Contains(config.IssueSortOrders, order) {
+		return order
+	}
+	return cfg.GetIssueSort(repoRoot)
+}
+
+func rowSortLabel(row workQueueRow) string {
+	if row.Issue != nil {
+		return strings.ToLower(row.Issue.Identifier)
+	}
+	if row.Worktree != nil {
+		return strings.ToLower(row.Worktree.Name())
+	}
+	return ""
+}
+
+func (m *model) issueRow(issue *linear.Issue, worktreesByIssue map[string]*git.Worktree) workQueueRow {
+	wt := worktreesByIssue[strings.ToUpper(issue.Identifier)]
+	updated := issue.UpdatedAt
+	if updated.IsZero() && wt != nil && wt.UpdatedAt.After(updated) {
+		updated = wt.UpdatedAt
+	}
+	return workQueueRow{
+		Kind:     workQueueRowIssue,
+		Issue:    issue,
+		Worktree: wt,
+		Closed:   isClosedIssue(*issue) || (wt != nil && wt.Merged),
+		Updated:  updated,
+	}
+}
+
+func (m *model) expandRow(row workQueueRow, worktreesByIssue map[string]*git.Worktree) []workQueueRow {
+	rows := []workQueueRow{row}
+	if row.Kind != workQueueRowIssue || row.Issue == nil || !row.Issue.Expanded {
+		return rows
+	}
+	for i := range row.Issue.Children {
 		childRow := m.issueRow(&row.Issue.Children[i], worktreesByIssue)
 		if childRow.Closed && len(m.Worktrees) > 0 && !m.ShowAllWorkItems {
 			continue
@@ -1432,7 +2630,10 @@ func (m *model) matchWorktreesToIssues(matchedBranches *map[string]bool) map[str
 					(*matchedBranches)[wt.Branch] = true
 				}
 			}
-			if len(issues[i].Children) > 0 {
+			// A worktree for a subtask stays in view on its own row until the
+			// subtask's row shows, as subtasks are fetched before they're asked
+			// for; search shows matching subtasks wherever they are
+			if (issues[i].Expanded || m.SearchMode) && len(issues[i].Children) > 0 {
 				walk(issues[i].Children)
 			}
 		}
@@ -1448,61 +2649,60 @@ func branchMatchesIdentifier(branch, identifier string) bool {
 }
 
 func (m *model) shouldConsiderWorktree(wt git.Worktree) bool {
-	if wt.Prunable || wt.Branch == "" {
+	if wt.Prunable || wt.Bare || (wt.Branch == "" && !wt.Detached) {
 		return false
 	}
 	branch := strings.ToLower(wt.Branch)
 	return branch != "main" && branch != "master"
 }
 
+// worktreeKey identifies a worktree row for selection: its branch, or its path
+// when a detached HEAD leaves it without one
+func worktreeKey(wt git.Worktree) string {
+	if wt.Branch != "" {
+		return wt.Branch
+	}
+	return wt.Path
+}
+
 func isClosedIssue(issue linear.Issue) bool {
 	stateType := strings.ToLower(issue.State.Type)
 	return stateType == "completed" || stateType == "done" || stateType == "canceled" || stateType == "cancelled"
 }
 
-func (m *model) filterWorkQueueRows(rows []workQueueRow, query string) []workQueueRow {
-	query = strings.ToLower(strings.TrimSpace(query))
-	if query == "" {
-		return rows
-	}
-	var filtered []workQueueRow
-	for _, row := range rows {
-		target := ""
-		if row.Issue != nil {
-			target = row.Issue.Identifier + " " + row.Issue.Title
-		} else if row.Worktree != nil {
-			target = row.Worktree.Branch + " " + row.Worktree.Path
-		}
-		if fuzzy.MatchNormalized(query, strings.ToLower(target)) {
-			filtered = append(filtered, row)
-		}
+func (m *model) selectedRow() *workQueueRow {
+	rows := m.visibleWorkQueueRows()
+	if i := m.selectedRowIndex(rows); i >= 0 {
+		return &rows[i]
 	}
-	return filtered
+	return nil
 }
 
-func (m *model) selectedRow() *workQueueRow {
-	rows := m.visibleWorkQueueRows()
-	for i := range rows {
-		row := rows[i]
+// selectedRowIndex returns the position of the selected row in rows, or -1
+// when the input is selected
+func (m model) selectedRowIndex(rows []workQueueRow) int {
+	for i, row := range rows {
 		switch row.Kind {
 		case workQueueRowIssue:
 			if m.SelectedIssue != nil && row.Issue != nil && row.Issue.ID == m.SelectedIssue.ID {
-				return &row
+				return i
 			}
 		case workQueueRowWorktree:
-			if row.Worktree != nil && row.Worktree.Branch == m.SelectedWorktree {
-				return &row
+			if row.Worktree != nil && worktreeKey(*row.Worktree) == m.SelectedWorktree {
+				return i
 			}
 		case workQueueRowAddSubtask:
 			if row.ParentID == m.AddSubtaskSelected {
-				return &row
+				return i
 			}
 		}
 	}
-	return nil
+	return -1
 }
 
 func (m *model) selectRow(row workQueueRow) {
+	m.SuggestionIndex = 0
+	m.QuickJump = nil
 	m.SelectedIssue = nil
 	m.SelectedWorktree = ""
 	m.AddSubtaskSelected = ""
@@ -1519,24 +2719,61 @@ func (m *model) selectRow(row workQueueRow) {
 		}
 	case workQueueRowWorktree:
 		if row.Worktree != nil {
-			m.SelectedWorktree = row.Worktree.Branch
-			m.TextInput.Placeholder = row.Worktree.Branch
+			m.SelectedWorktree = worktreeKey(*row.Worktree)
+			m.TextInput.Placeholder = row.Worktree.Name()
 		}
 	case workQueueRowAddSubtask:
 		m.AddSubtaskSelected = row.ParentID
 	}
 }
 
+// expandSelection starts a subtask on "Add subtask", or shows the selected
+// issue's subtasks, fetching them first if they haven't been loaded
+func (m model) expandSelection() (model, tea.Cmd) {
+	if m.InputMode || m.Submitted || m.SearchMode {
+		return m, nil
+	}
+	if m.AddSubtaskSelected != "" {
+		// Start subtask input mode
+		m.SubtaskInputMode = true
+		m.SubtaskParentID = m.AddSubtaskSelected
+		m.setSubtaskEntryMode(m.AddSubtaskSelected, true)
+		m.resetSubtaskForm()
+		m.SubtaskInput.Focus()
+	} else if m.SelectedIssue != nil {
+		// Always expand - either to show children or the "add subtask" option
+		if !m.SelectedIssue.Expanded {
+			if m.SelectedIssue.HasChildren && len(m.SelectedIssue.Children) == 0 {
+				// Fetch children and expand
+				return m, m.fetchChildren(m.SelectedIssue.ID)
+			}
+			// Expand immediately (either shows existing children or just the "add subtask" option)
+			m.updateIssueExpansion(m.SelectedIssue.ID, true)
+		}
+		// If already expanded, do nothing (already showing children/add subtask option)
+	}
+	return m, nil
+}
+
 func (m *model) selectInput() {
+	// The issue browser has no input to select, so the tree's first row stands in for it
+	if m.BrowseOnly {
+		m.selectFirstRow()
+		return
+	}
 	m.SelectedIssue = nil
 	m.SelectedWorktree = ""
 	m.AddSubtaskSelected = ""
 	m.InputMode = true
 	m.TextInput.Focus()
 	m.TextInput.Placeholder = m.DefaultPlaceholder
+	m.ListOffset = 0
+	m.SuggestionIndex = 0
+	m.QuickJump = nil
 }
 
 func (m *model) moveSelection(delta int) {
+	defer m.scrollToSelection()
 	rows := m.visibleWorkQueueRows()
 	if len(rows) == 0 {
 		m.selectInput()
@@ -1554,13 +2791,16 @@ func (m *model) moveSelection(delta int) {
 	for i := range rows {
 		row := rows[i]
 		if (row.Kind == workQueueRowIssue && m.SelectedIssue != nil && row.Issue != nil && row.Issue.ID == m.SelectedIssue.ID) ||
-			(row.Kind == workQueueRowWorktree && row.Worktree != nil && row.Worktree.Branch == m.SelectedWorktree) ||
+			(row.Kind == workQueueRowWorktree && row.Worktree != nil && worktreeKey(*row.Worktree) == m.SelectedWorktree) ||
 			(row.Kind == workQueueRowAddSubtask && row.ParentID == m.AddSubtaskSelected) {
 			current = i
 			break
 		}
 	}
 	next := current + delta
+	if (next < 0 || next >= len(rows)) && m.BrowseOnly {
+		return
+	}
 	if next < 0 || next >= len(rows) {
 		m.selectInput()
 		if delta < 0 {
@@ -1596,8 +2836,8 @@ type worktreeLoadStartedMsg struct {
 	ch <-chan tea.Msg
 }
 
-type worktreesLoadingStatusMsg struct {
-	status string
+type worktreeCreateStartedMsg struct {
+	ch <-chan tea.Msg
 }
 
 type worktreesLoadedMsg struct {
@@ -1618,6 +2858,21 @@ type childrenErrorMsg struct {
 	err      error
 }
 
+// searchChildrenLoadedMsg brings subtasks loaded so search can find them,
+// by parent ID, without expanding their parents
+type searchChildrenLoadedMsg struct {
+	children map[string][]linear.Issue
+	err      error
+}
+
+// childrenBatchLoadedMsg brings the subtasks of several issues fetched
+// together, by parent ID
+type childrenBatchLoadedMsg struct {
+	parentIDs []string
+	children  map[string][]linear.Issue
+	err       error
+}
+
 type subtaskCreatedMsg struct {
 	parentID string
 	subtask  linear.Issue
@@ -1651,6 +2906,24 @@ type issueDoneErrorMsg struct {
 	err error
 }
 
+type linkedIssueStatesLoadedMsg struct {
+	states map[string]linear.State
+}
+
+type workflowStatesLoadedMsg struct {
+	issueID string
+	states  []linear.State
+}
+
+type issueStateUpdatedMsg struct {
+	issueID string
+	state   linear.State
+}
+
+type issueStateErrorMsg struct {
+	err error
+}
+
 type workQueueRowKind int
 
 const (
@@ -1666,16 +2939,46 @@ type workQueueRow struct {
 	ParentID string
 	Closed   bool
 	Updated  time.Time
+	Match    []int // where the search query fell in the row's search text
 }
 
 const maxVisibleActiveRows = 20
 
+// renderFailure is the result screen for an error: what went wrong, the
+// detail behind it and what to try next
+func (m model) renderFailure() string {
+	failure := m.Failure
+	if failure.Title == "" {
+		failure = problem.Problem{Title: m.ErrorMsg}
+	}
+	s := strings.Builder{}
+	s.WriteString(errorStyle.Render("✗ Error: " + failure.Title))
+	for _, detail := range failure.Details {
+		s.WriteString("\n" + helpStyle.Render("  "+detail))
+	}
+	if len(failure.Hints) > 0 {
+		s.WriteString("\n")
+	}
+	for _, hint := range failure.Hints {
+		s.WriteString("\n" + normalStyle.Render("→ "+hint))
+	}
+	s.WriteString("\n\n" + helpStyle.Render("Press any key to exit."))
+	return s.String()
+}
+
 func (m model) View() string {
+	m.Profile.MarkOnce("first render")
+	if m.Plain {
+		// The styles render through lipgloss's renderer, so its colour
+		// profile is put back once this render is done
+		defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
 	if m.Done {
 		if m.Success {
 			return successStyle.Render("✓ "+m.Result) + "\n\n" + helpStyle.Render("Press any key to exit.")
 		} else {
-			return errorStyle.Render("✗ Error: "+m.ErrorMsg) + "\n\n" + helpStyle.Render("Press any key to exit.")
+			return m.renderFailure()
 		}
 	}
 
@@ -1683,20 +2986,75 @@ func (m model) View() string {
 		return m.renderPromptCaptureView()
 	}
 
+	if m.ExistingPrompt != nil {
+		return m.renderExistingPromptView()
+	}
+
+	if m.TemplatePickerMode {
+		return m.renderTemplatePickerView()
+	}
+
+	if m.SparseProfileMode {
+		return m.renderSparseProfileView()
+	}
+
+	if m.StatusPickerMode {
+		return m.renderStatusPickerView()
+	}
+
+	if m.RepoPickerMode {
+		return m.renderRepoPickerView()
+	}
+
+	if m.LabelPickerMode {
+		return m.renderLabelPickerView()
+	}
+
+	if m.CyclePickerMode {
+		return m.renderCyclePickerView()
+	}
+
+	if m.RenameMode {
+		return m.renderRenameView()
+	}
+
+	if m.NoteMode {
+		return m.renderNoteView()
+	}
+
+	if m.Prune != nil {
+		return m.renderPruneView()
+	}
+
+	if m.HelpMode {
+		base := m
+		base.HelpMode = false
+		return placeOverlay(base.View(), m.renderHelpPanel(), m.Width, m.Height)
+	}
+
 	if m.Creating {
 		if m.ActiveCreationMode == creationModeBranchOnly {
 			return fmt.Sprintf("%s Creating branch...", m.Spinner.View())
 		}
-		return fmt.Sprintf("%s Creating worktree...", m.Spinner.View())
+		return fmt.Sprintf("%s %s", m.Spinner.View(), m.creatingStatus())
 	}
 
+	if m.CreatingSubtask && m.Checklist != nil {
+		return fmt.Sprintf("%s Creating subtasks %d/%d...", m.Spinner.View(), len(m.Checklist.Results)+1, len(m.Checklist.Titles))
+	}
 	if m.CreatingSubtask {
 		return fmt.Sprintf("%s Creating subtask...", m.Spinner.View())
 	}
 
+	if m.BoardMode {
+		return m.renderBoardView()
+	}
+
 	s := strings.Builder{}
-	s.WriteString(headerStyle.Render("🌱 sprout"))
-	s.WriteString("\n\n")
+	if m.showHeader() {
+		s.WriteString(headerStyle.Render("🌱 sprout"))
+		s.WriteString("\n\n")
+	}
 
 	// Input using textinput component - adjust prompt style based on selection and display search mode appropriately
 	if m.SearchMode {
@@ -1710,7 +3068,7 @@ func (m model) View() string {
 		} else {
 			// Show actual search content with selected branch if any
 			searchDisplay := "/" + m.SearchQuery
-			if m.SelectedIssue != nil && !m.InputMode {
+			if m.SelectedIssue != nil && !m.InputMode && !m.BrowseOnly {
 				// Show selected issue's branch name after the search
 				fullDisplay := searchDisplay + " sprout/" + m.SelectedIssue.GetBranchName()
 				s.WriteString(selectedStyle.Render(fullDisplay))
@@ -1718,9 +3076,11 @@ func (m model) View() string {
 				s.WriteString(selectedStyle.Render(searchDisplay))
 			}
 		}
+	} else if m.BrowseOnly {
+		s.WriteString(helpStyle.Render("Browsing issues; nothing here creates a branch or worktree"))
 	} else {
 		// Normal mode - adjust prompt style based on selection
-		if m.SelectedIssue == nil && m.SelectedWorktree == "" && m.AddSubtaskSelected == "" {
+		if (m.SelectedIssue == nil || m.quickJumpIssue() != nil) && m.SelectedWorktree == "" && m.AddSubtaskSelected == "" {
 			// When input is selected, use selected style for prompt
 			m.TextInput.PromptStyle = selectedStyle
 		} else {
@@ -1728,45 +3088,146 @@ func (m model) View() string {
 			m.TextInput.PromptStyle = lipgloss.NewStyle().Foreground(primaryColor)
 		}
 		s.WriteString(m.TextInput.View())
+		s.WriteString(m.renderBranchSuggestions())
+		s.WriteString(m.renderQuickJump())
+		s.WriteString(m.renderBranchNameCheck())
 	}
 	s.WriteString("\n")
+	if nextUp := m.renderNextUp(); nextUp != "" && !m.WorktreesLoading {
+		s.WriteString(nextUp)
+		s.WriteString("\n")
+	}
+	if banner := m.renderMergedBanner(); banner != "" && !m.WorktreesLoading {
+		s.WriteString(banner)
+		s.WriteString("\n")
+	}
 
 	// Display Linear tickets tree if available
-	if m.LinearLoading || m.WorktreesLoading {
+	if (m.LinearLoading && m.IssuesLoadedSoFar == 0) || m.WorktreesLoading {
 		s.WriteString(m.renderLoadingStatus())
-	} else if m.LinearError != "" {
-		s.WriteString(errorStyle.Render("Error: " + m.LinearError))
 	} else if m.WorktreesError != "" {
 		s.WriteString(errorStyle.Render("Error: " + m.WorktreesError))
 	} else {
+		// A Linear failure leaves the worktrees to work with, so they're still
+		// listed above the error
 		treeView := m.buildWorkQueueTree()
 		if treeView != "" {
 			trimmedTree := strings.TrimRight(treeView, "\n")
 			s.WriteString(trimmedTree)
 			s.WriteString("\n")
-		} else if m.LinearClient != nil && !m.SearchMode {
+		} else if m.LinearClient != nil && !m.SearchMode && m.LinearError == "" {
 			s.WriteString(helpStyle.Render("No assigned tickets found"))
 		}
+		if m.LinearError != "" {
+			s.WriteString(errorStyle.Render("Error: " + m.LinearError))
+		}
 	}
 
 	// Display creation mode toggle at the bottom, ensuring we only add a newline if needed
 	if !strings.HasSuffix(s.String(), "\n") {
 		s.WriteString("\n")
 	}
-	modeLabel := "[worktree <tab>]"
+	if m.SubtaskInputMode && m.SubtaskChecklistMode {
+		s.WriteString(m.renderSubtaskChecklist())
+		return s.String()
+	}
+	if m.SubtaskInputMode && m.SubtaskFormExpanded {
+		s.WriteString(m.renderSubtaskForm())
+		return s.String()
+	}
+	if len(m.ChecklistReport) > 0 {
+		s.WriteString(m.renderChecklistReport())
+		s.WriteString("\n")
+	}
+	s.WriteString(helpStyle.Render(m.renderFooter(m.footerHotkeys())))
+
+	return s.String()
+}
+
+// footerHotkeys lists the main shortcuts using the active keymap
+func (m model) footerHotkeys() string {
+	hint := func(binding key.Binding, label string) string {
+		return "[" + binding.Help().Key + " " + label + "]"
+	}
+
+	if m.BrowseOnly {
+		return m.wrapHotkeys([]string{
+			hint(m.Keys.Status, "status"), hint(m.Keys.Unassign, "unassign"), hint(m.Keys.Done, "done"), hint(m.Keys.Undo, "undo"),
+			hint(m.Keys.OpenIssue, "open"), hint(m.Keys.CopyIssue, "copy"), hint(m.Keys.Help, "help"),
+		})
+	}
+
+	mode := "worktree"
 	if m.CreationMode == creationModeBranchOnly {
-		modeLabel = "[branch <tab>]"
+		mode = "branch"
 	}
-	allLabel := ""
+	hints := []string{"[" + mode + " <" + m.Keys.ToggleMode.Help().Key + ">]"}
 	if len(m.Worktrees) > 0 {
-		allLabel = " [a all]"
 		if m.ShowAllWorkItems {
-			allLabel = " [a active]"
+			hints = append(hints, hint(m.Keys.ToggleAll, "active"))
+		} else {
+			hints = append(hints, hint(m.Keys.ToggleAll, "all"))
+		}
+	}
+	hints = append(hints, hint(m.Keys.Status, "status"), hint(m.Keys.Unassign, "unassign"), hint(m.Keys.Done, "done"), hint(m.Keys.Undo, "undo"))
+	if len(m.RepoRoots) > 1 {
+		hints = append(hints, hint(m.Keys.SwitchRepo, "repo"))
+	}
+	if m.LabelFilter != "" {
+		hints = append(hints, hint(m.Keys.Label, "label: "+m.LabelFilter))
+	}
+	if m.CycleFilter.Active() {
+		hints = append(hints, hint(m.Keys.Cycle, "cycle: "+m.CycleFilter.Label()))
+	}
+	if m.IssueSort != "" && m.IssueSort != config.IssueSortUpdated {
+		hints = append(hints, hint(m.Keys.Sort, "by "+m.IssueSort))
+	}
+	hints = append(hints, hint(m.Keys.Help, "help"))
+	return m.wrapHotkeys(hints)
+}
+
+// wrapHotkeys joins footer hints, wrapping onto another line rather than
+// overflowing a narrow terminal
+func (m model) wrapHotkeys(hints []string) string {
+	var lines []string
+	line := ""
+	for _, h := range hints {
+		if line != "" && m.Width > 0 && lipgloss.Width(line)+1+lipgloss.Width(h) > m.Width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
 		}
+		line += h
+	}
+	return strings.Join(append(lines, line), "\n")
+}
+
+// renderSubtaskForm renders the expanded fields of the inline subtask form; the
+// title stays inline in the tree
+func (m model) renderSubtaskForm() string {
+	label := func(field subtaskField, text string) string {
+		if m.SubtaskField == field {
+			return selectedStyle.Render(text)
+		}
+		return normalStyle.Render(text)
+	}
+	estimate := "none"
+	if points := subtaskEstimates[m.SubtaskEstimateIndex]; points > 0 {
+		estimate = fmt.Sprintf("%d", points)
 	}
-	hotkeys := modeLabel + allLabel + " [u unassign] [d done] [z undo]"
-	s.WriteString(helpStyle.Render(m.renderFooter(hotkeys)))
 
+	s := strings.Builder{}
+	s.WriteString(label(subtaskFieldDescription, "Description:"))
+	s.WriteString("\n")
+	s.WriteString(m.SubtaskDescription.View())
+	s.WriteString("\n")
+	s.WriteString(label(subtaskFieldEstimate, "Estimate: ‹ "+estimate+" ›"))
+	s.WriteString("\n")
+	s.WriteString(label(subtaskFieldPriority, "Priority: ‹ "+subtaskPriorities[m.SubtaskPriority]+" ›"))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("[tab next field] [←/→ change] [enter create] [esc cancel] [ctrl+l checklist]"))
 	return s.String()
 }
 
@@ -1790,25 +3251,33 @@ func (m model) renderLoadingStatus() string {
 }
 
 func (m model) renderFooter(hotkeys string) string {
-	if m.FooterError == "" {
+	message := m.FooterError
+	if message == "" {
+		message = m.FooterNotice
+	}
+	if message == "" {
+		message = m.issuesAge()
+	}
+	if message == "" {
 		return hotkeys
 	}
 
 	if m.Width <= 0 {
-		return hotkeys + "\n" + m.FooterError
+		return hotkeys + "\n" + message
 	}
 
-	hotkeysWidth := lipgloss.Width(hotkeys)
-	errorWidth := lipgloss.Width(m.FooterError)
-	if hotkeysWidth+1+errorWidth <= m.Width {
-		return hotkeys + strings.Repeat(" ", m.Width-hotkeysWidth-errorWidth) + m.FooterError
+	// The message shares the hotkeys' last line when the footer has wrapped
+	hotkeysWidth := lipgloss.Width(hotkeys[strings.LastIndex(hotkeys, "\n")+1:])
+	messageWidth := lipgloss.Width(message)
+	if hotkeysWidth+1+messageWidth <= m.Width {
+		return hotkeys + strings.Repeat(" ", m.Width-hotkeysWidth-messageWidth) + message
 	}
 
-	return hotkeys + "\n" + m.FooterError
+	return hotkeys + "\n" + message
 }
 
 func (m model) renderPromptCaptureView() string {
-	status := "Creating worktree..."
+	status := m.creatingStatus()
 	if m.PromptSubmitted && !m.CreationFinished {
 		status = "Prompt queued, waiting for git..."
 	} else if !m.PromptSubmitted && m.CreationFinished {
@@ -1831,6 +3300,217 @@ func (m model) renderPromptCaptureView() string {
 	return s.String()
 }
 
+func (m model) renderSparseProfileView() string {
+	s := strings.Builder{}
+	s.WriteString(headerStyle.Render("🌱 sprout"))
+	s.WriteString("\n\n")
+	s.WriteString(titleStyle.Render("Sparse checkout for " + m.PendingBranchName + ":"))
+	s.WriteString("\n")
+
+	var options []string
+	for _, option := range m.sparseOptions() {
+		if len(option.Directories) == 0 {
+			options = append(options, option.Name)
+			continue
+		}
+		options = append(options, option.Name+"  "+statusStyle.Render(strings.Join(option.Directories, ", ")))
+	}
+	for i, option := range options {
+		if i == m.SparseProfileIndex {
+			s.WriteString(selectedStyle.Render("> " + option))
+		} else {
+			s.WriteString(normalStyle.Render("  " + option))
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString(helpStyle.Render("[enter create] [esc back]"))
+	return s.String()
+}
+
+func (m model) renderTemplatePickerView() string {
+	s := strings.Builder{}
+	s.WriteString(headerStyle.Render("🌱 sprout"))
+	s.WriteString("\n\n")
+	s.WriteString(titleStyle.Render("Template for " + m.PendingBranchName + ":"))
+	s.WriteString("\n")
+	for i, prefix := range m.templateOptions() {
+		option := "no template"
+		if prefix != "" {
+			option = prefix
+			if summary := templateSummary(m.Templates[prefix]); summary != "" {
+				option += "  " + statusStyle.Render(summary)
+			}
+		}
+		if i == m.TemplatePickerIndex {
+			s.WriteString(selectedStyle.Render("> " + option))
+		} else {
+			s.WriteString(normalStyle.Render("  " + option))
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString(helpStyle.Render("[enter choose] [esc back]"))
+	return s.String()
+}
+
+// templateSummary describes what a template changes, such as "from develop, api profile"
+func templateSummary(template config.Template) string {
+	var parts []string
+	if template.Base != "" {
+		parts = append(parts, "from "+template.Base)
+	}
+	if template.SparseProfile != "" {
+		parts = append(parts, template.SparseProfile+" profile")
+	}
+	if template.DefaultCommand != "" {
+		parts = append(parts, "runs "+template.DefaultCommand)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (m model) renderExistingPromptView() string {
+	existing := m.ExistingPrompt
+	s := strings.Builder{}
+	s.WriteString(headerStyle.Render("🌱 sprout"))
+	s.WriteString("\n\n")
+	switch {
+	case existing.WorktreePath != "":
+		s.WriteString(titleStyle.Render("A worktree for " + existing.Branch + " already exists:"))
+		s.WriteString("\n")
+		s.WriteString(normalStyle.Render("  " + existing.WorktreePath))
+		s.WriteString("\n\n")
+		s.WriteString(normalStyle.Render("Open it instead?"))
+		s.WriteString("\n")
+		s.WriteString(helpStyle.Render("[enter/y open] " + m.suffixOption() + "[esc/n back]"))
+	case m.CreationMode == creationModeBranchOnly:
+		s.WriteString(titleStyle.Render("Branch " + existing.Branch + " already exists."))
+		s.WriteString("\n\n")
+		s.WriteString(normalStyle.Render("Use it instead?"))
+		s.WriteString("\n")
+		s.WriteString(helpStyle.Render("[enter/y use] " + m.suffixOption() + "[esc/n back]"))
+	default:
+		s.WriteString(titleStyle.Render("Branch " + existing.Branch + " already exists without a worktree."))
+		s.WriteString("\n\n")
+		s.WriteString(normalStyle.Render("Check it out in a new worktree?"))
+		s.WriteString("\n")
+		s.WriteString(helpStyle.Render("[enter/y check out] " + m.suffixOption() + "[esc/n back]"))
+	}
+	return s.String()
+}
+
+// suffixOption is the prompt's key for creating SuffixedBranch instead, when
+// there's a name free
+func (m model) suffixOption() string {
+	if m.SuffixedBranch == "" {
+		return ""
+	}
+	return "[s create " + m.SuffixedBranch + "] "
+}
+
+func (m model) renderStatusPickerView() string {
+	title := m.StatusPickerIssueID
+	if issue := m.findIssueByID(m.StatusPickerIssueID); issue != nil {
+		title = issue.Identifier + " " + issue.Title
+	}
+
+	s := strings.Builder{}
+	s.WriteString(headerStyle.Render("🌱 sprout"))
+	s.WriteString("\n\n")
+	s.WriteString(titleStyle.Render("Status for " + title + ":"))
+	s.WriteString("\n")
+	for i, state := range m.WorkflowStates {
+		if i == m.StatusPickerIndex {
+			s.WriteString(selectedStyle.Render("> " + state.Name))
+		} else {
+			s.WriteString(normalStyle.Render("  " + state.Name))
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString(helpStyle.Render("[enter apply] [esc back]"))
+	return s.String()
+}
+
+func (m model) renderRepoPickerView() string {
+	s := strings.Builder{}
+	s.WriteString(headerStyle.Render("🌱 sprout"))
+	s.WriteString("\n\n")
+	if m.RepoRoot == "" {
+		s.WriteString(titleStyle.Render("Choose a repository:"))
+	} else {
+		s.WriteString(titleStyle.Render("Switch repository:"))
+	}
+	s.WriteString("\n")
+	for i, root := range m.RepoRoots {
+		label := filepath.Base(root)
+		if root == m.RepoRoot {
+			label += " (current)"
+		}
+		if i == m.RepoPickerIndex {
+			s.WriteString(selectedStyle.Render("> " + label))
+		} else {
+			s.WriteString(normalStyle.Render("  " + label))
+		}
+		s.WriteString("\n")
+	}
+
+	if m.RepoRoot == "" {
+		s.WriteString(helpStyle.Render("[enter open] [esc quit]"))
+	} else {
+		s.WriteString(helpStyle.Render("[enter switch] [esc back]"))
+	}
+	return s.String()
+}
+
+func (m model) renderLabelPickerView() string {
+	s := strings.Builder{}
+	s.WriteString(headerStyle.Render("🌱 sprout"))
+	s.WriteString("\n\n")
+	s.WriteString(titleStyle.Render("Show issues labelled:"))
+	s.WriteString("\n")
+	for i, label := range m.labelFilterOptions() {
+		if label == "" {
+			label = "any label"
+		}
+		if i == m.LabelPickerIndex {
+			s.WriteString(selectedStyle.Render("> " + label))
+		} else {
+			s.WriteString(normalStyle.Render("  " + label))
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString(helpStyle.Render("[enter filter] [esc back]"))
+	return s.String()
+}
+
+func (m model) renderCyclePickerView() string {
+	s := strings.Builder{}
+	s.WriteString(headerStyle.Render("🌱 sprout"))
+	s.WriteString("\n\n")
+	s.WriteString(titleStyle.Render("Show issues in:"))
+	s.WriteString("\n")
+	for i, filter := range m.cycleFilterOptions() {
+		label := filter.Label()
+		switch {
+		case !filter.Active():
+			label = "any cycle"
+		case filter.Current:
+			label = "the current cycle"
+		}
+		if i == m.CyclePickerIndex {
+			s.WriteString(selectedStyle.Render("> " + label))
+		} else {
+			s.WriteString(normalStyle.Render("  " + label))
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString(helpStyle.Render("[enter filter] [esc back]"))
+	return s.String()
+}
+
 func (m model) buildSimpleLinearTree() string {
 	// Choose which issues to display based on search mode
 	var issuesToDisplay []linear.Issue
@@ -1910,6 +3590,7 @@ func (m model) buildWorkQueueTree() string {
 
 	maxIdentifierWidth := 0
 	maxStatusWidth := 0
+	maxWorkspaceWidth := 0
 	for _, row := range rows {
 		if row.Issue == nil {
 			continue
@@ -1920,21 +3601,128 @@ func (m model) buildWorkQueueTree() string {
 		if width := lipgloss.Width(row.Issue.State.Name); width > maxStatusWidth {
 			maxStatusWidth = width
 		}
+		if width := lipgloss.Width(row.Issue.Workspace); width > maxWorkspaceWidth {
+			maxWorkspaceWidth = width
+		}
 	}
 	if len(m.Worktrees) > 0 && maxIdentifierWidth > 0 && maxIdentifierWidth < 8 {
 		maxIdentifierWidth = 8
 	}
 
-	var s strings.Builder
-	for i, row := range rows {
+	start, end := m.listWindow(rows)
+	var lines []string
+	if start > 0 {
+		lines = append(lines, helpStyle.Render(fmt.Sprintf("↑ %d more", start)))
+	}
+	for i := start; i < end; i++ {
+		row := rows[i]
 		depth := rowDepth(row)
-		s.WriteString(m.treePrefix(rows, i, depth))
-		s.WriteString(m.renderWorkQueueRow(row, maxIdentifierWidth, maxStatusWidth))
-		if i < len(rows)-1 {
-			s.WriteString("\n")
+		lines = append(lines, m.treePrefix(rows, i, depth)+m.renderWorkQueueRow(row, maxIdentifierWidth, maxStatusWidth, maxWorkspaceWidth))
+		for _, line := range m.previewLines(row) {
+			lines = append(lines, m.previewPrefix(rows, i, depth)+line)
 		}
 	}
-	return s.String()
+	if end < len(rows) {
+		lines = append(lines, helpStyle.Render(fmt.Sprintf("↓ %d more", len(rows)-end)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// compactHeaderHeight is the terminal height below which the header is
+// dropped to leave more room for the tree
+const compactHeaderHeight = 12
+
+func (m model) showHeader() bool {
+	return m.Height <= 0 || m.Height >= compactHeaderHeight
+}
+
+// listHeight returns how many lines the tree can use between the input and
+// the footer, or 0 when the terminal height is unknown
+func (m model) listHeight() int {
+	if m.Height <= 0 {
+		return 0
+	}
+	chrome := 1 + len(m.branchSuggestions()) // the input line and any suggestions under it
+	if m.checkTypedBranchName() != nil {
+		chrome++
+	}
+	if m.renderQuickJump() != "" {
+		chrome++
+	}
+	chrome += m.previewHeight()
+	if !m.WorktreesLoading && m.nextUp() != nil {
+		chrome++
+	}
+	if m.showHeader() {
+		chrome += 2
+	}
+	switch {
+	case m.SubtaskInputMode && m.SubtaskChecklistMode:
+		chrome += lipgloss.Height(m.renderSubtaskChecklist())
+	case m.SubtaskInputMode && m.SubtaskFormExpanded:
+		chrome += lipgloss.Height(m.renderSubtaskForm())
+	default:
+		chrome += lipgloss.Height(m.renderFooter(m.footerHotkeys()))
+		chrome += len(m.ChecklistReport)
+	}
+	return max(1, m.Height-chrome)
+}
+
+// listWindow returns the range of rows to draw, starting from ListOffset but
+// moving just far enough to keep the selected row in view
+func (m model) listWindow(rows []workQueueRow) (start, end int) {
+	height := m.listHeight()
+	total := len(rows)
+	if height == 0 || total <= height {
+		return 0, total
+	}
+
+	selected := m.selectedRowIndex(rows)
+	start = m.ListOffset
+	visible := windowRows(start, height, total)
+	// Moving the window can add or remove an indicator, so settle on a start
+	// that still shows the selection with the room left over
+	for i := 0; i < 3; i++ {
+		next := scrollWindow(start, selected, visible, total)
+		if next == start {
+			break
+		}
+		start = next
+		visible = windowRows(start, height, total)
+	}
+	return start, min(total, start+visible)
+}
+
+// windowRows returns how many rows fit in height when the window begins at
+// start, after making room for the scroll indicators it needs
+func windowRows(start, height, total int) int {
+	visible := height
+	if start > 0 {
+		visible--
+	}
+	if start+visible < total {
+		visible--
+	}
+	return max(1, visible)
+}
+
+// scrollWindow clamps a window of size visible starting at offset so that it
+// stays within total rows and contains selected, when a row is selected
+func scrollWindow(offset, selected, visible, total int) int {
+	if selected >= 0 {
+		if selected < offset {
+			offset = selected
+		} else if selected >= offset+visible {
+			offset = selected - visible + 1
+		}
+	}
+	return max(0, min(offset, total-visible))
+}
+
+// scrollToSelection remembers where the tree is scrolled to, so moving back
+// up the list doesn't jump the window
+func (m *model) scrollToSelection() {
+	m.ListOffset, _ = m.listWindow(m.visibleWorkQueueRows())
 }
 
 func rowDepth(row workQueueRow) int {
@@ -1964,6 +3752,34 @@ func (m model) treePrefix(rows []workQueueRow, index, depth int) string {
 	return expandedStyle.Render(prefix.String())
 }
 
+// ciBadge is how a worktree row shows what its open PR's checks add up to,
+// or nothing when none have run
+func ciBadge(status string) string {
+	switch status {
+	case github.CIPassing:
+		return statusDoneStyle.Render("✓ CI")
+	case github.CIFailing:
+		return statusCancelledStyle.Render("✗ CI")
+	case github.CIPending:
+		return statusInProgressStyle.Render("● CI")
+	}
+	return ""
+}
+
+// previewPrefix carries the tree's lines on down past the preview under the
+// row at index
+func (m model) previewPrefix(rows []workQueueRow, index, depth int) string {
+	var prefix strings.Builder
+	for level := 0; level <= depth; level++ {
+		if hasLaterAtDepth(rows, index, level) {
+			prefix.WriteString("│  ")
+		} else {
+			prefix.WriteString("   ")
+		}
+	}
+	return expandedStyle.Render(prefix.String())
+}
+
 func hasLaterAtDepth(rows []workQueueRow, index, depth int) bool {
 	for i := index + 1; i < len(rows); i++ {
 		nextDepth := rowDepth(rows[i])
@@ -1977,17 +3793,37 @@ func hasLaterAtDepth(rows []workQueueRow, index, depth int) bool {
 	return false
 }
 
-func (m model) renderWorkQueueRow(row workQueueRow, maxIdentifierWidth, maxStatusWidth int) string {
+func (m model) renderWorkQueueRow(row workQueueRow, maxIdentifierWidth, maxStatusWidth, maxWorkspaceWidth int) string {
 	var content string
 	switch row.Kind {
 	case workQueueRowWorktree:
 		if row.Worktree != nil {
-			content = titleStyle.Render(row.Worktree.Branch)
+			content = highlightMatches(row.Worktree.Name(), row.Match, 0, titleStyle)
+			identifier := metadata.IssueFromBranch(row.Worktree.Branch)
+			if state, ok := m.LinkedIssueStates[identifier]; ok {
+				content += "  " + identifierStyle.Render(identifier) + " " + m.getStatusStyle(state).Render(state.Name)
+			}
+			if states := row.Worktree.States(); len(states) > 0 {
+				pin := ""
+				if row.Worktree.Pinned {
+					pin = "📌 "
+				}
+				content += "  " + pin + statusStyle.Render(strings.Join(states, ", "))
+			}
+			if badge := ciBadge(row.Worktree.CIStatus); badge != "" {
+				content += "  " + badge
+			}
+			if row.Worktree.DiskUsage > 0 {
+				content += "  " + statusStyle.Render(stats.FormatBytes(row.Worktree.DiskUsage))
+			}
+			if row.Worktree.Note != "" {
+				content += "  " + statusStyle.Render("📝 "+row.Worktree.Note)
+			}
 		}
 	case workQueueRowAddSubtask:
 		if parent := m.findIssueByID(row.ParentID); parent != nil && parent.ShowingSubtaskEntry {
 			if m.SubtaskInputMode && m.SubtaskParentID == row.ParentID {
-				content = m.SubtaskInput.View()
+				content = m.subtaskEntryView()
 			} else {
 				content = addSubtaskStyle.Render("+ " + parent.SubtaskEntryText)
 			}
@@ -1996,7 +3832,7 @@ func (m model) renderWorkQueueRow(row workQueueRow, maxIdentifierWidth, maxStatu
 		}
 	case workQueueRowIssue:
 		if row.Issue != nil {
-			content = m.renderIssueContent(*row.Issue, maxIdentifierWidth, maxStatusWidth)
+			content = m.renderIssueContent(*row.Issue, row.Match, maxIdentifierWidth, maxStatusWidth, maxWorkspaceWidth)
 		}
 	}
 
@@ -2005,7 +3841,7 @@ func (m model) renderWorkQueueRow(row workQueueRow, maxIdentifierWidth, maxStatu
 	case workQueueRowIssue:
 		selected = m.SelectedIssue != nil && row.Issue != nil && row.Issue.ID == m.SelectedIssue.ID
 	case workQueueRowWorktree:
-		selected = row.Worktree != nil && row.Worktree.Branch == m.SelectedWorktree
+		selected = row.Worktree != nil && worktreeKey(*row.Worktree) == m.SelectedWorktree
 	case workQueueRowAddSubtask:
 		selected = row.ParentID == m.AddSubtaskSelected
 	}
@@ -2015,7 +3851,10 @@ func (m model) renderWorkQueueRow(row workQueueRow, maxIdentifierWidth, maxStatu
 	return normalStyle.Render(content)
 }
 
-func (m model) renderIssueContent(issue linear.Issue, maxIdentifierWidth, maxStatusWidth int) string {
+// renderIssueContent lays out an issue's row in columns; the workspace column
+// only appears when issues from several Linear workspaces are merged. match
+// highlights where a search query fell in the identifier and title
+func (m model) renderIssueContent(issue linear.Issue, match []int, maxIdentifierWidth, maxStatusWidth, maxWorkspaceWidth int) string {
 	title := issue.Title
 	statusText := issue.State.Name
 	statusStyle := m.getStatusStyle(issue.State)
@@ -2025,20 +3864,121 @@ func (m model) renderIssueContent(issue linear.Issue, maxIdentifierWidth, maxSta
 	treePrefixWidth := (issue.Depth + 1) * 3
 	marginWidth := 15
 	availableWidth := m.Width - maxIdentifierWidth - maxStatusWidth - treePrefixWidth - marginWidth
+	if maxWorkspaceWidth > 0 {
+		availableWidth -= maxWorkspaceWidth + 2
+	}
 	if availableWidth < 20 {
 		availableWidth = 20
 	}
+
+	badges, badgesWidth := issueBadges(issue)
+	if badgesWidth > 0 {
+		availableWidth = max(availableWidth-badgesWidth-2, 20)
+	}
+	// Chips get whatever is left once the title has room for its start
+	chips, chipsWidth := issueChips(issue, availableWidth-min(len(title), minTitleWidth)-2)
+	if chipsWidth > 0 {
+		availableWidth = max(availableWidth-chipsWidth-2, minTitleWidth)
+	}
+	ellipsis := ""
 	if len(title) > availableWidth && availableWidth > 3 {
-		title = title[:availableWidth-3] + "..."
+		title, ellipsis = title[:availableWidth-3], "..."
 	}
 
-	identifier := identifierStyle.Render(issue.Identifier)
-	titleText := titleStyle.Render(title)
+	identifier := highlightMatches(issue.Identifier, match, 0, identifierStyle)
+	titleText := highlightMatches(title, match, utf8.RuneCountInString(issue.Identifier)+1, titleStyle)
+	if ellipsis != "" {
+		titleText += titleStyle.Render(ellipsis)
+	}
+	if chips != "" {
+		titleText += "  " + chips
+	}
+	if badges != "" {
+		titleText += "  " + badges
+	}
 	identifierPadding := maxIdentifierWidth - lipgloss.Width(issue.Identifier)
 	statusPadding := maxStatusWidth - statusWidth
+	if maxWorkspaceWidth > 0 {
+		workspacePadding := maxWorkspaceWidth - lipgloss.Width(issue.Workspace)
+		identifier += strings.Repeat(" ", identifierPadding) + "  " + workspaceStyle.Render(issue.Workspace)
+		identifierPadding = workspacePadding
+	}
 	return fmt.Sprintf("%s%s  %s%s  %s", identifier, strings.Repeat(" ", identifierPadding), styledStatus, strings.Repeat(" ", statusPadding), titleText)
 }
 
+// minTitleWidth is how much of an issue's title label and project chips leave room for
+const minTitleWidth = 20
+
+// issueChips renders an issue's labels in their Linear colors, then its
+// project, fitting as many as room allows and counting the rest as "+N".
+// It returns the chips with their unstyled width.
+func issueChips(issue linear.Issue, room int) (string, int) {
+	type chip struct {
+		text  string
+		style lipgloss.Style
+	}
+	var chips []chip
+	for _, label := range issue.Labels {
+		style := lipgloss.NewStyle()
+		if label.Color != "" {
+			style = style.Foreground(lipgloss.Color(label.Color))
+		}
+		chips = append(chips, chip{"● " + label.Name, style})
+	}
+	if project := issue.ProjectName(); project != "" {
+		chips = append(chips, chip{"◆ " + project, statusStyle})
+	}
+
+	var styled []string
+	width := 0
+	for i, c := range chips {
+		sep := 0
+		if width > 0 {
+			sep = 1
+		}
+		// Showing this chip must still leave room to count the ones after it
+		rest := 0
+		if i < len(chips)-1 {
+			rest = len(fmt.Sprintf(" +%d", len(chips)-i-1))
+		}
+		if width+sep+lipgloss.Width(c.text)+rest > room {
+			if overflow := fmt.Sprintf("+%d", len(chips)-i); width+sep+len(overflow) <= room {
+				styled = append(styled, statusStyle.Render(overflow))
+				width += sep + len(overflow)
+			}
+			break
+		}
+		styled = append(styled, c.style.Render(c.text))
+		width += sep + lipgloss.Width(c.text)
+	}
+	return strings.Join(styled, " "), width
+}
+
+// issueBadges renders an issue's priority, estimate and cycle compactly, e.g.
+// "P1 3pt C12", returning them with their unstyled width
+func issueBadges(issue linear.Issue) (string, int) {
+	var styled, plain []string
+	add := func(text string, style lipgloss.Style) {
+		styled = append(styled, style.Render(text))
+		plain = append(plain, text)
+	}
+	switch {
+	case issue.Priority == 1:
+		add("P1", priorityUrgentStyle)
+	case issue.Priority == 2:
+		add("P2", priorityHighStyle)
+	case issue.Priority > 2:
+		add(fmt.Sprintf("P%d", issue.Priority), statusStyle)
+	}
+	if issue.Estimate > 0 {
+		add(strconv.FormatFloat(issue.Estimate, 'f', -1, 64)+"pt", statusStyle)
+	}
+	if issue.Cycle != nil && issue.Cycle.Number > 0 {
+		add(fmt.Sprintf("C%d", int(issue.Cycle.Number)), statusStyle)
+	}
+	return strings.Join(styled, " "), lipgloss.Width(strings.Join(plain, " "))
+}
+
 // addIssueNode recursively adds an issue and its children to the tree
 func (m model) addIssueNode(parent *tree.Tree, issue linear.Issue) {
 	// Create the display content
@@ -2103,7 +4043,7 @@ func (m model) addIssueNode(parent *tree.Tree, issue linear.Issue) {
 		if issue.ShowingSubtaskEntry {
 			// Show the input field inline
 			if m.SubtaskInputMode && m.SubtaskParentID == issue.ID {
-				addSubtaskContent = m.SubtaskInput.View()
+				addSubtaskContent = m.subtaskEntryView()
 			} else {
 				// Show the text being entered (not currently in input mode)
 				addSubtaskContent = addSubtaskStyle.Render("+ " + issue.SubtaskEntryText)
@@ -2130,9 +4070,20 @@ func (m model) addIssueNode(parent *tree.Tree, issue linear.Issue) {
 }
 
 func RunInteractive() error {
-	m, err := NewTUI()
+	return RunInteractiveWithProfile(nil)
+}
+
+// RunInteractiveWithProfile is RunInteractive marking how long setting up
+// and the first render took on profile
+func RunInteractiveWithProfile(profile *startup.Profile) error {
+	m, err := newTUI(profile)
 	if err != nil {
-		return err
+		// Outside any repository, offer the registered ones instead
+		picker, ok := newLaunchRepoPicker()
+		if !ok {
+			return err
+		}
+		m = picker
 	}
 
 	p := tea.NewProgram(m)
@@ -2140,6 +4091,9 @@ func RunInteractive() error {
 	if err != nil {
 		return err
 	}
+	if resultModel, ok := finalModel.(model); ok {
+		resultModel.saveIssueTree()
+	}
 
 	// Check if user cancelled
 	if resultModel, ok := finalModel.(model); ok && resultModel.Cancelled {
@@ -2149,6 +4103,9 @@ func RunInteractive() error {
 
 	// After TUI exits, check if we need to execute a default command
 	if resultModel, ok := finalModel.(model); ok && resultModel.Success && resultModel.WorktreePath != "" && resultModel.Resumed {
+		if err := resultModel.WorktreeManager.StartTimer(resultModel.ResumeBranch, resultModel.WorktreePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 		repoName, _ := git.GetRepositoryName()
 		resolvedCmd := config.ResolveResumeCommand(resultModel.ResumeCommandArgs, resultModel.DefaultCommandArgs, config.ResumeContext{
 			WorktreePath: resultModel.WorktreePath,
@@ -2156,6 +4113,7 @@ func RunInteractive() error {
 			RepoName:     repoName,
 		})
 		if len(resolvedCmd) > 0 {
+			resultModel.recordLastCommand(resultModel.ResumeBranch, resolvedCmd)
 			cmd := exec.Command(resolvedCmd[0], resolvedCmd[1:]...)
 			cmd.Dir = resultModel.WorktreePath
 			cmd.Stdin = os.Stdin
@@ -2172,9 +4130,15 @@ func RunInteractive() error {
 			}
 		}
 	} else if resultModel, ok := finalModel.(model); ok && resultModel.Success && resultModel.WorktreePath != "" {
-		resolvedCmd := config.ResolveDefaultCommand(resultModel.DefaultCommandArgs, resultModel.CapturedPrompt)
+		repoName, _ := git.GetRepositoryName()
+		resolvedCmd, lastCmd := config.ResolveCreatedCommand(resultModel.DefaultCommandArgs, resultModel.ResumeCommandArgs, resultModel.CapturedPrompt, config.ResumeContext{
+			WorktreePath: resultModel.WorktreePath,
+			BranchName:   resultModel.CreatedBranch,
+			RepoName:     repoName,
+		})
 		if len(resolvedCmd) > 0 {
 			// Execute the default command in the worktree directory
+			resultModel.recordLastCommand(resultModel.CreatedBranch, lastCmd)
 			cmd := exec.Command(resolvedCmd[0], resolvedCmd[1:]...)
 			cmd.Dir = resultModel.WorktreePath
 			cmd.Stdin = os.Stdin
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"sprout/pkg/git"
+)
+
+// branchWarningStyle is the line under the input saying what's wrong with the
+// branch name being typed
+var branchWarningStyle = lipgloss.NewStyle().
+	Foreground(warningColor)
+
+// branchNameCheck is what's wrong with the branch name being typed
+type branchNameCheck struct {
+	Message string
+	Blocks  bool // Enter does nothing until the name is changed
+}
+
+// checkTypedBranchName validates the branch name in the input as it's typed,
+// by the same rules creating its worktree would, or returns nil when there's
+// nothing to say. A worktree that already exists isn't a problem, since Enter
+// offers to open it, but it's worth knowing before pressing it
+func (m model) checkTypedBranchName() *branchNameCheck {
+	typed := strings.TrimSpace(m.TextInput.Value())
+	if !m.InputMode || m.SearchMode || m.Submitted || m.SubtaskInputMode || typed == "" || m.SuggestionIndex > 0 || m.quickJumpIssue() != nil {
+		return nil
+	}
+
+	branch, err := git.ValidateBranchName(typed)
+	if err != nil {
+		return &branchNameCheck{Message: err.Error(), Blocks: true}
+	}
+	for _, wt := range m.Worktrees {
+		if wt.Branch == branch {
+			return &branchNameCheck{Message: branch + " has a worktree already; Enter offers to open it"}
+		}
+	}
+	return nil
+}
+
+// renderBranchNameCheck is the warning line under the input, if there is one
+func (m model) renderBranchNameCheck() string {
+	check := m.checkTypedBranchName()
+	if check == nil {
+		return ""
+	}
+	return "\n" + branchWarningStyle.Render("⚠ "+check.Message)
+}
@@ -0,0 +1,101 @@
+package version
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GitFeature is a git capability sprout relies on, with the release that added it
+type GitFeature struct {
+	Name       string
+	MinVersion string
+}
+
+var (
+	// GitWorktrees covers git worktree list --porcelain, which every worktree command reads
+	GitWorktrees = GitFeature{Name: "git worktree list --porcelain", MinVersion: "2.7.0"}
+	// GitWorktreeMove covers git worktree move, which sprout rename relies on
+	GitWorktreeMove = GitFeature{Name: "git worktree move", MinVersion: "2.17.0"}
+	// GitWorktreeLock covers git worktree lock, which sprout pin relies on
+	GitWorktreeLock = GitFeature{Name: "git worktree lock", MinVersion: "2.10.0"}
+	// GitSparseCone covers the cone-mode sparse checkouts behind --paths and sparse profiles
+	GitSparseCone = GitFeature{Name: "git sparse-checkout --cone", MinVersion: "2.25.0"}
+)
+
+// Supports reports whether gitVersion has the feature; an unknown version is
+// given the benefit of the doubt
+func (f GitFeature) Supports(gitVersion string) bool {
+	return gitVersion == "" || Compare(gitVersion, f.MinVersion) >= 0
+}
+
+// ToolsInterface reports the versions of the external tools sprout drives
+type ToolsInterface interface {
+	GitVersion() (string, error)
+	GHVersion() (string, error)
+}
+
+type commandRunner func(name string, args ...string) ([]byte, error)
+
+// Tools asks the git and gh binaries on PATH for their versions
+type Tools struct {
+	runner commandRunner
+}
+
+func NewTools() *Tools {
+	return NewToolsWithRunner(runCommandOutput)
+}
+
+func NewToolsWithRunner(runner commandRunner) *Tools {
+	if runner == nil {
+		runner = runCommandOutput
+	}
+	return &Tools{runner: runner}
+}
+
+func runCommandOutput(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+// GitVersion returns the local git's version, e.g. 2.43.0
+func (t *Tools) GitVersion() (string, error) {
+	return t.version("git")
+}
+
+// GHVersion returns the GitHub CLI's version, e.g. 2.40.1
+func (t *Tools) GHVersion() (string, error) {
+	return t.version("gh")
+}
+
+func (t *Tools) version(name string) (string, error) {
+	output, err := t.runner(name, "--version")
+	if err != nil {
+		return "", fmt.Errorf("%s --version failed: %w", name, err)
+	}
+	parsed := ParseToolVersion(string(output))
+	if parsed == "" {
+		return "", fmt.Errorf("couldn't find a version in %s --version output: %q", name, strings.TrimSpace(string(output)))
+	}
+	return parsed, nil
+}
+
+// ParseToolVersion pulls the dotted version number out of a tool's --version
+// output, such as "git version 2.39.3 (Apple Git-146)" or "2.42.0.windows.2"
+func ParseToolVersion(output string) string {
+	firstLine, _, _ := strings.Cut(output, "\n")
+	for _, field := range strings.Fields(firstLine) {
+		end := 0
+		for end < len(field) && (field[end] >= '0' && field[end] <= '9' || field[end] == '.') {
+			end++
+		}
+		numbers := strings.Split(strings.Trim(field[:end], "."), ".")
+		if len(numbers) < 2 || numbers[0] == "" {
+			continue
+		}
+		if len(numbers) > 3 {
+			numbers = numbers[:3]
+		}
+		return strings.Join(numbers, ".")
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Build details, injected at release time with
+//
+//	go build -ldflags "-X sprout/pkg/version.Version=v1.2.3 -X sprout/pkg/version.Commit=abc1234 -X sprout/pkg/version.Date=2026-01-02T15:04:05Z"
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Dev is the version of builds made without release ldflags, such as go run or go install
+const Dev = "dev"
+
+// IsRelease reports whether this binary was built by the release pipeline
+func IsRelease() bool {
+	return Version != Dev && Version != ""
+}
+
+// Newer reports whether version a is later than version b. Both are semver
+// strings with an optional leading v; a pre-release sorts before its release.
+func Newer(a, b string) bool {
+	return Compare(a, b) > 0
+}
+
+// Compare orders two semver strings, returning -1, 0 or 1
+func Compare(a, b string) int {
+	aCore, aPre := split(a)
+	bCore, bPre := split(b)
+	for i := 0; i < 3; i++ {
+		if aCore[i] != bCore[i] {
+			if aCore[i] < bCore[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	case aPre < bPre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// split parses "v1.2.3-rc.1" into its numeric core and pre-release suffix;
+// missing or malformed parts count as 0
+func split(version string) ([3]int, string) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+	core, pre, _ := strings.Cut(version, "-")
+
+	var parts [3]int
+	for i, field := range strings.SplitN(core, ".", 3) {
+		parts[i], _ = strconv.Atoi(field)
+	}
+	return parts, pre
+}
@@ -0,0 +1,54 @@
+package version
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"1.2.3", "v1.2.3", 0},
+		{"v1.10.0", "v1.9.9", 1},
+		{"v1.2.3", "v2.0.0", -1},
+		{"v1.2.3-rc.1", "v1.2.3", -1},
+		{"v1.2.3", "v1.2.3-rc.1", 1},
+		{"v1.2.3-rc.2", "v1.2.3-rc.1", 1},
+		{"v1.2", "v1.2.0", 0},
+		{"v1.2.3+build.5", "v1.2.3", 0},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParseToolVersion(t *testing.T) {
+	tests := []struct {
+		output, want string
+	}{
+		{"git version 2.43.0\n", "2.43.0"},
+		{"git version 2.39.3 (Apple Git-146)", "2.39.3"},
+		{"git version 2.42.0.windows.2", "2.42.0"},
+		{"gh version 2.40.1 (2023-12-13)\nhttps://github.com/cli/cli/releases/tag/v2.40.1\n", "2.40.1"},
+		{"no version here", ""},
+	}
+	for _, tt := range tests {
+		if got := ParseToolVersion(tt.output); got != tt.want {
+			t.Errorf("ParseToolVersion(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestGitFeatureSupports(t *testing.T) {
+	if GitSparseCone.Supports("2.20.1") {
+		t.Error("Expected git 2.20.1 to lack cone-mode sparse checkout")
+	}
+	if !GitSparseCone.Supports("2.25.0") || !GitSparseCone.Supports("2.43.0") {
+		t.Error("Expected git 2.25.0 and later to support cone-mode sparse checkout")
+	}
+	if !GitWorktrees.Supports("") {
+		t.Error("Expected an unknown git version to be given the benefit of the doubt")
+	}
+}
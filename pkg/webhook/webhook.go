@@ -0,0 +1,98 @@
+// Package webhook posts worktree and PR lifecycle events to the webhook set
+// in the sprout config, such as a Slack incoming webhook for a team channel
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sprout/pkg/config"
+)
+
+// Payload is the JSON body posted for each event. Text sums it up in a
+// sentence, which is what Slack shows
+type Payload struct {
+	Event  string    `json:"event"`
+	Repo   string    `json:"repo"`
+	Branch string    `json:"branch"`
+	Path   string    `json:"path,omitempty"`
+	Issue  string    `json:"issue,omitempty"`
+	Time   time.Time `json:"time"`
+	Text   string    `json:"text"`
+}
+
+// Notifier posts events to one webhook
+type Notifier struct {
+	webhook    config.Webhook
+	timeout    time.Duration
+	httpClient *http.Client
+}
+
+// New returns a Notifier for webhook, or nil when no URL is set. A nil
+// Notifier sends nothing
+func New(webhook config.Webhook, timeout time.Duration) *Notifier {
+	if webhook.URL == "" {
+		return nil
+	}
+	return &Notifier{webhook: webhook, timeout: timeout, httpClient: &http.Client{}}
+}
+
+// Notify posts payload, unless the webhook leaves its event out. Time and
+// Text are filled in when they're empty
+func (n *Notifier) Notify(payload Payload) error {
+	if n == nil || !n.webhook.Sends(payload.Event) {
+		return nil
+	}
+	if payload.Time.IsZero() {
+		payload.Time = time.Now()
+	}
+	if payload.Text == "" {
+		payload.Text = summarize(payload)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	ctx := context.Background()
+	if n.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", n.webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post %s to the webhook: %w", payload.Event, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook rejected %s with status %d", payload.Event, resp.StatusCode)
+	}
+	return nil
+}
+
+func summarize(payload Payload) string {
+	what := payload.Branch
+	if payload.Issue != "" {
+		what = payload.Branch + " (" + payload.Issue + ")"
+	}
+	switch payload.Event {
+	case config.WebhookWorktreeCreated:
+		return fmt.Sprintf("Worktree created for %s in %s", what, payload.Repo)
+	case config.WebhookWorktreeDeleted:
+		return fmt.Sprintf("Worktree for %s in %s cleaned up", what, payload.Repo)
+	case config.WebhookPRMerged:
+		return fmt.Sprintf("PR for %s in %s merged", what, payload.Repo)
+	}
+	return fmt.Sprintf("%s: %s in %s", payload.Event, what, payload.Repo)
+}
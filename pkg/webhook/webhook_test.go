@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"sprout/pkg/config"
+)
+
+func TestNotifyPostsTheEventAsJSON(t *testing.T) {
+	var received []Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected a JSON POST, got %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		var payload Payload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		received = append(received, payload)
+	}))
+	defer server.Close()
+
+	notifier := New(config.Webhook{URL: server.URL, Events: []string{config.WebhookWorktreeCreated}}, time.Second)
+	if err := notifier.Notify(Payload{Event: config.WebhookWorktreeCreated, Repo: "web", Branch: "spr-12-fix-login", Issue: "SPR-12"}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if err := notifier.Notify(Payload{Event: config.WebhookWorktreeDeleted, Repo: "web", Branch: "spr-12-fix-login"}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("expected only the created event posted, got %+v", received)
+	}
+	if received[0].Text != "Worktree created for spr-12-fix-login (SPR-12) in web" || received[0].Time.IsZero() {
+		t.Fatalf("expected the text and time filled in, got %+v", received[0])
+	}
+}
+
+func TestNotifyReportsARejectedPost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := New(config.Webhook{URL: server.URL}, time.Second).Notify(Payload{Event: config.WebhookPRMerged, Branch: "fix"})
+	if err == nil || !strings.Contains(err.Error(), "webhook rejected prMerged with status 404") {
+		t.Fatalf("expected the status reported, got %v", err)
+	}
+	if err := New(config.Webhook{}, time.Second).Notify(Payload{Event: config.WebhookPRMerged}); err != nil {
+		t.Fatalf("expected no webhook to send nothing, got %v", err)
+	}
+}